@@ -0,0 +1,53 @@
+package main
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"domain-detection-go/internal/handler"
+)
+
+// adminHandlers serve the admin API
+type adminHandlers struct {
+	domain        *handler.DomainHandler
+	monitor       *handler.MonitorHandler
+	providerUsage *handler.ProviderUsageHandler
+	retention     *handler.RetentionHandler
+	billing       *handler.BillingHandler
+	trial         *handler.TrialHandler
+	auth          *handler.AuthHandler
+}
+
+// registerAdminRoutes adds the admin API to a group that already requires an admin
+func registerAdminRoutes(admin *gin.RouterGroup, h adminHandlers) {
+	admin.PUT("/settings/domain-limit", h.domain.UpdateDomainLimit)
+	admin.POST("/domains/transfer", h.domain.TransferDomains)
+	admin.GET("/dns/resolvers", h.domain.GetDNSResolvers)
+	admin.GET("/region-fallbacks", h.domain.GetRegionFallbacks)
+	admin.PUT("/region-fallbacks/:region", h.domain.UpdateRegionFallbacks)
+	admin.GET("/monitors/drift", h.domain.GetMonitorDrift)
+	admin.POST("/monitors/reconcile", h.domain.ReconcileMonitors)
+	admin.POST("/monitors/sync", h.monitor.SyncMonitors)
+	admin.GET("/monitors/providers/stats", h.monitor.GetProviderStats)
+	admin.POST("/monitors/checkpoints/invalidate", h.monitor.InvalidateCheckpoints)
+	admin.GET("/provider-usage", h.providerUsage.GetUsage)
+
+	// Data retention
+	admin.GET("/retention/policies", h.retention.GetPolicies)
+	admin.PUT("/retention/policies/:plan", h.retention.UpdatePolicy)
+	admin.GET("/retention/report", h.retention.GetReport)
+	admin.POST("/retention/run", h.retention.RunRetention)
+
+	// Billing statements and unit prices
+	admin.GET("/billing/prices", h.billing.GetPrices)
+	admin.PUT("/billing/prices/:plan", h.billing.UpdatePrices)
+	admin.GET("/billing/statements", h.billing.GetStatements)
+	admin.GET("/billing/statements/:user_id", h.billing.GetUserStatement)
+
+	// Trial accounts
+	admin.GET("/trials/:user_id", h.trial.GetUserTrialStatus)
+	admin.PUT("/trials/:user_id", h.trial.UpdateTrial)
+	admin.POST("/trials/:user_id/convert", h.trial.ConvertTrial)
+
+	// Support access to a user's account
+	admin.POST("/impersonate/:user_id", h.auth.Impersonate)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"domain-detection-go/internal/middleware"
+)
+
+type nonAdmins struct{}
+
+func (nonAdmins) IsAdmin(int) (bool, error) { return false, nil }
+
+// adminRoutes are requests to the admin API; a non-admin must be refused every one of them
+var adminRoutes = []struct {
+	method string
+	path   string
+}{
+	{http.MethodPost, "/api/admin/impersonate/2"},
+	{http.MethodPut, "/api/admin/settings/domain-limit"},
+	{http.MethodPost, "/api/admin/domains/transfer"},
+	{http.MethodGet, "/api/admin/trials/2"},
+	{http.MethodPut, "/api/admin/trials/2"},
+	{http.MethodPost, "/api/admin/trials/2/convert"},
+	{http.MethodGet, "/api/admin/billing/prices"},
+	{http.MethodPut, "/api/admin/billing/prices/pro"},
+	{http.MethodGet, "/api/admin/billing/statements"},
+	{http.MethodGet, "/api/admin/billing/statements/2"},
+	{http.MethodGet, "/api/admin/retention/policies"},
+	{http.MethodPut, "/api/admin/retention/policies/pro"},
+	{http.MethodGet, "/api/admin/retention/report"},
+	{http.MethodPost, "/api/admin/retention/run"},
+	{http.MethodGet, "/api/admin/region-fallbacks"},
+	{http.MethodPut, "/api/admin/region-fallbacks/SG"},
+	{http.MethodPost, "/api/admin/monitors/reconcile"},
+	{http.MethodPost, "/api/admin/monitors/sync"},
+	{http.MethodPost, "/api/admin/monitors/checkpoints/invalidate"},
+	{http.MethodGet, "/api/admin/provider-usage"},
+}
+
+func TestAdminRoutesRefuseNonAdmins(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	authenticated := r.Group("/api", func(c *gin.Context) {
+		c.Set("user_id", 1)
+		c.Next()
+	})
+	// The handlers are never reached, so they need no services
+	registerAdminRoutes(authenticated.Group("/admin", middleware.AdminMiddleware(nonAdmins{})), adminHandlers{})
+
+	for _, route := range adminRoutes {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(route.method, route.path, nil))
+		if w.Code != http.StatusForbidden {
+			t.Errorf("%s %s: status = %d, want %d", route.method, route.path, w.Code, http.StatusForbidden)
+		}
+	}
+}
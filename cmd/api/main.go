@@ -1,8 +1,15 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"log"
-	"os"
+	"log/slog"
+	"net/http"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -10,20 +17,59 @@ import (
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 
+	"domain-detection-go/internal/audit"
 	"domain-detection-go/internal/auth"
+	"domain-detection-go/internal/deepcheck"
+	"domain-detection-go/internal/dns"
 	"domain-detection-go/internal/domain"
+	"domain-detection-go/internal/events"
+	"domain-detection-go/internal/export"
 	"domain-detection-go/internal/handler"
+	"domain-detection-go/internal/health"
+	"domain-detection-go/internal/logging"
 	"domain-detection-go/internal/middleware"
+	"domain-detection-go/internal/migrate"
 	"domain-detection-go/internal/monitor"
 	"domain-detection-go/internal/notification"
+	"domain-detection-go/internal/probe"
+	"domain-detection-go/internal/report"
 	"domain-detection-go/internal/service"
+	"domain-detection-go/internal/trial"
+	"domain-detection-go/migrations"
 	"domain-detection-go/pkg/config"
 )
 
+// shutdownTimeout bounds how long a SIGTERM waits for requests and checks in progress
+const shutdownTimeout = 30 * time.Second
+
 func main() {
+	migrateOnly := flag.Bool("migrate", false, "apply pending schema migrations and exit")
+	flag.Parse()
+
 	// Load configuration
 	cfg := config.LoadConfig()
 
+	// Structured logs; the standard log package writes through the same handler
+	logger := logging.Setup(logging.Config{Level: cfg.LogLevel, Format: cfg.LogFormat})
+
+	if *migrateOnly {
+		db, err := sqlx.Connect("postgres", cfg.DatabaseURL)
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer db.Close()
+		runMigrations(db)
+		return
+	}
+
+	// Fail fast on missing or invalid settings rather than on the first request using them
+	if err := cfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if missing := cfg.MissingProductionVars(); len(missing) > 0 {
+		logger.Warn("Configuration incomplete, the features using these variables will fail", "missing", missing)
+	}
+
 	// Connect to database
 	db, err := sqlx.Connect("postgres", cfg.DatabaseURL)
 	if err != nil {
@@ -31,120 +77,379 @@ func main() {
 	}
 	defer db.Close()
 
+	if cfg.AutoMigrate {
+		runMigrations(db)
+	}
+
 	// Initialize monitor service
 	uptrendsConfig := monitor.UptrendsConfig{
-		APIKey:      os.Getenv("UPTRENDS_API_KEY"),
-		APIUsername: os.Getenv("UPTRENDS_USERNAME"),
-		BaseURL:     os.Getenv("UPTRENDS_API_URL"), // Optional
-		MaxRetries:  3,
-		RetryDelay:  2 * time.Second,
+		APIKey:        cfg.UptrendsAPIKey,
+		APIUsername:   cfg.UptrendsUsername,
+		BaseURL:       cfg.UptrendsAPIURL,
+		MaxRetries:    3,
+		RetryDelay:    2 * time.Second,
+		MaxConcurrent: cfg.UptrendsMaxConcurrent,
+		CheckpointTTL: time.Duration(cfg.UptrendsCheckpointTTL) * time.Minute,
 	}
 	uptrendsClient := monitor.NewUptrendsClient(uptrendsConfig)
 
 	// Initialize Site24x7 client
 	site24x7Config := monitor.Site24x7Config{
-		ClientID:     os.Getenv("SITE24X7_CLIENT_ID"),
-		ClientSecret: os.Getenv("SITE24X7_CLIENT_SECRET"),
-		RefreshToken: os.Getenv("SITE24X7_REFRESH_TOKEN"),
-		BaseURL:      "https://www.site24x7.com/api",
+		ClientID:      cfg.Site24x7ClientID,
+		ClientSecret:  cfg.Site24x7ClientSecret,
+		RefreshToken:  cfg.Site24x7RefreshToken,
+		BaseURL:       "https://www.site24x7.com/api",
+		MaxConcurrent: cfg.Site24x7MaxConcurrent,
 	}
 	site24x7Client := monitor.NewSite24x7Client(site24x7Config)
 
+	// Count every request sent to the provider APIs
+	providerUsageService := service.NewProviderUsageService(db, map[string]int{
+		"Uptrends": cfg.UptrendsMonthlyQuota,
+		"Site24x7": cfg.Site24x7MonthlyQuota,
+	})
+	uptrendsClient.SetUsageRecorder(providerUsageService)
+	site24x7Client.SetUsageRecorder(providerUsageService)
+
+	// Initialize the built-in HTTP checker
+	directCheckConfig := monitor.DirectCheckConfig{
+		Timeout:      time.Duration(cfg.DirectCheckTimeout) * time.Second,
+		UserAgent:    cfg.DirectCheckUserAgent,
+		MaxRedirects: cfg.DirectCheckMaxRedirects,
+	}
+	directClient := monitor.NewDirectCheckClient(db, directCheckConfig)
+
 	telegramConfig := notification.TelegramConfig{
-		APIToken: os.Getenv("TELEGRAM_BOT_TOKEN"),
+		APIToken: cfg.TelegramBotToken,
 	}
 
 	// Add email configuration
 	emailConfig := notification.EmailConfig{
-		SMTPHost:     os.Getenv("SMTP_HOST"),
-		SMTPPort:     os.Getenv("SMTP_PORT"),
-		SMTPUsername: os.Getenv("SMTP_USERNAME"),
-		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
-		FromEmail:    os.Getenv("FROM_EMAIL"),
-		FromName:     os.Getenv("FROM_NAME"),
+		Provider:      cfg.EmailProvider,
+		SMTPHost:      cfg.SMTPHost,
+		SMTPPort:      cfg.SMTPPort,
+		SMTPUsername:  cfg.SMTPUsername,
+		SMTPPassword:  cfg.SMTPPassword,
+		SMTPPoolSize:  cfg.SMTPPoolSize,
+		SMTPPlaintext: cfg.SMTPPlaintext,
+		FromEmail:     cfg.FromEmail,
+		FromName:      cfg.FromName,
+
+		SendGridAPIKey: cfg.SendGridAPIKey,
+
+		SESRegion:          cfg.SESRegion,
+		SESAccessKeyID:     cfg.SESAccessKeyID,
+		SESSecretAccessKey: cfg.SESSecretAccessKey,
 	}
 
 	// Initialize services
-	authService := auth.NewAuthService(db, cfg.JWTSecret, cfg.EncryptionKey)
-	domainService := domain.NewDomainService(db, uptrendsClient, site24x7Client)
-	deepCheckService := service.NewDeepCheckService(db)
+	eventBus := events.NewBus()
+	auditLogger := audit.NewLogger(db)
+	authService := auth.NewAuthService(db, cfg.JWTSecret, cfg.EncryptionKey, cfg.TrialDays, auditLogger)
+	domainService := domain.NewDomainService(db, uptrendsClient, site24x7Client, directClient, eventBus, cfg.Environment, auditLogger, cfg.EncryptionKey, cfg.RequireDomainVerification, logger)
+	domainService.SetAckTimeout(time.Duration(cfg.IncidentAckTimeout) * time.Minute)
+	deepCheckService := service.NewDeepCheckService(db, cfg.DeepCheckEscalationFailures)
+	deepCheckClient := deepcheck.NewDeepCheckClient(cfg.DeepCheckBaseURL)
 	promptService := service.NewTelegramPromptService(db)
-	telegramService := notification.NewTelegramService(telegramConfig, db, promptService)
-	emailService := notification.NewEmailService(emailConfig, db, promptService)
-	monitorService := monitor.NewMonitorService(uptrendsClient, site24x7Client, domainService, telegramService, emailService, deepCheckService)
+	telegramService := notification.NewTelegramService(telegramConfig, db, promptService, logger)
+	emailService := notification.NewEmailService(emailConfig, db, promptService, logger)
+	suppressionStore := notification.NewSuppressionStore(cfg.NotificationSuppressionStore, db)
+	telegramService.SetSuppressionStore(suppressionStore)
+	emailService.SetSuppressionStore(suppressionStore)
+	telegramService.SetRateLimit(cfg.NotificationRateLimit)
+	emailService.SetRateLimit(cfg.NotificationRateLimit)
+	notifiers := notification.NewFanout(telegramService, emailService)
+	notifiers.SubscribeAlerts(eventBus)
+	monitorService := monitor.NewMonitorService(uptrendsClient, site24x7Client, directClient, domainService, deepCheckService, deepCheckClient, cfg.RecoveryConfirmations,
+		monitor.LoadShedding{BacklogThreshold: cfg.CheckBacklogThreshold, SampleMinInterval: cfg.SheddingMinInterval}, logger)
+	retentionService := service.NewRetentionService(db)
+	statusPageService := notification.NewStatusPageService(db, eventBus, cfg.EncryptionKey)
+	if sealed, err := statusPageService.EncryptStoredAPIKeys(); err != nil {
+		logger.Error("Failed to encrypt stored status page api keys", "error", err)
+	} else if sealed > 0 {
+		logger.Info("Encrypted stored status page api keys", "count", sealed)
+	}
+	configValidationService := service.NewConfigValidationService(db)
+	routingService := notification.NewRoutingService(db, notifiers)
+	escalationService := notification.NewEscalationService(db, notifiers, domainService)
+	historyService := notification.NewHistoryService(db, notifiers, domainService)
+	billingService := service.NewBillingService(db, eventBus)
+	dnsService := dns.NewDNSService(db, eventBus, notifiers)
+	probeService := probe.NewProbeService(db, eventBus, notifiers)
+	exportService := export.NewExportService(db, cfg.EncryptionKey)
+	exportService.SetAccountExportMailer(emailService, cfg.PublicBaseURL)
+	reportService := report.NewReportService(db)
+	trialService := trial.NewTrialService(db, domainService, notifiers)
+	healthService := health.NewHealthService(db, uptrendsClient, site24x7Client, telegramService)
 
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(authService)
-	domainHandler := handler.NewDomainHandler(domainService)
-	telegramHandler := handler.NewTelegramHandler(telegramService)
-	telegramBotHandler := handler.NewTelegramBotHandler(telegramService, domainService)
+	accountHandler := handler.NewAccountHandler(authService, domainService)
+	domainHandler := handler.NewDomainHandler(domainService, dnsService)
+	telegramHandler := handler.NewTelegramHandler(telegramService, configValidationService)
+	telegramBotHandler := handler.NewTelegramBotHandler(telegramService, domainService, deepCheckService, deepCheckClient, cfg.TelegramWebhookSecret)
 	promptHandler := handler.NewTelegramPromptHandler(promptService)
-	emailHandler := handler.NewEmailHandler(emailService)
-	callbackHandler := handler.NewCallbackHandler(domainService, telegramService, emailService, deepCheckService)
-	// monitorHandler := handler.NewMonitorHandler(monitorService)
+	emailHandler := handler.NewEmailHandler(emailService, configValidationService)
+	callbackHandler := handler.NewCallbackHandler(domainService, notifiers, deepCheckService, telegramService, cfg.CallbackSecret)
+	retentionHandler := handler.NewRetentionHandler(retentionService)
+	statusPageHandler := handler.NewStatusPageHandler(statusPageService)
+	notificationHandler := handler.NewNotificationHandler(telegramService, emailService, configValidationService, routingService, escalationService, historyService)
+	billingHandler := handler.NewBillingHandler(billingService)
+	providerUsageHandler := handler.NewProviderUsageHandler(providerUsageService)
+	trialHandler := handler.NewTrialHandler(trialService)
+	probeHandler := handler.NewProbeHandler(probeService)
+	deepCheckHandler := handler.NewDeepCheckHandler(deepCheckService, domainService, deepCheckClient)
+	runbookHandler := handler.NewRunbookHandler(domainService)
+	incidentHandler := handler.NewIncidentHandler(domainService)
+	maintenanceHandler := handler.NewMaintenanceHandler(domainService)
+	exportHandler := handler.NewExportHandler(exportService)
+	reportHandler := handler.NewReportHandler(reportService)
+	publicPageHandler := handler.NewPublicPageHandler(reportService)
+	healthHandler := handler.NewHealthHandler(healthService)
+	monitorHandler := handler.NewMonitorHandler(monitorService)
 
-	// Start the scheduled domain check in a goroutine
-	go func() {
-		monitorService.RunScheduledChecks()
-	}()
+	// Schedulers stop when SIGINT or SIGTERM cancels ctx
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var schedulers sync.WaitGroup
+	startScheduler := func(run func(ctx context.Context)) {
+		schedulers.Add(1)
+		go func() {
+			defer schedulers.Done()
+			run(ctx)
+		}()
+	}
+
+	// Start the scheduled domain check
+	startScheduler(monitorService.RunScheduledChecks)
+
+	// Write out the provider API usage counts
+	startScheduler(providerUsageService.RunScheduledUsageFlush)
+
+	// Keep the cached Uptrends checkpoint IDs fresh
+	startScheduler(uptrendsClient.RunScheduledCheckpointRefresh)
+
+	// Keep provider monitor active flags in line with the database
+	if cfg.MonitorSyncInterval > 0 {
+		startScheduler(func(ctx context.Context) {
+			monitorService.RunScheduledStatusSync(ctx, time.Duration(cfg.MonitorSyncInterval)*time.Minute)
+		})
+	}
+
+	// Fail deep check orders whose callback never arrives
+	startScheduler(func(ctx context.Context) {
+		notification.RunScheduledDeepCheckTimeouts(ctx, deepCheckService, notifiers, telegramService, cfg.DeepCheckTimeoutMinutes)
+	})
+
+	// Start the daily data retention pruner
+	startScheduler(retentionService.RunScheduledRetention)
+
+	// Start the hourly trial expiry job
+	startScheduler(trialService.RunScheduledTrialChecks)
+
+	// Deliver notification digests
+	startScheduler(func(ctx context.Context) {
+		notification.RunScheduledDigests(ctx, telegramService, emailService)
+	})
+
+	// Deliver weekly and monthly email reports
+	startScheduler(func(ctx context.Context) {
+		notification.RunScheduledEmailReports(ctx, emailService)
+	})
+
+	// Escalate prolonged outages to extra configs
+	startScheduler(escalationService.RunScheduledEscalations)
+
+	// Start the TLS/HTTP capability prober
+	startScheduler(probeService.RunScheduledProbes)
+
+	// Check domain registration expiry (WHOIS/RDAP)
+	startScheduler(probeService.RunScheduledRegistrationChecks)
+
+	// Start the daily internal check of archived domains
+	startScheduler(domainService.RunScheduledArchiveChecks)
+
+	// Purge domains that have been in the trash for 30 days
+	startScheduler(domainService.RunScheduledTrashPurge)
+
+	// Retry failed monitor creations and deletions
+	startScheduler(domainService.RunScheduledMonitorTasks)
+
+	// Resume account deletions interrupted by a restart
+	startScheduler(domainService.RunScheduledAccountDeletions)
+
+	// Delete orphaned provider monitors and recreate missing ones
+	startScheduler(domainService.RunScheduledReconciliation)
+
+	// Deliver daily data exports to customer SFTP servers
+	startScheduler(exportService.RunScheduledExports)
 
 	// Set up Gin router
-	router := gin.Default()
+	router := gin.New()
+	router.Use(middleware.RequestLogger(logger), gin.Recovery())
 
 	// Apply comprehensive CORS middleware
 	corsConfig := cors.Config{
 		AllowOrigins:     []string{"*"}, // Your Vue frontend URL
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With"},
-		ExposeHeaders:    []string{"Content-Length", "Content-Type"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", "X-Request-ID"},
+		ExposeHeaders:    []string{"Content-Length", "Content-Type", "X-Request-ID"},
 		AllowCredentials: true,
 		MaxAge:           86400, // 24 hours
 	}
 	router.Use(cors.New(corsConfig))
 
+	// Load balancer probes
+	router.GET("/healthz", healthHandler.Healthz)
+	router.GET("/readyz", healthHandler.Readyz)
+
 	// Public routes
 	router.POST("/api/login", authHandler.Login)
 	router.POST("/api/register", authHandler.Register)
+	router.POST("/api/token/refresh", authHandler.RefreshToken)
 	router.GET("/api/regions", authHandler.GetRegions)
 
-	// Add webhook endpoint for Telegram bot (public, no auth required)
+	// Add webhook endpoint for Telegram bot (public, authenticated by the webhook secret)
 	router.POST("/api/telegram/webhook", telegramBotHandler.WebhookHandler)
 
 	// Add simple callback endpoint (no authentication)
 	router.POST("/api/callback", callbackHandler.HandleCallback)
 
+	// Data export downloads, authorized by the token in the link mailed to the user
+	router.GET("/api/user/export/:token", exportHandler.DownloadAccountExport)
+
+	// Hosted status pages shared by users with their own clients
+	router.GET("/status/:publicSlug", publicPageHandler.GetPublicPage)
+
 	// Protected routes
 	protected := router.Group("/api")
-	protected.Use(middleware.JWTAuthMiddleware(cfg.JWTSecret))
+	protected.Use(middleware.APIKeyOrJWTAuthMiddleware(cfg.JWTSecret, authService, authService))
+	protected.Use(middleware.ImpersonationMiddleware(authService, authService))
+	protected.Use(middleware.AccountDeletionMiddleware(authService))
+	protected.Use(middleware.TrialRestrictionMiddleware(trialService))
 	{
 		// 2FA routes
 		protected.POST("/2fa/setup", authHandler.SetupTwoFactor)
 		protected.POST("/2fa/verify", authHandler.VerifyTwoFactor)
 		protected.POST("/2fa/disable", authHandler.DisableTwoFactor)
+		protected.GET("/2fa/recovery-codes", authHandler.GetRecoveryCodes)
+		protected.POST("/2fa/recovery-codes", authHandler.RegenerateRecoveryCodes)
 
 		// User profile
 		protected.GET("/user/profile", authHandler.GetUserProfile)
 		protected.PUT("/user/password", authHandler.UpdatePassword)
+		protected.PUT("/user/timezone", authHandler.UpdateTimezone)
+
+		// Account deletion, run in the background
+		protected.DELETE("/user", accountHandler.DeleteAccount)
+		protected.GET("/user/deletion", accountHandler.GetAccountDeletion)
+
+		// Archive of all user data, mailed as a download link
+		protected.GET("/user/export", exportHandler.RequestAccountExport)
+
+		// API keys for programmatic domain management
+		protected.POST("/apikeys", authHandler.CreateAPIKey)
+		protected.GET("/apikeys", authHandler.GetAPIKeys)
+		protected.DELETE("/apikeys/:id", authHandler.DeleteAPIKey)
+
+		// Login sessions
+		protected.POST("/logout", authHandler.Logout)
+		protected.GET("/sessions", authHandler.GetSessions)
+		protected.DELETE("/sessions/:id", authHandler.RevokeSession)
+		protected.GET("/user/trial", trialHandler.GetTrialStatus)
+		protected.GET("/user/deep-check-escalation", deepCheckHandler.GetEscalationSettings)
+		protected.PUT("/user/deep-check-escalation", deepCheckHandler.UpdateEscalationSettings)
+		protected.GET("/user/monitor-providers", domainHandler.GetUserProviders)
+		protected.PUT("/user/monitor-providers", domainHandler.SetUserProviders)
+		protected.GET("/user/settings", domainHandler.GetUserSettings)
+		protected.PUT("/user/settings", domainHandler.UpdateUserSettings)
+
+		// Dashboard summary
+		protected.GET("/summary", domainHandler.GetSummary)
 
 		// Domain management routes
 		protected.GET("/domains", domainHandler.GetDomains)
+		protected.GET("/domains/export", domainHandler.ExportDomains)
+		protected.GET("/domains/trash", domainHandler.GetTrash)
 		protected.GET("/domains/:id", domainHandler.GetDomain)
+		protected.GET("/domains/:id/history", domainHandler.GetDomainHistory)
+		protected.GET("/domains/:id/tls-history", probeHandler.GetTLSHistory)
+		protected.GET("/domains/:id/registration", probeHandler.GetRegistration)
+		protected.GET("/domains/:id/dns-history", domainHandler.GetDNSHistory)
+		protected.POST("/domains/:id/deepcheck", deepCheckHandler.RequestDeepCheck)
+		protected.GET("/domains/:id/incidents", incidentHandler.GetDomainIncidents)
+		protected.GET("/domains/:id/maintenance", maintenanceHandler.GetDomainMaintenanceWindows)
+		protected.POST("/domains/:id/maintenance", maintenanceHandler.CreateDomainMaintenanceWindow)
+		protected.GET("/domains/:id/uptime", reportHandler.GetDomainUptime)
+		protected.GET("/domains/:id/stats", reportHandler.GetDomainStats)
+		protected.GET("/domains/:id/subdomains", domainHandler.GetSubdomains)
 		protected.POST("/domains", domainHandler.AddDomain)
+		protected.PUT("/domains", domainHandler.UpsertDomain)
 		protected.PUT("/domains/:id", domainHandler.UpdateDomain)
 		protected.PUT("/domains/batch", domainHandler.UpdateAllDomains)
+		protected.PUT("/domains/batch-selected", domainHandler.UpdateSelectedDomains)
 		protected.DELETE("/domains/:id", domainHandler.DeleteDomain)
+		protected.POST("/domains/:id/archive", domainHandler.ArchiveDomain)
+		protected.POST("/domains/:id/unarchive", domainHandler.UnarchiveDomain)
+		protected.POST("/domains/:id/snooze", domainHandler.SnoozeDomain)
+		protected.POST("/domains/:id/subdomains/discover", domainHandler.DiscoverSubdomains)
+		protected.DELETE("/domains/:id/snooze", domainHandler.UnsnoozeDomain)
+		protected.POST("/domains/:id/restore", domainHandler.RestoreDomain)
+		protected.POST("/domains/:id/monitors/recreate", domainHandler.RecreateMonitors)
+		protected.POST("/domains/:id/monitor-tasks/retry", domainHandler.RetryMonitorTasks)
+		protected.GET("/domains/:id/verification", domainHandler.GetDomainVerification)
+		protected.POST("/domains/:id/verify", domainHandler.VerifyDomain)
+		protected.GET("/domains/:id/direct-check", domainHandler.GetDirectCheck)
+		protected.PUT("/domains/:id/direct-check", domainHandler.UpdateDirectCheck)
+		protected.GET("/domains/:id/tags", domainHandler.GetDomainTags)
+		protected.PUT("/domains/:id/tags", domainHandler.SetDomainTags)
+		protected.GET("/domains/:id/providers", domainHandler.GetDomainProviders)
+		protected.PUT("/domains/:id/providers", domainHandler.SetDomainProviders)
+		protected.GET("/domains/:id/runbook", runbookHandler.GetDomainRunbook)
+		protected.PUT("/domains/:id/runbook", runbookHandler.SetDomainRunbook)
+		protected.DELETE("/domains/:id/runbook", runbookHandler.DeleteDomainRunbook)
+		protected.GET("/runbooks", runbookHandler.GetRunbooks)
+		protected.PUT("/runbooks/regions/:region", runbookHandler.SetRegionRunbook)
+		protected.DELETE("/runbooks/regions/:region", runbookHandler.DeleteRegionRunbook)
 		protected.POST("/domains/batch", domainHandler.AddBatchDomains)
+		protected.POST("/domains/import", domainHandler.ImportDomains)
 		protected.DELETE("/domains/batch", domainHandler.DeleteBatchDomains)
 		protected.DELETE("/domains", domainHandler.DeleteAllDomains)
 
+		// Deep check orders
+		protected.GET("/deep-checks", deepCheckHandler.GetDeepChecks)
+		protected.GET("/deepchecks/:orderID", deepCheckHandler.GetDeepCheck)
+
+		// Incidents
+		protected.GET("/incidents", incidentHandler.GetIncidents)
+
+		// Maintenance windows
+		protected.GET("/maintenance", maintenanceHandler.GetMaintenanceWindows)
+		protected.POST("/maintenance", maintenanceHandler.CreateMaintenanceWindow)
+		protected.DELETE("/maintenance/:id", maintenanceHandler.DeleteMaintenanceWindow)
+
+		// Uptime and SLA reports
+		protected.GET("/reports/uptime", reportHandler.GetUptimeReport)
+
+		// Scheduled data exports to customer SFTP servers
+		protected.GET("/exports/targets", exportHandler.GetTargets)
+		protected.POST("/exports/targets", exportHandler.AddTarget)
+		protected.PUT("/exports/targets/:id", exportHandler.UpdateTarget)
+		protected.DELETE("/exports/targets/:id", exportHandler.DeleteTarget)
+		protected.GET("/exports/deliveries", exportHandler.GetDeliveries)
+
 		// Set up Telegram API routes
 		telegramRoutes := protected.Group("/telegram")
 		{
 			telegramRoutes.GET("/bot", telegramHandler.GetBotInfo)
+			telegramRoutes.POST("/link-code", telegramHandler.CreateLinkCode)
 			telegramRoutes.GET("/configs", telegramHandler.GetTelegramConfigs)
 			telegramRoutes.POST("/configs", telegramHandler.AddTelegramConfig)
 			telegramRoutes.PUT("/configs/:id", telegramHandler.UpdateTelegramConfig)
 			telegramRoutes.DELETE("/configs/:id", telegramHandler.DeleteTelegramConfig)
+			telegramRoutes.GET("/configs/external/:external_id", telegramHandler.GetTelegramConfigByExternalID)
+			telegramRoutes.PUT("/configs/external/:external_id", telegramHandler.UpsertTelegramConfigByExternalID)
 
 			// Add this new route for sending test messages
 			telegramRoutes.POST("/configs/:id/test", telegramHandler.SendTestMessage)
@@ -157,9 +462,61 @@ func main() {
 			emailRoutes.POST("/configs", emailHandler.AddEmailConfig)
 			emailRoutes.PUT("/configs/:id", emailHandler.UpdateEmailConfig)
 			emailRoutes.DELETE("/configs/:id", emailHandler.DeleteEmailConfig)
+			emailRoutes.GET("/configs/external/:external_id", emailHandler.GetEmailConfigByExternalID)
+			emailRoutes.PUT("/configs/external/:external_id", emailHandler.UpsertEmailConfigByExternalID)
 			emailRoutes.POST("/configs/:id/test", emailHandler.SendTestEmail)
+			emailRoutes.POST("/configs/:id/verify", emailHandler.VerifyEmailConfig)
+			emailRoutes.POST("/configs/:id/verify/resend", emailHandler.ResendVerification)
+			emailRoutes.GET("/configs/:id/report", emailHandler.GetReportSettings)
+			emailRoutes.PUT("/configs/:id/report", emailHandler.UpdateReportSettings)
+			emailRoutes.POST("/configs/:id/report/send", emailHandler.SendReport)
 		}
 
+		// Bulk notification config provisioning across channels
+		protected.POST("/notifications/configs/batch", notificationHandler.BatchAddConfigs)
+
+		// Digest-only delivery per notification config
+		protected.GET("/notifications/configs/:channel/:id/digest", notificationHandler.GetDigestSettings)
+		protected.PUT("/notifications/configs/:channel/:id/digest", notificationHandler.UpdateDigestSettings)
+		protected.GET("/notifications/configs/:channel/:id/languages", notificationHandler.GetLanguageFallbacks)
+		protected.PUT("/notifications/configs/:channel/:id/languages", notificationHandler.UpdateLanguageFallbacks)
+		protected.GET("/notifications/configs/:channel/:id/tags", notificationHandler.GetConfigTags)
+		protected.PUT("/notifications/configs/:channel/:id/tags", notificationHandler.UpdateConfigTags)
+		protected.GET("/notifications/configs/:channel/:id/quiet-hours", notificationHandler.GetQuietHours)
+		protected.PUT("/notifications/configs/:channel/:id/quiet-hours", notificationHandler.UpdateQuietHours)
+		protected.GET("/notifications/configs/:channel/:id/timezone", notificationHandler.GetConfigTimezone)
+		protected.PUT("/notifications/configs/:channel/:id/timezone", notificationHandler.UpdateConfigTimezone)
+
+		// Per-region alert routing
+		protected.GET("/notifications/routing-rules", notificationHandler.GetRoutingRules)
+		protected.POST("/notifications/routing-rules", notificationHandler.AddRoutingRule)
+		protected.DELETE("/notifications/routing-rules/:id", notificationHandler.DeleteRoutingRule)
+
+		// Delivery history and resending of failed notifications
+		protected.GET("/notifications/history", notificationHandler.GetHistory)
+		protected.POST("/notifications/:id/resend", notificationHandler.ResendNotification)
+
+		// Escalation levels for prolonged outages
+		protected.GET("/notifications/escalation-rules", notificationHandler.GetEscalationRules)
+		protected.POST("/notifications/escalation-rules", notificationHandler.AddEscalationRule)
+		protected.DELETE("/notifications/escalation-rules/:id", notificationHandler.DeleteEscalationRule)
+
+		// Status page integration routes
+		statusPageRoutes := protected.Group("/status-pages")
+		{
+			statusPageRoutes.GET("/configs", statusPageHandler.GetConfigs)
+			statusPageRoutes.POST("/configs", statusPageHandler.AddConfig)
+			statusPageRoutes.PUT("/configs/:id", statusPageHandler.UpdateConfig)
+			statusPageRoutes.DELETE("/configs/:id", statusPageHandler.DeleteConfig)
+
+			// Hosted page served at /status/:publicSlug
+			statusPageRoutes.GET("/public", publicPageHandler.GetSettings)
+			statusPageRoutes.PUT("/public", publicPageHandler.UpdateSettings)
+		}
+
+		// Monthly usage statement for the current user
+		protected.GET("/billing/statement", billingHandler.GetStatement)
+
 		// prompt management routes
 		protected.GET("/telegram-prompts", promptHandler.GetPrompts)
 		protected.GET("/telegram-prompts/:id", promptHandler.GetPrompt)
@@ -168,21 +525,77 @@ func main() {
 		protected.DELETE("/telegram-prompts/:id", promptHandler.DeletePrompt)
 
 		// Admin routes
-		admin := protected.Group("/admin")
-		// TODO: Add admin middleware
-		{
-			admin.PUT("/settings/domain-limit", domainHandler.UpdateDomainLimit)
-		}
+		admin := protected.Group("/admin", middleware.AdminMiddleware(authService))
+		registerAdminRoutes(admin, adminHandlers{
+			domain:        domainHandler,
+			monitor:       monitorHandler,
+			providerUsage: providerUsageHandler,
+			retention:     retentionHandler,
+			billing:       billingHandler,
+			trial:         trialHandler,
+			auth:          authHandler,
+		})
 	}
 
 	// Start server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	port := cfg.Port
+
+	server := &http.Server{
+		Addr:    ":" + port,
+		Handler: router,
+	}
+
+	go func() {
+		logger.Info("Starting server", "port", port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	logger.Info("Shutting down, waiting for requests and checks in progress", "timeout", shutdownTimeout.String())
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Server shutdown failed", "error", err)
+	}
+
+	// Wait for the schedulers to return, then for the queued alerts and the background
+	// work they and the handlers started
+	done := make(chan struct{})
+	go func() {
+		schedulers.Wait()
+		eventBus.Close()
+		monitorService.Wait()
+		domainService.Wait()
+		exportService.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		logger.Info("Background work finished")
+	case <-shutdownCtx.Done():
+		logger.Warn("Shutdown timed out, abandoning background work in progress")
 	}
 
-	log.Printf("Starting server on port %s", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	monitorService.Close()
+	directClient.Close()
+	emailService.Close()
+	logger.Info("Server stopped")
+}
+
+// runMigrations applies the embedded schema migrations, exiting when one fails
+func runMigrations(db *sqlx.DB) {
+	applied, err := migrate.Up(context.Background(), db.DB, migrations.FS)
+	if err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+	version, err := migrate.Version(context.Background(), db.DB)
+	if err != nil {
+		log.Fatalf("Failed to get schema version: %v", err)
 	}
+	slog.Info("Database schema migrated", "version", version, "applied", applied)
 }
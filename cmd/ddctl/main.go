@@ -0,0 +1,211 @@
+// Command ddctl is a small command line wrapper around pkg/client.
+//
+// The API address and token are read from DD_API_URL and DD_TOKEN. Run
+// "ddctl login" to obtain a token.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"domain-detection-go/pkg/client"
+	"domain-detection-go/pkg/model"
+)
+
+const usage = `Usage: ddctl <command> [flags]
+
+Commands:
+  login -u <username> -p <password> [-totp <code>]
+  domains list
+  domains add -region <code> [-interval <minutes>] [-deep] <domain>...
+  domains rm <id>...
+  notifications list
+  deep-checks list [-domain <id>] [-limit <n>]
+
+Environment:
+  DD_API_URL  API base URL (default http://localhost:8080)
+  DD_TOKEN    JWT returned by "ddctl login"
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	baseURL := os.Getenv("DD_API_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+	c := client.New(baseURL, client.WithToken(os.Getenv("DD_TOKEN")))
+
+	var err error
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "login":
+		err = runLogin(c, args)
+	case "domains":
+		err = runDomains(c, args)
+	case "notifications":
+		err = runNotifications(c, args)
+	case "deep-checks":
+		err = runDeepChecks(c, args)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ddctl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runLogin(c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("login", flag.ExitOnError)
+	username := fs.String("u", "", "username")
+	password := fs.String("p", "", "password")
+	totp := fs.String("totp", "", "two-factor code")
+	fs.Parse(args)
+
+	if *username == "" || *password == "" {
+		return fmt.Errorf("-u and -p are required")
+	}
+
+	resp, err := c.Login(*username, *password, *totp)
+	if err != nil {
+		return err
+	}
+	fmt.Println(resp.Token)
+	return nil
+}
+
+func runDomains(c *client.Client, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing domains subcommand")
+	}
+
+	switch args[0] {
+	case "list":
+		resp, err := c.ListDomains()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tREGION\tACTIVE\tSTATUS\tLAST CHECK")
+		for _, d := range resp.Domains {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%t\t%d\t%s\n", d.ID, d.Name, d.Region, d.Active, d.LastStatus,
+				d.LastCheck.Format("2006-01-02 15:04:05"))
+		}
+		w.Flush()
+		fmt.Printf("%d of %d domains used\n", resp.TotalDomains, resp.DomainLimit)
+		return nil
+
+	case "add":
+		fs := flag.NewFlagSet("domains add", flag.ExitOnError)
+		region := fs.String("region", "", "region code")
+		interval := fs.Int("interval", 0, "check interval in minutes")
+		deep := fs.Bool("deep", false, "enable deep checks")
+		fs.Parse(args[1:])
+
+		if *region == "" || fs.NArg() == 0 {
+			return fmt.Errorf("-region and at least one domain are required")
+		}
+
+		req := model.DomainBatchAddRequest{Interval: *interval}
+		for _, name := range fs.Args() {
+			req.Domains = append(req.Domains, model.DomainBatchItem{Name: name, Region: *region, IsDeepCheck: *deep})
+		}
+		resp, err := c.AddDomains(req)
+		for _, r := range resp.Success {
+			fmt.Printf("added %s (id %d)\n", r.Name, r.ID)
+		}
+		for _, r := range resp.Failed {
+			fmt.Printf("failed %s: %s\n", r.Name, r.Reason)
+		}
+		return err
+
+	case "rm":
+		ids, err := parseIDs(args[1:])
+		if err != nil {
+			return err
+		}
+		resp, err := c.DeleteDomains(ids)
+		for _, r := range resp.Success {
+			fmt.Printf("deleted %s (id %d)\n", r.Name, r.ID)
+		}
+		for _, r := range resp.Failed {
+			fmt.Printf("failed %d: %s\n", r.ID, r.Reason)
+		}
+		return err
+	}
+
+	return fmt.Errorf("unknown domains subcommand %q", args[0])
+}
+
+func runNotifications(c *client.Client, args []string) error {
+	if len(args) == 0 || args[0] != "list" {
+		return fmt.Errorf("usage: ddctl notifications list")
+	}
+
+	telegramConfigs, err := c.ListTelegramConfigs()
+	if err != nil {
+		return err
+	}
+	emailConfigs, err := c.ListEmailConfigs()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "CHANNEL\tID\tTARGET\tACTIVE\tDOWN\tUP")
+	for _, cfg := range telegramConfigs {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%t\t%t\t%t\n", client.ChannelTelegram, cfg.ID, cfg.ChatID,
+			cfg.IsActive, cfg.NotifyOnDown, cfg.NotifyOnUp)
+	}
+	for _, cfg := range emailConfigs {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%t\t%t\t%t\n", client.ChannelEmail, cfg.ID, cfg.EmailAddress,
+			cfg.IsActive, cfg.NotifyOnDown, cfg.NotifyOnUp)
+	}
+	return w.Flush()
+}
+
+func runDeepChecks(c *client.Client, args []string) error {
+	if len(args) == 0 || args[0] != "list" {
+		return fmt.Errorf("usage: ddctl deep-checks list [-domain <id>] [-limit <n>]")
+	}
+
+	fs := flag.NewFlagSet("deep-checks list", flag.ExitOnError)
+	domainID := fs.Int("domain", 0, "only list orders for this domain ID")
+	limit := fs.Int("limit", 50, "maximum number of orders")
+	fs.Parse(args[1:])
+
+	orders, err := c.ListDeepChecks(*domainID, *limit)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ORDER\tDOMAIN\tSTATUS\tCREATED")
+	for _, o := range orders {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", o.OrderID, o.DomainName, o.Status, o.CreatedAt.Format("2006-01-02 15:04:05"))
+	}
+	return w.Flush()
+}
+
+func parseIDs(args []string) ([]int, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("at least one ID is required")
+	}
+	ids := make([]int, 0, len(args))
+	for _, arg := range args {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ID %q", arg)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,180 @@
+// Command rotatekeys re-encrypts every secret stored in the database under the
+// current ENCRYPTION_KEY: 2FA secrets, basic auth passwords of domain checks, SFTP
+// credentials of export targets and API keys of status page integrations.
+//
+// To rotate the key, set ENCRYPTION_KEY to the new key and pass the old one with
+// -old-key or OLD_ENCRYPTION_KEY. Without an old key, secrets are re-encrypted under
+// the current key, which upgrades 2FA secrets still using the legacy CBC format.
+// Everything runs in one transaction, so a wrong old key changes nothing.
+package main
+
+import (
+	"database/sql"
+	"encoding/base32"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+
+	"domain-detection-go/internal/auth"
+	"domain-detection-go/internal/credentials"
+	"domain-detection-go/pkg/config"
+)
+
+func main() {
+	oldKey := flag.String("old-key", os.Getenv("OLD_ENCRYPTION_KEY"), "encryption key the secrets are currently stored under")
+	dryRun := flag.Bool("dry-run", false, "decrypt and re-encrypt without committing")
+	flag.Parse()
+
+	cfg := config.LoadConfig()
+	if *oldKey == "" {
+		*oldKey = cfg.EncryptionKey
+	}
+
+	db, err := sqlx.Connect("postgres", cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Beginx()
+	if err != nil {
+		log.Fatalf("Failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	r := rotator{tx: tx, oldKey: *oldKey, newKey: cfg.EncryptionKey}
+	steps := []struct {
+		name string
+		run  func() (int, error)
+	}{
+		{"2FA secrets", r.rotateTOTPSecrets},
+		{"basic auth passwords", func() (int, error) {
+			return r.rotateColumn("domains", "basic_auth_password")
+		}},
+		{"export passwords", func() (int, error) {
+			return r.rotateColumn("export_targets", "password_encrypted")
+		}},
+		{"export private keys", func() (int, error) {
+			return r.rotateColumn("export_targets", "private_key_encrypted")
+		}},
+		{"status page api keys", r.rotateStatusPageKeys},
+	}
+	for _, step := range steps {
+		count, err := step.run()
+		if err != nil {
+			log.Fatalf("Failed to rotate %s: %v", step.name, err)
+		}
+		log.Printf("Re-encrypted %d %s", count, step.name)
+	}
+
+	if *dryRun {
+		log.Printf("Dry run, rolling back")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		log.Fatalf("Failed to commit: %v", err)
+	}
+	log.Printf("Key rotation complete")
+}
+
+// rotator re-encrypts secrets from the old key to the new one inside a transaction
+type rotator struct {
+	tx     *sqlx.Tx
+	oldKey string
+	newKey string
+}
+
+// rotateTOTPSecrets re-encrypts 2FA secrets in the current format
+func (r rotator) rotateTOTPSecrets() (int, error) {
+	var users []struct {
+		ID     int            `db:"id"`
+		Secret sql.NullString `db:"two_factor_secret"`
+	}
+	if err := r.tx.Select(&users, "SELECT id, two_factor_secret FROM users WHERE two_factor_secret IS NOT NULL"); err != nil {
+		return 0, err
+	}
+
+	for _, u := range users {
+		secret, err := auth.DecryptTOTPSecret(u.Secret, r.oldKey)
+		if err != nil {
+			return 0, fmt.Errorf("user %d: %w", u.ID, err)
+		}
+		// The legacy format decrypts to garbage under a wrong key instead of failing
+		if _, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret.String); err != nil {
+			return 0, fmt.Errorf("user %d: secret does not decrypt under the old key", u.ID)
+		}
+
+		encrypted, err := auth.EncryptTOTPSecret(secret.String, r.newKey)
+		if err != nil {
+			return 0, fmt.Errorf("user %d: %w", u.ID, err)
+		}
+		if _, err := r.tx.Exec("UPDATE users SET two_factor_secret = $1 WHERE id = $2", encrypted, u.ID); err != nil {
+			return 0, fmt.Errorf("user %d: %w", u.ID, err)
+		}
+	}
+	return len(users), nil
+}
+
+// rotateStatusPageKeys re-encrypts the API keys of status page integrations. Keys still
+// stored in plaintext are sealed under the new key.
+func (r rotator) rotateStatusPageKeys() (int, error) {
+	var configs []struct {
+		ID        int    `db:"id"`
+		APIKey    string `db:"api_key"`
+		Encrypted bool   `db:"api_key_encrypted"`
+	}
+	if err := r.tx.Select(&configs, "SELECT id, api_key, api_key_encrypted FROM status_page_configs"); err != nil {
+		return 0, err
+	}
+
+	for _, c := range configs {
+		plaintext := c.APIKey
+		if c.Encrypted {
+			var err error
+			if plaintext, err = credentials.Open(c.APIKey, r.oldKey); err != nil {
+				return 0, fmt.Errorf("status page config %d: %w", c.ID, err)
+			}
+		}
+		sealed, err := credentials.Seal(plaintext, r.newKey)
+		if err != nil {
+			return 0, fmt.Errorf("status page config %d: %w", c.ID, err)
+		}
+		_, err = r.tx.Exec("UPDATE status_page_configs SET api_key = $1, api_key_encrypted = true WHERE id = $2", sealed, c.ID)
+		if err != nil {
+			return 0, fmt.Errorf("status page config %d: %w", c.ID, err)
+		}
+	}
+	return len(configs), nil
+}
+
+// rotateColumn re-encrypts a column holding values sealed by the credentials package
+func (r rotator) rotateColumn(table, column string) (int, error) {
+	var rows []struct {
+		ID     int    `db:"id"`
+		Sealed string `db:"sealed"`
+	}
+	query := fmt.Sprintf("SELECT id, %s AS sealed FROM %s WHERE COALESCE(%s, '') <> ''", column, table, column)
+	if err := r.tx.Select(&rows, query); err != nil {
+		return 0, err
+	}
+
+	update := fmt.Sprintf("UPDATE %s SET %s = $1 WHERE id = $2", table, column)
+	for _, row := range rows {
+		plaintext, err := credentials.Open(row.Sealed, r.oldKey)
+		if err != nil {
+			return 0, fmt.Errorf("%s %d: %w", table, row.ID, err)
+		}
+		sealed, err := credentials.Seal(plaintext, r.newKey)
+		if err != nil {
+			return 0, fmt.Errorf("%s %d: %w", table, row.ID, err)
+		}
+		if _, err := r.tx.Exec(update, sealed, row.ID); err != nil {
+			return 0, fmt.Errorf("%s %d: %w", table, row.ID, err)
+		}
+	}
+	return len(rows), nil
+}
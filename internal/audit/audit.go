@@ -0,0 +1,47 @@
+// Package audit records actions in the audit_logs table
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Actions recorded in the audit log
+const (
+	ActionMonitorsRecreated = "monitors_recreated"
+	ActionDomainVerified    = "domain_verified"
+	ActionDomainTransferred = "domain_transferred"
+	// Support staff acting as a user: the token being issued and each request made with it
+	ActionImpersonationStarted = "impersonation_started"
+	ActionImpersonatedRequest  = "impersonated_request"
+)
+
+// Logger writes audit log entries
+type Logger struct {
+	db *sqlx.DB
+}
+
+// NewLogger creates a new audit logger
+func NewLogger(db *sqlx.DB) *Logger {
+	return &Logger{db: db}
+}
+
+// Record stores an action taken by a user. domainID is 0 for actions that do not
+// concern a single domain; details is stored as JSON.
+func (l *Logger) Record(userID, domainID int, action string, details interface{}) error {
+	payload, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit details: %w", err)
+	}
+
+	_, err = l.db.Exec(`
+        INSERT INTO audit_logs (user_id, action, domain_id, details, created_at)
+        VALUES ($1, $2, NULLIF($3, 0), $4, NOW())
+    `, userID, action, domainID, payload)
+	if err != nil {
+		return fmt.Errorf("failed to record audit log: %w", err)
+	}
+	return nil
+}
@@ -8,10 +8,13 @@ import (
 	"database/sql"
 	"encoding/base32"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"net/url"
 	"strings"
 
+	"domain-detection-go/internal/credentials"
+
 	"github.com/pquerna/otp/totp"
 )
 
@@ -52,32 +55,17 @@ func ValidateTOTP(secret sql.NullString, code string) bool {
 	return totp.Validate(code, secretStr)
 }
 
+// totpSecretV1 prefixes secrets sealed with AES-GCM. Secrets without a version prefix
+// use the original CBC format and are still readable until re-encrypted.
+const totpSecretV1 = "v1:"
+
 // EncryptTOTPSecret encrypts the TOTP secret before storing in database
 func EncryptTOTPSecret(secret, encryptionKey string) (string, error) {
-	// Create a fixed-size key from the encryption key using SHA-256
-	hash := sha256.Sum256([]byte(encryptionKey))
-	block, err := aes.NewCipher(hash[:])
+	sealed, err := credentials.Seal(secret, encryptionKey)
 	if err != nil {
 		return "", err
 	}
-
-	// The IV needs to be unique, but not secure
-	// Using a fixed IV is not recommended in production
-	iv := make([]byte, aes.BlockSize)
-	for i := 0; i < len(iv); i++ {
-		iv[i] = byte(i)
-	}
-
-	// Pad the secret to be a multiple of the block size
-	paddedSecret := padSecret(secret, aes.BlockSize)
-
-	// Encrypt the secret
-	encrypted := make([]byte, len(paddedSecret))
-	mode := cipher.NewCBCEncrypter(block, iv)
-	mode.CryptBlocks(encrypted, []byte(paddedSecret))
-
-	// Return hex encoded string
-	return hex.EncodeToString(encrypted), nil
+	return totpSecretV1 + sealed, nil
 }
 
 // DecryptTOTPSecret decrypts the TOTP secret from database
@@ -86,11 +74,28 @@ func DecryptTOTPSecret(encryptedSecret sql.NullString, encryptionKey string) (sq
 		return sql.NullString{Valid: false}, nil
 	}
 
+	if sealed, ok := strings.CutPrefix(encryptedSecret.String, totpSecretV1); ok {
+		secret, err := credentials.Open(sealed, encryptionKey)
+		if err != nil {
+			return sql.NullString{Valid: false}, err
+		}
+		return sql.NullString{String: secret, Valid: true}, nil
+	}
+
+	return decryptLegacyTOTPSecret(encryptedSecret.String, encryptionKey)
+}
+
+// decryptLegacyTOTPSecret decrypts a secret stored with AES-CBC and a fixed IV. A wrong
+// key yields garbage instead of an error.
+func decryptLegacyTOTPSecret(encryptedSecret, encryptionKey string) (sql.NullString, error) {
 	// Decode the hex string
-	encrypted, err := hex.DecodeString(encryptedSecret.String)
+	encrypted, err := hex.DecodeString(encryptedSecret)
 	if err != nil {
 		return sql.NullString{Valid: false}, err
 	}
+	if len(encrypted)%aes.BlockSize != 0 {
+		return sql.NullString{Valid: false}, errors.New("legacy secret is not a multiple of the block size")
+	}
 
 	// Create cipher block
 	hash := sha256.Sum256([]byte(encryptionKey))
@@ -116,16 +121,6 @@ func DecryptTOTPSecret(encryptedSecret sql.NullString, encryptionKey string) (sq
 	return sql.NullString{String: string(unpaddedSecret), Valid: true}, nil
 }
 
-// Helper function to pad the secret to a multiple of blockSize
-func padSecret(secret string, blockSize int) []byte {
-	padding := blockSize - (len(secret) % blockSize)
-	padtext := make([]byte, padding)
-	for i := 0; i < padding; i++ {
-		padtext[i] = byte(padding)
-	}
-	return append([]byte(secret), padtext...)
-}
-
 // Helper function to remove padding
 func unpadSecret(data []byte) []byte {
 	length := len(data)
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+
+	"domain-detection-go/pkg/model"
+)
+
+const (
+	// apiKeyPrefix marks API keys so they are recognisable in configs and logs
+	apiKeyPrefix = "ddk_"
+	// maxAPIKeysPerUser bounds how many keys a user can hold at once
+	maxAPIKeysPerUser = 20
+)
+
+// hashAPIKey returns the stored form of a key. Keys carry 256 bits of randomness, so
+// a plain SHA-256 is enough and allows looking them up by hash.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIKey generates a new API key for the user. The returned response is the only
+// place the full key appears.
+func (s *AuthService) CreateAPIKey(userID int, name string) (*model.APIKeyCreateResponse, error) {
+	var count int
+	if err := s.db.Get(&count, "SELECT COUNT(*) FROM api_keys WHERE user_id = $1", userID); err != nil {
+		return nil, fmt.Errorf("failed to count API keys: %w", err)
+	}
+	if count >= maxAPIKeysPerUser {
+		return nil, ErrAPIKeyLimitReached
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key := apiKeyPrefix + hex.EncodeToString(secret)
+
+	response := &model.APIKeyCreateResponse{Key: key}
+	err := s.db.Get(&response.APIKey, `
+        INSERT INTO api_keys (user_id, name, key_prefix, key_hash)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id, user_id, name, key_prefix, last_used_at, created_at
+    `, userID, name, key[:len(apiKeyPrefix)+8], hashAPIKey(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to store API key: %w", err)
+	}
+
+	slog.Info("Created API key", "key_id", response.ID, "user_id", userID)
+	return response, nil
+}
+
+// GetAPIKeys lists the user's API keys without their secrets
+func (s *AuthService) GetAPIKeys(userID int) ([]model.APIKey, error) {
+	keys := []model.APIKey{}
+	err := s.db.Select(&keys, `
+        SELECT id, user_id, name, key_prefix, last_used_at, created_at
+        FROM api_keys
+        WHERE user_id = $1
+        ORDER BY created_at DESC
+    `, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// DeleteAPIKey revokes one of the user's API keys
+func (s *AuthService) DeleteAPIKey(userID, keyID int) error {
+	result, err := s.db.Exec("DELETE FROM api_keys WHERE id = $1 AND user_id = $2", keyID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete API key: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check deleted API key: %w", err)
+	}
+	if rows == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// AuthenticateAPIKey resolves a key to its owner and records its use
+func (s *AuthService) AuthenticateAPIKey(key string) (*model.User, error) {
+	var user model.User
+	err := s.db.Get(&user, `
+        UPDATE api_keys k SET last_used_at = NOW()
+        FROM users u
+        WHERE k.key_hash = $1 AND u.id = k.user_id
+        RETURNING u.*
+    `, hashAPIKey(key))
+	if err == sql.ErrNoRows {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate API key: %w", err)
+	}
+	return &user, nil
+}
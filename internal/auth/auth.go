@@ -4,13 +4,14 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/jmoiron/sqlx"
 	"golang.org/x/crypto/bcrypt"
 
+	"domain-detection-go/internal/audit"
 	"domain-detection-go/pkg/model"
 )
 
@@ -19,14 +20,18 @@ type AuthService struct {
 	db            *sqlx.DB
 	jwtSecret     []byte
 	encryptionKey string
+	trialDays     int // New users start on a trial of this many days, 0 disables trials
+	audit         *audit.Logger
 }
 
 // NewAuthService creates a new authentication service
-func NewAuthService(db *sqlx.DB, jwtSecret, encryptionKey string) *AuthService {
+func NewAuthService(db *sqlx.DB, jwtSecret, encryptionKey string, trialDays int, auditLogger *audit.Logger) *AuthService {
 	return &AuthService{
 		db:            db,
 		jwtSecret:     []byte(jwtSecret),
 		encryptionKey: encryptionKey,
+		trialDays:     trialDays,
+		audit:         auditLogger,
 	}
 }
 
@@ -42,8 +47,8 @@ func CheckPassword(password, hash string) bool {
 	return err == nil
 }
 
-// GenerateJWT creates a new JWT token for authenticated users
-func (s *AuthService) GenerateJWT(userID int, username string, region sql.NullString) (string, error) {
+// GenerateJWT creates a new JWT token for authenticated users, bound to their session
+func (s *AuthService) GenerateJWT(userID int, username string, region sql.NullString, sessionID int) (string, error) {
 	token := jwt.New(jwt.SigningMethodHS256)
 
 	regionValue := ""
@@ -55,86 +60,91 @@ func (s *AuthService) GenerateJWT(userID int, username string, region sql.NullSt
 	claims["user_id"] = userID
 	claims["username"] = username
 	claims["region"] = regionValue
-	claims["exp"] = time.Now().Add(time.Hour * 24).Unix()
+	claims["sid"] = sessionID
+	claims["exp"] = time.Now().Add(accessTokenTTL).Unix()
 
 	return token.SignedString(s.jwtSecret)
 }
 
-// Login authenticates a user and handles 2FA if enabled
-func (s *AuthService) Login(creds model.UserCredentials) (*model.User, string, error) {
+// Login authenticates a user, handles 2FA if enabled and starts a session for the
+// client
+func (s *AuthService) Login(creds model.UserCredentials, userAgent, ipAddress string) (*model.User, *model.TokenPair, error) {
 	var user model.User
 
 	err := s.db.Get(&user, "SELECT * FROM users WHERE username = $1", creds.Username)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, "", errors.New("invalid username or password")
+			return nil, nil, errors.New("invalid username or password")
 		}
-		return nil, "", err
+		return nil, nil, err
 	}
 
 	// Check password
 	if !CheckPassword(creds.Password, user.PasswordHash) {
-		return nil, "", errors.New("invalid username or password")
+		return nil, nil, errors.New("invalid username or password")
+	}
+
+	// The account is locked while it is being deleted
+	if user.DeletionRequestedAt != nil {
+		return nil, nil, errors.New("account is being deleted")
 	}
 
 	// Check if 2FA is enabled
-	if user.TwoFactorEnabled {
+	if user.TwoFactorEnabled && creds.TOTPCode == "" && creds.RecoveryCode != "" {
+		// A recovery code stands in for a lost authenticator
+		if err := s.useRecoveryCode(user.ID, creds.RecoveryCode); err != nil {
+			return nil, nil, err
+		}
+	} else if user.TwoFactorEnabled {
 		// If 2FA is enabled, validate the TOTP code
 		if creds.TOTPCode == "" {
-			return &user, "", errors.New("2fa_required")
+			return &user, nil, ErrTwoFactorRequired
 		}
 
 		// Decrypt the secret
 		secret, err := DecryptTOTPSecret(user.TwoFactorSecret, s.encryptionKey)
 		if err != nil {
-			return nil, "", errors.New("error processing 2FA")
+			return nil, nil, errors.New("error processing 2FA")
 		}
 
 		// Validate the TOTP code
 		if !ValidateTOTP(secret, creds.TOTPCode) {
-			return nil, "", errors.New("invalid 2FA code")
+			return nil, nil, ErrInvalidTwoFactorCode
 		}
 	}
 
-	// Generate JWT token
-	token, err := s.GenerateJWT(user.ID, user.Username, user.Region)
+	tokens, err := s.createSession(&user, userAgent, ipAddress)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, err
 	}
 
-	return &user, token, nil
+	return &user, tokens, nil
 }
 
 // SetupTwoFactor initializes 2FA for a user
 func (s *AuthService) SetupTwoFactor(userID int) (*model.TwoFactorSetupResponse, error) {
-	// Add debugging
-	log.Printf("Setting up 2FA for user ID: %d", userID)
-
 	var user model.User
 	err := s.db.Get(&user, "SELECT * FROM users WHERE id = $1", userID)
 	if err != nil {
-		log.Printf("Error fetching user: %v", err)
 		return nil, err
 	}
 
 	// Generate a new TOTP secret
 	secret, err := GenerateTOTPSecret()
 	if err != nil {
-		log.Printf("Error generating TOTP secret: %v", err)
 		return nil, err
 	}
 
 	// Reset two_factor_enabled if it might be causing issues
 	_, err = s.db.Exec("UPDATE users SET two_factor_enabled = false WHERE id = $1", userID)
 	if err != nil {
-		log.Printf("Error resetting 2FA flag: %v", err)
+		slog.Warn("Failed to reset 2FA flag", "user_id", userID, "error", err)
 		// Continue anyway - not critical
 	}
 
 	// Encrypt the secret before storing it
 	encryptedSecret, err := EncryptTOTPSecret(secret, s.encryptionKey)
 	if err != nil {
-		log.Printf("Error encrypting TOTP secret: %v", err)
 		return nil, err
 	}
 
@@ -142,7 +152,6 @@ func (s *AuthService) SetupTwoFactor(userID int) (*model.TwoFactorSetupResponse,
 	_, err = s.db.Exec("UPDATE users SET two_factor_secret = $1 WHERE id = $2",
 		sql.NullString{String: encryptedSecret, Valid: true}, userID)
 	if err != nil {
-		log.Printf("Error updating user with new 2FA secret: %v", err)
 		return nil, err
 	}
 
@@ -155,43 +164,48 @@ func (s *AuthService) SetupTwoFactor(userID int) (*model.TwoFactorSetupResponse,
 	}, nil
 }
 
-// VerifyAndEnableTwoFactor verifies the 2FA code and enables 2FA if valid
-func (s *AuthService) VerifyAndEnableTwoFactor(userID int, code string) error {
+// VerifyAndEnableTwoFactor verifies the 2FA code and enables 2FA if valid. It returns
+// the user's new recovery codes, which are only handed out once the authenticator works.
+func (s *AuthService) VerifyAndEnableTwoFactor(userID int, code string) ([]string, error) {
 	var user model.User
 
 	err := s.db.Get(&user, "SELECT * FROM users WHERE id = $1", userID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Check if we have a valid secret
 	if !user.TwoFactorSecret.Valid {
-		return errors.New("two-factor authentication is not set up")
+		return nil, errors.New("two-factor authentication is not set up")
 	}
 
 	// Decrypt the secret
 	secret, err := DecryptTOTPSecret(user.TwoFactorSecret, s.encryptionKey)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Validate the TOTP code
 	if !ValidateTOTP(secret, code) {
-		return errors.New("invalid 2FA code")
+		return nil, ErrInvalidTwoFactorCode
 	}
 
 	// Enable 2FA for the user
 	_, err = s.db.Exec("UPDATE users SET two_factor_enabled = true WHERE id = $1", userID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return s.generateRecoveryCodes(userID)
 }
 
 // DisableTwoFactor disables 2FA for a user
 func (s *AuthService) DisableTwoFactor(userID int) error {
 	_, err := s.db.Exec("UPDATE users SET two_factor_enabled = false, two_factor_secret = NULL WHERE id = $1", userID)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec("DELETE FROM two_factor_recovery_codes WHERE user_id = $1", userID)
 	return err
 }
 
@@ -205,8 +219,37 @@ func (s *AuthService) GetUserByID(userID int) (*model.User, error) {
 	return &user, nil
 }
 
-// UpdatePassword updates a user's password after verifying the current password
-func (s *AuthService) UpdatePassword(userID int, currentPassword, newPassword string) error {
+// AccountDeletionRequested reports whether a user asked for their account to be deleted
+func (s *AuthService) AccountDeletionRequested(userID int) (bool, error) {
+	var requested bool
+	err := s.db.Get(&requested, "SELECT deletion_requested_at IS NOT NULL FROM users WHERE id = $1", userID)
+	if err == sql.ErrNoRows {
+		// Deleted already
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get account deletion state: %w", err)
+	}
+	return requested, nil
+}
+
+// IsAdmin reports whether a user may use the admin API
+func (s *AuthService) IsAdmin(userID int) (bool, error) {
+	var role string
+	err := s.db.Get(&role, "SELECT role FROM users WHERE id = $1", userID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get user role: %w", err)
+	}
+	return role == model.RoleAdmin, nil
+}
+
+// UpdatePassword updates a user's password after verifying the current password. All
+// other sessions of the user are revoked with it, only the session making the change
+// stays logged in.
+func (s *AuthService) UpdatePassword(userID, currentSessionID int, currentPassword, newPassword string) error {
 	// Get the user from the database
 	var user model.User
 	err := s.db.Get(&user, "SELECT id, password_hash FROM users WHERE id = $1", userID)
@@ -216,7 +259,7 @@ func (s *AuthService) UpdatePassword(userID int, currentPassword, newPassword st
 
 	// Verify the current password
 	if !s.comparePasswords(user.PasswordHash, currentPassword) {
-		return errors.New("incorrect current password")
+		return ErrIncorrectCurrentPassword
 	}
 
 	// Hash the new password
@@ -225,8 +268,14 @@ func (s *AuthService) UpdatePassword(userID int, currentPassword, newPassword st
 		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	// Update the password in the database
-	_, err = s.db.Exec(
+	_, err = tx.Exec(
 		"UPDATE users SET password_hash = $1, updated_at = NOW() WHERE id = $2",
 		hashedPassword, userID,
 	)
@@ -234,6 +283,69 @@ func (s *AuthService) UpdatePassword(userID int, currentPassword, newPassword st
 		return fmt.Errorf("failed to update password: %w", err)
 	}
 
+	_, err = tx.Exec(`
+        UPDATE user_sessions SET revoked_at = NOW()
+        WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL
+    `, userID, currentSessionID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ConfirmAccountDeletion checks the password and, when 2FA is enabled, the TOTP or
+// recovery code a user gives to delete their account
+func (s *AuthService) ConfirmAccountDeletion(userID int, req model.AccountDeletionRequest) (*model.User, error) {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve user: %w", err)
+	}
+
+	if !CheckPassword(req.Password, user.PasswordHash) {
+		return nil, ErrIncorrectPassword
+	}
+
+	if !user.TwoFactorEnabled {
+		return user, nil
+	}
+	if req.TOTPCode == "" && req.RecoveryCode != "" {
+		if err := s.useRecoveryCode(user.ID, req.RecoveryCode); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+	if req.TOTPCode == "" {
+		return nil, ErrTwoFactorRequired
+	}
+	secret, err := DecryptTOTPSecret(user.TwoFactorSecret, s.encryptionKey)
+	if err != nil {
+		return nil, errors.New("error processing 2FA")
+	}
+	if !ValidateTOTP(secret, req.TOTPCode) {
+		return nil, ErrInvalidTwoFactorCode
+	}
+	return user, nil
+}
+
+// UpdateTimezone sets the timezone notification timestamps are shown in. An empty
+// timezone restores the default.
+func (s *AuthService) UpdateTimezone(userID int, timezone string) error {
+	var value *string
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return fmt.Errorf("invalid timezone %q", timezone)
+		}
+		value = &timezone
+	}
+
+	_, err := s.db.Exec("UPDATE users SET timezone = $1, updated_at = NOW() WHERE id = $2", value, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update timezone: %w", err)
+	}
 	return nil
 }
 
@@ -0,0 +1,26 @@
+package auth
+
+import "errors"
+
+// Errors returned by the auth service for requests that cannot be applied. Handlers
+// match them with errors.Is to choose the response.
+var (
+	ErrTwoFactorRequired        = errors.New("2fa_required")
+	ErrInvalidTwoFactorCode     = errors.New("invalid 2FA code")
+	ErrTwoFactorNotEnabled      = errors.New("two-factor authentication is not enabled")
+	ErrInvalidRecoveryCode      = errors.New("invalid recovery code")
+	ErrIncorrectPassword        = errors.New("incorrect password")
+	ErrIncorrectCurrentPassword = errors.New("incorrect current password")
+	ErrUsernameExists           = errors.New("username already exists")
+	ErrEmailExists              = errors.New("email already exists")
+	ErrUserNotFound             = errors.New("user not found")
+	ErrInvalidRefreshToken      = errors.New("invalid refresh token")
+	ErrSessionNotFound          = errors.New("session not found")
+	ErrAPIKeyLimitReached       = errors.New("api key limit reached")
+	ErrAPIKeyNotFound           = errors.New("api key not found")
+	ErrInvalidAPIKey            = errors.New("invalid api key")
+
+	// Impersonation requests that cannot be applied
+	ErrSelfImpersonation         = errors.New("cannot impersonate yourself")
+	ErrImpersonationNeedsSession = errors.New("impersonation requires a login session")
+)
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"domain-detection-go/internal/audit"
+	"domain-detection-go/pkg/model"
+)
+
+// impersonationTokenTTL is the lifetime of a token issued to support staff acting as a
+// user. There is no refresh token, so support asks for a new one when it expires.
+const impersonationTokenTTL = 30 * time.Minute
+
+// Impersonate issues a read-only token that lets support staff see the account of a user
+// exactly as the user does. Issuing it is recorded in the audit log of the staff member.
+// The token is bound to the staff member's login session, so it stops working when that
+// session is revoked or ends.
+func (s *AuthService) Impersonate(adminID, adminSessionID, userID int, reason string) (*model.ImpersonationToken, error) {
+	if adminID == userID {
+		return nil, ErrSelfImpersonation
+	}
+	if adminSessionID == 0 {
+		return nil, ErrImpersonationNeedsSession
+	}
+
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	expiresAt := time.Now().Add(impersonationTokenTTL)
+
+	// Record before issuing, a token is never handed out without its audit entry
+	err = s.audit.Record(adminID, 0, audit.ActionImpersonationStarted, map[string]interface{}{
+		"user_id":    user.ID,
+		"username":   user.Username,
+		"reason":     reason,
+		"expires_at": expiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	token := jwt.New(jwt.SigningMethodHS256)
+	claims := token.Claims.(jwt.MapClaims)
+	claims["user_id"] = user.ID
+	claims["username"] = user.Username
+	claims["region"] = user.Region.String
+	claims["impersonator_id"] = adminID
+	claims["sid"] = adminSessionID
+	claims["exp"] = expiresAt.Unix()
+
+	signed, err := token.SignedString(s.jwtSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	return &model.ImpersonationToken{
+		Token:     signed,
+		UserID:    user.ID,
+		Username:  user.Username,
+		ExpiresIn: int(impersonationTokenTTL.Seconds()),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// RecordImpersonatedRequest adds a request made with an impersonation token to the audit
+// log of the staff member who made it
+func (s *AuthService) RecordImpersonatedRequest(adminID, userID int, method, path string) error {
+	return s.audit.Record(adminID, 0, audit.ActionImpersonatedRequest, map[string]interface{}{
+		"user_id": userID,
+		"method":  method,
+		"path":    path,
+	})
+}
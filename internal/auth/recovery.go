@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// recoveryCodeCount is how many recovery codes are generated at once
+const recoveryCodeCount = 10
+
+// recoveryEncoding renders codes without padding or easily confused characters
+var recoveryEncoding = base32.NewEncoding("abcdefghjkmnpqrstuvwxyz023456789").WithPadding(base32.NoPadding)
+
+// normalizeRecoveryCode strips the formatting users may type around a code
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// hashRecoveryCode returns the stored form of a recovery code. Codes carry 50 bits of
+// randomness and are single use, so a plain SHA-256 allows looking them up by hash.
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(normalizeRecoveryCode(code)))
+	return hex.EncodeToString(sum[:])
+}
+
+// generateRecoveryCodes replaces the user's recovery codes and returns the new ones,
+// formatted as "xxxxx-xxxxx"
+func (s *AuthService) generateRecoveryCodes(userID int) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	for len(codes) < recoveryCodeCount {
+		raw := make([]byte, 7)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		code := recoveryEncoding.EncodeToString(raw)[:10]
+		codes = append(codes, code[:5]+"-"+code[5:])
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM two_factor_recovery_codes WHERE user_id = $1", userID); err != nil {
+		return nil, fmt.Errorf("failed to clear recovery codes: %w", err)
+	}
+	for _, code := range codes {
+		_, err := tx.Exec(`
+            INSERT INTO two_factor_recovery_codes (user_id, code_hash)
+            VALUES ($1, $2)
+            ON CONFLICT DO NOTHING
+        `, userID, hashRecoveryCode(code))
+		if err != nil {
+			return nil, fmt.Errorf("failed to store recovery code: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return codes, nil
+}
+
+// useRecoveryCode consumes one of the user's unused recovery codes
+func (s *AuthService) useRecoveryCode(userID int, code string) error {
+	result, err := s.db.Exec(`
+        UPDATE two_factor_recovery_codes SET used_at = NOW()
+        WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+    `, userID, hashRecoveryCode(code))
+	if err != nil {
+		return fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check recovery code: %w", err)
+	}
+	if rows == 0 {
+		return ErrInvalidRecoveryCode
+	}
+
+	slog.Info("User logged in with a recovery code", "user_id", userID)
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of a user with 2FA enabled after
+// checking a current TOTP code. Previous codes stop working.
+func (s *AuthService) RegenerateRecoveryCodes(userID int, totpCode string) ([]string, error) {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve user: %w", err)
+	}
+	if !user.TwoFactorEnabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	secret, err := DecryptTOTPSecret(user.TwoFactorSecret, s.encryptionKey)
+	if err != nil {
+		return nil, errors.New("error processing 2FA")
+	}
+	if !ValidateTOTP(secret, totpCode) {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	return s.generateRecoveryCodes(userID)
+}
+
+// CountRecoveryCodes returns how many unused recovery codes the user has left
+func (s *AuthService) CountRecoveryCodes(userID int) (int, error) {
+	var remaining int
+	err := s.db.Get(&remaining, `
+        SELECT COUNT(*) FROM two_factor_recovery_codes WHERE user_id = $1 AND used_at IS NULL
+    `, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+	return remaining, nil
+}
@@ -2,7 +2,7 @@ package auth
 
 import (
 	"domain-detection-go/pkg/model"
-	"errors"
+	"log/slog"
 	"time"
 )
 
@@ -15,7 +15,7 @@ func (s *AuthService) RegisterUser(req model.RegistrationRequest) (int64, error)
 		return 0, err
 	}
 	if count > 0 {
-		return 0, errors.New("username already exists")
+		return 0, ErrUsernameExists
 	}
 
 	// Check if email already exists
@@ -24,7 +24,7 @@ func (s *AuthService) RegisterUser(req model.RegistrationRequest) (int64, error)
 		return 0, err
 	}
 	if count > 0 {
-		return 0, errors.New("email already exists")
+		return 0, ErrEmailExists
 	}
 
 	// Hash password
@@ -44,9 +44,32 @@ func (s *AuthService) RegisterUser(req model.RegistrationRequest) (int64, error)
 		return 0, err
 	}
 
+	if s.trialDays > 0 {
+		if err := s.startTrial(userID); err != nil {
+			slog.Error("Failed to start trial", "user_id", userID, "error", err)
+		}
+	}
+
 	return userID, nil
 }
 
+// startTrial puts a newly registered user on the trial plan
+func (s *AuthService) startTrial(userID int64) error {
+	_, err := s.db.Exec("UPDATE users SET trial_expires_at = $1 WHERE id = $2",
+		time.Now().AddDate(0, 0, s.trialDays), userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`
+        INSERT INTO user_settings (user_id, plan, updated_at)
+        VALUES ($1, $2, NOW())
+        ON CONFLICT (user_id)
+        DO UPDATE SET plan = $2, updated_at = NOW()
+    `, userID, model.TRIAL_PLAN)
+	return err
+}
+
 // GetRegions fetches all active regions from the database
 func (s *AuthService) GetRegions() ([]model.Region, error) {
 	var regions []model.Region
@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"domain-detection-go/pkg/model"
+)
+
+const (
+	// accessTokenTTL is the lifetime of a JWT. Clients renew it with their refresh token.
+	accessTokenTTL = 15 * time.Minute
+	// refreshTokenTTL is how long a session survives without being refreshed
+	refreshTokenTTL = 30 * 24 * time.Hour
+	// refreshTokenPrefix marks refresh tokens so they are not mistaken for API keys
+	refreshTokenPrefix = "ddr_"
+)
+
+// hashRefreshToken returns the stored form of a refresh token
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newRefreshToken generates a random refresh token
+func newRefreshToken() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	return refreshTokenPrefix + hex.EncodeToString(secret), nil
+}
+
+// createSession starts a session for a user who just logged in
+func (s *AuthService) createSession(user *model.User, userAgent, ipAddress string) (*model.TokenPair, error) {
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	var sessionID int
+	err = s.db.Get(&sessionID, `
+        INSERT INTO user_sessions (user_id, refresh_token_hash, user_agent, ip_address, expires_at)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id
+    `, user.ID, hashRefreshToken(refreshToken), userAgent, ipAddress, time.Now().Add(refreshTokenTTL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return s.tokenPair(user, sessionID, refreshToken)
+}
+
+// tokenPair signs an access token for the session
+func (s *AuthService) tokenPair(user *model.User, sessionID int, refreshToken string) (*model.TokenPair, error) {
+	token, err := s.GenerateJWT(user.ID, user.Username, user.Region, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.TokenPair{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(accessTokenTTL.Seconds()),
+	}, nil
+}
+
+// RefreshSession exchanges a refresh token for a new token pair. The refresh token is
+// rotated, so a token that was already used no longer works.
+func (s *AuthService) RefreshSession(refreshToken string) (*model.TokenPair, error) {
+	newToken, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	var session struct {
+		ID     int `db:"id"`
+		UserID int `db:"user_id"`
+	}
+	err = s.db.Get(&session, `
+        UPDATE user_sessions
+        SET refresh_token_hash = $2, last_used_at = NOW(), expires_at = $3
+        WHERE refresh_token_hash = $1 AND revoked_at IS NULL AND expires_at > NOW()
+        RETURNING id, user_id
+    `, hashRefreshToken(refreshToken), hashRefreshToken(newToken), time.Now().Add(refreshTokenTTL))
+	if err == sql.ErrNoRows {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh session: %w", err)
+	}
+
+	user, err := s.GetUserByID(session.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session user: %w", err)
+	}
+	return s.tokenPair(user, session.ID, newToken)
+}
+
+// SessionActive reports whether access tokens of the session are still accepted
+func (s *AuthService) SessionActive(sessionID int) (bool, error) {
+	var active bool
+	err := s.db.Get(&active, `
+        SELECT revoked_at IS NULL AND expires_at > NOW()
+        FROM user_sessions
+        WHERE id = $1
+    `, sessionID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check session: %w", err)
+	}
+	return active, nil
+}
+
+// GetSessions lists the active sessions of the user, marking the current one
+func (s *AuthService) GetSessions(userID, currentSessionID int) ([]model.Session, error) {
+	sessions := []model.Session{}
+	err := s.db.Select(&sessions, `
+        SELECT id, user_agent, ip_address, created_at, last_used_at, expires_at
+        FROM user_sessions
+        WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+        ORDER BY last_used_at DESC
+    `, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+	return sessions, nil
+}
+
+// RevokeSession ends one of the user's sessions. Its refresh token and access tokens
+// stop working immediately.
+func (s *AuthService) RevokeSession(userID, sessionID int) error {
+	result, err := s.db.Exec(`
+        UPDATE user_sessions SET revoked_at = NOW()
+        WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+    `, sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check revoked session: %w", err)
+	}
+	if rows == 0 {
+		return ErrSessionNotFound
+	}
+
+	slog.Info("Revoked session", "session_id", sessionID, "user_id", userID)
+	return nil
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"domain-detection-go/internal/testdb"
+	"domain-detection-go/pkg/model"
+)
+
+func TestUserScansEveryUsersColumn(t *testing.T) {
+	db := testdb.Open(t)
+	s := NewAuthService(db, "secret", "", 0, nil)
+
+	trialExpiresAt := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	var userID int
+	err := db.Get(&userID, `
+        INSERT INTO users (username, password_hash, email, trial_expires_at, suspended_at)
+        VALUES ('scan', 'hash', 'scan@example.com', $1, NOW())
+        RETURNING id
+    `, trialExpiresAt)
+	if err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		t.Fatalf("GetUserByID: %v", err)
+	}
+	if user.TrialExpiresAt == nil || !user.TrialExpiresAt.Equal(trialExpiresAt) {
+		t.Errorf("TrialExpiresAt = %v, want %v", user.TrialExpiresAt, trialExpiresAt)
+	}
+	if user.SuspendedAt == nil {
+		t.Error("SuspendedAt = nil, want the suspension time")
+	}
+
+	key, err := s.CreateAPIKey(userID, "scan")
+	if err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+	user, err = s.AuthenticateAPIKey(key.Key)
+	if err != nil {
+		t.Fatalf("AuthenticateAPIKey: %v", err)
+	}
+	if user.ID != userID {
+		t.Errorf("AuthenticateAPIKey returned user %d, want %d", user.ID, userID)
+	}
+}
+
+func TestUpdatePasswordRevokesOtherSessions(t *testing.T) {
+	db := testdb.Open(t)
+	s := NewAuthService(db, "secret", "", 0, nil)
+
+	hash, err := HashPassword("old-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &model.User{Username: "password", PasswordHash: hash}
+	err = db.Get(&user.ID, `
+        INSERT INTO users (username, password_hash, email)
+        VALUES ('password', $1, 'password@example.com')
+        RETURNING id
+    `, hash)
+	if err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	for _, agent := range []string{"current", "other"} {
+		if _, err := s.createSession(user, agent, "127.0.0.1"); err != nil {
+			t.Fatalf("createSession: %v", err)
+		}
+	}
+	var currentID, otherID int
+	if err := db.Get(&currentID, "SELECT id FROM user_sessions WHERE user_id = $1 AND user_agent = 'current'", user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Get(&otherID, "SELECT id FROM user_sessions WHERE user_id = $1 AND user_agent = 'other'", user.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.UpdatePassword(user.ID, currentID, "old-password", "new-password"); err != nil {
+		t.Fatalf("UpdatePassword: %v", err)
+	}
+
+	if active, err := s.SessionActive(currentID); err != nil || !active {
+		t.Errorf("current session active = %v (%v), want true", active, err)
+	}
+	if active, err := s.SessionActive(otherID); err != nil || active {
+		t.Errorf("other session active = %v (%v), want false", active, err)
+	}
+}
@@ -0,0 +1,123 @@
+// Package blockpage recognises the block pages and connection resets that ISPs in
+// mainland China and Vietnam serve in place of a blocked site. Block pages are usually
+// served with status 200, so without this they read as the site being up.
+package blockpage
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"regexp"
+	"strings"
+)
+
+// MaxBodyBytes caps how much of a page is inspected for block page signatures
+const MaxBodyBytes = 64 << 10
+
+// signature is a lowercase substring identifying a block page, with a short name for
+// the alert
+type signature struct {
+	name    string
+	pattern string
+}
+
+// headerSignatures match the value of a response header, such as the redirect of a
+// blocked request to the ISP's notice page
+var headerSignatures = map[string][]signature{
+	"Location": {
+		{"ISP block page redirect", "blockpage"},
+		{"ISP block page redirect", "/blocked.html"},
+	},
+}
+
+// titleSignatures match the page title
+var titleSignatures = []signature{
+	{"CN ICP filing block", "网站暂时无法访问"},
+	{"CN ICP filing block", "网站暂时无法进行访问"},
+	{"CN blocked site notice", "网站已被屏蔽"},
+	{"VN blocked site notice", "bị chặn"},
+	{"blocked site notice", "website blocked"},
+}
+
+// bodySignatures match anywhere in the inspected part of the page
+var bodySignatures = []signature{
+	{"CN ICP filing block", "该网站暂时无法进行访问"},
+	{"CN ICP filing block", "网站未备案"},
+	{"CN regulatory block", "根据相关法律法规"},
+	{"VN blocked site notice", "trang web này đã bị chặn"},
+	{"VN regulatory block", "theo yêu cầu của cơ quan chức năng"},
+}
+
+// resetSignatures match connection errors caused by injected TCP resets, as used by the
+// Great Firewall on blocked hostnames
+var resetSignatures = []signature{
+	{"connection reset", "connection reset by peer"},
+	{"connection reset", "forcibly closed by the remote host"},
+}
+
+var titlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// Detect returns the name of the block page signature matched by a response's headers
+// or body, and whether one matched
+func Detect(header http.Header, body []byte) (string, bool) {
+	for key, signatures := range headerSignatures {
+		if name, ok := match(signatures, header.Get(key)); ok {
+			return name, true
+		}
+	}
+	if len(body) > MaxBodyBytes {
+		body = body[:MaxBodyBytes]
+	}
+	if m := titlePattern.FindSubmatch(body); m != nil {
+		if name, ok := match(titleSignatures, string(m[1])); ok {
+			return name, true
+		}
+	}
+	return match(bodySignatures, string(body))
+}
+
+// DetectRawHeaders applies the header signatures to raw response headers, as reported
+// by the deep check nodes
+func DetectRawHeaders(head string) (string, bool) {
+	reader := textproto.NewReader(bufio.NewReader(strings.NewReader(head)))
+	// The status line comes first when present
+	if strings.HasPrefix(head, "HTTP/") {
+		if _, err := reader.ReadLine(); err != nil {
+			return "", false
+		}
+	}
+	header, err := reader.ReadMIMEHeader()
+	if err != nil && len(header) == 0 {
+		return "", false
+	}
+	return Detect(http.Header(header), nil)
+}
+
+// DetectError returns the name of the reset signature matched by a connection error,
+// and whether one matched
+func DetectError(err error) (string, bool) {
+	if err == nil {
+		return "", false
+	}
+	return match(resetSignatures, err.Error())
+}
+
+// Description returns the error description stored for a likely ISP-blocked check
+func Description(name string) string {
+	return fmt.Sprintf("Likely ISP-blocked: %s", name)
+}
+
+// match returns the first signature found in the text, ignoring case
+func match(signatures []signature, text string) (string, bool) {
+	if text == "" {
+		return "", false
+	}
+	text = strings.ToLower(text)
+	for _, s := range signatures {
+		if strings.Contains(text, s.pattern) {
+			return s.name, true
+		}
+	}
+	return "", false
+}
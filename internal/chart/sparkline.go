@@ -0,0 +1,162 @@
+// Package chart renders small charts as PNG images for emails
+package chart
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"time"
+)
+
+// Point is one check shown on a sparkline
+type Point struct {
+	At           time.Time `db:"checked_at"`
+	Available    bool      `db:"available"`
+	ResponseTime int       `db:"total_time"` // Milliseconds, 0 when unknown
+}
+
+// Sparkline sizes. The response time line is drawn above a strip of uptime buckets.
+const (
+	SparklineWidth  = 320
+	SparklineHeight = 64
+
+	sparklineBuckets = 48
+	stripHeight      = 8
+	stripGap         = 4
+)
+
+var (
+	backgroundColor = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	lineColor       = color.RGBA{0x34, 0x98, 0xdb, 0xff}
+	upColor         = color.RGBA{0x27, 0xae, 0x60, 0xff}
+	downColor       = color.RGBA{0xe7, 0x4c, 0x3c, 0xff}
+	noDataColor     = color.RGBA{0xdd, 0xdd, 0xdd, 0xff}
+)
+
+// bucket aggregates the checks of one slice of the chart's period
+type bucket struct {
+	checks    int
+	down      int
+	timeSum   int
+	timeCount int
+}
+
+// Sparkline renders the checks between from and to as a PNG: the average response time
+// of successful checks as a line, and below it a strip that is green where every check
+// succeeded, red where one failed and grey without checks.
+func Sparkline(points []Point, from, to time.Time) ([]byte, error) {
+	if !to.After(from) {
+		return nil, fmt.Errorf("invalid sparkline period %s - %s", from, to)
+	}
+
+	buckets := make([]bucket, sparklineBuckets)
+	span := to.Sub(from)
+	for _, p := range points {
+		if p.At.Before(from) || !p.At.Before(to) {
+			continue
+		}
+		b := &buckets[int(int64(p.At.Sub(from))*sparklineBuckets/int64(span))]
+		b.checks++
+		if !p.Available {
+			b.down++
+		} else if p.ResponseTime > 0 {
+			b.timeSum += p.ResponseTime
+			b.timeCount++
+		}
+	}
+
+	maxTime := 0
+	for _, b := range buckets {
+		if b.timeCount > 0 && b.timeSum/b.timeCount > maxTime {
+			maxTime = b.timeSum / b.timeCount
+		}
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, SparklineWidth, SparklineHeight))
+	fill(img, img.Bounds(), backgroundColor)
+
+	// Uptime strip
+	stripTop := SparklineHeight - stripHeight
+	for i, b := range buckets {
+		c := noDataColor
+		if b.down > 0 {
+			c = downColor
+		} else if b.checks > 0 {
+			c = upColor
+		}
+		x0 := i * SparklineWidth / sparklineBuckets
+		x1 := (i+1)*SparklineWidth/sparklineBuckets - 1 // Leave a pixel between buckets
+		fill(img, image.Rect(x0, stripTop, x1, SparklineHeight), c)
+	}
+
+	// Response time line, broken where a bucket has no successful checks
+	lineHeight := stripTop - stripGap
+	prevX, prevY, havePrev := 0, 0, false
+	for i, b := range buckets {
+		if b.timeCount == 0 || maxTime == 0 {
+			havePrev = false
+			continue
+		}
+		x := i * (SparklineWidth - 1) / (sparklineBuckets - 1)
+		y := 1 + (lineHeight-3)*(maxTime-b.timeSum/b.timeCount)/maxTime
+		if havePrev {
+			drawLine(img, prevX, prevY, x, y, lineColor)
+		} else {
+			drawLine(img, x, y, x, y, lineColor)
+		}
+		prevX, prevY, havePrev = x, y, true
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode sparkline: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// fill paints a rectangle
+func fill(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+// drawLine draws a two pixel thick line with Bresenham's algorithm
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		img.SetRGBA(x0, y0, c)
+		img.SetRGBA(x0, y0+1, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,57 @@
+// Package credentials encrypts secrets stored in the database, such as SFTP passwords
+// and basic auth credentials of domain checks
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+)
+
+// Seal encrypts a credential with AES-GCM under a key derived from the
+// application encryption key. The random nonce is prepended to the ciphertext.
+func Seal(plaintext, encryptionKey string) (string, error) {
+	gcm, err := credentialCipher(encryptionKey)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a credential sealed by Seal
+func Open(sealed, encryptionKey string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := credentialCipher(encryptionKey)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("credential too short")
+	}
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+func credentialCipher(encryptionKey string) (cipher.AEAD, error) {
+	hash := sha256.Sum256([]byte(encryptionKey))
+	block, err := aes.NewCipher(hash[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
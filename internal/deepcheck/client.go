@@ -2,15 +2,18 @@ package deepcheck
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
-	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+
+	"domain-detection-go/internal/blockpage"
 )
 
 // DeepCheckClient handles deep check API calls
@@ -39,18 +42,8 @@ type GoogleTranslateResponse struct {
 	} `json:"data"`
 }
 
-// NewDeepCheckClient creates a new deep check client
-func NewDeepCheckClient() *DeepCheckClient {
-	// Get base URL from environment variable
-	baseURL := os.Getenv("DEEP_CHECK_BASE_URL")
-	if baseURL == "" {
-		// Fallback to default URL if not configured
-		baseURL = "https://itdog-hq-public.passgfw-global-mixed-uat-eks.y8schwifty.app"
-		log.Printf("[DEEP-CHECK] WARNING: DEEP_CHECK_BASE_URL not configured, using default: %s", baseURL)
-	} else {
-		log.Printf("[DEEP-CHECK] Using configured base URL: %s", baseURL)
-	}
-
+// NewDeepCheckClient creates a new deep check client for the API at baseURL
+func NewDeepCheckClient(baseURL string) *DeepCheckClient {
 	return &DeepCheckClient{
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
@@ -71,8 +64,6 @@ func (c *DeepCheckClient) RequestDeepCheck(url string) (*DeepCheckResponse, erro
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	log.Printf("[DEEP-CHECK] Requesting deep check for URL: %s", url)
-
 	// Create HTTP request
 	apiURL := fmt.Sprintf("%s/v1/hq/order", c.baseURL)
 	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(jsonData))
@@ -84,13 +75,9 @@ func (c *DeepCheckClient) RequestDeepCheck(url string) (*DeepCheckResponse, erro
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
-	log.Printf("[DEEP-CHECK] Making request to: %s", apiURL)
-	log.Printf("[DEEP-CHECK] Request payload: %s", string(jsonData))
-
 	// Send request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		log.Printf("[DEEP-CHECK] ERROR: Failed to send request: %v", err)
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
@@ -99,28 +86,21 @@ func (c *DeepCheckClient) RequestDeepCheck(url string) (*DeepCheckResponse, erro
 	var responseBody bytes.Buffer
 	_, err = responseBody.ReadFrom(resp.Body)
 	if err != nil {
-		log.Printf("[DEEP-CHECK] ERROR: Failed to read response body: %v", err)
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	log.Printf("[DEEP-CHECK] Response status: %d", resp.StatusCode)
-	log.Printf("[DEEP-CHECK] Response body: %s", responseBody.String())
-
 	// Check response status
 	if resp.StatusCode != http.StatusOK {
-		log.Printf("[DEEP-CHECK] ERROR: API returned status %d: %s", resp.StatusCode, responseBody.String())
 		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, responseBody.String())
 	}
 
 	// Parse response
 	var deepCheckResp DeepCheckResponse
 	if err := json.Unmarshal(responseBody.Bytes(), &deepCheckResp); err != nil {
-		log.Printf("[DEEP-CHECK] ERROR: Failed to parse response: %v", err)
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	log.Printf("[DEEP-CHECK] SUCCESS: Order created - OrderID: %s, URL: %s",
-		deepCheckResp.OrderID, deepCheckResp.ITDOG_TEST_URL)
+	slog.Info("Deep check ordered", "order_id", deepCheckResp.OrderID, "url", url)
 
 	return &deepCheckResp, nil
 }
@@ -181,6 +161,12 @@ func (r *DeepCheckRecord) GetResponseTimeMs() int {
 	return int(timeFloat * 1000) // Convert seconds to milliseconds
 }
 
+// LikelyBlocked returns true if the node got an ISP block page instead of the site
+func (r *DeepCheckRecord) LikelyBlocked() bool {
+	_, blocked := blockpage.DetectRawHeaders(r.Head)
+	return blocked
+}
+
 // IsHealthy returns true if the record indicates a healthy response
 func (r *DeepCheckRecord) IsHealthy() bool {
 	// If type is not success, it's definitely not healthy
@@ -188,6 +174,11 @@ func (r *DeepCheckRecord) IsHealthy() bool {
 		return false
 	}
 
+	// Block pages are usually served with a success status
+	if r.LikelyBlocked() {
+		return false
+	}
+
 	// If HTTP code is 0, check response time
 	if r.HTTPCode == 0 {
 		return false
@@ -199,6 +190,9 @@ func (r *DeepCheckRecord) IsHealthy() bool {
 
 // GetStatusDescription returns a description of the status
 func (r *DeepCheckRecord) GetStatusDescription() string {
+	if r.LikelyBlocked() {
+		return "疑似被電訊商封鎖"
+	}
 	switch r.HTTPCode {
 	case 0:
 		return "無回應"
@@ -296,17 +290,15 @@ func (req *DeepCheckCallbackRequest) FormatTelegramMessage(targetDomain, languag
 
 	// Translate messages if language is not Chinese (zh) and not empty
 	if language != "" && language != "zh" && language != "zh-CN" {
-		log.Printf("[DEEP-CHECK] Translating messages from Chinese to %s", language)
 		translatedMessages := make([]string, len(messages))
 
 		for i, msg := range messages {
 			translated, err := translateText(msg, "zh", language)
 			if err != nil {
-				log.Printf("[DEEP-CHECK] Translation failed for message %d: %v, using original", i+1, err)
+				slog.Warn("Deep check message translation failed, using original", "language", language, "message", i+1, "error", err)
 				translatedMessages[i] = msg
 			} else {
 				translatedMessages[i] = translated
-				log.Printf("[DEEP-CHECK] Successfully translated message %d", i+1)
 			}
 
 			// Add small delay to avoid hitting API rate limits
@@ -316,12 +308,6 @@ func (req *DeepCheckCallbackRequest) FormatTelegramMessage(targetDomain, languag
 		messages = translatedMessages
 	}
 
-	// Log all messages for preview
-	log.Printf("[DEEP-CHECK] TELEGRAM MESSAGES COUNT: %d", len(messages))
-	for i, msg := range messages {
-		log.Printf("[DEEP-CHECK] TELEGRAM MESSAGE %d PREVIEW (%d chars):\n%s", i+1, len(msg), msg)
-	}
-
 	return messages
 }
 
@@ -571,7 +557,8 @@ func (req *DeepCheckCallbackRequest) extractCityName(record DeepCheckRecord) str
 	return city
 }
 
-// FormatEmailMessage formats the callback results for Email (HTML format) with translation support
+// FormatEmailMessage formats the callback summary for Email (HTML format) with translation
+// support. The per-node results are attached separately, see FormatCSV.
 func (req *DeepCheckCallbackRequest) FormatEmailMessage(targetDomain, language string) (string, string) {
 	summary := req.AnalyzeResults(targetDomain)
 
@@ -584,12 +571,8 @@ func (req *DeepCheckCallbackRequest) FormatEmailMessage(targetDomain, language s
         .header{background-color:#f4f4f4;padding:15px;text-align:center}
         .content{padding:15px}
         .summary{background-color:#e7f3ff;padding:10px;margin:10px 0;border-radius:5px}
-        table{width:100%;border-collapse:collapse;margin:10px 0}
-        th,td{border:1px solid #ddd;padding:6px;text-align:left;font-size:11px}
-        th{background-color:#f2f2f2;font-weight:bold}
-        .success{color:#28a745}.warning{color:#ffc107}.danger{color:#dc3545}
-        .error-row{background-color:#fff2f2}
-        .success-row{background-color:#f2fff2}
+        .success{color:#28a745}.danger{color:#dc3545}
+        .note{color:#666;font-size:12px}
         </style></head><body>`)
 
 	// Create translatable content (Chinese first)
@@ -599,89 +582,23 @@ func (req *DeepCheckCallbackRequest) FormatEmailMessage(targetDomain, language s
 	targetDomainLabel := "📍 目標域名："
 	checkTimeLabel := "🕓 檢查時間："
 	orderIdLabel := "🔍 訂單編號："
-
-	// Table headers in Chinese
-	provinceHeader := "省份"
-	cityHeader := "城市"
-	ispHeader := "電訊商"
-	responseTimeHeader := "響應時間"
-	statusCodeHeader := "狀態碼"
-	statusDescHeader := "狀態描述"
-	ipHeader := "IP地址"
-
-	// Section titles
-	errorRegionsTitle := "異常地區"
 	normalRegionsTitle := "正常地區"
-	allErrorTitle := "全部異常"
-	allNormalTitle := "全部正常"
-	timeoutText := "超時"
-	countSuffix := "個"
-	totalPrefix := "總共"
+	errorRegionsTitle := "異常地區"
+	attachmentNote := "各節點的完整結果見附件 CSV 文件。"
 
 	// Translate content if not Chinese
 	if language != "" && language != "zh" && language != "zh-CN" {
-		log.Printf("[DEEP-CHECK] Translating email content from Chinese to %s", language)
-
-		// Translate text elements (with error handling)
-		if translated, err := translateText(headerTitle, "zh", language); err == nil {
-			headerTitle = translated
-		}
-		if translated, err := translateText(targetDomainLabel, "zh", language); err == nil {
-			targetDomainLabel = translated
-		}
-		if translated, err := translateText(checkTimeLabel, "zh", language); err == nil {
-			checkTimeLabel = translated
-		}
-		if translated, err := translateText(orderIdLabel, "zh", language); err == nil {
-			orderIdLabel = translated
-		}
-		if translated, err := translateText(provinceHeader, "zh", language); err == nil {
-			provinceHeader = translated
-		}
-		if translated, err := translateText(cityHeader, "zh", language); err == nil {
-			cityHeader = translated
-		}
-		if translated, err := translateText(ispHeader, "zh", language); err == nil {
-			ispHeader = translated
-		}
-		if translated, err := translateText(responseTimeHeader, "zh", language); err == nil {
-			responseTimeHeader = translated
-		}
-		if translated, err := translateText(statusCodeHeader, "zh", language); err == nil {
-			statusCodeHeader = translated
-		}
-		if translated, err := translateText(statusDescHeader, "zh", language); err == nil {
-			statusDescHeader = translated
-		}
-		if translated, err := translateText(ipHeader, "zh", language); err == nil {
-			ipHeader = translated
-		}
-		if translated, err := translateText(errorRegionsTitle, "zh", language); err == nil {
-			errorRegionsTitle = translated
-		}
-		if translated, err := translateText(normalRegionsTitle, "zh", language); err == nil {
-			normalRegionsTitle = translated
-		}
-		if translated, err := translateText(allErrorTitle, "zh", language); err == nil {
-			allErrorTitle = translated
-		}
-		if translated, err := translateText(allNormalTitle, "zh", language); err == nil {
-			allNormalTitle = translated
-		}
-		if translated, err := translateText(timeoutText, "zh", language); err == nil {
-			timeoutText = translated
-		}
-		if translated, err := translateText(countSuffix, "zh", language); err == nil {
-			countSuffix = translated
-		}
-		if translated, err := translateText(totalPrefix, "zh", language); err == nil { // Add this line
-			totalPrefix = translated
+		for _, text := range []*string{&headerTitle, &targetDomainLabel, &checkTimeLabel, &orderIdLabel,
+			&normalRegionsTitle, &errorRegionsTitle, &attachmentNote} {
+			if translated, err := translateText(*text, "zh", language); err == nil {
+				*text = translated
+			}
 		}
 
 		// Translate status text (handling complex format)
-		statusOnlyText := summary.Status // Just the status part
-		if translated, err := translateText(statusOnlyText, "zh", language); err == nil {
-			// Reconstruct status text with translated status
+		translatedStatus := summary.Status
+		if translated, err := translateText(summary.Status, "zh", language); err == nil {
+			translatedStatus = translated
 			nodeText := "nodes normal" // Default fallback
 			if translatedNodes, err := translateText("節點正常", "zh", language); err == nil {
 				nodeText = translatedNodes
@@ -690,22 +607,15 @@ func (req *DeepCheckCallbackRequest) FormatEmailMessage(targetDomain, language s
 				summary.StatusEmoji, translated, summary.SuccessNodes, summary.TotalNodes, nodeText, summary.SuccessRate)
 		}
 
-		// Translate subject - REMOVE the subjectTemplate declaration
 		if translated, err := translateText("深度網絡檢測報告", "zh", language); err == nil {
-			subject = fmt.Sprintf("%s - %s [%s]", translated, targetDomain,
-				func() string {
-					if translatedStatus, err := translateText(summary.Status, "zh", language); err == nil {
-						return translatedStatus
-					}
-					return summary.Status
-				}())
+			subject = fmt.Sprintf("%s - %s [%s]", translated, targetDomain, translatedStatus)
 		}
 
 		// Add small delays to avoid API rate limits
 		time.Sleep(500 * time.Millisecond)
 	}
 
-	// Build the email body with translated content
+	// Only the summary goes into the body; big tables get mangled by some mail clients
 	body.WriteString(fmt.Sprintf(`
         <div class="header">
         <h2>%s</h2>
@@ -716,111 +626,55 @@ func (req *DeepCheckCallbackRequest) FormatEmailMessage(targetDomain, language s
         <p><strong>%s</strong>%s</p>
         <p><strong>%s</strong>%s</p>
         <p><strong>%s</strong>%s</p>
-        </div>`,
+        </div>
+        <p class="success">%s：%d</p>
+        <p class="danger">%s：%d</p>
+        <p class="note">%s</p>
+        </div></body></html>`,
 		headerTitle, statusText, targetDomainLabel, targetDomain,
 		checkTimeLabel, summary.CheckTime.Format("2006-01-02 15:04:05"),
-		orderIdLabel, req.OrderID))
-
-	// Show all data based on status
-	switch summary.Status {
-	case "部分異常":
-		// Show error regions first
-		body.WriteString(fmt.Sprintf(`<h3 class="danger">%s (%s%d%s)：</h3>`,
-			errorRegionsTitle, totalPrefix, summary.ErrorNodes, countSuffix))
-		body.WriteString(`<table>`)
-		body.WriteString(fmt.Sprintf(`<tr><th>%s</th><th>%s</th><th>%s</th><th>%s</th><th>%s</th><th>%s</th><th>%s</th></tr>`,
-			provinceHeader, cityHeader, ispHeader, responseTimeHeader, statusCodeHeader, statusDescHeader, ipHeader))
-
-		for _, record := range req.Records {
-			if !record.IsHealthy() {
-				city := req.extractCityName(record)
-				responseTime := fmt.Sprintf("%dms", record.GetResponseTimeMs())
-				if record.HTTPCode == 0 || record.GetResponseTimeMs() > 10000 {
-					responseTime = timeoutText
-				}
-
-				// Translate status description
-				statusDesc := record.GetStatusDescription()
-				if language != "" && language != "zh" && language != "zh-CN" {
-					if translated, err := translateText(statusDesc, "zh", language); err == nil {
-						statusDesc = translated
-					}
-					time.Sleep(50 * time.Millisecond) // Small delay
-				}
-
-				body.WriteString(fmt.Sprintf(`<tr class="error-row"><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%d</td><td>%s</td><td>%s</td></tr>`,
-					record.RegionName, city, record.ISP, responseTime, record.HTTPCode, statusDesc, record.IP))
-			}
-		}
-		body.WriteString(`</table>`)
-
-		// Show normal regions
-		body.WriteString(fmt.Sprintf(`<h3 class="success">%s (%s%d%s)：</h3>`,
-			normalRegionsTitle, totalPrefix, summary.SuccessNodes, countSuffix))
-		body.WriteString(`<table>`)
-		body.WriteString(fmt.Sprintf(`<tr><th>%s</th><th>%s</th><th>%s</th><th>%s</th><th>%s</th><th>%s</th></tr>`,
-			provinceHeader, cityHeader, ispHeader, responseTimeHeader, statusCodeHeader, ipHeader))
-
-		for _, record := range req.Records {
-			if record.IsHealthy() {
-				city := req.extractCityName(record)
-				body.WriteString(fmt.Sprintf(`<tr class="success-row"><td>%s</td><td>%s</td><td>%s</td><td>%dms</td><td>%d</td><td>%s</td></tr>`,
-					record.RegionName, city, record.ISP, record.GetResponseTimeMs(), record.HTTPCode, record.IP))
-			}
-		}
-		body.WriteString(`</table>`)
-
-	case "全部異常":
-		// Show all error regions
-		body.WriteString(fmt.Sprintf(`<h3 class="danger">%s (%s%d%s)：</h3>`,
-			allErrorTitle, totalPrefix, summary.ErrorNodes, countSuffix))
-		body.WriteString(`<table>`)
-		body.WriteString(fmt.Sprintf(`<tr><th>%s</th><th>%s</th><th>%s</th><th>%s</th><th>%s</th><th>%s</th><th>%s</th></tr>`,
-			provinceHeader, cityHeader, ispHeader, responseTimeHeader, statusCodeHeader, statusDescHeader, ipHeader))
-
-		for _, record := range req.Records {
-			city := req.extractCityName(record)
-			responseTime := fmt.Sprintf("%dms", record.GetResponseTimeMs())
-			if record.HTTPCode == 0 || record.GetResponseTimeMs() > 10000 {
-				responseTime = timeoutText
-			}
-
-			// Translate status description
-			statusDesc := record.GetStatusDescription()
-			if language != "" && language != "zh" && language != "zh-CN" {
-				if translated, err := translateText(statusDesc, "zh", language); err == nil {
-					statusDesc = translated
-				}
-				time.Sleep(50 * time.Millisecond) // Small delay
-			}
-
-			body.WriteString(fmt.Sprintf(`<tr class="error-row"><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%d</td><td>%s</td><td>%s</td></tr>`,
-				record.RegionName, city, record.ISP, responseTime, record.HTTPCode, statusDesc, record.IP))
-		}
-		body.WriteString(`</table>`)
-
-	default: // 全部正常
-		// Show all normal regions
-		body.WriteString(fmt.Sprintf(`<h3 class="success">%s (%s%d%s)：</h3>`,
-			allNormalTitle, totalPrefix, summary.TotalNodes, countSuffix))
-		body.WriteString(`<table>`)
-		body.WriteString(fmt.Sprintf(`<tr><th>%s</th><th>%s</th><th>%s</th><th>%s</th><th>%s</th><th>%s</th></tr>`,
-			provinceHeader, cityHeader, ispHeader, responseTimeHeader, statusCodeHeader, ipHeader))
-
-		for _, record := range req.Records {
-			city := req.extractCityName(record)
-			body.WriteString(fmt.Sprintf(`<tr class="success-row"><td>%s</td><td>%s</td><td>%s</td><td>%dms</td><td>%d</td><td>%s</td></tr>`,
-				record.RegionName, city, record.ISP, record.GetResponseTimeMs(), record.HTTPCode, record.IP))
-		}
-		body.WriteString(`</table>`)
-	}
-
-	body.WriteString(`</div></body></html>`)
+		orderIdLabel, req.OrderID,
+		normalRegionsTitle, summary.SuccessNodes,
+		errorRegionsTitle, summary.ErrorNodes,
+		attachmentNote))
 	htmlBody := body.String()
 
-	// LOG THE RAW EMAIL MESSAGE FOR PREVIEW
-	log.Printf("[DEEP-CHECK] RAW EMAIL SUBJECT PREVIEW: %s", subject)
-	log.Printf("[DEEP-CHECK] EMAIL HTML BODY LENGTH: %d characters", len(htmlBody))
-
 	return subject, htmlBody
 }
+
+// FormatCSV renders the results of every node as CSV, unhealthy nodes first. The file
+// starts with a UTF-8 byte order mark so spreadsheets show the Chinese names correctly.
+func (req *DeepCheckCallbackRequest) FormatCSV() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff")
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"province", "city", "isp", "ip", "ip_location", "healthy", "likely_blocked",
+		"http_code", "status", "response_time_ms", "dns_time", "connect_time", "download_time", "redirects"})
+
+	records := make([]DeepCheckRecord, len(req.Records))
+	copy(records, req.Records)
+	sort.SliceStable(records, func(i, j int) bool {
+		return !records[i].IsHealthy() && records[j].IsHealthy()
+	})
+
+	for _, record := range records {
+		w.Write([]string{
+			record.RegionName,
+			req.extractCityName(record),
+			record.ISP,
+			record.IP,
+			record.Address,
+			strconv.FormatBool(record.IsHealthy()),
+			strconv.FormatBool(record.LikelyBlocked()),
+			strconv.Itoa(record.HTTPCode),
+			record.GetStatusDescription(),
+			strconv.Itoa(record.GetResponseTimeMs()),
+			record.DNSTime,
+			record.ConnectTime,
+			record.DownloadTime,
+			strconv.Itoa(record.Redirect),
+		})
+	}
+	w.Flush()
+	return buf.Bytes()
+}
@@ -0,0 +1,266 @@
+package dns
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"domain-detection-go/internal/events"
+	"domain-detection-go/internal/notification"
+	"domain-detection-go/pkg/model"
+
+	"github.com/lib/pq"
+	"golang.org/x/net/publicsuffix"
+)
+
+// dnsSnapshotRow scans a snapshot together with its array columns
+type dnsSnapshotRow struct {
+	model.DNSSnapshot
+	ARecords    pq.StringArray `db:"a_records"`
+	AAAARecords pq.StringArray `db:"aaaa_records"`
+	NSRecords   pq.StringArray `db:"ns_records"`
+}
+
+func (r dnsSnapshotRow) toModel() model.DNSSnapshot {
+	snapshot := r.DNSSnapshot
+	snapshot.A = []string(r.ARecords)
+	snapshot.AAAA = []string(r.AAAARecords)
+	snapshot.NS = []string(r.NSRecords)
+	return snapshot
+}
+
+// GetSnapshots returns the most recent distinct record sets of a domain, newest first
+func (s *DNSService) GetSnapshots(domainID, limit int) ([]model.DNSSnapshot, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+
+	var rows []dnsSnapshotRow
+	err := s.db.Select(&rows, `
+        SELECT id, domain_id, resolver, a_records, aaaa_records, cname, ns_records, first_seen_at, last_seen_at
+        FROM domain_dns_snapshots
+        WHERE domain_id = $1
+        ORDER BY first_seen_at DESC
+        LIMIT $2
+    `, domainID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get DNS snapshots: %w", err)
+	}
+
+	snapshots := make([]model.DNSSnapshot, len(rows))
+	for i, row := range rows {
+		snapshots[i] = row.toModel()
+	}
+	return snapshots, nil
+}
+
+// SnapshotDomain resolves the A, AAAA, CNAME and NS records of a domain and stores
+// them when they differ from the last snapshot. Users are alerted when the resolved
+// addresses, the CNAME target or the nameservers change.
+func (s *DNSService) SnapshotDomain(domain model.Domain) error {
+	resolver, err := s.snapshotResolver(domain.Region)
+	if err != nil {
+		return err
+	}
+
+	current, err := resolveRecords(hostname(domain.Name), resolver.Address)
+	if err != nil {
+		// A failed lookup is an availability problem, which the regular checks report
+		return fmt.Errorf("failed to resolve records via %s: %w", resolver.Name, err)
+	}
+	current.DomainID = domain.ID
+	current.Resolver = resolver.Name
+
+	var previousRow dnsSnapshotRow
+	err = s.db.Get(&previousRow, `
+        SELECT id, domain_id, resolver, a_records, aaaa_records, cname, ns_records, first_seen_at, last_seen_at
+        FROM domain_dns_snapshots
+        WHERE domain_id = $1
+        ORDER BY first_seen_at DESC
+        LIMIT 1
+    `, domain.ID)
+	hasPrevious := err == nil
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get previous DNS snapshot: %w", err)
+	}
+	previous := previousRow.toModel()
+
+	if hasPrevious && previous.Resolver == current.Resolver && len(describeRecordChanges(previous, current)) == 0 {
+		_, err := s.db.Exec("UPDATE domain_dns_snapshots SET last_seen_at = NOW() WHERE id = $1", previous.ID)
+		if err != nil {
+			return fmt.Errorf("failed to update DNS snapshot: %w", err)
+		}
+		return nil
+	}
+
+	_, err = s.db.Exec(`
+        INSERT INTO domain_dns_snapshots
+        (domain_id, resolver, a_records, aaaa_records, cname, ns_records, first_seen_at, last_seen_at)
+        VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+    `, domain.ID, current.Resolver, pq.Array(current.A), pq.Array(current.AAAA), current.CNAME, pq.Array(current.NS))
+	if err != nil {
+		return fmt.Errorf("failed to save DNS snapshot: %w", err)
+	}
+
+	// Answers from a different resolver are not comparable, e.g. after a region change
+	if !hasPrevious || previous.Resolver != current.Resolver {
+		return nil
+	}
+
+	changes := describeRecordChanges(previous, current)
+	slog.Info("DNS records changed", "domain", domain.Name, "changes", changes)
+	s.events.Publish(events.Event{
+		Type:     events.DNSRecordsChanged,
+		UserID:   domain.UserID,
+		DomainID: domain.ID,
+		Payload: map[string]interface{}{
+			"domain":  domain.Name,
+			"changes": changes,
+		},
+	})
+	s.notifyRecordChanges(domain, resolver, changes)
+
+	return nil
+}
+
+// snapshotResolver returns the resolver records are taken from: the first active
+// resolver of the domain's region, or the baseline resolver
+func (s *DNSService) snapshotResolver(region string) (model.DNSResolver, error) {
+	var resolver model.DNSResolver
+	err := s.db.Get(&resolver, `
+        SELECT id, region_code, name, address, is_active
+        FROM dns_resolvers
+        WHERE is_active = true AND region_code IN ($1, $2)
+        ORDER BY region_code = $1 DESC, id
+        LIMIT 1
+    `, region, BaselineRegion)
+	if err == sql.ErrNoRows {
+		return resolver, fmt.Errorf("no active resolver for region %s", region)
+	}
+	if err != nil {
+		return resolver, fmt.Errorf("failed to get DNS resolver: %w", err)
+	}
+	return resolver, nil
+}
+
+// notifyRecordChanges alerts the user through every configured channel
+func (s *DNSService) notifyRecordChanges(domain model.Domain, resolver model.DNSResolver, changes []string) {
+	if s.notifiers == nil {
+		return
+	}
+
+	message := fmt.Sprintf("🔀 DNS records changed for %s (seen from %s, %s):\n- %s\n\nIf this change was not planned, check for hijacking or a misconfigured failover.",
+		domain.Name, resolver.Name, resolver.RegionCode, strings.Join(changes, "\n- "))
+
+	subject := fmt.Sprintf("DNS records changed for %s", domain.Name)
+	body := fmt.Sprintf("<html><body><h2>%s</h2><p>Seen from %s (%s):</p><ul><li>%s</li></ul><p>If this change was not planned, check for hijacking or a misconfigured failover.</p></body></html>",
+		subject, resolver.Name, resolver.RegionCode, strings.Join(changes, "</li><li>"))
+
+	err := s.notifiers.SendCustom(domain.UserID, notification.StaticMessage(notification.CustomMessage{
+		Subject: subject,
+		HTML:    body,
+		Text:    []string{message},
+	}))
+	if err != nil {
+		slog.Error("DNS change alert not sent", "domain", domain.Name, "error", err)
+	}
+}
+
+// resolveRecords looks up the records of host against a specific DNS server. NS
+// records are taken from the closest enclosing zone, up to the registrable domain.
+func resolveRecords(host, server string) (model.DNSSnapshot, error) {
+	resolver := serverResolver(server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*resolveTimeout)
+	defer cancel()
+
+	snapshot := model.DNSSnapshot{A: []string{}, AAAA: []string{}, NS: []string{}}
+
+	addrs, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return snapshot, err
+	}
+	for _, addr := range addrs {
+		if addr.IP.To4() != nil {
+			snapshot.A = append(snapshot.A, addr.IP.String())
+		} else {
+			snapshot.AAAA = append(snapshot.AAAA, addr.IP.String())
+		}
+	}
+	sort.Strings(snapshot.A)
+	sort.Strings(snapshot.AAAA)
+
+	if cname, err := resolver.LookupCNAME(ctx, host); err == nil {
+		cname = strings.TrimSuffix(strings.ToLower(cname), ".")
+		if cname != strings.ToLower(host) {
+			snapshot.CNAME = &cname
+		}
+	}
+
+	zone := strings.ToLower(host)
+	apex, err := publicsuffix.EffectiveTLDPlusOne(zone)
+	if err != nil {
+		apex = zone
+	}
+	for {
+		nameservers, err := resolver.LookupNS(ctx, zone)
+		if err == nil && len(nameservers) > 0 {
+			for _, ns := range nameservers {
+				snapshot.NS = append(snapshot.NS, strings.TrimSuffix(strings.ToLower(ns.Host), "."))
+			}
+			sort.Strings(snapshot.NS)
+			break
+		}
+		if zone == apex || !strings.Contains(zone, ".") {
+			break
+		}
+		zone = zone[strings.Index(zone, ".")+1:]
+	}
+
+	return snapshot, nil
+}
+
+// describeRecordChanges compares two snapshots and describes what changed
+func describeRecordChanges(previous, current model.DNSSnapshot) []string {
+	var changes []string
+	if !equalStrings(previous.A, current.A) {
+		changes = append(changes, fmt.Sprintf("A records changed from [%s] to [%s]",
+			strings.Join(previous.A, ", "), strings.Join(current.A, ", ")))
+	}
+	if !equalStrings(previous.AAAA, current.AAAA) {
+		changes = append(changes, fmt.Sprintf("AAAA records changed from [%s] to [%s]",
+			strings.Join(previous.AAAA, ", "), strings.Join(current.AAAA, ", ")))
+	}
+	if stringValue(previous.CNAME) != stringValue(current.CNAME) {
+		changes = append(changes, fmt.Sprintf("CNAME changed from %q to %q",
+			stringValue(previous.CNAME), stringValue(current.CNAME)))
+	}
+	// An empty NS answer is usually a lookup hiccup rather than a delegation change
+	if len(current.NS) > 0 && !equalStrings(previous.NS, current.NS) {
+		changes = append(changes, fmt.Sprintf("nameservers changed from [%s] to [%s]",
+			strings.Join(previous.NS, ", "), strings.Join(current.NS, ", ")))
+	}
+	return changes
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,169 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// resolveTimeout bounds a single lookup against one resolver
+const resolveTimeout = 5 * time.Second
+
+// reservedNetworks are answers a public domain should never resolve to. Resolvers
+// returning them are typically poisoned or serving a block page.
+var reservedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+)
+
+// lookup resolves host against a specific DNS server (host:port) and returns sorted
+// IPs along with the CNAME target, empty when host is not an alias
+func lookup(host, server string) ([]string, string, error) {
+	resolver := serverResolver(server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*resolveTimeout)
+	defer cancel()
+
+	addrs, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ips := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		ips = append(ips, addr.IP.String())
+	}
+	sort.Strings(ips)
+
+	var target string
+	if cname, err := resolver.LookupCNAME(ctx, host); err == nil {
+		cname = strings.TrimSuffix(strings.ToLower(cname), ".")
+		if cname != strings.ToLower(host) {
+			target = cname
+		}
+	}
+	return ips, target, nil
+}
+
+// lookupASN returns the number of the autonomous system announcing ip, taken from the
+// Team Cymru IP-to-ASN TXT records through the given DNS server
+func lookupASN(ip, server string) (string, error) {
+	name, err := asnQueryName(ip)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+
+	records, err := serverResolver(server).LookupTXT(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	// Records read "13335 | 104.16.0.0/12 | US | arin | 2014-03-28", a prefix announced
+	// by several systems lists all of them space separated in the first field
+	for _, record := range records {
+		if asns := strings.Fields(strings.SplitN(record, "|", 2)[0]); len(asns) > 0 {
+			return asns[0], nil
+		}
+	}
+	return "", fmt.Errorf("no ASN found for %s", ip)
+}
+
+// asnQueryName builds the origin lookup name of ip: reversed octets for IPv4 and
+// reversed nibbles for IPv6
+func asnQueryName(ip string) (string, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return "", fmt.Errorf("invalid IP address %q", ip)
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return fmt.Sprintf("%d.%d.%d.%d.origin.asn.cymru.com", v4[3], v4[2], v4[1], v4[0]), nil
+	}
+
+	const hexDigits = "0123456789abcdef"
+	labels := make([]string, 0, 32)
+	for i := len(parsed) - 1; i >= 0; i-- {
+		labels = append(labels, string(hexDigits[parsed[i]&0x0f]), string(hexDigits[parsed[i]>>4]))
+	}
+	return strings.Join(labels, ".") + ".origin6.asn.cymru.com", nil
+}
+
+// serverResolver returns a resolver sending every query to server (host:port)
+func serverResolver(server string) *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			d := net.Dialer{Timeout: resolveTimeout}
+			return d.DialContext(ctx, network, server)
+		},
+	}
+}
+
+// hostname extracts the host to resolve from a domain entry, which may be a full URL
+func hostname(name string) string {
+	if !strings.Contains(name, "://") {
+		name = "https://" + name
+	}
+	parsed, err := url.Parse(name)
+	if err != nil || parsed.Hostname() == "" {
+		return name
+	}
+	return parsed.Hostname()
+}
+
+// cnameZone returns the provider zone of a CNAME target. CDNs hand out different edge
+// hostnames per region, but they share the provider's zone. Zones CDNs list as private
+// public suffixes, like cloudfront.net, are the provider zone themselves.
+func cnameZone(cname string) string {
+	if cname == "" {
+		return ""
+	}
+	if suffix, icann := publicsuffix.PublicSuffix(cname); !icann && strings.Contains(suffix, ".") {
+		return suffix
+	}
+	zone, err := publicsuffix.EffectiveTLDPlusOne(cname)
+	if err != nil {
+		return cname
+	}
+	return zone
+}
+
+// isReserved reports whether ip falls in a private, loopback or otherwise reserved range
+func isReserved(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range reservedNetworks {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
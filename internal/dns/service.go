@@ -0,0 +1,324 @@
+package dns
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"domain-detection-go/internal/domain"
+	"domain-detection-go/internal/events"
+	"domain-detection-go/internal/notification"
+	"domain-detection-go/pkg/model"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// errDomainNotFound is returned for unknown domains. The domain package is referenced
+// through it because comparisons name their domain values "domain".
+var errDomainNotFound = domain.ErrDomainNotFound
+
+// BaselineRegion is the resolver region every regional answer is compared against
+const BaselineRegion = "GLOBAL"
+
+// DNSService compares how a domain resolves from resolvers in each monitored region and
+// tracks changes to its DNS records
+type DNSService struct {
+	db        *sqlx.DB
+	events    *events.Bus
+	notifiers *notification.Fanout
+	inFlight  sync.Map // domain IDs with a comparison currently running
+	asns      sync.Map // IP address to the ASN announcing it, as seen by lookupASN
+}
+
+// NewDNSService creates a new DNS service that compares resolution and snapshots the
+// records after every domain check
+func NewDNSService(db *sqlx.DB, eventBus *events.Bus, notifiers *notification.Fanout) *DNSService {
+	s := &DNSService{db: db, events: eventBus, notifiers: notifiers}
+	if eventBus != nil {
+		eventBus.Subscribe(s.handleDomainChecked, events.DomainChecked)
+	}
+	return s
+}
+
+// handleDomainChecked runs a comparison and a record snapshot in the background so
+// checks are not slowed down
+func (s *DNSService) handleDomainChecked(e events.Event) {
+	if _, running := s.inFlight.LoadOrStore(e.DomainID, true); running {
+		return
+	}
+
+	go func() {
+		defer s.inFlight.Delete(e.DomainID)
+		if _, err := s.CompareDomain(e.DomainID); err != nil {
+			slog.Error("DNS comparison failed", "domain_id", e.DomainID, "error", err)
+		}
+
+		var domain model.Domain
+		err := s.db.Get(&domain, "SELECT id, user_id, name, COALESCE(region, '') AS region FROM domains WHERE id = $1", e.DomainID)
+		if err != nil {
+			slog.Error("Failed to load domain for DNS snapshot", "domain_id", e.DomainID, "error", err)
+			return
+		}
+		if err := s.SnapshotDomain(domain); err != nil {
+			slog.Error("DNS snapshot failed", "domain", domain.Name, "error", err)
+		}
+	}()
+}
+
+// GetResolvers returns every configured resolver
+func (s *DNSService) GetResolvers() ([]model.DNSResolver, error) {
+	var resolvers []model.DNSResolver
+	err := s.db.Select(&resolvers, `
+        SELECT id, region_code, name, address, COALESCE(is_active, true) AS is_active
+        FROM dns_resolvers
+        ORDER BY region_code, id
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get DNS resolvers: %w", err)
+	}
+	return resolvers, nil
+}
+
+// GetComparison returns the latest comparison for a domain, or nil if none has run yet
+func (s *DNSService) GetComparison(domainID int) (*model.DNSComparison, error) {
+	var row struct {
+		model.DNSComparison
+		ResultsJSON []byte `db:"results"`
+	}
+	err := s.db.Get(&row, `
+        SELECT domain_id, divergent, reason, results, compared_at
+        FROM domain_dns_comparisons
+        WHERE domain_id = $1
+    `, domainID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get DNS comparison: %w", err)
+	}
+
+	comparison := row.DNSComparison
+	if err := json.Unmarshal(row.ResultsJSON, &comparison.Results); err != nil {
+		return nil, fmt.Errorf("failed to parse DNS comparison results: %w", err)
+	}
+	return &comparison, nil
+}
+
+// CompareDomain resolves a domain from the baseline resolvers and the active resolvers
+// of every region the domain is monitored in, stores the comparison and publishes a
+// DNSDivergence event when the domain starts diverging
+func (s *DNSService) CompareDomain(domainID int) (*model.DNSComparison, error) {
+	var domain model.Domain
+	err := s.db.Get(&domain, `
+        SELECT id, user_id, name, COALESCE(region, '') AS region, extra_regions
+        FROM domains WHERE id = $1
+    `, domainID)
+	if err == sql.ErrNoRows {
+		return nil, errDomainNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var resolvers []model.DNSResolver
+	err = s.db.Select(&resolvers, `
+        SELECT id, region_code, name, address, is_active
+        FROM dns_resolvers
+        WHERE is_active = true AND (region_code = $1 OR region_code = ANY($2))
+        ORDER BY region_code, id
+    `, BaselineRegion, pq.Array(domain.Regions()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get DNS resolvers: %w", err)
+	}
+
+	host := hostname(domain.Name)
+	results := make([]model.DNSResolution, len(resolvers))
+
+	var wg sync.WaitGroup
+	for i, resolver := range resolvers {
+		wg.Add(1)
+		go func(i int, resolver model.DNSResolver) {
+			defer wg.Done()
+			result := model.DNSResolution{
+				RegionCode: resolver.RegionCode,
+				Resolver:   resolver.Name,
+				Address:    resolver.Address,
+				IPs:        []string{},
+			}
+			ips, cname, err := lookup(host, resolver.Address)
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.IPs = ips
+				result.CNAME = cname
+			}
+			results[i] = result
+		}(i, resolver)
+	}
+	wg.Wait()
+
+	reasons := compareResults(results, s.asnLookup(resolvers))
+	comparison := &model.DNSComparison{
+		DomainID:  domainID,
+		Divergent: len(reasons) > 0,
+		Results:   results,
+	}
+	if comparison.Divergent {
+		reason := strings.Join(reasons, "; ")
+		comparison.Reason = &reason
+	}
+
+	previous, err := s.GetComparison(domainID)
+	if err != nil {
+		slog.Error("Failed to load previous DNS comparison", "domain_id", domainID, "error", err)
+	}
+
+	resultsJSON, err := json.Marshal(results)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode DNS comparison results: %w", err)
+	}
+
+	err = s.db.Get(&comparison.ComparedAt, `
+        INSERT INTO domain_dns_comparisons (domain_id, divergent, reason, results, compared_at)
+        VALUES ($1, $2, $3, $4, NOW())
+        ON CONFLICT (domain_id)
+        DO UPDATE SET divergent = $2, reason = $3, results = $4, compared_at = NOW()
+        RETURNING compared_at
+    `, domainID, comparison.Divergent, comparison.Reason, resultsJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save DNS comparison: %w", err)
+	}
+
+	if comparison.Divergent && (previous == nil || !previous.Divergent) {
+		slog.Warn("Divergent DNS answers", "domain", domain.Name, "reason", *comparison.Reason)
+		s.events.Publish(events.Event{
+			Type:     events.DNSDivergence,
+			UserID:   domain.UserID,
+			DomainID: domainID,
+			Payload: map[string]interface{}{
+				"domain":  domain.Name,
+				"reason":  *comparison.Reason,
+				"results": results,
+			},
+		})
+	}
+
+	return comparison, nil
+}
+
+// asnLookup returns a function giving the ASN announcing an IP, queried through the
+// first baseline resolver and cached for the lifetime of the service. It returns an
+// empty string when the ASN is unknown.
+func (s *DNSService) asnLookup(resolvers []model.DNSResolver) func(ip string) string {
+	var server string
+	for _, resolver := range resolvers {
+		if resolver.RegionCode == BaselineRegion {
+			server = resolver.Address
+			break
+		}
+	}
+
+	return func(ip string) string {
+		if asn, ok := s.asns.Load(ip); ok {
+			return asn.(string)
+		}
+		if server == "" {
+			return ""
+		}
+		asn, err := lookupASN(ip, server)
+		if err != nil {
+			slog.Debug("ASN lookup failed", "ip", ip, "error", err)
+			return ""
+		}
+		s.asns.Store(ip, asn)
+		return asn
+	}
+}
+
+// compareResults checks every regional answer against the baseline and returns
+// a reason for each divergence found. An empty result means the answers agree.
+// CDNs answer each region with nearby edges, so a regional answer agrees when it
+// shares an address, the CNAME target's zone or an announcing ASN with the baseline.
+func compareResults(results []model.DNSResolution, asnOf func(ip string) string) []string {
+	baseline := make(map[string]bool)
+	baselineZones := make(map[string]bool)
+	var baselineIPs []string
+	baselineResolved := false
+	for _, r := range results {
+		if r.RegionCode == BaselineRegion && r.Error == "" {
+			baselineResolved = true
+			for _, ip := range r.IPs {
+				baseline[ip] = true
+			}
+			baselineIPs = append(baselineIPs, r.IPs...)
+			if zone := cnameZone(r.CNAME); zone != "" {
+				baselineZones[zone] = true
+			}
+		}
+	}
+
+	var baselineASNs map[string]bool
+	sameNetwork := func(ips []string) bool {
+		if baselineASNs == nil {
+			baselineASNs = asnSet(baselineIPs, asnOf)
+		}
+		for asn := range asnSet(ips, asnOf) {
+			if baselineASNs[asn] {
+				return true
+			}
+		}
+		return false
+	}
+
+	var reasons []string
+	for _, r := range results {
+		label := fmt.Sprintf("%s (%s)", r.Resolver, r.RegionCode)
+
+		for _, ip := range r.IPs {
+			if isReserved(ip) {
+				reasons = append(reasons, fmt.Sprintf("%s returned reserved address %s", label, ip))
+				break
+			}
+		}
+
+		if r.RegionCode == BaselineRegion {
+			continue
+		}
+
+		switch {
+		case r.Error != "" && baselineResolved:
+			reasons = append(reasons, fmt.Sprintf("%s failed to resolve: %s", label, r.Error))
+		case r.Error == "" && !baselineResolved:
+			reasons = append(reasons, fmt.Sprintf("%s resolved but the baseline did not", label))
+		case r.Error == "" && baselineResolved && !overlaps(r.IPs, baseline) &&
+			!baselineZones[cnameZone(r.CNAME)] && !sameNetwork(r.IPs):
+			reasons = append(reasons, fmt.Sprintf("%s answered %s, sharing no address, CNAME target or network with the baseline",
+				label, strings.Join(r.IPs, ", ")))
+		}
+	}
+	return reasons
+}
+
+// asnSet returns the known ASNs announcing ips
+func asnSet(ips []string, asnOf func(ip string) string) map[string]bool {
+	asns := make(map[string]bool)
+	for _, ip := range ips {
+		if asn := asnOf(ip); asn != "" {
+			asns[asn] = true
+		}
+	}
+	return asns
+}
+
+func overlaps(ips []string, set map[string]bool) bool {
+	for _, ip := range ips {
+		if set[ip] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package dns
+
+import (
+	"strings"
+	"testing"
+
+	"domain-detection-go/pkg/model"
+)
+
+func TestCompareResultsToleratesCDNAnswers(t *testing.T) {
+	asns := map[string]string{
+		"104.16.1.1":  "13335",
+		"104.17.2.2":  "13335",
+		"203.0.113.9": "64500",
+	}
+	asnOf := func(ip string) string { return asns[ip] }
+
+	tests := []struct {
+		name      string
+		baseline  model.DNSResolution
+		regional  model.DNSResolution
+		divergent string
+	}{
+		{
+			name:     "shared address",
+			baseline: model.DNSResolution{IPs: []string{"198.51.100.1", "198.51.100.2"}},
+			regional: model.DNSResolution{IPs: []string{"198.51.100.2"}},
+		},
+		{
+			name:     "different edges of the same CDN zone",
+			baseline: model.DNSResolution{IPs: []string{"198.51.100.1"}, CNAME: "e1.a.akamaiedge.net"},
+			regional: model.DNSResolution{IPs: []string{"198.51.100.77"}, CNAME: "e1.b.akamaiedge.net"},
+		},
+		{
+			name:     "different addresses in the same ASN",
+			baseline: model.DNSResolution{IPs: []string{"104.16.1.1"}},
+			regional: model.DNSResolution{IPs: []string{"104.17.2.2"}},
+		},
+		{
+			name:      "unrelated network",
+			baseline:  model.DNSResolution{IPs: []string{"104.16.1.1"}},
+			regional:  model.DNSResolution{IPs: []string{"203.0.113.9"}},
+			divergent: "sharing no address",
+		},
+		{
+			name:      "reserved address",
+			baseline:  model.DNSResolution{IPs: []string{"104.16.1.1"}},
+			regional:  model.DNSResolution{IPs: []string{"127.0.0.1"}},
+			divergent: "reserved address",
+		},
+		{
+			name:      "regional resolver failed",
+			baseline:  model.DNSResolution{IPs: []string{"104.16.1.1"}},
+			regional:  model.DNSResolution{IPs: []string{}, Error: "timeout"},
+			divergent: "failed to resolve",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.baseline.RegionCode, tt.baseline.Resolver = BaselineRegion, "Google"
+			tt.regional.RegionCode, tt.regional.Resolver = "VN", "Viettel"
+
+			reasons := strings.Join(compareResults([]model.DNSResolution{tt.baseline, tt.regional}, asnOf), "; ")
+			if tt.divergent == "" && reasons != "" {
+				t.Errorf("got divergence %q, want none", reasons)
+			}
+			if tt.divergent != "" && !strings.Contains(reasons, tt.divergent) {
+				t.Errorf("got %q, want a reason containing %q", reasons, tt.divergent)
+			}
+		})
+	}
+}
+
+func TestASNQueryName(t *testing.T) {
+	tests := map[string]string{
+		"104.16.1.2":  "2.1.16.104.origin.asn.cymru.com",
+		"2001:db8::1": "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.origin6.asn.cymru.com",
+	}
+	for ip, want := range tests {
+		got, err := asnQueryName(ip)
+		if err != nil || got != want {
+			t.Errorf("asnQueryName(%q) = %q, %v, want %q", ip, got, err, want)
+		}
+	}
+}
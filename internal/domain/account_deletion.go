@@ -0,0 +1,320 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"domain-detection-go/internal/events"
+	"domain-detection-go/internal/logging"
+	"domain-detection-go/pkg/model"
+)
+
+// accountDeletionBatchSize is how many history rows are deleted per statement, so a long
+// history is not removed in one huge transaction
+const accountDeletionBatchSize = 5000
+
+// accountDeletionStaleAfter is how long a deletion may go without progress before the
+// scheduler takes it over, as the instance running it has likely stopped
+const accountDeletionStaleAfter = 10 * time.Minute
+
+const accountDeletionColumns = `id, user_id, username, status, step, domains_total, domains_done,
+        last_error, requested_at, updated_at, completed_at`
+
+// accountDeletionStep is one part of deleting an account
+type accountDeletionStep struct {
+	name string
+	run  func(deletion *model.AccountDeletion) error
+}
+
+// accountDeletionSteps returns the steps of an account deletion in the order they run
+func (s *DomainService) accountDeletionSteps() []accountDeletionStep {
+	return []accountDeletionStep{
+		{model.AccountDeletionStepMonitors, s.deleteAccountMonitors},
+		{model.AccountDeletionStepHistory, s.deleteAccountHistory},
+		{model.AccountDeletionStepNotifications, s.deleteAccountNotifications},
+		{model.AccountDeletionStepDomains, s.deleteAccountDomains},
+		{model.AccountDeletionStepAccount, s.deleteAccount},
+	}
+}
+
+// RequestAccountDeletion starts deleting a user's account in the background. The caller
+// has already confirmed the user's password and 2FA code. The account is locked first:
+// its sessions and API keys are revoked and the user can no longer log in, so nothing
+// is added behind the deletion's back.
+func (s *DomainService) RequestAccountDeletion(ctx context.Context, userID int, username string) (*model.AccountDeletion, error) {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var deletion model.AccountDeletion
+	err = tx.Get(&deletion, `
+        INSERT INTO account_deletions (user_id, username)
+        VALUES ($1, $2)
+        ON CONFLICT (user_id) WHERE status IN ('pending', 'running') DO NOTHING
+        RETURNING `+accountDeletionColumns, userID, username)
+	if err == sql.ErrNoRows {
+		return nil, ErrAccountDeletionInProgress
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to request account deletion: %w", err)
+	}
+
+	if _, err := tx.Exec("UPDATE users SET deletion_requested_at = NOW() WHERE id = $1", userID); err != nil {
+		return nil, fmt.Errorf("failed to lock account: %w", err)
+	}
+	if _, err := tx.Exec("UPDATE user_sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL", userID); err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM api_keys WHERE user_id = $1", userID); err != nil {
+		return nil, fmt.Errorf("failed to revoke api keys: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit account deletion: %w", err)
+	}
+
+	logging.FromContext(ctx, s.logger).Info("Account deletion requested", "user_id", userID, "deletion_id", deletion.ID)
+	s.goAsync(func() {
+		s.runAccountDeletion(deletion.ID)
+	})
+	return &deletion, nil
+}
+
+// GetAccountDeletion returns the most recent deletion of a user's account
+func (s *DomainService) GetAccountDeletion(userID int) (*model.AccountDeletion, error) {
+	var deletion model.AccountDeletion
+	err := s.db.Get(&deletion, `
+        SELECT `+accountDeletionColumns+`
+        FROM account_deletions
+        WHERE user_id = $1
+        ORDER BY id DESC
+        LIMIT 1
+    `, userID)
+	if err == sql.ErrNoRows {
+		return nil, ErrAccountDeletionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account deletion: %w", err)
+	}
+	return &deletion, nil
+}
+
+// runAccountDeletion runs the remaining steps of a deletion. A deletion that was
+// interrupted or failed resumes at the step it stopped in.
+func (s *DomainService) runAccountDeletion(deletionID int) {
+	// Claim the deletion so that the request and the scheduler never both run it
+	var deletion model.AccountDeletion
+	err := s.db.Get(&deletion, `
+        UPDATE account_deletions
+        SET status = 'running', updated_at = NOW()
+        WHERE id = $1
+          AND (status = 'pending'
+               OR (status IN ('running', 'failed') AND updated_at < NOW() - make_interval(secs => $2)))
+        RETURNING `+accountDeletionColumns, deletionID, int(accountDeletionStaleAfter.Seconds()))
+	if err == sql.ErrNoRows {
+		return
+	}
+	if err != nil {
+		s.logger.Error("Failed to start account deletion", "deletion_id", deletionID, "error", err)
+		return
+	}
+	logger := s.logger.With("user_id", deletion.UserID, "deletion_id", deletion.ID)
+
+	steps := s.accountDeletionSteps()
+	first := 0
+	for i, step := range steps {
+		if step.name == deletion.Step {
+			first = i
+		}
+	}
+
+	for _, step := range steps[first:] {
+		_, err := s.db.Exec("UPDATE account_deletions SET step = $1, updated_at = NOW() WHERE id = $2", step.name, deletion.ID)
+		if err == nil {
+			logger.Info("Account deletion step started", "step", step.name)
+			err = step.run(&deletion)
+		}
+		if err != nil {
+			logger.Error("Account deletion failed", "step", step.name, "error", err)
+			_, dbErr := s.db.Exec(`
+                UPDATE account_deletions SET status = 'failed', last_error = $1, updated_at = NOW()
+                WHERE id = $2
+            `, err.Error(), deletion.ID)
+			if dbErr != nil {
+				logger.Error("Failed to record account deletion failure", "error", dbErr)
+			}
+			return
+		}
+	}
+
+	_, err = s.db.Exec(`
+        UPDATE account_deletions SET status = 'completed', last_error = NULL, completed_at = NOW(), updated_at = NOW()
+        WHERE id = $1
+    `, deletion.ID)
+	if err != nil {
+		logger.Error("Failed to complete account deletion", "error", err)
+		return
+	}
+	logger.Info("Account deleted", "username", deletion.Username, "domains", deletion.DomainsTotal)
+}
+
+// deleteAccountMonitors deletes the provider monitors of every domain of the account,
+// including archived and deleted ones, and stops their built-in checks. Deletions that
+// fail are retried from the monitor tasks, which outlive the account.
+func (s *DomainService) deleteAccountMonitors(deletion *model.AccountDeletion) error {
+	var domains []model.Domain
+	err := s.db.Select(&domains, `
+        SELECT id, user_id, monitor_guid, site24x7_monitor_id, direct_monitor_id
+        FROM domains
+        WHERE user_id = $1
+        ORDER BY id
+    `, deletion.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get domains: %w", err)
+	}
+
+	deletion.DomainsTotal = len(domains)
+	_, err = s.db.Exec(`
+        UPDATE account_deletions SET domains_total = $1, domains_done = 0, updated_at = NOW() WHERE id = $2
+    `, deletion.DomainsTotal, deletion.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update progress: %w", err)
+	}
+
+	for i, domain := range domains {
+		for _, p := range s.monitorProviders() {
+			if err := s.deleteProviderMonitor(domain.UserID, domain.ID, p.name, p.monitorID(domain)); err != nil {
+				s.logger.Warn("Failed to delete monitor", "provider", p.name, "domain_id", domain.ID, "error", err)
+			}
+		}
+		s.setDirectMonitorStatus(domain, false)
+
+		// Forget the monitors so a resumed deletion does not delete them twice
+		_, err := s.db.Exec(`
+            UPDATE domains
+            SET active = false, monitor_guid = '', site24x7_monitor_id = NULL, direct_monitor_id = NULL, updated_at = NOW()
+            WHERE id = $1
+        `, domain.ID)
+		if err != nil {
+			return fmt.Errorf("failed to clear monitors of domain %d: %w", domain.ID, err)
+		}
+		s.events.Publish(events.Event{Type: events.DomainDeleted, UserID: domain.UserID, DomainID: domain.ID})
+
+		deletion.DomainsDone = i + 1
+		_, err = s.db.Exec("UPDATE account_deletions SET domains_done = $1, updated_at = NOW() WHERE id = $2", deletion.DomainsDone, deletion.ID)
+		if err != nil {
+			return fmt.Errorf("failed to update progress: %w", err)
+		}
+	}
+	return nil
+}
+
+// deleteAccountHistory deletes the check and notification history and the deep check
+// orders of the account in batches
+func (s *DomainService) deleteAccountHistory(deletion *model.AccountDeletion) error {
+	queries := []struct {
+		table string
+		query string
+	}{
+		{"notification_history", `
+            DELETE FROM notification_history WHERE id IN (
+                SELECT h.id FROM notification_history h JOIN domains d ON d.id = h.domain_id
+                WHERE d.user_id = $1 LIMIT $2
+            )`},
+		{"domain_check_history", `
+            DELETE FROM domain_check_history WHERE id IN (
+                SELECT h.id FROM domain_check_history h JOIN domains d ON d.id = h.domain_id
+                WHERE d.user_id = $1 LIMIT $2
+            )`},
+		{"deep_check_orders", `
+            DELETE FROM deep_check_orders WHERE id IN (
+                SELECT id FROM deep_check_orders WHERE user_id = $1 LIMIT $2
+            )`},
+	}
+
+	for _, q := range queries {
+		for {
+			result, err := s.db.Exec(q.query, deletion.UserID, accountDeletionBatchSize)
+			if err != nil {
+				return fmt.Errorf("failed to delete %s: %w", q.table, err)
+			}
+			deleted, _ := result.RowsAffected()
+			if _, err := s.db.Exec("UPDATE account_deletions SET updated_at = NOW() WHERE id = $1", deletion.ID); err != nil {
+				return fmt.Errorf("failed to update progress: %w", err)
+			}
+			if deleted < accountDeletionBatchSize {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// deleteAccountNotifications deletes the Telegram and email configs of the account
+func (s *DomainService) deleteAccountNotifications(deletion *model.AccountDeletion) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM telegram_configs WHERE user_id = $1", deletion.UserID); err != nil {
+		return fmt.Errorf("failed to delete telegram configs: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM email_configs WHERE user_id = $1", deletion.UserID); err != nil {
+		return fmt.Errorf("failed to delete email configs: %w", err)
+	}
+	return tx.Commit()
+}
+
+// deleteAccountDomains deletes the domains of the account with what remains of their data
+func (s *DomainService) deleteAccountDomains(deletion *model.AccountDeletion) error {
+	if _, err := s.db.Exec("DELETE FROM domains WHERE user_id = $1", deletion.UserID); err != nil {
+		return fmt.Errorf("failed to delete domains: %w", err)
+	}
+	return nil
+}
+
+// deleteAccount deletes the user, which also removes their sessions, API keys and
+// settings
+func (s *DomainService) deleteAccount(deletion *model.AccountDeletion) error {
+	if _, err := s.db.Exec("DELETE FROM users WHERE id = $1", deletion.UserID); err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	return nil
+}
+
+// RunScheduledAccountDeletions resumes account deletions whose instance stopped running
+// them and retries failed ones, checking every minute. The owner of a locked account
+// cannot request the deletion again.
+func (s *DomainService) RunScheduledAccountDeletions(ctx context.Context) {
+	s.logger.Info("RunScheduledAccountDeletions")
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("RunScheduledAccountDeletions stopped")
+			return
+		case <-ticker.C:
+			var ids []int
+			err := s.db.Select(&ids, `
+                SELECT id FROM account_deletions
+                WHERE status IN ('pending', 'running', 'failed') AND updated_at < NOW() - make_interval(secs => $1)
+                ORDER BY id
+            `, int(accountDeletionStaleAfter.Seconds()))
+			if err != nil {
+				s.logger.Error("Failed to get stalled account deletions", "error", err)
+				continue
+			}
+			for _, id := range ids {
+				s.logger.Info("Resuming account deletion", "deletion_id", id)
+				s.runAccountDeletion(id)
+			}
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package domain
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"domain-detection-go/internal/events"
+	"domain-detection-go/pkg/model"
+)
+
+// archiveCheckTimeout bounds the daily internal probe of an archived domain
+const archiveCheckTimeout = 15 * time.Second
+
+// ArchiveDomain deletes the provider monitors of a domain and keeps it listed with
+// only a daily internal check, so it no longer counts towards the domain limit
+func (s *DomainService) ArchiveDomain(userID, domainID int) error {
+	domain, err := s.GetDomain(domainID, userID)
+	if err != nil {
+		return err
+	}
+	if domain.ArchivedAt != nil {
+		return ErrDomainAlreadyArchived
+	}
+
+	if err := s.deleteProviderMonitor(userID, domainID, model.ProviderUptrends, domain.GetMonitorGuid()); err != nil {
+		s.logger.Error("Failed to delete monitor of archived domain", "provider", model.ProviderUptrends, "domain_id", domainID, "error", err)
+	}
+	if err := s.deleteProviderMonitor(userID, domainID, model.ProviderSite24x7, domain.GetSite24x7MonitorID()); err != nil {
+		s.logger.Error("Failed to delete monitor of archived domain", "provider", model.ProviderSite24x7, "domain_id", domainID, "error", err)
+	}
+	// The built-in check keeps its settings and is resumed on unarchive
+	s.setDirectMonitorStatus(*domain, false)
+
+	_, err = s.db.Exec(`
+        UPDATE domains
+        SET archived_at = NOW(), active = false, monitor_guid = '', site24x7_monitor_id = NULL,
+            recovery_pending = false, recovery_successes = 0, failure_pending = false, updated_at = NOW()
+        WHERE id = $1 AND user_id = $2
+    `, domainID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to archive domain: %w", err)
+	}
+
+	s.events.Publish(events.Event{Type: events.DomainUpdated, UserID: userID, DomainID: domainID})
+	return nil
+}
+
+// UnarchiveDomain reactivates an archived domain and recreates its provider monitors
+func (s *DomainService) UnarchiveDomain(userID, domainID int) error {
+	domain, err := s.GetDomain(domainID, userID)
+	if err != nil {
+		return err
+	}
+	if domain.ArchivedAt == nil {
+		return ErrDomainNotArchived
+	}
+	if domain.VerificationPending() {
+		return ErrDomainNotVerified
+	}
+
+	var count int
+	err = s.db.Get(&count, "SELECT COUNT(*) FROM domains WHERE user_id = $1 AND archived_at IS NULL AND deleted_at IS NULL", userID)
+	if err != nil {
+		return err
+	}
+	limit, err := s.GetDomainLimit(userID)
+	if err != nil {
+		return err
+	}
+	if count >= limit {
+		return ErrDomainLimitReached
+	}
+
+	_, err = s.db.Exec(`
+        UPDATE domains
+        SET archived_at = NULL, active = true, updated_at = NOW()
+        WHERE id = $1 AND user_id = $2
+    `, domainID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to unarchive domain: %w", err)
+	}
+
+	s.goCreateMonitor(context.Background(), userID, domainID, domain.Name, domain.Regions(), domain.Interval)
+	s.setDirectMonitorStatus(*domain, true)
+
+	s.events.Publish(events.Event{Type: events.DomainUpdated, UserID: userID, DomainID: domainID})
+	return nil
+}
+
+// RunArchiveChecks probes every archived domain that was not checked in the last day
+func (s *DomainService) RunArchiveChecks() error {
+	var domains []model.Domain
+	err := s.db.Select(&domains, `
+        SELECT id, user_id, name
+        FROM domains
+        WHERE archived_at IS NOT NULL AND deleted_at IS NULL
+          AND (last_check IS NULL OR last_check < NOW() - INTERVAL '1 day')
+    `)
+	if err != nil {
+		return fmt.Errorf("failed to get archived domains: %w", err)
+	}
+
+	client := &http.Client{
+		Timeout: archiveCheckTimeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // Only reachability is recorded
+		},
+	}
+
+	for _, domain := range domains {
+		statusCode, totalTime, errorDescription := archiveCheck(client, domain.Name)
+
+		if err := s.UpdateDomainStatus(domain.ID, statusCode, 0, totalTime, errorDescription); err != nil {
+			s.logger.Error("Failed to update archive check status", "domain", domain.Name, "error", err)
+			continue
+		}
+
+		var description sql.NullString
+		if errorDescription != "" {
+			description = sql.NullString{String: errorDescription, Valid: true}
+		}
+		_, err := s.db.Exec(`
+            INSERT INTO domain_archive_checks (domain_id, status_code, total_time, error_description, checked_at)
+            VALUES ($1, $2, $3, $4, NOW())
+        `, domain.ID, statusCode, totalTime, description)
+		if err != nil {
+			s.logger.Error("Failed to record archive check", "domain", domain.Name, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// RunScheduledArchiveChecks checks archived domains once a day
+func (s *DomainService) RunScheduledArchiveChecks(ctx context.Context) {
+	s.logger.Info("RunScheduledArchiveChecks")
+	ticker := time.NewTicker(1 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("RunScheduledArchiveChecks stopped")
+			return
+		case <-ticker.C:
+			if err := s.RunArchiveChecks(); err != nil {
+				s.logger.Error("Archive checks failed", "error", err)
+			}
+		}
+	}
+}
+
+// archiveCheck performs a single GET of the domain, returning status code 0 on connection errors
+func archiveCheck(client *http.Client, domainName string) (int, int, string) {
+	start := time.Now()
+	resp, err := client.Get(canonicalDomainName(domainName))
+	totalTime := int(time.Since(start).Milliseconds())
+	if err != nil {
+		return 0, totalTime, err.Error()
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode, totalTime, ""
+}
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"time"
+
+	"domain-detection-go/internal/events"
+	"domain-detection-go/pkg/model"
+)
+
+// subscribeCheckConsumers records check history and manages incidents from the events
+// published by the monitor, and turns outages into alerts
+func (s *DomainService) subscribeCheckConsumers() {
+	if s.events == nil {
+		return
+	}
+	s.events.Subscribe(s.handleDomainChecked, events.DomainChecked)
+	s.events.Subscribe(s.handleDomainEvaluated, events.DomainEvaluated)
+}
+
+// handleDomainChecked stores a check in the domain's history
+func (s *DomainService) handleDomainChecked(e events.Event) {
+	result, ok := e.Payload["result"].(model.DomainCheckResult)
+	if !ok {
+		return
+	}
+	if err := s.RecordCheck(e.DomainID, result); err != nil {
+		s.logger.Error("Failed to record check history", "domain_id", e.DomainID, "error", err)
+	}
+}
+
+// handleDomainEvaluated attaches a failed check to the domain's incident or resolves it
+// on recovery, then publishes an alert unless the domain is in maintenance or the incident
+// is acknowledged. Down alerts carry the remediation runbook of the domain or its region.
+func (s *DomainService) handleDomainEvaluated(e events.Event) {
+	domain, ok := e.Payload["domain"].(model.Domain)
+	if !ok {
+		return
+	}
+	statusChanged, _ := e.Payload["status_changed"].(bool)
+	available := domain.Available()
+	logger := s.logger.With("domain_id", domain.ID, "domain", domain.Name)
+
+	if !available {
+		incident, err := s.RecordIncidentFailure(domain)
+		if err != nil {
+			logger.Error("Failed to record incident", "error", err)
+		}
+		domain.Incident = incident
+	} else if statusChanged {
+		incident, err := s.ResolveIncident(domain.ID)
+		if err != nil {
+			logger.Error("Failed to resolve incident", "error", err)
+		}
+		domain.Incident = incident
+	}
+
+	if available && !statusChanged {
+		return
+	}
+
+	// An acknowledged outage stays quiet until it recovers or the acknowledgement expires
+	if !available && domain.Incident != nil && domain.Incident.Acknowledged(time.Now()) {
+		logger.Info("Incident acknowledged, skipping notification", "incident_id", domain.Incident.ID,
+			"acknowledged_by", domain.Incident.AcknowledgedBy)
+		return
+	}
+
+	// Planned maintenance silences status alerts and deep checks. Checks and incidents
+	// are still recorded.
+	inMaintenance, err := s.InMaintenance(domain, time.Now())
+	if err != nil {
+		logger.Error("Failed to check maintenance windows", "error", err)
+	}
+	if inMaintenance {
+		logger.Info("Domain in maintenance, skipping notification")
+		return
+	}
+
+	if !available {
+		runbook, err := s.ResolveRunbook(domain)
+		if err != nil {
+			logger.Error("Failed to resolve runbook", "error", err)
+		}
+		domain.Runbook = runbook
+	}
+
+	s.events.Publish(events.Event{
+		Type:     events.DomainAlert,
+		UserID:   domain.UserID,
+		DomainID: domain.ID,
+		Payload:  map[string]interface{}{"domain": domain, "status_changed": statusChanged},
+	})
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"fmt"
+
+	"domain-detection-go/pkg/model"
+)
+
+// validateCheckType checks that a port is given for TCP checks and only for them
+func validateCheckType(checkType string, port *int) error {
+	switch {
+	case checkType == model.CheckTypeTCP && port == nil:
+		return &CheckTypeError{CheckType: checkType, PortRequired: true}
+	case checkType != model.CheckTypeTCP && port != nil:
+		return &CheckTypeError{CheckType: checkType}
+	}
+	return nil
+}
+
+// domainCheckType returns the check type of a domain
+func (s *DomainService) domainCheckType(domainID int) (string, error) {
+	var checkType string
+	if err := s.db.Get(&checkType, "SELECT check_type FROM domains WHERE id = $1", domainID); err != nil {
+		return model.CheckTypeHTTPS, fmt.Errorf("failed to get check type: %w", err)
+	}
+	return checkType, nil
+}
+
+// createDirectMonitor creates the built-in monitor of a domain and stores its ID
+func (s *DomainService) createDirectMonitor(userID, domainID int, fullURL, displayName string, region string, interval int) (string, error) {
+	tags := s.MonitorTags(userID, domainID)
+	monitorID, err := s.directClient.CreateMonitor(fullURL, tags.MonitorName(displayName), tags, []string{region}, interval, model.DefaultHTTPCheckSettings())
+	if err != nil {
+		return "", err
+	}
+	if _, err := s.db.Exec("UPDATE domains SET direct_monitor_id = $1, updated_at = NOW() WHERE id = $2", monitorID, domainID); err != nil {
+		if delErr := s.directClient.DeleteMonitor(monitorID); delErr != nil {
+			s.logger.Error("Failed to delete orphaned monitor", "provider", model.ProviderDirect, "monitor_id", monitorID, "error", delErr)
+		}
+		return "", fmt.Errorf("failed to update domain with direct monitor ID: %w", err)
+	}
+	return monitorID, nil
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+
+	"domain-detection-go/pkg/model"
+)
+
+// validateContentMatch checks a domain's expected content before it is saved. An empty
+// match type turns content matching off and accepts any pattern.
+func validateContentMatch(matchType, pattern string) error {
+	switch matchType {
+	case "":
+		return nil
+	case model.ContentMatchPresent, model.ContentMatchAbsent:
+	case model.ContentMatchRegex:
+		if _, err := regexp.Compile(pattern); err != nil {
+			return &ContentMatchError{Reason: fmt.Sprintf("pattern is not a valid regular expression: %v", err)}
+		}
+	default:
+		return &ContentMatchError{Reason: "type must be present, absent or regex"}
+	}
+
+	if pattern == "" {
+		return &ContentMatchError{Reason: "pattern is required"}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+
+	"domain-detection-go/internal/events"
+	"domain-detection-go/pkg/model"
+)
+
+// GetDirectCheck returns the built-in HTTP check settings of a domain owned by the user
+func (s *DomainService) GetDirectCheck(userID, domainID int) (*model.DirectCheckSettings, error) {
+	domain, err := s.GetDomain(domainID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := model.DirectCheckSettings{DomainID: domainID, FollowRedirects: true}
+	if domain.GetDirectMonitorID() == "" {
+		return &settings, nil
+	}
+
+	err = s.db.Get(&settings, `
+        SELECT domain_id, is_active, timeout_seconds, user_agent, follow_redirects, max_redirects
+        FROM direct_monitors
+        WHERE domain_id = $1
+    `, domainID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get direct check settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// UpdateDirectCheck enables, disables or reconfigures the built-in HTTP check of a
+// domain. Disabling it removes the monitor together with its settings.
+func (s *DomainService) UpdateDirectCheck(userID, domainID int, req model.DirectCheckRequest) (*model.DirectCheckSettings, error) {
+	if s.directClient == nil {
+		return nil, ErrProviderNotConfigured
+	}
+
+	domain, err := s.GetDomain(domainID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if domain.ArchivedAt != nil {
+		return nil, ErrDomainArchived
+	}
+	if domain.VerificationPending() && req.Enabled {
+		return nil, ErrDomainNotVerified
+	}
+
+	// TCP and ping checks are only run by the built-in checker
+	if !req.Enabled && !model.IsHTTPCheckType(domain.CheckType) {
+		return nil, ErrDirectCheckRequired
+	}
+
+	monitorID := domain.GetDirectMonitorID()
+	if !req.Enabled {
+		if monitorID != "" {
+			if err := s.directClient.DeleteMonitor(monitorID); err != nil {
+				return nil, err
+			}
+			if _, err := s.db.Exec("UPDATE domains SET direct_monitor_id = NULL, updated_at = NOW() WHERE id = $1", domainID); err != nil {
+				return nil, fmt.Errorf("failed to clear direct monitor ID: %w", err)
+			}
+			s.events.Publish(events.Event{Type: events.DomainUpdated, UserID: userID, DomainID: domainID})
+		}
+		return s.GetDirectCheck(userID, domainID)
+	}
+
+	if monitorID == "" {
+		displayName := domain.Name
+		if parsedURL, err := url.Parse(domain.Name); err == nil && parsedURL.Hostname() != "" {
+			displayName = parsedURL.Hostname()
+		}
+		monitorID, err = s.createDirectMonitor(userID, domainID, domain.Name, displayName, domain.Region, domain.Interval)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	followRedirects := true
+	if req.FollowRedirects != nil {
+		followRedirects = *req.FollowRedirects
+	}
+	_, err = s.db.Exec(`
+        UPDATE direct_monitors
+        SET timeout_seconds = $1, user_agent = $2, follow_redirects = $3, max_redirects = $4, updated_at = NOW()
+        WHERE domain_id = $5
+    `, req.TimeoutSeconds, req.UserAgent, followRedirects, req.MaxRedirects, domainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update direct check settings: %w", err)
+	}
+
+	s.events.Publish(events.Event{Type: events.DomainUpdated, UserID: userID, DomainID: domainID})
+	return s.GetDirectCheck(userID, domainID)
+}
+
+// setDirectMonitorStatus pauses or resumes the built-in check of a domain, if it has one
+func (s *DomainService) setDirectMonitorStatus(domain model.Domain, isActive bool) {
+	if domain.GetDirectMonitorID() == "" || s.directClient == nil {
+		return
+	}
+	if err := s.directClient.UpdateMonitorStatus(domain.GetDirectMonitorID(), isActive); err != nil {
+		s.logger.Error("Failed to update monitor status", "provider", model.ProviderDirect, "domain_id", domain.ID, "error", err)
+	}
+}
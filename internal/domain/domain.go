@@ -1,19 +1,26 @@
 package domain
 
 import (
+	"context"
 	"database/sql"
 	"errors"
-	"log"
+	"log/slog"
 	"net/url"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
+	"domain-detection-go/internal/audit"
+	"domain-detection-go/internal/etag"
+	"domain-detection-go/internal/events"
+	"domain-detection-go/internal/logging"
 	"domain-detection-go/pkg/model"
 
 	"fmt"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 // DomainService handles domain operations
@@ -21,15 +28,68 @@ type DomainService struct {
 	db             *sqlx.DB
 	uptrendsClient MonitorClient
 	site24x7Client MonitorClient
+	directClient   MonitorClient
+	events         *events.Bus
+	summaries      *summaryCache
+	environment    string // Tagged on provider monitors to tell deployments apart
+	audit          *audit.Logger
+	recreations    *recreationQueue
+	async          sync.WaitGroup // Monitor creations running in the background
+	encryptionKey  string         // Encrypts the basic auth passwords of HTTP checks
+	// New domains wait for ownership verification before they are monitored
+	requireVerification bool
+	ackTimeout          time.Duration // How long an incident acknowledgement pauses its alerts
+	logger              *slog.Logger
 }
 
 // NewDomainService creates a new domain service
-func NewDomainService(db *sqlx.DB, uptrendsClient MonitorClient, site24x7Client MonitorClient) *DomainService {
-	return &DomainService{
-		db:             db,
-		uptrendsClient: uptrendsClient,
-		site24x7Client: site24x7Client,
-	}
+func NewDomainService(db *sqlx.DB, uptrendsClient MonitorClient, site24x7Client MonitorClient, directClient MonitorClient, eventBus *events.Bus, environment string, auditLogger *audit.Logger, encryptionKey string, requireVerification bool, logger *slog.Logger) *DomainService {
+	s := &DomainService{
+		db:                  db,
+		uptrendsClient:      uptrendsClient,
+		site24x7Client:      site24x7Client,
+		directClient:        directClient,
+		events:              eventBus,
+		summaries:           newSummaryCache(),
+		environment:         environment,
+		audit:               auditLogger,
+		recreations:         newRecreationQueue(),
+		encryptionKey:       encryptionKey,
+		requireVerification: requireVerification,
+		ackTimeout:          defaultAckTimeout,
+		logger:              logger.With("component", "domain"),
+	}
+	s.subscribeSummaryInvalidation()
+	s.subscribeCheckConsumers()
+	return s
+}
+
+// goCreateMonitor creates the provider monitors of a domain in the background. The
+// context only carries the request ID into the logs; the creation outlives the request.
+func (s *DomainService) goCreateMonitor(ctx context.Context, userID, domainID int, fullURL string, domainRegions []string, interval int) {
+	ctx = context.WithoutCancel(ctx)
+	s.goAsync(func() {
+		s.createMonitorAsync(ctx, userID, domainID, fullURL, domainRegions, interval)
+	})
+}
+
+// goAsync runs provider work in the background. Wait blocks until it has finished.
+func (s *DomainService) goAsync(job func()) {
+	s.async.Add(1)
+	go func() {
+		defer s.async.Done()
+		job()
+	}()
+}
+
+// Wait blocks until the monitor creations running in the background have finished
+func (s *DomainService) Wait() {
+	s.async.Wait()
+}
+
+// MonitorTags returns the ownership tags applied to provider monitors for a domain
+func (s *DomainService) MonitorTags(userID, domainID int) model.MonitorTags {
+	return model.MonitorTags{UserID: userID, DomainID: domainID, Environment: s.environment}
 }
 
 // DEFAULT_DOMAIN_LIMIT defines the default number of domains a user can add
@@ -38,6 +98,50 @@ const DEFAULT_DOMAIN_LIMIT = 100
 // DEFAULT_INTERVAL defines the default interval in minutes
 const DEFAULT_INTERVAL = 20
 
+// MIN_INTERVAL and MAX_INTERVAL bound the check interval of every plan, in minutes
+const (
+	MIN_INTERVAL = 5
+	MAX_INTERVAL = 1440
+)
+
+// DEFAULT_MIN_INTERVAL defines the shortest interval when a user's plan has none configured
+const DEFAULT_MIN_INTERVAL = 10
+
+// GetMinInterval returns the shortest check interval allowed by the user's plan
+func (s *DomainService) GetMinInterval(userID int) (int, error) {
+	var minInterval int
+	err := s.db.Get(&minInterval, `
+        SELECT min_interval FROM plans
+        WHERE name = COALESCE((SELECT plan FROM user_settings WHERE user_id = $1), $2)
+    `, userID, model.DEFAULT_PLAN)
+
+	if err == sql.ErrNoRows {
+		return DEFAULT_MIN_INTERVAL, nil
+	}
+	if err != nil {
+		return DEFAULT_MIN_INTERVAL, fmt.Errorf("failed to get minimum interval: %w", err)
+	}
+	if minInterval < MIN_INTERVAL {
+		minInterval = MIN_INTERVAL
+	}
+	return minInterval, nil
+}
+
+// ValidateInterval checks that an interval is allowed by the user's plan. This is the
+// only place intervals are validated; every way of adding or updating domains goes
+// through it.
+func (s *DomainService) ValidateInterval(userID int, interval int) error {
+	minInterval, err := s.GetMinInterval(userID)
+	if err != nil {
+		s.logger.Warn("Failed to get minimum interval, using default", "user_id", userID, "error", err)
+	}
+
+	if interval < minInterval || interval > MAX_INTERVAL {
+		return &IntervalError{Min: minInterval, Max: MAX_INTERVAL}
+	}
+	return nil
+}
+
 // GetDomainLimit returns the domain limit for a user
 func (s *DomainService) GetDomainLimit(userID int) (int, error) {
 	var limit int
@@ -53,8 +157,14 @@ func (s *DomainService) GetDomainLimit(userID int) (int, error) {
 	return limit, nil
 }
 
-// ValidateDomainName checks if a domain name or URL is valid
+// ValidateDomainName checks if a domain name or URL is valid. Internationalized names
+// are validated in their ASCII (punycode) form.
 func (s *DomainService) ValidateDomainName(input string) bool {
+	input, _, err := idnForms(input)
+	if err != nil {
+		return false
+	}
+
 	// Check if the input is a URL with scheme
 	parsedURL, err := url.Parse(input)
 	if err != nil {
@@ -86,15 +196,29 @@ func (s *DomainService) ValidateDomainName(input string) bool {
 }
 
 // AddDomain adds a new domain to monitor
-func (s *DomainService) AddDomain(userID int, req model.DomainAddRequest) (int, error) {
+func (s *DomainService) AddDomain(ctx context.Context, userID int, req model.DomainAddRequest) (int, error) {
 	// Validate domain name
 	if !s.ValidateDomainName(req.Name) {
-		return 0, errors.New("invalid domain name format")
+		return 0, ErrInvalidDomainName
+	}
+
+	// Internationalized names are monitored in their ASCII form and displayed as entered
+	name, unicodeName, err := idnForms(req.Name)
+	if err != nil {
+		return 0, ErrInvalidDomainName
+	}
+
+	checkType := req.CheckType
+	if checkType == "" {
+		checkType = model.CheckTypeHTTPS
+	}
+	if err := validateCheckType(checkType, req.Port); err != nil {
+		return 0, err
 	}
 
 	// Check if user has reached the domain limit
 	var count int
-	err := s.db.Get(&count, "SELECT COUNT(*) FROM domains WHERE user_id = $1", userID)
+	err = s.db.Get(&count, "SELECT COUNT(*) FROM domains WHERE user_id = $1 AND archived_at IS NULL AND deleted_at IS NULL", userID)
 	if err != nil {
 		return 0, err
 	}
@@ -105,7 +229,19 @@ func (s *DomainService) AddDomain(userID int, req model.DomainAddRequest) (int,
 	}
 
 	if count >= limit {
-		return 0, errors.New("domain limit reached")
+		return 0, ErrDomainLimitReached
+	}
+
+	// Domains added without a region or interval use the user's defaults
+	settings, err := s.GetUserSettings(userID)
+	if err != nil {
+		return 0, err
+	}
+	if req.Region == "" {
+		if settings.DefaultRegion == nil {
+			return 0, ErrRegionRequired
+		}
+		req.Region = *settings.DefaultRegion
 	}
 
 	// Validate the region
@@ -115,40 +251,49 @@ func (s *DomainService) AddDomain(userID int, req model.DomainAddRequest) (int,
 		return 0, fmt.Errorf("error verifying region: %w", err)
 	}
 	if !isValidRegion {
-		return 0, errors.New("invalid region")
+		return 0, ErrInvalidRegion
+	}
+	extraRegions, err := s.normalizeExtraRegions(req.Region, req.ExtraRegions)
+	if err != nil {
+		return 0, err
 	}
 
 	// Parse URL to ensure consistent storage
-	parsedURL, err := url.Parse(req.Name)
+	parsedURL, err := url.Parse(name)
 	if err != nil {
 		return 0, fmt.Errorf("invalid URL format: %w", err)
 	}
 
-	// Ensure there's a scheme, default to https if not specified
-	fullURL := req.Name
+	// Ensure there's a scheme, default to https if not specified. Plain HTTP checks
+	// default to http; TCP and ping checks only use the host.
+	fullURL := name
 	if parsedURL.Scheme == "" {
-		fullURL = "https://" + req.Name
+		scheme := "https://"
+		if checkType == model.CheckTypeHTTP {
+			scheme = "http://"
+		}
+		fullURL = scheme + name
+		if unicodeName != "" {
+			unicodeName = scheme + unicodeName
+		}
 	}
 
-	err = s.db.Get(&count, `
-    SELECT COUNT(*) FROM domains 
-    WHERE user_id = $1 AND LOWER(name) = LOWER($2) AND region = $3
-`, userID, fullURL, req.Region)
-
+	regions := append([]string{req.Region}, extraRegions...)
+	exists, err := s.domainInRegions(userID, fullURL, regions)
 	if err != nil {
 		return 0, err
 	}
 
-	if count > 0 {
-		return 0, errors.New("domain already exists in this region")
+	if exists {
+		return 0, ErrDomainExists
 	}
 
 	// Set default interval if not provided
 	interval := req.Interval
 	if interval == 0 {
-		interval = DEFAULT_INTERVAL
-	} else if interval != 10 && interval != 20 && interval != 30 && interval != 60 && interval != 120 {
-		return 0, errors.New("interval must be 10, 20, 30, 60 or 120 minutes")
+		interval = defaultInterval(settings)
+	} else if err := s.ValidateInterval(userID, interval); err != nil {
+		return 0, err
 	}
 
 	// Insert the domain with the region and is_deep_check specified in the request
@@ -157,23 +302,45 @@ func (s *DomainService) AddDomain(userID int, req model.DomainAddRequest) (int,
 		req.IsDeepCheck = false // Ensure it's set to false if not specified
 	}
 	err = s.db.QueryRow(`
-        INSERT INTO domains (user_id, name, interval, monitor_guid, active, region, is_deep_check, created_at, updated_at)
-        VALUES ($1, $2, $3, '', true, $4, $5, $6, $6)
+        INSERT INTO domains (user_id, name, unicode_name, interval, monitor_guid, active, region, extra_regions, is_deep_check,
+                             check_type, port, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, '', true, $5, $6, $7, $8, $9, $10, $10)
         RETURNING id
-    `, userID, fullURL, interval, req.Region, req.IsDeepCheck, time.Now()).Scan(&domainID)
+    `, userID, fullURL, unicodeName, interval, req.Region, pq.Array(extraRegions), req.IsDeepCheck,
+		checkType, req.Port, time.Now()).Scan(&domainID)
 
+	// A concurrent request added the same domain after the check above
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return 0, ErrDomainExists
+	}
 	if err != nil {
 		return 0, err
 	}
 
-	// Create the monitor asynchronously in the background using the domain's region
-	go s.createMonitorAsync(domainID, fullURL, req.Region)
+	if req.HTTPCheckRequest.IsSet() {
+		if err := s.updateHTTPCheckSettings(domainID, model.DefaultHTTPCheckSettings(), req.HTTPCheckRequest); err != nil {
+			return 0, err
+		}
+	}
+	if err := s.applyDomainDefaults(domainID, settings); err != nil {
+		return 0, err
+	}
+
+	// Create the monitor asynchronously in the background using the domain's regions
+	s.startMonitoring(ctx, userID, domainID, fullURL, regions, interval)
+
+	s.events.Publish(events.Event{Type: events.DomainAdded, UserID: userID, DomainID: domainID})
+
+	if req.DiscoverSubdomains {
+		s.goDiscoverSubdomains(ctx, userID, domainID)
+	}
 
 	return domainID, nil
 }
 
 // AddBatchDomains adds multiple domains in a batch
-func (s *DomainService) AddBatchDomains(userID int, req model.DomainBatchAddRequest) model.DomainBatchAddResponse {
+func (s *DomainService) AddBatchDomains(ctx context.Context, userID int, req model.DomainBatchAddRequest) model.DomainBatchAddResponse {
+	logger := logging.FromContext(ctx, s.logger)
 	response := model.DomainBatchAddResponse{
 		Success: []model.DomainAddResult{},
 		Failed:  []model.DomainAddResult{},
@@ -182,12 +349,13 @@ func (s *DomainService) AddBatchDomains(userID int, req model.DomainBatchAddRequ
 
 	// Check if user has reached the domain limit
 	var currentCount int
-	err := s.db.Get(&currentCount, "SELECT COUNT(*) FROM domains WHERE user_id = $1", userID)
+	err := s.db.Get(&currentCount, "SELECT COUNT(*) FROM domains WHERE user_id = $1 AND archived_at IS NULL AND deleted_at IS NULL", userID)
 	if err != nil {
-		log.Printf("Error checking domain count: %v", err)
+		logger.Error("Failed to check domain count", "user_id", userID, "error", err)
 		for _, domainItem := range req.Domains {
 			response.Failed = append(response.Failed, model.DomainAddResult{
 				Name:   domainItem.Name,
+				Region: domainItem.Region,
 				Reason: "Internal server error: could not check domain count",
 			})
 		}
@@ -196,10 +364,11 @@ func (s *DomainService) AddBatchDomains(userID int, req model.DomainBatchAddRequ
 
 	limit, err := s.GetDomainLimit(userID)
 	if err != nil {
-		log.Printf("Error getting domain limit: %v", err)
+		logger.Error("Failed to get domain limit", "user_id", userID, "error", err)
 		for _, domainItem := range req.Domains {
 			response.Failed = append(response.Failed, model.DomainAddResult{
 				Name:   domainItem.Name,
+				Region: domainItem.Region,
 				Reason: "Internal server error: could not check domain limit",
 			})
 		}
@@ -213,21 +382,36 @@ func (s *DomainService) AddBatchDomains(userID int, req model.DomainBatchAddRequ
 		for _, domainItem := range req.Domains {
 			response.Failed = append(response.Failed, model.DomainAddResult{
 				Name:   domainItem.Name,
+				Region: domainItem.Region,
 				Reason: "Domain limit reached",
 			})
 		}
 		return response
 	}
 
+	settings, err := s.GetUserSettings(userID)
+	if err != nil {
+		logger.Error("Failed to get user settings", "user_id", userID, "error", err)
+		for _, domainItem := range req.Domains {
+			response.Failed = append(response.Failed, model.DomainAddResult{
+				Name:   domainItem.Name,
+				Region: domainItem.Region,
+				Reason: "Internal server error: could not get default settings",
+			})
+		}
+		return response
+	}
+
 	// Set default interval if not provided
 	interval := req.Interval
 	if interval == 0 {
-		interval = DEFAULT_INTERVAL
-	} else if interval != 10 && interval != 20 && interval != 30 && interval != 60 && interval != 120 {
+		interval = defaultInterval(settings)
+	} else if err := s.ValidateInterval(userID, interval); err != nil {
 		for _, domainItem := range req.Domains {
 			response.Failed = append(response.Failed, model.DomainAddResult{
 				Name:   domainItem.Name,
-				Reason: "Invalid interval - must be 10, 20, 30, 60 or 120 minutes",
+				Region: domainItem.Region,
+				Reason: "Invalid " + err.Error(),
 			})
 		}
 		return response
@@ -235,12 +419,13 @@ func (s *DomainService) AddBatchDomains(userID int, req model.DomainBatchAddRequ
 
 	// Get existing domains for this user to avoid duplicates
 	existingDomains := make(map[string]bool)
-	rows, err := s.db.Query("SELECT name, region FROM domains WHERE user_id = $1", userID)
+	rows, err := s.db.Query("SELECT name, region, extra_regions FROM domains WHERE user_id = $1 AND deleted_at IS NULL", userID)
 	if err != nil {
-		log.Printf("Error checking existing domains: %v", err)
+		logger.Error("Failed to check existing domains", "user_id", userID, "error", err)
 		for _, domainItem := range req.Domains {
 			response.Failed = append(response.Failed, model.DomainAddResult{
 				Name:   domainItem.Name,
+				Region: domainItem.Region,
 				Reason: "Internal server error: could not check existing domains",
 			})
 		}
@@ -248,20 +433,23 @@ func (s *DomainService) AddBatchDomains(userID int, req model.DomainBatchAddRequ
 	}
 	defer rows.Close()
 
-	// Store normalized hostnames with regions for duplicate detection
+	// Store normalized hostnames with every region of the domain for duplicate detection
 	for rows.Next() {
 		var fullURL, region string
-		if err := rows.Scan(&fullURL, &region); err != nil {
+		var extraRegions pq.StringArray
+		if err := rows.Scan(&fullURL, &region, &extraRegions); err != nil {
 			continue
 		}
 
 		// Extract hostname from URL if it contains protocol
+		name := strings.ToLower(fullURL)
 		parsedURL, err := url.Parse(fullURL)
 		if err == nil && (parsedURL.Scheme == "http" || parsedURL.Scheme == "https") {
 			// Use hostname+region as the key
-			existingDomains[strings.ToLower(parsedURL.Hostname())+":"+region] = true
-		} else {
-			existingDomains[strings.ToLower(fullURL)+":"+region] = true
+			name = strings.ToLower(parsedURL.Hostname())
+		}
+		for _, r := range append([]string{region}, extraRegions...) {
+			existingDomains[name+":"+r] = true
 		}
 	}
 
@@ -271,18 +459,47 @@ func (s *DomainService) AddBatchDomains(userID int, req model.DomainBatchAddRequ
 		if response.Added >= availableSlots {
 			response.Failed = append(response.Failed, model.DomainAddResult{
 				Name:   domainItem.Name,
+				Region: domainItem.Region,
 				Reason: "Domain limit reached",
 			})
 			continue
 		}
 
+		// Items may override the batch interval
+		itemInterval := interval
+		if domainItem.Interval != 0 {
+			if err := s.ValidateInterval(userID, domainItem.Interval); err != nil {
+				response.Failed = append(response.Failed, model.DomainAddResult{
+					Name:   domainItem.Name,
+					Region: domainItem.Region,
+					Reason: "Invalid " + err.Error(),
+				})
+				continue
+			}
+			itemInterval = domainItem.Interval
+		}
+
+		// Items without a region use the user's default region
+		if domainItem.Region == "" {
+			if settings.DefaultRegion == nil {
+				response.Failed = append(response.Failed, model.DomainAddResult{
+					Name:   domainItem.Name,
+					Reason: "Region is required",
+				})
+				continue
+			}
+			domainItem.Region = *settings.DefaultRegion
+		}
+
 		// Normalize input
 		domainInput := strings.TrimSpace(domainItem.Name)
 
-		// Validate domain or URL
-		if !s.ValidateDomainName(domainInput) {
+		// Validate domain or URL, converting internationalized names to their ASCII form
+		domainInput, unicodeName, err := idnForms(domainInput)
+		if err != nil || !s.ValidateDomainName(domainInput) {
 			response.Failed = append(response.Failed, model.DomainAddResult{
 				Name:   domainItem.Name,
+				Region: domainItem.Region,
 				Reason: "Invalid domain name format",
 			})
 			continue
@@ -294,6 +511,7 @@ func (s *DomainService) AddBatchDomains(userID int, req model.DomainBatchAddRequ
 		if err != nil {
 			response.Failed = append(response.Failed, model.DomainAddResult{
 				Name:   domainItem.Name,
+				Region: domainItem.Region,
 				Reason: "Internal server error: could not verify region",
 			})
 			continue
@@ -302,16 +520,33 @@ func (s *DomainService) AddBatchDomains(userID int, req model.DomainBatchAddRequ
 		if !isValidRegion {
 			response.Failed = append(response.Failed, model.DomainAddResult{
 				Name:   domainItem.Name,
+				Region: domainItem.Region,
 				Reason: "Invalid region: " + domainItem.Region,
 			})
 			continue
 		}
 
+		extraRegions, err := s.normalizeExtraRegions(domainItem.Region, domainItem.ExtraRegions)
+		if err != nil {
+			reason := "Internal server error: could not verify region"
+			if errors.Is(err, ErrInvalidRegion) {
+				reason = "Invalid extra regions"
+			}
+			response.Failed = append(response.Failed, model.DomainAddResult{
+				Name:   domainItem.Name,
+				Region: domainItem.Region,
+				Reason: reason,
+			})
+			continue
+		}
+		regions := append([]string{domainItem.Region}, extraRegions...)
+
 		// Parse URL to ensure consistent storage
 		parsedURL, err := url.Parse(domainInput)
 		if err != nil {
 			response.Failed = append(response.Failed, model.DomainAddResult{
 				Name:   domainItem.Name,
+				Region: domainItem.Region,
 				Reason: "Invalid URL format",
 			})
 			continue
@@ -321,15 +556,22 @@ func (s *DomainService) AddBatchDomains(userID int, req model.DomainBatchAddRequ
 		fullURL := domainInput
 		if parsedURL.Scheme == "" {
 			fullURL = "https://" + domainInput
+			if unicodeName != "" {
+				unicodeName = "https://" + unicodeName
+			}
 		}
 
-		// Create combined key with domain+region for duplicate checking
-		domainKey := strings.ToLower(fullURL) + ":" + domainItem.Region
-
-		// Check if this domain+region combination already exists
-		if existingDomains[domainKey] {
+		// Check if the domain already exists in any of its regions
+		duplicate := false
+		for _, region := range regions {
+			if existingDomains[strings.ToLower(fullURL)+":"+region] {
+				duplicate = true
+			}
+		}
+		if duplicate {
 			response.Failed = append(response.Failed, model.DomainAddResult{
 				Name:   domainItem.Name,
+				Region: domainItem.Region,
 				Reason: "Domain already exists in this region",
 			})
 			continue
@@ -341,84 +583,120 @@ func (s *DomainService) AddBatchDomains(userID int, req model.DomainBatchAddRequ
 			domainItem.IsDeepCheck = false // Ensure it's set to false if not specified
 		}
 		err = s.db.QueryRow(`
-			INSERT INTO domains (user_id, name, interval, monitor_guid, active, region, is_deep_check, created_at, updated_at)
-			VALUES ($1, $2, $3, '', true, $4, $5, $6, $6)
+			INSERT INTO domains (user_id, name, unicode_name, interval, monitor_guid, active, region, extra_regions, is_deep_check, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, '', true, $5, $6, $7, $8, $8)
 			RETURNING id
-		`, userID, fullURL, interval, domainItem.Region, domainItem.IsDeepCheck, time.Now()).Scan(&domainID)
+		`, userID, fullURL, unicodeName, itemInterval, domainItem.Region, pq.Array(extraRegions), domainItem.IsDeepCheck, time.Now()).Scan(&domainID)
 
 		if err != nil {
 			response.Failed = append(response.Failed, model.DomainAddResult{
 				Name:   domainItem.Name,
+				Region: domainItem.Region,
 				Reason: "Failed to insert domain: " + err.Error(),
 			})
 			continue
 		}
 
-		// Create monitor asynchronously using domain-specific region
-		go s.createMonitorAsync(domainID, fullURL, domainItem.Region)
+		if err := s.applyDomainDefaults(domainID, settings); err != nil {
+			logger.Error("Failed to apply domain defaults", "domain_id", domainID, "error", err)
+		}
+
+		// Create monitor asynchronously using domain-specific regions
+		s.startMonitoring(ctx, userID, domainID, fullURL, regions, itemInterval)
 
 		// Mark domain as successfully added
 		response.Success = append(response.Success, model.DomainAddResult{
-			Name: domainItem.Name,
-			ID:   domainID,
+			Name:   domainItem.Name,
+			Region: domainItem.Region,
+			ID:     domainID,
 		})
 		response.Added++
 
 		// Add to our existing domains map to prevent duplicates within the batch
-		existingDomains[strings.ToLower(fullURL)+":"+domainItem.Region] = true
+		for _, region := range regions {
+			existingDomains[strings.ToLower(fullURL)+":"+region] = true
+		}
+
+		s.events.Publish(events.Event{Type: events.DomainAdded, UserID: userID, DomainID: domainID})
 	}
 
 	return response
 }
 
 // createMonitorAsync creates a monitor in Uptrends and updates the domain record
-func (s *DomainService) createMonitorAsync(domainID int, fullURL, domainRegion string) {
+func (s *DomainService) createMonitorAsync(ctx context.Context, userID, domainID int, fullURL string, domainRegions []string, interval int) {
+	logger := logging.FromContext(ctx, s.logger).With("user_id", userID, "domain_id", domainID)
+
 	// Add some delay to prevent overwhelming the APIs
 	time.Sleep(100 * time.Millisecond)
 
 	// Extract domain name for the monitor name
 	parsedURL, err := url.Parse(fullURL)
 	if err != nil {
-		log.Printf("Failed to parse URL for monitor creation: %v", err)
+		logger.Error("Failed to parse URL for monitor creation", "url", fullURL, "error", err)
 		return
 	}
 
-	displayName := parsedURL.Hostname()
-	monitorName := fmt.Sprintf("Domain Check - %s", displayName)
+	tags := s.MonitorTags(userID, domainID)
+	monitorName := tags.MonitorName(parsedURL.Hostname())
+	settings := s.MonitorCheckSettings(domainID)
 
 	// Create array of regions to use (primary + fallbacks)
-	regions := []string{domainRegion}
+	regions := s.MonitorRegions(domainRegions...)
+	if len(regions) > len(domainRegions) {
+		logger.Debug("Adding fallback regions", "regions", domainRegions, "fallbacks", regions[len(domainRegions):])
+	}
+
+	// TCP and ping checks are run by the built-in checker only
+	checkType, err := s.domainCheckType(domainID)
+	if err != nil {
+		logger.Warn("Failed to get check type", "error", err)
+	}
+	if !model.IsHTTPCheckType(checkType) {
+		if s.directClient == nil {
+			logger.Error("Built-in checker not configured, domain cannot be monitored", "check_type", checkType)
+			return
+		}
+		if _, err := s.createDirectMonitor(userID, domainID, fullURL, parsedURL.Hostname(), domainRegions[0], interval); err != nil {
+			logger.Error("Failed to create monitor", "provider", model.ProviderDirect, "url", fullURL, "error", err)
+			return
+		}
+		logger.Info("Created monitor", "provider", model.ProviderDirect, "check_type", checkType)
+		return
+	}
 
-	// Add fallback regions based on primary region
-	switch domainRegion {
-	case "TH", "ID", "KR":
-		regions = append(regions, "VN") // Add Vietnam
-		log.Printf("Adding Vietnam fallback region for domain %d with primary region %s", domainID, domainRegion)
-	case "VN":
-		regions = append(regions, "TH") // Add Thailand
-		log.Printf("Adding Thailand fallback region for domain %d with primary region %s", domainID, domainRegion)
+	// Only the providers selected for the domain get a monitor
+	providers, err := s.SelectedProviders(userID, domainID)
+	if err != nil {
+		logger.Warn("Failed to get provider selection, using every provider", "error", err)
+	}
+	selected := make(map[string]bool, len(providers))
+	for _, provider := range providers {
+		selected[provider] = true
 	}
 
 	var uptrendsGuid, site24x7ID string
 	var uptrendsErr, site24x7Err error
 
 	// Create monitor in Uptrends
-	if s.uptrendsClient != nil {
-		uptrendsGuid, uptrendsErr = s.uptrendsClient.CreateMonitor(fullURL, monitorName, regions)
+	if s.uptrendsClient != nil && selected[model.ProviderUptrends] {
+		uptrendsGuid, uptrendsErr = s.uptrendsClient.CreateMonitor(fullURL, monitorName, tags, regions, interval, settings)
 		if uptrendsErr != nil {
-			log.Printf("Failed to create Uptrends monitor for domain %d (%s): %v", domainID, fullURL, uptrendsErr)
+			logger.Error("Failed to create monitor", "provider", model.ProviderUptrends, "url", fullURL, "error", uptrendsErr)
+			s.enqueueMonitorTask(userID, domainID, model.ProviderUptrends, model.MonitorTaskCreate, "", uptrendsErr)
 		} else {
-			log.Printf("Successfully created Uptrends monitor %s for domain %d", uptrendsGuid, domainID)
+			logger.Info("Created monitor", "provider", model.ProviderUptrends, "monitor_id", uptrendsGuid)
 		}
 	}
 
 	// Create monitor in Site24x7
-	if s.site24x7Client != nil {
-		site24x7ID, site24x7Err = s.site24x7Client.CreateMonitor(fullURL, monitorName, regions)
+	if s.site24x7Client != nil && selected[model.ProviderSite24x7] {
+		site24x7ID, site24x7Err = s.site24x7Client.CreateMonitor(fullURL, monitorName, tags, regions, interval, settings)
 		if site24x7Err != nil {
-			log.Printf("Failed to create Site24x7 monitor for domain %d (%s): %v", domainID, fullURL, site24x7Err)
+			logger.Error("Failed to create monitor", "provider", model.ProviderSite24x7, "url", fullURL, "error", site24x7Err)
+			s.enqueueMonitorTask(userID, domainID, model.ProviderSite24x7, model.MonitorTaskCreate, "", site24x7Err)
 		} else {
-			log.Printf("Successfully created Site24x7 monitor %s for domain %d", site24x7ID, domainID)
+			logger.Info("Created monitor", "provider", model.ProviderSite24x7, "monitor_id", site24x7ID)
 		}
 	}
 
@@ -445,21 +723,23 @@ func (s *DomainService) createMonitorAsync(domainID int, fullURL, domainRegion s
     `, uptrendsParam, site24x7Param, domainID)
 
 	if err != nil {
-		log.Printf("Failed to update domain %d with monitor IDs: %v", domainID, err)
+		logger.Error("Failed to store monitor IDs", "error", err)
 
-		// Clean up created monitors if database update failed
-		if uptrendsGuid != "" && s.uptrendsClient != nil {
-			if delErr := s.uptrendsClient.DeleteMonitor(uptrendsGuid); delErr != nil {
-				log.Printf("Failed to delete orphaned Uptrends monitor %s: %v", uptrendsGuid, delErr)
+		// Clean up created monitors if database update failed and retry the creation later
+		if uptrendsGuid != "" {
+			if delErr := s.deleteProviderMonitor(userID, domainID, model.ProviderUptrends, uptrendsGuid); delErr != nil {
+				logger.Error("Failed to delete orphaned monitor", "provider", model.ProviderUptrends, "monitor_id", uptrendsGuid, "error", delErr)
 			}
+			s.enqueueMonitorTask(userID, domainID, model.ProviderUptrends, model.MonitorTaskCreate, "", err)
 		}
-		if site24x7ID != "" && s.site24x7Client != nil {
-			if delErr := s.site24x7Client.DeleteMonitor(site24x7ID); delErr != nil {
-				log.Printf("Failed to delete orphaned Site24x7 monitor %s: %v", site24x7ID, delErr)
+		if site24x7ID != "" {
+			if delErr := s.deleteProviderMonitor(userID, domainID, model.ProviderSite24x7, site24x7ID); delErr != nil {
+				logger.Error("Failed to delete orphaned monitor", "provider", model.ProviderSite24x7, "monitor_id", site24x7ID, "error", delErr)
 			}
+			s.enqueueMonitorTask(userID, domainID, model.ProviderSite24x7, model.MonitorTaskCreate, "", err)
 		}
 	} else {
-		log.Printf("Successfully created and linked monitors for domain %d (%s)", domainID, fullURL)
+		logger.Info("Created and linked monitors", "url", fullURL)
 	}
 }
 
@@ -467,16 +747,20 @@ func (s *DomainService) createMonitorAsync(domainID int, fullURL, domainRegion s
 func (s *DomainService) GetDomain(domainID, userID int) (*model.Domain, error) {
 	var domain model.Domain
 	err := s.db.Get(&domain, `
-        SELECT id, user_id, name, active, interval, region, last_status, error_code,
-               total_time, error_description, monitor_guid, site24x7_monitor_id, 
-               is_deep_check, last_check, created_at, updated_at
+        SELECT id, user_id, name, active, interval, region, extra_regions, last_status, error_code,
+               total_time, error_description, monitor_guid, site24x7_monitor_id, direct_monitor_id,
+               is_deep_check, last_check, created_at, updated_at, verification_token, verified_at,
+               recovery_pending, recovery_successes, consecutive_failures, failure_pending,
+               failure_threshold, recovery_threshold, content_match_type, content_match_pattern, availability_strategy,
+               archived_at, snoozed_until, notes, owner_contact, labels, unicode_name, parent_id, check_type, port,
+               notification_channels, `+httpCheckColumns+`
         FROM domains
-        WHERE id = $1 AND user_id = $2
+        WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
     `, domainID, userID)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("domain not found")
+			return nil, ErrDomainNotFound
 		}
 		return nil, err
 	}
@@ -485,17 +769,36 @@ func (s *DomainService) GetDomain(domainID, userID int) (*model.Domain, error) {
 }
 
 // UpdateDomain updates domain settings
-func (s *DomainService) UpdateDomain(domainID, userID int, req model.DomainUpdateRequest) error {
+func (s *DomainService) UpdateDomain(ctx context.Context, domainID, userID int, req model.DomainUpdateRequest) error {
+	return s.updateDomain(ctx, domainID, userID, req, nil)
+}
+
+// updateDomain applies req to a domain. When expected is set, the row is only written
+// while the fields its ETag covers still hold expected's values, otherwise
+// etag.ErrPreconditionFailed is returned.
+func (s *DomainService) updateDomain(ctx context.Context, domainID, userID int, req model.DomainUpdateRequest, expected *model.Domain) error {
+	logger := logging.FromContext(ctx, s.logger).With("user_id", userID, "domain_id", domainID)
+
 	// First check if domain exists and belongs to user
 	var domain model.Domain
-	err := s.db.Get(&domain, "SELECT * FROM domains WHERE id = $1 AND user_id = $2", domainID, userID)
+	err := s.db.Get(&domain, "SELECT * FROM domains WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL", domainID, userID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return errors.New("domain not found")
+			return ErrDomainNotFound
 		}
 		return err
 	}
 
+	// Archived domains have no provider monitors; they are reactivated through UnarchiveDomain
+	if domain.ArchivedAt != nil && (req.Active != nil || (req.Region != nil && *req.Region != "")) {
+		return ErrDomainArchived
+	}
+
+	// Unverified domains have no provider monitors; they are activated through VerifyDomain
+	if domain.VerificationPending() && req.Active != nil && *req.Active {
+		return ErrDomainNotVerified
+	}
+
 	// Build update query
 	query := "UPDATE domains SET updated_at = NOW()"
 	params := []interface{}{}
@@ -510,8 +813,8 @@ func (s *DomainService) UpdateDomain(domainID, userID int, req model.DomainUpdat
 
 	if req.Interval != nil {
 		// Validate interval
-		if *req.Interval != 10 && *req.Interval != 20 && *req.Interval != 30 && *req.Interval != 60 && *req.Interval != 120 {
-			return errors.New("interval must be 10, 20, 30, 60, 120 minutes")
+		if err := s.ValidateInterval(userID, *req.Interval); err != nil {
+			return err
 		}
 
 		query += fmt.Sprintf(", interval = $%d", paramIndex)
@@ -525,6 +828,80 @@ func (s *DomainService) UpdateDomain(domainID, userID int, req model.DomainUpdat
 		paramIndex++
 	}
 
+	if req.FailureThreshold != nil {
+		query += fmt.Sprintf(", failure_threshold = $%d", paramIndex)
+		params = append(params, *req.FailureThreshold)
+		paramIndex++
+	}
+
+	if req.RecoveryThreshold != nil {
+		query += fmt.Sprintf(", recovery_threshold = $%d", paramIndex)
+		params = append(params, *req.RecoveryThreshold)
+		paramIndex++
+	}
+
+	if req.AvailabilityStrategy != nil {
+		query += fmt.Sprintf(", availability_strategy = $%d", paramIndex)
+		params = append(params, *req.AvailabilityStrategy)
+		paramIndex++
+	}
+
+	if req.Port != nil {
+		if err := validateCheckType(domain.CheckType, req.Port); err != nil {
+			return err
+		}
+
+		query += fmt.Sprintf(", port = $%d", paramIndex)
+		params = append(params, *req.Port)
+		paramIndex++
+	}
+
+	if req.Notes != nil {
+		query += fmt.Sprintf(", notes = $%d", paramIndex)
+		params = append(params, strings.TrimSpace(*req.Notes))
+		paramIndex++
+	}
+
+	if req.OwnerContact != nil {
+		query += fmt.Sprintf(", owner_contact = $%d", paramIndex)
+		params = append(params, strings.TrimSpace(*req.OwnerContact))
+		paramIndex++
+	}
+
+	if req.Labels != nil {
+		labels, err := model.NormalizeLabels(*req.Labels)
+		if err != nil {
+			return err
+		}
+
+		query += fmt.Sprintf(", labels = $%d", paramIndex)
+		params = append(params, labels)
+		paramIndex++
+	}
+
+	if req.NotificationChannels != nil {
+		query += fmt.Sprintf(", notification_channels = $%d", paramIndex)
+		params = append(params, model.DomainChannels(*req.NotificationChannels))
+		paramIndex++
+	}
+
+	if req.ContentMatchType != nil || req.ContentMatchPattern != nil {
+		matchType, pattern := domain.ContentMatchType, domain.ContentMatchPattern
+		if req.ContentMatchType != nil {
+			matchType = *req.ContentMatchType
+		}
+		if req.ContentMatchPattern != nil {
+			pattern = *req.ContentMatchPattern
+		}
+		if err := validateContentMatch(matchType, pattern); err != nil {
+			return err
+		}
+
+		query += fmt.Sprintf(", content_match_type = $%d, content_match_pattern = $%d", paramIndex, paramIndex+1)
+		params = append(params, matchType, pattern)
+		paramIndex += 2
+	}
+
 	// Add region field if provided
 	if req.Region != nil && *req.Region != "" {
 		// Validate region
@@ -534,57 +911,117 @@ func (s *DomainService) UpdateDomain(domainID, userID int, req model.DomainUpdat
 			return fmt.Errorf("error verifying region: %w", err)
 		}
 		if !isValidRegion {
-			return errors.New("invalid region")
+			return ErrInvalidRegion
 		}
 
 		query += fmt.Sprintf(", region = $%d", paramIndex)
 		params = append(params, *req.Region)
 		paramIndex++
+	}
 
-		// If region changed and monitors exist, recreate them
-		if domain.Region != *req.Region {
-			// Delete existing monitors using helper methods
-			if domain.GetMonitorGuid() != "" && s.uptrendsClient != nil {
-				if err := s.uptrendsClient.DeleteMonitor(domain.GetMonitorGuid()); err != nil {
-					log.Printf("Failed to delete Uptrends monitor for region change: %v", err)
-				}
-			}
-			if domain.GetSite24x7MonitorID() != "" && s.site24x7Client != nil {
-				if err := s.site24x7Client.DeleteMonitor(domain.GetSite24x7MonitorID()); err != nil {
-					log.Printf("Failed to delete Site24x7 monitor for region change: %v", err)
-				}
-			}
+	// The extra regions are checked against the new primary region, which they must not repeat
+	regions := domain.Regions()
+	if (req.Region != nil && *req.Region != "") || req.ExtraRegions != nil {
+		primary, extra := domain.Region, []string(domain.ExtraRegions)
+		if req.Region != nil && *req.Region != "" {
+			primary = *req.Region
+		}
+		if req.ExtraRegions != nil {
+			extra = *req.ExtraRegions
+		}
+		normalized, err := s.normalizeExtraRegions(primary, extra)
+		if err != nil {
+			return err
+		}
+		if !sameRegions(normalized, domain.ExtraRegions) {
+			query += fmt.Sprintf(", extra_regions = $%d", paramIndex)
+			params = append(params, pq.Array(normalized))
+			paramIndex++
+		}
+		regions = append([]string{primary}, normalized...)
+	}
 
-			// Schedule creation of new monitors
-			go s.createMonitorAsync(domainID, domain.Name, *req.Region)
+	// If the regions changed and monitors exist, recreate them. Unverified domains get
+	// theirs once verified.
+	regionsChanged := !sameRegions(regions, domain.Regions())
+	if regionsChanged && !domain.VerificationPending() {
+		// Delete existing monitors using helper methods
+		if err := s.deleteProviderMonitor(userID, domainID, model.ProviderUptrends, domain.GetMonitorGuid()); err != nil {
+			logger.Error("Failed to delete monitor for region change", "provider", model.ProviderUptrends, "error", err)
 		}
+		if err := s.deleteProviderMonitor(userID, domainID, model.ProviderSite24x7, domain.GetSite24x7MonitorID()); err != nil {
+			logger.Error("Failed to delete monitor for region change", "provider", model.ProviderSite24x7, "error", err)
+		}
+
+		// Schedule creation of new monitors
+		interval := domain.Interval
+		if req.Interval != nil {
+			interval = *req.Interval
+		}
+		s.goCreateMonitor(ctx, userID, domainID, domain.Name, regions, interval)
 	}
 
 	// Add WHERE clause
 	query += fmt.Sprintf(" WHERE id = $%d AND user_id = $%d", paramIndex, paramIndex+1)
 	params = append(params, domainID, userID)
+	if expected != nil {
+		next := paramIndex + 2
+		query += fmt.Sprintf(" AND name = $%d AND region = $%d AND interval = $%d AND active = $%d AND COALESCE(is_deep_check, false) = $%d",
+			next, next+1, next+2, next+3, next+4)
+		params = append(params, expected.Name, expected.Region, expected.Interval, expected.Active, expected.IsDeepCheck)
+	}
 
 	// Execute update if we have fields to update
 	if paramIndex > 1 {
-		log.Printf("Executing query: %s with params: %v", query, params)
-		_, err = s.db.Exec(query, params...)
+		logger.Debug("Updating domain", "query", query)
+		result, err := s.db.Exec(query, params...)
 		if err != nil {
 			return err
 		}
+		if expected != nil {
+			if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+				return etag.ErrPreconditionFailed
+			}
+		}
+		s.events.Publish(events.Event{Type: events.DomainUpdated, UserID: userID, DomainID: domainID})
+	}
+
+	// Provider monitors are created with the HTTP settings, so existing ones are recreated.
+	// A region change recreates them already.
+	if req.HTTPCheckRequest.IsSet() {
+		if err := s.updateHTTPCheckSettings(domainID, domain.HTTPCheckSettings, req.HTTPCheckRequest); err != nil {
+			return err
+		}
+		if !regionsChanged && (domain.GetMonitorGuid() != "" || domain.GetSite24x7MonitorID() != "") {
+			s.goAsync(func() {
+				if _, err := s.RecreateMonitors(userID, domainID, nil); err != nil {
+					logger.Error("Failed to recreate monitors with new HTTP settings", "error", err)
+				}
+			})
+		}
+	}
+
+	// Recreated monitors are created with the new interval already
+	if req.Interval != nil && *req.Interval != domain.Interval && !regionsChanged && !req.HTTPCheckRequest.IsSet() {
+		interval := *req.Interval
+		s.goAsync(func() {
+			s.syncMonitorIntervals(domain, interval)
+		})
 	}
 
 	// Update monitor statuses if active status changed using helper methods
-	if req.Active != nil && req.Region == nil {
+	if req.Active != nil && !regionsChanged {
 		if domain.GetMonitorGuid() != "" && s.uptrendsClient != nil {
 			if err := s.uptrendsClient.UpdateMonitorStatus(domain.GetMonitorGuid(), *req.Active); err != nil {
-				log.Printf("Failed to update Uptrends monitor status: %v", err)
+				logger.Error("Failed to update monitor status", "provider", model.ProviderUptrends, "error", err)
 			}
 		}
 		if domain.GetSite24x7MonitorID() != "" && s.site24x7Client != nil {
 			if err := s.site24x7Client.UpdateMonitorStatus(domain.GetSite24x7MonitorID(), *req.Active); err != nil {
-				log.Printf("Failed to update Site24x7 monitor status: %v", err)
+				logger.Error("Failed to update monitor status", "provider", model.ProviderSite24x7, "error", err)
 			}
 		}
+		s.setDirectMonitorStatus(domain, *req.Active)
 	}
 
 	return nil
@@ -592,6 +1029,12 @@ func (s *DomainService) UpdateDomain(domainID, userID int, req model.DomainUpdat
 
 // GetDomains gets all domains for a user
 func (s *DomainService) GetDomains(userID int) (model.DomainListResponse, error) {
+	return s.GetDomainsByLabels(userID, nil)
+}
+
+// GetDomainsByLabels gets the domains of a user that carry every given label. The total
+// and limit always cover every domain.
+func (s *DomainService) GetDomainsByLabels(userID int, labels model.DomainLabels) (model.DomainListResponse, error) {
 	var domains []model.Domain
 
 	err := s.db.Select(&domains, `
@@ -600,41 +1043,60 @@ func (s *DomainService) GetDomains(userID int) (model.DomainListResponse, error)
             d.user_id, 
             d.name, 
             COALESCE(d.active, false) AS active,
-            d.region,  
+            d.region,
+            d.extra_regions,
             d.last_status, 
             d.error_code, 
             d.error_description, 
             d.last_check, 
             d.monitor_guid,
             d.site24x7_monitor_id,
+            d.direct_monitor_id,
             d.interval,
             d.total_time,
-            COALESCE(d.is_deep_check, false) AS is_deep_check
+            COALESCE(d.is_deep_check, false) AS is_deep_check,
+            d.recovery_pending,
+            d.recovery_successes,
+            d.failure_pending,
+            d.failure_threshold,
+            d.recovery_threshold,
+            d.content_match_type,
+            d.content_match_pattern,
+            d.availability_strategy,
+            d.archived_at,
+            d.verification_token,
+            d.verified_at,
+            d.snoozed_until,
+            d.notes,
+            d.owner_contact,
+            d.labels,
+            d.unicode_name,
+            d.parent_id,
+            d.check_type,
+            d.port,
+            d.notification_channels
         FROM domains d
-        WHERE d.user_id = $1
+        WHERE d.user_id = $1 AND d.deleted_at IS NULL AND d.labels @> $2
         ORDER BY d.created_at DESC
-    `, userID)
+    `, userID, labels)
 
 	if err != nil {
 		return model.DomainListResponse{}, err
 	}
-
-	// Get domain count and limit
-	var count int
-	err = s.db.Get(&count, "SELECT COUNT(*) FROM domains WHERE user_id = $1", userID)
-	if err != nil {
+	if err := s.attachMonitorTasks(domains); err != nil {
 		return model.DomainListResponse{}, err
 	}
 
-	limit, err := s.GetDomainLimit(userID)
+	// Get domain count and limit from the cached summary
+	summary, err := s.GetDomainSummary(userID)
 	if err != nil {
 		return model.DomainListResponse{}, err
 	}
 
 	return model.DomainListResponse{
 		Domains:      domains,
-		TotalDomains: count,
-		DomainLimit:  limit,
+		TotalDomains: summary.TotalDomains,
+		DomainLimit:  summary.DomainLimit,
 	}, nil
 }
 
@@ -643,13 +1105,18 @@ func (s *DomainService) GetAllActiveDomainsWithMonitors() ([]model.Domain, error
 	var domains []model.Domain
 
 	query := `
-        SELECT id, user_id, name, active, interval, monitor_guid, site24x7_monitor_id, 
+        SELECT id, user_id, name, active, interval, monitor_guid, site24x7_monitor_id, direct_monitor_id,
                last_status, error_code, total_time, error_description, last_check, 
-               created_at, updated_at, region, COALESCE(is_deep_check, false) AS is_deep_check
+               created_at, updated_at, region, extra_regions, COALESCE(is_deep_check, false) AS is_deep_check,
+               recovery_pending, recovery_successes, consecutive_failures, failure_pending,
+               failure_threshold, recovery_threshold, content_match_type, content_match_pattern, availability_strategy,
+               last_skipped_at, unicode_name, check_type
         FROM domains 
         WHERE active = true
-        AND (monitor_guid IS NOT NULL AND monitor_guid != '') 
-        OR (site24x7_monitor_id IS NOT NULL AND site24x7_monitor_id != '')
+        AND ((monitor_guid IS NOT NULL AND monitor_guid != '') 
+             OR (site24x7_monitor_id IS NOT NULL AND site24x7_monitor_id != '')
+             OR (direct_monitor_id IS NOT NULL AND direct_monitor_id != ''))
+        AND user_id NOT IN (SELECT id FROM users WHERE suspended_at IS NOT NULL)
     `
 
 	err := s.db.Select(&domains, query)
@@ -660,7 +1127,8 @@ func (s *DomainService) GetAllActiveDomainsWithMonitors() ([]model.Domain, error
 	return domains, nil
 }
 
-// UpdateAllUserDomains updates settings for domains of a user in a specific region
+// UpdateAllUserDomains updates settings for domains of a user in a specific region.
+// Domains waiting for verification are left alone.
 func (s *DomainService) UpdateAllUserDomains(userID int, req model.DomainUpdateRequest) error {
 	// Get domain information for this user, filtered by region if provided
 	var domains []model.Domain
@@ -668,10 +1136,10 @@ func (s *DomainService) UpdateAllUserDomains(userID int, req model.DomainUpdateR
 	var query string
 
 	if req.Region != nil && *req.Region != "" {
-		query = "SELECT id, name, monitor_guid, site24x7_monitor_id, region, COALESCE(is_deep_check, false) AS is_deep_check FROM domains WHERE user_id = $1 AND region = $2"
+		query = "SELECT id, name, interval, monitor_guid, site24x7_monitor_id, direct_monitor_id, region, COALESCE(is_deep_check, false) AS is_deep_check FROM domains WHERE user_id = $1 AND region = $2 AND archived_at IS NULL AND deleted_at IS NULL AND " + verifiedCondition
 		params = []interface{}{userID, *req.Region}
 	} else {
-		query = "SELECT id, name, monitor_guid, site24x7_monitor_id, region, COALESCE(is_deep_check, false) AS is_deep_check FROM domains WHERE user_id = $1"
+		query = "SELECT id, name, interval, monitor_guid, site24x7_monitor_id, direct_monitor_id, region, COALESCE(is_deep_check, false) AS is_deep_check FROM domains WHERE user_id = $1 AND archived_at IS NULL AND deleted_at IS NULL AND " + verifiedCondition
 		params = []interface{}{userID}
 	}
 
@@ -699,8 +1167,8 @@ func (s *DomainService) UpdateAllUserDomains(userID int, req model.DomainUpdateR
 	}
 
 	if req.Interval != nil {
-		if *req.Interval != 10 && *req.Interval != 20 && *req.Interval != 30 && *req.Interval != 60 && *req.Interval != 120 {
-			return errors.New("interval must be 10, 20, 30, 60 or 120 minutes")
+		if err := s.ValidateInterval(userID, *req.Interval); err != nil {
+			return err
 		}
 
 		updateQuery += fmt.Sprintf(", interval = $%d", paramIndex)
@@ -716,20 +1184,21 @@ func (s *DomainService) UpdateAllUserDomains(userID int, req model.DomainUpdateR
 
 	// Add WHERE clause with region filter if provided
 	if req.Region != nil && *req.Region != "" {
-		updateQuery += fmt.Sprintf(" WHERE user_id = $%d AND region = $%d", paramIndex, paramIndex+1)
+		updateQuery += fmt.Sprintf(" WHERE user_id = $%d AND region = $%d AND archived_at IS NULL AND deleted_at IS NULL AND %s", paramIndex, paramIndex+1, verifiedCondition)
 		updateParams = append(updateParams, userID, *req.Region)
 	} else {
-		updateQuery += fmt.Sprintf(" WHERE user_id = $%d", paramIndex)
+		updateQuery += fmt.Sprintf(" WHERE user_id = $%d AND archived_at IS NULL AND deleted_at IS NULL AND %s", paramIndex, verifiedCondition)
 		updateParams = append(updateParams, userID)
 	}
 
 	// Execute the update if we have fields to update
 	if paramIndex > 1 {
-		log.Printf("Executing query: %s with params: %v", updateQuery, updateParams)
+		s.logger.Debug("Updating all domains", "user_id", userID, "query", updateQuery)
 		_, err = s.db.Exec(updateQuery, updateParams...)
 		if err != nil {
 			return err
 		}
+		s.events.Publish(events.Event{Type: events.DomainUpdated, UserID: userID})
 	}
 
 	// Update monitors in both services if active status is changing using helper methods
@@ -737,52 +1206,144 @@ func (s *DomainService) UpdateAllUserDomains(userID int, req model.DomainUpdateR
 		for _, domain := range domains {
 			if domain.GetMonitorGuid() != "" && s.uptrendsClient != nil {
 				if err := s.uptrendsClient.UpdateMonitorStatus(domain.GetMonitorGuid(), *req.Active); err != nil {
-					log.Printf("Failed to update Uptrends monitor status for domain %d: %v", domain.ID, err)
+					s.logger.Error("Failed to update monitor status", "provider", model.ProviderUptrends, "domain_id", domain.ID, "error", err)
 				}
 			}
 			if domain.GetSite24x7MonitorID() != "" && s.site24x7Client != nil {
 				if err := s.site24x7Client.UpdateMonitorStatus(domain.GetSite24x7MonitorID(), *req.Active); err != nil {
-					log.Printf("Failed to update Site24x7 monitor status for domain %d: %v", domain.ID, err)
+					s.logger.Error("Failed to update monitor status", "provider", model.ProviderSite24x7, "domain_id", domain.ID, "error", err)
 				}
 			}
+			s.setDirectMonitorStatus(domain, *req.Active)
 		}
 	}
 
+	if req.Interval != nil {
+		interval := *req.Interval
+		s.goAsync(func() {
+			for _, domain := range domains {
+				if domain.Interval != interval {
+					s.syncMonitorIntervals(domain, interval)
+				}
+			}
+		})
+	}
+
 	return nil
 }
 
-// DeleteDomain deletes a domain
-func (s *DomainService) DeleteDomain(userID, domainID int) error {
+// UpdateSelectedDomains applies the same active status, interval or region to each of
+// the given domains. Each domain is updated on its own, so one failing domain does not
+// stop the others.
+func (s *DomainService) UpdateSelectedDomains(ctx context.Context, userID int, req model.DomainBatchUpdateRequest) (*model.DomainBatchUpdateResponse, error) {
+	if len(req.DomainIDs) == 0 {
+		return nil, errors.New("no domain IDs provided")
+	}
+	if req.Active == nil && req.Interval == nil && (req.Region == nil || *req.Region == "") {
+		return nil, ErrNoFieldsToUpdate
+	}
+	if req.Interval != nil {
+		if err := s.ValidateInterval(userID, *req.Interval); err != nil {
+			return nil, err
+		}
+	}
+
+	var domains []struct {
+		ID   int    `db:"id"`
+		Name string `db:"name"`
+	}
+	err := s.db.Select(&domains, `
+        SELECT id, name FROM domains
+        WHERE user_id = $1 AND id = ANY($2) AND deleted_at IS NULL
+    `, userID, pq.Array(req.DomainIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch domains: %w", err)
+	}
+	names := make(map[int]string, len(domains))
+	for _, d := range domains {
+		names[d.ID] = d.Name
+	}
+
+	response := &model.DomainBatchUpdateResponse{
+		Success:    []model.DomainUpdateResult{},
+		Failed:     []model.DomainUpdateResult{},
+		TotalCount: len(req.DomainIDs),
+	}
+	update := model.DomainUpdateRequest{Active: req.Active, Interval: req.Interval, Region: req.Region}
+
+	for _, domainID := range req.DomainIDs {
+		name, exists := names[domainID]
+		if !exists {
+			response.Failed = append(response.Failed, model.DomainUpdateResult{
+				ID:     domainID,
+				Reason: "Domain not found or access denied",
+			})
+			continue
+		}
+
+		if err := s.UpdateDomain(ctx, domainID, userID, update); err != nil {
+			response.Failed = append(response.Failed, model.DomainUpdateResult{
+				ID:     domainID,
+				Name:   name,
+				Reason: batchUpdateFailureReason(err),
+			})
+			continue
+		}
+
+		response.Success = append(response.Success, model.DomainUpdateResult{ID: domainID, Name: name})
+		response.UpdatedCount++
+	}
+
+	return response, nil
+}
+
+// batchUpdateFailureReason turns an UpdateDomain error into a reason for batch results
+func batchUpdateFailureReason(err error) string {
+	switch {
+	case errors.Is(err, ErrDomainNotFound):
+		return "Domain not found or access denied"
+	case errors.Is(err, ErrDomainArchived):
+		return "Domain is archived; unarchive it first"
+	case errors.Is(err, ErrDomainNotVerified):
+		return "Domain is not verified; verify it first"
+	case errors.Is(err, ErrInvalidRegion):
+		return "Invalid region"
+	}
+	return "Failed to update domain: " + err.Error()
+}
+
+// DeleteDomain deletes the provider monitors of a domain and moves it to the trash,
+// from which it can be restored until it is purged
+func (s *DomainService) DeleteDomain(ctx context.Context, userID, domainID int) error {
+	logger := logging.FromContext(ctx, s.logger).With("user_id", userID, "domain_id", domainID)
+
 	// First get the domain to retrieve its monitor IDs
 	var domain model.Domain
-	err := s.db.Get(&domain, "SELECT * FROM domains WHERE id = $1 AND user_id = $2", domainID, userID)
+	err := s.db.Get(&domain, "SELECT * FROM domains WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL", domainID, userID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			log.Printf("Domain not found for user %d: %d", userID, domainID)
-			return errors.New("domain not found or not owned by user")
+			logger.Debug("Domain to delete not found")
+			return ErrDomainNotFound
 		}
 		return err
 	}
 
 	// Delete monitors from both services using helper methods
-	if domain.GetMonitorGuid() != "" && s.uptrendsClient != nil {
-		if err := s.uptrendsClient.DeleteMonitor(domain.GetMonitorGuid()); err != nil {
-			log.Printf("Failed to delete Uptrends monitor %s: %v", domain.GetMonitorGuid(), err)
-		}
+	if err := s.deleteProviderMonitor(userID, domainID, model.ProviderUptrends, domain.GetMonitorGuid()); err != nil {
+		logger.Error("Failed to delete monitor", "provider", model.ProviderUptrends, "monitor_id", domain.GetMonitorGuid(), "error", err)
 	}
 
-	if domain.GetSite24x7MonitorID() != "" && s.site24x7Client != nil {
-		if err := s.site24x7Client.DeleteMonitor(domain.GetSite24x7MonitorID()); err != nil {
-			log.Printf("Failed to delete Site24x7 monitor %s: %v", domain.GetSite24x7MonitorID(), err)
-		}
+	if err := s.deleteProviderMonitor(userID, domainID, model.ProviderSite24x7, domain.GetSite24x7MonitorID()); err != nil {
+		logger.Error("Failed to delete monitor", "provider", model.ProviderSite24x7, "monitor_id", domain.GetSite24x7MonitorID(), "error", err)
 	}
 
-	// Delete domain from database
-	_, err = s.db.Exec("DELETE FROM domains WHERE id = $1 AND user_id = $2", domainID, userID)
-	if err != nil {
+	// Move the domain to the trash
+	if err := s.trashDomain(domainID, userID); err != nil {
 		return err
 	}
 
+	s.events.Publish(events.Event{Type: events.DomainDeleted, UserID: userID, DomainID: domainID})
+
 	return nil
 }
 
@@ -810,16 +1371,16 @@ func (s *DomainService) DeleteAllDomains(userID int) error {
 	for _, domain := range domains.Domains {
 		// Delete from Uptrends if monitor ID exists
 		if domain.GetMonitorGuid() != "" {
-			if deleteErr := s.uptrendsClient.DeleteMonitor(domain.GetMonitorGuid()); deleteErr != nil {
-				log.Printf("Warning: Failed to delete Uptrends monitor %s: %v", domain.GetMonitorGuid(), deleteErr)
+			if deleteErr := s.deleteProviderMonitor(userID, domain.ID, model.ProviderUptrends, domain.GetMonitorGuid()); deleteErr != nil {
+				s.logger.Warn("Failed to delete monitor", "provider", model.ProviderUptrends, "domain_id", domain.ID, "monitor_id", domain.GetMonitorGuid(), "error", deleteErr)
 				// Continue with deletion even if external service fails
 			}
 		}
 
 		// Delete from Site24x7 if monitor ID exists
 		if domain.GetSite24x7MonitorID() != "" {
-			if deleteErr := s.site24x7Client.DeleteMonitor(domain.GetSite24x7MonitorID()); deleteErr != nil {
-				log.Printf("Warning: Failed to delete Site24x7 monitor %s: %v", domain.GetSite24x7MonitorID(), deleteErr)
+			if deleteErr := s.deleteProviderMonitor(userID, domain.ID, model.ProviderSite24x7, domain.GetSite24x7MonitorID()); deleteErr != nil {
+				s.logger.Warn("Failed to delete monitor", "provider", model.ProviderSite24x7, "domain_id", domain.ID, "monitor_id", domain.GetSite24x7MonitorID(), "error", deleteErr)
 				// Continue with deletion even if external service fails
 			}
 		}
@@ -856,7 +1417,9 @@ func (s *DomainService) DeleteAllDomains(userID int) error {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	log.Printf("Successfully deleted %d domains for user %d", rowsAffected, userID)
+	s.events.Publish(events.Event{Type: events.DomainDeleted, UserID: userID})
+
+	s.logger.Info("Deleted all domains", "user_id", userID, "count", rowsAffected)
 	return nil
 }
 
@@ -873,8 +1436,12 @@ func (s *DomainService) UpdateDomainLimit(userID int, limit int) error {
         ON CONFLICT (user_id)
         DO UPDATE SET domain_limit = $2, updated_at = NOW()
     `, userID, limit)
+	if err != nil {
+		return err
+	}
 
-	return err
+	s.events.Publish(events.Event{Type: events.DomainUpdated, UserID: userID})
+	return nil
 }
 
 // GetAllActiveDomains gets all active domains across all users
@@ -904,7 +1471,8 @@ func (s *DomainService) UpdateDomainStatus(domainID int, statusCode, errorCode,
 		return err
 	}
 
-	// Update domain status details
+	// Update domain status details. The failure streak uses the same rule as
+	// Domain.Available.
 	_, err = s.db.Exec(`
 		UPDATE domains
 		SET 
@@ -912,6 +1480,7 @@ func (s *DomainService) UpdateDomainStatus(domainID int, statusCode, errorCode,
 			error_code = $2,
 			total_time = $3,
 			error_description = $4,
+			consecutive_failures = CASE WHEN $1 >= 200 AND $1 < 400 THEN 0 ELSE consecutive_failures + 1 END,
 			last_check = NOW(),
 			updated_at = NOW()
 		WHERE id = $5
@@ -920,6 +1489,26 @@ func (s *DomainService) UpdateDomainStatus(domainID int, statusCode, errorCode,
 	return err
 }
 
+// UpdateRecoveryState records how far a down domain is through recovery verification
+func (s *DomainService) UpdateRecoveryState(domainID int, pending bool, successes int) error {
+	_, err := s.db.Exec(`
+        UPDATE domains
+        SET recovery_pending = $1, recovery_successes = $2
+        WHERE id = $3
+    `, pending, successes, domainID)
+	return err
+}
+
+// UpdateFailureState records whether a domain's failures are still below its failure threshold
+func (s *DomainService) UpdateFailureState(domainID int, pending bool) error {
+	_, err := s.db.Exec(`
+        UPDATE domains
+        SET failure_pending = $1
+        WHERE id = $2
+    `, pending, domainID)
+	return err
+}
+
 // GetAllActiveDomainsWithUserRegions gets all active domains with their user regions
 func (s *DomainService) GetAllActiveDomainsWithUserRegions() ([]model.DomainWithRegion, error) {
 	var domains []model.DomainWithRegion
@@ -1031,7 +1620,7 @@ func (s *DomainService) GetDomainsWithoutSite24x7Monitor() ([]model.Domain, erro
                last_status, error_code, total_time, error_description, last_check, 
                created_at, updated_at, region
         FROM domains 
-        WHERE active = true
+        WHERE active = true AND archived_at IS NULL
         AND (site24x7_monitor_id IS NULL OR site24x7_monitor_id = '')
     `
 
@@ -1067,7 +1656,7 @@ func (s *DomainService) DeleteBatchDomains(userID int, domainIDs []int) (*model.
 	query := fmt.Sprintf(`
         SELECT id, name, monitor_guid, site24x7_monitor_id
         FROM domains 
-        WHERE user_id = $1 AND id IN (%s)
+        WHERE user_id = $1 AND id IN (%s) AND deleted_at IS NULL
     `, strings.Join(placeholders, ","))
 
 	var domains []struct {
@@ -1079,7 +1668,7 @@ func (s *DomainService) DeleteBatchDomains(userID int, domainIDs []int) (*model.
 
 	err := s.db.Select(&domains, query, args...)
 	if err != nil {
-		log.Printf("Failed to fetch domains for batch delete: %v", err)
+		s.logger.Error("Failed to fetch domains for batch delete", "user_id", userID, "error", err)
 		return nil, fmt.Errorf("failed to fetch domains: %w", err)
 	}
 
@@ -1120,24 +1709,24 @@ func (s *DomainService) DeleteBatchDomains(userID int, domainIDs []int) (*model.
 
 		// Delete from Uptrends if monitor GUID exists
 		if domain.MonitorGuid != nil && *domain.MonitorGuid != "" {
-			if err := s.uptrendsClient.DeleteMonitor(*domain.MonitorGuid); err != nil {
+			if err := s.deleteProviderMonitor(userID, domainID, model.ProviderUptrends, *domain.MonitorGuid); err != nil {
 				deleteErrors = append(deleteErrors, fmt.Sprintf("Uptrends: %v", err))
-				log.Printf("Warning: Failed to delete Uptrends monitor %s for domain %s: %v",
-					*domain.MonitorGuid, domain.Name, err)
+				s.logger.Warn("Failed to delete monitor", "provider", model.ProviderUptrends, "domain_id", domainID,
+					"monitor_id", *domain.MonitorGuid, "error", err)
 			}
 		}
 
 		// Delete from Site24x7 if monitor ID exists
 		if domain.Site24x7MonitorID != nil && *domain.Site24x7MonitorID != "" {
-			if err := s.site24x7Client.DeleteMonitor(*domain.Site24x7MonitorID); err != nil {
+			if err := s.deleteProviderMonitor(userID, domainID, model.ProviderSite24x7, *domain.Site24x7MonitorID); err != nil {
 				deleteErrors = append(deleteErrors, fmt.Sprintf("Site24x7: %v", err))
-				log.Printf("Warning: Failed to delete Site24x7 monitor %s for domain %s: %v",
-					*domain.Site24x7MonitorID, domain.Name, err)
+				s.logger.Warn("Failed to delete monitor", "provider", model.ProviderSite24x7, "domain_id", domainID,
+					"monitor_id", *domain.Site24x7MonitorID, "error", err)
 			}
 		}
 
-		// Delete from database (continue even if external deletions failed)
-		err := s.deleteDomainFromDB(domainID, userID)
+		// Move to the trash (continue even if external deletions failed)
+		err := s.trashDomain(domainID, userID)
 		if err != nil {
 			reason := fmt.Sprintf("Database deletion failed: %v", err)
 			if len(deleteErrors) > 0 {
@@ -1165,10 +1754,12 @@ func (s *DomainService) DeleteBatchDomains(userID int, domainIDs []int) (*model.
 
 		response.Success = append(response.Success, result)
 		response.DeletedCount++
+
+		s.events.Publish(events.Event{Type: events.DomainDeleted, UserID: userID, DomainID: domainID})
 	}
 
-	log.Printf("Batch delete completed for user %d: %d/%d domains deleted successfully",
-		userID, response.DeletedCount, response.TotalCount)
+	s.logger.Info("Batch delete completed", "user_id", userID,
+		"deleted", response.DeletedCount, "total", response.TotalCount)
 
 	return response, nil
 }
@@ -1212,7 +1803,7 @@ func (s *DomainService) deleteDomainFromDB(domainID, userID int) error {
 	}
 
 	if rowsAffected == 0 {
-		return errors.New("domain not found or access denied")
+		return ErrDomainNotFound
 	}
 
 	// Commit transaction
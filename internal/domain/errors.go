@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Errors returned by the domain service for requests that cannot be applied. Handlers
+// match them with errors.Is to choose the response.
+var (
+	ErrDomainNotFound     = errors.New("domain not found")
+	ErrInvalidDomainName  = errors.New("invalid domain name format")
+	ErrDomainLimitReached = errors.New("domain limit reached")
+	ErrInvalidRegion      = errors.New("invalid region")
+	ErrRegionRequired     = errors.New("region is required")
+	ErrDomainExists       = errors.New("domain already exists in this region")
+	ErrDomainArchived     = errors.New("domain is archived")
+	ErrDomainNotVerified  = errors.New("domain is not verified")
+	ErrNoFieldsToUpdate   = errors.New("no fields to update")
+	ErrSameTransferUser   = errors.New("source and target user are the same")
+	ErrTargetUserNotFound = errors.New("target user not found")
+
+	ErrDomainAlreadyArchived     = errors.New("domain is already archived")
+	ErrDomainNotArchived         = errors.New("domain is not archived")
+	ErrDomainIsSubdomain         = errors.New("domain is a subdomain")
+	ErrInvalidSnoozeDuration     = errors.New("invalid snooze duration")
+	ErrRecreationInProgress      = errors.New("monitor recreation already in progress")
+	ErrProviderNotConfigured     = errors.New("provider not configured")
+	ErrDirectCheckRequired       = errors.New("check type requires the direct check")
+	ErrVerificationNotRequired   = errors.New("domain does not require verification")
+	ErrVerificationTokenNotFound = errors.New("verification token not found")
+	ErrRunbookEmpty              = errors.New("runbook URL or notes are required")
+	ErrRunbookNotFound           = errors.New("runbook not found")
+
+	// Maintenance windows that cannot be scheduled
+	ErrMaintenanceEndsFirst     = errors.New("maintenance must end after it starts")
+	ErrMaintenanceLongerThanGap = errors.New("a recurring maintenance window must be shorter than its period")
+	ErrMaintenanceOver          = errors.New("maintenance window is already over")
+	ErrMaintenanceNotFound      = errors.New("maintenance window not found")
+
+	ErrIncidentNotFound          = errors.New("incident not found")
+	ErrIncidentResolved          = errors.New("incident already resolved")
+	ErrAccountDeletionInProgress = errors.New("account deletion already in progress")
+	ErrAccountDeletionNotFound   = errors.New("account deletion not found")
+)
+
+// IntervalError is returned for a check interval outside the range the user's plan allows
+type IntervalError struct {
+	Min int
+	Max int
+}
+
+func (e *IntervalError) Error() string {
+	return fmt.Sprintf("interval must be between %d and %d minutes", e.Min, e.Max)
+}
+
+// CheckTypeError is returned when a port is missing for a TCP check or given for another
+// check type
+type CheckTypeError struct {
+	CheckType    string
+	PortRequired bool
+}
+
+func (e *CheckTypeError) Error() string {
+	if e.PortRequired {
+		return fmt.Sprintf("check type %s requires a port", e.CheckType)
+	}
+	return fmt.Sprintf("check type %s does not use a port", e.CheckType)
+}
+
+// ContentMatchError is returned for an invalid homepage content match
+type ContentMatchError struct {
+	Reason string // e.g. "pattern is required"
+}
+
+func (e *ContentMatchError) Error() string {
+	return "content match " + e.Reason
+}
+
+// MissingDomainsError lists the requested domains that do not exist or belong to
+// another user
+type MissingDomainsError struct {
+	IDs []int
+}
+
+func (e *MissingDomainsError) Error() string {
+	parts := make([]string, len(e.IDs))
+	for i, id := range e.IDs {
+		parts[i] = strconv.Itoa(id)
+	}
+	return "domains not found: " + strings.Join(parts, ", ")
+}
+
+// TransferConflictError is returned when the target user of a transfer already monitors
+// one of the domains in the same region
+type TransferConflictError struct {
+	Name string
+}
+
+func (e *TransferConflictError) Error() string {
+	return fmt.Sprintf("target user already monitors %s in this region", e.Name)
+}
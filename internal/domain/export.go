@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// exportColumns is the header row of a domain export
+var exportColumns = []interface{}{
+	"name", "region", "interval", "last_status", "response_time_ms", "last_check",
+	"monitor_guid", "site24x7_monitor_id", "direct_monitor_id",
+}
+
+// ExportDomainRows returns the user's domains with their latest status as rows for a
+// CSV or spreadsheet export, starting with a header row. Numeric columns are kept as
+// ints so spreadsheet writers can store them as numbers.
+func (s *DomainService) ExportDomainRows(userID int) ([][]interface{}, error) {
+	list, err := s.GetDomains(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get domains: %w", err)
+	}
+
+	rows := make([][]interface{}, 0, len(list.Domains)+1)
+	rows = append(rows, exportColumns)
+	for _, d := range list.Domains {
+		lastCheck := ""
+		if !d.LastCheck.IsZero() {
+			lastCheck = d.LastCheck.UTC().Format(time.RFC3339)
+		}
+		rows = append(rows, []interface{}{
+			d.Name,
+			d.Region,
+			d.Interval,
+			d.LastStatus,
+			d.TotalTime,
+			lastCheck,
+			d.GetMonitorGuid(),
+			d.GetSite24x7MonitorID(),
+			d.GetDirectMonitorID(),
+		})
+	}
+	return rows, nil
+}
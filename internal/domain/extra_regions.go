@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// normalizeExtraRegions upper-cases and de-duplicates the extra regions of a domain,
+// dropping its primary region, and checks that every region is active
+func (s *DomainService) normalizeExtraRegions(primary string, extra []string) ([]string, error) {
+	seen := map[string]bool{primary: true}
+	regions := []string{}
+	for _, region := range extra {
+		code := strings.ToUpper(strings.TrimSpace(region))
+		if code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+		regions = append(regions, code)
+	}
+	if len(regions) == 0 {
+		return regions, nil
+	}
+
+	var active int
+	err := s.db.Get(&active, "SELECT COUNT(*) FROM regions WHERE code = ANY($1) AND is_active = TRUE", pq.Array(regions))
+	if err != nil {
+		return nil, fmt.Errorf("error verifying region: %w", err)
+	}
+	if active != len(regions) {
+		return nil, ErrInvalidRegion
+	}
+	return regions, nil
+}
+
+// domainInRegions reports whether the user already has the domain in any of the regions,
+// as a primary or an extra region
+func (s *DomainService) domainInRegions(userID int, fullURL string, regions []string) (bool, error) {
+	var exists bool
+	err := s.db.Get(&exists, `
+        SELECT EXISTS (
+            SELECT 1 FROM domains
+            WHERE user_id = $1 AND LOWER(name) = LOWER($2) AND deleted_at IS NULL
+              AND (ARRAY[region::TEXT] || extra_regions) && $3::TEXT[]
+        )
+    `, userID, fullURL, pq.Array(regions))
+	if err != nil {
+		return false, fmt.Errorf("failed to check existing domains: %w", err)
+	}
+	return exists, nil
+}
+
+// sameRegions reports whether two region lists hold the same regions in the same order
+func sameRegions(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
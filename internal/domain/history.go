@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"domain-detection-go/pkg/model"
+
+	"github.com/lib/pq"
+)
+
+// Limits for GET /api/domains/:id/history
+const (
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 1000
+)
+
+// RecordCheck stores the result of a check in the domain's history
+func (s *DomainService) RecordCheck(domainID int, result model.DomainCheckResult) error {
+	_, err := s.db.Exec(`
+        INSERT INTO domain_check_history
+        (domain_id, available, status_code, error_code, total_time, error_description, dissenting_providers, checked_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+    `, domainID, result.Available, result.StatusCode, result.ErrorCode, result.TotalTime, result.ErrorDescription,
+		pq.Array(nonNilStrings(result.DissentingProviders)))
+	if err != nil {
+		return fmt.Errorf("failed to record check history: %w", err)
+	}
+	return nil
+}
+
+// RecordSkippedCheck records that a due check was skipped to shed load. The skip
+// consumes the check's slot, so the domain is next due one interval later.
+func (s *DomainService) RecordSkippedCheck(domainID int, reason string, backlog int) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+        INSERT INTO domain_skipped_checks (domain_id, reason, backlog, skipped_at)
+        VALUES ($1, $2, $3, NOW())
+    `, domainID, reason, backlog)
+	if err != nil {
+		return fmt.Errorf("failed to record skipped check: %w", err)
+	}
+	if _, err := tx.Exec("UPDATE domains SET last_skipped_at = NOW() WHERE id = $1", domainID); err != nil {
+		return fmt.Errorf("failed to update last skipped time: %w", err)
+	}
+	return tx.Commit()
+}
+
+// GetFlappingDomainIDs returns the domains whose availability changed at least
+// minChanges times within the window
+func (s *DomainService) GetFlappingDomainIDs(window time.Duration, minChanges int) (map[int]bool, error) {
+	var ids []int
+	err := s.db.Select(&ids, `
+        SELECT domain_id FROM (
+            SELECT domain_id,
+                   available <> LAG(available) OVER (PARTITION BY domain_id ORDER BY checked_at) AS changed
+            FROM domain_check_history
+            WHERE checked_at >= NOW() - make_interval(secs => $1)
+        ) transitions
+        WHERE changed
+        GROUP BY domain_id
+        HAVING COUNT(*) >= $2
+    `, window.Seconds(), minChanges)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get flapping domains: %w", err)
+	}
+
+	flapping := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		flapping[id] = true
+	}
+	return flapping, nil
+}
+
+// GetCheckHistory returns the most recent checks of a domain owned by the user within
+// the optional time range, together with its uptime over that range
+func (s *DomainService) GetCheckHistory(domainID, userID int, from, to *time.Time, limit int) (*model.DomainHistoryResponse, error) {
+	if _, err := s.GetDomain(domainID, userID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	if limit > maxHistoryLimit {
+		limit = maxHistoryLimit
+	}
+
+	const rangeFilter = `
+        WHERE domain_id = $1
+          AND ($2::timestamptz IS NULL OR checked_at >= $2)
+          AND ($3::timestamptz IS NULL OR checked_at <= $3)`
+
+	response := &model.DomainHistoryResponse{DomainID: domainID, From: from, To: to, History: []model.DomainCheckHistory{}}
+
+	var counts struct {
+		Total  int `db:"total"`
+		Failed int `db:"failed"`
+	}
+	err := s.db.Get(&counts, `
+        SELECT COUNT(*) AS total, COUNT(*) FILTER (WHERE NOT available) AS failed
+        FROM domain_check_history`+rangeFilter, domainID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count check history: %w", err)
+	}
+	err = s.db.Get(&response.SkippedChecks, `
+        SELECT COUNT(*) FROM domain_skipped_checks
+        WHERE domain_id = $1
+          AND ($2::timestamptz IS NULL OR skipped_at >= $2)
+          AND ($3::timestamptz IS NULL OR skipped_at <= $3)`, domainID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count skipped checks: %w", err)
+	}
+	response.TotalChecks = counts.Total
+	response.FailedChecks = counts.Failed
+	if counts.Total > 0 {
+		response.UptimePercentage = float64(counts.Total-counts.Failed) * 100 / float64(counts.Total)
+	}
+
+	var rows []struct {
+		model.DomainCheckHistory
+		Dissenting pq.StringArray `db:"dissenting_providers"`
+	}
+	err = s.db.Select(&rows, `
+        SELECT id, domain_id, available, COALESCE(status_code, 0) AS status_code,
+               COALESCE(error_code, 0) AS error_code, COALESCE(total_time, 0) AS total_time,
+               COALESCE(error_description, '') AS error_description, dissenting_providers, checked_at
+        FROM domain_check_history`+rangeFilter+`
+        ORDER BY checked_at DESC
+        LIMIT $4`, domainID, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get check history: %w", err)
+	}
+	for _, row := range rows {
+		row.DomainCheckHistory.DissentingProviders = nonNilStrings(row.Dissenting)
+		response.History = append(response.History, row.DomainCheckHistory)
+	}
+
+	return response, nil
+}
+
+// nonNilStrings returns the slice, or an empty one for nil, so it is stored and encoded
+// as an empty array
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
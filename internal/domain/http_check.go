@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"fmt"
+
+	"domain-detection-go/internal/credentials"
+	"domain-detection-go/pkg/model"
+)
+
+// httpCheckColumns are the columns of model.HTTPCheckSettings
+const httpCheckColumns = `http_method, http_headers, basic_auth_username, basic_auth_password,
+               expected_status_codes, follow_redirects`
+
+// updateHTTPCheckSettings applies a request on top of the current settings of a domain.
+// A new basic auth password is encrypted before it is stored.
+func (s *DomainService) updateHTTPCheckSettings(domainID int, current model.HTTPCheckSettings, req model.HTTPCheckRequest) error {
+	settings := current
+	if req.HTTPMethod != nil {
+		settings.HTTPMethod = *req.HTTPMethod
+	}
+	if req.HTTPHeaders != nil {
+		settings.HTTPHeaders = req.HTTPHeaders
+	}
+	if req.BasicAuthUsername != nil {
+		settings.BasicAuthUsername = *req.BasicAuthUsername
+	}
+	if req.BasicAuthPassword != nil {
+		settings.BasicAuthPassword = ""
+		if *req.BasicAuthPassword != "" {
+			sealed, err := credentials.Seal(*req.BasicAuthPassword, s.encryptionKey)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt basic auth password: %w", err)
+			}
+			settings.BasicAuthPassword = sealed
+		}
+	}
+	if req.ExpectedStatusCodes != nil {
+		settings.ExpectedStatusCodes = req.ExpectedStatusCodes
+	}
+	if req.FollowRedirects != nil {
+		settings.FollowRedirects = *req.FollowRedirects
+	}
+
+	_, err := s.db.Exec(`
+        UPDATE domains
+        SET http_method = $1, http_headers = $2, basic_auth_username = $3, basic_auth_password = $4,
+            expected_status_codes = $5, follow_redirects = $6, updated_at = NOW()
+        WHERE id = $7
+    `, settings.HTTPMethod, settings.HTTPHeaders, settings.BasicAuthUsername, settings.BasicAuthPassword,
+		settings.ExpectedStatusCodes, settings.FollowRedirects, domainID)
+	if err != nil {
+		return fmt.Errorf("failed to update HTTP check settings: %w", err)
+	}
+	return nil
+}
+
+// MonitorCheckSettings returns the HTTP settings to create the provider monitors of a
+// domain with, with the basic auth password decrypted. The defaults are returned when
+// the settings cannot be read.
+func (s *DomainService) MonitorCheckSettings(domainID int) model.HTTPCheckSettings {
+	var settings model.HTTPCheckSettings
+	err := s.db.Get(&settings, "SELECT "+httpCheckColumns+" FROM domains WHERE id = $1", domainID)
+	if err != nil {
+		s.logger.Warn("Failed to get HTTP check settings, using defaults", "domain_id", domainID, "error", err)
+		return model.DefaultHTTPCheckSettings()
+	}
+
+	if settings.BasicAuthPassword != "" {
+		password, err := credentials.Open(settings.BasicAuthPassword, s.encryptionKey)
+		if err != nil {
+			s.logger.Error("Failed to decrypt basic auth password", "domain_id", domainID, "error", err)
+			password = ""
+		}
+		settings.BasicAuthPassword = password
+	}
+	return settings
+}
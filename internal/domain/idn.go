@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// idnForms returns a domain or URL with its host in the ASCII (punycode) form and in the
+// Unicode form. Hosts that are plain ASCII and not punycode are returned unchanged with
+// an empty Unicode form.
+func idnForms(input string) (ascii, unicode string, err error) {
+	host := input
+	if parsedURL, err := url.Parse(input); err == nil && (parsedURL.Scheme == "http" || parsedURL.Scheme == "https") {
+		host = parsedURL.Hostname()
+	}
+	if !isIDN(host) {
+		return input, "", nil
+	}
+
+	asciiHost, err := idna.Lookup.ToASCII(host)
+	if err != nil {
+		return "", "", err
+	}
+	unicodeHost, err := idna.Display.ToUnicode(asciiHost)
+	if err != nil {
+		return "", "", err
+	}
+	return strings.Replace(input, host, asciiHost, 1), strings.Replace(input, host, unicodeHost, 1), nil
+}
+
+// isIDN reports whether a host has non-ASCII characters or punycode labels
+func isIDN(host string) bool {
+	for _, r := range host {
+		if r > 127 {
+			return true
+		}
+	}
+	for _, label := range strings.Split(strings.ToLower(host), ".") {
+		if strings.HasPrefix(label, "xn--") {
+			return true
+		}
+	}
+	return false
+}
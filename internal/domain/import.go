@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"domain-detection-go/pkg/model"
+)
+
+// maxImportRows bounds the data rows of an imported CSV file, like the batch endpoint
+const maxImportRows = 100
+
+// ImportDomainsCSV adds the domains listed in a CSV file. The first line is a header
+// naming the columns; name and region are required, interval is optional and falls
+// back to the default. Rows are validated here and then added through AddBatchDomains,
+// and the outcome is reported per row. An error is only returned when the file itself
+// cannot be used.
+func (s *DomainService) ImportDomainsCSV(ctx context.Context, userID int, r io.Reader) (*model.DomainImportResponse, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, column := range header {
+		// Spreadsheet exports often start with a byte order mark
+		column = strings.TrimPrefix(column, "\ufeff")
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	nameCol, hasName := columns["name"]
+	regionCol, hasRegion := columns["region"]
+	intervalCol, hasInterval := columns["interval"]
+	if !hasName || !hasRegion {
+		return nil, errors.New("CSV header must contain name and region columns")
+	}
+
+	field := func(record []string, col int) string {
+		if col < len(record) {
+			return strings.TrimSpace(record[col])
+		}
+		return ""
+	}
+
+	response := &model.DomainImportResponse{Rows: []model.DomainImportRowResult{}}
+	seen := make(map[string]int)
+	var items []model.DomainBatchItem
+	pending := make(map[string]int) // name+region -> index in response.Rows
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		if len(response.Rows) >= maxImportRows {
+			return nil, fmt.Errorf("too many rows, maximum allowed is %d", maxImportRows)
+		}
+
+		row := model.DomainImportRowResult{
+			Row:    line,
+			Name:   field(record, nameCol),
+			Region: strings.ToUpper(field(record, regionCol)),
+			Status: model.ImportRowFailed,
+		}
+
+		key := strings.ToLower(row.Name) + ":" + row.Region
+		switch {
+		case row.Name == "":
+			row.Reason = "Missing name"
+		case row.Region == "":
+			row.Reason = "Missing region"
+		case seen[key] != 0:
+			row.Reason = fmt.Sprintf("Duplicate of row %d", seen[key])
+		}
+		if hasInterval && row.Reason == "" {
+			if value := field(record, intervalCol); value != "" {
+				interval, err := strconv.Atoi(value)
+				if err != nil || interval <= 0 {
+					row.Reason = "Invalid interval: " + value
+				}
+				row.Interval = interval
+			}
+		}
+
+		if row.Reason == "" {
+			seen[key] = row.Row
+			pending[row.Name+"\x00"+row.Region] = len(response.Rows)
+			items = append(items, model.DomainBatchItem{Name: row.Name, Region: row.Region, Interval: row.Interval})
+		}
+		response.Rows = append(response.Rows, row)
+	}
+
+	if len(response.Rows) == 0 {
+		return nil, errors.New("CSV file has no domain rows")
+	}
+
+	if len(items) > 0 {
+		batch := s.AddBatchDomains(ctx, userID, model.DomainBatchAddRequest{Domains: items})
+		for _, result := range batch.Success {
+			if i, ok := pending[result.Name+"\x00"+result.Region]; ok {
+				response.Rows[i].Status = model.ImportRowAdded
+				response.Rows[i].ID = result.ID
+			}
+		}
+		for _, result := range batch.Failed {
+			if i, ok := pending[result.Name+"\x00"+result.Region]; ok {
+				response.Rows[i].Reason = result.Reason
+			}
+		}
+	}
+
+	response.Total = len(response.Rows)
+	for _, row := range response.Rows {
+		if row.Status == model.ImportRowAdded {
+			response.Added++
+		} else {
+			response.Failed++
+		}
+	}
+	return response, nil
+}
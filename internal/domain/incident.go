@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"domain-detection-go/pkg/model"
+)
+
+// incidentColumns are the columns scanned into model.Incident
+const incidentColumns = `id, domain_id, user_id, domain_name, opened_at, resolved_at, duration_seconds,
+               failure_count, first_status, last_status, last_error, last_failure_at,
+               acknowledged_at, acknowledged_by, acknowledged_until`
+
+// defaultAckTimeout is how long an acknowledgement pauses alerts unless configured
+const defaultAckTimeout = 4 * time.Hour
+
+// SetAckTimeout sets how long an incident acknowledgement pauses its alerts
+func (s *DomainService) SetAckTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		s.ackTimeout = timeout
+	}
+}
+
+// RecordIncidentFailure attaches a failed check to the domain's open incident, opening
+// a new incident when there is none
+func (s *DomainService) RecordIncidentFailure(domain model.Domain) (*model.Incident, error) {
+	var incident model.Incident
+	err := s.db.Get(&incident, `
+        UPDATE incidents
+        SET failure_count = failure_count + 1, last_status = $2, last_error = $3, last_failure_at = NOW()
+        WHERE domain_id = $1 AND resolved_at IS NULL
+        RETURNING `+incidentColumns,
+		domain.ID, domain.LastStatus, domain.ErrorDescription)
+	if err == nil {
+		return &incident, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to update incident: %w", err)
+	}
+
+	err = s.db.Get(&incident, `
+        INSERT INTO incidents (domain_id, user_id, domain_name, first_status, last_status, last_error)
+        VALUES ($1, $2, $3, $4, $4, $5)
+        RETURNING `+incidentColumns,
+		domain.ID, domain.UserID, domain.Name, domain.LastStatus, domain.ErrorDescription)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open incident: %w", err)
+	}
+	return &incident, nil
+}
+
+// ResolveIncident closes the domain's open incident and records its duration. Nil is
+// returned when no incident was open.
+func (s *DomainService) ResolveIncident(domainID int) (*model.Incident, error) {
+	var incident model.Incident
+	err := s.db.Get(&incident, `
+        UPDATE incidents
+        SET resolved_at = NOW(), duration_seconds = EXTRACT(EPOCH FROM NOW() - opened_at)::INTEGER
+        WHERE domain_id = $1 AND resolved_at IS NULL
+        RETURNING `+incidentColumns,
+		domainID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve incident: %w", err)
+	}
+	return &incident, nil
+}
+
+// GetIncidents lists the user's incidents, newest first. status may be "open",
+// "resolved" or empty for both, and domainID 0 covers every domain.
+func (s *DomainService) GetIncidents(userID, domainID int, status string, limit int) ([]model.Incident, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+
+	incidents := []model.Incident{}
+	err := s.db.Select(&incidents, `
+        SELECT `+incidentColumns+`
+        FROM incidents
+        WHERE user_id = $1 AND ($2 = 0 OR domain_id = $2)
+          AND ($3 = '' OR ($3 = 'open') = (resolved_at IS NULL))
+        ORDER BY opened_at DESC
+        LIMIT $4
+    `, userID, domainID, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get incidents: %w", err)
+	}
+	return incidents, nil
+}
+
+// AcknowledgeIncident records who took care of an open incident and pauses its down
+// alerts and escalations until it recovers or the acknowledgement times out
+func (s *DomainService) AcknowledgeIncident(userID, incidentID int, acknowledgedBy string) (*model.Incident, error) {
+	var incident model.Incident
+	err := s.db.Get(&incident, `
+        UPDATE incidents
+        SET acknowledged_at = NOW(), acknowledged_by = $3,
+            acknowledged_until = NOW() + make_interval(secs => $4)
+        WHERE id = $1 AND user_id = $2 AND resolved_at IS NULL
+        RETURNING `+incidentColumns,
+		incidentID, userID, acknowledgedBy, s.ackTimeout.Seconds())
+	if err == sql.ErrNoRows {
+		var resolved bool
+		err = s.db.Get(&resolved, `
+            SELECT resolved_at IS NOT NULL FROM incidents WHERE id = $1 AND user_id = $2
+        `, incidentID, userID)
+		if err == sql.ErrNoRows {
+			return nil, ErrIncidentNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get incident: %w", err)
+		}
+		return nil, ErrIncidentResolved
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to acknowledge incident: %w", err)
+	}
+	return &incident, nil
+}
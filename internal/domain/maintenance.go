@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"domain-detection-go/pkg/model"
+)
+
+// CreateMaintenanceWindow schedules maintenance for one domain, or for every domain of
+// the user when domainID is 0
+func (s *DomainService) CreateMaintenanceWindow(userID, domainID int, req model.MaintenanceWindowRequest) (*model.MaintenanceWindow, error) {
+	window := model.MaintenanceWindow{
+		UserID:     userID,
+		StartsAt:   req.StartsAt,
+		EndsAt:     req.EndsAt,
+		Recurrence: req.Recurrence,
+		Reason:     req.Reason,
+	}
+	if window.Recurrence == "" {
+		window.Recurrence = model.RecurrenceNone
+	}
+	if !window.EndsAt.After(window.StartsAt) {
+		return nil, ErrMaintenanceEndsFirst
+	}
+	if period := window.RecurrencePeriod(); period > 0 && window.EndsAt.Sub(window.StartsAt) >= period {
+		return nil, ErrMaintenanceLongerThanGap
+	}
+	if window.Recurrence == model.RecurrenceNone && !window.EndsAt.After(time.Now()) {
+		return nil, ErrMaintenanceOver
+	}
+
+	if domainID != 0 {
+		domain, err := s.GetDomain(domainID, userID)
+		if err != nil {
+			return nil, err
+		}
+		window.DomainID = &domain.ID
+	}
+
+	err := s.db.Get(&window, `
+        INSERT INTO maintenance_windows (user_id, domain_id, starts_at, ends_at, recurrence, reason)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id, user_id, domain_id, starts_at, ends_at, recurrence, reason, created_at
+    `, window.UserID, window.DomainID, window.StartsAt, window.EndsAt, window.Recurrence, window.Reason)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create maintenance window: %w", err)
+	}
+	return &window, nil
+}
+
+// GetMaintenanceWindows lists the user's maintenance windows that are current or
+// upcoming. With a domainID, only windows covering that domain are listed, including
+// user-wide ones.
+func (s *DomainService) GetMaintenanceWindows(userID, domainID int) ([]model.MaintenanceWindow, error) {
+	windows := []model.MaintenanceWindow{}
+	err := s.db.Select(&windows, `
+        SELECT id, user_id, domain_id, starts_at, ends_at, recurrence, reason, created_at
+        FROM maintenance_windows
+        WHERE user_id = $1 AND ($2 = 0 OR domain_id IS NULL OR domain_id = $2)
+          AND (recurrence <> 'none' OR ends_at > NOW())
+        ORDER BY starts_at
+    `, userID, domainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get maintenance windows: %w", err)
+	}
+	return windows, nil
+}
+
+// DeleteMaintenanceWindow removes one of the user's maintenance windows
+func (s *DomainService) DeleteMaintenanceWindow(userID, windowID int) error {
+	result, err := s.db.Exec("DELETE FROM maintenance_windows WHERE id = $1 AND user_id = $2", windowID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete maintenance window: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check deleted maintenance window: %w", err)
+	}
+	if rows == 0 {
+		return ErrMaintenanceNotFound
+	}
+	return nil
+}
+
+// InMaintenance reports whether a maintenance window covering the domain is active at t
+func (s *DomainService) InMaintenance(domain model.Domain, t time.Time) (bool, error) {
+	windows, err := s.GetMaintenanceWindows(domain.UserID, domain.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, window := range windows {
+		if window.ActiveAt(t) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
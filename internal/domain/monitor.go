@@ -1,12 +1,17 @@
 package domain
 
-import "domain-detection-go/pkg/model"
+import (
+	"context"
+
+	"domain-detection-go/pkg/model"
+)
 
 // MonitorClient defines the interface for domain monitoring operations
 type MonitorClient interface {
-	CreateMonitor(fullURL string, name string, regions []string) (string, error)
+	CreateMonitor(fullURL string, name string, tags model.MonitorTags, regions []string, interval int, settings model.HTTPCheckSettings) (string, error)
 	UpdateMonitorStatus(monitorID string, isActive bool) error
+	UpdateMonitorInterval(monitorID string, interval int) error
 	DeleteMonitor(monitorID string) error
-	GetLatestMonitorCheck(monitorID string, region string) (*model.DomainCheckResult, error)
+	GetLatestMonitorCheck(ctx context.Context, monitorID string, region string) (*model.DomainCheckResult, error)
 	Close()
 }
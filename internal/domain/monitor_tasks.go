@@ -0,0 +1,257 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"domain-detection-go/pkg/model"
+
+	"github.com/lib/pq"
+)
+
+// maxMonitorTaskAttempts is how often a monitor task is tried before it is marked failed
+const maxMonitorTaskAttempts = 8
+
+// errMonitorTaskObsolete is returned for tasks that no longer need to run, such as the
+// creation of a monitor for a deleted domain
+var errMonitorTaskObsolete = errors.New("monitor task is obsolete")
+
+// monitorTaskBackoff returns the delay before the next attempt of a task that has failed
+// the given number of times
+func monitorTaskBackoff(attempts int) time.Duration {
+	if attempts > 8 {
+		return 6 * time.Hour
+	}
+	delay := time.Minute << attempts
+	if delay > 6*time.Hour {
+		delay = 6 * time.Hour
+	}
+	return delay
+}
+
+// enqueueMonitorTask queues a failed monitor creation or deletion for retry. A task that
+// is already pending is not queued twice.
+func (s *DomainService) enqueueMonitorTask(userID, domainID int, provider, action, monitorID string, cause error) {
+	_, err := s.db.Exec(`
+        INSERT INTO monitor_tasks (user_id, domain_id, provider, action, monitor_id, attempts, last_error, next_attempt_at)
+        SELECT $1, $2, $3, $4, NULLIF($5, ''), 1, $6, NOW() + make_interval(secs => $7)
+        WHERE NOT EXISTS (
+            SELECT 1 FROM monitor_tasks
+            WHERE domain_id = $2 AND provider = $3 AND action = $4
+              AND COALESCE(monitor_id, '') = $5 AND status = 'pending'
+        )
+    `, userID, domainID, provider, action, monitorID, cause.Error(), int(monitorTaskBackoff(1).Seconds()))
+	if err != nil {
+		s.logger.Error("Failed to queue monitor task", "action", action, "provider", provider, "domain_id", domainID, "error", err)
+		return
+	}
+	s.logger.Info("Queued monitor task for retry", "action", action, "provider", provider, "domain_id", domainID, "cause", cause)
+}
+
+// deleteProviderMonitor deletes a provider monitor, queueing the deletion for retry if it fails
+func (s *DomainService) deleteProviderMonitor(userID, domainID int, provider, monitorID string) error {
+	p, ok := s.monitorProvider(provider)
+	if !ok || monitorID == "" {
+		return nil
+	}
+	if err := p.client.DeleteMonitor(monitorID); err != nil {
+		s.enqueueMonitorTask(userID, domainID, provider, model.MonitorTaskDelete, monitorID, err)
+		return err
+	}
+	return nil
+}
+
+// monitorCreationPending reports whether the creation of a domain's monitor on a provider
+// is waiting to be retried
+func (s *DomainService) monitorCreationPending(domainID int, provider string) bool {
+	var pending bool
+	err := s.db.Get(&pending, `
+        SELECT EXISTS (
+            SELECT 1 FROM monitor_tasks
+            WHERE domain_id = $1 AND provider = $2 AND action = 'create' AND status = 'pending'
+        )
+    `, domainID, provider)
+	if err != nil {
+		s.logger.Error("Failed to check monitor tasks", "domain_id", domainID, "error", err)
+		return false
+	}
+	return pending
+}
+
+// attachMonitorTasks populates the monitor tasks and provisioning state of domains
+func (s *DomainService) attachMonitorTasks(domains []model.Domain) error {
+	if len(domains) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(domains))
+	for i, d := range domains {
+		ids[i] = int64(d.ID)
+	}
+
+	var tasks []model.MonitorTask
+	err := s.db.Select(&tasks, `
+        SELECT id, user_id, domain_id, provider, action, monitor_id, status, attempts, last_error,
+               next_attempt_at, created_at, updated_at
+        FROM monitor_tasks
+        WHERE domain_id = ANY($1)
+        ORDER BY id
+    `, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to get monitor tasks: %w", err)
+	}
+
+	byDomain := make(map[int][]model.MonitorTask)
+	for _, task := range tasks {
+		byDomain[task.DomainID] = append(byDomain[task.DomainID], task)
+	}
+	for i := range domains {
+		domains[i].MonitorTasks = byDomain[domains[i].ID]
+		for _, task := range domains[i].MonitorTasks {
+			if task.Action == model.MonitorTaskCreate && task.Status == model.MonitorTaskPending {
+				domains[i].Provisioning = true
+			}
+		}
+	}
+	return nil
+}
+
+// AttachMonitorTasks populates the monitor tasks and provisioning state of a domain
+func (s *DomainService) AttachMonitorTasks(domain *model.Domain) error {
+	domains := []model.Domain{*domain}
+	if err := s.attachMonitorTasks(domains); err != nil {
+		return err
+	}
+	domain.MonitorTasks = domains[0].MonitorTasks
+	domain.Provisioning = domains[0].Provisioning
+	return nil
+}
+
+// RetryMonitorTasks schedules the failed monitor tasks of a domain owned by the user for
+// an immediate attempt. It returns the number of tasks retried.
+func (s *DomainService) RetryMonitorTasks(userID, domainID int) (int, error) {
+	if _, err := s.GetDomain(domainID, userID); err != nil {
+		return 0, err
+	}
+
+	result, err := s.db.Exec(`
+        UPDATE monitor_tasks
+        SET status = 'pending', attempts = 0, next_attempt_at = NOW(), updated_at = NOW()
+        WHERE domain_id = $1 AND user_id = $2 AND status = 'failed'
+    `, domainID, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to retry monitor tasks: %w", err)
+	}
+	retried, _ := result.RowsAffected()
+	return int(retried), nil
+}
+
+// ProcessMonitorTasks attempts every monitor task that is due
+func (s *DomainService) ProcessMonitorTasks() error {
+	var tasks []model.MonitorTask
+	err := s.db.Select(&tasks, `
+        SELECT id, user_id, domain_id, provider, action, monitor_id, status, attempts, last_error,
+               next_attempt_at, created_at, updated_at
+        FROM monitor_tasks
+        WHERE status = 'pending' AND next_attempt_at <= NOW()
+        ORDER BY next_attempt_at
+        LIMIT 50
+    `)
+	if err != nil {
+		return fmt.Errorf("failed to get due monitor tasks: %w", err)
+	}
+
+	for _, task := range tasks {
+		err := s.runMonitorTask(task)
+		if err == nil || errors.Is(err, errMonitorTaskObsolete) {
+			if err == nil {
+				s.logger.Info("Monitor task succeeded", "task_id", task.ID, "action", task.Action,
+					"provider", task.Provider, "domain_id", task.DomainID, "attempts", task.Attempts+1)
+			}
+			if _, err := s.db.Exec("DELETE FROM monitor_tasks WHERE id = $1", task.ID); err != nil {
+				s.logger.Error("Failed to remove monitor task", "task_id", task.ID, "error", err)
+			}
+			continue
+		}
+
+		attempts := task.Attempts + 1
+		status := model.MonitorTaskPending
+		if attempts >= maxMonitorTaskAttempts {
+			status = model.MonitorTaskFailed
+		}
+		s.logger.Warn("Monitor task failed", "task_id", task.ID, "action", task.Action, "provider", task.Provider,
+			"domain_id", task.DomainID, "attempts", attempts, "error", err)
+
+		_, dbErr := s.db.Exec(`
+            UPDATE monitor_tasks
+            SET status = $1, attempts = $2, last_error = $3,
+                next_attempt_at = NOW() + make_interval(secs => $4), updated_at = NOW()
+            WHERE id = $5
+        `, status, attempts, err.Error(), int(monitorTaskBackoff(attempts).Seconds()), task.ID)
+		if dbErr != nil {
+			s.logger.Error("Failed to update monitor task", "task_id", task.ID, "error", dbErr)
+		}
+	}
+
+	return nil
+}
+
+// runMonitorTask makes one attempt at a monitor task
+func (s *DomainService) runMonitorTask(task model.MonitorTask) error {
+	p, ok := s.monitorProvider(task.Provider)
+	if !ok {
+		return errMonitorTaskObsolete
+	}
+
+	switch task.Action {
+	case model.MonitorTaskDelete:
+		if task.MonitorID == nil {
+			return errMonitorTaskObsolete
+		}
+		return p.client.DeleteMonitor(*task.MonitorID)
+
+	case model.MonitorTaskCreate:
+		domain, err := s.GetDomain(task.DomainID, task.UserID)
+		if err != nil {
+			if errors.Is(err, ErrDomainNotFound) {
+				return errMonitorTaskObsolete
+			}
+			return err
+		}
+		if domain.ArchivedAt != nil || domain.VerificationPending() || p.monitorID(*domain) != "" {
+			return errMonitorTaskObsolete
+		}
+		selected, err := s.SelectedProviders(domain.UserID, domain.ID)
+		if err != nil {
+			return err
+		}
+		for _, name := range selected {
+			if name == p.name {
+				_, err = s.createProviderMonitor(*domain, p)
+				return err
+			}
+		}
+	}
+
+	return errMonitorTaskObsolete
+}
+
+// RunScheduledMonitorTasks retries failed monitor creations and deletions every minute
+func (s *DomainService) RunScheduledMonitorTasks(ctx context.Context) {
+	s.logger.Info("RunScheduledMonitorTasks")
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("RunScheduledMonitorTasks stopped")
+			return
+		case <-ticker.C:
+			if err := s.ProcessMonitorTasks(); err != nil {
+				s.logger.Error("Monitor tasks failed", "error", err)
+			}
+		}
+	}
+}
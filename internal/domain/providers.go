@@ -0,0 +1,350 @@
+package domain
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"domain-detection-go/internal/events"
+	"domain-detection-go/pkg/model"
+
+	"github.com/lib/pq"
+)
+
+// monitorProvider is an external monitoring provider domains can be checked by. A new
+// provider needs a client, a domains column for its monitor ID and an entry here.
+type monitorProvider struct {
+	name        string
+	client      MonitorClient
+	monitorID   func(model.Domain) string
+	store       func(domainID int, monitorID string) (int, error)
+	multiRegion bool // Monitors check from the fallback regions too, and results can be read per region
+}
+
+// monitorProviders returns the configured providers in order of preference. The result
+// of the first provider that answers supplies the status details of a check.
+func (s *DomainService) monitorProviders() []monitorProvider {
+	var providers []monitorProvider
+	if s.uptrendsClient != nil {
+		providers = append(providers, monitorProvider{
+			name:        model.ProviderUptrends,
+			client:      s.uptrendsClient,
+			monitorID:   model.Domain.GetMonitorGuid,
+			store:       s.UpdateDomainUptrendsGUID,
+			multiRegion: true,
+		})
+	}
+	if s.site24x7Client != nil {
+		providers = append(providers, monitorProvider{
+			name:      model.ProviderSite24x7,
+			client:    s.site24x7Client,
+			monitorID: model.Domain.GetSite24x7MonitorID,
+			store:     s.UpdateDomainSite24x7ID,
+		})
+	}
+	return providers
+}
+
+// monitorProvider returns a configured provider by name
+func (s *DomainService) monitorProvider(name string) (monitorProvider, bool) {
+	for _, p := range s.monitorProviders() {
+		if p.name == name {
+			return p, true
+		}
+	}
+	return monitorProvider{}, false
+}
+
+// MonitorProviderNames returns the names of the configured providers in order of preference
+func (s *DomainService) MonitorProviderNames() []string {
+	names := []string{}
+	for _, p := range s.monitorProviders() {
+		names = append(names, p.name)
+	}
+	return names
+}
+
+// syncMonitorIntervals pushes a domain's new check interval to its provider monitors, so
+// providers do not check more often than the domain is polled
+func (s *DomainService) syncMonitorIntervals(domain model.Domain, interval int) {
+	for _, p := range s.monitorProviders() {
+		monitorID := p.monitorID(domain)
+		if monitorID == "" {
+			continue
+		}
+		if err := p.client.UpdateMonitorInterval(monitorID, interval); err != nil {
+			s.logger.Error("Failed to update monitor interval", "provider", p.name, "domain_id", domain.ID, "interval", interval, "error", err)
+		}
+	}
+}
+
+// ProviderClient returns the client of a configured provider, or nil
+func (s *DomainService) ProviderClient(name string) MonitorClient {
+	p, ok := s.monitorProvider(name)
+	if !ok {
+		return nil
+	}
+	return p.client
+}
+
+// ProviderMonitorID returns the monitor ID of a domain on a provider
+func (s *DomainService) ProviderMonitorID(domain model.Domain, name string) string {
+	p, ok := s.monitorProvider(name)
+	if !ok {
+		return ""
+	}
+	return p.monitorID(domain)
+}
+
+// SelectedProviders returns the providers a domain is checked by, in order of preference:
+// the domain's own selection, else its owner's, else every configured provider.
+// Selected providers that are not configured on this deployment are left out.
+func (s *DomainService) SelectedProviders(userID, domainID int) ([]string, error) {
+	// The website monitors of the providers cannot run TCP or ping checks
+	if checkType, err := s.domainCheckType(domainID); err == nil && !model.IsHTTPCheckType(checkType) {
+		return []string{}, nil
+	}
+
+	var selected pq.StringArray
+	err := s.db.Get(&selected, `
+        SELECT COALESCE(
+            (SELECT providers FROM domain_monitor_providers WHERE domain_id = $1),
+            (SELECT providers FROM user_monitor_providers WHERE user_id = $2)
+        )
+    `, domainID, userID)
+	if err != nil && err != sql.ErrNoRows {
+		return s.MonitorProviderNames(), fmt.Errorf("failed to get provider selection: %w", err)
+	}
+	return s.filterProviders(selected), nil
+}
+
+// filterProviders keeps the configured providers of a selection in order of preference.
+// An empty selection means every configured provider.
+func (s *DomainService) filterProviders(selected []string) []string {
+	if len(selected) == 0 {
+		return s.MonitorProviderNames()
+	}
+	wanted := make(map[string]bool, len(selected))
+	for _, name := range selected {
+		wanted[name] = true
+	}
+	providers := []string{}
+	for _, name := range s.MonitorProviderNames() {
+		if wanted[name] {
+			providers = append(providers, name)
+		}
+	}
+	return providers
+}
+
+// validateProviders checks that every provider of a selection is configured
+func (s *DomainService) validateProviders(providers []string) error {
+	for _, name := range providers {
+		if _, ok := s.monitorProvider(name); !ok {
+			return fmt.Errorf("unsupported provider %q", name)
+		}
+	}
+	return nil
+}
+
+// EnsureMonitor returns the monitor ID of a domain on a provider, creating the monitor
+// if the domain has none. It returns "" if the monitor could not be created, in which
+// case the creation is queued for retry.
+func (s *DomainService) EnsureMonitor(domain model.Domain, name string) string {
+	p, ok := s.monitorProvider(name)
+	if !ok {
+		return ""
+	}
+	if id := p.monitorID(domain); id != "" {
+		return id
+	}
+	if s.monitorCreationPending(domain.ID, name) {
+		return ""
+	}
+
+	s.logger.Info("Creating missing monitor", "provider", name, "domain_id", domain.ID, "region", domain.Region)
+
+	monitorID, err := s.createProviderMonitor(domain, p)
+	if err != nil {
+		s.logger.Error("Failed to create monitor", "provider", name, "domain_id", domain.ID, "error", err)
+		s.enqueueMonitorTask(domain.UserID, domain.ID, name, model.MonitorTaskCreate, "", err)
+		return ""
+	}
+
+	s.logger.Info("Created and linked monitor", "provider", name, "domain_id", domain.ID, "monitor_id", monitorID)
+	return monitorID
+}
+
+// createProviderMonitor creates the monitor of a domain on a provider and stores its ID
+func (s *DomainService) createProviderMonitor(domain model.Domain, p monitorProvider) (string, error) {
+	parsedURL, err := url.Parse(domain.Name)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse URL: %w", err)
+	}
+	displayName := parsedURL.Hostname()
+	if displayName == "" {
+		displayName = domain.Name
+	}
+	tags := s.MonitorTags(domain.UserID, domain.ID)
+
+	monitorID, err := p.client.CreateMonitor(domain.Name, tags.MonitorName(displayName), tags,
+		s.MonitorRegions(domain.Regions()...), domain.Interval, s.MonitorCheckSettings(domain.ID))
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := p.store(domain.ID, monitorID); err != nil {
+		// Clean up created monitor if database update failed
+		if delErr := s.deleteProviderMonitor(domain.UserID, domain.ID, p.name, monitorID); delErr != nil {
+			s.logger.Error("Failed to delete orphaned monitor", "provider", p.name, "monitor_id", monitorID, "error", delErr)
+		}
+		return "", fmt.Errorf("failed to store monitor ID: %w", err)
+	}
+	return monitorID, nil
+}
+
+// applyProviderSelection creates the monitors of a domain on its selected providers and
+// deletes them from the providers no longer selected
+func (s *DomainService) applyProviderSelection(domain model.Domain) {
+	if domain.ArchivedAt != nil || domain.VerificationPending() {
+		return
+	}
+	selected, err := s.SelectedProviders(domain.UserID, domain.ID)
+	if err != nil {
+		s.logger.Error("Failed to apply provider selection", "domain_id", domain.ID, "error", err)
+		return
+	}
+	wanted := make(map[string]bool, len(selected))
+	for _, name := range selected {
+		wanted[name] = true
+	}
+
+	for _, p := range s.monitorProviders() {
+		id := p.monitorID(domain)
+		switch {
+		case wanted[p.name] && id == "":
+			s.EnsureMonitor(domain, p.name)
+		case !wanted[p.name] && id != "":
+			if err := s.deleteProviderMonitor(domain.UserID, domain.ID, p.name, id); err != nil {
+				// The deletion is retried in the background
+				s.logger.Error("Failed to delete deselected monitor", "provider", p.name, "domain_id", domain.ID, "monitor_id", id, "error", err)
+			}
+			if _, err := p.store(domain.ID, ""); err != nil {
+				s.logger.Error("Failed to clear monitor", "provider", p.name, "domain_id", domain.ID, "error", err)
+			}
+		}
+	}
+	s.events.Publish(events.Event{Type: events.DomainUpdated, UserID: domain.UserID, DomainID: domain.ID})
+}
+
+// GetDomainProviders returns the provider selection of a domain owned by the user
+func (s *DomainService) GetDomainProviders(userID, domainID int) (*model.ProviderSelection, error) {
+	if _, err := s.GetDomain(domainID, userID); err != nil {
+		return nil, err
+	}
+
+	var own pq.StringArray
+	err := s.db.Get(&own, "SELECT providers FROM domain_monitor_providers WHERE domain_id = $1", domainID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get domain providers: %w", err)
+	}
+
+	providers, err := s.SelectedProviders(userID, domainID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.ProviderSelection{
+		Providers: providers,
+		Available: s.MonitorProviderNames(),
+		Inherited: len(own) == 0,
+	}, nil
+}
+
+// SetDomainProviders replaces the provider selection of a domain owned by the user. The
+// domain's monitors are created and deleted to match in the background.
+func (s *DomainService) SetDomainProviders(userID, domainID int, providers []string) (*model.ProviderSelection, error) {
+	domain, err := s.GetDomain(domainID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateProviders(providers); err != nil {
+		return nil, err
+	}
+
+	if len(providers) == 0 {
+		_, err = s.db.Exec("DELETE FROM domain_monitor_providers WHERE domain_id = $1", domainID)
+	} else {
+		_, err = s.db.Exec(`
+            INSERT INTO domain_monitor_providers (domain_id, providers, updated_at)
+            VALUES ($1, $2, NOW())
+            ON CONFLICT (domain_id) DO UPDATE SET providers = $2, updated_at = NOW()
+        `, domainID, pq.Array(providers))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save domain providers: %w", err)
+	}
+
+	s.goAsync(func() { s.applyProviderSelection(*domain) })
+	return s.GetDomainProviders(userID, domainID)
+}
+
+// GetUserProviders returns the default provider selection of a user's domains
+func (s *DomainService) GetUserProviders(userID int) (*model.ProviderSelection, error) {
+	var own pq.StringArray
+	err := s.db.Get(&own, "SELECT providers FROM user_monitor_providers WHERE user_id = $1", userID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get user providers: %w", err)
+	}
+	return &model.ProviderSelection{
+		Providers: s.filterProviders(own),
+		Available: s.MonitorProviderNames(),
+		Inherited: len(own) == 0,
+	}, nil
+}
+
+// SetUserProviders replaces the default provider selection of a user's domains. Domains
+// without a selection of their own are updated to match in the background.
+func (s *DomainService) SetUserProviders(userID int, providers []string) (*model.ProviderSelection, error) {
+	if err := s.validateProviders(providers); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if len(providers) == 0 {
+		_, err = s.db.Exec("DELETE FROM user_monitor_providers WHERE user_id = $1", userID)
+	} else {
+		_, err = s.db.Exec(`
+            INSERT INTO user_monitor_providers (user_id, providers, updated_at)
+            VALUES ($1, $2, NOW())
+            ON CONFLICT (user_id) DO UPDATE SET providers = $2, updated_at = NOW()
+        `, userID, pq.Array(providers))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save user providers: %w", err)
+	}
+
+	var domainIDs []int
+	err = s.db.Select(&domainIDs, `
+        SELECT id FROM domains d
+        WHERE d.user_id = $1 AND d.archived_at IS NULL AND d.deleted_at IS NULL
+          AND NOT EXISTS (SELECT 1 FROM domain_monitor_providers p WHERE p.domain_id = d.id)
+    `, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get domains to update: %w", err)
+	}
+	s.goAsync(func() {
+		for _, domainID := range domainIDs {
+			domain, err := s.GetDomain(domainID, userID)
+			if err != nil {
+				if !errors.Is(err, ErrDomainNotFound) {
+					s.logger.Error("Failed to apply provider selection", "domain_id", domainID, "error", err)
+				}
+				continue
+			}
+			s.applyProviderSelection(*domain)
+		}
+	})
+
+	return s.GetUserProviders(userID)
+}
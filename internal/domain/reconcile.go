@@ -0,0 +1,299 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"domain-detection-go/pkg/model"
+)
+
+// MonitorLister is implemented by monitor clients that can list every monitor on the account
+type MonitorLister interface {
+	ListMonitors() ([]model.ProviderMonitor, error)
+}
+
+// reconcileGracePeriod leaves new domains alone while their monitors are still being
+// created in the background
+const reconcileGracePeriod = 15 * time.Minute
+
+// ReconcileMonitors compares the monitors on every provider with the domains that
+// reference them. Orphaned monitors of this deployment are deleted, and domains missing
+// a monitor on a selected provider, or referencing one the provider no longer has, get
+// a new one. A dry run only records the drift.
+func (s *DomainService) ReconcileMonitors(dryRun bool) (*model.MonitorReconciliation, error) {
+	run := &model.MonitorReconciliation{DryRun: dryRun, Drift: []model.MonitorDrift{}}
+	err := s.db.QueryRow(`
+        INSERT INTO monitor_reconciliation_runs (dry_run, started_at)
+        VALUES ($1, NOW())
+        RETURNING id, started_at
+    `, dryRun).Scan(&run.ID, &run.StartedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start reconciliation run: %w", err)
+	}
+
+	selections := make(map[int]map[string]bool)
+	var runErrors []string
+	for _, p := range s.monitorProviders() {
+		if err := s.reconcileProvider(run, p, selections); err != nil {
+			s.logger.Error("Failed to reconcile provider", "provider", p.name, "error", err)
+			runErrors = append(runErrors, fmt.Sprintf("%s: %v", p.name, err))
+		}
+	}
+
+	if len(runErrors) > 0 {
+		msg := strings.Join(runErrors, "; ")
+		run.Error = &msg
+	}
+	err = s.db.QueryRow(`
+        UPDATE monitor_reconciliation_runs
+        SET monitors_listed = $1, orphaned = $2, missing = $3, stale = $4, fixed = $5, failed = $6,
+            error = $7, finished_at = NOW()
+        WHERE id = $8
+        RETURNING finished_at
+    `, run.MonitorsListed, run.Orphaned, run.Missing, run.Stale, run.Fixed, run.Failed,
+		run.Error, run.ID).Scan(&run.FinishedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to finish reconciliation run: %w", err)
+	}
+
+	s.logger.Info("Reconciliation finished", "run_id", run.ID, "dry_run", run.DryRun, "monitors", run.MonitorsListed,
+		"orphaned", run.Orphaned, "missing", run.Missing, "stale", run.Stale, "fixed", run.Fixed, "failed", run.Failed)
+	return run, nil
+}
+
+// reconcileProvider records and resolves the drift on one provider. Providers whose
+// client cannot list monitors are skipped.
+func (s *DomainService) reconcileProvider(run *model.MonitorReconciliation, p monitorProvider, selections map[int]map[string]bool) error {
+	lister, ok := p.client.(MonitorLister)
+	if !ok {
+		return nil
+	}
+	monitors, err := lister.ListMonitors()
+	if err != nil {
+		return fmt.Errorf("failed to list monitors: %w", err)
+	}
+	run.MonitorsListed += len(monitors)
+
+	// Domains are loaded after listing so monitors created in between are not orphaned
+	var domains []model.Domain
+	err = s.db.Select(&domains, `
+        SELECT id, user_id, name, active, interval, region, monitor_guid, site24x7_monitor_id,
+               created_at, updated_at, archived_at
+        FROM domains
+    `)
+	if err != nil {
+		return fmt.Errorf("failed to get domains: %w", err)
+	}
+
+	listed := make(map[string]bool, len(monitors))
+	for _, m := range monitors {
+		listed[m.ID] = true
+	}
+	referenced := make(map[string]bool)
+	created := make(map[int]time.Time, len(domains))
+	for _, d := range domains {
+		if id := p.monitorID(d); id != "" {
+			referenced[id] = true
+		}
+		created[d.ID] = d.CreatedAt
+	}
+
+	// An empty listing is more likely an API problem than every monitor being gone
+	if len(monitors) == 0 && len(referenced) > 0 {
+		return errors.New("provider listed no monitors, skipping")
+	}
+
+	for _, m := range monitors {
+		if referenced[m.ID] {
+			continue
+		}
+		tags, ok := model.ParseMonitorName(m.Name)
+		if !ok || tags.Environment != s.environment {
+			continue // Not created by this deployment
+		}
+		if createdAt, ok := created[tags.DomainID]; ok && time.Since(createdAt) < reconcileGracePeriod {
+			continue
+		}
+		if inUse, err := s.monitorReferenced(m.ID); err != nil || inUse {
+			continue
+		}
+
+		drift := model.MonitorDrift{Provider: p.name, Kind: model.DriftOrphaned, MonitorID: &m.ID, MonitorName: &m.Name}
+		if _, ok := created[tags.DomainID]; ok {
+			drift.DomainID = &tags.DomainID
+		}
+		s.resolveDrift(run, drift, "deleted", func() error {
+			return p.client.DeleteMonitor(m.ID)
+		})
+	}
+
+	for _, d := range domains {
+		if d.ArchivedAt != nil || !d.Active || time.Since(d.CreatedAt) < reconcileGracePeriod {
+			continue
+		}
+		selected, ok := selections[d.ID]
+		if !ok {
+			providers, err := s.SelectedProviders(d.UserID, d.ID)
+			if err != nil {
+				s.logger.Error("Failed to get provider selection", "domain_id", d.ID, "error", err)
+			}
+			selected = make(map[string]bool, len(providers))
+			for _, name := range providers {
+				selected[name] = true
+			}
+			selections[d.ID] = selected
+		}
+		if !selected[p.name] {
+			continue
+		}
+
+		id := p.monitorID(d)
+		drift := model.MonitorDrift{Provider: p.name, DomainID: &d.ID}
+		switch {
+		case id == "":
+			if s.monitorCreationPending(d.ID, p.name) {
+				continue // Already being retried
+			}
+			drift.Kind = model.DriftMissing
+		case !listed[id]:
+			drift.Kind = model.DriftStale
+			drift.MonitorID = &id
+		default:
+			continue
+		}
+		s.resolveDrift(run, drift, "recreated", func() error {
+			return s.replaceMonitor(d, p, id)
+		})
+	}
+
+	return nil
+}
+
+// monitorReferenced reports whether any domain currently references a monitor ID
+func (s *DomainService) monitorReferenced(monitorID string) (bool, error) {
+	var exists bool
+	err := s.db.Get(&exists, `
+        SELECT EXISTS (SELECT 1 FROM domains WHERE monitor_guid = $1 OR site24x7_monitor_id = $1)
+    `, monitorID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check monitor references: %w", err)
+	}
+	return exists, nil
+}
+
+// replaceMonitor creates the monitor of a domain on a provider, clearing the stale
+// monitor ID it referenced first
+func (s *DomainService) replaceMonitor(d model.Domain, p monitorProvider, staleID string) error {
+	if staleID != "" {
+		if _, err := p.store(d.ID, ""); err != nil {
+			return err
+		}
+	}
+
+	domain, err := s.GetDomain(d.ID, d.UserID)
+	if err != nil {
+		return err
+	}
+	if s.EnsureMonitor(*domain, p.name) == "" {
+		return errors.New("monitor could not be created")
+	}
+	return nil
+}
+
+// resolveDrift counts and records a drift, fixing it unless the run is a dry run
+func (s *DomainService) resolveDrift(run *model.MonitorReconciliation, drift model.MonitorDrift, action string, fix func() error) {
+	switch drift.Kind {
+	case model.DriftOrphaned:
+		run.Orphaned++
+	case model.DriftMissing:
+		run.Missing++
+	case model.DriftStale:
+		run.Stale++
+	}
+
+	drift.RunID = run.ID
+	drift.Action = "none"
+	if !run.DryRun {
+		if err := fix(); err != nil {
+			s.logger.Error("Failed to fix monitor drift", "provider", drift.Provider, "kind", drift.Kind, "error", err)
+			msg := err.Error()
+			drift.Action = "failed"
+			drift.Error = &msg
+			run.Failed++
+		} else {
+			drift.Action = action
+			run.Fixed++
+		}
+	}
+
+	err := s.db.QueryRow(`
+        INSERT INTO monitor_drift (run_id, provider, kind, monitor_id, monitor_name, domain_id, action, error, detected_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+        RETURNING id, detected_at
+    `, drift.RunID, drift.Provider, drift.Kind, drift.MonitorID, drift.MonitorName, drift.DomainID,
+		drift.Action, drift.Error).Scan(&drift.ID, &drift.DetectedAt)
+	if err != nil {
+		s.logger.Error("Failed to record monitor drift", "error", err)
+	}
+	run.Drift = append(run.Drift, drift)
+}
+
+// GetMonitorDriftReport returns the most recent reconciliation runs, with the drift
+// found by the latest one
+func (s *DomainService) GetMonitorDriftReport(limit int) (*model.MonitorDriftReport, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+
+	report := &model.MonitorDriftReport{Runs: []model.MonitorReconciliation{}}
+	err := s.db.Select(&report.Runs, `
+        SELECT id, dry_run, monitors_listed, orphaned, missing, stale, fixed, failed, error,
+               started_at, finished_at
+        FROM monitor_reconciliation_runs
+        ORDER BY started_at DESC
+        LIMIT $1
+    `, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reconciliation runs: %w", err)
+	}
+	if len(report.Runs) == 0 {
+		return report, nil
+	}
+
+	latest := report.Runs[0]
+	latest.Drift = []model.MonitorDrift{}
+	err = s.db.Select(&latest.Drift, `
+        SELECT id, run_id, provider, kind, monitor_id, monitor_name, domain_id, action, error, detected_at
+        FROM monitor_drift
+        WHERE run_id = $1
+        ORDER BY id
+    `, latest.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get monitor drift: %w", err)
+	}
+	report.Latest = &latest
+
+	return report, nil
+}
+
+// RunScheduledReconciliation reconciles provider monitors with domains every 6 hours
+func (s *DomainService) RunScheduledReconciliation(ctx context.Context) {
+	s.logger.Info("RunScheduledReconciliation")
+	ticker := time.NewTicker(6 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("RunScheduledReconciliation stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.ReconcileMonitors(false); err != nil {
+				s.logger.Error("Reconciliation failed", "error", err)
+			}
+		}
+	}
+}
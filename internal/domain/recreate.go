@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"net/url"
+	"sync"
+
+	"domain-detection-go/internal/audit"
+	"domain-detection-go/internal/events"
+	"domain-detection-go/pkg/model"
+)
+
+// recreationQueue runs monitor recreations one at a time so a burst of requests cannot
+// flood the provider APIs, and refuses a second recreation of a domain already queued
+type recreationQueue struct {
+	mu      sync.Mutex
+	pending map[int]bool
+	run     sync.Mutex
+}
+
+func newRecreationQueue() *recreationQueue {
+	return &recreationQueue{pending: make(map[int]bool)}
+}
+
+// do runs job for the domain once the jobs queued before it have finished
+func (q *recreationQueue) do(domainID int, job func()) error {
+	q.mu.Lock()
+	if q.pending[domainID] {
+		q.mu.Unlock()
+		return ErrRecreationInProgress
+	}
+	q.pending[domainID] = true
+	q.mu.Unlock()
+
+	defer func() {
+		q.mu.Lock()
+		delete(q.pending, domainID)
+		q.mu.Unlock()
+	}()
+
+	q.run.Lock()
+	defer q.run.Unlock()
+	job()
+	return nil
+}
+
+// RecreateMonitors deletes and recreates the provider monitors of a domain on the given
+// providers, or on the providers selected for the domain when none are given. Each provider is
+// handled independently, so a failure on one does not stop the others. The action is
+// recorded in the audit log.
+func (s *DomainService) RecreateMonitors(userID, domainID int, providers []string) (*model.MonitorRecreateResponse, error) {
+	domain, err := s.GetDomain(domainID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if domain.ArchivedAt != nil {
+		return nil, ErrDomainArchived
+	}
+	if domain.VerificationPending() {
+		return nil, ErrDomainNotVerified
+	}
+
+	if len(providers) == 0 {
+		providers, err = s.SelectedProviders(userID, domainID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	targets := make([]monitorProvider, 0, len(providers))
+	for _, provider := range providers {
+		p, ok := s.monitorProvider(provider)
+		if !ok {
+			return nil, ErrProviderNotConfigured
+		}
+		targets = append(targets, p)
+	}
+
+	response := &model.MonitorRecreateResponse{DomainID: domainID, Results: []model.MonitorRecreateResult{}}
+	err = s.recreations.do(domainID, func() {
+		for _, p := range targets {
+			response.Results = append(response.Results, s.recreateMonitor(*domain, p))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.audit.Record(userID, domainID, audit.ActionMonitorsRecreated, response); err != nil {
+		s.logger.Error("Failed to record monitor recreation", "domain_id", domainID, "error", err)
+	}
+	s.events.Publish(events.Event{Type: events.DomainUpdated, UserID: userID, DomainID: domainID})
+
+	return response, nil
+}
+
+// recreateMonitor replaces the monitor of a domain on a single provider. The old monitor
+// is deleted on a best-effort basis, since a wedged monitor may no longer be deletable.
+func (s *DomainService) recreateMonitor(domain model.Domain, p monitorProvider) model.MonitorRecreateResult {
+	provider, client := p.name, p.client
+	result := model.MonitorRecreateResult{Provider: provider, OldID: p.monitorID(domain)}
+
+	if result.OldID != "" {
+		if err := client.DeleteMonitor(result.OldID); err != nil {
+			s.logger.Error("Failed to delete monitor", "provider", provider, "domain_id", domain.ID, "monitor_id", result.OldID, "error", err)
+		}
+	}
+
+	parsedURL, err := url.Parse(domain.Name)
+	if err != nil {
+		result.Error = "invalid domain URL"
+		return result
+	}
+	tags := s.MonitorTags(domain.UserID, domain.ID)
+
+	settings := s.MonitorCheckSettings(domain.ID)
+
+	newID, err := client.CreateMonitor(domain.Name, tags.MonitorName(parsedURL.Hostname()), tags, s.MonitorRegions(domain.Regions()...), domain.Interval, settings)
+	if err != nil {
+		s.logger.Error("Failed to recreate monitor", "provider", provider, "domain_id", domain.ID, "error", err)
+		result.Error = err.Error()
+		s.enqueueMonitorTask(domain.UserID, domain.ID, provider, model.MonitorTaskCreate, "", err)
+	}
+
+	// Store the new ID, or clear the deleted one when creation failed
+	if _, err := p.store(domain.ID, newID); err != nil {
+		s.logger.Error("Failed to store recreated monitor", "provider", provider, "domain_id", domain.ID, "error", err)
+		if newID != "" {
+			if delErr := s.deleteProviderMonitor(domain.UserID, domain.ID, provider, newID); delErr != nil {
+				s.logger.Error("Failed to delete orphaned monitor", "provider", provider, "monitor_id", newID, "error", delErr)
+			}
+		}
+		result.Error = "failed to store monitor ID"
+		return result
+	}
+
+	result.NewID = newID
+	result.Recreated = newID != ""
+	return result
+}
@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+
+	"domain-detection-go/pkg/model"
+
+	"github.com/lib/pq"
+)
+
+// MonitorRegions returns the regions of a domain followed by their fallback regions.
+// If the fallbacks cannot be loaded the domain is monitored from its own region only.
+func (s *DomainService) MonitorRegions(domainRegions ...string) []string {
+	regions := append([]string{}, domainRegions...)
+
+	var fallbacks []string
+	err := s.db.Select(&fallbacks, `
+        SELECT fallback_code FROM region_fallbacks
+        WHERE region_code = ANY($1)
+        ORDER BY array_position($1, region_code), position, fallback_code
+    `, pq.Array(domainRegions))
+	if err != nil {
+		s.logger.Warn("Failed to get fallback regions", "regions", domainRegions, "error", err)
+		return regions
+	}
+
+	seen := make(map[string]bool, len(regions))
+	for _, region := range regions {
+		seen[region] = true
+	}
+	for _, fallback := range fallbacks {
+		if !seen[fallback] {
+			seen[fallback] = true
+			regions = append(regions, fallback)
+		}
+	}
+	return regions
+}
+
+// GetRegionFallbacks returns the fallback regions of every region that has any
+func (s *DomainService) GetRegionFallbacks() ([]model.RegionFallbacks, error) {
+	var rows []struct {
+		Region   string `db:"region_code"`
+		Fallback string `db:"fallback_code"`
+	}
+	err := s.db.Select(&rows, `
+        SELECT region_code, fallback_code FROM region_fallbacks
+        ORDER BY region_code, position, fallback_code
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get region fallbacks: %w", err)
+	}
+
+	result := []model.RegionFallbacks{}
+	for _, row := range rows {
+		if len(result) == 0 || result[len(result)-1].Region != row.Region {
+			result = append(result, model.RegionFallbacks{Region: row.Region, Fallbacks: []string{}})
+		}
+		last := &result[len(result)-1]
+		last.Fallbacks = append(last.Fallbacks, row.Fallback)
+	}
+	return result, nil
+}
+
+// UpdateRegionFallbacks replaces the fallback regions of a region. Existing monitors keep
+// their regions until they are recreated.
+func (s *DomainService) UpdateRegionFallbacks(region string, fallbacks []string) (*model.RegionFallbacks, error) {
+	region = strings.ToUpper(strings.TrimSpace(region))
+	if err := s.checkRegion(region); err != nil {
+		return nil, ErrInvalidRegion
+	}
+
+	seen := map[string]bool{region: true}
+	codes := make([]string, 0, len(fallbacks))
+	for _, fallback := range fallbacks {
+		code := strings.ToUpper(strings.TrimSpace(fallback))
+		if seen[code] {
+			return nil, fmt.Errorf("invalid fallback region %q: duplicate or same as region", fallback)
+		}
+		if err := s.checkRegion(code); err != nil {
+			return nil, fmt.Errorf("invalid fallback region %q", fallback)
+		}
+		seen[code] = true
+		codes = append(codes, code)
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM region_fallbacks WHERE region_code = $1", region); err != nil {
+		return nil, fmt.Errorf("failed to clear region fallbacks: %w", err)
+	}
+	for i, code := range codes {
+		_, err := tx.Exec("INSERT INTO region_fallbacks (region_code, fallback_code, position) VALUES ($1, $2, $3)", region, code, i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save region fallback: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit region fallbacks: %w", err)
+	}
+
+	return &model.RegionFallbacks{Region: region, Fallbacks: codes}, nil
+}
+
+// checkRegion returns an error unless the region exists
+func (s *DomainService) checkRegion(code string) error {
+	var exists bool
+	if err := s.db.Get(&exists, "SELECT EXISTS(SELECT 1 FROM regions WHERE code = $1)", code); err != nil {
+		return fmt.Errorf("error verifying region: %w", err)
+	}
+	if !exists {
+		return ErrInvalidRegion
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"fmt"
+
+	"domain-detection-go/pkg/model"
+
+	"github.com/lib/pq"
+)
+
+// ChecksFromFallbackRegions reports whether a provider's monitors also check from the
+// fallback regions of a domain. Other providers only check from the primary region.
+func (s *DomainService) ChecksFromFallbackRegions(provider string) bool {
+	p, ok := s.monitorProvider(provider)
+	return ok && p.multiRegion
+}
+
+// RecordRegionStatuses stores the latest per-provider, per-region results of a domain.
+// A provider that did not answer keeps its previous results; results of providers and
+// regions the domain is no longer checked by or from are removed.
+func (s *DomainService) RecordRegionStatuses(domainID int, providers, regions []string, statuses []model.DomainRegionStatus) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+        DELETE FROM domain_region_status
+        WHERE domain_id = $1 AND (NOT provider = ANY($2) OR NOT region = ANY($3))
+    `, domainID, pq.Array(providers), pq.Array(regions))
+	if err != nil {
+		return fmt.Errorf("failed to clear region statuses: %w", err)
+	}
+	for _, status := range statuses {
+		_, err := tx.Exec(`
+            INSERT INTO domain_region_status
+            (domain_id, provider, region, available, status_code, error_code, error_description, total_time, checked_at)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+            ON CONFLICT (domain_id, provider, region) DO UPDATE
+            SET available = EXCLUDED.available, status_code = EXCLUDED.status_code,
+                error_code = EXCLUDED.error_code, error_description = EXCLUDED.error_description,
+                total_time = EXCLUDED.total_time, checked_at = EXCLUDED.checked_at
+        `, domainID, status.Provider, status.Region, status.Available, status.StatusCode, status.ErrorCode,
+			status.ErrorDescription, status.TotalTime)
+		if err != nil {
+			return fmt.Errorf("failed to record region status: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit region statuses: %w", err)
+	}
+	return nil
+}
+
+// GetRegionStatuses returns the latest per-provider, per-region results of a domain
+func (s *DomainService) GetRegionStatuses(domainID int) ([]model.DomainRegionStatus, error) {
+	statuses := []model.DomainRegionStatus{}
+	err := s.db.Select(&statuses, `
+        SELECT domain_id, provider, region, available, status_code, error_code, error_description,
+               total_time, checked_at
+        FROM domain_region_status
+        WHERE domain_id = $1
+        ORDER BY region, provider
+    `, domainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get region statuses: %w", err)
+	}
+	return statuses, nil
+}
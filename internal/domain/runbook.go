@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"database/sql"
+	"fmt"
+
+	"domain-detection-go/pkg/model"
+)
+
+// GetRunbooks returns every runbook of a user, domain runbooks first
+func (s *DomainService) GetRunbooks(userID int) ([]model.Runbook, error) {
+	runbooks := []model.Runbook{}
+	err := s.db.Select(&runbooks, `
+        SELECT id, user_id, domain_id, region_code, runbook_url, notes, updated_at
+        FROM runbooks
+        WHERE user_id = $1
+        ORDER BY domain_id NULLS LAST, region_code
+    `, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get runbooks: %w", err)
+	}
+	return runbooks, nil
+}
+
+// GetDomainRunbook returns the runbook attached directly to a domain, or nil if none exists
+func (s *DomainService) GetDomainRunbook(userID, domainID int) (*model.Runbook, error) {
+	return s.getRunbook("domain_id = $2", userID, domainID)
+}
+
+// GetRegionRunbook returns the runbook of a region, or nil if none exists
+func (s *DomainService) GetRegionRunbook(userID int, region string) (*model.Runbook, error) {
+	return s.getRunbook("region_code = $2", userID, region)
+}
+
+// ResolveRunbook returns the runbook that applies to a domain: its own runbook, or else the
+// runbook of its region. Nil is returned when neither exists.
+func (s *DomainService) ResolveRunbook(domain model.Domain) (*model.Runbook, error) {
+	runbook, err := s.GetDomainRunbook(domain.UserID, domain.ID)
+	if err != nil || runbook != nil {
+		return runbook, err
+	}
+	return s.GetRegionRunbook(domain.UserID, domain.Region)
+}
+
+// SetDomainRunbook attaches a runbook to a domain owned by the user, replacing any previous one
+func (s *DomainService) SetDomainRunbook(userID, domainID int, req model.RunbookRequest) error {
+	if _, err := s.GetDomain(domainID, userID); err != nil {
+		return err
+	}
+	if req.URL == "" && req.Notes == "" {
+		return ErrRunbookEmpty
+	}
+
+	_, err := s.db.Exec(`
+        INSERT INTO runbooks (user_id, domain_id, runbook_url, notes, updated_at)
+        VALUES ($1, $2, $3, $4, NOW())
+        ON CONFLICT (domain_id) WHERE domain_id IS NOT NULL
+        DO UPDATE SET runbook_url = EXCLUDED.runbook_url, notes = EXCLUDED.notes, updated_at = NOW()
+    `, userID, domainID, req.URL, req.Notes)
+	if err != nil {
+		return fmt.Errorf("failed to set domain runbook: %w", err)
+	}
+	return nil
+}
+
+// SetRegionRunbook sets the runbook used by every domain of the user in a region that has
+// no runbook of its own
+func (s *DomainService) SetRegionRunbook(userID int, region string, req model.RunbookRequest) error {
+	var isValidRegion bool
+	err := s.db.Get(&isValidRegion, "SELECT EXISTS(SELECT 1 FROM regions WHERE code = $1)", region)
+	if err != nil {
+		return fmt.Errorf("error verifying region: %w", err)
+	}
+	if !isValidRegion {
+		return ErrInvalidRegion
+	}
+	if req.URL == "" && req.Notes == "" {
+		return ErrRunbookEmpty
+	}
+
+	_, err = s.db.Exec(`
+        INSERT INTO runbooks (user_id, region_code, runbook_url, notes, updated_at)
+        VALUES ($1, $2, $3, $4, NOW())
+        ON CONFLICT (user_id, region_code) WHERE region_code IS NOT NULL
+        DO UPDATE SET runbook_url = EXCLUDED.runbook_url, notes = EXCLUDED.notes, updated_at = NOW()
+    `, userID, region, req.URL, req.Notes)
+	if err != nil {
+		return fmt.Errorf("failed to set region runbook: %w", err)
+	}
+	return nil
+}
+
+// DeleteDomainRunbook removes the runbook attached to a domain
+func (s *DomainService) DeleteDomainRunbook(userID, domainID int) error {
+	return s.deleteRunbook("domain_id = $2", userID, domainID)
+}
+
+// DeleteRegionRunbook removes the runbook of a region
+func (s *DomainService) DeleteRegionRunbook(userID int, region string) error {
+	return s.deleteRunbook("region_code = $2", userID, region)
+}
+
+func (s *DomainService) getRunbook(scope string, userID int, key interface{}) (*model.Runbook, error) {
+	var runbook model.Runbook
+	err := s.db.Get(&runbook, `
+        SELECT id, user_id, domain_id, region_code, runbook_url, notes, updated_at
+        FROM runbooks
+        WHERE user_id = $1 AND `+scope, userID, key)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get runbook: %w", err)
+	}
+	return &runbook, nil
+}
+
+func (s *DomainService) deleteRunbook(scope string, userID int, key interface{}) error {
+	var id int
+	err := s.db.Get(&id, "DELETE FROM runbooks WHERE user_id = $1 AND "+scope+" RETURNING id", userID, key)
+	if err == sql.ErrNoRows {
+		return ErrRunbookNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete runbook: %w", err)
+	}
+	return nil
+}
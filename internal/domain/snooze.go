@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"domain-detection-go/internal/events"
+	"domain-detection-go/pkg/model"
+)
+
+// maxSnoozeDuration bounds a snooze so a forgotten one cannot hide outages for good
+const maxSnoozeDuration = 7 * 24 * time.Hour
+
+// SnoozeDomain silences the alerts of a domain for a while. Unlike deactivating the
+// domain, it keeps being checked and its history and incidents keep being recorded.
+func (s *DomainService) SnoozeDomain(userID, domainID int, duration time.Duration) (*model.DomainSnooze, error) {
+	if duration <= 0 || duration > maxSnoozeDuration {
+		return nil, ErrInvalidSnoozeDuration
+	}
+
+	var snooze model.DomainSnooze
+	err := s.db.Get(&snooze, `
+        UPDATE domains SET snoozed_until = NOW() + make_interval(secs => $3)
+        WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+        RETURNING id AS domain_id, name, snoozed_until
+    `, domainID, userID, duration.Seconds())
+	if err == sql.ErrNoRows {
+		return nil, ErrDomainNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to snooze domain: %w", err)
+	}
+
+	s.events.Publish(events.Event{Type: events.DomainUpdated, UserID: userID, DomainID: domainID})
+	return &snooze, nil
+}
+
+// UnsnoozeDomain lets the alerts of a snoozed domain through again
+func (s *DomainService) UnsnoozeDomain(userID, domainID int) error {
+	result, err := s.db.Exec(`
+        UPDATE domains SET snoozed_until = NULL
+        WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+    `, domainID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to unsnooze domain: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrDomainNotFound
+	}
+
+	s.events.Publish(events.Event{Type: events.DomainUpdated, UserID: userID, DomainID: domainID})
+	return nil
+}
@@ -0,0 +1,204 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"domain-detection-go/pkg/model"
+)
+
+const (
+	// ctLogURL searches certificate transparency logs, see https://crt.sh
+	ctLogURL = "https://crt.sh/"
+	// maxDiscoveredSubdomains bounds how many subdomains one discovery adds
+	maxDiscoveredSubdomains = 100
+)
+
+// ctLogClient queries certificate transparency logs; crt.sh can be slow for large domains
+var ctLogClient = &http.Client{Timeout: 60 * time.Second}
+
+// DiscoverSubdomains finds the public subdomains of a base domain, from certificate
+// transparency logs or a list given by the user, and monitors the ones not monitored
+// yet in the regions and interval of the base domain, grouped under it
+func (s *DomainService) DiscoverSubdomains(ctx context.Context, userID, parentID int, req model.SubdomainDiscoveryRequest) (*model.SubdomainDiscoveryResponse, error) {
+	parent, err := s.GetDomain(parentID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if parent.ParentID != nil {
+		return nil, ErrDomainIsSubdomain
+	}
+
+	base := domainHost(parent.Name)
+	var names []string
+	switch req.Source {
+	case model.SubdomainSourceCT:
+		if names, err = fetchCTSubdomains(ctx, base); err != nil {
+			return nil, err
+		}
+	case model.SubdomainSourceList:
+		names = req.Subdomains
+	default:
+		return nil, errors.New("invalid subdomain source")
+	}
+
+	discovered := filterSubdomains(names, base)
+	response := &model.SubdomainDiscoveryResponse{Discovered: discovered}
+
+	batch := model.DomainBatchAddRequest{Interval: parent.Interval}
+	for _, name := range discovered {
+		batch.Domains = append(batch.Domains, model.DomainBatchItem{
+			Name:         "https://" + name,
+			Region:       parent.Region,
+			IsDeepCheck:  parent.IsDeepCheck,
+			ExtraRegions: parent.ExtraRegions,
+		})
+	}
+	response.DomainBatchAddResponse = s.AddBatchDomains(ctx, userID, batch)
+
+	for _, added := range response.Success {
+		if _, err := s.db.Exec("UPDATE domains SET parent_id = $1 WHERE id = $2 AND user_id = $3", parentID, added.ID, userID); err != nil {
+			return nil, fmt.Errorf("failed to group subdomain %s: %w", added.Name, err)
+		}
+	}
+	return response, nil
+}
+
+// GetSubdomainStatus returns a base domain with its subdomains and their combined status
+func (s *DomainService) GetSubdomainStatus(userID, parentID int) (*model.DomainGroupStatus, error) {
+	parent, err := s.GetDomain(parentID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	group := &model.DomainGroupStatus{Parent: *parent, Subdomains: []model.Domain{}}
+	err = s.db.Select(&group.Subdomains, `
+        SELECT id, user_id, name, unicode_name, active, interval, region, extra_regions, last_status,
+               error_code, error_description, total_time, last_check, created_at, updated_at,
+               recovery_pending, failure_pending, archived_at, parent_id
+        FROM domains
+        WHERE parent_id = $1 AND user_id = $2 AND deleted_at IS NULL
+        ORDER BY name
+    `, parentID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subdomains: %w", err)
+	}
+
+	for _, d := range append([]model.Domain{*parent}, group.Subdomains...) {
+		if !d.Active {
+			continue
+		}
+		group.Total++
+		if d.ConfirmedAvailable() {
+			group.Up++
+		} else {
+			group.Down++
+		}
+	}
+	switch {
+	case group.Down == 0:
+		group.Status = model.DomainGroupUp
+	case group.Up == 0:
+		group.Status = model.DomainGroupDown
+	default:
+		group.Status = model.DomainGroupDegraded
+	}
+	return group, nil
+}
+
+// ctLogEntry is a certificate found by crt.sh; name_value holds its names, one per line
+type ctLogEntry struct {
+	NameValue string `json:"name_value"`
+}
+
+// fetchCTSubdomains returns the names of the certificates logged for a domain and its
+// subdomains
+func fetchCTSubdomains(ctx context.Context, base string) ([]string, error) {
+	query := url.Values{"q": {"%." + base}, "output": {"json"}}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, ctLogURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate log request: %w", err)
+	}
+
+	resp, err := ctLogClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query certificate logs: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("certificate log search failed with status %d", resp.StatusCode)
+	}
+
+	var entries []ctLogEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("failed to decode certificate logs: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, strings.Split(entry.NameValue, "\n")...)
+	}
+	return names, nil
+}
+
+// filterSubdomains normalizes names to hosts below base, dropping wildcards, duplicates,
+// the base itself and anything outside it. Bare labels such as "www" are put in front
+// of base. At most maxDiscoveredSubdomains are kept, in name order.
+func filterSubdomains(names []string, base string) []string {
+	seen := make(map[string]bool)
+	var subdomains []string
+	for _, name := range names {
+		name = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+		name = strings.TrimPrefix(name, "*.")
+		if name == "" || strings.Contains(name, "*") {
+			continue
+		}
+		if strings.Contains(name, "://") {
+			name = domainHost(name)
+		}
+		if !strings.Contains(name, ".") {
+			name += "." + base
+		}
+		if !strings.HasSuffix(name, "."+base) || seen[name] {
+			continue
+		}
+		seen[name] = true
+		subdomains = append(subdomains, name)
+	}
+
+	sort.Strings(subdomains)
+	if len(subdomains) > maxDiscoveredSubdomains {
+		subdomains = subdomains[:maxDiscoveredSubdomains]
+	}
+	return subdomains
+}
+
+// domainHost returns the lowercase host of a domain entry, e.g. example.com for
+// https://example.com/path
+func domainHost(name string) string {
+	if parsedURL, err := url.Parse(name); err == nil && parsedURL.Hostname() != "" {
+		return strings.ToLower(parsedURL.Hostname())
+	}
+	return strings.ToLower(name)
+}
+
+// goDiscoverSubdomains discovers the subdomains of a newly added domain in the background
+func (s *DomainService) goDiscoverSubdomains(ctx context.Context, userID, domainID int) {
+	ctx = context.WithoutCancel(ctx)
+	s.goAsync(func() {
+		logger := s.logger.With("user_id", userID, "domain_id", domainID)
+		response, err := s.DiscoverSubdomains(ctx, userID, domainID, model.SubdomainDiscoveryRequest{Source: model.SubdomainSourceCT})
+		if err != nil {
+			logger.Error("Failed to discover subdomains", "error", err)
+			return
+		}
+		logger.Info("Discovered subdomains", "discovered", len(response.Discovered), "added", response.Added)
+	})
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"domain-detection-go/internal/events"
+	"domain-detection-go/pkg/model"
+)
+
+// summaryCacheTTL bounds how long a summary is served, in case a change happens without
+// an event, e.g. the domain limit set by an admin
+const summaryCacheTTL = 30 * time.Second
+
+// summaryCache holds per-user domain summaries until invalidated by an event or expired.
+// Each invalidation bumps the user's generation, so a summary computed before it is not
+// stored after it.
+type summaryCache struct {
+	mu          sync.RWMutex
+	summaries   map[int]cachedSummary
+	generations map[int]uint64
+}
+
+type cachedSummary struct {
+	summary  model.DomainSummary
+	cachedAt time.Time
+}
+
+func newSummaryCache() *summaryCache {
+	return &summaryCache{
+		summaries:   make(map[int]cachedSummary),
+		generations: make(map[int]uint64),
+	}
+}
+
+// get returns the cached summary of the user, or the generation to pass to set on a miss
+func (c *summaryCache) get(userID int) (model.DomainSummary, uint64, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	cached, ok := c.summaries[userID]
+	if ok && time.Since(cached.cachedAt) < summaryCacheTTL {
+		return cached.summary, 0, true
+	}
+	return model.DomainSummary{}, c.generations[userID], false
+}
+
+// set stores a summary computed at generation, unless the user's summary was
+// invalidated since
+func (c *summaryCache) set(userID int, generation uint64, summary model.DomainSummary) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generations[userID] != generation {
+		return
+	}
+	c.summaries[userID] = cachedSummary{summary: summary, cachedAt: time.Now()}
+}
+
+func (c *summaryCache) invalidate(userID int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generations[userID]++
+	delete(c.summaries, userID)
+}
+
+// subscribeSummaryInvalidation drops cached summaries when a user's domains change
+func (s *DomainService) subscribeSummaryInvalidation() {
+	if s.events == nil {
+		return
+	}
+	s.events.Subscribe(func(e events.Event) {
+		s.summaries.invalidate(e.UserID)
+	}, events.DomainAdded, events.DomainUpdated, events.DomainDeleted, events.DomainStatusChanged)
+}
+
+// GetDomainSummary returns the cached domain summary for a user, computing it on a miss
+func (s *DomainService) GetDomainSummary(userID int) (model.DomainSummary, error) {
+	summary, generation, ok := s.summaries.get(userID)
+	if ok {
+		return summary, nil
+	}
+
+	err := s.db.Get(&summary, `
+        SELECT COUNT(*) FILTER (WHERE archived_at IS NULL) AS total_domains,
+               COUNT(*) FILTER (WHERE active = true) AS active_domains,
+               COUNT(*) FILTER (WHERE active = true AND last_check IS NOT NULL
+                                AND (last_status < 200 OR last_status >= 400)) AS down_domains,
+               COUNT(*) FILTER (WHERE archived_at IS NOT NULL) AS archived_domains
+        FROM domains
+        WHERE user_id = $1 AND deleted_at IS NULL
+    `, userID)
+	if err != nil {
+		return model.DomainSummary{}, fmt.Errorf("failed to compute domain summary: %w", err)
+	}
+
+	limit, err := s.GetDomainLimit(userID)
+	if err != nil {
+		return model.DomainSummary{}, err
+	}
+	summary.DomainLimit = limit
+
+	s.summaries.set(userID, generation, summary)
+	return summary, nil
+}
+
+// Events returns the event bus used by the domain service
+func (s *DomainService) Events() *events.Bus {
+	return s.events
+}
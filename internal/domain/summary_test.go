@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"domain-detection-go/pkg/model"
+)
+
+func TestSummaryCacheDropsStaleSet(t *testing.T) {
+	c := newSummaryCache()
+
+	_, generation, ok := c.get(1)
+	if ok {
+		t.Fatal("empty cache reported a hit")
+	}
+	// The domains change while the summary is being computed
+	c.invalidate(1)
+	c.set(1, generation, model.DomainSummary{TotalDomains: 1})
+	if _, _, ok := c.get(1); ok {
+		t.Fatal("summary computed before an invalidation was cached")
+	}
+
+	_, generation, _ = c.get(1)
+	c.set(1, generation, model.DomainSummary{TotalDomains: 2})
+	summary, _, ok := c.get(1)
+	if !ok || summary.TotalDomains != 2 {
+		t.Fatalf("get = %+v, %v, want the fresh summary", summary, ok)
+	}
+}
+
+func TestSummaryCacheExpires(t *testing.T) {
+	c := newSummaryCache()
+	c.summaries[1] = cachedSummary{cachedAt: time.Now().Add(-summaryCacheTTL)}
+	if _, _, ok := c.get(1); ok {
+		t.Fatal("expired summary reported a hit")
+	}
+}
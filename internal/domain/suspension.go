@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"fmt"
+
+	"domain-detection-go/internal/events"
+	"domain-detection-go/pkg/model"
+)
+
+// SetUserMonitoringSuspended pauses or resumes the provider monitors of every active
+// domain owned by a user. Scheduled checks skip users with suspended_at set.
+func (s *DomainService) SetUserMonitoringSuspended(userID int, suspended bool) error {
+	if suspended {
+		_, err := s.db.Exec("UPDATE users SET suspended_at = NOW() WHERE id = $1 AND suspended_at IS NULL", userID)
+		if err != nil {
+			return fmt.Errorf("failed to suspend user: %w", err)
+		}
+	} else {
+		_, err := s.db.Exec("UPDATE users SET suspended_at = NULL WHERE id = $1", userID)
+		if err != nil {
+			return fmt.Errorf("failed to resume user: %w", err)
+		}
+	}
+
+	var domains []model.Domain
+	err := s.db.Select(&domains, `
+        SELECT id, user_id, name, active, interval, monitor_guid, site24x7_monitor_id, direct_monitor_id,
+               last_status, error_code, total_time, error_description, last_check,
+               created_at, updated_at, region, COALESCE(is_deep_check, false) AS is_deep_check
+        FROM domains
+        WHERE user_id = $1 AND active = true
+    `, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get domains for user %d: %w", userID, err)
+	}
+
+	providerActive := !suspended
+	for _, domain := range domains {
+		if domain.GetMonitorGuid() != "" && s.uptrendsClient != nil {
+			if err := s.uptrendsClient.UpdateMonitorStatus(domain.GetMonitorGuid(), providerActive); err != nil {
+				s.logger.Error("Failed to update monitor status", "provider", model.ProviderUptrends, "domain_id", domain.ID, "error", err)
+			}
+		}
+		if domain.GetSite24x7MonitorID() != "" && s.site24x7Client != nil {
+			if err := s.site24x7Client.UpdateMonitorStatus(domain.GetSite24x7MonitorID(), providerActive); err != nil {
+				s.logger.Error("Failed to update monitor status", "provider", model.ProviderSite24x7, "domain_id", domain.ID, "error", err)
+			}
+		}
+		s.setDirectMonitorStatus(domain, providerActive)
+	}
+
+	s.events.Publish(events.Event{Type: events.DomainUpdated, UserID: userID})
+	return nil
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"fmt"
+
+	"domain-detection-go/internal/events"
+	"domain-detection-go/pkg/model"
+)
+
+// GetDomainTags returns the tags of a domain owned by the user
+func (s *DomainService) GetDomainTags(userID, domainID int) ([]string, error) {
+	if _, err := s.GetDomain(domainID, userID); err != nil {
+		return nil, err
+	}
+
+	tags := []string{}
+	err := s.db.Select(&tags, "SELECT tag FROM domain_tags WHERE domain_id = $1 ORDER BY tag", domainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get domain tags: %w", err)
+	}
+	return tags, nil
+}
+
+// SetDomainTags replaces the tags of a domain owned by the user
+func (s *DomainService) SetDomainTags(userID, domainID int, tags []string) ([]string, error) {
+	if _, err := s.GetDomain(domainID, userID); err != nil {
+		return nil, err
+	}
+
+	tags, err := model.NormalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM domain_tags WHERE domain_id = $1", domainID); err != nil {
+		return nil, fmt.Errorf("failed to clear domain tags: %w", err)
+	}
+	for _, tag := range tags {
+		if _, err := tx.Exec("INSERT INTO domain_tags (domain_id, tag) VALUES ($1, $2)", domainID, tag); err != nil {
+			return nil, fmt.Errorf("failed to add tag %s: %w", tag, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.events.Publish(events.Event{Type: events.DomainUpdated, UserID: userID, DomainID: domainID})
+	return tags, nil
+}
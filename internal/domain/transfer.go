@@ -0,0 +1,158 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"domain-detection-go/internal/audit"
+	"domain-detection-go/internal/events"
+	"domain-detection-go/internal/logging"
+	"domain-detection-go/pkg/model"
+
+	"github.com/lib/pq"
+)
+
+// TransferDomains moves domains from one user to another in a single transaction. The
+// provider monitors, check history, incidents and deep checks stay with the domains;
+// status page entries and pending digest events of the previous owner are dropped.
+// Either every domain moves or none does.
+func (s *DomainService) TransferDomains(ctx context.Context, adminID int, req model.DomainTransferRequest) (*model.DomainTransferResponse, error) {
+	logger := logging.FromContext(ctx, s.logger).With("from_user_id", req.FromUserID, "to_user_id", req.ToUserID)
+
+	if req.FromUserID == req.ToUserID {
+		return nil, ErrSameTransferUser
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var targetExists bool
+	if err := tx.Get(&targetExists, "SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)", req.ToUserID); err != nil {
+		return nil, fmt.Errorf("failed to check target user: %w", err)
+	}
+	if !targetExists {
+		return nil, ErrTargetUserNotFound
+	}
+
+	var domains []model.Domain
+	err = tx.Select(&domains, `
+        SELECT * FROM domains
+        WHERE user_id = $1 AND id = ANY($2) AND deleted_at IS NULL
+        ORDER BY id
+        FOR UPDATE
+    `, req.FromUserID, pq.Array(req.DomainIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch domains: %w", err)
+	}
+	if missing := missingDomainIDs(req.DomainIDs, domains); len(missing) > 0 {
+		return nil, &MissingDomainsError{IDs: missing}
+	}
+
+	// Archived domains do not count towards the limit, as when adding domains
+	limit, err := s.GetDomainLimit(req.ToUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get domain limit: %w", err)
+	}
+	var count int
+	err = tx.Get(&count, "SELECT COUNT(*) FROM domains WHERE user_id = $1 AND archived_at IS NULL AND deleted_at IS NULL", req.ToUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count target domains: %w", err)
+	}
+	for _, d := range domains {
+		if d.ArchivedAt == nil {
+			count++
+		}
+	}
+	if count > limit {
+		return nil, ErrDomainLimitReached
+	}
+
+	for _, d := range domains {
+		var exists bool
+		err := tx.Get(&exists, `
+            SELECT EXISTS (
+                SELECT 1 FROM domains
+                WHERE user_id = $1 AND LOWER(name) = LOWER($2) AND deleted_at IS NULL
+                  AND (ARRAY[region::TEXT] || extra_regions) && $3::TEXT[]
+            )
+        `, req.ToUserID, d.Name, pq.Array(d.Regions()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to check target domains: %w", err)
+		}
+		if exists {
+			return nil, &TransferConflictError{Name: d.Name}
+		}
+	}
+
+	ids := pq.Array(req.DomainIDs)
+	statements := []string{
+		"UPDATE domains SET user_id = $2, updated_at = NOW() WHERE id = ANY($1)",
+		"UPDATE incidents SET user_id = $2 WHERE domain_id = ANY($1)",
+		"UPDATE deep_check_orders SET user_id = $2, reply_chat_id = NULL WHERE domain_id = ANY($1)",
+		"UPDATE maintenance_windows SET user_id = $2 WHERE domain_id = ANY($1)",
+		"UPDATE runbooks SET user_id = $2, updated_at = NOW() WHERE domain_id = ANY($1)",
+		"UPDATE monitor_tasks SET user_id = $2, updated_at = NOW() WHERE domain_id = ANY($1)",
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement, ids, req.ToUserID); err != nil {
+			return nil, fmt.Errorf("failed to transfer domains: %w", err)
+		}
+	}
+
+	// Entries pointing at the previous owner's pages and notification configs
+	cleanups := []string{
+		"DELETE FROM status_page_components WHERE domain_id = ANY($1)",
+		"DELETE FROM public_status_page_domains WHERE domain_id = ANY($1)",
+		"DELETE FROM notification_digest_events WHERE domain_id = ANY($1)",
+	}
+	for _, statement := range cleanups {
+		if _, err := tx.Exec(statement, ids); err != nil {
+			return nil, fmt.Errorf("failed to transfer domains: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	response := &model.DomainTransferResponse{
+		FromUserID: req.FromUserID,
+		ToUserID:   req.ToUserID,
+		Domains:    make([]model.DomainTransferResult, 0, len(domains)),
+	}
+	for _, d := range domains {
+		response.Domains = append(response.Domains, model.DomainTransferResult{ID: d.ID, Name: d.Name})
+
+		details := map[string]int{"from_user_id": req.FromUserID, "to_user_id": req.ToUserID, "admin_id": adminID}
+		for _, userID := range []int{req.FromUserID, req.ToUserID} {
+			if err := s.audit.Record(userID, d.ID, audit.ActionDomainTransferred, details); err != nil {
+				logger.Error("Failed to record domain transfer", "domain_id", d.ID, "error", err)
+			}
+		}
+	}
+	s.events.Publish(events.Event{Type: events.DomainUpdated, UserID: req.FromUserID})
+	s.events.Publish(events.Event{Type: events.DomainUpdated, UserID: req.ToUserID})
+
+	logger.Info("Domains transferred", "count", len(domains), "admin_id", adminID)
+	return response, nil
+}
+
+// missingDomainIDs lists the requested IDs that were not found, in ascending order
+func missingDomainIDs(requested []int, found []model.Domain) []int {
+	present := make(map[int]bool, len(found))
+	for _, d := range found {
+		present[d.ID] = true
+	}
+	var missing []int
+	for _, id := range requested {
+		if !present[id] {
+			missing = append(missing, id)
+		}
+	}
+	sort.Ints(missing)
+	return missing
+}
@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"domain-detection-go/internal/events"
+	"domain-detection-go/internal/logging"
+	"domain-detection-go/pkg/model"
+)
+
+// trashRetentionDays is how long a deleted domain can be restored before it is purged
+const trashRetentionDays = 30
+
+// trashDomain moves a domain to the trash. Its provider monitors must already be deleted;
+// the built-in check is paused and resumed on restore.
+func (s *DomainService) trashDomain(domainID, userID int) error {
+	var directMonitorID sql.NullString
+	err := s.db.Get(&directMonitorID, `
+        UPDATE domains
+        SET deleted_at = NOW(), active = false, monitor_guid = '', site24x7_monitor_id = NULL,
+            recovery_pending = false, recovery_successes = 0, failure_pending = false, updated_at = NOW()
+        WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+        RETURNING direct_monitor_id
+    `, domainID, userID)
+	if err == sql.ErrNoRows {
+		return ErrDomainNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete domain: %w", err)
+	}
+
+	if directMonitorID.Valid {
+		s.setDirectMonitorStatus(model.Domain{ID: domainID, DirectMonitorID: &directMonitorID.String}, false)
+	}
+	return nil
+}
+
+// GetTrash returns the deleted domains of a user, most recently deleted first
+func (s *DomainService) GetTrash(userID int) (model.DomainTrashResponse, error) {
+	response := model.DomainTrashResponse{Domains: []model.Domain{}, RetentionDays: trashRetentionDays}
+	err := s.db.Select(&response.Domains, `
+        SELECT id, user_id, name, active, interval, region, extra_regions, is_deep_check,
+               created_at, updated_at, archived_at, deleted_at
+        FROM domains
+        WHERE user_id = $1 AND deleted_at IS NOT NULL
+        ORDER BY deleted_at DESC
+    `, userID)
+	if err != nil {
+		return model.DomainTrashResponse{}, fmt.Errorf("failed to get deleted domains: %w", err)
+	}
+	return response, nil
+}
+
+// RestoreDomain takes a domain out of the trash and recreates its provider monitors.
+// The domain counts against the limit again unless it was archived when deleted.
+func (s *DomainService) RestoreDomain(ctx context.Context, userID, domainID int) error {
+	var domain model.Domain
+	err := s.db.Get(&domain, `
+        SELECT id, user_id, name, interval, region, extra_regions, direct_monitor_id,
+               archived_at, deleted_at, verification_token, verified_at
+        FROM domains
+        WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL
+    `, domainID, userID)
+	if err == sql.ErrNoRows {
+		return ErrDomainNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get deleted domain: %w", err)
+	}
+
+	if domain.ArchivedAt == nil {
+		var count int
+		err = s.db.Get(&count, "SELECT COUNT(*) FROM domains WHERE user_id = $1 AND archived_at IS NULL AND deleted_at IS NULL", userID)
+		if err != nil {
+			return err
+		}
+		limit, err := s.GetDomainLimit(userID)
+		if err != nil {
+			return err
+		}
+		if count >= limit {
+			return ErrDomainLimitReached
+		}
+	}
+
+	// The domain may have been added again while it was in the trash
+	exists, err := s.domainInRegions(userID, domain.Name, domain.Regions())
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrDomainExists
+	}
+
+	monitored := domain.ArchivedAt == nil && !domain.VerificationPending()
+	_, err = s.db.Exec(`
+        UPDATE domains SET deleted_at = NULL, active = $1, updated_at = NOW()
+        WHERE id = $2 AND user_id = $3
+    `, monitored, domainID, userID)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		// Added again in its primary region since the check above
+		return ErrDomainExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to restore domain: %w", err)
+	}
+
+	if monitored {
+		s.goCreateMonitor(ctx, userID, domainID, domain.Name, domain.Regions(), domain.Interval)
+		s.setDirectMonitorStatus(domain, true)
+	}
+	logging.FromContext(ctx, s.logger).Info("Restored domain", "user_id", userID, "domain_id", domainID)
+
+	s.events.Publish(events.Event{Type: events.DomainAdded, UserID: userID, DomainID: domainID})
+	return nil
+}
+
+// PurgeTrash permanently deletes the domains that have been in the trash for longer
+// than the retention period
+func (s *DomainService) PurgeTrash() (int, error) {
+	var domains []model.Domain
+	err := s.db.Select(&domains, `
+        SELECT id, user_id
+        FROM domains
+        WHERE deleted_at < NOW() - make_interval(days => $1)
+    `, trashRetentionDays)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get expired deleted domains: %w", err)
+	}
+
+	purged := 0
+	for _, domain := range domains {
+		if err := s.deleteDomainFromDB(domain.ID, domain.UserID); err != nil {
+			s.logger.Error("Failed to purge deleted domain", "domain_id", domain.ID, "error", err)
+			continue
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// RunScheduledTrashPurge purges expired deleted domains at startup and then once a day,
+// so that restarts more often than daily still purge
+func (s *DomainService) RunScheduledTrashPurge(ctx context.Context) {
+	s.logger.Info("RunScheduledTrashPurge")
+	run := func() {
+		purged, err := s.PurgeTrash()
+		if err != nil {
+			s.logger.Error("Trash purge failed", "error", err)
+			return
+		}
+		if purged > 0 {
+			s.logger.Info("Purged deleted domains", "count", purged)
+		}
+	}
+	run()
+
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("RunScheduledTrashPurge stopped")
+			return
+		case <-ticker.C:
+			run()
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+
+	"domain-detection-go/internal/events"
+	"domain-detection-go/internal/testdb"
+)
+
+func insertTestDomain(t *testing.T, db *sqlx.DB, userID int, name string, trashed bool) int {
+	t.Helper()
+	var id int
+	err := db.Get(&id, `
+        INSERT INTO domains (user_id, name, region, deleted_at)
+        VALUES ($1, $2, 'VN', CASE WHEN $3 THEN NOW() END)
+        RETURNING id
+    `, userID, name, trashed)
+	if err != nil {
+		t.Fatalf("insert domain %s: %v", name, err)
+	}
+	return id
+}
+
+func insertTestUser(t *testing.T, db *sqlx.DB) int {
+	t.Helper()
+	var id int
+	err := db.Get(&id, `
+        INSERT INTO users (username, password_hash, email) VALUES ('trash', 'hash', 'trash@example.com')
+        RETURNING id
+    `)
+	if err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	return id
+}
+
+func TestTrashedDomainDoesNotBlockAddingItAgain(t *testing.T) {
+	db := testdb.Open(t)
+	userID := insertTestUser(t, db)
+
+	insertTestDomain(t, db, userID, "example.com", true)
+	insertTestDomain(t, db, userID, "Example.com", false)
+
+	_, err := db.Exec(`INSERT INTO domains (user_id, name, region) VALUES ($1, 'example.com', 'VN')`, userID)
+	if err == nil {
+		t.Fatal("second active copy of the domain was accepted")
+	}
+}
+
+func TestRestoreDomainRefusesActiveDuplicate(t *testing.T) {
+	db := testdb.Open(t)
+	s := NewDomainService(db, nil, nil, nil, events.NewBus(), "test", nil, "", false, slog.Default())
+	userID := insertTestUser(t, db)
+
+	trashedID := insertTestDomain(t, db, userID, "example.com", true)
+	insertTestDomain(t, db, userID, "example.com", false)
+
+	err := s.RestoreDomain(context.Background(), userID, trashedID)
+	if !errors.Is(err, ErrDomainExists) {
+		t.Fatalf("RestoreDomain error = %v, want domain already exists in this region", err)
+	}
+
+	var trashed bool
+	if err := db.Get(&trashed, "SELECT deleted_at IS NOT NULL FROM domains WHERE id = $1", trashedID); err != nil {
+		t.Fatalf("get domain: %v", err)
+	}
+	if !trashed {
+		t.Error("domain was restored next to its active duplicate")
+	}
+}
@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+
+	"domain-detection-go/internal/etag"
+	"domain-detection-go/pkg/model"
+)
+
+// domainState is the client-managed part of a domain that its ETag covers
+type domainState struct {
+	Name        string `json:"name"`
+	Region      string `json:"region"`
+	Interval    int    `json:"interval"`
+	Active      bool   `json:"active"`
+	IsDeepCheck bool   `json:"is_deep_check"`
+}
+
+// DomainETag returns the ETag of a domain. Check results do not affect it.
+func DomainETag(d model.Domain) string {
+	return etag.Compute(domainState{
+		Name:        d.Name,
+		Region:      d.Region,
+		Interval:    d.Interval,
+		Active:      d.Active,
+		IsDeepCheck: d.IsDeepCheck,
+	})
+}
+
+// canonicalDomainName returns the name as stored, with https:// added when no scheme is given
+func canonicalDomainName(name string) string {
+	if parsed, err := url.Parse(name); err == nil && parsed.Scheme == "" {
+		return "https://" + name
+	}
+	return name
+}
+
+// FindDomainByKey returns the user's domain with the given name and region, or nil if none exists
+func (s *DomainService) FindDomainByKey(userID int, name, region string) (*model.Domain, error) {
+	var domainID int
+	err := s.db.Get(&domainID, `
+        SELECT id FROM domains
+        WHERE user_id = $1 AND LOWER(name) = LOWER($2) AND region = $3 AND deleted_at IS NULL
+    `, userID, canonicalDomainName(name), region)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetDomain(domainID, userID)
+}
+
+// UpsertDomain creates or updates the domain identified by name and region so that it
+// matches req. Only changed fields are written, so repeating a request is a no-op.
+// ifMatch and ifNoneMatch are the conditional request headers; etag.ErrPreconditionFailed
+// is returned when they do not hold. The boolean result reports whether the domain was created.
+func (s *DomainService) UpsertDomain(ctx context.Context, userID int, req model.DomainUpsertRequest, ifMatch, ifNoneMatch string) (*model.Domain, bool, error) {
+	existing, err := s.FindDomainByKey(userID, req.Name, req.Region)
+	if err != nil {
+		return nil, false, err
+	}
+
+	current := ""
+	if existing != nil {
+		current = DomainETag(*existing)
+	}
+	if err := etag.Check(ifMatch, ifNoneMatch, current); err != nil {
+		return nil, false, err
+	}
+
+	interval := req.Interval
+	if interval == 0 {
+		settings, err := s.GetUserSettings(userID)
+		if err != nil {
+			return nil, false, err
+		}
+		interval = defaultInterval(settings)
+	}
+	active := req.Active == nil || *req.Active
+
+	if existing == nil {
+		domainID, err := s.AddDomain(ctx, userID, model.DomainAddRequest{
+			Name:        req.Name,
+			Interval:    interval,
+			Region:      req.Region,
+			IsDeepCheck: req.IsDeepCheck,
+		})
+		// Another request created the domain since it was looked up, so it no longer
+		// matches the conditions the request was made under
+		if errors.Is(err, ErrDomainExists) && (ifMatch != "" || ifNoneMatch != "") {
+			return nil, false, etag.ErrPreconditionFailed
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		if !active {
+			if err := s.UpdateDomain(ctx, domainID, userID, model.DomainUpdateRequest{Active: &active}); err != nil {
+				return nil, false, err
+			}
+		}
+
+		domain, err := s.GetDomain(domainID, userID)
+		return domain, true, err
+	}
+
+	var update model.DomainUpdateRequest
+	if existing.Interval != interval {
+		update.Interval = &interval
+	}
+	if existing.Active != active {
+		update.Active = &active
+	}
+	if existing.IsDeepCheck != req.IsDeepCheck {
+		update.IsDeepCheck = &req.IsDeepCheck
+	}
+	if update.Interval == nil && update.Active == nil && update.IsDeepCheck == nil {
+		return existing, false, nil
+	}
+
+	// The write only applies while the domain still has the state its ETag was checked
+	// against, so a concurrent change fails the request instead of being overwritten
+	if err := s.updateDomain(ctx, existing.ID, userID, update, existing); err != nil {
+		return nil, false, err
+	}
+
+	domain, err := s.GetDomain(existing.ID, userID)
+	return domain, false, err
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"domain-detection-go/internal/etag"
+	"domain-detection-go/internal/events"
+	"domain-detection-go/internal/testdb"
+	"domain-detection-go/pkg/model"
+)
+
+func TestUpdateDomainRefusesChangedPrecondition(t *testing.T) {
+	db := testdb.Open(t)
+	s := NewDomainService(db, nil, nil, nil, events.NewBus(), "test", nil, "", false, slog.Default())
+	userID := insertTestUser(t, db)
+	domainID := insertTestDomain(t, db, userID, "https://example.com", false)
+
+	checked, err := s.GetDomain(domainID, userID)
+	if err != nil {
+		t.Fatalf("GetDomain: %v", err)
+	}
+
+	// Another request changes the domain after the ETag was checked
+	if _, err := db.Exec("UPDATE domains SET interval = interval + 1 WHERE id = $1", domainID); err != nil {
+		t.Fatalf("concurrent update: %v", err)
+	}
+
+	inactive := false
+	err = s.updateDomain(context.Background(), domainID, userID, model.DomainUpdateRequest{Active: &inactive}, checked)
+	if !errors.Is(err, etag.ErrPreconditionFailed) {
+		t.Fatalf("updateDomain with a stale state returned %v, want %v", err, etag.ErrPreconditionFailed)
+	}
+
+	current, err := s.GetDomain(domainID, userID)
+	if err != nil {
+		t.Fatalf("GetDomain: %v", err)
+	}
+	if !current.Active {
+		t.Error("stale update was written")
+	}
+	if err := s.updateDomain(context.Background(), domainID, userID, model.DomainUpdateRequest{Active: &inactive}, current); err != nil {
+		t.Errorf("updateDomain with the current state: %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"database/sql"
+	"fmt"
+
+	"domain-detection-go/pkg/model"
+
+	"github.com/lib/pq"
+)
+
+// GetUserSettings returns the domain limit and domain defaults of a user
+func (s *DomainService) GetUserSettings(userID int) (*model.UserSettings, error) {
+	settings := model.UserSettings{DomainLimit: DEFAULT_DOMAIN_LIMIT}
+	err := s.db.Get(&settings, `
+        SELECT COALESCE(domain_limit, $2) AS domain_limit, default_interval, default_region,
+               default_failure_threshold, default_recovery_threshold, default_notification_channels
+        FROM user_settings
+        WHERE user_id = $1
+    `, userID, DEFAULT_DOMAIN_LIMIT)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get user settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// UpdateUserSettings replaces the domain defaults of a user. The default interval and
+// region are validated like those of a new domain.
+func (s *DomainService) UpdateUserSettings(userID int, req model.UserSettingsRequest) error {
+	if req.DefaultInterval != nil {
+		if err := s.ValidateInterval(userID, *req.DefaultInterval); err != nil {
+			return err
+		}
+	}
+	if req.DefaultRegion != nil {
+		var isValidRegion bool
+		err := s.db.Get(&isValidRegion, "SELECT EXISTS(SELECT 1 FROM regions WHERE code = $1 AND is_active = TRUE)", *req.DefaultRegion)
+		if err != nil {
+			return fmt.Errorf("error verifying region: %w", err)
+		}
+		if !isValidRegion {
+			return ErrInvalidRegion
+		}
+	}
+
+	var channels pq.StringArray
+	if req.DefaultChannels != nil {
+		channels = model.DomainChannels(*req.DefaultChannels)
+	}
+
+	_, err := s.db.Exec(`
+        INSERT INTO user_settings (user_id, default_interval, default_region, default_failure_threshold,
+                                   default_recovery_threshold, default_notification_channels, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, NOW())
+        ON CONFLICT (user_id)
+        DO UPDATE SET default_interval = $2, default_region = $3, default_failure_threshold = $4,
+                      default_recovery_threshold = $5, default_notification_channels = $6, updated_at = NOW()
+    `, userID, req.DefaultInterval, req.DefaultRegion, req.DefaultFailureThreshold, req.DefaultRecoveryThreshold, channels)
+	if err != nil {
+		return fmt.Errorf("failed to update user settings: %w", err)
+	}
+	return nil
+}
+
+// applyDomainDefaults stores the user's default alert thresholds and notification
+// channels on a domain that was just added
+func (s *DomainService) applyDomainDefaults(domainID int, settings *model.UserSettings) error {
+	if settings.DefaultFailureThreshold == nil && settings.DefaultRecoveryThreshold == nil && settings.DefaultChannels == nil {
+		return nil
+	}
+	_, err := s.db.Exec(`
+        UPDATE domains
+        SET failure_threshold = COALESCE($1, failure_threshold),
+            recovery_threshold = COALESCE($2, recovery_threshold),
+            notification_channels = $3
+        WHERE id = $4
+    `, settings.DefaultFailureThreshold, settings.DefaultRecoveryThreshold, settings.DefaultChannels, domainID)
+	if err != nil {
+		return fmt.Errorf("failed to apply domain defaults: %w", err)
+	}
+	return nil
+}
+
+// defaultInterval returns the interval of a domain added without one
+func defaultInterval(settings *model.UserSettings) int {
+	if settings.DefaultInterval != nil {
+		return *settings.DefaultInterval
+	}
+	return DEFAULT_INTERVAL
+}
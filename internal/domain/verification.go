@@ -0,0 +1,205 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"domain-detection-go/internal/audit"
+	"domain-detection-go/internal/events"
+	"domain-detection-go/internal/logging"
+	"domain-detection-go/internal/netguard"
+	"domain-detection-go/pkg/model"
+)
+
+const (
+	// verificationDNSPrefix is prepended to the host name of a domain for its TXT record
+	verificationDNSPrefix = "_domain-detection"
+	// verificationPath is where a domain serves its token over HTTP
+	verificationPath = "/.well-known/domain-detection-verification.txt"
+	// verificationTimeout bounds each lookup of a verification token
+	verificationTimeout = 10 * time.Second
+)
+
+// verifiedCondition matches the domains that do not wait for ownership verification
+const verifiedCondition = "(verification_token IS NULL OR verified_at IS NOT NULL)"
+
+// startMonitoring creates the provider monitors of a new domain, or holds the domain back
+// until its ownership is verified when verification is required
+func (s *DomainService) startMonitoring(ctx context.Context, userID, domainID int, fullURL string, regions []string, interval int) {
+	if !s.requireVerification {
+		s.goCreateMonitor(ctx, userID, domainID, fullURL, regions, interval)
+		return
+	}
+
+	token, err := newVerificationToken()
+	if err == nil {
+		_, err = s.db.Exec(`
+            UPDATE domains SET active = false, verification_token = $1, updated_at = NOW()
+            WHERE id = $2
+        `, token, domainID)
+	}
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Failed to request domain verification", "domain_id", domainID, "error", err)
+	}
+}
+
+// newVerificationToken returns a random token to publish for a domain
+func newVerificationToken() (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate verification token: %w", err)
+	}
+	return "domain-detection-verification=" + hex.EncodeToString(raw), nil
+}
+
+// verificationInstructions describes where the token of a domain has to be published
+func verificationInstructions(domain model.Domain) model.DomainVerification {
+	host := domain.Name
+	if parsed, err := url.Parse(canonicalDomainName(domain.Name)); err == nil && parsed.Hostname() != "" {
+		host = parsed.Hostname()
+	}
+
+	verification := model.DomainVerification{
+		DomainID:   domain.ID,
+		Verified:   !domain.VerificationPending(),
+		VerifiedAt: domain.VerifiedAt,
+		DNSName:    verificationDNSPrefix + "." + host,
+		HTTPURL:    "https://" + host + verificationPath,
+	}
+	if domain.VerificationToken != nil {
+		verification.Token = *domain.VerificationToken
+	}
+	return verification
+}
+
+// GetDomainVerification returns how to verify the ownership of a domain
+func (s *DomainService) GetDomainVerification(userID, domainID int) (*model.DomainVerification, error) {
+	domain, err := s.GetDomain(domainID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if domain.VerificationToken == nil {
+		return nil, ErrVerificationNotRequired
+	}
+
+	verification := verificationInstructions(*domain)
+	return &verification, nil
+}
+
+// VerifyDomain looks for the verification token of a domain in its DNS and on its
+// well-known file. Once found the domain is activated and its monitors are created.
+func (s *DomainService) VerifyDomain(ctx context.Context, userID, domainID int) (*model.DomainVerification, error) {
+	logger := logging.FromContext(ctx, s.logger).With("user_id", userID, "domain_id", domainID)
+
+	domain, err := s.GetDomain(domainID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if domain.VerificationToken == nil {
+		return nil, ErrVerificationNotRequired
+	}
+	verification := verificationInstructions(*domain)
+	if verification.Verified {
+		return &verification, nil
+	}
+
+	method := ""
+	if found, err := lookupVerificationTXT(ctx, verification.DNSName, verification.Token); err != nil {
+		logger.Debug("Verification TXT lookup failed", "name", verification.DNSName, "error", err)
+	} else if found {
+		method = model.VerificationDNS
+	}
+	if method == "" {
+		if found, err := fetchVerificationFile(ctx, verification.HTTPURL, verification.Token); err != nil {
+			logger.Debug("Verification file fetch failed", "url", verification.HTTPURL, "error", err)
+		} else if found {
+			method = model.VerificationHTTP
+		}
+	}
+	if method == "" {
+		return &verification, ErrVerificationTokenNotFound
+	}
+
+	// Archived domains stay inactive; unarchiving creates their monitors
+	err = s.db.QueryRow(`
+        UPDATE domains SET verified_at = NOW(), active = (archived_at IS NULL), updated_at = NOW()
+        WHERE id = $1 AND user_id = $2
+        RETURNING verified_at
+    `, domainID, userID).Scan(&verification.VerifiedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark domain as verified: %w", err)
+	}
+	verification.Verified = true
+	verification.Method = method
+	logger.Info("Domain verified", "method", method)
+
+	if domain.ArchivedAt == nil {
+		s.goCreateMonitor(ctx, userID, domainID, domain.Name, domain.Regions(), domain.Interval)
+	}
+	if err := s.audit.Record(userID, domainID, audit.ActionDomainVerified, verification); err != nil {
+		logger.Error("Failed to record domain verification", "error", err)
+	}
+	s.events.Publish(events.Event{Type: events.DomainUpdated, UserID: userID, DomainID: domainID})
+
+	return &verification, nil
+}
+
+// lookupVerificationTXT reports whether any TXT record of the name holds the token
+func lookupVerificationTXT(ctx context.Context, name, token string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, verificationTimeout)
+	defer cancel()
+
+	records, err := net.DefaultResolver.LookupTXT(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	for _, record := range records {
+		if strings.TrimSpace(record) == token {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// verificationClient fetches verification files. The domain is user input, so internal
+// addresses are refused, and redirects are not followed: the file must be served by the
+// domain itself, and a redirect could point anywhere.
+var verificationClient = &http.Client{
+	Transport: netguard.Transport(),
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// fetchVerificationFile reports whether the well-known file of a domain holds the token
+func fetchVerificationFile(ctx context.Context, fileURL, token string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, verificationTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := verificationClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return false, err
+	}
+	return strings.Contains(string(body), token), nil
+}
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"domain-detection-go/internal/netguard"
+)
+
+func TestFetchVerificationFileRefusesInternalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached a loopback server")
+		w.Write([]byte("token"))
+	}))
+	defer server.Close()
+
+	found, err := fetchVerificationFile(context.Background(), server.URL+verificationPath, "token")
+	if found {
+		t.Fatal("verification file on a loopback address was accepted")
+	}
+	if !errors.Is(err, netguard.ErrInternalAddress) {
+		t.Errorf("fetch error = %v, want %v", err, netguard.ErrInternalAddress)
+	}
+}
@@ -0,0 +1,46 @@
+// Package etag computes entity tags and evaluates conditional request headers
+// for endpoints that support optimistic concurrency.
+package etag
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// ErrPreconditionFailed is returned when If-Match or If-None-Match does not hold
+var ErrPreconditionFailed = errors.New("precondition failed")
+
+// Compute returns a strong ETag for the JSON encoding of v. Only the fields a client
+// can set should be passed so that background updates (check results) do not change it.
+func Compute(v interface{}) string {
+	data, _ := json.Marshal(v)
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// Matches reports whether a comma-separated If-Match / If-None-Match header value
+// lists etag or is "*". Weak validators are compared by their opaque tag.
+func Matches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Check evaluates the conditional headers against the current ETag of a resource,
+// which is empty when the resource does not exist yet
+func Check(ifMatch, ifNoneMatch, current string) error {
+	if ifMatch != "" && (current == "" || !Matches(ifMatch, current)) {
+		return ErrPreconditionFailed
+	}
+	if ifNoneMatch != "" && current != "" && Matches(ifNoneMatch, current) {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package events
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Event types published by the services
+const (
+	DomainAdded          = "domain.added"
+	DomainUpdated        = "domain.updated"
+	DomainDeleted        = "domain.deleted"
+	DomainStatusChanged  = "domain.status_changed"
+	DomainChecked        = "domain.checked"   // Payload "result" is the model.DomainCheckResult once stored
+	DomainEvaluated      = "domain.evaluated" // A check that passed the failure and recovery thresholds
+	DomainAlert          = "domain.alert"     // An outage or status change to notify about
+	DNSDivergence        = "domain.dns_divergence"
+	TLSRegression        = "domain.tls_regression"
+	RegistrationExpiring = "domain.registration_expiring"
+	DNSRecordsChanged    = "domain.dns_records_changed"
+)
+
+// Event represents something that happened to a domain or user
+type Event struct {
+	Type       string
+	UserID     int
+	DomainID   int
+	Payload    map[string]interface{}
+	OccurredAt time.Time
+}
+
+// Handler processes a published event
+type Handler func(Event)
+
+// Bus is a simple in-process publish/subscribe event bus
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+	queues   map[string][]chan Event // Async subscribers, each drained by its own goroutine
+	closed   bool
+	workers  sync.WaitGroup
+}
+
+// NewBus creates a new event bus
+func NewBus() *Bus {
+	return &Bus{
+		handlers: make(map[string][]Handler),
+		queues:   make(map[string][]chan Event),
+	}
+}
+
+// Subscribe registers a handler for one or more event types
+func (b *Bus) Subscribe(handler Handler, eventTypes ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, eventType := range eventTypes {
+		b.handlers[eventType] = append(b.handlers[eventType], handler)
+	}
+}
+
+// SubscribeAsync registers a handler that runs on its own goroutine, so slow work such
+// as sending notifications does not hold up the publisher. Events are handled one at a
+// time in publish order; Publish blocks once buffer events are waiting.
+func (b *Bus) SubscribeAsync(handler Handler, buffer int, eventTypes ...string) {
+	queue := make(chan Event, buffer)
+
+	b.mu.Lock()
+	for _, eventType := range eventTypes {
+		b.queues[eventType] = append(b.queues[eventType], queue)
+	}
+	b.mu.Unlock()
+
+	b.workers.Add(1)
+	go func() {
+		defer b.workers.Done()
+		for event := range queue {
+			b.dispatch(handler, event)
+		}
+	}()
+}
+
+// Publish delivers an event synchronously to all subscribed handlers and queues it for
+// the async ones. A nil bus is a no-op so services can run without one.
+func (b *Bus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	b.mu.RLock()
+	handlers := append([]Handler(nil), b.handlers[event.Type]...)
+	if !b.closed {
+		for _, queue := range b.queues[event.Type] {
+			queue <- event
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		b.dispatch(handler, event)
+	}
+}
+
+// Close stops accepting events for async subscribers and blocks until the queued ones
+// have been handled
+func (b *Bus) Close() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		closed := make(map[chan Event]bool)
+		for _, queues := range b.queues {
+			for _, queue := range queues {
+				if !closed[queue] {
+					closed[queue] = true
+					close(queue)
+				}
+			}
+		}
+	}
+	b.mu.Unlock()
+
+	b.workers.Wait()
+}
+
+// dispatch runs a handler, recovering from a panic so one subscriber cannot take down
+// the publisher or another subscriber
+func (b *Bus) dispatch(handler Handler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("Recovered from panic in event handler", "event", event.Type, "panic", r)
+		}
+	}()
+	handler(event)
+}
@@ -0,0 +1,308 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"domain-detection-go/pkg/model"
+)
+
+// accountExportTTL is how long the download link of an account export works
+const accountExportTTL = 7 * 24 * time.Hour
+
+// maxAccountExportBytes caps the size of an account export. Archives are kept in the
+// database until their link expires, so a larger one fails instead of being stored.
+const maxAccountExportBytes = 100 << 20
+
+// errAccountExportTooLarge is recorded on exports that grew past maxAccountExportBytes
+var errAccountExportTooLarge = fmt.Errorf("account export is larger than %d MB", maxAccountExportBytes>>20)
+
+// errAccountExportNotPending is returned when an export was marked failed, e.g. as
+// interrupted, while its archive was being written. Its link is then not mailed.
+var errAccountExportNotPending = errors.New("account export is no longer pending")
+
+// accountExportTimeout is how long an export may stay pending before it is considered
+// lost to a restart and marked failed
+const accountExportTimeout = time.Hour
+
+// accountExportFiles are the files of an account export. Each holds the rows of its
+// query as a JSON array. Columns are listed so stored secrets, such as basic auth
+// passwords and verification tokens, stay out of the archive.
+var accountExportFiles = []struct {
+	name  string
+	query string
+}{
+	{"profile.json", `
+        SELECT id, username, email, region, timezone, two_factor_enabled, trial_expires_at, created_at, updated_at
+        FROM users WHERE id = $1`},
+	{"domains.json", `
+        SELECT id, name, unicode_name, region, extra_regions, active, interval, is_deep_check, check_type, port,
+               last_status, error_code, total_time, error_description, last_check, availability_strategy,
+               failure_threshold, recovery_threshold, content_match_type, content_match_pattern, http_method,
+               http_headers, basic_auth_username, expected_status_codes, follow_redirects, notification_channels,
+               notes, owner_contact, labels, parent_id, verified_at, snoozed_until, archived_at, deleted_at,
+               created_at, updated_at
+        FROM domains WHERE user_id = $1 ORDER BY id`},
+	{"check_history.json", `
+        SELECT h.id, h.domain_id, h.available, h.status_code, h.error_code, h.total_time, h.error_description,
+               h.dissenting_providers, h.checked_at
+        FROM domain_check_history h JOIN domains d ON d.id = h.domain_id
+        WHERE d.user_id = $1 ORDER BY h.domain_id, h.checked_at`},
+	{"telegram_configs.json", `
+        SELECT id, chat_id, chat_name, language, is_active, notify_on_down, notify_on_up,
+               timezone, external_id, created_at, updated_at
+        FROM telegram_configs WHERE user_id = $1 ORDER BY id`},
+	{"email_configs.json", `
+        SELECT id, email_address, email_name, language, is_active, notify_on_down, notify_on_up,
+               timezone, external_id, verified_at, created_at, updated_at
+        FROM email_configs WHERE user_id = $1 ORDER BY id`},
+	{"notification_history.json", `
+        SELECT nh.id, nh.domain_id, nh.telegram_config_id, nh.email_config_id, nh.incident_id, nh.notification_type,
+               nh.status_code, nh.error_code, nh.error_description, nh.delivery_status, nh.delivery_error,
+               nh.attempts, nh.last_attempt_at, nh.notified_at
+        FROM notification_history nh JOIN domains d ON d.id = nh.domain_id
+        WHERE d.user_id = $1 ORDER BY nh.notified_at, nh.id`},
+}
+
+// AccountExportMailer mails users the link to download their data export
+type AccountExportMailer interface {
+	SendAccountExportLink(to, link string, expiresAt time.Time) error
+}
+
+// SetAccountExportMailer sets how download links of account exports reach users and the
+// public URL of the API the links point to
+func (s *ExportService) SetAccountExportMailer(mailer AccountExportMailer, publicBaseURL string) {
+	s.mailer = mailer
+	s.publicBaseURL = publicBaseURL
+}
+
+const accountExportColumns = "id, status, size_bytes, last_error, requested_at, completed_at, expires_at"
+
+// RequestAccountExport starts building an archive of all data of a user in the
+// background. While one is being built, it is returned instead of starting another.
+func (s *ExportService) RequestAccountExport(userID int) (*model.AccountExport, error) {
+	var export model.AccountExport
+	err := s.db.Get(&export, `
+        INSERT INTO account_exports (user_id) VALUES ($1)
+        ON CONFLICT (user_id) WHERE status = 'pending' DO NOTHING
+        RETURNING `+accountExportColumns, userID)
+	if err == sql.ErrNoRows {
+		err = s.db.Get(&export, `
+            SELECT `+accountExportColumns+` FROM account_exports
+            WHERE user_id = $1 AND status = 'pending'
+        `, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get account export: %w", err)
+		}
+		return &export, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to request account export: %w", err)
+	}
+
+	s.builds.Add(1)
+	go func() {
+		defer s.builds.Done()
+		s.buildAccountExport(export.ID, userID)
+	}()
+	return &export, nil
+}
+
+// Wait blocks until the account exports being built in the background have finished
+func (s *ExportService) Wait() {
+	s.builds.Wait()
+}
+
+// buildAccountExport writes the archive of an export and mails its download link
+func (s *ExportService) buildAccountExport(exportID, userID int) {
+	err := s.completeAccountExport(exportID, userID)
+	if err == nil {
+		slog.Info("Account export ready", "export_id", exportID, "user_id", userID)
+		return
+	}
+	if errors.Is(err, errAccountExportNotPending) {
+		slog.Warn("Account export was marked failed while it was built", "export_id", exportID, "user_id", userID)
+		return
+	}
+
+	slog.Error("Account export failed", "export_id", exportID, "user_id", userID, "error", err)
+	_, dbErr := s.db.Exec(`
+        UPDATE account_exports SET status = 'failed', archive = NULL, token_hash = NULL, last_error = $1, completed_at = NOW()
+        WHERE id = $2 AND status = 'pending'
+    `, err.Error(), exportID)
+	if dbErr != nil {
+		slog.Error("Failed to record failure of account export", "export_id", exportID, "error", dbErr)
+	}
+}
+
+func (s *ExportService) completeAccountExport(exportID, userID int) error {
+	if s.mailer == nil {
+		return errors.New("no mailer configured for account exports")
+	}
+
+	var email string
+	if err := s.db.Get(&email, "SELECT email FROM users WHERE id = $1", userID); err != nil {
+		return fmt.Errorf("failed to get user email: %w", err)
+	}
+
+	archive, err := s.writeAccountArchive(userID)
+	if err != nil {
+		return err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return fmt.Errorf("failed to generate download token: %w", err)
+	}
+	token := hex.EncodeToString(secret)
+	expiresAt := time.Now().Add(accountExportTTL)
+
+	// An export pending past accountExportTimeout may have been marked failed meanwhile,
+	// letting the user request a new one. It stays failed and its link is not mailed.
+	result, err := s.db.Exec(`
+        UPDATE account_exports
+        SET status = 'ready', archive = $1, size_bytes = $2, token_hash = $3, completed_at = NOW(), expires_at = $4
+        WHERE id = $5 AND status = 'pending'
+    `, archive, len(archive), hashExportToken(token), expiresAt, exportID)
+	if err != nil {
+		return fmt.Errorf("failed to store account export: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return errAccountExportNotPending
+	}
+
+	return s.mailer.SendAccountExportLink(email, s.publicBaseURL+"/api/user/export/"+token, expiresAt)
+}
+
+// writeAccountArchive builds the ZIP archive of all data of a user. It fails with
+// errAccountExportTooLarge once the archive passes maxAccountExportBytes.
+func (s *ExportService) writeAccountArchive(userID int) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&limitedWriter{w: &buf, remaining: maxAccountExportBytes})
+	for _, file := range accountExportFiles {
+		w, err := zw.Create(file.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add %s: %w", file.name, err)
+		}
+		if err := s.writeJSONRows(w, file.query, userID); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", file.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeJSONRows writes the rows of a query as a JSON array, one object per row, as they
+// are scanned
+func (s *ExportService) writeJSONRows(w io.Writer, query string, args ...interface{}) error {
+	rows, err := s.db.Queryx(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	separator := "\n"
+	for rows.Next() {
+		row := map[string]interface{}{}
+		if err := rows.MapScan(row); err != nil {
+			return err
+		}
+		// JSONB columns arrive as raw bytes, other byte values are kept as text
+		for column, value := range row {
+			if b, ok := value.([]byte); ok {
+				if json.Valid(b) {
+					row[column] = json.RawMessage(b)
+				} else {
+					row[column] = string(b)
+				}
+			}
+		}
+		data, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, separator+string(data)); err != nil {
+			return err
+		}
+		separator = ",\n"
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n]\n")
+	return err
+}
+
+// limitedWriter writes to w until remaining bytes are used up, then fails with
+// errAccountExportTooLarge
+type limitedWriter struct {
+	w         io.Writer
+	remaining int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > l.remaining {
+		return 0, errAccountExportTooLarge
+	}
+	n, err := l.w.Write(p)
+	l.remaining -= n
+	return n, err
+}
+
+// DownloadAccountExport returns the archive behind a download link
+func (s *ExportService) DownloadAccountExport(token string) ([]byte, error) {
+	var archive []byte
+	err := s.db.Get(&archive, `
+        SELECT archive FROM account_exports
+        WHERE token_hash = $1 AND status = 'ready' AND expires_at > NOW()
+    `, hashExportToken(token))
+	if err == sql.ErrNoRows {
+		return nil, ErrAccountExportNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account export: %w", err)
+	}
+	return archive, nil
+}
+
+// ExpireAccountExports drops the archives whose download link expired and fails the
+// exports a restart left pending
+func (s *ExportService) ExpireAccountExports() error {
+	_, err := s.db.Exec(`
+        UPDATE account_exports SET status = 'expired', archive = NULL, token_hash = NULL
+        WHERE status = 'ready' AND expires_at <= NOW()
+    `)
+	if err != nil {
+		return fmt.Errorf("failed to expire account exports: %w", err)
+	}
+
+	_, err = s.db.Exec(`
+        UPDATE account_exports SET status = 'failed', last_error = 'interrupted', completed_at = NOW()
+        WHERE status = 'pending' AND requested_at < NOW() - make_interval(secs => $1)
+    `, int(accountExportTimeout.Seconds()))
+	if err != nil {
+		return fmt.Errorf("failed to fail interrupted account exports: %w", err)
+	}
+	return nil
+}
+
+// hashExportToken returns the stored form of a download token
+func hashExportToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
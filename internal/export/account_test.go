@@ -0,0 +1,74 @@
+package export
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"domain-detection-go/internal/testdb"
+)
+
+type recordingMailer struct {
+	links []string
+}
+
+func (m *recordingMailer) SendAccountExportLink(to, link string, expiresAt time.Time) error {
+	m.links = append(m.links, link)
+	return nil
+}
+
+func TestLimitedWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := &limitedWriter{w: &buf, remaining: 5}
+
+	if _, err := w.Write([]byte("abc")); err != nil {
+		t.Fatalf("Write within the limit: %v", err)
+	}
+	if _, err := w.Write([]byte("de")); err != nil {
+		t.Fatalf("Write up to the limit: %v", err)
+	}
+	if _, err := w.Write([]byte("f")); !errors.Is(err, errAccountExportTooLarge) {
+		t.Fatalf("Write past the limit = %v, want %v", err, errAccountExportTooLarge)
+	}
+	if got := buf.String(); got != "abcde" {
+		t.Errorf("written %q, want %q", got, "abcde")
+	}
+}
+
+func TestBuildAccountExportLeavesInterruptedExportsFailed(t *testing.T) {
+	db := testdb.Open(t)
+	mailer := &recordingMailer{}
+	s := NewExportService(db, "")
+	s.SetAccountExportMailer(mailer, "https://api.example.com")
+
+	var userID, exportID int
+	err := db.Get(&userID, `
+        INSERT INTO users (username, password_hash, email)
+        VALUES ('exporter', 'hash', 'exporter@example.com') RETURNING id
+    `)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Marked failed as interrupted while its archive was being written
+	err = db.Get(&exportID, `
+        INSERT INTO account_exports (user_id, status, last_error, completed_at)
+        VALUES ($1, 'failed', 'interrupted', NOW()) RETURNING id
+    `, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.buildAccountExport(exportID, userID)
+
+	if len(mailer.links) != 0 {
+		t.Errorf("mailed %d links for an interrupted export", len(mailer.links))
+	}
+	var status, lastError string
+	if err := db.QueryRow("SELECT status, last_error FROM account_exports WHERE id = $1", exportID).Scan(&status, &lastError); err != nil {
+		t.Fatal(err)
+	}
+	if status != "failed" || lastError != "interrupted" {
+		t.Errorf("export is %s (%s), want failed (interrupted)", status, lastError)
+	}
+}
@@ -0,0 +1,147 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+
+	"domain-detection-go/pkg/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// datasetWriters build the CSV file of a dataset for a user and period, returning the
+// number of data rows
+var datasetWriters = map[string]func(db *sqlx.DB, userID int, from, to time.Time) ([]byte, int, error){
+	model.ExportDatasetChecks:        exportChecks,
+	model.ExportDatasetNotifications: exportNotifications,
+	model.ExportDatasetIncidents:     exportIncidents,
+}
+
+type checkRow struct {
+	DomainID         int       `db:"domain_id"`
+	Domain           string    `db:"name"`
+	Region           string    `db:"region"`
+	CheckedAt        time.Time `db:"checked_at"`
+	Available        bool      `db:"available"`
+	StatusCode       int       `db:"status_code"`
+	ErrorCode        int       `db:"error_code"`
+	TotalTime        int       `db:"total_time"`
+	ErrorDescription string    `db:"error_description"`
+}
+
+func selectChecks(db *sqlx.DB, userID int, from, to time.Time) ([]checkRow, error) {
+	var rows []checkRow
+	err := db.Select(&rows, `
+        SELECT h.domain_id, d.name, COALESCE(d.region, '') AS region, h.checked_at, h.available,
+               COALESCE(h.status_code, 0) AS status_code, COALESCE(h.error_code, 0) AS error_code,
+               COALESCE(h.total_time, 0) AS total_time, COALESCE(h.error_description, '') AS error_description
+        FROM domain_check_history h
+        JOIN domains d ON d.id = h.domain_id
+        WHERE d.user_id = $1 AND h.checked_at >= $2 AND h.checked_at < $3
+        ORDER BY h.domain_id, h.checked_at
+    `, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get check history: %w", err)
+	}
+	return rows, nil
+}
+
+func exportChecks(db *sqlx.DB, userID int, from, to time.Time) ([]byte, int, error) {
+	rows, err := selectChecks(db, userID, from, to)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	records := [][]string{{"domain", "region", "checked_at", "available", "status_code", "error_code", "response_time_ms", "error"}}
+	for _, r := range rows {
+		records = append(records, []string{r.Domain, r.Region, r.CheckedAt.UTC().Format(time.RFC3339),
+			strconv.FormatBool(r.Available), strconv.Itoa(r.StatusCode), strconv.Itoa(r.ErrorCode),
+			strconv.Itoa(r.TotalTime), r.ErrorDescription})
+	}
+	return writeCSV(records), len(rows), nil
+}
+
+func exportNotifications(db *sqlx.DB, userID int, from, to time.Time) ([]byte, int, error) {
+	var rows []struct {
+		Domain           string    `db:"name"`
+		Region           string    `db:"region"`
+		Type             string    `db:"notification_type"`
+		Channel          string    `db:"channel"`
+		StatusCode       int       `db:"status_code"`
+		ErrorDescription string    `db:"error_description"`
+		NotifiedAt       time.Time `db:"notified_at"`
+	}
+	err := db.Select(&rows, `
+        SELECT d.name, COALESCE(d.region, '') AS region, nh.notification_type,
+               CASE WHEN nh.email_config_id IS NOT NULL THEN 'email' ELSE 'telegram' END AS channel,
+               nh.status_code, COALESCE(nh.error_description, '') AS error_description, nh.notified_at
+        FROM notification_history nh
+        JOIN domains d ON d.id = nh.domain_id
+        WHERE d.user_id = $1 AND nh.notified_at >= $2 AND nh.notified_at < $3
+        ORDER BY nh.notified_at
+    `, userID, from, to)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get notification history: %w", err)
+	}
+
+	records := [][]string{{"domain", "region", "type", "channel", "status_code", "error", "notified_at"}}
+	for _, r := range rows {
+		records = append(records, []string{r.Domain, r.Region, r.Type, r.Channel, strconv.Itoa(r.StatusCode),
+			r.ErrorDescription, r.NotifiedAt.UTC().Format(time.RFC3339)})
+	}
+	return writeCSV(records), len(rows), nil
+}
+
+// exportIncidents derives outages from runs of failed checks within the period. An
+// outage still ongoing at the end of the period has no resolved_at.
+func exportIncidents(db *sqlx.DB, userID int, from, to time.Time) ([]byte, int, error) {
+	rows, err := selectChecks(db, userID, from, to)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	records := [][]string{{"domain", "region", "started_at", "resolved_at", "duration_seconds", "failed_checks", "last_status_code", "last_error"}}
+	var open *checkRow
+	var failed int
+	var last checkRow
+	flush := func(resolvedAt *time.Time) {
+		resolved, duration := "", ""
+		if resolvedAt != nil {
+			resolved = resolvedAt.UTC().Format(time.RFC3339)
+			duration = strconv.Itoa(int(resolvedAt.Sub(open.CheckedAt).Seconds()))
+		}
+		records = append(records, []string{open.Domain, open.Region, open.CheckedAt.UTC().Format(time.RFC3339),
+			resolved, duration, strconv.Itoa(failed), strconv.Itoa(last.StatusCode), last.ErrorDescription})
+		open = nil
+	}
+
+	for i := range rows {
+		r := rows[i]
+		if open != nil && r.DomainID != open.DomainID {
+			flush(nil)
+		}
+		if !r.Available {
+			if open == nil {
+				open, failed = &rows[i], 0
+			}
+			failed++
+			last = r
+		} else if open != nil {
+			flush(&r.CheckedAt)
+		}
+	}
+	if open != nil {
+		flush(nil)
+	}
+	return writeCSV(records), len(records) - 1, nil
+}
+
+func writeCSV(records [][]string) []byte {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.WriteAll(records)
+	return buf.Bytes()
+}
@@ -0,0 +1,15 @@
+package export
+
+import "errors"
+
+// Errors returned by the export service for requests that cannot be applied. Handlers
+// match them with errors.Is to choose the response.
+var (
+	ErrTargetNotFound        = errors.New("export target not found")
+	ErrAccountExportNotFound = errors.New("export not found")
+
+	// Export targets that cannot be saved
+	ErrCredentialsRequired       = errors.New("password or private key is required")
+	ErrInvalidHostKeyFingerprint = errors.New("invalid host key fingerprint")
+	ErrInvalidPrivateKey         = errors.New("invalid private key")
+)
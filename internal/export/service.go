@@ -0,0 +1,337 @@
+package export
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"path"
+	"sync"
+	"time"
+
+	"domain-detection-go/internal/credentials"
+	"domain-detection-go/pkg/model"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"golang.org/x/crypto/ssh"
+)
+
+// Delivery retry settings. The wait doubles after every failed attempt.
+const (
+	maxExportAttempts = 6
+	exportRetryBase   = 5 * time.Minute
+)
+
+// exportTargetRow is an export target as stored, including its encrypted credentials
+type exportTargetRow struct {
+	model.ExportTarget
+	Datasets            pq.StringArray `db:"datasets"`
+	PasswordEncrypted   sql.NullString `db:"password_encrypted"`
+	PrivateKeyEncrypted sql.NullString `db:"private_key_encrypted"`
+}
+
+func (r exportTargetRow) toModel() model.ExportTarget {
+	target := r.ExportTarget
+	target.Datasets = []string(r.Datasets)
+	return target
+}
+
+const exportTargetColumns = `id, user_id, name, host, port, username,
+        password_encrypted IS NOT NULL AS has_password, private_key_encrypted IS NOT NULL AS has_private_key,
+        password_encrypted, private_key_encrypted, host_key_fingerprint, remote_dir, datasets,
+        delivery_hour, is_active, created_at, updated_at`
+
+// ExportService delivers daily dumps of monitoring data to customer SFTP servers
+type ExportService struct {
+	db            *sqlx.DB
+	encryptionKey string
+	mailer        AccountExportMailer
+	publicBaseURL string
+	builds        sync.WaitGroup // Account exports being built in the background
+}
+
+// NewExportService creates a new export service
+func NewExportService(db *sqlx.DB, encryptionKey string) *ExportService {
+	return &ExportService{db: db, encryptionKey: encryptionKey}
+}
+
+// GetTargets returns every export target of a user
+func (s *ExportService) GetTargets(userID int) ([]model.ExportTarget, error) {
+	var rows []exportTargetRow
+	err := s.db.Select(&rows, "SELECT "+exportTargetColumns+" FROM export_targets WHERE user_id = $1 ORDER BY id", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get export targets: %w", err)
+	}
+
+	targets := make([]model.ExportTarget, len(rows))
+	for i, row := range rows {
+		targets[i] = row.toModel()
+	}
+	return targets, nil
+}
+
+// AddTarget creates an export target for a user
+func (s *ExportService) AddTarget(userID int, req model.ExportTargetRequest) (int, error) {
+	if req.Password == "" && req.PrivateKey == "" {
+		return 0, ErrCredentialsRequired
+	}
+	password, privateKey, err := s.sealCredentials(req)
+	if err != nil {
+		return 0, err
+	}
+
+	var targetID int
+	err = s.db.Get(&targetID, `
+        INSERT INTO export_targets
+        (user_id, name, host, port, username, password_encrypted, private_key_encrypted, host_key_fingerprint,
+         remote_dir, datasets, delivery_hour, is_active, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW(), NOW())
+        RETURNING id
+    `, userID, req.Name, req.Host, portOrDefault(req.Port), req.Username, password, privateKey, req.HostKeyFingerprint,
+		remoteDirOrDefault(req.RemoteDir), pq.Array(req.Datasets), req.DeliveryHour, req.IsActive == nil || *req.IsActive)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add export target: %w", err)
+	}
+	return targetID, nil
+}
+
+// UpdateTarget replaces the settings of an export target. Credentials not given in the
+// request are kept.
+func (s *ExportService) UpdateTarget(targetID, userID int, req model.ExportTargetRequest) error {
+	password, privateKey, err := s.sealCredentials(req)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.db.Exec(`
+        UPDATE export_targets
+        SET name = $1, host = $2, port = $3, username = $4,
+            password_encrypted = COALESCE($5, password_encrypted),
+            private_key_encrypted = COALESCE($6, private_key_encrypted),
+            host_key_fingerprint = $7, remote_dir = $8, datasets = $9, delivery_hour = $10,
+            is_active = COALESCE($11, is_active), updated_at = NOW()
+        WHERE id = $12 AND user_id = $13
+    `, req.Name, req.Host, portOrDefault(req.Port), req.Username, password, privateKey, req.HostKeyFingerprint,
+		remoteDirOrDefault(req.RemoteDir), pq.Array(req.Datasets), req.DeliveryHour, req.IsActive, targetID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update export target: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrTargetNotFound
+	}
+	return nil
+}
+
+// DeleteTarget removes an export target and its delivery history
+func (s *ExportService) DeleteTarget(targetID, userID int) error {
+	result, err := s.db.Exec("DELETE FROM export_targets WHERE id = $1 AND user_id = $2", targetID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete export target: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrTargetNotFound
+	}
+	return nil
+}
+
+// GetDeliveries returns the most recent deliveries of a user's targets, optionally
+// limited to one target
+func (s *ExportService) GetDeliveries(userID, targetID, limit int) ([]model.ExportDelivery, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+
+	deliveries := []model.ExportDelivery{}
+	err := s.db.Select(&deliveries, `
+        SELECT e.id, e.target_id, e.dataset, e.period_start, e.period_end, e.file_name, e.status, e.attempts,
+               e.rows_exported, e.last_error, e.next_attempt_at, e.delivered_at, e.created_at
+        FROM export_deliveries e
+        JOIN export_targets t ON t.id = e.target_id
+        WHERE t.user_id = $1 AND ($2 = 0 OR e.target_id = $2)
+        ORDER BY e.created_at DESC, e.id DESC
+        LIMIT $3
+    `, userID, targetID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get export deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// RunExports schedules yesterday's files for every target past its delivery hour and
+// delivers every file that is due, until ctx is done
+func (s *ExportService) RunExports(ctx context.Context) error {
+	now := time.Now().UTC()
+	periodEnd := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	periodStart := periodEnd.AddDate(0, 0, -1)
+
+	_, err := s.db.Exec(`
+        INSERT INTO export_deliveries (target_id, dataset, period_start, period_end, file_name, created_at)
+        SELECT t.id, ds.dataset, $1::timestamptz, $2::timestamptz, ds.dataset || '-' || to_char($1::timestamptz AT TIME ZONE 'UTC', 'YYYY-MM-DD') || '.csv', NOW()
+        FROM export_targets t, unnest(t.datasets) AS ds(dataset)
+        WHERE t.is_active = true AND t.delivery_hour <= $3
+        ON CONFLICT (target_id, dataset, period_start) DO NOTHING
+    `, periodStart, periodEnd, now.Hour())
+	if err != nil {
+		return fmt.Errorf("failed to schedule exports: %w", err)
+	}
+
+	var due []model.ExportDelivery
+	err = s.db.Select(&due, `
+        SELECT e.id, e.target_id, e.dataset, e.period_start, e.period_end, e.file_name, e.status, e.attempts,
+               e.rows_exported, e.last_error, e.next_attempt_at, e.delivered_at, e.created_at
+        FROM export_deliveries e
+        JOIN export_targets t ON t.id = e.target_id
+        WHERE e.status = 'pending' AND e.next_attempt_at <= NOW() AND t.is_active = true
+        ORDER BY e.next_attempt_at
+    `)
+	if err != nil {
+		return fmt.Errorf("failed to get due exports: %w", err)
+	}
+
+	for _, delivery := range due {
+		rows, err := s.deliver(ctx, delivery)
+		if err != nil && ctx.Err() != nil {
+			// Interrupted by shutdown, not the target's fault; the next run retries it
+			return nil
+		}
+		s.recordAttempt(delivery, rows, err)
+	}
+	return nil
+}
+
+// RunScheduledExports delivers customer exports as they become due and drops expired
+// account exports
+func (s *ExportService) RunScheduledExports(ctx context.Context) {
+	slog.Info("RunScheduledExports")
+	ticker := time.NewTicker(10 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("RunScheduledExports stopped")
+			return
+		case <-ticker.C:
+			if err := s.RunExports(ctx); err != nil {
+				slog.Error("Export run failed", "error", err)
+			}
+			if err := s.ExpireAccountExports(); err != nil {
+				slog.Error("Failed to expire account exports", "error", err)
+			}
+		}
+	}
+}
+
+// deliver builds the file of a delivery and uploads it, returning the number of rows
+func (s *ExportService) deliver(ctx context.Context, delivery model.ExportDelivery) (int, error) {
+	var row exportTargetRow
+	err := s.db.Get(&row, "SELECT "+exportTargetColumns+" FROM export_targets WHERE id = $1", delivery.TargetID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get export target: %w", err)
+	}
+
+	build, ok := datasetWriters[delivery.Dataset]
+	if !ok {
+		return 0, fmt.Errorf("unknown dataset %s", delivery.Dataset)
+	}
+	data, rows, err := build(s.db, row.UserID, delivery.PeriodStart, delivery.PeriodEnd)
+	if err != nil {
+		return 0, err
+	}
+
+	target := SFTPTarget{
+		Host:               row.Host,
+		Port:               row.Port,
+		Username:           row.Username,
+		HostKeyFingerprint: row.HostKeyFingerprint,
+	}
+	if row.PasswordEncrypted.Valid {
+		if target.Password, err = credentials.Open(row.PasswordEncrypted.String, s.encryptionKey); err != nil {
+			return 0, fmt.Errorf("failed to decrypt password: %w", err)
+		}
+	}
+	if row.PrivateKeyEncrypted.Valid {
+		if target.PrivateKey, err = credentials.Open(row.PrivateKeyEncrypted.String, s.encryptionKey); err != nil {
+			return 0, fmt.Errorf("failed to decrypt private key: %w", err)
+		}
+	}
+
+	if err := UploadSFTP(ctx, target, path.Join(row.RemoteDir, delivery.FileName), data); err != nil {
+		return 0, err
+	}
+	return rows, nil
+}
+
+// recordAttempt stores the outcome of a delivery attempt, scheduling a retry on failure
+func (s *ExportService) recordAttempt(delivery model.ExportDelivery, rows int, deliveryErr error) {
+	var err error
+	if deliveryErr == nil {
+		_, err = s.db.Exec(`
+            UPDATE export_deliveries
+            SET status = 'delivered', attempts = attempts + 1, rows_exported = $1, last_error = NULL,
+                next_attempt_at = NULL, delivered_at = NOW()
+            WHERE id = $2
+        `, rows, delivery.ID)
+	} else {
+		attempts := delivery.Attempts + 1
+		status := model.ExportStatusPending
+		if attempts >= maxExportAttempts {
+			status = model.ExportStatusFailed
+		}
+		slog.Warn("Export delivery failed", "delivery_id", delivery.ID, "file", delivery.FileName,
+			"target_id", delivery.TargetID, "attempt", attempts, "error", deliveryErr)
+
+		_, err = s.db.Exec(`
+            UPDATE export_deliveries
+            SET status = $1, attempts = $2, last_error = $3, next_attempt_at = NOW() + make_interval(secs => $4)
+            WHERE id = $5
+        `, status, attempts, deliveryErr.Error(), int(exportRetryBase.Seconds())<<(attempts-1), delivery.ID)
+	}
+	if err != nil {
+		slog.Error("Failed to record export delivery", "delivery_id", delivery.ID, "error", err)
+	}
+}
+
+// sealCredentials encrypts the credentials of a request, returning nil for those not given
+func (s *ExportService) sealCredentials(req model.ExportTargetRequest) (interface{}, interface{}, error) {
+	if !ValidHostKeyFingerprint(req.HostKeyFingerprint) {
+		return nil, nil, ErrInvalidHostKeyFingerprint
+	}
+	if req.PrivateKey != "" {
+		if _, err := ssh.ParsePrivateKey([]byte(req.PrivateKey)); err != nil {
+			return nil, nil, ErrInvalidPrivateKey
+		}
+	}
+
+	var password, privateKey interface{}
+	if req.Password != "" {
+		sealed, err := credentials.Seal(req.Password, s.encryptionKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encrypt password: %w", err)
+		}
+		password = sealed
+	}
+	if req.PrivateKey != "" {
+		sealed, err := credentials.Seal(req.PrivateKey, s.encryptionKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encrypt private key: %w", err)
+		}
+		privateKey = sealed
+	}
+	return password, privateKey, nil
+}
+
+func portOrDefault(port int) int {
+	if port == 0 {
+		return 22
+	}
+	return port
+}
+
+func remoteDirOrDefault(dir string) string {
+	if dir == "" {
+		return "."
+	}
+	return dir
+}
@@ -0,0 +1,347 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"domain-detection-go/internal/netguard"
+)
+
+// SFTP protocol version 3 packet types and flags used by the uploader
+const (
+	sftpInit    = 1
+	sftpVersion = 2
+	sftpOpen    = 3
+	sftpClose   = 4
+	sftpWrite   = 6
+	sftpRemove  = 13
+	sftpRename  = 18
+	sftpStatus  = 101
+	sftpHandle  = 102
+
+	sftpStatusOK         = 0
+	sftpStatusNoSuchFile = 2
+
+	sftpFlagWrite    = 0x02
+	sftpFlagCreate   = 0x08
+	sftpFlagTruncate = 0x10
+
+	sftpChunkSize   = 32 * 1024
+	sftpDialTimeout = 30 * time.Second
+	// sftpIOTimeout is how long the server may stall a read or write before the upload
+	// is abandoned
+	sftpIOTimeout = 60 * time.Second
+)
+
+// SFTPTarget describes where and how to connect for an upload. One of Password or
+// PrivateKey must be set. Only a server presenting the host key of HostKeyFingerprint is
+// uploaded to.
+type SFTPTarget struct {
+	Host               string
+	Port               int
+	Username           string
+	Password           string
+	PrivateKey         string // PEM encoded
+	HostKeyFingerprint string // SHA256 fingerprint as printed by ssh-keygen -l
+}
+
+// ValidHostKeyFingerprint reports whether fingerprint is a SHA256 host key fingerprint
+// as printed by ssh-keygen -l, e.g. "SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8"
+func ValidHostKeyFingerprint(fingerprint string) bool {
+	digest, ok := strings.CutPrefix(fingerprint, "SHA256:")
+	if !ok {
+		return false
+	}
+	sum, err := base64.RawStdEncoding.DecodeString(digest)
+	return err == nil && len(sum) == sha256.Size
+}
+
+// pinnedHostKey accepts only the host key with the given SHA256 fingerprint
+func pinnedHostKey(fingerprint string) ssh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		if seen := ssh.FingerprintSHA256(key); seen != fingerprint {
+			return fmt.Errorf("host key mismatch: server presented %s", seen)
+		}
+		return nil
+	}
+}
+
+// deadlineConn fails reads and writes on which the server stalls for longer than
+// timeout
+type deadlineConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (c *deadlineConn) Read(b []byte) (int, error) {
+	if err := c.Conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+	return c.Conn.Read(b)
+}
+
+func (c *deadlineConn) Write(b []byte) (int, error) {
+	if err := c.Conn.SetWriteDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+	return c.Conn.Write(b)
+}
+
+// UploadSFTP writes data to remotePath on the target. The file is written under a
+// temporary name and renamed once complete, so readers never see partial files. The
+// upload is abandoned when ctx is done or the server stalls.
+func UploadSFTP(ctx context.Context, target SFTPTarget, remotePath string, data []byte) error {
+	if target.HostKeyFingerprint == "" {
+		return errors.New("no host key fingerprint configured")
+	}
+
+	var auth []ssh.AuthMethod
+	if target.PrivateKey != "" {
+		signer, err := ssh.ParsePrivateKey([]byte(target.PrivateKey))
+		if err != nil {
+			return fmt.Errorf("invalid private key: %w", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if target.Password != "" {
+		auth = append(auth, ssh.Password(target.Password))
+	}
+	if len(auth) == 0 {
+		return errors.New("no SFTP credentials configured")
+	}
+
+	config := &ssh.ClientConfig{
+		User:            target.Username,
+		Auth:            auth,
+		HostKeyCallback: pinnedHostKey(target.HostKeyFingerprint),
+		Timeout:         sftpDialTimeout,
+	}
+
+	port := target.Port
+	if port == 0 {
+		port = 22
+	}
+	addr := net.JoinHostPort(target.Host, strconv.Itoa(port))
+	// The host is customer input: refuse the application's own network
+	netConn, err := netguard.Dialer(sftpDialTimeout).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	// Closing the connection unblocks every read and write of the exchange
+	stop := context.AfterFunc(ctx, func() { netConn.Close() })
+	defer stop()
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(&deadlineConn{Conn: netConn, timeout: sftpIOTimeout}, addr, config)
+	if err != nil {
+		netConn.Close()
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	client := ssh.NewClient(sshConn, chans, reqs)
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to open session: %w", err)
+	}
+	defer session.Close()
+
+	w, err := session.StdinPipe()
+	if err != nil {
+		return err
+	}
+	r, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		return fmt.Errorf("failed to start sftp subsystem: %w", err)
+	}
+
+	conn := &sftpConn{w: w, r: r}
+	if err := conn.init(); err != nil {
+		return err
+	}
+
+	partPath := remotePath + ".part"
+	if err := conn.writeFile(partPath, data); err != nil {
+		return err
+	}
+	return conn.replace(partPath, remotePath)
+}
+
+// sftpConn is a minimal synchronous SFTP v3 client supporting file uploads
+type sftpConn struct {
+	w      io.Writer
+	r      io.Reader
+	nextID uint32
+}
+
+func (c *sftpConn) init() error {
+	if err := c.send(sftpInit, sftpUint32(3)); err != nil {
+		return err
+	}
+	typ, _, err := c.recv()
+	if err != nil {
+		return err
+	}
+	if typ != sftpVersion {
+		return fmt.Errorf("unexpected sftp packet %d during init", typ)
+	}
+	return nil
+}
+
+// writeFile creates or truncates a remote file and writes data to it
+func (c *sftpConn) writeFile(name string, data []byte) error {
+	typ, payload, err := c.request(sftpOpen, sftpString(name),
+		sftpUint32(sftpFlagWrite|sftpFlagCreate|sftpFlagTruncate), sftpUint32(0))
+	if err != nil {
+		return err
+	}
+	if typ != sftpHandle {
+		return fmt.Errorf("failed to open %s: %w", name, statusError(typ, payload))
+	}
+	handle, _ := readString(payload)
+
+	for offset := 0; offset < len(data); offset += sftpChunkSize {
+		end := offset + sftpChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		offsetBytes := make([]byte, 8)
+		binary.BigEndian.PutUint64(offsetBytes, uint64(offset))
+		err := c.expectStatus(c.request(sftpWrite, sftpString(handle), offsetBytes, sftpString(string(data[offset:end]))))
+		if err != nil {
+			c.request(sftpClose, sftpString(handle))
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	if err := c.expectStatus(c.request(sftpClose, sftpString(handle))); err != nil {
+		return fmt.Errorf("failed to close %s: %w", name, err)
+	}
+	return nil
+}
+
+// replace renames oldPath to newPath. SFTP v3 rename fails when the target exists, so it
+// is removed first; a target that does not exist yet is fine.
+func (c *sftpConn) replace(oldPath, newPath string) error {
+	err := c.expectStatus(c.request(sftpRemove, sftpString(newPath)))
+	var statusErr *sftpStatusError
+	if err != nil && !(errors.As(err, &statusErr) && statusErr.Code == sftpStatusNoSuchFile) {
+		return fmt.Errorf("failed to remove %s: %w", path.Base(newPath), err)
+	}
+	if err := c.expectStatus(c.request(sftpRename, sftpString(oldPath), sftpString(newPath))); err != nil {
+		return fmt.Errorf("failed to rename %s: %w", path.Base(oldPath), err)
+	}
+	return nil
+}
+
+// request sends a packet with a fresh request ID and returns the response without its ID
+func (c *sftpConn) request(typ byte, fields ...[]byte) (byte, []byte, error) {
+	c.nextID++
+	id := c.nextID
+	if err := c.send(typ, append([][]byte{sftpUint32(id)}, fields...)...); err != nil {
+		return 0, nil, err
+	}
+
+	respType, payload, err := c.recv()
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(payload) < 4 || binary.BigEndian.Uint32(payload) != id {
+		return 0, nil, errors.New("unexpected sftp response ID")
+	}
+	return respType, payload[4:], nil
+}
+
+func (c *sftpConn) expectStatus(typ byte, payload []byte, err error) error {
+	if err != nil {
+		return err
+	}
+	return statusError(typ, payload)
+}
+
+func (c *sftpConn) send(typ byte, fields ...[]byte) error {
+	var body bytes.Buffer
+	body.WriteByte(typ)
+	for _, f := range fields {
+		body.Write(f)
+	}
+	if _, err := c.w.Write(append(sftpUint32(uint32(body.Len())), body.Bytes()...)); err != nil {
+		return fmt.Errorf("failed to send sftp packet: %w", err)
+	}
+	return nil
+}
+
+func (c *sftpConn) recv() (byte, []byte, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(c.r, header); err != nil {
+		return 0, nil, fmt.Errorf("failed to read sftp packet: %w", err)
+	}
+	length := binary.BigEndian.Uint32(header)
+	if length == 0 || length > 256*1024 {
+		return 0, nil, fmt.Errorf("invalid sftp packet length %d", length)
+	}
+	packet := make([]byte, length)
+	if _, err := io.ReadFull(c.r, packet); err != nil {
+		return 0, nil, fmt.Errorf("failed to read sftp packet: %w", err)
+	}
+	return packet[0], packet[1:], nil
+}
+
+// sftpStatusError is a status response other than OK
+type sftpStatusError struct {
+	Code    uint32
+	Message string
+}
+
+func (e *sftpStatusError) Error() string {
+	return fmt.Sprintf("sftp status %d: %s", e.Code, e.Message)
+}
+
+// statusError converts a response into an error unless it is an OK status
+func statusError(typ byte, payload []byte) error {
+	if typ != sftpStatus || len(payload) < 4 {
+		return fmt.Errorf("unexpected sftp packet %d", typ)
+	}
+	code := binary.BigEndian.Uint32(payload)
+	if code == sftpStatusOK {
+		return nil
+	}
+	msg, _ := readString(payload[4:])
+	return &sftpStatusError{Code: code, Message: msg}
+}
+
+func sftpUint32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	return b
+}
+
+func sftpString(s string) []byte {
+	return append(sftpUint32(uint32(len(s))), s...)
+}
+
+func readString(b []byte) (string, []byte) {
+	if len(b) < 4 {
+		return "", nil
+	}
+	n := binary.BigEndian.Uint32(b)
+	if uint32(len(b)-4) < n {
+		return "", nil
+	}
+	return string(b[4 : 4+n]), b[4+n:]
+}
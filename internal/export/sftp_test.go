@@ -0,0 +1,234 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"domain-detection-go/internal/netguard"
+)
+
+func newHostKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatalf("convert key: %v", err)
+	}
+	return key
+}
+
+func TestPinnedHostKeyRefusesUnknownKeys(t *testing.T) {
+	pinned, other := newHostKey(t), newHostKey(t)
+	callback := pinnedHostKey(ssh.FingerprintSHA256(pinned))
+
+	if err := callback("sftp.example.com:22", nil, pinned); err != nil {
+		t.Errorf("pinned host key refused: %v", err)
+	}
+	if err := callback("sftp.example.com:22", nil, other); err == nil {
+		t.Error("unknown host key accepted")
+	}
+}
+
+func TestUploadSFTPRequiresHostKeyFingerprint(t *testing.T) {
+	err := UploadSFTP(context.Background(), SFTPTarget{Host: "sftp.example.com", Username: "u", Password: "p"}, "/out.csv", nil)
+	if err == nil {
+		t.Fatal("upload without a host key fingerprint was attempted")
+	}
+}
+
+func TestUploadSFTPRefusesInternalAddresses(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	target := SFTPTarget{Host: "127.0.0.1", Port: port, Username: "u", Password: "p", HostKeyFingerprint: ssh.FingerprintSHA256(newHostKey(t))}
+	err = UploadSFTP(context.Background(), target, "/out.csv", nil)
+	if !errors.Is(err, netguard.ErrInternalAddress) {
+		t.Errorf("upload error = %v, want %v", err, netguard.ErrInternalAddress)
+	}
+}
+
+func TestValidHostKeyFingerprint(t *testing.T) {
+	valid := ssh.FingerprintSHA256(newHostKey(t))
+	for fingerprint, want := range map[string]bool{
+		valid:                            true,
+		"":                               false,
+		"MD5:16:27:ac:a5:76:28:2d:36:63": false,
+		"SHA256:not-base64!":             false,
+		"SHA256:c2hvcnQ":                 false,
+	} {
+		if got := ValidHostKeyFingerprint(fingerprint); got != want {
+			t.Errorf("ValidHostKeyFingerprint(%q) = %v, want %v", fingerprint, got, want)
+		}
+	}
+}
+
+// sftpPacket frames a packet as the server sends it
+func sftpPacket(typ byte, fields ...[]byte) []byte {
+	body := []byte{typ}
+	for _, f := range fields {
+		body = append(body, f...)
+	}
+	return append(sftpUint32(uint32(len(body))), body...)
+}
+
+// sftpStatusPacket is a status response to request id
+func sftpStatusPacket(id, code uint32, message string) []byte {
+	return sftpPacket(sftpStatus, sftpUint32(id), sftpUint32(code), sftpString(message), sftpString(""))
+}
+
+func TestSFTPConnSendFramesPackets(t *testing.T) {
+	var sent bytes.Buffer
+	conn := &sftpConn{w: &sent}
+	if err := conn.send(sftpOpen, sftpUint32(7), sftpString("out.csv")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []byte{0, 0, 0, 16, sftpOpen, 0, 0, 0, 7, 0, 0, 0, 7, 'o', 'u', 't', '.', 'c', 's', 'v'}
+	if !bytes.Equal(sent.Bytes(), want) {
+		t.Errorf("sent % x, want % x", sent.Bytes(), want)
+	}
+}
+
+func TestSFTPConnRecv(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []byte
+		typ     byte
+		payload []byte
+		wantErr string
+	}{
+		{name: "packet", input: sftpPacket(sftpHandle, sftpUint32(1), sftpString("h")), typ: sftpHandle, payload: append(sftpUint32(1), sftpString("h")...)},
+		{name: "empty packet", input: sftpUint32(0), wantErr: "invalid sftp packet length 0"},
+		{name: "oversized packet", input: sftpUint32(1 << 20), wantErr: "invalid sftp packet length"},
+		{name: "truncated header", input: []byte{0, 0}, wantErr: "failed to read sftp packet"},
+		{name: "truncated body", input: append(sftpUint32(10), sftpStatus), wantErr: "failed to read sftp packet"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := &sftpConn{r: bytes.NewReader(tt.input)}
+			typ, payload, err := conn.recv()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("recv error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if typ != tt.typ || !bytes.Equal(payload, tt.payload) {
+				t.Errorf("recv = %d % x, want %d % x", typ, payload, tt.typ, tt.payload)
+			}
+		})
+	}
+}
+
+func TestSFTPConnRequestChecksResponseID(t *testing.T) {
+	var sent bytes.Buffer
+	conn := &sftpConn{w: &sent, r: bytes.NewReader(sftpStatusPacket(2, sftpStatusOK, ""))}
+	if _, _, err := conn.request(sftpClose, sftpString("h")); err == nil {
+		t.Error("response to another request accepted")
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	if err := statusError(sftpStatus, append(sftpUint32(sftpStatusOK), sftpString("")...)); err != nil {
+		t.Errorf("OK status = %v, want nil", err)
+	}
+
+	err := statusError(sftpStatus, append(sftpUint32(3), sftpString("Permission denied")...))
+	var statusErr *sftpStatusError
+	if !errors.As(err, &statusErr) || statusErr.Code != 3 || statusErr.Message != "Permission denied" {
+		t.Errorf("failure status = %v, want code 3 with its message", err)
+	}
+
+	if err := statusError(sftpHandle, sftpString("h")); err == nil || errors.As(err, &statusErr) {
+		t.Errorf("non-status packet = %v, want an unexpected packet error", err)
+	}
+	if err := statusError(sftpStatus, []byte{0, 0}); err == nil {
+		t.Error("truncated status accepted")
+	}
+}
+
+func TestReadString(t *testing.T) {
+	s, rest := readString(append(sftpString("abc"), 'x'))
+	if s != "abc" || string(rest) != "x" {
+		t.Errorf("readString = %q, %q, want \"abc\", \"x\"", s, rest)
+	}
+	for _, input := range [][]byte{nil, {0, 0}, append(sftpUint32(5), "abc"...)} {
+		if s, rest := readString(input); s != "" || rest != nil {
+			t.Errorf("readString(% x) = %q, % x, want empty", input, s, rest)
+		}
+	}
+}
+
+func TestSFTPConnReplace(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses [][]byte
+		wantErr   string
+	}{
+		{
+			name:      "existing file",
+			responses: [][]byte{sftpStatusPacket(1, sftpStatusOK, ""), sftpStatusPacket(2, sftpStatusOK, "")},
+		},
+		{
+			name:      "new file",
+			responses: [][]byte{sftpStatusPacket(1, sftpStatusNoSuchFile, "No such file"), sftpStatusPacket(2, sftpStatusOK, "")},
+		},
+		{
+			name:      "remove refused",
+			responses: [][]byte{sftpStatusPacket(1, 3, "Permission denied")},
+			wantErr:   "failed to remove out.csv",
+		},
+		{
+			name:      "rename refused",
+			responses: [][]byte{sftpStatusPacket(1, sftpStatusOK, ""), sftpStatusPacket(2, 4, "Failure")},
+			wantErr:   "failed to rename out.csv.part",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent bytes.Buffer
+			conn := &sftpConn{w: &sent, r: bytes.NewReader(bytes.Join(tt.responses, nil))}
+			err := conn.replace("/in/out.csv.part", "/in/out.csv")
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("replace = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("replace error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDeadlineConnTimesOutStalledReads(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	conn := &deadlineConn{Conn: client, timeout: 50 * time.Millisecond}
+	defer conn.Close()
+
+	_, err := conn.Read(make([]byte, 1))
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("read from a stalled server = %v, want a timeout", err)
+	}
+}
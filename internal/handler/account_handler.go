@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"domain-detection-go/internal/auth"
+	"domain-detection-go/internal/domain"
+	"domain-detection-go/pkg/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccountHandler handles users deleting their own account
+type AccountHandler struct {
+	authService   *auth.AuthService
+	domainService *domain.DomainService
+}
+
+// NewAccountHandler creates a new account handler
+func NewAccountHandler(authService *auth.AuthService, domainService *domain.DomainService) *AccountHandler {
+	return &AccountHandler{
+		authService:   authService,
+		domainService: domainService,
+	}
+}
+
+// DeleteAccount handles DELETE /api/user. The account is locked right away, signing the
+// user out everywhere and revoking their API keys, and deleted in the background.
+func (h *AccountHandler) DeleteAccount(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req model.AccountDeletionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	user, err := h.authService.ConfirmAccountDeletion(userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrTwoFactorRequired):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "2FA code required", "require_2fa": true})
+		case errors.Is(err, auth.ErrIncorrectPassword),
+			errors.Is(err, auth.ErrInvalidTwoFactorCode),
+			errors.Is(err, auth.ErrInvalidRecoveryCode):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to confirm account deletion"})
+		}
+		return
+	}
+
+	deletion, err := h.domainService.RequestAccountDeletion(c.Request.Context(), userID, user.Username)
+	if err != nil {
+		if errors.Is(err, errAccountDeletionInProgress) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Account deletion already in progress"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, deletion)
+}
+
+// GetAccountDeletion handles GET /api/user/deletion
+func (h *AccountHandler) GetAccountDeletion(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	deletion, err := h.domainService.GetAccountDeletion(userID)
+	if err != nil {
+		if errors.Is(err, errAccountDeletionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No account deletion requested"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get account deletion"})
+		return
+	}
+
+	c.JSON(http.StatusOK, deletion)
+}
@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"domain-detection-go/internal/auth"
 	"domain-detection-go/pkg/model"
@@ -29,9 +32,9 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	user, token, err := h.authService.Login(creds)
+	user, tokens, err := h.authService.Login(creds, c.Request.UserAgent(), c.ClientIP())
 	if err != nil {
-		if err.Error() == "2fa_required" {
+		if errors.Is(err, auth.ErrTwoFactorRequired) {
 			// Special case: 2FA is enabled, but code not provided
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":       "2FA code required",
@@ -44,7 +47,9 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"token": token,
+		"token":         tokens.Token,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
 		"user": gin.H{
 			"id":          user.ID,
 			"username":    user.Username,
@@ -86,13 +91,17 @@ func (h *AuthHandler) VerifyTwoFactor(c *gin.Context) {
 		return
 	}
 
-	err := h.authService.VerifyAndEnableTwoFactor(userID, req.TOTPCode)
+	recoveryCodes, err := h.authService.VerifyAndEnableTwoFactor(userID, req.TOTPCode)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication enabled"})
+	// The recovery codes are shown only once
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Two-factor authentication enabled",
+		"recovery_codes": recoveryCodes,
+	})
 }
 
 // DisableTwoFactor disables 2FA for a user
@@ -112,6 +121,51 @@ func (h *AuthHandler) DisableTwoFactor(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
 }
 
+// GetRecoveryCodes handles GET /api/2fa/recovery-codes
+func (h *AuthHandler) GetRecoveryCodes(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	remaining, err := h.authService.CountRecoveryCodes(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get recovery codes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.RecoveryCodesStatus{Remaining: remaining})
+}
+
+// RegenerateRecoveryCodes handles POST /api/2fa/recovery-codes
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req model.TwoFactorVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	codes, err := h.authService.RegenerateRecoveryCodes(userID, req.TOTPCode)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrTwoFactorNotEnabled), errors.Is(err, auth.ErrInvalidTwoFactorCode):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to regenerate recovery codes"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, model.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
 // GetUserProfile returns the current user's profile data
 func (h *AuthHandler) GetUserProfile(c *gin.Context) {
 	userID := c.GetInt("user_id") // Set by auth middleware
@@ -126,12 +180,18 @@ func (h *AuthHandler) GetUserProfile(c *gin.Context) {
 		return
 	}
 
+	timezone := model.DefaultTimezone
+	if user.Timezone.Valid && user.Timezone.String != "" {
+		timezone = user.Timezone.String
+	}
+
 	// Return only needed fields (don't send sensitive data)
 	c.JSON(http.StatusOK, gin.H{
 		"username":         user.Username,
 		"email":            user.Email,
 		"twoFactorEnabled": user.TwoFactorEnabled,
 		"region":           user.Region,
+		"timezone":         timezone,
 	})
 }
 
@@ -152,9 +212,10 @@ func (h *AuthHandler) UpdatePassword(c *gin.Context) {
 	}
 
 	// Call service method
-	err := h.authService.UpdatePassword(userID, req.CurrentPassword, req.NewPassword)
+	// Tokens issued before sessions existed carry no session ID, all sessions are revoked then
+	err := h.authService.UpdatePassword(userID, c.GetInt("session_id"), req.CurrentPassword, req.NewPassword)
 	if err != nil {
-		if err.Error() == "incorrect current password" {
+		if errors.Is(err, auth.ErrIncorrectCurrentPassword) {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
 			return
 		}
@@ -164,3 +225,223 @@ func (h *AuthHandler) UpdatePassword(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Password updated successfully"})
 }
+
+// UpdateTimezone handles PUT /api/user/timezone
+func (h *AuthHandler) UpdateTimezone(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req model.TimezoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.authService.UpdateTimezone(userID, req.Timezone); err != nil {
+		if strings.HasPrefix(err.Error(), "invalid timezone") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update timezone"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Timezone updated successfully"})
+}
+
+// CreateAPIKey handles POST /api/apikeys
+func (h *AuthHandler) CreateAPIKey(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req model.APIKeyCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	key, err := h.authService.CreateAPIKey(userID, req.Name)
+	if err != nil {
+		if errors.Is(err, auth.ErrAPIKeyLimitReached) {
+			c.JSON(http.StatusConflict, gin.H{"error": "API key limit reached"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+// GetAPIKeys handles GET /api/apikeys
+func (h *AuthHandler) GetAPIKeys(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	keys, err := h.authService.GetAPIKeys(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API keys"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_keys": keys})
+}
+
+// DeleteAPIKey handles DELETE /api/apikeys/:id
+func (h *AuthHandler) DeleteAPIKey(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	keyID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+		return
+	}
+
+	if err := h.authService.DeleteAPIKey(userID, keyID); err != nil {
+		if errors.Is(err, auth.ErrAPIKeyNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete API key"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key deleted"})
+}
+
+// RefreshToken handles POST /api/token/refresh
+func (h *AuthHandler) RefreshToken(c *gin.Context) {
+	var req model.TokenRefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	tokens, err := h.authService.RefreshSession(req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidRefreshToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout handles POST /api/logout by revoking the session of the current token
+func (h *AuthHandler) Logout(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	sessionID := c.GetInt("session_id")
+	if sessionID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token is not bound to a session"})
+		return
+	}
+
+	if err := h.authService.RevokeSession(userID, sessionID); err != nil && !errors.Is(err, auth.ErrSessionNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// GetSessions handles GET /api/sessions
+func (h *AuthHandler) GetSessions(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	sessions, err := h.authService.GetSessions(userID, c.GetInt("session_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+// RevokeSession handles DELETE /api/sessions/:id
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	sessionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	if err := h.authService.RevokeSession(userID, sessionID); err != nil {
+		if errors.Is(err, auth.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully"})
+}
+
+// Impersonate handles POST /api/admin/impersonate/:user_id
+func (h *AuthHandler) Impersonate(c *gin.Context) {
+	adminID := c.GetInt("user_id")
+	if adminID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req model.ImpersonationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := h.authService.Impersonate(adminID, c.GetInt("session_id"), userID, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		case errors.Is(err, auth.ErrSelfImpersonation):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, auth.ErrImpersonationNeedsSession):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Log in again to impersonate users"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue impersonation token"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"domain-detection-go/internal/service"
+	"domain-detection-go/pkg/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BillingHandler handles usage statement and unit price requests
+type BillingHandler struct {
+	billingService *service.BillingService
+}
+
+// NewBillingHandler creates a new billing handler
+func NewBillingHandler(billingService *service.BillingService) *BillingHandler {
+	return &BillingHandler{
+		billingService: billingService,
+	}
+}
+
+// GetStatement handles GET /api/billing/statement?month=YYYY-MM&format=json|csv|pdf
+func (h *BillingHandler) GetStatement(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	h.writeStatement(c, userID)
+}
+
+// GetUserStatement handles GET /api/admin/billing/statements/:user_id
+func (h *BillingHandler) GetUserStatement(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	h.writeStatement(c, userID)
+}
+
+// GetStatements handles GET /api/admin/billing/statements?month=YYYY-MM&format=json|csv
+func (h *BillingHandler) GetStatements(c *gin.Context) {
+	month := c.Query("month")
+
+	statements, err := h.billingService.GetStatements(month)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		c.JSON(http.StatusOK, gin.H{"statements": statements})
+	case "csv":
+		var buf bytes.Buffer
+		if err := service.WriteStatementCSV(&buf, statements...); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		filename := "statements.csv"
+		if len(statements) > 0 {
+			filename = fmt.Sprintf("statements-%s.csv", statements[0].Month)
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Data(http.StatusOK, "text/csv", buf.Bytes())
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+	}
+}
+
+// GetPrices handles GET /api/admin/billing/prices
+func (h *BillingHandler) GetPrices(c *gin.Context) {
+	prices, err := h.billingService.GetPrices()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"prices": prices})
+}
+
+// UpdatePrices handles PUT /api/admin/billing/prices/:plan
+func (h *BillingHandler) UpdatePrices(c *gin.Context) {
+	var req model.BillingUnitPricesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.billingService.UpsertPrices(c.Param("plan"), req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Unit prices updated successfully"})
+}
+
+// writeStatement builds a user's statement and writes it in the requested format
+func (h *BillingHandler) writeStatement(c *gin.Context, userID int) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" && format != "pdf" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json, csv or pdf"})
+		return
+	}
+
+	statement, err := h.billingService.GetStatement(userID, c.Query("month"))
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("statement-%d-%s.%s", statement.UserID, statement.Month, format)
+	switch format {
+	case "csv":
+		var buf bytes.Buffer
+		if err := service.WriteStatementCSV(&buf, *statement); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Data(http.StatusOK, "text/csv", buf.Bytes())
+	case "pdf":
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Data(http.StatusOK, "application/pdf", service.StatementPDF(*statement))
+	default:
+		c.JSON(http.StatusOK, statement)
+	}
+}
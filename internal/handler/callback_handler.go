@@ -2,10 +2,11 @@ package handler
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
-	"os"
+	"strings"
 	"time"
 
 	"domain-detection-go/internal/deepcheck"
@@ -20,37 +21,45 @@ import (
 // CallbackHandler handles callback requests
 type CallbackHandler struct {
 	domainService    *domain.DomainService
-	telegramService  *notification.TelegramService
-	emailService     *notification.EmailService
+	notifiers        *notification.Fanout
 	deepCheckService *service.DeepCheckService
+	telegramService  *notification.TelegramService
+	callbackSecret   string
 }
 
 // NewCallbackHandler creates a new callback handler
 func NewCallbackHandler(
 	domainService *domain.DomainService,
-	telegramService *notification.TelegramService,
-	emailService *notification.EmailService,
+	notifiers *notification.Fanout,
 	deepCheckService *service.DeepCheckService,
+	telegramService *notification.TelegramService,
+	callbackSecret string,
 ) *CallbackHandler {
 	return &CallbackHandler{
 		domainService:    domainService,
-		telegramService:  telegramService,
-		emailService:     emailService,
+		notifiers:        notifiers,
 		deepCheckService: deepCheckService,
+		telegramService:  telegramService,
+		callbackSecret:   callbackSecret,
 	}
 }
 
+// callbackSecretHeaders are never written to the logs
+var callbackSecretHeaders = []string{"X-Callback-Secret", "Authorization", "Cookie"}
+
 // HandleCallback logs the incoming request and processes deep check callbacks
 func (h *CallbackHandler) HandleCallback(c *gin.Context) {
+	logger := requestLogger(c).With("component", "callback")
+
 	// Check for secret header
 	secretHeader := c.GetHeader("X-Callback-Secret")
-	expectedSecret := os.Getenv("CALLBACK_SECRET")
+	expectedSecret := h.callbackSecret
 
 	// If no secret is configured, skip authentication
 	if expectedSecret == "" {
-		log.Printf("[CALLBACK] WARNING: No CALLBACK_SECRET configured, skipping authentication")
+		logger.Warn("No CALLBACK_SECRET configured, skipping authentication")
 	} else if secretHeader != expectedSecret {
-		log.Printf("[CALLBACK] UNAUTHORIZED: Invalid or missing secret header from IP: %s", c.ClientIP())
+		logger.Warn("Rejected callback with invalid or missing secret", "client_ip", c.ClientIP())
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"status":  "error",
 			"message": "Unauthorized",
@@ -58,18 +67,11 @@ func (h *CallbackHandler) HandleCallback(c *gin.Context) {
 		return
 	}
 
-	// Generate simple request ID
-	requestID := time.Now().Format("20060102-150405-000")
-
-	// Log basic request info
-	log.Printf("[CALLBACK-%s] Method: %s, URL: %s", requestID, c.Request.Method, c.Request.URL.String())
-	log.Printf("[CALLBACK-%s] Remote IP: %s", requestID, c.ClientIP())
-	log.Printf("[CALLBACK-%s] Headers: %v", requestID, c.Request.Header)
+	logger.Debug("Callback received", "client_ip", c.ClientIP(), "headers", redactHeaders(c.Request.Header))
 
-	// Read and log the request body
 	body, err := io.ReadAll(c.Request.Body)
 	if err != nil {
-		log.Printf("[CALLBACK-%s] ERROR reading body: %v", requestID, err)
+		logger.Error("Failed to read callback body", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{
 			"status":  "error",
 			"message": "Failed to read request body",
@@ -77,17 +79,12 @@ func (h *CallbackHandler) HandleCallback(c *gin.Context) {
 		return
 	}
 
-	// Log the body
-	log.Printf("[CALLBACK-%s] Body: %s", requestID, string(body))
-
 	// Try to parse as deep check callback
 	var deepCheckCallback deepcheck.DeepCheckCallbackRequest
 	if err := json.Unmarshal(body, &deepCheckCallback); err == nil && deepCheckCallback.OrderID != "" {
-		// This is a deep check callback
-		log.Printf("[CALLBACK-%s] Processing deep check callback for order: %s", requestID, deepCheckCallback.OrderID)
-		h.processDeepCheckCallback(requestID, &deepCheckCallback)
+		h.processDeepCheckCallback(logger.With("order_id", deepCheckCallback.OrderID), &deepCheckCallback)
 	} else {
-		log.Printf("[CALLBACK-%s] Not a deep check callback, logging only", requestID)
+		logger.Info("Callback is not a deep check callback, ignoring", "body_bytes", len(body))
 	}
 
 	// Return simple success response
@@ -98,119 +95,109 @@ func (h *CallbackHandler) HandleCallback(c *gin.Context) {
 	})
 }
 
+// redactHeaders returns a copy of request headers with the secret ones masked
+func redactHeaders(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, name := range callbackSecretHeaders {
+		if redacted.Get(name) != "" {
+			redacted.Set(name, "[redacted]")
+		}
+	}
+	return redacted
+}
+
 // processDeepCheckCallback processes the deep check results and sends notifications
-func (h *CallbackHandler) processDeepCheckCallback(requestID string, callback *deepcheck.DeepCheckCallbackRequest) {
-	log.Printf("[CALLBACK-%s] Processing deep check results - OrderID: %s, Records: %d",
-		requestID, callback.OrderID, callback.Count)
+func (h *CallbackHandler) processDeepCheckCallback(logger *slog.Logger, callback *deepcheck.DeepCheckCallbackRequest) {
+	logger.Info("Processing deep check callback", "records", callback.Count)
 
 	if h.deepCheckService == nil {
-		log.Printf("[CALLBACK-%s] ERROR: Deep check service not available", requestID)
+		logger.Error("Deep check service not available")
 		return
 	}
 
 	// Look up the order in database
 	order, err := h.deepCheckService.GetDeepCheckOrderByOrderID(callback.OrderID)
 	if err != nil {
-		log.Printf("[CALLBACK-%s] ERROR: Failed to find deep check order %s: %v",
-			requestID, callback.OrderID, err)
+		logger.Error("Failed to find deep check order", "error", err)
 		return
 	}
+	logger = logger.With("user_id", order.UserID, "domain_id", order.DomainID)
 
-	log.Printf("[CALLBACK-%s] Found deep check order: UserID=%d, DomainID=%d, Domain=%s",
-		requestID, order.UserID, order.DomainID, order.DomainName)
+	// Callbacks may be delivered more than once; only the first one completes the order
+	if order.Status != model.DeepCheckStatusPending {
+		logger.Info("Ignoring duplicate deep check callback", "status", order.Status)
+		return
+	}
 
 	// Update the order with callback data
-	if err := h.deepCheckService.UpdateDeepCheckOrderCallback(callback.OrderID, callback); err != nil {
-		log.Printf("[CALLBACK-%s] ERROR: Failed to update deep check order: %v", requestID, err)
+	applied, err := h.deepCheckService.UpdateDeepCheckOrderCallback(callback.OrderID, callback)
+	if err != nil {
+		logger.Error("Failed to update deep check order", "error", err)
 		// Continue with notifications even if we can't update the database
+	} else if !applied {
+		logger.Info("Deep check order was completed concurrently, ignoring duplicate callback")
+		return
 	}
 
 	// Get the current domain information
 	domain, err := h.domainService.GetDomain(order.DomainID, order.UserID)
 	if err != nil {
-		log.Printf("[CALLBACK-%s] ERROR: Failed to get domain %d for user %d: %v",
-			requestID, order.DomainID, order.UserID, err)
+		logger.Error("Failed to get domain of deep check order", "error", err)
 		return
 	}
 
-	log.Printf("[CALLBACK-%s] Retrieved domain: %s (User: %d)", requestID, domain.Name, domain.UserID)
-
 	// Send notifications using the domain information
-	h.sendDeepCheckNotifications(requestID, *domain, callback, order.DomainName)
+	h.sendDeepCheckNotifications(logger.With("domain", domain.Name), *domain, callback, order)
 }
 
-// Update the sendDeepCheckNotifications method in callback_handler.go
-func (h *CallbackHandler) sendDeepCheckNotifications(requestID string, domain model.Domain, callback *deepcheck.DeepCheckCallbackRequest, targetDomain string) {
-	log.Printf("[CALLBACK-%s] Sending deep check notifications for domain %s (User: %d)",
-		requestID, domain.Name, domain.UserID)
-
-	// Send Telegram notification (multiple messages with language support)
-	if h.telegramService != nil {
-		// Get user's Telegram configurations to determine languages
-		configs, err := h.telegramService.GetTelegramConfigsForUser(domain.UserID)
-		if err != nil {
-			log.Printf("[CALLBACK-%s] ERROR: Failed to get Telegram configs: %v", requestID, err)
-		} else if len(configs) > 0 {
-			// Send to each config with their preferred language
-			for _, config := range configs {
-				if !config.IsActive {
-					continue
-				}
-
-				language := config.Language
-				if language == "" {
-					language = "en" // Default to English
-				}
-
-				log.Printf("[CALLBACK-%s] Formatting Telegram messages for language: %s", requestID, language)
-				telegramMessages := callback.FormatTelegramMessage(targetDomain, language)
-
-				// Send messages to this specific config
-				if err := h.telegramService.SendMultipleMessagesToConfig(config, telegramMessages); err != nil {
-					log.Printf("[CALLBACK-%s] ERROR: Failed to send Telegram messages to config %d: %v",
-						requestID, config.ID, err)
-				} else {
-					log.Printf("[CALLBACK-%s] Successfully sent %d Telegram messages to config %d (%s)",
-						requestID, len(telegramMessages), config.ID, language)
-				}
-			}
-		} else {
-			log.Printf("[CALLBACK-%s] No active Telegram configs found for user %d", requestID, domain.UserID)
+// sendDeepCheckNotifications sends the results of a deep check to the user
+func (h *CallbackHandler) sendDeepCheckNotifications(logger *slog.Logger, domain model.Domain, callback *deepcheck.DeepCheckCallbackRequest, order *model.DeepCheckOrder) {
+
+	targetDomain := order.DomainName
+
+	// Every recipient gets the results formatted in its own language
+	render := func(language string) notification.CustomMessage {
+		subject, htmlBody := callback.FormatEmailMessage(targetDomain, language)
+		message := notification.CustomMessage{
+			Subject: subject,
+			HTML:    htmlBody,
+			Attachments: []notification.EmailAttachment{{
+				Filename:    fmt.Sprintf("deep-check-%s-%s.csv", targetDomain, callback.OrderID),
+				ContentType: "text/csv; charset=utf-8",
+				Data:        callback.FormatCSV(),
+			}},
+			Text: callback.FormatTelegramMessage(targetDomain, language),
+		}
+		if order.Source == model.DeepCheckSourceEscalation && order.ConsecutiveFailures != nil {
+			attachToIncident(&message, targetDomain, *order.ConsecutiveFailures)
 		}
+		return message
 	}
 
-	// Send Email notification with language support
-	if h.emailService != nil {
-		// Get user's email configurations to determine languages
-		configs, err := h.emailService.GetEmailConfigsForUser(domain.UserID)
-		if err != nil {
-			log.Printf("[CALLBACK-%s] ERROR: Failed to get email configs: %v", requestID, err)
-		} else if len(configs) > 0 {
-			// Send to each config with their preferred language
-			for _, config := range configs {
-				if !config.IsActive {
-					continue
-				}
-
-				language := config.Language
-				if language == "" {
-					language = "en" // Default to English
-				}
-
-				log.Printf("[CALLBACK-%s] Formatting email message for language: %s", requestID, language)
-				subject, htmlBody := callback.FormatEmailMessage(targetDomain, language) // Pass language parameter
-
-				// Send email to this specific config
-				if err := h.emailService.SendEmailToSpecificConfig(config, subject, htmlBody); err != nil {
-					log.Printf("[CALLBACK-%s] ERROR: Failed to send email to config %d: %v",
-						requestID, config.ID, err)
-				} else {
-					log.Printf("[CALLBACK-%s] Successfully sent email to config %d (%s)",
-						requestID, config.ID, language)
-				}
-			}
+	// Checks ordered from the bot only report back to the chat they were ordered from
+	if order.ReplyChatID != nil {
+		if err := h.telegramService.SendCustomToChat(domain.UserID, *order.ReplyChatID, render); err != nil {
+			logger.Error("Failed to send deep check results to chat", "chat_id", *order.ReplyChatID, "error", err)
 		} else {
-			log.Printf("[CALLBACK-%s] No active email configs found for user %d", requestID, domain.UserID)
+			logger.Info("Sent deep check results to chat", "chat_id", *order.ReplyChatID)
 		}
+		return
+	}
+
+	if err := h.notifiers.SendCustom(domain.UserID, render); err != nil {
+		logger.Error("Failed to send deep check notifications", "error", err)
+	} else {
+		logger.Info("Sent deep check notifications")
 	}
 }
+
+// attachToIncident marks deep check results ordered by an escalation as part of the
+// ongoing outage alert
+func attachToIncident(message *notification.CustomMessage, targetDomain string, failures int) {
+	intro := fmt.Sprintf("🚨 Incident update for %s: deep check ordered automatically after %d consecutive failed checks.",
+		targetDomain, failures)
+
+	message.Subject = fmt.Sprintf("[Incident] %s", message.Subject)
+	message.HTML = strings.Replace(message.HTML, "<body>", fmt.Sprintf("<body><p><strong>%s</strong></p>", intro), 1)
+	message.Text = append([]string{intro}, message.Text...)
+}
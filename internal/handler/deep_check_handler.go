@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"domain-detection-go/internal/deepcheck"
+	"domain-detection-go/internal/domain"
+	"domain-detection-go/internal/service"
+	"domain-detection-go/pkg/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// deepCheckCooldownMinutes is how long a pending order blocks a new request for the
+// same domain. Results usually arrive well within this time.
+const deepCheckCooldownMinutes = 10
+
+// DeepCheckHandler handles deep check order requests
+type DeepCheckHandler struct {
+	deepCheckService *service.DeepCheckService
+	domainService    *domain.DomainService
+	deepCheckClient  *deepcheck.DeepCheckClient
+}
+
+// NewDeepCheckHandler creates a new deep check handler
+func NewDeepCheckHandler(deepCheckService *service.DeepCheckService, domainService *domain.DomainService,
+	deepCheckClient *deepcheck.DeepCheckClient) *DeepCheckHandler {
+	return &DeepCheckHandler{
+		deepCheckService: deepCheckService,
+		domainService:    domainService,
+		deepCheckClient:  deepCheckClient,
+	}
+}
+
+// GetDeepChecks handles GET /api/deep-checks
+func (h *DeepCheckHandler) GetDeepChecks(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID := 0
+	if raw := c.Query("domain_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+			return
+		}
+		domainID = id
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	orders, err := h.deepCheckService.GetUserDeepCheckOrders(userID, domainID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"orders": orders})
+}
+
+// RequestDeepCheck handles POST /api/domains/:id/deepcheck. The order is placed with
+// the deep check provider and its results arrive later through the callback.
+func (h *DeepCheckHandler) RequestDeepCheck(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	d, err := h.domainService.GetDomain(domainID, userID)
+	if err != nil {
+		if errors.Is(err, errDomainNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch domain"})
+		return
+	}
+	if d.ArchivedAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Domain is archived"})
+		return
+	}
+
+	pending, err := h.deepCheckService.GetRecentPendingOrder(d.ID, deepCheckCooldownMinutes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if pending != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":    "A deep check is already running for this domain",
+			"order_id": pending.OrderID,
+		})
+		return
+	}
+
+	response, err := h.deepCheckClient.RequestDeepCheck(d.Name)
+	if err != nil {
+		requestLogger(c).Error("Failed to request deep check", "domain", d.Name, "error", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to request deep check"})
+		return
+	}
+
+	if err := h.deepCheckService.CreateDeepCheckOrder(response.OrderID, userID, d.ID, d.Name, model.DeepCheckSourceManual, nil); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record deep check order"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"order_id": response.OrderID,
+		"status":   "pending",
+	})
+}
+
+// GetDeepCheck handles GET /api/deepchecks/:orderID
+func (h *DeepCheckHandler) GetDeepCheck(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	order, err := h.deepCheckService.GetUserDeepCheckOrder(c.Param("orderID"), userID)
+	if err != nil {
+		if errors.Is(err, service.ErrDeepCheckOrderNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Deep check order not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// GetEscalationSettings handles GET /api/user/deep-check-escalation
+func (h *DeepCheckHandler) GetEscalationSettings(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	settings, err := h.deepCheckService.GetEscalationSettings(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateEscalationSettings handles PUT /api/user/deep-check-escalation
+func (h *DeepCheckHandler) UpdateEscalationSettings(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req model.DeepCheckEscalationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.deepCheckService.UpdateEscalationSettings(userID, req.Failures); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := h.deepCheckService.GetEscalationSettings(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
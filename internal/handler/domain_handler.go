@@ -1,28 +1,113 @@
 package handler
 
 import (
+	"bytes"
+	"encoding/csv"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
+	"domain-detection-go/internal/dns"
 	"domain-detection-go/internal/domain"
+	"domain-detection-go/internal/etag"
+	"domain-detection-go/internal/export"
 	"domain-detection-go/pkg/model"
 
 	"github.com/gin-gonic/gin"
 )
 
+// The domain package is referenced through these because handlers name their domain
+// values "domain"
+var (
+	domainETag            = domain.DomainETag
+	errDomainNotFound     = domain.ErrDomainNotFound
+	errInvalidDomainName  = domain.ErrInvalidDomainName
+	errDomainLimitReached = domain.ErrDomainLimitReached
+	errInvalidRegion      = domain.ErrInvalidRegion
+	errRegionRequired     = domain.ErrRegionRequired
+	errDomainExists       = domain.ErrDomainExists
+	errDomainArchived     = domain.ErrDomainArchived
+	errDomainNotVerified  = domain.ErrDomainNotVerified
+	errNoFieldsToUpdate   = domain.ErrNoFieldsToUpdate
+	errSameTransferUser   = domain.ErrSameTransferUser
+	errTargetUserNotFound = domain.ErrTargetUserNotFound
+
+	errDomainAlreadyArchived     = domain.ErrDomainAlreadyArchived
+	errDomainNotArchived         = domain.ErrDomainNotArchived
+	errDomainIsSubdomain         = domain.ErrDomainIsSubdomain
+	errInvalidSnoozeDuration     = domain.ErrInvalidSnoozeDuration
+	errRecreationInProgress      = domain.ErrRecreationInProgress
+	errProviderNotConfigured     = domain.ErrProviderNotConfigured
+	errDirectCheckRequired       = domain.ErrDirectCheckRequired
+	errVerificationNotRequired   = domain.ErrVerificationNotRequired
+	errVerificationTokenNotFound = domain.ErrVerificationTokenNotFound
+	errRunbookEmpty              = domain.ErrRunbookEmpty
+	errRunbookNotFound           = domain.ErrRunbookNotFound
+	errMaintenanceEndsFirst      = domain.ErrMaintenanceEndsFirst
+	errMaintenanceLongerThanGap  = domain.ErrMaintenanceLongerThanGap
+	errMaintenanceOver           = domain.ErrMaintenanceOver
+	errMaintenanceNotFound       = domain.ErrMaintenanceNotFound
+	errIncidentNotFound          = domain.ErrIncidentNotFound
+	errIncidentResolved          = domain.ErrIncidentResolved
+	errAccountDeletionInProgress = domain.ErrAccountDeletionInProgress
+	errAccountDeletionNotFound   = domain.ErrAccountDeletionNotFound
+)
+
+// Typed errors of the domain package that carry the details of an invalid request
+type (
+	intervalError         = domain.IntervalError
+	checkTypeError        = domain.CheckTypeError
+	contentMatchError     = domain.ContentMatchError
+	missingDomainsError   = domain.MissingDomainsError
+	transferConflictError = domain.TransferConflictError
+)
+
+// intervalMessage describes an interval the user's plan does not allow
+func intervalMessage(err *intervalError) string {
+	return fmt.Sprintf("Interval must be between %d and %d minutes", err.Min, err.Max)
+}
+
+// invalidDomainRequestMessage returns the client message for the typed validation
+// errors shared by the endpoints that add and update domains, or false if err is
+// none of them
+func invalidDomainRequestMessage(err error) (string, bool) {
+	var (
+		intervalErr     *intervalError
+		checkTypeErr    *checkTypeError
+		contentMatchErr *contentMatchError
+		labelErr        *model.LabelError
+	)
+	switch {
+	case errors.As(err, &intervalErr):
+		return intervalMessage(intervalErr), true
+	case errors.As(err, &checkTypeErr):
+		if checkTypeErr.PortRequired {
+			return fmt.Sprintf("Check type %s requires a port", checkTypeErr.CheckType), true
+		}
+		return fmt.Sprintf("Check type %s does not use a port", checkTypeErr.CheckType), true
+	case errors.As(err, &contentMatchErr):
+		return "Content match " + contentMatchErr.Reason, true
+	case errors.As(err, &labelErr):
+		return fmt.Sprintf("Invalid %s: %s", labelErr.Subject, labelErr.Reason), true
+	}
+	return "", false
+}
+
 // DomainHandler handles domain-related HTTP requests
 type DomainHandler struct {
 	domainService *domain.DomainService
+	dnsService    *dns.DNSService
 }
 
 // NewDomainHandler creates a new domain handler
-func NewDomainHandler(domainService *domain.DomainService) *DomainHandler {
+func NewDomainHandler(domainService *domain.DomainService, dnsService *dns.DNSService) *DomainHandler {
 	return &DomainHandler{
 		domainService: domainService,
+		dnsService:    dnsService,
 	}
 }
 
@@ -34,13 +119,17 @@ func (h *DomainHandler) GetDomains(c *gin.Context) {
 		return
 	}
 
-	// Log user ID for debugging
-	log.Printf("Fetching domains for user ID: %d", userID)
+	// Optional label filters, e.g. ?label=merchant:acme&label=env:prod
+	labels, err := model.ParseLabelFilters(c.QueryArray("label"))
+	if err != nil {
+		message, _ := invalidDomainRequestMessage(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return
+	}
 
-	response, err := h.domainService.GetDomains(userID)
+	response, err := h.domainService.GetDomainsByLabels(userID, labels)
 	if err != nil {
-		// Log the actual error for debugging
-		log.Printf("Error fetching domains: %v", err)
+		requestLogger(c).Error("Failed to fetch domains", "user_id", userID, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch domains: " + err.Error()})
 		return
 	}
@@ -64,7 +153,7 @@ func (h *DomainHandler) GetDomain(c *gin.Context) {
 
 	domain, err := h.domainService.GetDomain(domainID, userID)
 	if err != nil {
-		if err.Error() == "domain not found" {
+		if errors.Is(err, errDomainNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
 			return
 		}
@@ -72,9 +161,87 @@ func (h *DomainHandler) GetDomain(c *gin.Context) {
 		return
 	}
 
+	if h.dnsService != nil {
+		comparison, err := h.dnsService.GetComparison(domain.ID)
+		if err != nil {
+			requestLogger(c).Error("Failed to fetch DNS comparison", "domain_id", domain.ID, "error", err)
+		}
+		domain.DNSComparison = comparison
+	}
+
+	runbook, err := h.domainService.ResolveRunbook(*domain)
+	if err != nil {
+		requestLogger(c).Error("Failed to fetch runbook", "domain_id", domain.ID, "error", err)
+	}
+	domain.Runbook = runbook
+
+	if err := h.domainService.AttachMonitorTasks(domain); err != nil {
+		requestLogger(c).Error("Failed to fetch monitor tasks", "domain_id", domain.ID, "error", err)
+	}
+
+	regionStatuses, err := h.domainService.GetRegionStatuses(domain.ID)
+	if err != nil {
+		requestLogger(c).Error("Failed to fetch region statuses", "domain_id", domain.ID, "error", err)
+	}
+	domain.RegionStatuses = regionStatuses
+
+	c.Header("ETag", domainETag(*domain))
 	c.JSON(http.StatusOK, domain)
 }
 
+// GetDomainHistory handles GET /api/domains/:id/history?from=&to=&limit=
+func (h *DomainHandler) GetDomainHistory(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	from, err := parseTimeQuery(c, "from")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from time, expected RFC 3339"})
+		return
+	}
+	to, err := parseTimeQuery(c, "to")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to time, expected RFC 3339"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+
+	history, err := h.domainService.GetCheckHistory(domainID, userID, from, to, limit)
+	if err != nil {
+		if errors.Is(err, errDomainNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch domain history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// parseTimeQuery parses an optional RFC 3339 query parameter
+func parseTimeQuery(c *gin.Context, name string) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
 // AddDomain handles POST /api/domains
 func (h *DomainHandler) AddDomain(c *gin.Context) {
 	userID := c.GetInt("user_id")
@@ -89,41 +256,28 @@ func (h *DomainHandler) AddDomain(c *gin.Context) {
 		return
 	}
 
-	// Validate region
-	if req.Region == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Region is required"})
-		return
-	}
-
-	// Log the request for debugging
-	log.Printf("AddDomain request: %+v for user: %d", req, userID)
-
-	domainID, err := h.domainService.AddDomain(userID, req)
+	domainID, err := h.domainService.AddDomain(c.Request.Context(), userID, req)
 	if err != nil {
-		// Log the detailed error
-		log.Printf("Error adding domain: %v", err)
+		requestLogger(c).Warn("Failed to add domain", "user_id", userID, "error", err)
 
-		if err.Error() == "invalid domain name format" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain name format"})
+		if message, ok := invalidDomainRequestMessage(err); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": message})
 			return
 		}
-		if err.Error() == "domain limit reached" {
+		switch {
+		case errors.Is(err, errInvalidDomainName):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain name format"})
+		case errors.Is(err, errDomainLimitReached):
 			c.JSON(http.StatusForbidden, gin.H{"error": "Domain limit reached"})
-			return
-		}
-		if err.Error() == "domain already exists" {
+		case errors.Is(err, errDomainExists):
 			c.JSON(http.StatusConflict, gin.H{"error": "This domain is already being monitored"})
-			return
-		}
-		if err.Error() == "interval must be 10, 20, 30, 60 or 120 minutes" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Interval must be 10, 20, 30, 60 or 120 minutes"})
-			return
-		}
-		if err.Error() == "invalid region" {
+		case errors.Is(err, errInvalidRegion):
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid region"})
-			return
+		case errors.Is(err, errRegionRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Region is required; set a default region in your settings to omit it"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add domain: " + err.Error()})
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add domain: " + err.Error()})
 		return
 	}
 
@@ -133,6 +287,54 @@ func (h *DomainHandler) AddDomain(c *gin.Context) {
 	})
 }
 
+// UpsertDomain handles PUT /api/domains, creating or updating the domain identified by
+// name and region. Supports If-Match / If-None-Match for optimistic concurrency.
+func (h *DomainHandler) UpsertDomain(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req model.DomainUpsertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	domain, created, err := h.domainService.UpsertDomain(c.Request.Context(), userID, req, c.GetHeader("If-Match"), c.GetHeader("If-None-Match"))
+	if err != nil {
+		requestLogger(c).Warn("Failed to upsert domain", "user_id", userID, "error", err)
+
+		if message, ok := invalidDomainRequestMessage(err); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": message})
+			return
+		}
+		switch {
+		case errors.Is(err, etag.ErrPreconditionFailed):
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Domain was modified or does not match the precondition"})
+		case errors.Is(err, errInvalidDomainName):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain name format"})
+		case errors.Is(err, errDomainLimitReached):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Domain limit reached"})
+		case errors.Is(err, errInvalidRegion):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid region"})
+		case errors.Is(err, errDomainExists):
+			c.JSON(http.StatusConflict, gin.H{"error": "This domain is already being monitored"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save domain: " + err.Error()})
+		}
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.Header("ETag", domainETag(*domain))
+	c.JSON(status, domain)
+}
+
 // AddBatchDomains handles the addition of multiple domains in one request
 func (h *DomainHandler) AddBatchDomains(c *gin.Context) {
 	// Get user ID from context
@@ -198,10 +400,10 @@ func (h *DomainHandler) AddBatchDomains(c *gin.Context) {
 	req.Domains = filteredDomains
 
 	// Log the batch add request
-	log.Printf("Batch add request for user %d: %d domains", userID, len(req.Domains))
+	requestLogger(c).Info("Batch adding domains", "user_id", userID, "domains", len(req.Domains))
 
 	// Process batch domain addition
-	response := h.domainService.AddBatchDomains(userID, req)
+	response := h.domainService.AddBatchDomains(c.Request.Context(), userID, req)
 
 	// Return appropriate status code based on results
 	statusCode := http.StatusOK
@@ -214,243 +416,265 @@ func (h *DomainHandler) AddBatchDomains(c *gin.Context) {
 	c.JSON(statusCode, response)
 }
 
-// UpdateDomain handles PUT /api/domains/:id
-func (h *DomainHandler) UpdateDomain(c *gin.Context) {
+// ImportDomains handles POST /api/domains/import with a multipart CSV upload in the
+// "file" field
+func (h *DomainHandler) ImportDomains(c *gin.Context) {
 	userID := c.GetInt("user_id")
 	if userID == 0 {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	domainID, err := strconv.Atoi(c.Param("id"))
+	fileHeader, err := c.FormFile("file")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A CSV file is required in the file field"})
 		return
 	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file"})
+		return
+	}
+	defer file.Close()
 
-	// Log the domain ID and user ID
-	log.Printf("Updating domain ID: %d for user ID: %d", domainID, userID)
-
-	var req model.DomainUpdateRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Printf("Invalid request format: %v", err)
+	response, err := h.domainService.ImportDomainsCSV(c.Request.Context(), userID, file)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Log the update request
-	log.Printf("Update request: %+v", req)
+	requestLogger(c).Info("Imported domains from CSV", "user_id", userID, "added", response.Added, "total", response.Total)
 
-	err = h.domainService.UpdateDomain(domainID, userID, req)
-	if err != nil {
-		// Log the actual error
-		log.Printf("Error updating domain: %v", err)
-
-		if err.Error() == "domain not found" {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
-			return
-		}
-		if err.Error() == "interval must be 10, 20, 30, 60 or 120 minutes" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Interval must be 10, 20, 30, 60 or 120 minutes"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update domain: " + err.Error()})
-		return
+	// Same status codes as the batch endpoint
+	statusCode := http.StatusOK
+	if response.Added == 0 {
+		statusCode = http.StatusBadRequest
+	} else if response.Failed > 0 {
+		statusCode = http.StatusPartialContent
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Domain updated successfully"})
+	c.JSON(statusCode, response)
 }
 
-// UpdateAllDomains handles PUT /api/domains/batch
-func (h *DomainHandler) UpdateAllDomains(c *gin.Context) {
+// GetDNSHistory handles GET /api/domains/:id/dns-history and returns the distinct DNS
+// record sets seen for the domain
+func (h *DomainHandler) GetDNSHistory(c *gin.Context) {
 	userID := c.GetInt("user_id")
 	if userID == 0 {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	var req model.DomainUpdateRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Printf("Invalid request format: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
 		return
 	}
 
-	// Log the batch update request
-	log.Printf("Batch update request for user %d: %+v", userID, req)
-
-	err := h.domainService.UpdateAllUserDomains(userID, req)
-	if err != nil {
-		// Log the actual error
-		log.Printf("Error batch updating domains: %v", err)
-
-		if err.Error() == "no fields to update" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "No fields to update"})
+	if _, err := h.domainService.GetDomain(domainID, userID); err != nil {
+		if errors.Is(err, errDomainNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
 			return
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch domain"})
+		return
+	}
 
-		if err.Error() == "interval must be 10, 20, 30, 60 or 120 minutes" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Interval must be 10, 20, 30, 60 or 120 minutes"})
-			return
-		}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
 
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update domains: " + err.Error()})
+	snapshots, err := h.dnsService.GetSnapshots(domainID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "All domains updated successfully"})
+	c.JSON(http.StatusOK, gin.H{"snapshots": snapshots})
 }
 
-// DeleteDomain handles DELETE /api/domains/:id
-func (h *DomainHandler) DeleteDomain(c *gin.Context) {
-	userID := c.GetInt("user_id") // Set by auth middleware
+// ExportDomains handles GET /api/domains/export?format=csv|xlsx and returns the user's
+// domains with their latest status as a downloadable file
+func (h *DomainHandler) ExportDomains(c *gin.Context) {
+	userID := c.GetInt("user_id")
 	if userID == 0 {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	domainID, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "xlsx" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or xlsx"})
 		return
 	}
 
-	err = h.domainService.DeleteDomain(userID, domainID)
+	rows, err := h.domainService.ExportDomainRows(userID)
 	if err != nil {
-		if err.Error() == "domain not found" {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
-			return
+		requestLogger(c).Error("Failed to export domains", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export domains"})
+		return
+	}
+
+	filename := fmt.Sprintf("domains-%s.%s", time.Now().UTC().Format("2006-01-02"), format)
+	var buf bytes.Buffer
+	contentType := "text/csv"
+
+	if format == "xlsx" {
+		contentType = export.XLSXContentType
+		err = export.WriteXLSX(&buf, "Domains", rows)
+	} else {
+		w := csv.NewWriter(&buf)
+		for _, row := range rows {
+			record := make([]string, len(row))
+			for i, value := range row {
+				record[i] = fmt.Sprint(value)
+			}
+			w.Write(record)
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete domain"})
+		w.Flush()
+		err = w.Error()
+	}
+	if err != nil {
+		requestLogger(c).Error("Failed to write domain export", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export domains"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Domain deleted successfully"})
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
 }
 
-// DeleteAllDomains deletes all domains for the authenticated user
-func (h *DomainHandler) DeleteAllDomains(c *gin.Context) {
+// UpdateDomain handles PUT /api/domains/:id
+func (h *DomainHandler) UpdateDomain(c *gin.Context) {
 	userID := c.GetInt("user_id")
 	if userID == 0 {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	// Get current domains count for response
-	domainsResponse, err := h.domainService.GetDomains(userID)
+	domainID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current domains"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
 		return
 	}
 
-	domainsCount := len(domainsResponse.Domains)
-
-	if domainsCount == 0 {
-		c.JSON(http.StatusOK, gin.H{
-			"message":       "No domains to delete",
-			"deleted_count": 0,
-		})
+	var req model.DomainUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Delete all domains
-	err = h.domainService.DeleteAllDomains(userID)
+	err = h.domainService.UpdateDomain(c.Request.Context(), domainID, userID, req)
 	if err != nil {
-		log.Printf("Failed to delete all domains for user %d: %v", userID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete domains"})
+		requestLogger(c).Warn("Failed to update domain", "domain_id", domainID, "error", err)
+
+		if message, ok := invalidDomainRequestMessage(err); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": message})
+			return
+		}
+		switch {
+		case errors.Is(err, errDomainNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		case errors.Is(err, errInvalidRegion):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid region"})
+		case errors.Is(err, errDomainArchived):
+			c.JSON(http.StatusConflict, gin.H{"error": "Domain is archived; unarchive it first"})
+		case errors.Is(err, errDomainNotVerified):
+			c.JSON(http.StatusConflict, gin.H{"error": "Domain is not verified; verify it first"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update domain: " + err.Error()})
+		}
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":       fmt.Sprintf("Successfully deleted all %d domains", domainsCount),
-		"deleted_count": domainsCount,
-	})
+	c.JSON(http.StatusOK, gin.H{"message": "Domain updated successfully"})
 }
 
-// UpdateDomainLimit handles PUT /api/settings/domain-limit
-func (h *DomainHandler) UpdateDomainLimit(c *gin.Context) {
-	// Admin only endpoint - check for admin role if you have it
-	userID := c.GetInt("user_id") // Set by auth middleware
+// UpdateAllDomains handles PUT /api/domains/batch
+func (h *DomainHandler) UpdateAllDomains(c *gin.Context) {
+	userID := c.GetInt("user_id")
 	if userID == 0 {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	var req struct {
-		UserID int `json:"user_id" binding:"required"`
-		Limit  int `json:"limit" binding:"required,min=1"`
-	}
-
+	var req model.DomainUpdateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// TODO: Check if requesting user has admin permissions
-
-	err := h.domainService.UpdateDomainLimit(req.UserID, req.Limit)
+	err := h.domainService.UpdateAllUserDomains(userID, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update domain limit"})
+		requestLogger(c).Warn("Failed to update all domains", "user_id", userID, "error", err)
+
+		if errors.Is(err, errNoFieldsToUpdate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No fields to update"})
+			return
+		}
+
+		if message, ok := invalidDomainRequestMessage(err); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": message})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update domains: " + err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Domain limit updated successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": "All domains updated successfully"})
 }
 
-// DeleteBatchDomains handles DELETE /api/domains/batch with domain IDs
-func (h *DomainHandler) DeleteBatchDomains(c *gin.Context) {
+// UpdateSelectedDomains handles PUT /api/domains/batch-selected
+func (h *DomainHandler) UpdateSelectedDomains(c *gin.Context) {
 	userID := c.GetInt("user_id")
 	if userID == 0 {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	var req model.DomainBatchDeleteRequest
+	var req model.DomainBatchUpdateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Validate domain IDs
-	if len(req.DomainIDs) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No domain IDs provided"})
-		return
-	}
-
 	if len(req.DomainIDs) > 100 { // Reasonable limit
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Too many domains. Maximum 100 domains per batch"})
 		return
 	}
 
-	// Validate all IDs are positive integers
+	// Validate IDs and remove duplicates
+	seen := make(map[int]bool)
+	uniqueIDs := []int{}
 	for _, id := range req.DomainIDs {
 		if id <= 0 {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "All domain IDs must be positive integers"})
 			return
 		}
-	}
-
-	// Remove duplicates
-	seen := make(map[int]bool)
-	uniqueIDs := []int{}
-	for _, id := range req.DomainIDs {
 		if !seen[id] {
 			seen[id] = true
 			uniqueIDs = append(uniqueIDs, id)
 		}
 	}
+	req.DomainIDs = uniqueIDs
 
-	// Delete domains
-	response, err := h.domainService.DeleteBatchDomains(userID, uniqueIDs)
+	response, err := h.domainService.UpdateSelectedDomains(c.Request.Context(), userID, req)
 	if err != nil {
-		log.Printf("Failed to delete batch domains for user %d: %v", userID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete domains"})
+		if errors.Is(err, errNoFieldsToUpdate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No fields to update"})
+			return
+		}
+		if message, ok := invalidDomainRequestMessage(err); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": message})
+			return
+		}
+		requestLogger(c).Error("Failed to update selected domains", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update domains"})
 		return
 	}
 
 	// Return appropriate status code
 	statusCode := http.StatusOK
-	if response.DeletedCount == 0 {
+	if response.UpdatedCount == 0 {
 		statusCode = http.StatusNotFound
 	} else if len(response.Failed) > 0 {
 		statusCode = http.StatusPartialContent // 206 for partial success
@@ -458,3 +682,971 @@ func (h *DomainHandler) DeleteBatchDomains(c *gin.Context) {
 
 	c.JSON(statusCode, response)
 }
+
+// ArchiveDomain handles POST /api/domains/:id/archive
+func (h *DomainHandler) ArchiveDomain(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	if err := h.domainService.ArchiveDomain(userID, domainID); err != nil {
+		switch {
+		case errors.Is(err, errDomainNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		case errors.Is(err, errDomainAlreadyArchived):
+			c.JSON(http.StatusConflict, gin.H{"error": "Domain is already archived"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to archive domain: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Domain archived successfully"})
+}
+
+// UnarchiveDomain handles POST /api/domains/:id/unarchive
+func (h *DomainHandler) UnarchiveDomain(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	if err := h.domainService.UnarchiveDomain(userID, domainID); err != nil {
+		switch {
+		case errors.Is(err, errDomainNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		case errors.Is(err, errDomainNotArchived):
+			c.JSON(http.StatusConflict, gin.H{"error": "Domain is not archived"})
+		case errors.Is(err, errDomainNotVerified):
+			c.JSON(http.StatusConflict, gin.H{"error": "Domain is not verified"})
+		case errors.Is(err, errDomainLimitReached):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Domain limit reached"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unarchive domain: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Domain unarchived successfully"})
+}
+
+// DiscoverSubdomains handles POST /api/domains/:id/subdomains/discover
+func (h *DomainHandler) DiscoverSubdomains(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	var req model.SubdomainDiscoveryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.domainService.DiscoverSubdomains(c.Request.Context(), userID, domainID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, errDomainNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		case errors.Is(err, errDomainIsSubdomain):
+			c.JSON(http.StatusConflict, gin.H{"error": "Subdomains cannot have subdomains of their own"})
+		default:
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to discover subdomains: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetSubdomains handles GET /api/domains/:id/subdomains
+func (h *DomainHandler) GetSubdomains(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	group, err := h.domainService.GetSubdomainStatus(userID, domainID)
+	if err != nil {
+		if errors.Is(err, errDomainNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get subdomains: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// SnoozeDomain handles POST /api/domains/:id/snooze
+func (h *DomainHandler) SnoozeDomain(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	var req model.DomainSnoozeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid duration, use e.g. 30m or 2h"})
+		return
+	}
+
+	snooze, err := h.domainService.SnoozeDomain(userID, domainID, duration)
+	if err != nil {
+		switch {
+		case errors.Is(err, errDomainNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		case errors.Is(err, errInvalidSnoozeDuration):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Snooze duration must be positive and at most 7 days"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to snooze domain: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, snooze)
+}
+
+// UnsnoozeDomain handles DELETE /api/domains/:id/snooze
+func (h *DomainHandler) UnsnoozeDomain(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	if err := h.domainService.UnsnoozeDomain(userID, domainID); err != nil {
+		if errors.Is(err, errDomainNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unsnooze domain: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Domain unsnoozed successfully"})
+}
+
+// RecreateMonitors handles POST /api/domains/:id/monitors/recreate
+func (h *DomainHandler) RecreateMonitors(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	var req model.MonitorRecreateRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	resp, err := h.domainService.RecreateMonitors(userID, domainID, req.Providers)
+	if err != nil {
+		switch {
+		case errors.Is(err, errDomainNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		case errors.Is(err, errDomainArchived):
+			c.JSON(http.StatusConflict, gin.H{"error": "Domain is archived"})
+		case errors.Is(err, errDomainNotVerified):
+			c.JSON(http.StatusConflict, gin.H{"error": "Domain is not verified"})
+		case errors.Is(err, errRecreationInProgress):
+			c.JSON(http.StatusConflict, gin.H{"error": "Monitor recreation already in progress"})
+		case errors.Is(err, errProviderNotConfigured):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Provider not configured"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to recreate monitors: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// RetryMonitorTasks handles POST /api/domains/:id/monitor-tasks/retry
+func (h *DomainHandler) RetryMonitorTasks(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	retried, err := h.domainService.RetryMonitorTasks(userID, domainID)
+	if err != nil {
+		if errors.Is(err, errDomainNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"retried": retried})
+}
+
+// GetDomainVerification handles GET /api/domains/:id/verification
+func (h *DomainHandler) GetDomainVerification(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	verification, err := h.domainService.GetDomainVerification(userID, domainID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errDomainNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		case errors.Is(err, errVerificationNotRequired):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain does not require verification"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
+
+// VerifyDomain handles POST /api/domains/:id/verify
+func (h *DomainHandler) VerifyDomain(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	verification, err := h.domainService.VerifyDomain(c.Request.Context(), userID, domainID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errDomainNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		case errors.Is(err, errVerificationNotRequired):
+			c.JSON(http.StatusConflict, gin.H{"error": "Domain does not require verification"})
+		case errors.Is(err, errVerificationTokenNotFound):
+			// The instructions tell the user what to publish
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Verification token not found", "verification": verification})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify domain: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
+
+// GetDirectCheck handles GET /api/domains/:id/direct-check
+func (h *DomainHandler) GetDirectCheck(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	settings, err := h.domainService.GetDirectCheck(userID, domainID)
+	if err != nil {
+		if errors.Is(err, errDomainNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get direct check settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateDirectCheck handles PUT /api/domains/:id/direct-check
+func (h *DomainHandler) UpdateDirectCheck(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	var req model.DirectCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := h.domainService.UpdateDirectCheck(userID, domainID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, errDomainNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		case errors.Is(err, errDomainArchived):
+			c.JSON(http.StatusConflict, gin.H{"error": "Domain is archived"})
+		case errors.Is(err, errDomainNotVerified):
+			c.JSON(http.StatusConflict, gin.H{"error": "Domain is not verified"})
+		case errors.Is(err, errProviderNotConfigured):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Provider not configured"})
+		case errors.Is(err, errDirectCheckRequired):
+			c.JSON(http.StatusConflict, gin.H{"error": "TCP and ping checks cannot run without the direct check"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update direct check: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// GetDomainTags handles GET /api/domains/:id/tags
+func (h *DomainHandler) GetDomainTags(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	tags, err := h.domainService.GetDomainTags(userID, domainID)
+	if err != nil {
+		if errors.Is(err, errDomainNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get domain tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.TagsResponse{Tags: tags})
+}
+
+// SetDomainTags handles PUT /api/domains/:id/tags
+func (h *DomainHandler) SetDomainTags(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	var req model.TagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tags, err := h.domainService.SetDomainTags(userID, domainID, req.Tags)
+	if err != nil {
+		switch {
+		case errors.Is(err, errDomainNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		case strings.HasPrefix(err.Error(), "invalid tag"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update domain tags: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, model.TagsResponse{Tags: tags})
+}
+
+// GetDomainProviders handles GET /api/domains/:id/providers
+func (h *DomainHandler) GetDomainProviders(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	selection, err := h.domainService.GetDomainProviders(userID, domainID)
+	if err != nil {
+		if errors.Is(err, errDomainNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get domain providers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, selection)
+}
+
+// SetDomainProviders handles PUT /api/domains/:id/providers
+func (h *DomainHandler) SetDomainProviders(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	var req model.ProviderSelectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	selection, err := h.domainService.SetDomainProviders(userID, domainID, req.Providers)
+	if err != nil {
+		switch {
+		case errors.Is(err, errDomainNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		case strings.HasPrefix(err.Error(), "unsupported provider"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update domain providers"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, selection)
+}
+
+// GetUserProviders handles GET /api/user/monitor-providers
+func (h *DomainHandler) GetUserProviders(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	selection, err := h.domainService.GetUserProviders(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get monitor providers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, selection)
+}
+
+// SetUserProviders handles PUT /api/user/monitor-providers
+func (h *DomainHandler) SetUserProviders(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req model.ProviderSelectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	selection, err := h.domainService.SetUserProviders(userID, req.Providers)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "unsupported provider") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update monitor providers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, selection)
+}
+
+// GetUserSettings handles GET /api/user/settings
+func (h *DomainHandler) GetUserSettings(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	settings, err := h.domainService.GetUserSettings(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateUserSettings handles PUT /api/user/settings
+func (h *DomainHandler) UpdateUserSettings(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req model.UserSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.domainService.UpdateUserSettings(userID, req); err != nil {
+		var intervalErr *intervalError
+		switch {
+		case errors.Is(err, errInvalidRegion):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid region"})
+		case errors.As(err, &intervalErr):
+			c.JSON(http.StatusBadRequest, gin.H{"error": intervalMessage(intervalErr)})
+		default:
+			requestLogger(c).Error("Failed to update user settings", "user_id", userID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user settings"})
+		}
+		return
+	}
+
+	settings, err := h.domainService.GetUserSettings(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// DeleteDomain handles DELETE /api/domains/:id
+func (h *DomainHandler) DeleteDomain(c *gin.Context) {
+	userID := c.GetInt("user_id") // Set by auth middleware
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	err = h.domainService.DeleteDomain(c.Request.Context(), userID, domainID)
+	if err != nil {
+		if errors.Is(err, errDomainNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete domain"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Domain deleted successfully"})
+}
+
+// GetTrash handles GET /api/domains/trash
+func (h *DomainHandler) GetTrash(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	trash, err := h.domainService.GetTrash(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deleted domains"})
+		return
+	}
+
+	c.JSON(http.StatusOK, trash)
+}
+
+// RestoreDomain handles POST /api/domains/:id/restore
+func (h *DomainHandler) RestoreDomain(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	if err := h.domainService.RestoreDomain(c.Request.Context(), userID, domainID); err != nil {
+		switch {
+		case errors.Is(err, errDomainNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Deleted domain not found"})
+		case errors.Is(err, errDomainLimitReached):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Domain limit reached"})
+		case errors.Is(err, errDomainExists):
+			c.JSON(http.StatusConflict, gin.H{"error": "Domain already exists in this region"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore domain: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Domain restored successfully"})
+}
+
+// DeleteAllDomains deletes all domains for the authenticated user
+func (h *DomainHandler) DeleteAllDomains(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Get current domains count for response
+	domainsResponse, err := h.domainService.GetDomains(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current domains"})
+		return
+	}
+
+	domainsCount := len(domainsResponse.Domains)
+
+	if domainsCount == 0 {
+		c.JSON(http.StatusOK, gin.H{
+			"message":       "No domains to delete",
+			"deleted_count": 0,
+		})
+		return
+	}
+
+	// Delete all domains
+	err = h.domainService.DeleteAllDomains(userID)
+	if err != nil {
+		requestLogger(c).Error("Failed to delete all domains", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete domains"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       fmt.Sprintf("Successfully deleted all %d domains", domainsCount),
+		"deleted_count": domainsCount,
+	})
+}
+
+// UpdateDomainLimit handles PUT /api/admin/settings/domain-limit
+func (h *DomainHandler) UpdateDomainLimit(c *gin.Context) {
+	userID := c.GetInt("user_id") // Set by auth middleware
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req struct {
+		UserID int `json:"user_id" binding:"required"`
+		Limit  int `json:"limit" binding:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.domainService.UpdateDomainLimit(req.UserID, req.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update domain limit"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Domain limit updated successfully"})
+}
+
+// TransferDomains handles POST /api/admin/domains/transfer
+func (h *DomainHandler) TransferDomains(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req model.DomainTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate IDs and remove duplicates
+	seen := make(map[int]bool)
+	uniqueIDs := []int{}
+	for _, id := range req.DomainIDs {
+		if id <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "All domain IDs must be positive integers"})
+			return
+		}
+		if !seen[id] {
+			seen[id] = true
+			uniqueIDs = append(uniqueIDs, id)
+		}
+	}
+	req.DomainIDs = uniqueIDs
+
+	response, err := h.domainService.TransferDomains(c.Request.Context(), userID, req)
+	if err != nil {
+		var (
+			missingErr  *missingDomainsError
+			conflictErr *transferConflictError
+		)
+		switch {
+		case errors.Is(err, errSameTransferUser):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Source and target user are the same"})
+		case errors.Is(err, errTargetUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Target user not found"})
+		case errors.As(err, &missingErr):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domains not found", "domain_ids": missingErr.IDs})
+		case errors.Is(err, errDomainLimitReached):
+			c.JSON(http.StatusConflict, gin.H{"error": "Target user's domain limit would be exceeded"})
+		case errors.As(err, &conflictErr):
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Target user already monitors %s in this region", conflictErr.Name)})
+		default:
+			requestLogger(c).Error("Failed to transfer domains", "from_user_id", req.FromUserID, "to_user_id", req.ToUserID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer domains"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteBatchDomains handles DELETE /api/domains/batch with domain IDs
+func (h *DomainHandler) DeleteBatchDomains(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req model.DomainBatchDeleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate domain IDs
+	if len(req.DomainIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No domain IDs provided"})
+		return
+	}
+
+	if len(req.DomainIDs) > 100 { // Reasonable limit
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Too many domains. Maximum 100 domains per batch"})
+		return
+	}
+
+	// Validate all IDs are positive integers
+	for _, id := range req.DomainIDs {
+		if id <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "All domain IDs must be positive integers"})
+			return
+		}
+	}
+
+	// Remove duplicates
+	seen := make(map[int]bool)
+	uniqueIDs := []int{}
+	for _, id := range req.DomainIDs {
+		if !seen[id] {
+			seen[id] = true
+			uniqueIDs = append(uniqueIDs, id)
+		}
+	}
+
+	// Delete domains
+	response, err := h.domainService.DeleteBatchDomains(userID, uniqueIDs)
+	if err != nil {
+		requestLogger(c).Error("Failed to delete batch domains", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete domains"})
+		return
+	}
+
+	// Return appropriate status code
+	statusCode := http.StatusOK
+	if response.DeletedCount == 0 {
+		statusCode = http.StatusNotFound
+	} else if len(response.Failed) > 0 {
+		statusCode = http.StatusPartialContent // 206 for partial success
+	}
+
+	c.JSON(statusCode, response)
+}
+
+// GetSummary handles GET /api/summary
+func (h *DomainHandler) GetSummary(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	summary, err := h.domainService.GetDomainSummary(userID)
+	if err != nil {
+		requestLogger(c).Error("Failed to fetch domain summary", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch summary"})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// GetDNSResolvers handles GET /api/admin/dns/resolvers
+func (h *DomainHandler) GetDNSResolvers(c *gin.Context) {
+	resolvers, err := h.dnsService.GetResolvers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"resolvers": resolvers})
+}
+
+// GetRegionFallbacks handles GET /api/admin/region-fallbacks
+func (h *DomainHandler) GetRegionFallbacks(c *gin.Context) {
+	fallbacks, err := h.domainService.GetRegionFallbacks()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"region_fallbacks": fallbacks})
+}
+
+// UpdateRegionFallbacks handles PUT /api/admin/region-fallbacks/:region
+func (h *DomainHandler) UpdateRegionFallbacks(c *gin.Context) {
+	var req model.RegionFallbacksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	fallbacks, err := h.domainService.UpdateRegionFallbacks(c.Param("region"), req.Fallbacks)
+	if err != nil {
+		switch {
+		case errors.Is(err, errInvalidRegion):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Region not found"})
+		case strings.HasPrefix(err.Error(), "invalid fallback region"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, fallbacks)
+}
+
+// GetMonitorDrift handles GET /api/admin/monitors/drift
+func (h *DomainHandler) GetMonitorDrift(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	report, err := h.domainService.GetMonitorDriftReport(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ReconcileMonitors handles POST /api/admin/monitors/reconcile
+func (h *DomainHandler) ReconcileMonitors(c *gin.Context) {
+	dryRun := c.Query("dry_run") == "true"
+
+	run, err := h.domainService.ReconcileMonitors(dryRun)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
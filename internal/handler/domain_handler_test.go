@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"fmt"
+	"testing"
+
+	"domain-detection-go/pkg/model"
+)
+
+func TestInvalidDomainRequestMessage(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&intervalError{Min: 5, Max: 1440}, "Interval must be between 5 and 1440 minutes"},
+		{fmt.Errorf("domain 3: %w", &checkTypeError{CheckType: "tcp", PortRequired: true}), "Check type tcp requires a port"},
+		{&checkTypeError{CheckType: "https"}, "Check type https does not use a port"},
+		{&contentMatchError{Reason: "pattern is required"}, "Content match pattern is required"},
+		{&model.LabelError{Subject: "label set", Reason: "at most 20 labels are allowed"}, "Invalid label set: at most 20 labels are allowed"},
+	}
+	for _, tt := range tests {
+		got, ok := invalidDomainRequestMessage(tt.err)
+		if !ok || got != tt.want {
+			t.Errorf("invalidDomainRequestMessage(%v) = %q, %v, want %q", tt.err, got, ok, tt.want)
+		}
+	}
+
+	if _, ok := invalidDomainRequestMessage(errDomainNotFound); ok {
+		t.Error("invalidDomainRequestMessage accepted an error that is not a validation error")
+	}
+}
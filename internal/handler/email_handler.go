@@ -1,10 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"domain-detection-go/internal/etag"
 	"domain-detection-go/internal/notification"
+	"domain-detection-go/internal/service"
 	"domain-detection-go/pkg/model"
 
 	"github.com/gin-gonic/gin"
@@ -12,13 +16,15 @@ import (
 
 // EmailHandler handles email configuration requests
 type EmailHandler struct {
-	emailService *notification.EmailService
+	emailService      *notification.EmailService
+	validationService *service.ConfigValidationService
 }
 
 // NewEmailHandler creates a new email handler
-func NewEmailHandler(emailService *notification.EmailService) *EmailHandler {
+func NewEmailHandler(emailService *notification.EmailService, validationService *service.ConfigValidationService) *EmailHandler {
 	return &EmailHandler{
-		emailService: emailService,
+		emailService:      emailService,
+		validationService: validationService,
 	}
 }
 
@@ -36,6 +42,11 @@ func (h *EmailHandler) AddEmailConfig(c *gin.Context) {
 		return
 	}
 
+	if problems := h.validationService.ValidateEmailConfig(userID, req.EmailAddress, req.Language, req.MonitorRegions); len(problems) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": strings.Join(problems, "; "), "errors": problems})
+		return
+	}
+
 	configID, err := h.emailService.AddEmailConfig(
 		userID,
 		req.EmailAddress,
@@ -54,7 +65,8 @@ func (h *EmailHandler) AddEmailConfig(c *gin.Context) {
 
 	c.JSON(http.StatusCreated, gin.H{
 		"id":      configID,
-		"message": "Email configuration added successfully",
+		"status":  model.EmailConfigPending,
+		"message": "Email configuration added, confirm it with the code sent to the address",
 	})
 }
 
@@ -161,34 +173,254 @@ func (h *EmailHandler) SendTestEmail(c *gin.Context) {
 		return
 	}
 
-	// Get user's email configs
-	configs, err := h.emailService.GetEmailConfigsForUser(userID)
+	// Send test email to the config if it belongs to this user
+	err = h.emailService.SendTest(configID, userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve configurations"})
+		if errors.Is(err, notification.ErrConfigNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Configuration not found or not owned by you"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send test email: " + err.Error()})
 		return
 	}
 
-	var targetConfig *model.EmailConfig
-	for _, config := range configs {
-		if config.ID == configID {
-			targetConfig = &config
-			break
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Test email sent successfully",
+	})
+}
+
+// VerifyEmailConfig handles POST /api/email/configs/:id/verify, confirming a pending
+// config with the code mailed to it
+func (h *EmailHandler) VerifyEmailConfig(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	configID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid configuration ID"})
+		return
+	}
+
+	var req model.EmailVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.emailService.VerifyEmailConfig(configID, userID, req.Code); err != nil {
+		switch {
+		case errors.Is(err, notification.ErrConfigNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Configuration not found or not owned by you"})
+		case errors.Is(err, notification.ErrAlreadyVerified):
+			c.JSON(http.StatusConflict, gin.H{"error": "Email configuration already verified"})
+		case errors.Is(err, notification.ErrInvalidVerificationCode):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification code"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		}
+		return
 	}
 
-	if targetConfig == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Configuration not found or not owned by you"})
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Email configuration verified successfully",
+	})
+}
+
+// ResendVerification handles POST /api/email/configs/:id/verify/resend, mailing a new
+// code to a pending config
+func (h *EmailHandler) ResendVerification(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	// Send test email
-	err = h.emailService.SendTestEmail(*targetConfig)
+	configID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send test email: " + err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid configuration ID"})
+		return
+	}
+
+	if err := h.emailService.ResendVerification(configID, userID); err != nil {
+		switch {
+		case errors.Is(err, notification.ErrConfigNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Configuration not found or not owned by you"})
+		case errors.Is(err, notification.ErrAlreadyVerified):
+			c.JSON(http.StatusConflict, gin.H{"error": "Email configuration already verified"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send verification email: " + err.Error()})
+		}
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Test email sent successfully",
+		"message": "Verification email sent successfully",
 	})
 }
+
+// GetReportSettings handles GET /api/email/configs/:id/report
+func (h *EmailHandler) GetReportSettings(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	configID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid configuration ID"})
+		return
+	}
+
+	settings, err := h.emailService.GetReportSettings(configID, userID)
+	if err != nil {
+		if errors.Is(err, notification.ErrConfigNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Configuration not found or not owned by you"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get report settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateReportSettings handles PUT /api/email/configs/:id/report
+func (h *EmailHandler) UpdateReportSettings(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	configID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid configuration ID"})
+		return
+	}
+
+	var req model.EmailReportSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := h.emailService.UpdateReportSettings(configID, userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, notification.ErrConfigNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Configuration not found or not owned by you"})
+		case errors.Is(err, errInvalidRegion):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid region"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update report settings"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// SendReport handles POST /api/email/configs/:id/report/send
+func (h *EmailHandler) SendReport(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	configID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid configuration ID"})
+		return
+	}
+
+	if err := h.emailService.SendReportNow(configID, userID); err != nil {
+		if errors.Is(err, notification.ErrConfigNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Configuration not found or not owned by you"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send report: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Report sent successfully"})
+}
+
+// GetEmailConfigByExternalID handles GET /api/email/configs/external/:external_id
+func (h *EmailHandler) GetEmailConfigByExternalID(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	config, err := h.emailService.GetEmailConfigByExternalID(userID, c.Param("external_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if config == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Configuration not found"})
+		return
+	}
+
+	c.Header("ETag", notification.EmailConfigETag(*config))
+	c.JSON(http.StatusOK, config)
+}
+
+// UpsertEmailConfigByExternalID handles PUT /api/email/configs/external/:external_id, creating
+// or replacing the config. Supports If-Match / If-None-Match for optimistic concurrency.
+func (h *EmailHandler) UpsertEmailConfigByExternalID(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	externalID := c.Param("external_id")
+	if len(externalID) > 255 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "External ID must be at most 255 characters"})
+		return
+	}
+
+	var req model.EmailConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	existing, err := h.emailService.GetEmailConfigByExternalID(userID, externalID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	replacing := 0
+	if existing != nil {
+		replacing = existing.ID
+	}
+	if problems := h.validationService.ValidateEmailConfigReplacing(userID, replacing, req.EmailAddress, req.Language, req.MonitorRegions); len(problems) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": strings.Join(problems, "; "), "errors": problems})
+		return
+	}
+
+	config, created, err := h.emailService.UpsertEmailConfigByExternalID(userID, externalID, req, c.GetHeader("If-Match"), c.GetHeader("If-None-Match"))
+	if errors.Is(err, etag.ErrPreconditionFailed) {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Configuration was modified or does not match the precondition"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.Header("ETag", notification.EmailConfigETag(*config))
+	c.JSON(status, config)
+}
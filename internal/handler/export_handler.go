@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"domain-detection-go/internal/export"
+	"domain-detection-go/pkg/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// hostKeyFingerprintMessage explains the host key fingerprint export targets need
+const hostKeyFingerprintMessage = "Invalid host key fingerprint; use the SHA256 fingerprint printed by ssh-keygen -l, e.g. SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8"
+
+// ExportHandler handles scheduled SFTP export requests
+type ExportHandler struct {
+	exportService *export.ExportService
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(exportService *export.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// GetTargets handles GET /api/exports/targets
+func (h *ExportHandler) GetTargets(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	targets, err := h.exportService.GetTargets(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch export targets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"targets": targets})
+}
+
+// AddTarget handles POST /api/exports/targets
+func (h *ExportHandler) AddTarget(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req model.ExportTargetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	targetID, err := h.exportService.AddTarget(userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, export.ErrCredentialsRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Password or private key is required"})
+		case errors.Is(err, export.ErrInvalidHostKeyFingerprint):
+			c.JSON(http.StatusBadRequest, gin.H{"error": hostKeyFingerprintMessage})
+		case errors.Is(err, export.ErrInvalidPrivateKey):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid private key"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add export target: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"id":      targetID,
+		"message": "Export target added successfully",
+	})
+}
+
+// UpdateTarget handles PUT /api/exports/targets/:id
+func (h *ExportHandler) UpdateTarget(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	targetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid export target ID"})
+		return
+	}
+
+	var req model.ExportTargetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.exportService.UpdateTarget(targetID, userID, req); err != nil {
+		switch {
+		case errors.Is(err, export.ErrTargetNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Export target not found"})
+		case errors.Is(err, export.ErrInvalidHostKeyFingerprint):
+			c.JSON(http.StatusBadRequest, gin.H{"error": hostKeyFingerprintMessage})
+		case errors.Is(err, export.ErrInvalidPrivateKey):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid private key"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update export target: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Export target updated successfully"})
+}
+
+// DeleteTarget handles DELETE /api/exports/targets/:id
+func (h *ExportHandler) DeleteTarget(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	targetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid export target ID"})
+		return
+	}
+
+	if err := h.exportService.DeleteTarget(targetID, userID); err != nil {
+		if errors.Is(err, export.ErrTargetNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Export target not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete export target"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Export target deleted successfully"})
+}
+
+// GetDeliveries handles GET /api/exports/deliveries?target_id=&limit=
+func (h *ExportHandler) GetDeliveries(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	targetID, _ := strconv.Atoi(c.Query("target_id"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	deliveries, err := h.exportService.GetDeliveries(userID, targetID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch export deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+// RequestAccountExport handles GET /api/user/export. The archive is built in the
+// background and its download link mailed to the user.
+func (h *ExportHandler) RequestAccountExport(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	export, err := h.exportService.RequestAccountExport(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to request data export"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Your data export is being prepared; a download link will be emailed to you",
+		"export":  export,
+	})
+}
+
+// DownloadAccountExport handles GET /api/user/export/:token, the link mailed to the user
+func (h *ExportHandler) DownloadAccountExport(c *gin.Context) {
+	archive, err := h.exportService.DownloadAccountExport(c.Param("token"))
+	if err != nil {
+		if errors.Is(err, export.ErrAccountExportNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Export not found or expired"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to download data export"})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="domain-monitor-export.zip"`)
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/zip", archive)
+}
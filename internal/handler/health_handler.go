@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"net/http"
+
+	"domain-detection-go/internal/health"
+	"domain-detection-go/pkg/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthHandler serves the load balancer probes
+type HealthHandler struct {
+	healthService *health.HealthService
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(healthService *health.HealthService) *HealthHandler {
+	return &HealthHandler{
+		healthService: healthService,
+	}
+}
+
+// Healthz handles GET /healthz. It reports the database, Uptrends, Site24x7 and
+// Telegram states, and fails only when the database is down.
+func (h *HealthHandler) Healthz(c *gin.Context) {
+	h.respond(c, h.healthService.Check(true))
+}
+
+// Readyz handles GET /readyz. The instance can serve traffic while the database is up.
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	h.respond(c, h.healthService.Check(false))
+}
+
+func (h *HealthHandler) respond(c *gin.Context, report model.HealthReport) {
+	status := http.StatusOK
+	if report.Status == model.HealthDown {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"domain-detection-go/internal/domain"
+	"domain-detection-go/pkg/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IncidentHandler handles incident requests
+type IncidentHandler struct {
+	domainService *domain.DomainService
+}
+
+// NewIncidentHandler creates a new incident handler
+func NewIncidentHandler(domainService *domain.DomainService) *IncidentHandler {
+	return &IncidentHandler{
+		domainService: domainService,
+	}
+}
+
+// GetIncidents handles GET /api/incidents?status=open|resolved
+func (h *IncidentHandler) GetIncidents(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	status, ok := incidentStatus(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	incidents, err := h.domainService.GetIncidents(userID, 0, status, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"incidents": incidents})
+}
+
+// GetDomainIncidents handles GET /api/domains/:id/incidents
+func (h *IncidentHandler) GetDomainIncidents(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	if _, err := h.domainService.GetDomain(domainID, userID); err != nil {
+		if errors.Is(err, errDomainNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch domain"})
+		return
+	}
+
+	status, ok := incidentStatus(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	incidents, err := h.domainService.GetIncidents(userID, domainID, status, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"incidents": incidents})
+}
+
+// incidentStatus reads the optional status filter, answering 400 when it is invalid
+func incidentStatus(c *gin.Context) (string, bool) {
+	status := c.Query("status")
+	switch status {
+	case "", model.IncidentStatusOpen, model.IncidentStatusResolved:
+		return status, true
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "status must be open or resolved"})
+	return "", false
+}
@@ -0,0 +1,14 @@
+package handler
+
+import (
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+
+	"domain-detection-go/internal/logging"
+)
+
+// requestLogger returns the logger for a request, carrying its request ID
+func requestLogger(c *gin.Context) *slog.Logger {
+	return logging.FromContext(c.Request.Context(), slog.Default())
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"domain-detection-go/internal/domain"
+	"domain-detection-go/pkg/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaintenanceHandler handles maintenance window requests
+type MaintenanceHandler struct {
+	domainService *domain.DomainService
+}
+
+// NewMaintenanceHandler creates a new maintenance window handler
+func NewMaintenanceHandler(domainService *domain.DomainService) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		domainService: domainService,
+	}
+}
+
+// GetMaintenanceWindows handles GET /api/maintenance
+func (h *MaintenanceHandler) GetMaintenanceWindows(c *gin.Context) {
+	h.listWindows(c, 0)
+}
+
+// CreateMaintenanceWindow handles POST /api/maintenance for maintenance covering every
+// domain of the user
+func (h *MaintenanceHandler) CreateMaintenanceWindow(c *gin.Context) {
+	h.createWindow(c, 0)
+}
+
+// GetDomainMaintenanceWindows handles GET /api/domains/:id/maintenance
+func (h *MaintenanceHandler) GetDomainMaintenanceWindows(c *gin.Context) {
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+	h.listWindows(c, domainID)
+}
+
+// CreateDomainMaintenanceWindow handles POST /api/domains/:id/maintenance
+func (h *MaintenanceHandler) CreateDomainMaintenanceWindow(c *gin.Context) {
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+	h.createWindow(c, domainID)
+}
+
+// DeleteMaintenanceWindow handles DELETE /api/maintenance/:id
+func (h *MaintenanceHandler) DeleteMaintenanceWindow(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	windowID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid maintenance window ID"})
+		return
+	}
+
+	if err := h.domainService.DeleteMaintenanceWindow(userID, windowID); err != nil {
+		if errors.Is(err, errMaintenanceNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Maintenance window not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Maintenance window deleted"})
+}
+
+func (h *MaintenanceHandler) listWindows(c *gin.Context, domainID int) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if domainID != 0 {
+		if _, err := h.domainService.GetDomain(domainID, userID); err != nil {
+			if errors.Is(err, errDomainNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch domain"})
+			return
+		}
+	}
+
+	windows, err := h.domainService.GetMaintenanceWindows(userID, domainID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"maintenance_windows": windows})
+}
+
+func (h *MaintenanceHandler) createWindow(c *gin.Context, domainID int) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req model.MaintenanceWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	window, err := h.domainService.CreateMaintenanceWindow(userID, domainID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, errDomainNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		case errors.Is(err, errMaintenanceEndsFirst),
+			errors.Is(err, errMaintenanceLongerThanGap),
+			errors.Is(err, errMaintenanceOver):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, window)
+}
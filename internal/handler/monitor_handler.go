@@ -1,7 +1,11 @@
 package handler
 
 import (
+	"net/http"
+
 	"domain-detection-go/internal/monitor"
+
+	"github.com/gin-gonic/gin"
 )
 
 // MonitorHandler handles domain monitoring requests
@@ -15,3 +19,28 @@ func NewMonitorHandler(monitorService *monitor.MonitorService) *MonitorHandler {
 		monitorService: monitorService,
 	}
 }
+
+// SyncMonitors handles POST /api/admin/monitors/sync
+func (h *MonitorHandler) SyncMonitors(c *gin.Context) {
+	result, err := h.monitorService.SyncMonitorStatus()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetProviderStats handles GET /api/admin/monitors/providers/stats
+func (h *MonitorHandler) GetProviderStats(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": h.monitorService.ProviderStats()})
+}
+
+// InvalidateCheckpoints handles POST /api/admin/monitors/checkpoints/invalidate
+func (h *MonitorHandler) InvalidateCheckpoints(c *gin.Context) {
+	regions := h.monitorService.InvalidateCheckpoints()
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Checkpoint cache cleared",
+		"regions": regions,
+	})
+}
@@ -0,0 +1,803 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"domain-detection-go/internal/notification"
+	"domain-detection-go/internal/service"
+	"domain-detection-go/pkg/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationHandler handles requests spanning multiple notification channels
+type NotificationHandler struct {
+	telegramService   *notification.TelegramService
+	emailService      *notification.EmailService
+	validationService *service.ConfigValidationService
+	routingService    *notification.RoutingService
+	escalationService *notification.EscalationService
+	historyService    *notification.HistoryService
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(telegramService *notification.TelegramService, emailService *notification.EmailService,
+	validationService *service.ConfigValidationService, routingService *notification.RoutingService,
+	escalationService *notification.EscalationService, historyService *notification.HistoryService) *NotificationHandler {
+	return &NotificationHandler{
+		telegramService:   telegramService,
+		emailService:      emailService,
+		validationService: validationService,
+		routingService:    routingService,
+		escalationService: escalationService,
+		historyService:    historyService,
+	}
+}
+
+// BatchAddConfigs handles POST /api/notifications/configs/batch
+func (h *NotificationHandler) BatchAddConfigs(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req model.NotificationConfigBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := model.NotificationConfigBatchResponse{
+		Success: []model.NotificationConfigBatchResult{},
+		Failed:  []model.NotificationConfigBatchResult{},
+		Total:   len(req.Configs),
+	}
+
+	// Track targets within this batch so duplicates in the request itself are caught
+	seen := make(map[string]bool)
+
+	for i, item := range req.Configs {
+		target := item.ChatID
+		if item.Channel == model.ChannelEmail {
+			target = item.EmailAddress
+		}
+		result := model.NotificationConfigBatchResult{Index: i, Channel: item.Channel, Target: target}
+
+		problems := h.validationService.ValidateBatchItem(userID, item)
+		key := item.Channel + ":" + strings.ToLower(target)
+		if target != "" && seen[key] {
+			problems = append(problems, "duplicate of an earlier item in this batch")
+		}
+		seen[key] = true
+
+		if len(problems) > 0 {
+			result.Errors = problems
+			response.Failed = append(response.Failed, result)
+			continue
+		}
+
+		var configID int
+		var err error
+		switch item.Channel {
+		case model.ChannelTelegram:
+			configID, err = h.telegramService.AddTelegramConfig(userID, item.ChatID, item.Name, item.Language,
+				item.NotifyOnDown, item.NotifyOnUp, item.IsActive, item.MonitorRegions)
+		case model.ChannelEmail:
+			configID, err = h.emailService.AddEmailConfig(userID, item.EmailAddress, item.Name, item.Language,
+				item.NotifyOnDown, item.NotifyOnUp, item.IsActive, item.MonitorRegions)
+		}
+
+		if err != nil {
+			result.Errors = []string{err.Error()}
+			response.Failed = append(response.Failed, result)
+			continue
+		}
+
+		result.ID = configID
+		response.Success = append(response.Success, result)
+		response.Added++
+	}
+
+	statusCode := http.StatusOK
+	if response.Added == 0 {
+		statusCode = http.StatusBadRequest
+	} else if len(response.Failed) > 0 {
+		statusCode = http.StatusPartialContent // 206 Partial Content
+	}
+
+	c.JSON(statusCode, response)
+}
+
+// digestSettingsService is implemented by every channel service supporting digest delivery
+type digestSettingsService interface {
+	GetDigestSettings(configID, userID int) (*model.DigestSettings, error)
+	UpdateDigestSettings(configID, userID int, req model.DigestSettingsRequest) error
+}
+
+// digestService returns the channel service for the :channel parameter
+func (h *NotificationHandler) digestService(channel string) digestSettingsService {
+	switch channel {
+	case model.ChannelTelegram:
+		return h.telegramService
+	case model.ChannelEmail:
+		return h.emailService
+	}
+	return nil
+}
+
+// GetDigestSettings handles GET /api/notifications/configs/:channel/:id/digest
+func (h *NotificationHandler) GetDigestSettings(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	svc := h.digestService(c.Param("channel"))
+	if svc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported channel"})
+		return
+	}
+
+	configID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid configuration ID"})
+		return
+	}
+
+	settings, err := svc.GetDigestSettings(configID, userID)
+	if err != nil {
+		if errors.Is(err, notification.ErrConfigNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Configuration not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateDigestSettings handles PUT /api/notifications/configs/:channel/:id/digest
+func (h *NotificationHandler) UpdateDigestSettings(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	svc := h.digestService(c.Param("channel"))
+	if svc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported channel"})
+		return
+	}
+
+	configID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid configuration ID"})
+		return
+	}
+
+	var req model.DigestSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := svc.UpdateDigestSettings(configID, userID, req); err != nil {
+		if errors.Is(err, notification.ErrConfigNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Configuration not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Digest settings updated successfully"})
+}
+
+// languageFallbackService is implemented by every channel service supporting language fallbacks
+type languageFallbackService interface {
+	GetLanguageFallbacks(configID, userID int) (*model.LanguageFallbacks, error)
+	UpdateLanguageFallbacks(configID, userID int, req model.LanguageFallbacksRequest) error
+}
+
+// languageService returns the channel service for the :channel parameter
+func (h *NotificationHandler) languageService(channel string) languageFallbackService {
+	switch channel {
+	case model.ChannelTelegram:
+		return h.telegramService
+	case model.ChannelEmail:
+		return h.emailService
+	}
+	return nil
+}
+
+// GetLanguageFallbacks handles GET /api/notifications/configs/:channel/:id/languages
+func (h *NotificationHandler) GetLanguageFallbacks(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	svc := h.languageService(c.Param("channel"))
+	if svc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported channel"})
+		return
+	}
+
+	configID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid configuration ID"})
+		return
+	}
+
+	fallbacks, err := svc.GetLanguageFallbacks(configID, userID)
+	if err != nil {
+		if errors.Is(err, notification.ErrConfigNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Configuration not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, fallbacks)
+}
+
+// UpdateLanguageFallbacks handles PUT /api/notifications/configs/:channel/:id/languages
+func (h *NotificationHandler) UpdateLanguageFallbacks(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	svc := h.languageService(c.Param("channel"))
+	if svc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported channel"})
+		return
+	}
+
+	configID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid configuration ID"})
+		return
+	}
+
+	var req model.LanguageFallbacksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := svc.UpdateLanguageFallbacks(configID, userID, req); err != nil {
+		switch {
+		case errors.Is(err, notification.ErrConfigNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Configuration not found"})
+		case errors.Is(err, service.ErrIncompleteLanguageChain):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No language in the chain covers all alert messages"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Language fallbacks updated successfully"})
+}
+
+// configTagService is implemented by every channel service supporting tag subscriptions
+type configTagService interface {
+	GetConfigTags(configID, userID int) ([]string, error)
+	UpdateConfigTags(configID, userID int, tags []string) ([]string, error)
+}
+
+// tagService returns the channel service for the :channel parameter
+func (h *NotificationHandler) tagService(channel string) configTagService {
+	switch channel {
+	case model.ChannelTelegram:
+		return h.telegramService
+	case model.ChannelEmail:
+		return h.emailService
+	}
+	return nil
+}
+
+// GetConfigTags handles GET /api/notifications/configs/:channel/:id/tags
+func (h *NotificationHandler) GetConfigTags(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	svc := h.tagService(c.Param("channel"))
+	if svc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported channel"})
+		return
+	}
+
+	configID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid configuration ID"})
+		return
+	}
+
+	tags, err := svc.GetConfigTags(configID, userID)
+	if err != nil {
+		if errors.Is(err, notification.ErrConfigNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Configuration not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.TagsResponse{Tags: tags})
+}
+
+// UpdateConfigTags handles PUT /api/notifications/configs/:channel/:id/tags
+func (h *NotificationHandler) UpdateConfigTags(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	svc := h.tagService(c.Param("channel"))
+	if svc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported channel"})
+		return
+	}
+
+	configID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid configuration ID"})
+		return
+	}
+
+	var req model.TagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tags, err := svc.UpdateConfigTags(configID, userID, req.Tags)
+	if err != nil {
+		switch {
+		case errors.Is(err, notification.ErrConfigNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Configuration not found"})
+		case strings.HasPrefix(err.Error(), "invalid tag"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, model.TagsResponse{Tags: tags})
+}
+
+// quietHoursService is implemented by every channel service supporting quiet hours
+type quietHoursService interface {
+	GetQuietHours(configID, userID int) (*model.QuietHours, error)
+	UpdateQuietHours(configID, userID int, req model.QuietHoursRequest) (*model.QuietHours, error)
+}
+
+// quietHoursService returns the channel service for the :channel parameter
+func (h *NotificationHandler) quietHoursService(channel string) quietHoursService {
+	switch channel {
+	case model.ChannelTelegram:
+		return h.telegramService
+	case model.ChannelEmail:
+		return h.emailService
+	}
+	return nil
+}
+
+// GetQuietHours handles GET /api/notifications/configs/:channel/:id/quiet-hours
+func (h *NotificationHandler) GetQuietHours(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	svc := h.quietHoursService(c.Param("channel"))
+	if svc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported channel"})
+		return
+	}
+
+	configID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid configuration ID"})
+		return
+	}
+
+	quiet, err := svc.GetQuietHours(configID, userID)
+	if err != nil {
+		if errors.Is(err, notification.ErrConfigNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Configuration not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, quiet)
+}
+
+// UpdateQuietHours handles PUT /api/notifications/configs/:channel/:id/quiet-hours
+func (h *NotificationHandler) UpdateQuietHours(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	svc := h.quietHoursService(c.Param("channel"))
+	if svc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported channel"})
+		return
+	}
+
+	configID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid configuration ID"})
+		return
+	}
+
+	var req model.QuietHoursRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quiet, err := svc.UpdateQuietHours(configID, userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, notification.ErrConfigNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Configuration not found"})
+		case strings.HasPrefix(err.Error(), "invalid time"), strings.HasPrefix(err.Error(), "invalid timezone"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, quiet)
+}
+
+// configTimezoneService is implemented by every channel service supporting timezone preferences
+type configTimezoneService interface {
+	GetConfigTimezone(configID, userID int) (*model.ConfigTimezone, error)
+	UpdateConfigTimezone(configID, userID int, timezone string) (*model.ConfigTimezone, error)
+}
+
+// timezoneService returns the channel service for the :channel parameter
+func (h *NotificationHandler) timezoneService(channel string) configTimezoneService {
+	switch channel {
+	case model.ChannelTelegram:
+		return h.telegramService
+	case model.ChannelEmail:
+		return h.emailService
+	}
+	return nil
+}
+
+// GetConfigTimezone handles GET /api/notifications/configs/:channel/:id/timezone
+func (h *NotificationHandler) GetConfigTimezone(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	svc := h.timezoneService(c.Param("channel"))
+	if svc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported channel"})
+		return
+	}
+
+	configID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid configuration ID"})
+		return
+	}
+
+	timezone, err := svc.GetConfigTimezone(configID, userID)
+	if err != nil {
+		if errors.Is(err, notification.ErrConfigNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Configuration not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, timezone)
+}
+
+// UpdateConfigTimezone handles PUT /api/notifications/configs/:channel/:id/timezone
+func (h *NotificationHandler) UpdateConfigTimezone(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	svc := h.timezoneService(c.Param("channel"))
+	if svc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported channel"})
+		return
+	}
+
+	configID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid configuration ID"})
+		return
+	}
+
+	var req model.TimezoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	timezone, err := svc.UpdateConfigTimezone(configID, userID, req.Timezone)
+	if err != nil {
+		switch {
+		case errors.Is(err, notification.ErrConfigNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Configuration not found"})
+		case strings.HasPrefix(err.Error(), "invalid timezone"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, timezone)
+}
+
+// GetRoutingRules handles GET /api/notifications/routing-rules
+func (h *NotificationHandler) GetRoutingRules(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	rules, err := h.routingService.GetRules(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+// AddRoutingRule handles POST /api/notifications/routing-rules
+func (h *NotificationHandler) AddRoutingRule(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req model.RoutingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ruleID, err := h.routingService.AddRule(userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, notification.ErrConfigNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Configuration not found"})
+		case errors.Is(err, errInvalidRegion):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid region"})
+		case errors.Is(err, notification.ErrUnsupportedChannel):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported channel"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"id":      ruleID,
+		"message": "Routing rule added successfully",
+	})
+}
+
+// DeleteRoutingRule handles DELETE /api/notifications/routing-rules/:id
+func (h *NotificationHandler) DeleteRoutingRule(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid routing rule ID"})
+		return
+	}
+
+	if err := h.routingService.DeleteRule(userID, ruleID); err != nil {
+		if errors.Is(err, notification.ErrRoutingRuleNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Routing rule not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Routing rule deleted successfully"})
+}
+
+// GetEscalationRules handles GET /api/notifications/escalation-rules
+func (h *NotificationHandler) GetEscalationRules(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	rules, err := h.escalationService.GetRules(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+// AddEscalationRule handles POST /api/notifications/escalation-rules
+func (h *NotificationHandler) AddEscalationRule(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req model.EscalationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ruleID, err := h.escalationService.AddRule(userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, notification.ErrConfigNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Configuration not found"})
+		case errors.Is(err, notification.ErrUnsupportedChannel):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported channel"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"id":      ruleID,
+		"message": "Escalation rule added successfully",
+	})
+}
+
+// DeleteEscalationRule handles DELETE /api/notifications/escalation-rules/:id
+func (h *NotificationHandler) DeleteEscalationRule(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid escalation rule ID"})
+		return
+	}
+
+	if err := h.escalationService.DeleteRule(userID, ruleID); err != nil {
+		if errors.Is(err, notification.ErrEscalationRuleNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Escalation rule not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Escalation rule deleted successfully"})
+}
+
+// GetHistory handles GET /api/notifications/history
+func (h *NotificationHandler) GetHistory(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	filter := model.NotificationHistoryFilter{
+		Channel:        c.Query("channel"),
+		Type:           c.Query("type"),
+		DeliveryStatus: c.Query("status"),
+	}
+	if raw := c.Query("domain_id"); raw != "" {
+		domainID, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+			return
+		}
+		filter.DomainID = domainID
+	}
+	var err error
+	if filter.From, err = parseTimeQuery(c, "from"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from time, expected RFC 3339"})
+		return
+	}
+	if filter.To, err = parseTimeQuery(c, "to"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to time, expected RFC 3339"})
+		return
+	}
+	filter.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "100"))
+
+	entries, err := h.historyService.GetHistory(userID, filter)
+	if err != nil {
+		if errors.Is(err, notification.ErrUnsupportedChannel) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported channel"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"notifications": entries})
+}
+
+// ResendNotification handles POST /api/notifications/:id/resend
+func (h *NotificationHandler) ResendNotification(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	entryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	entry, err := h.historyService.Resend(userID, entryID)
+	if err != nil {
+		switch {
+		case errors.Is(err, notification.ErrNotificationAbsent), errors.Is(err, errDomainNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		case errors.Is(err, notification.ErrConfigNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Configuration not found"})
+		case errors.Is(err, notification.ErrAlreadyDelivered), errors.Is(err, notification.ErrNotResendable):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if entry.DeliveryStatus != model.DeliverySent {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Resend failed", "notification": entry})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"notification": entry})
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"domain-detection-go/internal/probe"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProbeHandler handles domain capability probe requests
+type ProbeHandler struct {
+	probeService *probe.ProbeService
+}
+
+// NewProbeHandler creates a new probe handler
+func NewProbeHandler(probeService *probe.ProbeService) *ProbeHandler {
+	return &ProbeHandler{
+		probeService: probeService,
+	}
+}
+
+// GetTLSHistory handles GET /api/domains/:id/tls-history
+func (h *ProbeHandler) GetTLSHistory(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	probes, err := h.probeService.GetHistory(domainID, userID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"probes": probes})
+}
+
+// GetRegistration handles GET /api/domains/:id/registration
+func (h *ProbeHandler) GetRegistration(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	registration, err := h.probeService.GetRegistration(domainID, userID)
+	if err != nil {
+		if errors.Is(err, probe.ErrRegistrationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Registration not checked yet"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, registration)
+}
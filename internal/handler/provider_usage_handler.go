@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"domain-detection-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxProviderUsageDays is the longest period the provider usage report covers
+const maxProviderUsageDays = 90
+
+// ProviderUsageHandler handles provider API usage admin requests
+type ProviderUsageHandler struct {
+	usageService *service.ProviderUsageService
+}
+
+// NewProviderUsageHandler creates a new provider usage handler
+func NewProviderUsageHandler(usageService *service.ProviderUsageService) *ProviderUsageHandler {
+	return &ProviderUsageHandler{
+		usageService: usageService,
+	}
+}
+
+// GetUsage handles GET /api/admin/provider-usage?days=30
+func (h *ProviderUsageHandler) GetUsage(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days < 1 || days > maxProviderUsageDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 90"})
+		return
+	}
+
+	usage, err := h.usageService.GetUsage(days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"days": days, "providers": usage})
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"domain-detection-go/internal/report"
+	"domain-detection-go/pkg/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// publicPageTemplate renders a public status page for browsers
+var publicPageTemplate = template.Must(template.New("status").Funcs(template.FuncMap{
+	"uptime": func(pct *float64) string {
+		if pct == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%.2f%%", *pct)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <meta http-equiv="refresh" content="60">
+    <title>{{if .Title}}{{.Title}}{{else}}Status{{end}}</title>
+    <style>
+        body { font-family: Arial, sans-serif; color: #333; max-width: 720px; margin: 40px auto; padding: 0 16px; }
+        .banner { padding: 16px; border-radius: 5px; color: #fff; margin-bottom: 24px; }
+        .ok { background-color: #27ae60; }
+        .issue { background-color: #e74c3c; }
+        table { width: 100%; border-collapse: collapse; }
+        td, th { padding: 10px 8px; border-bottom: 1px solid #eee; text-align: left; }
+        .up { color: #27ae60; } .down { color: #e74c3c; } .unknown { color: #999; }
+        footer { color: #666; font-size: 12px; margin-top: 24px; }
+    </style>
+</head>
+<body>
+    <h1>{{if .Title}}{{.Title}}{{else}}Status{{end}}</h1>
+    {{if .AllUp}}<div class="banner ok">All systems operational</div>{{else}}<div class="banner issue">Some systems are down</div>{{end}}
+    <table>
+        <tr><th>Domain</th><th>Status</th><th>Uptime (30 days)</th></tr>
+        {{range .Domains}}<tr><td>{{.Name}}</td><td class="{{.Status}}">{{.Status}}</td><td>{{uptime .Uptime30d}}</td></tr>
+        {{end}}
+    </table>
+    <footer>Updated {{.GeneratedAt.UTC.Format "2006-01-02 15:04:05"}} UTC</footer>
+</body>
+</html>`))
+
+// PublicPageHandler handles the hosted status page and its settings
+type PublicPageHandler struct {
+	reportService *report.ReportService
+}
+
+// NewPublicPageHandler creates a new public status page handler
+func NewPublicPageHandler(reportService *report.ReportService) *PublicPageHandler {
+	return &PublicPageHandler{
+		reportService: reportService,
+	}
+}
+
+// GetSettings handles GET /api/status-pages/public
+func (h *PublicPageHandler) GetSettings(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	settings, err := h.reportService.GetPublicPageSettings(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get status page settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateSettings handles PUT /api/status-pages/public
+func (h *PublicPageHandler) UpdateSettings(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req model.PublicPageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := h.reportService.UpdatePublicPageSettings(userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, report.ErrInvalidSlug):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid slug: use 3 to 64 lowercase letters, digits or '-'"})
+		case errors.Is(err, report.ErrSlugTaken):
+			c.JSON(http.StatusConflict, gin.H{"error": "Slug already taken"})
+		case errors.Is(err, errDomainNotFound):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update status page: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// GetPublicPage handles GET /status/:publicSlug. Browsers get HTML, API clients JSON.
+func (h *PublicPageHandler) GetPublicPage(c *gin.Context) {
+	page, err := h.reportService.GetPublicPage(c.Param("publicSlug"))
+	if err != nil {
+		if errors.Is(err, report.ErrPageNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Status page not found"})
+			return
+		}
+		requestLogger(c).Error("Failed to build public status page", "slug", c.Param("publicSlug"), "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build status page"})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=60")
+	if c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML {
+		var body strings.Builder
+		if err := publicPageTemplate.Execute(&body, page); err != nil {
+			requestLogger(c).Error("Failed to render public status page", "slug", c.Param("publicSlug"), "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render status page"})
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(body.String()))
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
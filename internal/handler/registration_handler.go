@@ -1,9 +1,12 @@
 package handler
 
 import (
-	"domain-detection-go/pkg/model"
+	"errors"
 	"net/http"
 
+	"domain-detection-go/internal/auth"
+	"domain-detection-go/pkg/model"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -17,7 +20,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 	userID, err := h.authService.RegisterUser(req)
 	if err != nil {
-		if err.Error() == "username already exists" || err.Error() == "email already exists" {
+		if errors.Is(err, auth.ErrUsernameExists) || errors.Is(err, auth.ErrEmailExists) {
 			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 			return
 		}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"domain-detection-go/internal/report"
+	"domain-detection-go/pkg/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReportHandler handles uptime and SLA report requests
+type ReportHandler struct {
+	reportService *report.ReportService
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(reportService *report.ReportService) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+	}
+}
+
+// GetDomainUptime handles GET /api/domains/:id/uptime
+func (h *ReportHandler) GetDomainUptime(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	uptime, err := h.reportService.GetDomainUptime(userID, domainID)
+	if err != nil {
+		if errors.Is(err, errDomainNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build uptime report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, uptime)
+}
+
+// GetDomainStats handles GET /api/domains/:id/stats?window=24h|7d|30d
+func (h *ReportHandler) GetDomainStats(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	stats, err := h.reportService.GetDomainResponseStats(userID, domainID, c.DefaultQuery("window", model.UptimeWindow24h))
+	if err != nil {
+		switch {
+		case errors.Is(err, errDomainNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		case errors.Is(err, report.ErrInvalidWindow):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid window, expected 24h, 7d or 30d"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build response time statistics"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetUptimeReport handles GET /api/reports/uptime
+func (h *ReportHandler) GetUptimeReport(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	reports, err := h.reportService.GetUptimeReport(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build uptime report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"domains": reports})
+}
@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"domain-detection-go/internal/domain"
+	"domain-detection-go/pkg/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RunbookHandler handles remediation runbook requests
+type RunbookHandler struct {
+	domainService *domain.DomainService
+}
+
+// NewRunbookHandler creates a new runbook handler
+func NewRunbookHandler(domainService *domain.DomainService) *RunbookHandler {
+	return &RunbookHandler{
+		domainService: domainService,
+	}
+}
+
+// GetRunbooks handles GET /api/runbooks
+func (h *RunbookHandler) GetRunbooks(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	runbooks, err := h.domainService.GetRunbooks(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch runbooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"runbooks": runbooks})
+}
+
+// GetDomainRunbook handles GET /api/domains/:id/runbook. The runbook of the domain's
+// region is returned when the domain has none of its own.
+func (h *RunbookHandler) GetDomainRunbook(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	d, err := h.domainService.GetDomain(domainID, userID)
+	if err != nil {
+		if errors.Is(err, errDomainNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch domain"})
+		return
+	}
+
+	runbook, err := h.domainService.ResolveRunbook(*d)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch runbook"})
+		return
+	}
+	if runbook == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Runbook not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, runbook)
+}
+
+// SetDomainRunbook handles PUT /api/domains/:id/runbook
+func (h *RunbookHandler) SetDomainRunbook(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	var req model.RunbookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.domainService.SetDomainRunbook(userID, domainID, req); err != nil {
+		switch {
+		case errors.Is(err, errDomainNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		case errors.Is(err, errRunbookEmpty):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Runbook URL or notes are required"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set runbook: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Runbook updated successfully"})
+}
+
+// DeleteDomainRunbook handles DELETE /api/domains/:id/runbook
+func (h *RunbookHandler) DeleteDomainRunbook(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	domainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	if err := h.domainService.DeleteDomainRunbook(userID, domainID); err != nil {
+		if errors.Is(err, errRunbookNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Runbook not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete runbook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Runbook deleted successfully"})
+}
+
+// SetRegionRunbook handles PUT /api/runbooks/regions/:region
+func (h *RunbookHandler) SetRegionRunbook(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req model.RunbookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.domainService.SetRegionRunbook(userID, c.Param("region"), req); err != nil {
+		switch {
+		case errors.Is(err, errInvalidRegion):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid region"})
+		case errors.Is(err, errRunbookEmpty):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Runbook URL or notes are required"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set runbook: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Runbook updated successfully"})
+}
+
+// DeleteRegionRunbook handles DELETE /api/runbooks/regions/:region
+func (h *RunbookHandler) DeleteRegionRunbook(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.domainService.DeleteRegionRunbook(userID, c.Param("region")); err != nil {
+		if errors.Is(err, errRunbookNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Runbook not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete runbook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Runbook deleted successfully"})
+}
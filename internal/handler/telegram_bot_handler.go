@@ -1,68 +1,319 @@
 package handler
 
 import (
+	"context"
+	"crypto/subtle"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"domain-detection-go/internal/deepcheck"
 	"domain-detection-go/internal/domain"
 	"domain-detection-go/internal/notification"
+	"domain-detection-go/internal/service"
 	"domain-detection-go/pkg/model"
 
 	"github.com/gin-gonic/gin"
 )
 
 type TelegramBotHandler struct {
-	telegramService *notification.TelegramService
-	domainService   *domain.DomainService
+	telegramService  *notification.TelegramService
+	domainService    *domain.DomainService
+	deepCheckService *service.DeepCheckService
+	deepCheckClient  *deepcheck.DeepCheckClient
+	confirmations    *removalConfirmations
+	webhookSecret    string // Empty accepts unauthenticated updates outside production
 }
 
-func NewTelegramBotHandler(telegramService *notification.TelegramService, domainService *domain.DomainService) *TelegramBotHandler {
+func NewTelegramBotHandler(telegramService *notification.TelegramService, domainService *domain.DomainService,
+	deepCheckService *service.DeepCheckService, deepCheckClient *deepcheck.DeepCheckClient, webhookSecret string) *TelegramBotHandler {
 	return &TelegramBotHandler{
-		telegramService: telegramService,
-		domainService:   domainService,
+		telegramService:  telegramService,
+		domainService:    domainService,
+		deepCheckService: deepCheckService,
+		deepCheckClient:  deepCheckClient,
+		confirmations:    newRemovalConfirmations(),
+		webhookSecret:    webhookSecret,
 	}
 }
 
-// WebhookHandler handles incoming webhook requests from Telegram
+// WebhookHandler handles incoming webhook requests from Telegram. The chat of an update
+// is trusted to run commands for its linked account, so updates must carry the secret
+// the webhook was registered with.
 func (h *TelegramBotHandler) WebhookHandler(c *gin.Context) {
+	if h.webhookSecret == "" {
+		requestLogger(c).Warn("No TELEGRAM_WEBHOOK_SECRET configured, skipping authentication")
+	} else if subtle.ConstantTimeCompare([]byte(c.GetHeader("X-Telegram-Bot-Api-Secret-Token")), []byte(h.webhookSecret)) != 1 {
+		requestLogger(c).Warn("Rejected Telegram webhook with invalid or missing secret", "client_ip", c.ClientIP())
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
 	var update TelegramUpdate
 	if err := c.ShouldBindJSON(&update); err != nil {
-		log.Printf("Error parsing webhook: %v", err)
+		requestLogger(c).Warn("Failed to parse Telegram webhook", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
 		return
 	}
 
 	// Handle different types of updates
 	if update.Message != nil {
-		h.handleMessage(update.Message)
+		h.handleMessage(c.Request.Context(), update.Message)
 	} else if update.CallbackQuery != nil {
-		h.handleCallbackQuery(update.CallbackQuery)
+		h.handleCallbackQuery(c.Request.Context(), update.CallbackQuery)
 	}
 
 	c.JSON(http.StatusOK, gin.H{"ok": true})
 }
 
 // handleMessage processes incoming text messages
-func (h *TelegramBotHandler) handleMessage(message *TelegramMessage) {
-	if message.Text == "" {
+func (h *TelegramBotHandler) handleMessage(ctx context.Context, message *TelegramMessage) {
+	args := strings.Fields(message.Text)
+	if len(args) == 0 {
 		return
 	}
 
 	chatID := fmt.Sprintf("%d", message.Chat.ID)
 
-	switch {
-	case strings.HasPrefix(message.Text, "/rm"):
+	// In group chats commands may be addressed to the bot as /command@BotName
+	command, _, _ := strings.Cut(args[0], "@")
+	switch command {
+	case "/rm":
 		h.handleRemoveCommand(chatID)
-	case strings.HasPrefix(message.Text, "/start"):
+	case "/add":
+		h.handleAddCommand(ctx, chatID, args[1:])
+	case "/status":
+		h.handleStatusCommand(chatID)
+	case "/list":
+		h.handleListCommand(chatID)
+	case "/deepcheck":
+		h.handleDeepCheckCommand(chatID, args[1:])
+	case "/link":
+		h.handleLinkCommand(chatID, message.Chat, args[1:])
+	case "/start":
 		h.handleStartCommand(chatID)
-	case strings.HasPrefix(message.Text, "/help"):
+	case "/help":
 		h.handleHelpCommand(chatID)
 	}
 }
 
+// telegramListLimit caps the domains listed in one message, which Telegram limits to
+// 4096 characters
+const telegramListLimit = 50
+
+// handleAddCommand processes the /add <url> <region> command
+func (h *TelegramBotHandler) handleAddCommand(ctx context.Context, chatID string, args []string) {
+	if len(args) != 2 {
+		h.telegramService.SendMessage(chatID, "Usage: /add <url> <region>\nExample: /add example.com HK")
+		return
+	}
+
+	userID, err := h.telegramService.GetUserIDByChatID(chatID)
+	if err != nil {
+		h.telegramService.SendMessage(chatID, "❌ You are not registered or your Telegram is not configured. Please configure your Telegram notifications first.")
+		return
+	}
+
+	req := model.DomainAddRequest{Name: args[0], Region: strings.ToUpper(args[1])}
+	domainID, err := h.domainService.AddDomain(ctx, userID, req)
+	if err != nil {
+		var reason string
+		var intervalErr *intervalError
+		switch {
+		case errors.Is(err, errInvalidDomainName):
+			reason = "Invalid domain name format"
+		case errors.Is(err, errDomainLimitReached):
+			reason = "Domain limit reached"
+		case errors.Is(err, errDomainExists):
+			reason = "This domain is already being monitored in this region"
+		case errors.Is(err, errInvalidRegion):
+			reason = "Invalid region"
+		case errors.As(err, &intervalErr):
+			reason = intervalMessage(intervalErr)
+		default:
+			slog.Error("Failed to add domain from Telegram", "error", err)
+			reason = "Failed to add domain, please try again later"
+		}
+		h.telegramService.SendMessage(chatID, fmt.Sprintf("❌ %s", reason))
+		return
+	}
+
+	h.telegramService.SendMessage(chatID, fmt.Sprintf("✅ Added **%s** (%s), domain #%d. Monitoring starts in a few minutes.", req.Name, req.Region, domainID))
+}
+
+// handleStatusCommand processes the /status command
+func (h *TelegramBotHandler) handleStatusCommand(chatID string) {
+	userID, err := h.telegramService.GetUserIDByChatID(chatID)
+	if err != nil {
+		h.telegramService.SendMessage(chatID, "❌ You are not registered or your Telegram is not configured. Please configure your Telegram notifications first.")
+		return
+	}
+
+	summary, err := h.domainService.GetDomainSummary(userID)
+	if err != nil {
+		h.telegramService.SendMessage(chatID, "❌ Error retrieving your domains. Please try again later.")
+		return
+	}
+
+	message := fmt.Sprintf("📊 **Domain status**\n\n🟢 Up: %d\n🔴 Down: %d\n⏸️ Paused: %d\n📦 Archived: %d\n\n%d of %d domains used",
+		summary.ActiveDomains-summary.DownDomains, summary.DownDomains, summary.TotalDomains-summary.ActiveDomains,
+		summary.ArchivedDomains, summary.TotalDomains, summary.DomainLimit)
+	h.telegramService.SendMessage(chatID, message)
+}
+
+// handleListCommand processes the /list command
+func (h *TelegramBotHandler) handleListCommand(chatID string) {
+	userID, err := h.telegramService.GetUserIDByChatID(chatID)
+	if err != nil {
+		h.telegramService.SendMessage(chatID, "❌ You are not registered or your Telegram is not configured. Please configure your Telegram notifications first.")
+		return
+	}
+
+	domainResponse, err := h.domainService.GetDomains(userID)
+	if err != nil {
+		h.telegramService.SendMessage(chatID, "❌ Error retrieving your domains. Please try again later.")
+		return
+	}
+
+	if len(domainResponse.Domains) == 0 {
+		h.telegramService.SendMessage(chatID, "📭 You don't have any domains yet. Use /add <url> <region> to add one.")
+		return
+	}
+
+	message := fmt.Sprintf("📋 **Your domains** (%d):\n\n", len(domainResponse.Domains))
+	for i, domain := range domainResponse.Domains {
+		if i == telegramListLimit {
+			message += fmt.Sprintf("\n… and %d more", len(domainResponse.Domains)-telegramListLimit)
+			break
+		}
+		status := "🟢"
+		switch {
+		case domain.ArchivedAt != nil:
+			status = "📦"
+		case !domain.Active:
+			status = "⏸️"
+		case !domain.Available():
+			status = "🔴"
+		}
+		message += fmt.Sprintf("%d. %s %s (%s)\n", i+1, status, domain.Name, domain.Region)
+	}
+
+	h.telegramService.SendMessage(chatID, message)
+}
+
+// handleLinkCommand processes the /link <code> command, registering the chat for the
+// account that generated the code in the web interface
+func (h *TelegramBotHandler) handleLinkCommand(chatID string, chat TelegramChat, args []string) {
+	if len(args) != 1 {
+		h.telegramService.SendMessage(chatID, "Usage: /link <code>\nGenerate a code in the web interface under Telegram settings.")
+		return
+	}
+
+	chatName := chat.Title
+	if chatName == "" {
+		chatName = chat.Username
+	}
+	if chatName == "" {
+		chatName = chat.FirstName
+	}
+
+	_, _, err := h.telegramService.LinkChat(args[0], chatID, chatName)
+	if err != nil {
+		switch {
+		case errors.Is(err, notification.ErrInvalidLinkCode):
+			h.telegramService.SendMessage(chatID, "❌ This code is invalid or has expired. Please generate a new one in the web interface.")
+		case errors.Is(err, notification.ErrChatLinkedElsewhere):
+			h.telegramService.SendMessage(chatID, "❌ This chat is already linked to another account.")
+		default:
+			slog.Error("Failed to link Telegram chat", "chat_id", chatID, "error", err)
+			h.telegramService.SendMessage(chatID, "❌ Failed to link this chat, please try again later")
+		}
+		return
+	}
+
+	h.telegramService.SendMessage(chatID, "✅ This chat is now linked to your account and will receive notifications. Use /help to see what the bot can do.")
+}
+
+// handleDeepCheckCommand processes the /deepcheck <domain> command. The report is sent
+// back to this chat once the deep check provider calls back. Deep checks spend paid
+// credits, so the command is only reached through updates carrying the webhook secret.
+func (h *TelegramBotHandler) handleDeepCheckCommand(chatID string, args []string) {
+	if len(args) != 1 {
+		h.telegramService.SendMessage(chatID, "Usage: /deepcheck <domain>\nExample: /deepcheck example.com")
+		return
+	}
+
+	userID, err := h.telegramService.GetUserIDByChatID(chatID)
+	if err != nil {
+		h.telegramService.SendMessage(chatID, "❌ You are not registered or your Telegram is not configured. Please configure your Telegram notifications first.")
+		return
+	}
+
+	domainResponse, err := h.domainService.GetDomains(userID)
+	if err != nil {
+		h.telegramService.SendMessage(chatID, "❌ Error retrieving your domains. Please try again later.")
+		return
+	}
+
+	wanted := botDomainHost(args[0])
+	var d *model.Domain
+	for i := range domainResponse.Domains {
+		if botDomainHost(domainResponse.Domains[i].Name) == wanted {
+			d = &domainResponse.Domains[i]
+			break
+		}
+	}
+	if d == nil {
+		h.telegramService.SendMessage(chatID, fmt.Sprintf("❌ Domain %s not found. Use /list to see your domains.", args[0]))
+		return
+	}
+	if d.ArchivedAt != nil {
+		h.telegramService.SendMessage(chatID, fmt.Sprintf("❌ %s is archived", d.Name))
+		return
+	}
+
+	pending, err := h.deepCheckService.GetRecentPendingOrder(d.ID, deepCheckCooldownMinutes)
+	if err != nil {
+		h.telegramService.SendMessage(chatID, "❌ Failed to request deep check, please try again later")
+		return
+	}
+	if pending != nil {
+		h.telegramService.SendMessage(chatID, fmt.Sprintf("⏳ A deep check is already running for %s (order %s)", d.Name, pending.OrderID))
+		return
+	}
+
+	response, err := h.deepCheckClient.RequestDeepCheck(d.Name)
+	if err != nil {
+		slog.Error("Failed to request deep check from Telegram", "domain", d.Name, "error", err)
+		h.telegramService.SendMessage(chatID, "❌ Failed to request deep check, please try again later")
+		return
+	}
+
+	if err := h.deepCheckService.CreateDeepCheckOrder(response.OrderID, userID, d.ID, d.Name, model.DeepCheckSourceTelegram, nil); err != nil {
+		slog.Error("Failed to record deep check order", "order_id", response.OrderID, "error", err)
+		h.telegramService.SendMessage(chatID, "❌ Failed to record deep check order, please try again later")
+		return
+	}
+	if err := h.deepCheckService.SetOrderReplyChat(response.OrderID, chatID); err != nil {
+		slog.Error("Failed to set reply chat of deep check order", "order_id", response.OrderID, "error", err)
+	}
+
+	h.telegramService.SendMessage(chatID, fmt.Sprintf("🔍 Deep check of **%s** requested (order %s). The report will be sent here when it is ready.", d.Name, response.OrderID))
+}
+
+// botDomainHost reduces a domain typed in a chat or stored on a domain to its host name,
+// so that example.com matches https://Example.com/
+func botDomainHost(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.TrimPrefix(name, "https://")
+	name = strings.TrimPrefix(name, "http://")
+	host, _, _ := strings.Cut(name, "/")
+	return host
+}
+
 // handleRemoveCommand processes the /rm command
 func (h *TelegramBotHandler) handleRemoveCommand(chatID string) {
 	// Find user by chat ID
@@ -100,16 +351,27 @@ func (h *TelegramBotHandler) handleRemoveCommand(chatID string) {
 }
 
 // handleCallbackQuery processes inline keyboard button clicks
-func (h *TelegramBotHandler) handleCallbackQuery(callback *TelegramCallbackQuery) {
+func (h *TelegramBotHandler) handleCallbackQuery(ctx context.Context, callback *TelegramCallbackQuery) {
 	chatID := fmt.Sprintf("%d", callback.Message.Chat.ID)
 
-	if strings.HasPrefix(callback.Data, "remove_domain_") {
-		h.handleDomainRemoval(chatID, callback.Data, callback.ID)
+	switch {
+	case strings.HasPrefix(callback.Data, "remove_domain_"):
+		h.handleDomainSelection(chatID, callback.Data, callback.ID)
+	case strings.HasPrefix(callback.Data, "remove_confirm_"):
+		h.handleDomainRemoval(ctx, chatID, callback.Data, callback.ID)
+	case strings.HasPrefix(callback.Data, "remove_cancel_"):
+		h.handleRemovalCancel(chatID, callback.Data, callback.ID)
+	case strings.HasPrefix(callback.Data, "remove_undo_"):
+		h.handleRemovalUndo(ctx, chatID, callback.Data, callback.ID)
+	case strings.HasPrefix(callback.Data, notification.IncidentAckCallbackPrefix):
+		h.handleIncidentAck(chatID, callback.Data, callback.ID, callback.From)
+	case strings.HasPrefix(callback.Data, notification.SnoozeCallbackPrefix):
+		h.handleDomainSnooze(chatID, callback.Data, callback.ID)
 	}
 }
 
-// handleDomainRemoval processes domain removal
-func (h *TelegramBotHandler) handleDomainRemoval(chatID, callbackData, callbackQueryID string) {
+// handleDomainSelection asks for confirmation before a selected domain is removed
+func (h *TelegramBotHandler) handleDomainSelection(chatID, callbackData, callbackQueryID string) {
 	// Extract domain ID from callback data
 	parts := strings.Split(callbackData, "_")
 	if len(parts) != 3 {
@@ -130,24 +392,167 @@ func (h *TelegramBotHandler) handleDomainRemoval(chatID, callbackData, callbackQ
 		return
 	}
 
-	// Get domain details before deletion
 	domain, err := h.domainService.GetDomain(domainID, userID)
 	if err != nil {
 		h.telegramService.AnswerCallbackQuery(callbackQueryID, "❌ Domain not found")
 		return
 	}
 
+	token, err := h.confirmations.add(chatID, userID, domainID)
+	if err != nil {
+		slog.Error("Failed to create removal confirmation", "error", err)
+		h.telegramService.AnswerCallbackQuery(callbackQueryID, "❌ Please try again")
+		return
+	}
+
+	h.telegramService.AnswerCallbackQuery(callbackQueryID, "")
+	keyboard := [][]notification.TelegramInlineKeyboardButton{{
+		{Text: "✅ Yes, remove", CallbackData: "remove_confirm_" + token},
+		{Text: "✖️ Cancel", CallbackData: "remove_cancel_" + token},
+	}}
+	h.telegramService.SendMessageWithKeyboard(chatID, fmt.Sprintf("⚠️ Remove **%s** (%s) and delete its monitors?\n\nThis confirmation expires in %d minutes.",
+		domain.Name, domain.Region, int(removalConfirmationTTL.Minutes())), keyboard)
+}
+
+// handleDomainRemoval removes a domain once its removal is confirmed
+func (h *TelegramBotHandler) handleDomainRemoval(ctx context.Context, chatID, callbackData, callbackQueryID string) {
+	pending, ok := h.confirmations.take(strings.TrimPrefix(callbackData, "remove_confirm_"), chatID)
+	if !ok {
+		h.telegramService.AnswerCallbackQuery(callbackQueryID, "⌛ This confirmation has expired, use /rm again")
+		return
+	}
+
+	// Get domain details before deletion
+	domain, err := h.domainService.GetDomain(pending.domainID, pending.userID)
+	if err != nil {
+		h.telegramService.AnswerCallbackQuery(callbackQueryID, "❌ Domain not found")
+		return
+	}
+
 	// Delete the domain
-	err = h.domainService.DeleteDomain(userID, domainID)
+	err = h.domainService.DeleteDomain(ctx, pending.userID, pending.domainID)
 	if err != nil {
 		h.telegramService.AnswerCallbackQuery(callbackQueryID, "❌ Failed to delete domain")
 		h.telegramService.SendMessage(chatID, fmt.Sprintf("❌ Failed to remove domain **%s** (%s): %s", domain.Name, domain.Region, err.Error()))
 		return
 	}
 
-	// Success response
+	// Success response, with a way back while the domain is in the trash
 	h.telegramService.AnswerCallbackQuery(callbackQueryID, "✅ Domain removed successfully")
-	h.telegramService.SendMessage(chatID, fmt.Sprintf("✅ Successfully removed domain **%s** (%s)", domain.Name, domain.Region))
+	keyboard := [][]notification.TelegramInlineKeyboardButton{{
+		{Text: "↩️ Undo", CallbackData: fmt.Sprintf("remove_undo_%d", domain.ID)},
+	}}
+	h.telegramService.SendMessageWithKeyboard(chatID, fmt.Sprintf("✅ Successfully removed domain **%s** (%s). It can be restored from the trash for 30 days.", domain.Name, domain.Region), keyboard)
+}
+
+// handleRemovalCancel discards a pending removal
+func (h *TelegramBotHandler) handleRemovalCancel(chatID, callbackData, callbackQueryID string) {
+	h.confirmations.take(strings.TrimPrefix(callbackData, "remove_cancel_"), chatID)
+	h.telegramService.AnswerCallbackQuery(callbackQueryID, "Removal cancelled")
+}
+
+// handleRemovalUndo restores a domain removed through the bot
+func (h *TelegramBotHandler) handleRemovalUndo(ctx context.Context, chatID, callbackData, callbackQueryID string) {
+	domainID, err := strconv.Atoi(strings.TrimPrefix(callbackData, "remove_undo_"))
+	if err != nil {
+		h.telegramService.AnswerCallbackQuery(callbackQueryID, "❌ Invalid domain ID")
+		return
+	}
+
+	userID, err := h.telegramService.GetUserIDByChatID(chatID)
+	if err != nil {
+		h.telegramService.AnswerCallbackQuery(callbackQueryID, "❌ User not found")
+		return
+	}
+
+	if err := h.domainService.RestoreDomain(ctx, userID, domainID); err != nil {
+		switch {
+		case errors.Is(err, errDomainNotFound):
+			h.telegramService.AnswerCallbackQuery(callbackQueryID, "❌ Domain is no longer in the trash")
+		case errors.Is(err, errDomainLimitReached):
+			h.telegramService.AnswerCallbackQuery(callbackQueryID, "❌ Domain limit reached")
+		case errors.Is(err, errDomainExists):
+			h.telegramService.AnswerCallbackQuery(callbackQueryID, "❌ Domain was added again in the meantime")
+		default:
+			h.telegramService.AnswerCallbackQuery(callbackQueryID, "❌ Failed to restore domain")
+		}
+		return
+	}
+
+	h.telegramService.AnswerCallbackQuery(callbackQueryID, "✅ Domain restored")
+	h.telegramService.SendMessage(chatID, "↩️ Domain restored; its monitors are being recreated.")
+}
+
+// handleIncidentAck acknowledges an incident from the button on its down alert, pausing
+// further alerts until recovery or the acknowledgement times out
+func (h *TelegramBotHandler) handleIncidentAck(chatID, callbackData, callbackQueryID string, from TelegramUser) {
+	incidentID, err := strconv.Atoi(strings.TrimPrefix(callbackData, notification.IncidentAckCallbackPrefix))
+	if err != nil {
+		h.telegramService.AnswerCallbackQuery(callbackQueryID, "❌ Invalid incident ID")
+		return
+	}
+
+	userID, err := h.telegramService.GetUserIDByChatID(chatID)
+	if err != nil {
+		h.telegramService.AnswerCallbackQuery(callbackQueryID, "❌ User not found")
+		return
+	}
+
+	incident, err := h.domainService.AcknowledgeIncident(userID, incidentID, telegramUserName(from))
+	if err != nil {
+		switch {
+		case errors.Is(err, errIncidentNotFound):
+			h.telegramService.AnswerCallbackQuery(callbackQueryID, "❌ Incident not found")
+		case errors.Is(err, errIncidentResolved):
+			h.telegramService.AnswerCallbackQuery(callbackQueryID, "✅ Incident already resolved")
+		default:
+			slog.Error("Failed to acknowledge incident", "incident_id", incidentID, "error", err)
+			h.telegramService.AnswerCallbackQuery(callbackQueryID, "❌ Failed to acknowledge incident")
+		}
+		return
+	}
+
+	h.telegramService.AnswerCallbackQuery(callbackQueryID, "✅ Incident acknowledged")
+	h.telegramService.SendMessage(chatID, fmt.Sprintf("👀 Incident #%d (%s) acknowledged by %s. Alerts are paused until it recovers or until %s UTC.",
+		incident.ID, incident.DomainName, incident.AcknowledgedBy, incident.AcknowledgedUntil.UTC().Format("15:04")))
+}
+
+// handleDomainSnooze silences a domain's alerts from the button on its down alert
+func (h *TelegramBotHandler) handleDomainSnooze(chatID, callbackData, callbackQueryID string) {
+	domainID, err := strconv.Atoi(strings.TrimPrefix(callbackData, notification.SnoozeCallbackPrefix))
+	if err != nil {
+		h.telegramService.AnswerCallbackQuery(callbackQueryID, "❌ Invalid domain ID")
+		return
+	}
+
+	userID, err := h.telegramService.GetUserIDByChatID(chatID)
+	if err != nil {
+		h.telegramService.AnswerCallbackQuery(callbackQueryID, "❌ User not found")
+		return
+	}
+
+	snooze, err := h.domainService.SnoozeDomain(userID, domainID, notification.AlertSnoozeDuration)
+	if err != nil {
+		if errors.Is(err, errDomainNotFound) {
+			h.telegramService.AnswerCallbackQuery(callbackQueryID, "❌ Domain not found")
+		} else {
+			slog.Error("Failed to snooze domain", "domain_id", domainID, "error", err)
+			h.telegramService.AnswerCallbackQuery(callbackQueryID, "❌ Failed to snooze domain")
+		}
+		return
+	}
+
+	h.telegramService.AnswerCallbackQuery(callbackQueryID, "🔕 Domain snoozed")
+	h.telegramService.SendMessage(chatID, fmt.Sprintf("🔕 Alerts for **%s** are snoozed until %s UTC. Checks keep running.",
+		snooze.Name, snooze.SnoozedUntil.UTC().Format("15:04")))
+}
+
+// telegramUserName names a Telegram user for the acknowledgement record
+func telegramUserName(user TelegramUser) string {
+	if user.Username != "" {
+		return "@" + user.Username
+	}
+	return strings.TrimSpace(user.FirstName + " " + user.LastName)
 }
 
 // createDomainSelectionKeyboard creates an inline keyboard for domain selection
@@ -179,10 +584,15 @@ func (h *TelegramBotHandler) handleStartCommand(chatID string) {
 	message := `👋 Welcome to Domain Monitor Bot!
 
 Available commands:
+/add <url> <region> - Add a domain to monitoring
+/list - List your domains
+/status - Show how many domains are up and down
+/deepcheck <domain> - Run a deep check and get the report here
 /rm - Remove a domain from monitoring
+/link <code> - Link this chat to your account
 /help - Show this help message
 
-To get started, please configure your Telegram notifications in the web interface.`
+To get started, generate a link code in the web interface and send it with /link.`
 
 	h.telegramService.SendMessage(chatID, message)
 }
@@ -192,15 +602,21 @@ func (h *TelegramBotHandler) handleHelpCommand(chatID string) {
 	message := `🤖 Domain Monitor Bot Help
 
 **Available Commands:**
+/add <url> <region> - Add a domain to monitoring
+/list - List your domains
+/status - Show how many domains are up and down
+/deepcheck <domain> - Run a deep check and get the report here
 /rm - Remove a domain from monitoring
+/link <code> - Link this chat to your account
 /help - Show this help message
 
 **How to use /rm:**
 1. Type /rm
 2. Select a domain from the list
-3. Confirm removal
+3. Confirm removal within 2 minutes
+4. Tap Undo to restore it if needed
 
-**Note:** You need to configure your Telegram notifications in the web interface first.`
+**Note:** Link this chat first with a code from the web interface: /link <code>`
 
 	h.telegramService.SendMessage(chatID, message)
 }
@@ -236,6 +652,9 @@ type TelegramUser struct {
 }
 
 type TelegramChat struct {
-	ID   int64  `json:"id"`
-	Type string `json:"type"`
+	ID        int64  `json:"id"`
+	Type      string `json:"type"`
+	Title     string `json:"title,omitempty"`
+	Username  string `json:"username,omitempty"`
+	FirstName string `json:"first_name,omitempty"`
 }
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTelegramWebhookRequiresSecret(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// The services are never reached: forged updates are refused, the accepted one is empty
+	h := NewTelegramBotHandler(nil, nil, nil, nil, "webhook-secret")
+	r := gin.New()
+	r.POST("/api/telegram/webhook", h.WebhookHandler)
+
+	tests := []struct {
+		name   string
+		secret string
+		want   int
+	}{
+		{"missing", "", http.StatusUnauthorized},
+		{"wrong", "guess", http.StatusUnauthorized},
+		{"valid", "webhook-secret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/telegram/webhook", strings.NewReader(`{"update_id": 1}`))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.secret != "" {
+				req.Header.Set("X-Telegram-Bot-Api-Secret-Token", tt.secret)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+// TestTelegramWebhookRefusesForgedCommands sends updates for commands that act on the
+// linked account without the webhook secret. The handler has no services, so an update
+// that got past the secret check would panic instead of being refused.
+func TestTelegramWebhookRefusesForgedCommands(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := NewTelegramBotHandler(nil, nil, nil, nil, "webhook-secret")
+	r := gin.New()
+	r.POST("/api/telegram/webhook", h.WebhookHandler)
+
+	updates := map[string]string{
+		"deepcheck":    `{"update_id": 1, "message": {"message_id": 1, "chat": {"id": 42}, "text": "/deepcheck example.com"}}`,
+		"add":          `{"update_id": 1, "message": {"message_id": 1, "chat": {"id": 42}, "text": "/add example.com HK"}}`,
+		"link":         `{"update_id": 1, "message": {"message_id": 1, "chat": {"id": 42}, "text": "/link ABC123"}}`,
+		"incident ack": `{"update_id": 1, "callback_query": {"id": "1", "from": {"id": 7}, "message": {"message_id": 1, "chat": {"id": 42}}, "data": "incident_ack_5"}}`,
+		"snooze":       `{"update_id": 1, "callback_query": {"id": "1", "from": {"id": 7}, "message": {"message_id": 1, "chat": {"id": 42}}, "data": "domain_snooze_5"}}`,
+	}
+	for name, update := range updates {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/telegram/webhook", strings.NewReader(update))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Telegram-Bot-Api-Secret-Token", "guess")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+			}
+		})
+	}
+}
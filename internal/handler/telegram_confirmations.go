@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// removalConfirmationTTL is how long a domain removal waits for the user to confirm it
+const removalConfirmationTTL = 2 * time.Minute
+
+// removalConfirmation is a domain removal waiting for the user to confirm it
+type removalConfirmation struct {
+	chatID   string
+	userID   int
+	domainID int
+	expires  time.Time
+}
+
+// removalConfirmations holds the pending domain removals of the Telegram bot, keyed by a
+// short random token carried in the confirmation buttons
+type removalConfirmations struct {
+	mu      sync.Mutex
+	pending map[string]removalConfirmation
+}
+
+func newRemovalConfirmations() *removalConfirmations {
+	return &removalConfirmations{pending: make(map[string]removalConfirmation)}
+}
+
+// add registers a pending removal and returns its token
+func (r *removalConfirmations) add(chatID string, userID, domainID int) (string, error) {
+	raw := make([]byte, 8)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(raw)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for t, c := range r.pending {
+		if now.After(c.expires) {
+			delete(r.pending, t)
+		}
+	}
+	r.pending[token] = removalConfirmation{chatID: chatID, userID: userID, domainID: domainID, expires: now.Add(removalConfirmationTTL)}
+	return token, nil
+}
+
+// take removes a pending removal, returning it if it belongs to the chat and has not expired
+func (r *removalConfirmations) take(token, chatID string) (removalConfirmation, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.pending[token]
+	if !ok || c.chatID != chatID {
+		return removalConfirmation{}, false
+	}
+	delete(r.pending, token)
+	if time.Now().After(c.expires) {
+		return removalConfirmation{}, false
+	}
+	return c, true
+}
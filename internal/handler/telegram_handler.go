@@ -1,10 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"domain-detection-go/internal/etag"
 	"domain-detection-go/internal/notification"
+	"domain-detection-go/internal/service"
 	"domain-detection-go/pkg/model"
 
 	"github.com/gin-gonic/gin"
@@ -12,13 +16,15 @@ import (
 
 // TelegramHandler handles telegram configuration requests
 type TelegramHandler struct {
-	telegramService *notification.TelegramService
+	telegramService   *notification.TelegramService
+	validationService *service.ConfigValidationService
 }
 
 // NewTelegramHandler creates a new telegram handler
-func NewTelegramHandler(telegramService *notification.TelegramService) *TelegramHandler {
+func NewTelegramHandler(telegramService *notification.TelegramService, validationService *service.ConfigValidationService) *TelegramHandler {
 	return &TelegramHandler{
-		telegramService: telegramService,
+		telegramService:   telegramService,
+		validationService: validationService,
 	}
 }
 
@@ -51,6 +57,11 @@ func (h *TelegramHandler) AddTelegramConfig(c *gin.Context) {
 		return
 	}
 
+	if problems := h.validationService.ValidateTelegramConfig(userID, req.ChatID, req.Language, req.MonitorRegions); len(problems) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": strings.Join(problems, "; "), "errors": problems})
+		return
+	}
+
 	// Call service method with updated parameters
 	configID, err := h.telegramService.AddTelegramConfig(
 		userID,
@@ -74,6 +85,24 @@ func (h *TelegramHandler) AddTelegramConfig(c *gin.Context) {
 	})
 }
 
+// CreateLinkCode handles POST /api/telegram/link-code. The user sends the code to the
+// bot as /link <code> from the chat to register.
+func (h *TelegramHandler) CreateLinkCode(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	link, err := h.telegramService.CreateLinkCode(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, link)
+}
+
 // GetTelegramConfigs retrieves all Telegram configurations for a user
 func (h *TelegramHandler) GetTelegramConfigs(c *gin.Context) {
 	userID := c.GetInt("user_id")
@@ -179,36 +208,93 @@ func (h *TelegramHandler) SendTestMessage(c *gin.Context) {
 		return
 	}
 
-	// Verify the config belongs to this user
-	configs, err := h.telegramService.GetTelegramConfigsForUser(userID)
+	// Send test message to the config if it belongs to this user
+	err = h.telegramService.SendTest(configID, userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve configurations"})
+		if errors.Is(err, notification.ErrConfigNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Configuration not found or not owned by you"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send test message: " + err.Error()})
 		return
 	}
 
-	var targetConfig *model.TelegramConfig
-	for _, config := range configs {
-		if config.ID == configID {
-			targetConfig = &config
-			break
-		}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Test message sent successfully",
+	})
+}
+
+// GetTelegramConfigByExternalID handles GET /api/telegram/configs/external/:external_id
+func (h *TelegramHandler) GetTelegramConfigByExternalID(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
 	}
 
-	if targetConfig == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Configuration not found or not owned by you"})
+	config, err := h.telegramService.GetTelegramConfigByExternalID(userID, c.Param("external_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if config == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Configuration not found"})
 		return
 	}
 
-	// Send test message
-	message := "🧪 Test Message\n\nThis is a test message from your Domain Detection service. If you're receiving this, your Telegram notifications are configured correctly."
+	c.Header("ETag", notification.TelegramConfigETag(*config))
+	c.JSON(http.StatusOK, config)
+}
+
+// UpsertTelegramConfigByExternalID handles PUT /api/telegram/configs/external/:external_id, creating
+// or replacing the config. Supports If-Match / If-None-Match for optimistic concurrency.
+func (h *TelegramHandler) UpsertTelegramConfigByExternalID(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	externalID := c.Param("external_id")
+	if len(externalID) > 255 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "External ID must be at most 255 characters"})
+		return
+	}
+
+	var req model.TelegramConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	err = h.telegramService.SendTelegramMessageToConfig(*targetConfig, message)
+	existing, err := h.telegramService.GetTelegramConfigByExternalID(userID, externalID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send test message: " + err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	replacing := 0
+	if existing != nil {
+		replacing = existing.ID
+	}
+	if problems := h.validationService.ValidateTelegramConfigReplacing(userID, replacing, req.ChatID, req.Language, req.MonitorRegions); len(problems) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": strings.Join(problems, "; "), "errors": problems})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Test message sent successfully",
-	})
+	config, created, err := h.telegramService.UpsertTelegramConfigByExternalID(userID, externalID, req, c.GetHeader("If-Match"), c.GetHeader("If-None-Match"))
+	if errors.Is(err, etag.ErrPreconditionFailed) {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Configuration was modified or does not match the precondition"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.Header("ETag", notification.TelegramConfigETag(*config))
+	c.JSON(status, config)
 }
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"domain-detection-go/internal/trial"
+	"domain-detection-go/pkg/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrialHandler handles trial account requests
+type TrialHandler struct {
+	trialService *trial.TrialService
+}
+
+// NewTrialHandler creates a new trial handler
+func NewTrialHandler(trialService *trial.TrialService) *TrialHandler {
+	return &TrialHandler{
+		trialService: trialService,
+	}
+}
+
+// GetTrialStatus handles GET /api/user/trial
+func (h *TrialHandler) GetTrialStatus(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	h.writeStatus(c, userID)
+}
+
+// GetUserTrialStatus handles GET /api/admin/trials/:user_id
+func (h *TrialHandler) GetUserTrialStatus(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	h.writeStatus(c, userID)
+}
+
+// UpdateTrial handles PUT /api/admin/trials/:user_id
+func (h *TrialHandler) UpdateTrial(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req model.TrialUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.trialService.SetTrialExpiry(userID, req.ExpiresAt); err != nil {
+		if errors.Is(err, trial.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Trial updated successfully"})
+}
+
+// ConvertTrial handles POST /api/admin/trials/:user_id/convert
+func (h *TrialHandler) ConvertTrial(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req model.TrialConvertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.trialService.ConvertTrial(userID, req.Plan); err != nil {
+		switch {
+		case errors.Is(err, trial.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		case errors.Is(err, trial.ErrPlanNotFound), errors.Is(err, trial.ErrConvertToTrialPlan):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Trial converted successfully"})
+}
+
+// writeStatus writes the trial status of a user
+func (h *TrialHandler) writeStatus(c *gin.Context, userID int) {
+	status, err := h.trialService.GetStatus(userID)
+	if err != nil {
+		if errors.Is(err, trial.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
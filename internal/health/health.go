@@ -0,0 +1,144 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"domain-detection-go/internal/monitor"
+	"domain-detection-go/internal/notification"
+	"domain-detection-go/pkg/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// externalCacheTTL is how long the state of an external API is reused, so frequent
+// load balancer probes do not use up provider rate limits
+const externalCacheTTL = time.Minute
+
+// dbTimeout bounds the database ping
+const dbTimeout = 3 * time.Second
+
+// pinger is a dependency that can verify its own connectivity
+type pinger interface {
+	Configured() bool
+	Ping() error
+}
+
+// component is a dependency checked by the health probes
+type component struct {
+	name     string
+	critical bool
+	dep      pinger
+
+	mu   sync.Mutex
+	last *model.HealthComponent
+}
+
+// HealthService checks the database and the external APIs the service depends on
+type HealthService struct {
+	db         *sqlx.DB
+	components []*component
+}
+
+// NewHealthService creates a new health service. The database is the only critical
+// component; provider and Telegram outages degrade the service but keep it ready.
+func NewHealthService(db *sqlx.DB, uptrendsClient *monitor.UptrendsClient, site24x7Client *monitor.Site24x7Client, telegramService *notification.TelegramService) *HealthService {
+	return &HealthService{
+		db: db,
+		components: []*component{
+			{name: "uptrends", dep: uptrendsClient},
+			{name: "site24x7", dep: site24x7Client},
+			{name: "telegram", dep: telegramService},
+		},
+	}
+}
+
+// Check returns the state of every component. External APIs are only checked when
+// includeExternal is set; readiness depends on the database alone.
+func (s *HealthService) Check(includeExternal bool) model.HealthReport {
+	components := []model.HealthComponent{s.checkDatabase()}
+
+	if includeExternal {
+		results := make([]model.HealthComponent, len(s.components))
+		var wg sync.WaitGroup
+		for i, c := range s.components {
+			wg.Add(1)
+			go func(i int, c *component) {
+				defer wg.Done()
+				results[i] = c.check()
+			}(i, c)
+		}
+		wg.Wait()
+		components = append(components, results...)
+	}
+
+	report := model.HealthReport{
+		Status:     model.HealthOK,
+		Components: components,
+		CheckedAt:  time.Now(),
+	}
+	for _, c := range components {
+		if c.Status != model.HealthDown {
+			continue
+		}
+		if c.Critical {
+			report.Status = model.HealthDown
+			break
+		}
+		report.Status = model.HealthDegraded
+	}
+	return report
+}
+
+// checkDatabase pings the database on every call
+func (s *HealthService) checkDatabase() model.HealthComponent {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := s.db.PingContext(ctx)
+	return result("database", true, start, err)
+}
+
+// check returns the cached state of an external API, refreshing it when stale
+func (c *component) check() model.HealthComponent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.last != nil && time.Since(c.last.CheckedAt) < externalCacheTTL {
+		return *c.last
+	}
+
+	var state model.HealthComponent
+	if !c.dep.Configured() {
+		state = model.HealthComponent{
+			Name:      c.name,
+			Status:    model.HealthDisabled,
+			Critical:  c.critical,
+			CheckedAt: time.Now(),
+		}
+	} else {
+		start := time.Now()
+		state = result(c.name, c.critical, start, c.dep.Ping())
+	}
+
+	c.last = &state
+	return state
+}
+
+// result builds the state of a component from the outcome of its check
+func result(name string, critical bool, start time.Time, err error) model.HealthComponent {
+	state := model.HealthComponent{
+		Name:      name,
+		Status:    model.HealthOK,
+		Critical:  critical,
+		LatencyMs: time.Since(start).Milliseconds(),
+		CheckedAt: time.Now(),
+	}
+	if err != nil {
+		state.Status = model.HealthDown
+		state.Error = err.Error()
+	}
+	return state
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccountDeletionChecker reports whether a user asked for their account to be deleted
+type AccountDeletionChecker interface {
+	AccountDeletionRequested(userID int) (bool, error)
+}
+
+// AccountDeletionMiddleware rejects mutating requests from users whose account is being
+// deleted, so nothing is added while the deletion cleans up. Like the admin check it
+// fails closed.
+func AccountDeletionMiddleware(checker AccountDeletionChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		userID := c.GetInt("user_id")
+		if userID == 0 {
+			c.Next()
+			return
+		}
+
+		requested, err := checker.AccountDeletionRequested(userID)
+		if err != nil {
+			slog.Error("Failed to check account deletion", "user_id", userID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check account status"})
+			c.Abort()
+			return
+		}
+		if requested {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account is being deleted"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminChecker reports whether a user may use the admin API
+type AdminChecker interface {
+	IsAdmin(userID int) (bool, error)
+}
+
+// AdminMiddleware rejects requests from users who are not admins. Unlike the trial check
+// it fails closed, an error looking up the role refuses the request.
+func AdminMiddleware(checker AdminChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetInt("user_id")
+		if userID == 0 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		isAdmin, err := checker.IsAdmin(userID)
+		if err != nil {
+			slog.Error("Failed to check admin role", "user_id", userID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check permissions"})
+			c.Abort()
+			return
+		}
+		if !isAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
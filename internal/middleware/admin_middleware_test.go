@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeAdminChecker struct {
+	admins map[int]bool
+	err    error
+}
+
+func (f fakeAdminChecker) IsAdmin(userID int) (bool, error) {
+	return f.admins[userID], f.err
+}
+
+func TestAdminMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		userID  int
+		checker fakeAdminChecker
+		want    int
+	}{
+		{"unauthenticated", 0, fakeAdminChecker{}, http.StatusUnauthorized},
+		{"non-admin", 2, fakeAdminChecker{admins: map[int]bool{1: true}}, http.StatusForbidden},
+		{"lookup fails", 1, fakeAdminChecker{err: errors.New("db down")}, http.StatusInternalServerError},
+		{"admin", 1, fakeAdminChecker{admins: map[int]bool{1: true}}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.GET("/api/admin/test", func(c *gin.Context) {
+				if tt.userID != 0 {
+					c.Set("user_id", tt.userID)
+				}
+				c.Next()
+			}, AdminMiddleware(tt.checker), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/test", nil))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
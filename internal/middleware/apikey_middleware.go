@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"domain-detection-go/internal/auth"
+	"domain-detection-go/pkg/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyAuthenticator resolves an API key to the user owning it
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(key string) (*model.User, error)
+}
+
+// apiKeyPrefixes lists the routes that accept an API key instead of a JWT. Account
+// routes, including key management itself, stay JWT only.
+var apiKeyPrefixes = []string{
+	"/api/domains",
+}
+
+// APIKeyOrJWTAuthMiddleware authenticates requests carrying an X-API-Key header by
+// key and all other requests by JWT
+func APIKeyOrJWTAuthMiddleware(jwtSecret string, keys APIKeyAuthenticator, sessions SessionChecker) gin.HandlerFunc {
+	jwtAuth := JWTAuthMiddleware(jwtSecret, sessions)
+
+	return func(c *gin.Context) {
+		key := c.GetHeader("X-API-Key")
+		if key == "" {
+			jwtAuth(c)
+			return
+		}
+
+		allowed := false
+		for _, prefix := range apiKeyPrefixes {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API keys can only be used for domain management"})
+			c.Abort()
+			return
+		}
+
+		user, err := keys.AuthenticateAPIKey(key)
+		if err != nil {
+			if !errors.Is(err, auth.ErrInvalidAPIKey) {
+				slog.Error("Failed to authenticate API key", "error", err)
+			}
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", user.ID)
+		c.Set("username", user.Username)
+		c.Set("region", user.Region.String)
+		c.Set("auth_method", "api_key")
+
+		c.Next()
+	}
+}
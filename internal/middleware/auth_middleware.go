@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"fmt"
+	"log/slog"
 	"net/http"
 	"strings"
 
@@ -9,8 +10,14 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// JWTAuthMiddleware creates middleware for JWT authentication
-func JWTAuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// SessionChecker reports whether the login session behind a token is still active
+type SessionChecker interface {
+	SessionActive(sessionID int) (bool, error)
+}
+
+// JWTAuthMiddleware creates middleware for JWT authentication. Tokens bound to a
+// session are rejected once the session is revoked or expired.
+func JWTAuthMiddleware(jwtSecret string, sessions SessionChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get the token from the Authorization header
 		authHeader := c.GetHeader("Authorization")
@@ -61,6 +68,33 @@ func JWTAuthMiddleware(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
+		// Impersonation tokens are bound to the staff member's session, see
+		// ImpersonationMiddleware
+		_, impersonating := claims["impersonator_id"]
+		sessionID, hasSession := claims["sid"].(float64)
+		if impersonating && !hasSession {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid impersonation token"})
+			c.Abort()
+			return
+		}
+
+		// Tokens issued before sessions existed carry no session ID and simply expire
+		if hasSession {
+			active, err := sessions.SessionActive(int(sessionID))
+			if err != nil {
+				slog.Error("Failed to check session", "session_id", int(sessionID), "error", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify session"})
+				c.Abort()
+				return
+			}
+			if !active {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Session revoked or expired"})
+				c.Abort()
+				return
+			}
+			c.Set("session_id", int(sessionID))
+		}
+
 		username, _ := claims["username"].(string)
 		region, _ := claims["region"].(string)
 
@@ -68,6 +102,11 @@ func JWTAuthMiddleware(jwtSecret string) gin.HandlerFunc {
 		c.Set("username", username)
 		c.Set("region", region)
 
+		// Support staff acting as the user, see ImpersonationMiddleware
+		if impersonatorID, ok := claims["impersonator_id"].(float64); ok {
+			c.Set("impersonator_id", int(impersonatorID))
+		}
+
 		c.Next()
 	}
 }
@@ -78,8 +117,7 @@ func RequireTwoFactor(db interface{}) gin.HandlerFunc {
 	// to ensure the user actually has 2FA enabled for certain operations
 	return func(c *gin.Context) {
 		// Example implementation (replace with actual logic)
-		userID, exists := c.Get("user_id")
-		if !exists {
+		if _, exists := c.Get("user_id"); !exists {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
 			c.Abort()
 			return
@@ -87,8 +125,6 @@ func RequireTwoFactor(db interface{}) gin.HandlerFunc {
 
 		// Here you would check if the user has 2FA enabled
 		// For now, we'll just proceed
-		fmt.Printf("Checking 2FA for user ID: %v\n", userID)
-
 		c.Next()
 	}
 }
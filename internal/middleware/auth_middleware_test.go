@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeSessionChecker struct {
+	active map[int]bool
+}
+
+func (f fakeSessionChecker) SessionActive(sessionID int) (bool, error) {
+	return f.active[sessionID], nil
+}
+
+func TestJWTAuthMiddlewareImpersonationSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const secret = "test-secret"
+	sessions := fakeSessionChecker{active: map[int]bool{10: true}}
+
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		want   int
+	}{
+		{"legacy token", jwt.MapClaims{"user_id": 2}, http.StatusOK},
+		{"impersonation without session", jwt.MapClaims{"user_id": 2, "impersonator_id": 1}, http.StatusUnauthorized},
+		{"impersonation with revoked session", jwt.MapClaims{"user_id": 2, "impersonator_id": 1, "sid": 11}, http.StatusUnauthorized},
+		{"impersonation with active session", jwt.MapClaims{"user_id": 2, "impersonator_id": 1, "sid": 10}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.claims["exp"] = time.Now().Add(time.Minute).Unix()
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, tt.claims).SignedString([]byte(secret))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := gin.New()
+			r.GET("/api/domains", JWTAuthMiddleware(secret, sessions), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/domains", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
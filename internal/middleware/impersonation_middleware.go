@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImpersonationRecorder records requests support staff make while acting as a user
+type ImpersonationRecorder interface {
+	RecordImpersonatedRequest(adminID, userID int, method, path string) error
+}
+
+// impersonationBlockedPrefixes lists the routes impersonation tokens cannot reach even
+// for reading: account security, data exports mailed to the user and the admin API itself
+var impersonationBlockedPrefixes = []string{
+	"/api/2fa",
+	"/api/user/export",
+	"/api/apikeys",
+	"/api/sessions",
+	"/api/admin",
+}
+
+// ImpersonationMiddleware limits requests made with an impersonation token to reading and
+// records each of them in the audit log. The staff member must still be an admin, and a
+// request that cannot be checked or recorded is refused. Requests made with the user's
+// own credentials pass through untouched.
+func ImpersonationMiddleware(recorder ImpersonationRecorder, admins AdminChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		adminID := c.GetInt("impersonator_id")
+		if adminID == 0 {
+			c.Next()
+			return
+		}
+
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Impersonation tokens are read-only"})
+			c.Abort()
+			return
+		}
+		for _, prefix := range impersonationBlockedPrefixes {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Not available while impersonating a user"})
+				c.Abort()
+				return
+			}
+		}
+
+		isAdmin, err := admins.IsAdmin(adminID)
+		if err != nil {
+			slog.Error("Failed to check admin role", "user_id", adminID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check permissions"})
+			c.Abort()
+			return
+		}
+		if !isAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			c.Abort()
+			return
+		}
+
+		userID := c.GetInt("user_id")
+		if err := recorder.RecordImpersonatedRequest(adminID, userID, c.Request.Method, c.Request.URL.RequestURI()); err != nil {
+			slog.Error("Failed to record impersonated request", "admin_id", adminID, "user_id", userID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record request"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeImpersonationRecorder struct {
+	recorded int
+}
+
+func (f *fakeImpersonationRecorder) RecordImpersonatedRequest(adminID, userID int, method, path string) error {
+	f.recorded++
+	return nil
+}
+
+func TestImpersonationMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		impersonator int
+		method       string
+		path         string
+		checker      fakeAdminChecker
+		want         int
+		wantRecorded int
+	}{
+		{"own credentials", 0, http.MethodPost, "/api/domains", fakeAdminChecker{}, http.StatusOK, 0},
+		{"read", 1, http.MethodGet, "/api/domains", fakeAdminChecker{admins: map[int]bool{1: true}}, http.StatusOK, 1},
+		{"write", 1, http.MethodPost, "/api/domains", fakeAdminChecker{admins: map[int]bool{1: true}}, http.StatusForbidden, 0},
+		{"blocked route", 1, http.MethodGet, "/api/sessions", fakeAdminChecker{admins: map[int]bool{1: true}}, http.StatusForbidden, 0},
+		{"no longer admin", 1, http.MethodGet, "/api/domains", fakeAdminChecker{}, http.StatusForbidden, 0},
+		{"lookup fails", 1, http.MethodGet, "/api/domains", fakeAdminChecker{err: errors.New("db down")}, http.StatusInternalServerError, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &fakeImpersonationRecorder{}
+			r := gin.New()
+			r.Use(func(c *gin.Context) {
+				c.Set("user_id", 2)
+				if tt.impersonator != 0 {
+					c.Set("impersonator_id", tt.impersonator)
+				}
+				c.Next()
+			}, ImpersonationMiddleware(recorder, tt.checker))
+			r.Handle(tt.method, tt.path, func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			if recorder.recorded != tt.wantRecorded {
+				t.Errorf("recorded %d requests, want %d", recorder.recorded, tt.wantRecorded)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrialChecker reports whether a user's trial has expired
+type TrialChecker interface {
+	IsTrialExpired(userID int) (bool, error)
+}
+
+// trialExemptRoutes lists the account routes, by method and route pattern, that stay
+// writable after a trial expires: account security, logging out and deleting the account
+var trialExemptRoutes = map[string]bool{
+	"POST /api/2fa/setup":          true,
+	"POST /api/2fa/verify":         true,
+	"POST /api/2fa/disable":        true,
+	"POST /api/2fa/recovery-codes": true,
+	"PUT /api/user/password":       true,
+	"PUT /api/user/timezone":       true,
+	"DELETE /api/user":             true,
+	"POST /api/apikeys":            true,
+	"DELETE /api/apikeys/:id":      true,
+	"POST /api/logout":             true,
+}
+
+// TrialRestrictionMiddleware rejects mutating requests from users whose trial has expired.
+// Read-only requests are always allowed so users can still see their data.
+func TrialRestrictionMiddleware(checker TrialChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		if trialExemptRoutes[c.Request.Method+" "+c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		userID := c.GetInt("user_id")
+		if userID == 0 {
+			c.Next()
+			return
+		}
+
+		expired, err := checker.IsTrialExpired(userID)
+		if err != nil {
+			// Fail open so a database hiccup does not lock paying users out
+			slog.Error("Failed to check trial status", "user_id", userID, "error", err)
+			c.Next()
+			return
+		}
+
+		if expired {
+			c.JSON(http.StatusPaymentRequired, gin.H{
+				"error":         "Your trial has expired. Upgrade your plan to make changes.",
+				"trial_expired": true,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type expiredTrials struct{}
+
+func (expiredTrials) IsTrialExpired(int) (bool, error) { return true, nil }
+
+func TestTrialRestrictionMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	api := r.Group("/api", func(c *gin.Context) {
+		c.Set("user_id", 1)
+		c.Next()
+	}, TrialRestrictionMiddleware(expiredTrials{}))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	api.GET("/user/settings", ok)
+	api.PUT("/user/settings", ok)
+	api.PUT("/user/monitor-providers", ok)
+	api.PUT("/user/deep-check-escalation", ok)
+	api.PUT("/user/password", ok)
+	api.DELETE("/user", ok)
+	api.POST("/2fa/disable", ok)
+	api.DELETE("/apikeys/:id", ok)
+	api.POST("/domains", ok)
+
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodGet, "/api/user/settings", http.StatusOK},
+		{http.MethodPut, "/api/user/password", http.StatusOK},
+		{http.MethodDelete, "/api/user", http.StatusOK},
+		{http.MethodPost, "/api/2fa/disable", http.StatusOK},
+		{http.MethodDelete, "/api/apikeys/3", http.StatusOK},
+		{http.MethodPut, "/api/user/settings", http.StatusPaymentRequired},
+		{http.MethodPut, "/api/user/monitor-providers", http.StatusPaymentRequired},
+		{http.MethodPut, "/api/user/deep-check-escalation", http.StatusPaymentRequired},
+		{http.MethodPost, "/api/domains", http.StatusPaymentRequired},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.want {
+			t.Errorf("%s %s: status = %d, want %d", tt.method, tt.path, w.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// lockID is the Postgres advisory lock held while migrating, so several instances
+// starting at once apply each migration only once
+const lockID = 729141
+
+// migrationFile matches golang-migrate file names, such as 000001_create_users_table.up.sql
+var migrationFile = regexp.MustCompile(`^(\d+)_(.+)\.up\.sql$`)
+
+// ErrDirty is returned when an earlier migration failed halfway and the schema has to be
+// repaired by hand
+var ErrDirty = errors.New("database is dirty")
+
+// Migration is an up migration read from the embedded files
+type Migration struct {
+	Version int64
+	Name    string
+	File    string
+}
+
+// Load returns the up migrations in fsys ordered by version
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var migrations []Migration
+	seen := make(map[int64]string)
+	for _, entry := range entries {
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version %s: %w", entry.Name(), err)
+		}
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", other, entry.Name(), version)
+		}
+		seen[version] = entry.Name()
+		migrations = append(migrations, Migration{Version: version, Name: match[2], File: entry.Name()})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up applies every migration newer than the database's version. The version is kept in
+// the schema_migrations table used by golang-migrate, so databases migrated with the
+// migrate CLI continue where it left off. It returns the number of migrations applied.
+func Up(ctx context.Context, db *sql.DB, fsys fs.FS) (int, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return 0, err
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+		return 0, fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockID)
+
+	_, err = conn.ExecContext(ctx, `
+        CREATE TABLE IF NOT EXISTS schema_migrations (
+            version BIGINT NOT NULL PRIMARY KEY,
+            dirty BOOLEAN NOT NULL
+        )
+    `)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	current, err := version(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+		if err := apply(ctx, conn, fsys, m); err != nil {
+			return applied, err
+		}
+		slog.Info("Applied migration", "version", m.Version, "name", m.Name)
+		applied++
+	}
+	return applied, nil
+}
+
+// Version returns the version of the database schema, 0 before the first migration
+func Version(ctx context.Context, db *sql.DB) (int64, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+	return version(ctx, conn)
+}
+
+// version reads the schema version, failing when the last migration did not complete
+func version(ctx context.Context, conn *sql.Conn) (int64, error) {
+	var v int64
+	var dirty bool
+	err := conn.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&v, &dirty)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get schema version: %w", err)
+	}
+	if dirty {
+		return v, fmt.Errorf("%w at version %d", ErrDirty, v)
+	}
+	return v, nil
+}
+
+// apply runs one migration and records its version in the same transaction, so a failed
+// migration leaves neither schema changes nor a dirty version behind
+func apply(ctx context.Context, conn *sql.Conn, fsys fs.FS, m Migration) error {
+	query, err := fs.ReadFile(fsys, m.File)
+	if err != nil {
+		return fmt.Errorf("failed to read migration %s: %w", m.File, err)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %s: %w", m.File, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, string(query)); err != nil {
+		return fmt.Errorf("failed to apply migration %s: %w", m.File, err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations"); err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", m.File, err)
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)", m.Version); err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", m.File, err)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,120 @@
+package monitor
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// checkpointEntry is the checkpoint IDs of one region and when they were fetched
+type checkpointEntry struct {
+	ids       []int
+	fetchedAt time.Time
+}
+
+// checkpointCache keeps the Uptrends checkpoint IDs of each region, which rarely change,
+// so checks do not fetch them again every time
+type checkpointCache struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	entries map[string]checkpointEntry
+}
+
+func newCheckpointCache(ttl time.Duration) *checkpointCache {
+	return &checkpointCache{
+		ttl:     ttl,
+		entries: make(map[string]checkpointEntry),
+	}
+}
+
+// get returns the cached IDs of a region and whether they are still fresh
+func (c *checkpointCache) get(region string) ([]int, bool, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[region]
+	return entry.ids, ok, ok && time.Since(entry.fetchedAt) < c.ttl
+}
+
+func (c *checkpointCache) set(region string, ids []int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[region] = checkpointEntry{ids: ids, fetchedAt: time.Now()}
+}
+
+// regions returns the cached regions in order
+func (c *checkpointCache) regions() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	regions := make([]string, 0, len(c.entries))
+	for region := range c.entries {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// clear drops every entry and returns the regions that were cached
+func (c *checkpointCache) clear() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	regions := make([]string, 0, len(c.entries))
+	for region := range c.entries {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	c.entries = make(map[string]checkpointEntry)
+	return regions
+}
+
+// getCheckpointIdsForRegion returns the checkpoint IDs of a region, fetching them when
+// they are not cached or have expired. Expired IDs are still used if the fetch fails.
+func (c *UptrendsClient) getCheckpointIdsForRegion(regionCode string) ([]int, error) {
+	cached, ok, fresh := c.checkpoints.get(regionCode)
+	if fresh {
+		return cached, nil
+	}
+
+	ids, err := c.fetchCheckpointIdsForRegion(regionCode)
+	if err != nil {
+		if ok {
+			slog.Warn("Failed to refresh Uptrends checkpoints, using expired ones", "region", regionCode, "error", err)
+			return cached, nil
+		}
+		return nil, err
+	}
+	c.checkpoints.set(regionCode, ids)
+	return ids, nil
+}
+
+// InvalidateCheckpoints drops the cached checkpoint IDs so the next checks fetch them
+// again, and returns the regions that were cached
+func (c *UptrendsClient) InvalidateCheckpoints() []string {
+	return c.checkpoints.clear()
+}
+
+// RunScheduledCheckpointRefresh refetches the cached checkpoint IDs twice per TTL, so
+// checks keep finding fresh entries, until ctx is cancelled
+func (c *UptrendsClient) RunScheduledCheckpointRefresh(ctx context.Context) {
+	slog.Info("RunScheduledCheckpointRefresh")
+	ticker := time.NewTicker(c.config.CheckpointTTL / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("RunScheduledCheckpointRefresh stopped")
+			return
+		case <-ticker.C:
+			for _, region := range c.checkpoints.regions() {
+				ids, err := c.fetchCheckpointIdsForRegion(region)
+				if err != nil {
+					slog.Warn("Failed to refresh Uptrends checkpoints", "region", region, "error", err)
+					continue
+				}
+				c.checkpoints.set(region, ids)
+			}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"domain-detection-go/internal/blockpage"
+	"domain-detection-go/pkg/model"
+)
+
+// contentCheckLimit caps how much of a page is read for content matching
+const contentCheckLimit = 1 << 20
+
+// checkContent fetches the domain's homepage and applies its content match. It returns
+// the error code and why the page failed the match, or "" when it passed. An ISP block
+// page fails with model.ErrorCodeBlocked whatever the match. Fetch errors are returned
+// as errors so the provider results decide the check on their own.
+func (s *MonitorService) checkContent(ctx context.Context, domain model.Domain) (int, string, error) {
+	fullURL := domain.Name
+	if parsedURL, err := url.Parse(fullURL); err != nil || parsedURL.Scheme == "" {
+		// If no scheme provided, default to HTTPS
+		fullURL = "https://" + fullURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid URL: %w", err)
+	}
+	req.Header.Set("User-Agent", defaultDirectUserAgent)
+
+	resp, err := s.contentClient.Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to fetch %s: %w", fullURL, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, contentCheckLimit))
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to read %s: %w", fullURL, err)
+	}
+
+	if name, ok := blockpage.Detect(resp.Header, body); ok {
+		return model.ErrorCodeBlocked, blockpage.Description(name), nil
+	}
+	mismatch, err := matchContent(domain.ContentMatchType, domain.ContentMatchPattern, string(body))
+	return model.ErrorCodeContentMismatch, mismatch, err
+}
+
+// matchContent applies a content match to a page body
+func matchContent(matchType, pattern, body string) (string, error) {
+	switch matchType {
+	case model.ContentMatchPresent:
+		if !strings.Contains(body, pattern) {
+			return fmt.Sprintf("Content check failed: keyword %q not found", pattern), nil
+		}
+	case model.ContentMatchAbsent:
+		if strings.Contains(body, pattern) {
+			return fmt.Sprintf("Content check failed: unexpected keyword %q found", pattern), nil
+		}
+	case model.ContentMatchRegex:
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return "", fmt.Errorf("invalid content pattern: %w", err)
+		}
+		if !re.MatchString(body) {
+			return fmt.Sprintf("Content check failed: pattern %q did not match", pattern), nil
+		}
+	default:
+		return "", fmt.Errorf("unknown content match type %q", matchType)
+	}
+	return "", nil
+}
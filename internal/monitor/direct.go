@@ -0,0 +1,253 @@
+package monitor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"domain-detection-go/internal/blockpage"
+	"domain-detection-go/internal/netguard"
+	"domain-detection-go/pkg/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// directCheckRegion is reported as the region of built-in checks
+const directCheckRegion = "app"
+
+// defaultDirectUserAgent identifies requests made from the application servers
+const defaultDirectUserAgent = "DomainMonitor/1.0"
+
+// DirectCheckConfig holds the defaults of the built-in HTTP checker
+type DirectCheckConfig struct {
+	Timeout      time.Duration
+	UserAgent    string
+	MaxRedirects int
+}
+
+// DirectCheckClient checks domains with plain HTTP requests from the application
+// servers. It implements the same interface as the external providers so domains
+// without Uptrends/Site24x7 monitors still get basic monitoring. Monitors are rows of
+// direct_monitors keyed by domain ID. Checks only connect to publicly routable
+// addresses.
+type DirectCheckClient struct {
+	db        *sqlx.DB
+	config    DirectCheckConfig
+	transport *http.Transport
+}
+
+// directMonitor is a built-in monitor together with its per-domain settings
+type directMonitor struct {
+	DomainID        int            `db:"domain_id"`
+	URL             string         `db:"url"`
+	IsActive        bool           `db:"is_active"`
+	TimeoutSeconds  sql.NullInt64  `db:"timeout_seconds"`
+	UserAgent       sql.NullString `db:"user_agent"`
+	FollowRedirects bool           `db:"follow_redirects"`
+	MaxRedirects    sql.NullInt64  `db:"max_redirects"`
+	CheckType       string         `db:"check_type"`
+	Port            sql.NullInt64  `db:"port"`
+}
+
+// NewDirectCheckClient creates a new built-in HTTP checker
+func NewDirectCheckClient(db *sqlx.DB, config DirectCheckConfig) *DirectCheckClient {
+	// Set default values if not provided
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.UserAgent == "" {
+		config.UserAgent = defaultDirectUserAgent
+	}
+	if config.MaxRedirects <= 0 {
+		config.MaxRedirects = 10
+	}
+
+	return &DirectCheckClient{
+		db:     db,
+		config: config,
+		// Timeouts are set per check on the http.Client
+		transport: netguard.Transport(),
+	}
+}
+
+// CreateMonitor registers a built-in monitor for the domain in the tags. Existing
+// per-domain settings are kept. Regions, interval and HTTP settings are ignored, checks
+// always run from the application servers on the domain's schedule with the settings of
+// the built-in check.
+func (c *DirectCheckClient) CreateMonitor(fullURL string, name string, tags model.MonitorTags, regions []string, interval int, settings model.HTTPCheckSettings) (string, error) {
+	if tags.DomainID == 0 {
+		return "", errors.New("direct monitors require a domain ID")
+	}
+
+	_, err := c.db.Exec(`
+        INSERT INTO direct_monitors (domain_id, url, is_active, created_at, updated_at)
+        VALUES ($1, $2, true, NOW(), NOW())
+        ON CONFLICT (domain_id) DO UPDATE SET url = EXCLUDED.url, is_active = true, updated_at = NOW()
+    `, tags.DomainID, fullURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to create direct monitor: %w", err)
+	}
+
+	slog.Info("Created direct monitor", "url", fullURL, "name", name)
+	return strconv.Itoa(tags.DomainID), nil
+}
+
+// UpdateMonitorStatus pauses or resumes a built-in monitor
+func (c *DirectCheckClient) UpdateMonitorStatus(monitorID string, isActive bool) error {
+	_, err := c.db.Exec("UPDATE direct_monitors SET is_active = $1, updated_at = NOW() WHERE domain_id = $2", isActive, monitorID)
+	if err != nil {
+		return fmt.Errorf("failed to update direct monitor status: %w", err)
+	}
+	return nil
+}
+
+// UpdateMonitorInterval does nothing, built-in monitors run on the domain's schedule
+func (c *DirectCheckClient) UpdateMonitorInterval(monitorID string, interval int) error {
+	return nil
+}
+
+// DeleteMonitor removes a built-in monitor and its settings
+func (c *DirectCheckClient) DeleteMonitor(monitorID string) error {
+	_, err := c.db.Exec("DELETE FROM direct_monitors WHERE domain_id = $1", monitorID)
+	if err != nil {
+		return fmt.Errorf("failed to delete direct monitor: %w", err)
+	}
+	return nil
+}
+
+// GetLatestMonitorCheck performs a check right away, since built-in monitors have no
+// stored results. Connection failures are reported as an unavailable result, not an error.
+func (c *DirectCheckClient) GetLatestMonitorCheck(ctx context.Context, monitorID string, region string) (*model.DomainCheckResult, error) {
+	var monitor directMonitor
+	err := c.db.GetContext(ctx, &monitor, `
+        SELECT m.domain_id, m.url, m.is_active, m.timeout_seconds, m.user_agent, m.follow_redirects, m.max_redirects,
+               d.check_type, d.port
+        FROM direct_monitors m
+        JOIN domains d ON d.id = m.domain_id
+        WHERE m.domain_id = $1
+    `, monitorID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("direct monitor %s not found", monitorID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get direct monitor: %w", err)
+	}
+	if !monitor.IsActive {
+		return nil, fmt.Errorf("direct monitor %s is paused", monitorID)
+	}
+
+	return c.check(ctx, monitor), nil
+}
+
+// Close cleans up resources
+func (c *DirectCheckClient) Close() {
+	c.transport.CloseIdleConnections()
+}
+
+// check performs a single GET request using the monitor's settings, or a TCP connect or
+// ping for domains with those check types
+func (c *DirectCheckClient) check(ctx context.Context, monitor directMonitor) *model.DomainCheckResult {
+	timeout := c.config.Timeout
+	if monitor.TimeoutSeconds.Valid {
+		timeout = time.Duration(monitor.TimeoutSeconds.Int64) * time.Second
+	}
+	switch monitor.CheckType {
+	case model.CheckTypeTCP:
+		return c.checkTCP(ctx, monitor, timeout)
+	case model.CheckTypeICMP:
+		return c.checkICMP(ctx, monitor, timeout)
+	}
+	userAgent := c.config.UserAgent
+	if monitor.UserAgent.Valid && monitor.UserAgent.String != "" {
+		userAgent = monitor.UserAgent.String
+	}
+	maxRedirects := c.config.MaxRedirects
+	if monitor.MaxRedirects.Valid {
+		maxRedirects = int(monitor.MaxRedirects.Int64)
+	}
+
+	fullURL := monitor.URL
+	if parsedURL, err := url.Parse(fullURL); err != nil || parsedURL.Scheme == "" {
+		// If no scheme provided, default to HTTPS
+		fullURL = "https://" + fullURL
+	}
+
+	client := &http.Client{
+		Transport: c.transport,
+		Timeout:   timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			// Report the redirect response itself when redirects are not followed
+			if !monitor.FollowRedirects {
+				return http.ErrUseLastResponse
+			}
+			if len(via) > maxRedirects {
+				return errors.New("too many redirects")
+			}
+			return nil
+		},
+	}
+
+	start := time.Now()
+	result := &model.DomainCheckResult{
+		Domain: monitor.URL,
+		Region: directCheckRegion,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
+	if err != nil {
+		result.ErrorCode = -1
+		result.ErrorDescription = fmt.Sprintf("Invalid URL: %v", err)
+		result.CheckedAt = time.Now()
+		return result
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := client.Do(req)
+
+	// Calculate response time regardless of error
+	responseTime := int(time.Since(start).Milliseconds())
+	result.ResponseTime = responseTime
+	result.TotalTime = responseTime
+	result.CheckedAt = time.Now()
+
+	if err != nil {
+		slog.Warn("Direct check failed", "url", fullURL, "error", err)
+		if name, ok := blockpage.DetectError(err); ok {
+			result.ErrorCode = model.ErrorCodeBlocked
+			result.ErrorDescription = blockpage.Description(name)
+			return result
+		}
+		result.ErrorCode = -1 // Custom error code for connection issues
+		result.ErrorDescription = fmt.Sprintf("Connection error: %v", err)
+		return result
+	}
+	defer resp.Body.Close()
+
+	// Read the start of the body to ensure the connection is working and to spot
+	// ISP block pages, but don't download everything
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, blockpage.MaxBodyBytes))
+
+	slog.Debug("Direct check response", "url", fullURL, "status", resp.StatusCode, "time_ms", responseTime)
+
+	result.StatusCode = resp.StatusCode
+	result.Available = resp.StatusCode >= 200 && resp.StatusCode < 400
+	if !result.Available {
+		result.ErrorDescription = resp.Status
+	}
+	// A block page usually comes with a success status
+	if name, ok := blockpage.Detect(resp.Header, body); ok {
+		slog.Info("Direct check got a block page", "url", fullURL, "signature", name)
+		result.Available = false
+		result.StatusCode = 0
+		result.ErrorCode = model.ErrorCodeBlocked
+		result.ErrorDescription = blockpage.Description(name)
+	}
+	return result
+}
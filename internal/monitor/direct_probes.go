@@ -0,0 +1,150 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"time"
+
+	"domain-detection-go/internal/netguard"
+	"domain-detection-go/pkg/model"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// Protocol numbers of ICMP and ICMPv6, as icmp.ParseMessage expects them
+const (
+	protocolICMP   = 1
+	protocolICMPv6 = 58
+)
+
+// probeHost returns the host of a domain entry, e.g. smtp.example.com for
+// https://smtp.example.com
+func probeHost(domainURL string) string {
+	if parsedURL, err := url.Parse(domainURL); err == nil && parsedURL.Hostname() != "" {
+		return parsedURL.Hostname()
+	}
+	return domainURL
+}
+
+// checkTCP connects to the monitor's port. Reachable services are reported with status
+// 200 so they count as available like a successful HTTP check.
+func (c *DirectCheckClient) checkTCP(ctx context.Context, monitor directMonitor, timeout time.Duration) *model.DomainCheckResult {
+	result := &model.DomainCheckResult{Domain: monitor.URL, Region: directCheckRegion}
+	if !monitor.Port.Valid {
+		result.ErrorCode = -1
+		result.ErrorDescription = "No port configured"
+		result.CheckedAt = time.Now()
+		return result
+	}
+
+	address := net.JoinHostPort(probeHost(monitor.URL), strconv.FormatInt(monitor.Port.Int64, 10))
+	start := time.Now()
+	conn, err := netguard.Dialer(timeout).DialContext(ctx, "tcp", address)
+	c.finishProbe(result, start, err)
+	if err == nil {
+		conn.Close()
+	}
+	return result
+}
+
+// checkICMP pings the monitor's host once. It uses unprivileged ICMP sockets, which on
+// Linux require the group of the process in net.ipv4.ping_group_range.
+func (c *DirectCheckClient) checkICMP(ctx context.Context, monitor directMonitor, timeout time.Duration) *model.DomainCheckResult {
+	result := &model.DomainCheckResult{Domain: monitor.URL, Region: directCheckRegion}
+	start := time.Now()
+	err := ping(ctx, probeHost(monitor.URL), timeout)
+	c.finishProbe(result, start, err)
+	return result
+}
+
+// finishProbe fills in the outcome of a TCP or ping check started at start
+func (c *DirectCheckClient) finishProbe(result *model.DomainCheckResult, start time.Time, err error) {
+	responseTime := int(time.Since(start).Milliseconds())
+	result.ResponseTime = responseTime
+	result.TotalTime = responseTime
+	result.CheckedAt = time.Now()
+	if err != nil {
+		result.ErrorCode = -1 // Custom error code for connection issues
+		result.ErrorDescription = fmt.Sprintf("Connection error: %v", err)
+		return
+	}
+	result.StatusCode = 200
+	result.Available = true
+}
+
+// ping sends one ICMP echo request to host and waits for the reply, until timeout or
+// ctx's deadline, whichever comes first
+func ping(ctx context.Context, host string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	ips, err := net.DefaultResolver.LookupIP(ctx, "ip", host)
+	if err != nil {
+		return err
+	}
+	if len(ips) == 0 {
+		return errors.New("no address found")
+	}
+	ip := ips[0]
+	for _, candidate := range ips {
+		if candidate.To4() != nil {
+			ip = candidate
+			break
+		}
+	}
+	if addr, ok := netip.AddrFromSlice(ip); !ok {
+		return fmt.Errorf("invalid address %s", ip)
+	} else if err := netguard.CheckPublicAddress(addr); err != nil {
+		return err
+	}
+
+	network, listenAddress, protocol := "udp4", "0.0.0.0", protocolICMP
+	var requestType, replyType icmp.Type = ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+	if ip.To4() == nil {
+		network, listenAddress, protocol = "udp6", "::", protocolICMPv6
+		requestType, replyType = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+	}
+
+	conn, err := icmp.ListenPacket(network, listenAddress)
+	if err != nil {
+		return fmt.Errorf("failed to open ICMP socket: %w", err)
+	}
+	defer conn.Close()
+
+	request, err := (&icmp.Message{
+		Type: requestType,
+		Body: &icmp.Echo{ID: 1, Seq: 1, Data: []byte("domain-detection")},
+	}).Marshal(nil)
+	if err != nil {
+		return err
+	}
+
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	if _, err := conn.WriteTo(request, &net.UDPAddr{IP: ip}); err != nil {
+		return err
+	}
+
+	// The kernel only delivers replies to this socket's own requests; anything else
+	// is skipped until the deadline
+	reply := make([]byte, 1500)
+	for {
+		n, _, err := conn.ReadFrom(reply)
+		if err != nil {
+			return err
+		}
+		message, err := icmp.ParseMessage(protocol, reply[:n])
+		if err == nil && message.Type == replyType {
+			return nil
+		}
+	}
+}
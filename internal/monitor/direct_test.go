@@ -0,0 +1,74 @@
+package monitor
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"domain-detection-go/internal/netguard"
+	"domain-detection-go/pkg/model"
+)
+
+func TestDirectCheckRefusesInternalAddresses(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer server.Close()
+	_, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	portNumber, _ := strconv.Atoi(port)
+
+	client := NewDirectCheckClient(nil, DirectCheckConfig{})
+	defer client.Close()
+
+	// localhost resolves to 127.0.0.1, which the domain name validation accepts
+	tests := []struct {
+		name    string
+		monitor directMonitor
+	}{
+		{
+			name:    "http",
+			monitor: directMonitor{URL: "http://localhost:" + port, CheckType: model.CheckTypeHTTP},
+		},
+		{
+			name:    "address literal",
+			monitor: directMonitor{URL: server.URL, CheckType: model.CheckTypeHTTP},
+		},
+		{
+			name: "tcp",
+			monitor: directMonitor{
+				URL:       "localhost",
+				CheckType: model.CheckTypeTCP,
+				Port:      sql.NullInt64{Int64: int64(portNumber), Valid: true},
+			},
+		},
+		{
+			name:    "icmp",
+			monitor: directMonitor{URL: "localhost", CheckType: model.CheckTypeICMP},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := client.check(context.Background(), tt.monitor)
+			if result.Available {
+				t.Fatalf("check of %s reported available", tt.monitor.URL)
+			}
+			if !strings.Contains(result.ErrorDescription, netguard.ErrInternalAddress.Error()) {
+				t.Errorf("error description = %q, want it to mention %q", result.ErrorDescription, netguard.ErrInternalAddress)
+			}
+		})
+	}
+	if n := hits.Load(); n != 0 {
+		t.Errorf("internal server got %d requests, want 0", n)
+	}
+}
@@ -0,0 +1,32 @@
+package monitor
+
+import "strconv"
+
+// site24x7Frequencies lists the check frequencies (in minutes) supported by Site24x7
+var site24x7Frequencies = []int{1, 5, 10, 15, 20, 30, 60, 120, 180, 360, 720, 1440}
+
+// uptrendsMaxCheckInterval is the longest check interval Uptrends accepts, in minutes
+const uptrendsMaxCheckInterval = 60
+
+// site24x7CheckFrequency maps a domain interval to the closest Site24x7 frequency
+// that does not exceed it, so checks are never less frequent than requested
+func site24x7CheckFrequency(interval int) string {
+	frequency := site24x7Frequencies[0]
+	for _, f := range site24x7Frequencies {
+		if f <= interval {
+			frequency = f
+		}
+	}
+	return strconv.Itoa(frequency)
+}
+
+// uptrendsCheckInterval maps a domain interval to an Uptrends CheckInterval
+func uptrendsCheckInterval(interval int) int {
+	if interval < 1 {
+		return 1
+	}
+	if interval > uptrendsMaxCheckInterval {
+		return uptrendsMaxCheckInterval
+	}
+	return interval
+}
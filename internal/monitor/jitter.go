@@ -0,0 +1,35 @@
+package monitor
+
+import (
+	"hash/fnv"
+	"strconv"
+	"time"
+
+	"domain-detection-go/pkg/model"
+)
+
+// Domains added together, or with the same interval after a restart, would otherwise be
+// due in the same scheduler tick. Each slot starts up to maxJitterFraction of the interval
+// early, capped at maxJitter, so those checks spread out over the following cycles.
+const (
+	maxJitterFraction = 0.1
+	maxJitter         = 5 * time.Minute
+)
+
+// checkJitter returns how much earlier than its interval a domain's slot after lastSlot
+// starts. It is pseudo-random per domain and slot, but stable while the slot is pending,
+// and never makes a check later than requested.
+func checkJitter(d model.Domain, lastSlot time.Time) time.Duration {
+	window := time.Duration(float64(time.Duration(d.Interval)*time.Minute) * maxJitterFraction)
+	if window > maxJitter {
+		window = maxJitter
+	}
+	if window < time.Second {
+		return 0
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(strconv.Itoa(d.ID)))
+	h.Write([]byte(strconv.FormatInt(lastSlot.Unix(), 10)))
+	return time.Duration(h.Sum64() % uint64(window))
+}
@@ -1,15 +1,18 @@
 package monitor
 
 import (
+	"context"
 	"fmt"
-	"log"
-	"net/url"
+	"log/slog"
+	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"domain-detection-go/internal/deepcheck"
 	"domain-detection-go/internal/domain"
-	"domain-detection-go/internal/notification"
+	"domain-detection-go/internal/events"
+	"domain-detection-go/internal/netguard"
 	"domain-detection-go/internal/service"
 	"domain-detection-go/pkg/model"
 )
@@ -18,21 +21,30 @@ import (
 type MonitorService struct {
 	uptrendsClient   *UptrendsClient
 	site24x7Client   *Site24x7Client
+	directClient     *DirectCheckClient
 	deepCheckClient  *deepcheck.DeepCheckClient
 	domainService    *domain.DomainService
-	telegramService  *notification.TelegramService
-	emailService     *notification.EmailService
 	deepCheckService *service.DeepCheckService
 	regions          []string
+
+	recoveryConfirmations int // Default consecutive successful checks before a down domain counts as recovered
+	shedding              LoadShedding
+	contentClient         *http.Client   // Fetches homepages for content matching, refusing internal addresses
+	deepChecks            sync.WaitGroup // Deep checks still being ordered
+	stagger               startupStagger // Only used by the scheduled checks goroutine
+	logger                *slog.Logger
 }
 
 // NewMonitorService creates a new monitor service
 func NewMonitorService(uptrendsClient *UptrendsClient,
 	site24x7Client *Site24x7Client,
+	directClient *DirectCheckClient,
 	domainService *domain.DomainService,
-	telegramService *notification.TelegramService,
-	emailService *notification.EmailService,
 	deepCheckService *service.DeepCheckService,
+	deepCheckClient *deepcheck.DeepCheckClient,
+	recoveryConfirmations int,
+	shedding LoadShedding,
+	logger *slog.Logger,
 ) *MonitorService {
 	// Default regions to check
 	regions := []string{
@@ -45,149 +57,48 @@ func NewMonitorService(uptrendsClient *UptrendsClient,
 		"VN", // Vietnam
 	}
 
-	return &MonitorService{
+	s := &MonitorService{
 		uptrendsClient:   uptrendsClient,
 		site24x7Client:   site24x7Client,
-		deepCheckClient:  deepcheck.NewDeepCheckClient(),
+		directClient:     directClient,
+		deepCheckClient:  deepCheckClient,
 		domainService:    domainService,
-		telegramService:  telegramService,
 		regions:          regions,
-		emailService:     emailService,
 		deepCheckService: deepCheckService,
-	}
-}
-
-// ensureUptrendsMonitor creates an Uptrends monitor if the domain doesn't have one
-func (s *MonitorService) ensureUptrendsMonitor(domain model.Domain) string {
-	// If domain already has an Uptrends monitor GUID, return it
-	if domain.GetMonitorGuid() != "" {
-		return domain.GetMonitorGuid()
-	}
-
-	// If Uptrends client is not available, return empty
-	if s.uptrendsClient == nil {
-		log.Printf("Uptrends client not available for domain %s", domain.Name)
-		return ""
-	}
-
-	log.Printf("Creating missing Uptrends monitor for domain %s in region %s", domain.Name, domain.Region)
-
-	// Extract domain name for the monitor name
-	parsedURL, err := url.Parse(domain.Name)
-	if err != nil {
-		log.Printf("Failed to parse URL for monitor creation: %v", err)
-		return ""
-	}
-
-	displayName := parsedURL.Hostname()
-	if displayName == "" {
-		displayName = domain.Name
-	}
-	monitorName := fmt.Sprintf("Domain Check - %s", displayName)
-
-	// Create monitor with the domain's region
-	regions := []string{domain.Region}
-
-	// Add fallback regions based on primary region
-	switch domain.Region {
-	case "TH", "ID", "KR":
-		regions = append(regions, "VN") // Add Vietnam
-	case "VN":
-		regions = append(regions, "TH") // Add Thailand
-	}
 
-	uptrendsGuid, err := s.uptrendsClient.CreateMonitor(domain.Name, monitorName, regions)
-	if err != nil {
-		log.Printf("Failed to create Uptrends monitor for domain %s: %v", domain.Name, err)
-		return ""
+		recoveryConfirmations: recoveryConfirmations,
+		shedding:              shedding,
+		contentClient:         &http.Client{Timeout: 15 * time.Second, Transport: netguard.Transport()},
+		logger:                logger.With("component", "monitor"),
 	}
-
-	// Update the domain in the database with the new monitor GUID
-	_, dbErr := s.domainService.UpdateDomainUptrendsGUID(domain.ID, uptrendsGuid)
-	if dbErr != nil {
-		log.Printf("Failed to update domain %d with Uptrends monitor GUID %s: %v", domain.ID, uptrendsGuid, dbErr)
-
-		// Clean up created monitor if database update failed
-		if delErr := s.uptrendsClient.DeleteMonitor(uptrendsGuid); delErr != nil {
-			log.Printf("Failed to delete orphaned Uptrends monitor %s: %v", uptrendsGuid, delErr)
-		}
-		return ""
+	if bus := domainService.Events(); bus != nil {
+		bus.Subscribe(s.handleDomainAlert, events.DomainAlert)
 	}
-
-	log.Printf("Successfully created and linked Uptrends monitor %s for domain %s", uptrendsGuid, domain.Name)
-	return uptrendsGuid
+	return s
 }
 
-// ensureSite24x7Monitor creates a Site24x7 monitor if the domain doesn't have one
-func (s *MonitorService) ensureSite24x7Monitor(domain model.Domain) string {
-	// If domain already has a Site24x7 monitor ID, return it
-	if domain.GetSite24x7MonitorID() != "" {
-		return domain.GetSite24x7MonitorID()
-	}
-
-	// If Site24x7 client is not available, return empty
-	if s.site24x7Client == nil {
-		log.Printf("Site24x7 client not available for domain %s", domain.Name)
-		return ""
-	}
-
-	log.Printf("Creating missing Site24x7 monitor for domain %s in region %s", domain.Name, domain.Region)
-
-	// Extract domain name for the monitor name
-	parsedURL, err := url.Parse(domain.Name)
-	if err != nil {
-		log.Printf("Failed to parse URL for monitor creation: %v", err)
-		return ""
-	}
-
-	displayName := parsedURL.Hostname()
-	if displayName == "" {
-		displayName = domain.Name
-	}
-	monitorName := fmt.Sprintf("Domain Check - %s", displayName)
-
-	// Create monitor with the domain's region
-	regions := []string{domain.Region}
-	site24x7ID, err := s.site24x7Client.CreateMonitor(domain.Name, monitorName, regions)
-	if err != nil {
-		log.Printf("Failed to create Site24x7 monitor for domain %s: %v", domain.Name, err)
-		return ""
-	}
-
-	// Update the domain in the database with the new monitor ID
-	_, dbErr := s.domainService.UpdateDomainSite24x7ID(domain.ID, site24x7ID)
-	if dbErr != nil {
-		log.Printf("Failed to update domain %d with Site24x7 monitor ID %s: %v", domain.ID, site24x7ID, dbErr)
-
-		// Clean up created monitor if database update failed
-		if delErr := s.site24x7Client.DeleteMonitor(site24x7ID); delErr != nil {
-			log.Printf("Failed to delete orphaned Site24x7 monitor %s: %v", site24x7ID, delErr)
-		}
-		return ""
-	}
-
-	log.Printf("Successfully created and linked Site24x7 monitor %s for domain %s", site24x7ID, domain.Name)
-	return site24x7ID
-}
-
-// checkAllActiveDomains checks domains that are due for checking based on their interval
-func (s *MonitorService) checkAllActiveDomains() {
+// checkAllActiveDomains checks domains that are due for checking based on their interval.
+// When ctx is cancelled the check in progress finishes and the remaining domains are left
+// for the next run.
+func (s *MonitorService) checkAllActiveDomains(ctx context.Context) {
 	// Get all active domains with monitor GUIDs
 	domains, err := s.domainService.GetAllActiveDomainsWithMonitors()
 	if err != nil {
-		log.Printf("Error getting active domains: %v", err)
+		s.logger.Error("Failed to get active domains", "error", err)
 		return
 	}
 
 	now := time.Now()
 
+	var due []model.Domain
 	for _, domain := range domains {
 		// Use helper methods to get string values
 		uptrendsGuid := domain.GetMonitorGuid()
 		site24x7ID := domain.GetSite24x7MonitorID()
+		directID := domain.GetDirectMonitorID()
 
 		// Skip domains without any monitor IDs
-		if uptrendsGuid == "" && site24x7ID == "" {
+		if uptrendsGuid == "" && site24x7ID == "" && directID == "" {
 			continue
 		}
 
@@ -196,96 +107,97 @@ func (s *MonitorService) checkAllActiveDomains() {
 			continue
 		}
 
-		log.Printf("Checking domain %s (interval: %d minutes)", domain.Name, domain.Interval)
+		due = append(due, domain)
+	}
+
+	// After a restart, spread the overdue domains instead of checking them all at once
+	due = s.staggerStartup(due, now)
+
+	// Under overload, check the most important domains first and sample the rest
+	due = s.shedLoad(due, now)
+
+	for i, domain := range due {
+		if ctx.Err() != nil {
+			s.logger.Info("Shutting down, skipping the remaining due domains", "skipped", len(due)-i, "due", len(due))
+			return
+		}
+
+		s.logger.Debug("Checking domain", "domain_id", domain.ID, "domain", domain.Name, "interval", domain.Interval)
 
 		func(d model.Domain) {
+			logger := s.logger.With("domain_id", d.ID, "domain", d.Name)
 			defer func() {
 				if r := recover(); r != nil {
-					log.Printf("Recovered from panic while checking domain %s: %v", d.Name, r)
+					logger.Error("Recovered from panic while checking domain", "panic", r)
 				}
 			}()
 
-			var uptrendsResult, site24x7Result *model.DomainCheckResult
-			var uptrendsErr, site24x7Err error
-
-			// Ensure Uptrends monitor exists and get its GUID
-			currentUptrendsGuid := d.GetMonitorGuid()
-			if currentUptrendsGuid == "" {
-				// Create Uptrends monitor if it doesn't exist
-				currentUptrendsGuid = s.ensureUptrendsMonitor(d)
-			}
-
-			// Check with Uptrends API if available
-			if currentUptrendsGuid != "" {
-				uptrendsResult, uptrendsErr = s.uptrendsClient.GetLatestMonitorCheck(currentUptrendsGuid, d.Region)
-				if uptrendsErr != nil {
-					log.Printf("Error checking domain %s with Uptrends: %v", d.Name, uptrendsErr)
-				}
-			}
-
-			// Ensure Site24x7 monitor exists and get its ID
-			currentSite24x7ID := d.GetSite24x7MonitorID()
-			if currentSite24x7ID == "" {
-				// Create Site24x7 monitor if it doesn't exist
-				currentSite24x7ID = s.ensureSite24x7Monitor(d)
+			// Check with every provider selected for the domain, creating missing monitors
+			providers, err := s.domainService.SelectedProviders(d.UserID, d.ID)
+			if err != nil {
+				logger.Warn("Failed to get providers, using every provider", "error", err)
 			}
-
-			// Check with Site24x7 API if available
-			if currentSite24x7ID != "" {
-				site24x7Result, site24x7Err = s.site24x7Client.GetLatestMonitorCheck(currentSite24x7ID, d.Region)
-				if site24x7Err != nil {
-					log.Printf("Error checking domain %s with Site24x7: %v", d.Name, site24x7Err)
-				}
+			// Every region is stored separately
+			own := d.Regions()
+			regions := s.domainService.MonitorRegions(own...)
+			results, regionStatuses := s.checkProviders(d, providers, own, regions, logger)
+			if err := s.domainService.RecordRegionStatuses(d.ID, providers, regions, regionStatuses); err != nil {
+				logger.Error("Failed to record region statuses", "error", err)
 			}
 
-			// Skip if both providers failed
-			if uptrendsErr != nil && site24x7Err != nil {
-				log.Printf("Both monitoring providers failed for domain %s, skipping notification", d.Name)
+			// Skip if every provider failed
+			if len(results) == 0 {
+				logger.Warn("All monitoring providers failed, skipping notification")
 				return
 			}
 
-			// Determine final result and availability
-			var finalResult *model.DomainCheckResult
-			var isAvailable bool
-
-			if uptrendsResult != nil && site24x7Result != nil {
-				// Both providers available - domain is available only if BOTH report it as available
-				isAvailable = uptrendsResult.Available && site24x7Result.Available
-
-				// Use Uptrends result as primary, but adjust availability
-				finalResult = uptrendsResult
-				finalResult.Available = isAvailable
-
-				log.Printf("Domain %s check results - Uptrends: available=%v, status=%d | Site24x7: available=%v, status=%d | Final: available=%v",
-					d.Name, uptrendsResult.Available, uptrendsResult.StatusCode,
-					site24x7Result.Available, site24x7Result.StatusCode, isAvailable)
-			} else if uptrendsResult != nil {
-				// Only Uptrends available
-				finalResult = uptrendsResult
-				isAvailable = uptrendsResult.Available
-				log.Printf("Domain %s check result (Uptrends only): available=%v, status=%d",
-					d.Name, isAvailable, uptrendsResult.StatusCode)
-			} else {
-				// Only Site24x7 available
-				finalResult = site24x7Result
-				isAvailable = site24x7Result.Available
-				log.Printf("Domain %s check result (Site24x7 only): available=%v, status=%d",
-					d.Name, isAvailable, site24x7Result.StatusCode)
+			// Combine the results with the domain's strategy. The most preferred result that
+			// agrees with the decision is used for the status details.
+			strategy := d.GetAvailabilityStrategy()
+			isAvailable, finalResult, dissenting := decideAvailability(strategy, results)
+			var summary []string
+			for _, r := range results {
+				summary = append(summary, fmt.Sprintf("%s: available=%v, status=%d", r.provider, r.result.Available, r.result.StatusCode))
+			}
+			logger.Info("Checked domain", "results", strings.Join(summary, " | "), "strategy", strategy, "available", isAvailable)
+			if len(dissenting) > 0 {
+				logger.Info("Providers disagreed with the final result", "dissenting", dissenting)
 			}
 
 			finalResult.Domain = d.Name
 			finalResult.Available = isAvailable
+			finalResult.DissentingProviders = dissenting
+
+			// A page that is served but fails the expected content, such as an ISP block
+			// page, counts as down
+			if isAvailable && d.ContentMatchType != "" && model.IsHTTPCheckType(d.CheckType) {
+				code, mismatch, err := s.checkContent(ctx, d)
+				if err != nil {
+					logger.Error("Failed to check content", "error", err)
+				} else if mismatch != "" {
+					logger.Info("Domain failed its content match", "mismatch", mismatch)
+					finalResult.Available = false
+					finalResult.StatusCode = 0
+					finalResult.ErrorCode = code
+					finalResult.ErrorDescription = mismatch
+				}
+			}
 
-			// Get previous status to detect changes
-			prevAvailable := d.Available()
+			// Get previous status to detect changes. A domain with an unconfirmed
+			// recovery is still considered down.
+			prevAvailable := d.ConfirmedAvailable()
 
-			// Update domain status in database
-			err := s.domainService.UpdateDomainStatus(d.ID, finalResult.StatusCode,
+			// Update domain status in database. Subscribers store the check history.
+			event := events.Event{Type: events.DomainChecked, UserID: d.UserID, DomainID: d.ID}
+			err = s.domainService.UpdateDomainStatus(d.ID, finalResult.StatusCode,
 				finalResult.ErrorCode, finalResult.TotalTime,
 				finalResult.ErrorDescription)
 			if err != nil {
-				log.Printf("Error updating status for domain %s: %v", d.Name, err)
+				logger.Error("Failed to update status", "error", err)
+			} else {
+				event.Payload = map[string]interface{}{"result": *finalResult}
 			}
+			s.domainService.Events().Publish(event)
 
 			// Get updated domain with new status
 			updatedDomain, _ := s.domainService.GetDomain(d.ID, d.UserID)
@@ -293,47 +205,94 @@ func (s *MonitorService) checkAllActiveDomains() {
 				// Get current availability status
 				currentAvailable := updatedDomain.Available()
 
+				// Hold back a new outage until the domain's failure threshold is reached. Failed
+				// checks below it raise no incident and no alert.
+				if !currentAvailable && (prevAvailable || d.LastCheck.IsZero()) {
+					threshold := updatedDomain.GetFailureThreshold()
+					if updatedDomain.ConsecutiveFailures < threshold {
+						logger.Info("Failure pending", "failures", updatedDomain.ConsecutiveFailures, "threshold", threshold)
+						if !d.FailurePending {
+							if err := s.domainService.UpdateFailureState(d.ID, true); err != nil {
+								logger.Error("Failed to update failure state", "error", err)
+							}
+						}
+						return
+					}
+				}
+				if d.FailurePending {
+					if err := s.domainService.UpdateFailureState(d.ID, false); err != nil {
+						logger.Error("Failed to clear failure state", "error", err)
+					}
+					updatedDomain.FailurePending = false
+				}
+
+				// Hold back the recovery until enough consecutive checks succeed. Domains that
+				// were never checked before have no outage to verify.
+				if !prevAvailable && currentAvailable && !d.LastCheck.IsZero() {
+					successes := d.RecoverySuccesses + 1
+					required := d.GetRecoveryThreshold(s.recoveryConfirmations)
+					if successes < required {
+						logger.Info("Recovery pending", "successes", successes, "required", required)
+						if err := s.domainService.UpdateRecoveryState(d.ID, true, successes); err != nil {
+							logger.Error("Failed to update recovery state", "error", err)
+						}
+						return
+					}
+				}
+				if d.RecoveryPending || d.RecoverySuccesses > 0 {
+					if err := s.domainService.UpdateRecoveryState(d.ID, false, 0); err != nil {
+						logger.Error("Failed to clear recovery state", "error", err)
+					}
+					updatedDomain.RecoveryPending = false
+					updatedDomain.RecoverySuccesses = 0
+				}
+
 				// Check if status changed (available → unavailable or vice versa)
 				statusChanged := prevAvailable != currentAvailable
 
 				// Only log status changes when they actually occur
 				if statusChanged {
-					log.Printf("Domain %s status changed: %v -> %v", d.Name, prevAvailable, currentAvailable)
+					logger.Info("Status changed", "was_available", prevAvailable, "available", currentAvailable)
+					s.domainService.Events().Publish(events.Event{
+						Type:     events.DomainStatusChanged,
+						UserID:   d.UserID,
+						DomainID: d.ID,
+						Payload:  map[string]interface{}{"available": currentAvailable},
+					})
 				} else {
-					log.Printf("Domain %s status unchanged: %v", d.Name, currentAvailable)
+					logger.Debug("Status unchanged", "available", currentAvailable)
 				}
 
-				// Send notification if domain is down or status changed
-				if !currentAvailable || statusChanged {
-					if statusChanged {
-						log.Printf("Domain %s status changed. Sending notification.", d.Name)
-					} else if !currentAvailable {
-						log.Printf("Domain %s is still down. Sending notification.", d.Name)
-					}
-
-					if s.telegramService != nil {
-						if err := s.telegramService.SendDomainStatusNotification(*updatedDomain, statusChanged); err != nil {
-							log.Printf("Failed to send Telegram notification for domain %s: %v", d.Name, err)
-						}
-					}
-
-					// Add email notification
-					if s.emailService != nil {
-						if err := s.emailService.SendDomainStatusNotification(*updatedDomain, statusChanged); err != nil {
-							log.Printf("Failed to send email notification for domain %s: %v", d.Name, err)
-						}
-					}
+				// Alerts are routed by the region that failed, which may be an extra region
+				updatedDomain.CheckedRegion = finalResult.Region
 
-					// Trigger deep check for CN region domains with is_deep_check enabled
-					if s.shouldTriggerDeepCheck(*updatedDomain, !currentAvailable) {
-						go s.triggerDeepCheck(*updatedDomain)
-					}
-				}
+				// Incidents, notifications and deep checks are handled by the subscribers
+				s.domainService.Events().Publish(events.Event{
+					Type:     events.DomainEvaluated,
+					UserID:   d.UserID,
+					DomainID: d.ID,
+					Payload:  map[string]interface{}{"domain": *updatedDomain, "status_changed": statusChanged},
+				})
 			}
 		}(domain)
 	}
 }
 
+// handleDomainAlert orders a deep check for an alerted outage. A persisting outage is
+// escalated once; otherwise CN region domains with is_deep_check enabled get one.
+func (s *MonitorService) handleDomainAlert(e events.Event) {
+	domain, ok := e.Payload["domain"].(model.Domain)
+	if !ok {
+		return
+	}
+	isDown := !domain.Available()
+	if s.shouldEscalateToDeepCheck(domain, isDown) {
+		s.goDeepCheck(domain, model.DeepCheckSourceEscalation)
+	} else if s.shouldTriggerDeepCheck(domain, isDown) {
+		s.goDeepCheck(domain, model.DeepCheckSourceAutomatic)
+	}
+}
+
 // shouldTriggerDeepCheck determines if a deep check should be triggered
 func (s *MonitorService) shouldTriggerDeepCheck(domain model.Domain, isDown bool) bool {
 	// Only trigger deep check if:
@@ -345,169 +304,165 @@ func (s *MonitorService) shouldTriggerDeepCheck(domain model.Domain, isDown bool
 		isDown
 }
 
+// shouldEscalateToDeepCheck reports whether the domain just reached the user's number
+// of consecutive failed checks. Escalation happens once per outage.
+func (s *MonitorService) shouldEscalateToDeepCheck(domain model.Domain, isDown bool) bool {
+	if !isDown || s.deepCheckService == nil {
+		return false
+	}
+
+	settings, err := s.deepCheckService.GetEscalationSettings(domain.UserID)
+	if err != nil {
+		s.logger.Error("Failed to get deep check escalation settings", "user_id", domain.UserID, "error", err)
+		return false
+	}
+	return settings.Failures > 0 && domain.ConsecutiveFailures == settings.Failures
+}
+
+// goDeepCheck orders a deep check in the background. Wait blocks until it is placed.
+func (s *MonitorService) goDeepCheck(domain model.Domain, source string) {
+	s.deepChecks.Add(1)
+	go func() {
+		defer s.deepChecks.Done()
+		s.triggerDeepCheck(domain, source)
+	}()
+}
+
 // triggerDeepCheck initiates a deep check for the domain
-func (s *MonitorService) triggerDeepCheck(domain model.Domain) {
+func (s *MonitorService) triggerDeepCheck(domain model.Domain, source string) {
+	logger := s.logger.With("domain_id", domain.ID, "domain", domain.Name, "source", source)
 	defer func() {
 		if r := recover(); r != nil {
-			log.Printf("[DEEP-CHECK] Recovered from panic while triggering deep check for domain %s: %v", domain.Name, r)
+			logger.Error("Recovered from panic while triggering deep check", "panic", r)
 		}
 	}()
 
 	if s.deepCheckClient == nil {
-		log.Printf("[DEEP-CHECK] WARNING: Deep check client not available for domain %s", domain.Name)
+		logger.Warn("Deep check client not available")
 		return
 	}
 
 	if s.deepCheckService == nil {
-		log.Printf("[DEEP-CHECK] WARNING: Deep check service not available for domain %s", domain.Name)
+		logger.Warn("Deep check service not available")
 		return
 	}
 
-	log.Printf("[DEEP-CHECK] Triggering deep check for CN domain %s (ID: %d)", domain.Name, domain.ID)
+	var failures *int
+	if source == model.DeepCheckSourceEscalation {
+		failures = &domain.ConsecutiveFailures
+		logger.Info("Escalating to deep check", "failures", domain.ConsecutiveFailures)
+	} else {
+		logger.Info("Triggering deep check")
+	}
 
 	// Call the deep check API
 	response, err := s.deepCheckClient.RequestDeepCheck(domain.Name)
 	if err != nil {
-		log.Printf("[DEEP-CHECK] ERROR: Failed to request deep check for domain %s: %v", domain.Name, err)
+		logger.Error("Failed to request deep check", "error", err)
 		return
 	}
 
-	log.Printf("[DEEP-CHECK] SUCCESS: Deep check initiated for domain %s - OrderID: %s",
-		domain.Name, response.OrderID)
+	logger.Info("Deep check initiated", "order_id", response.OrderID)
 
 	// Store the order in database for later callback handling
-	if err := s.deepCheckService.CreateDeepCheckOrder(response.OrderID, domain.UserID, domain.ID, domain.Name); err != nil {
-		log.Printf("[DEEP-CHECK] ERROR: Failed to store deep check order %s: %v", response.OrderID, err)
+	if err := s.deepCheckService.CreateDeepCheckOrder(response.OrderID, domain.UserID, domain.ID, domain.Name, source, failures); err != nil {
+		logger.Error("Failed to store deep check order", "order_id", response.OrderID, "error", err)
 		// Continue execution - the deep check is still running, we just can't track it
 	}
 }
 
-// RunScheduledChecks performs periodic checks on all active domains
-func (s *MonitorService) RunScheduledChecks() {
-	log.Printf("RunScheduledChecks")
+// RunScheduledChecks performs periodic checks on all active domains until ctx is cancelled
+func (s *MonitorService) RunScheduledChecks(ctx context.Context) {
+	s.logger.Info("RunScheduledChecks")
 	ticker := time.NewTicker(1 * time.Minute)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		s.checkAllActiveDomains()
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("RunScheduledChecks stopped")
+			return
+		case <-ticker.C:
+			s.checkAllActiveDomains(ctx)
+		}
 	}
 }
 
-// SyncMonitorStatus ensures that monitor statuses in Uptrends match the database
-func (s *MonitorService) SyncMonitorStatus() {
-	log.Printf("Starting monitor status sync")
+// monitorTagger is implemented by provider clients that tag monitors separately from
+// their structured name
+type monitorTagger interface {
+	UpdateMonitorTags(monitorID string, tags model.MonitorTags) error
+}
+
+// SyncMonitorStatus sets the active flag of every provider monitor to match its domain
+// and reapplies the ownership tags on providers that support them
+func (s *MonitorService) SyncMonitorStatus() (*model.MonitorSyncResult, error) {
+	s.logger.Info("Starting monitor status sync")
 
 	// Get all domains with monitor GUIDs
 	domains, err := s.domainService.GetAllDomainsWithMonitors()
 	if err != nil {
-		log.Printf("Error fetching domains with monitors: %v", err)
-		return
+		return nil, fmt.Errorf("failed to get domains with monitors: %w", err)
 	}
 
+	result := &model.MonitorSyncResult{Domains: len(domains)}
 	for _, domain := range domains {
-		// Update Uptrends monitor status if available
-		if domain.GetMonitorGuid() != "" && s.uptrendsClient != nil {
-			err := s.uptrendsClient.UpdateMonitorStatus(domain.GetMonitorGuid(), domain.Active)
-			if err != nil {
-				log.Printf("Error syncing Uptrends monitor status for domain %d: %v", domain.ID, err)
+		for _, provider := range s.domainService.MonitorProviderNames() {
+			monitorID := s.domainService.ProviderMonitorID(domain, provider)
+			if monitorID == "" {
+				continue
 			}
-		}
-
-		// Update Site24x7 monitor status if available
-		if domain.GetSite24x7MonitorID() != "" && s.site24x7Client != nil {
-			err := s.site24x7Client.UpdateMonitorStatus(domain.GetSite24x7MonitorID(), domain.Active)
-			if err != nil {
-				log.Printf("Error syncing Site24x7 monitor status for domain %d: %v", domain.ID, err)
+			client := s.domainService.ProviderClient(provider)
+			if err := client.UpdateMonitorStatus(monitorID, domain.Active); err != nil {
+				s.logger.Error("Failed to sync monitor status", "provider", provider, "domain_id", domain.ID, "error", err)
+				result.Failed++
+				result.Errors = append(result.Errors, fmt.Sprintf("domain %d (%s): %v", domain.ID, provider, err))
+				continue
 			}
+			if tagger, ok := client.(monitorTagger); ok {
+				tags := s.domainService.MonitorTags(domain.UserID, domain.ID)
+				if err := tagger.UpdateMonitorTags(monitorID, tags); err != nil {
+					s.logger.Warn("Failed to sync monitor tags", "provider", provider, "domain_id", domain.ID, "error", err)
+				}
+			}
+			result.Synced++
 		}
 	}
 
-	log.Printf("Completed monitor status sync")
+	s.logger.Info("Completed monitor status sync", "synced", result.Synced, "failed", result.Failed)
+	return result, nil
+}
+
+// ProviderStats returns the request counters of the provider API clients
+func (s *MonitorService) ProviderStats() []model.ProviderClientStats {
+	return []model.ProviderClientStats{s.uptrendsClient.Stats(), s.site24x7Client.Stats()}
 }
 
-// checkDomainDirect performs a direct HTTP check from the application
-// func (s *MonitorService) checkDomainDirect(fullURL string) (*model.DomainCheckResult, error) {
-// 	start := time.Now()
-
-// 	// Parse the URL
-// 	parsedURL, err := url.Parse(fullURL)
-// 	if err != nil {
-// 		return nil, fmt.Errorf("invalid URL format: %w", err)
-// 	}
-
-// 	// If no scheme provided, default to HTTPS
-// 	if parsedURL.Scheme == "" {
-// 		fullURL = fmt.Sprintf("https://%s", fullURL)
-// 	}
-
-// 	// Create HTTP client with timeout
-// 	client := &http.Client{
-// 		Timeout: 10 * time.Second,
-// 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-// 			// Allow up to 10 redirects
-// 			if len(via) >= 10 {
-// 				return errors.New("too many redirects")
-// 			}
-// 			return nil
-// 		},
-// 	}
-
-// 	// Create request
-// 	req, err := http.NewRequest("GET", fullURL, nil)
-// 	if err != nil {
-// 		return nil, fmt.Errorf("error creating request: %w", err)
-// 	}
-
-// 	// Add user agent
-// 	req.Header.Set("User-Agent", "DomainMonitor/1.0")
-
-// 	// Perform request
-// 	resp, err := client.Do(req)
-
-// 	// Calculate response time regardless of error
-// 	responseTime := int(time.Since(start).Milliseconds())
-
-// 	// Log any errors from the HTTP request
-// 	if err != nil {
-// 		log.Printf("Direct check error for domain %s: %v", fullURL, err)
-// 	}
-
-// 	// Check for connection errors
-// 	if err != nil {
-// 		// Return result with error info
-// 		return &model.DomainCheckResult{
-// 			Domain:           fullURL,
-// 			StatusCode:       0,
-// 			ResponseTime:     responseTime,
-// 			Available:        false,
-// 			TotalTime:        responseTime,
-// 			ErrorCode:        -1, // Custom error code for connection issues
-// 			ErrorDescription: fmt.Sprintf("Connection error: %v", err),
-// 			CheckedAt:        time.Now(),
-// 		}, nil
-// 	}
-// 	defer resp.Body.Close()
-
-// 	// Read a small portion of the body to ensure connection is working
-// 	// but don't download everything
-// 	buffer := make([]byte, 1024)
-// 	_, err = resp.Body.Read(buffer)
-
-// 	// Log response details
-// 	log.Printf("Direct check response for %s: status=%d (%s), time=%dms",
-// 		fullURL, resp.StatusCode, resp.Status, responseTime)
-
-// 	return &model.DomainCheckResult{
-// 		Domain:           fullURL,
-// 		StatusCode:       resp.StatusCode,
-// 		ResponseTime:     responseTime,
-// 		Available:        resp.StatusCode >= 200 && resp.StatusCode < 400,
-// 		TotalTime:        responseTime,
-// 		ErrorCode:        0,
-// 		ErrorDescription: resp.Status,
-// 		CheckedAt:        time.Now(),
-// 	}, nil
-// }
+// InvalidateCheckpoints drops the cached Uptrends checkpoint IDs and returns the regions
+// that were cached
+func (s *MonitorService) InvalidateCheckpoints() []string {
+	return s.uptrendsClient.InvalidateCheckpoints()
+}
+
+// RunScheduledStatusSync syncs provider monitor active flags at the given interval until
+// ctx is cancelled
+func (s *MonitorService) RunScheduledStatusSync(ctx context.Context, interval time.Duration) {
+	s.logger.Info("RunScheduledStatusSync")
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("RunScheduledStatusSync stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.SyncMonitorStatus(); err != nil {
+				s.logger.Error("Monitor status sync failed", "error", err)
+			}
+		}
+	}
+}
 
 // isDomainDueForCheck determines if a domain is due for a check based on its interval
 func isDomainDueForCheck(domain model.Domain, now time.Time) bool {
@@ -516,11 +471,16 @@ func isDomainDueForCheck(domain model.Domain, now time.Time) bool {
 		return true
 	}
 
-	// Calculate next check time based on interval (in minutes)
-	nextCheckTime := domain.LastCheck.Add(time.Duration(domain.Interval) * time.Minute)
+	// Calculate next check time based on interval; a skipped check consumes its slot
+	next := nextCheckTime(domain)
 
 	// If the next check time has passed, the domain is due for a check
-	return now.After(nextCheckTime) || now.Equal(nextCheckTime)
+	return now.After(next) || now.Equal(next)
+}
+
+// Wait blocks until the deep checks ordered in the background have been placed
+func (s *MonitorService) Wait() {
+	s.deepChecks.Wait()
 }
 
 // Close cleans up resources
@@ -0,0 +1,137 @@
+package monitor
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"domain-detection-go/pkg/model"
+)
+
+// providerCheckDeadline bounds how long a domain check waits for its providers. Providers
+// that have not answered by then are treated as failed for this check, and their
+// requests are cancelled.
+const providerCheckDeadline = 30 * time.Second
+
+// providerOutcome is what one provider reported for a domain
+type providerOutcome struct {
+	index    int
+	result   *providerResult
+	statuses []model.DomainRegionStatus
+}
+
+// checkProviders asks every selected provider and the built-in checker for the domain's
+// latest result at the same time, and returns the answers in order of preference with
+// the status of every region checked. A provider reports the domain's primary region
+// unless another of the domain's own regions is down; fallback regions never decide.
+func (s *MonitorService) checkProviders(d model.Domain, providers []string, own, regions []string, logger *slog.Logger) ([]providerResult, []model.DomainRegionStatus) {
+	ctx, cancel := context.WithTimeout(context.Background(), providerCheckDeadline)
+	defer cancel()
+
+	var checks []func() (*providerResult, []model.DomainRegionStatus)
+	for _, provider := range providers {
+		checks = append(checks, func() (*providerResult, []model.DomainRegionStatus) {
+			return s.checkProvider(ctx, d, provider, own, regions, logger)
+		})
+	}
+	if directID := d.GetDirectMonitorID(); directID != "" && s.directClient != nil {
+		checks = append(checks, func() (*providerResult, []model.DomainRegionStatus) {
+			result, err := s.directClient.GetLatestMonitorCheck(ctx, directID, d.Region)
+			if err != nil {
+				logger.Error("Provider check failed", "provider", model.ProviderDirect, "error", err)
+				return nil, nil
+			}
+			if result == nil {
+				return nil, nil
+			}
+			return &providerResult{model.ProviderDirect, result}, nil
+		})
+	}
+
+	// Buffered so providers answering after the deadline do not block
+	outcomes := make(chan providerOutcome, len(checks))
+	for i, check := range checks {
+		go func(i int, check func() (*providerResult, []model.DomainRegionStatus)) {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Error("Recovered from panic while checking provider", "panic", r)
+					outcomes <- providerOutcome{index: i}
+				}
+			}()
+			result, statuses := check()
+			outcomes <- providerOutcome{index: i, result: result, statuses: statuses}
+		}(i, check)
+	}
+
+	answered := make([]*providerOutcome, len(checks))
+collect:
+	for received := 0; received < len(checks); received++ {
+		select {
+		case outcome := <-outcomes:
+			answered[outcome.index] = &outcome
+		case <-ctx.Done():
+			logger.Warn("Providers did not answer before the deadline", "answered", received, "providers", len(checks))
+			break collect
+		}
+	}
+
+	var results []providerResult
+	var regionStatuses []model.DomainRegionStatus
+	for _, outcome := range answered {
+		if outcome == nil {
+			continue
+		}
+		if outcome.result != nil {
+			results = append(results, *outcome.result)
+		}
+		regionStatuses = append(regionStatuses, outcome.statuses...)
+	}
+	return results, regionStatuses
+}
+
+// checkProvider gets the latest result of one provider in each region it checks from,
+// creating its monitor if missing. Regions not reached before ctx is done are skipped.
+func (s *MonitorService) checkProvider(ctx context.Context, d model.Domain, provider string, own, regions []string, logger *slog.Logger) (*providerResult, []model.DomainRegionStatus) {
+	monitorID := s.domainService.EnsureMonitor(d, provider)
+	if monitorID == "" {
+		return nil, nil
+	}
+	providerRegions := regions[:1]
+	if s.domainService.ChecksFromFallbackRegions(provider) {
+		providerRegions = regions
+	}
+
+	var chosen *model.DomainCheckResult
+	var regionStatuses []model.DomainRegionStatus
+	for i, region := range providerRegions {
+		if ctx.Err() != nil {
+			break
+		}
+		result, err := s.domainService.ProviderClient(provider).GetLatestMonitorCheck(ctx, monitorID, region)
+		if err != nil {
+			logger.Error("Provider check failed", "provider", provider, "region", region, "error", err)
+			continue
+		}
+		if result == nil {
+			continue
+		}
+		regionStatuses = append(regionStatuses, model.DomainRegionStatus{
+			DomainID:         d.ID,
+			Provider:         provider,
+			Region:           region,
+			Available:        result.Available,
+			StatusCode:       result.StatusCode,
+			ErrorCode:        result.ErrorCode,
+			ErrorDescription: result.ErrorDescription,
+			TotalTime:        result.TotalTime,
+		})
+		if i < len(own) && (chosen == nil || (chosen.Available && !result.Available)) {
+			result.Region = region
+			chosen = result
+		}
+	}
+	if chosen == nil {
+		return nil, regionStatuses
+	}
+	return &providerResult{provider, chosen}, regionStatuses
+}
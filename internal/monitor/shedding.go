@@ -0,0 +1,101 @@
+package monitor
+
+import (
+	"sort"
+	"time"
+
+	"domain-detection-go/pkg/model"
+)
+
+// Reasons recorded for checks skipped while shedding load
+const (
+	skipReasonSampled = "load_shedding_sampled"
+)
+
+// flappingWindow and flappingMinChanges define when a domain counts as flapping
+const (
+	flappingWindow     = 1 * time.Hour
+	flappingMinChanges = 3
+)
+
+// LoadShedding configures how the scheduler behaves when more checks are due than
+// it can run in one pass
+type LoadShedding struct {
+	BacklogThreshold  int // Due checks above which shedding starts, 0 disables it
+	SampleMinInterval int // Healthy domains with at least this interval (minutes) may be sampled
+}
+
+// shedLoad returns the domains to check in this run. Below the backlog threshold every
+// due domain is checked. Above it, domains that are down or flapping go first and the
+// rest follow most overdue first; healthy long-interval domains skip every other slot,
+// and each skip is recorded so uptime calculations can exclude it. Domains beyond the
+// threshold stay due and are picked up by the next run.
+func (s *MonitorService) shedLoad(due []model.Domain, now time.Time) []model.Domain {
+	threshold := s.shedding.BacklogThreshold
+	if threshold <= 0 || len(due) <= threshold {
+		return due
+	}
+
+	flapping, err := s.domainService.GetFlappingDomainIDs(flappingWindow, flappingMinChanges)
+	if err != nil {
+		s.logger.Error("Failed to get flapping domains", "error", err)
+		flapping = map[int]bool{}
+	}
+
+	var priority, rest []model.Domain
+	skipped := 0
+	for _, d := range due {
+		if !d.ConfirmedAvailable() || flapping[d.ID] {
+			priority = append(priority, d)
+			continue
+		}
+		if s.canSample(d) {
+			if err := s.domainService.RecordSkippedCheck(d.ID, skipReasonSampled, len(due)); err != nil {
+				s.logger.Error("Failed to record skipped check", "domain_id", d.ID, "error", err)
+				rest = append(rest, d)
+				continue
+			}
+			skipped++
+			continue
+		}
+		rest = append(rest, d)
+	}
+
+	// Most overdue first, so no domain is starved while the backlog lasts
+	sort.SliceStable(rest, func(i, j int) bool {
+		return nextCheckTime(rest[i]).Before(nextCheckTime(rest[j]))
+	})
+
+	selected := append(priority, rest...)
+	deferred := 0
+	if len(selected) > threshold {
+		deferred = len(selected) - threshold
+		selected = selected[:threshold]
+	}
+
+	s.logger.Warn("Check backlog exceeds threshold", "due", len(due), "threshold", threshold,
+		"checking", len(selected), "priority", len(priority), "sampled_out", skipped, "deferred", deferred)
+	return selected
+}
+
+// canSample reports whether a healthy domain may skip its current slot. Only domains
+// with a long interval are sampled, and never twice in a row.
+func (s *MonitorService) canSample(d model.Domain) bool {
+	if s.shedding.SampleMinInterval <= 0 || d.Interval < s.shedding.SampleMinInterval {
+		return false
+	}
+	if d.LastCheck.IsZero() {
+		return false
+	}
+	return d.LastSkippedAt == nil || d.LastSkippedAt.Before(d.LastCheck)
+}
+
+// nextCheckTime returns when a domain's next slot starts, jittered so domains with the
+// same interval spread out. Skipped checks consume their slot just like performed ones.
+func nextCheckTime(d model.Domain) time.Time {
+	lastSlot := d.LastCheck
+	if d.LastSkippedAt != nil && d.LastSkippedAt.After(lastSlot) {
+		lastSlot = *d.LastSkippedAt
+	}
+	return lastSlot.Add(time.Duration(d.Interval)*time.Minute - checkJitter(d, lastSlot))
+}
@@ -2,33 +2,41 @@ package monitor
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"domain-detection-go/internal/outbound"
 	"domain-detection-go/pkg/model"
 )
 
 // Site24x7Config holds configuration for Site24x7 API
 type Site24x7Config struct {
-	ClientID     string
-	ClientSecret string
-	RefreshToken string
-	BaseURL      string
+	ClientID      string
+	ClientSecret  string
+	RefreshToken  string
+	BaseURL       string
+	MaxConcurrent int // Requests in flight at once
 }
 
 // Site24x7Client is a client for the Site24x7 API
 type Site24x7Client struct {
 	config      Site24x7Config
-	httpClient  *http.Client
+	httpClient  *outbound.Client
 	accessToken string
 	tokenExpiry time.Time
 	tokenMutex  sync.RWMutex
+
+	tagMutex sync.Mutex        // Guards tagIDs, never held across requests
+	tagIDs   map[string]string // "name=value" to Site24x7 tag ID, loaded on first use
 }
 
 // TokenResponse represents the OAuth token response
@@ -56,6 +64,19 @@ type MonitorCreateRequest struct {
 	MatchCase             bool     `json:"match_case"`
 	UserAgent             string   `json:"user_agent"`
 	UseNameServer         bool     `json:"use_name_server"`
+	TagIDs                []string `json:"tag_ids,omitempty"`
+
+	CustomHeaders         []Site24x7Header `json:"custom_headers,omitempty"`
+	AuthUser              string           `json:"auth_user,omitempty"`
+	AuthPass              string           `json:"auth_pass,omitempty"`
+	UpStatusCodes         string           `json:"up_status_codes,omitempty"` // Comma separated
+	FollowHTTPRedirection bool             `json:"follow_http_redirection"`
+}
+
+// Site24x7Header is a custom request header of a monitor
+type Site24x7Header struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
 }
 
 // MonitorCreateResponse represents a monitor creation response
@@ -125,9 +146,12 @@ type LogEntry struct {
 func NewSite24x7Client(config Site24x7Config) *Site24x7Client {
 	return &Site24x7Client{
 		config: config,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		httpClient: outbound.NewClient(outbound.Config{
+			Name:          "Site24x7",
+			Timeout:       30 * time.Second,
+			MaxConcurrent: config.MaxConcurrent,
+			MaxRetries:    3,
+		}),
 	}
 }
 
@@ -137,7 +161,7 @@ func (c *Site24x7Client) getAccessToken() (string, error) {
 	if c.accessToken != "" && time.Now().Before(c.tokenExpiry) {
 		token := c.accessToken
 		c.tokenMutex.RUnlock()
-		log.Printf("DEBUG: Using cached Site24x7 token (expires at %v)", c.tokenExpiry)
+		slog.Debug("Using cached Site24x7 token", "expires_at", c.tokenExpiry)
 		return token, nil
 	}
 	c.tokenMutex.RUnlock()
@@ -147,14 +171,11 @@ func (c *Site24x7Client) getAccessToken() (string, error) {
 
 	// Double-check after acquiring write lock
 	if c.accessToken != "" && time.Now().Before(c.tokenExpiry) {
-		log.Printf("DEBUG: Using cached Site24x7 token after lock (expires at %v)", c.tokenExpiry)
+		slog.Debug("Using cached Site24x7 token", "expires_at", c.tokenExpiry)
 		return c.accessToken, nil
 	}
 
-	log.Printf("DEBUG: Refreshing Site24x7 token...")
-	log.Printf("DEBUG: Using Client ID: %s", c.config.ClientID)
-	log.Printf("DEBUG: Using Base URL: %s", c.config.BaseURL)
-	log.Printf("DEBUG: Refresh token length: %d", len(c.config.RefreshToken))
+	slog.Debug("Refreshing Site24x7 token", "client_id", c.config.ClientID, "base_url", c.config.BaseURL)
 
 	// Refresh token
 	data := url.Values{}
@@ -164,32 +185,34 @@ func (c *Site24x7Client) getAccessToken() (string, error) {
 	data.Set("grant_type", "refresh_token")
 
 	tokenURL := "https://accounts.zoho.com/oauth/v2/token"
-	log.Printf("DEBUG: Requesting token from: %s", tokenURL)
 
-	resp, err := c.httpClient.PostForm(tokenURL, data)
+	req, err := http.NewRequest("POST", tokenURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("error creating token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		log.Printf("ERROR: Failed to make token request: %v", err)
+		slog.Error("Site24x7 token request failed", "error", err)
 		return "", fmt.Errorf("error refreshing token: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("ERROR: Failed to read token response: %v", err)
+		slog.Error("Failed to read Site24x7 token response", "error", err)
 		return "", fmt.Errorf("error reading token response: %w", err)
 	}
 
-	log.Printf("DEBUG: Token response status: %d", resp.StatusCode)
-	log.Printf("DEBUG: Token response body: %s", string(body))
-
 	if resp.StatusCode != http.StatusOK {
-		log.Printf("ERROR: Token refresh failed with status %d: %s", resp.StatusCode, string(body))
+		slog.Error("Site24x7 token refresh failed", "status", resp.StatusCode)
 		return "", fmt.Errorf("token refresh failed with status %d: %s", resp.StatusCode, string(body))
 	}
 
 	var tokenResp TokenResponse
 	if err := json.Unmarshal(body, &tokenResp); err != nil {
-		log.Printf("ERROR: Failed to parse token response: %v", err)
+		slog.Error("Failed to parse Site24x7 token response", "error", err)
 		return "", fmt.Errorf("error parsing token response: %w", err)
 	}
 
@@ -197,10 +220,7 @@ func (c *Site24x7Client) getAccessToken() (string, error) {
 	// Set expiry to 50 minutes (token expires in 60 minutes)
 	c.tokenExpiry = time.Now().Add(time.Duration(tokenResp.ExpiresIn-600) * time.Second)
 
-	log.Printf("SUCCESS: Site24x7 token refreshed successfully")
-	log.Printf("DEBUG: New token length: %d", len(c.accessToken))
-	log.Printf("DEBUG: Token expires at: %v", c.tokenExpiry)
-	log.Printf("DEBUG: API Domain: %s", tokenResp.APIDomain)
+	slog.Info("Site24x7 token refreshed", "expires_at", c.tokenExpiry, "api_domain", tokenResp.APIDomain)
 
 	return c.accessToken, nil
 }
@@ -228,17 +248,19 @@ func getSite24x7LocationProfileID(region string) string {
 }
 
 // CreateMonitor creates a new monitor in Site24x7
-func (c *Site24x7Client) CreateMonitor(fullURL string, name string, regions []string) (string, error) {
-	log.Printf("DEBUG: Creating Site24x7 monitor for URL: %s, Name: %s, Regions: %v", fullURL, name, regions)
+func (c *Site24x7Client) CreateMonitor(fullURL string, name string, tags model.MonitorTags, regions []string, interval int, settings model.HTTPCheckSettings) (string, error) {
+	slog.Debug("Creating Site24x7 monitor", "url", fullURL, "name", name, "regions", regions, "tags", tags.Fields())
 
 	token, err := c.getAccessToken()
 	if err != nil {
-		log.Printf("ERROR: Failed to get access token: %v", err)
+		slog.Error("Failed to get Site24x7 access token", "error", err)
 		return "", fmt.Errorf("failed to get access token: %w", err)
 	}
 
-	// Parse the URL to determine HTTP method
 	httpMethod := "G" // GET
+	if settings.HTTPMethod == model.HTTPMethodPost {
+		httpMethod = "P"
+	}
 	if fullURL == "" {
 		return "", fmt.Errorf("URL cannot be empty")
 	}
@@ -252,13 +274,11 @@ func (c *Site24x7Client) CreateMonitor(fullURL string, name string, regions []st
 	// Get the appropriate location profile ID for the user's region
 	locationProfileID := getSite24x7LocationProfileID(region)
 
-	log.Printf("DEBUG: Using region: %s, Location Profile ID: %s", region, locationProfileID)
-
 	createReq := MonitorCreateRequest{
-		DisplayName:           fmt.Sprintf("Monitor - %s", name),
+		DisplayName:           name,
 		Type:                  "URL",
 		Website:               fullURL,
-		CheckFrequency:        "5", // Check every 5 minutes
+		CheckFrequency:        site24x7CheckFrequency(interval),
 		Timeout:               15,
 		HTTPMethod:            httpMethod,
 		LocationProfileID:     locationProfileID,              // Use region-specific location profile
@@ -269,22 +289,72 @@ func (c *Site24x7Client) CreateMonitor(fullURL string, name string, regions []st
 		MatchCase:             false,
 		UserAgent:             "Mozilla Firefox",
 		UseNameServer:         false,
+		AuthUser:              settings.BasicAuthUsername,
+		FollowHTTPRedirection: settings.FollowRedirects,
+	}
+	// A monitor without tags still carries its ownership in the structured name
+	if tagIDs, err := c.monitorTagIDs(tags); err != nil {
+		slog.Warn("Creating Site24x7 monitor without tags", "url", fullURL, "error", err)
+	} else {
+		createReq.TagIDs = tagIDs
+	}
+	for headerName := range settings.HTTPHeaders {
+		createReq.CustomHeaders = append(createReq.CustomHeaders, Site24x7Header{Name: headerName, Value: "********"})
+	}
+	var upStatusCodes []string
+	for _, code := range settings.ExpectedStatusCodes {
+		upStatusCodes = append(upStatusCodes, strconv.Itoa(code))
+	}
+	createReq.UpStatusCodes = strings.Join(upStatusCodes, ",")
+
+	// Log the payload with header values and credentials masked
+	if settings.BasicAuthPassword != "" {
+		createReq.AuthPass = "********"
+	}
+	if loggedData, err := json.Marshal(createReq); err == nil {
+		slog.Debug("Site24x7 create monitor request", "payload", string(loggedData))
 	}
 
+	createReq.AuthPass = settings.BasicAuthPassword
+	createReq.CustomHeaders = nil
+	for headerName, value := range settings.HTTPHeaders {
+		createReq.CustomHeaders = append(createReq.CustomHeaders, Site24x7Header{Name: headerName, Value: value})
+	}
+
+	slog.Info("Creating Site24x7 monitor", "url", fullURL, "region", region, "location_profile_id", locationProfileID)
+
+	monitorID, err := c.postMonitor(token, createReq)
+	if err != nil && len(createReq.TagIDs) > 0 && isInvalidTagError(err) {
+		// A tag was deleted from the console since its ID was cached
+		slog.Warn("Site24x7 rejected cached tags, reloading them", "url", fullURL, "error", err)
+		c.resetTagIDs()
+		createReq.TagIDs = nil
+		if tagIDs, tagErr := c.monitorTagIDs(tags); tagErr == nil {
+			createReq.TagIDs = tagIDs
+		}
+		monitorID, err = c.postMonitor(token, createReq)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	slog.Info("Created Site24x7 monitor", "monitor_id", monitorID, "url", fullURL, "region", region)
+	return monitorID, nil
+}
+
+// postMonitor sends a monitor create request and returns the ID of the new monitor
+func (c *Site24x7Client) postMonitor(token string, createReq MonitorCreateRequest) (string, error) {
 	jsonData, err := json.Marshal(createReq)
 	if err != nil {
-		log.Printf("ERROR: Failed to marshal create request: %v", err)
+		slog.Error("Failed to marshal Site24x7 create request", "error", err)
 		return "", fmt.Errorf("error marshaling request: %w", err)
 	}
 
-	log.Printf("DEBUG: Create monitor request payload: %s", string(jsonData))
-
 	apiURL := "https://www.site24x7.com/api/monitors"
-	log.Printf("DEBUG: Making request to: %s", apiURL)
 
 	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(jsonData))
 	if err != nil {
-		log.Printf("ERROR: Failed to create HTTP request: %v", err)
+		slog.Error("Failed to create Site24x7 request", "error", err)
 		return "", fmt.Errorf("error creating request: %w", err)
 	}
 
@@ -292,64 +362,75 @@ func (c *Site24x7Client) CreateMonitor(fullURL string, name string, regions []st
 	req.Header.Set("Accept", "application/json; version=2.1")
 	req.Header.Set("Authorization", fmt.Sprintf("Zoho-oauthtoken %s", token))
 
-	log.Printf("DEBUG: Request headers: %v", req.Header)
-
-	log.Printf("Creating Site24x7 monitor for %s in region %s (profile: %s)", fullURL, region, locationProfileID)
-
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		log.Printf("ERROR: Failed to make HTTP request: %v", err)
+		slog.Error("Site24x7 create monitor request failed", "error", err)
 		return "", fmt.Errorf("error making request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("ERROR: Failed to read response body: %v", err)
+		slog.Error("Failed to read Site24x7 response", "error", err)
 		return "", fmt.Errorf("error reading response: %w", err)
 	}
 
-	log.Printf("DEBUG: Response status: %d", resp.StatusCode)
-	log.Printf("DEBUG: Response headers: %v", resp.Header)
-	log.Printf("DEBUG: Response body: %s", string(body))
+	slog.Debug("Site24x7 create monitor response", "status", resp.StatusCode, "body", string(body))
 
 	// Site24x7 returns 201 (Created) for successful monitor creation, not 200 (OK)
 	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-		log.Printf("ERROR: API returned non-success status: %d, body: %s", resp.StatusCode, string(body))
+		slog.Error("Site24x7 create monitor failed", "status", resp.StatusCode, "body", string(body))
 		return "", fmt.Errorf("API returned non-success status: %d, body: %s", resp.StatusCode, string(body))
 	}
 
 	var createResp MonitorCreateResponse
 	if err := json.Unmarshal(body, &createResp); err != nil {
-		log.Printf("ERROR: Failed to parse create response: %v", err)
+		slog.Error("Failed to parse Site24x7 create response", "error", err)
 		return "", fmt.Errorf("error parsing response: %w", err)
 	}
 
 	if createResp.Code != 0 {
-		log.Printf("ERROR: Site24x7 API error: Code=%d, Message=%s", createResp.Code, createResp.Message)
+		slog.Error("Site24x7 API error", "code", createResp.Code, "message", createResp.Message)
 		return "", fmt.Errorf("Site24x7 API error: %s", createResp.Message)
 	}
-
-	log.Printf("SUCCESS: Created Site24x7 monitor %s for %s in region %s", createResp.Data.MonitorID, fullURL, region)
-
 	return createResp.Data.MonitorID, nil
 }
 
 // UpdateMonitorStatus updates the status of a monitor
 func (c *Site24x7Client) UpdateMonitorStatus(monitorID string, isActive bool) error {
+	slog.Debug("Updating Site24x7 monitor status", "monitor_id", monitorID, "active", isActive)
+
+	// Site24x7 uses suspend_alert: true to disable, false to enable
+	if err := c.updateMonitor(monitorID, map[string]interface{}{"suspend_alert": !isActive}); err != nil {
+		return err
+	}
+
+	slog.Info("Updated Site24x7 monitor status", "monitor_id", monitorID, "active", isActive)
+	return nil
+}
+
+// UpdateMonitorInterval sets the Site24x7 check frequency to the closest one the domain
+// interval allows
+func (c *Site24x7Client) UpdateMonitorInterval(monitorID string, interval int) error {
+	frequency := site24x7CheckFrequency(interval)
+	if err := c.updateMonitor(monitorID, map[string]interface{}{"check_frequency": frequency}); err != nil {
+		return err
+	}
+
+	slog.Info("Updated Site24x7 monitor check frequency", "monitor_id", monitorID, "check_frequency", frequency)
+	return nil
+}
+
+// updateMonitor changes the given fields of a monitor
+func (c *Site24x7Client) updateMonitor(monitorID string, updateRequest map[string]interface{}) error {
 	token, err := c.getAccessToken()
 	if err != nil {
 		return fmt.Errorf("failed to get access token: %w", err)
 	}
 
-	// Site24x7 uses PUT with JSON data to update monitor status
+	// Site24x7 uses PUT with JSON data to update monitors
 	endpoint := fmt.Sprintf("https://www.site24x7.com/api/monitors/%s", monitorID)
-
-	// Create update request body
-	updateRequest := map[string]interface{}{
-		"monitor_id":    monitorID,
-		"suspend_alert": !isActive, // Site24x7 uses suspend_alert: true to disable, false to enable
-	}
+	updateRequest["monitor_id"] = monitorID
 
 	jsonData, err := json.Marshal(updateRequest)
 	if err != nil {
@@ -365,8 +446,6 @@ func (c *Site24x7Client) UpdateMonitorStatus(monitorID string, isActive bool) er
 	req.Header.Set("Accept", "application/json; version=2.1")
 	req.Header.Set("Authorization", fmt.Sprintf("Zoho-oauthtoken %s", token))
 
-	log.Printf("Updating Site24x7 monitor %s status to active=%v", monitorID, isActive)
-
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("error making request: %w", err)
@@ -396,8 +475,6 @@ func (c *Site24x7Client) UpdateMonitorStatus(monitorID string, isActive bool) er
 	if updateResp.Code != 0 {
 		return fmt.Errorf("Site24x7 API error: %s", updateResp.Message)
 	}
-
-	log.Printf("Successfully updated Site24x7 monitor %s status to active=%v", monitorID, isActive)
 	return nil
 }
 
@@ -430,12 +507,63 @@ func (c *Site24x7Client) DeleteMonitor(monitorID string) error {
 		return fmt.Errorf("API returned non-success status: %d, body: %s", resp.StatusCode, string(body))
 	}
 
-	log.Printf("Successfully deleted Site24x7 monitor %s", monitorID)
+	slog.Info("Deleted Site24x7 monitor", "monitor_id", monitorID)
 	return nil
 }
 
+// ListMonitors returns every monitor on the Site24x7 account
+func (c *Site24x7Client) ListMonitors() ([]model.ProviderMonitor, error) {
+	token, err := c.getAccessToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access token: %w", err)
+	}
+
+	req, err := http.NewRequest("GET", "https://www.site24x7.com/api/monitors", nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json; version=2.1")
+	req.Header.Set("Authorization", fmt.Sprintf("Zoho-oauthtoken %s", token))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned non-success status: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Data    []struct {
+			MonitorID   string `json:"monitor_id"`
+			DisplayName string `json:"display_name"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("error parsing response: %w", err)
+	}
+	if result.Code != 0 {
+		return nil, fmt.Errorf("Site24x7 API error: %s", result.Message)
+	}
+
+	monitors := make([]model.ProviderMonitor, 0, len(result.Data))
+	for _, m := range result.Data {
+		monitors = append(monitors, model.ProviderMonitor{ID: m.MonitorID, Name: m.DisplayName})
+	}
+	return monitors, nil
+}
+
 // GetLatestMonitorCheck gets the latest check result for a monitor
-func (c *Site24x7Client) GetLatestMonitorCheck(monitorID, region string) (*model.DomainCheckResult, error) {
+func (c *Site24x7Client) GetLatestMonitorCheck(ctx context.Context, monitorID, region string) (*model.DomainCheckResult, error) {
 	token, err := c.getAccessToken()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get access token: %w", err)
@@ -456,9 +584,9 @@ func (c *Site24x7Client) GetLatestMonitorCheck(monitorID, region string) (*model
 		url.QueryEscape(startTimeStr),
 		url.QueryEscape(endTimeStr))
 
-	log.Printf("Getting Site24x7 log reports for monitor %s: %s", monitorID, requestURL)
+	slog.Debug("Getting Site24x7 log reports", "monitor_id", monitorID, "url", requestURL)
 
-	req, err := http.NewRequest("GET", requestURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
@@ -515,7 +643,7 @@ func (c *Site24x7Client) GetLatestMonitorCheck(monitorID, region string) (*model
 	// Parse timestamp
 	checkedAt, err := time.Parse("2006-01-02T15:04:05-0700", latestEntry.CollectionTime)
 	if err != nil {
-		log.Printf("Could not parse timestamp '%s': %v. Using current time.", latestEntry.CollectionTime, err)
+		slog.Warn("Could not parse Site24x7 timestamp, using current time", "timestamp", latestEntry.CollectionTime, "error", err)
 		checkedAt = time.Now()
 	}
 
@@ -537,3 +665,24 @@ func (c *Site24x7Client) GetLatestMonitorCheck(monitorID, region string) (*model
 func (c *Site24x7Client) Close() {
 	// No persistent connections to close for Site24x7
 }
+
+// SetUsageRecorder reports every request sent to the Site24x7 API to the recorder
+func (c *Site24x7Client) SetUsageRecorder(recorder outbound.Recorder) {
+	c.httpClient.SetRecorder(recorder)
+}
+
+// Stats returns the request counters of the Site24x7 API client
+func (c *Site24x7Client) Stats() model.ProviderClientStats {
+	return c.httpClient.Stats()
+}
+
+// Configured reports whether Site24x7 OAuth credentials are set
+func (c *Site24x7Client) Configured() bool {
+	return c.config.ClientID != "" && c.config.RefreshToken != ""
+}
+
+// Ping verifies that a valid access token can be obtained
+func (c *Site24x7Client) Ping() error {
+	_, err := c.getAccessToken()
+	return err
+}
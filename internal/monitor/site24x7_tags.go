@@ -0,0 +1,203 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+
+	"domain-detection-go/pkg/model"
+)
+
+// site24x7TagColor is the color new ownership tags are created with
+const site24x7TagColor = "#A2D9CE"
+
+// site24x7Tag is a tag as listed and created through the Site24x7 tags API
+type site24x7Tag struct {
+	TagID    string `json:"tag_id,omitempty"`
+	TagName  string `json:"tag_name"`
+	TagValue string `json:"tag_value"`
+	TagColor string `json:"tag_color,omitempty"`
+}
+
+// UpdateMonitorTags replaces the tags of a monitor with the ownership tags, so monitors
+// created before tagging can be attributed from the Site24x7 console too
+func (c *Site24x7Client) UpdateMonitorTags(monitorID string, tags model.MonitorTags) error {
+	tagIDs, err := c.monitorTagIDs(tags)
+	if err != nil {
+		return err
+	}
+	err = c.updateMonitor(monitorID, map[string]interface{}{"tag_ids": tagIDs})
+	if err != nil && isInvalidTagError(err) {
+		// A tag was deleted from the console since its ID was cached
+		slog.Warn("Site24x7 rejected cached tags, reloading them", "monitor_id", monitorID, "error", err)
+		c.resetTagIDs()
+		if tagIDs, err = c.monitorTagIDs(tags); err != nil {
+			return err
+		}
+		err = c.updateMonitor(monitorID, map[string]interface{}{"tag_ids": tagIDs})
+	}
+	if err != nil {
+		return err
+	}
+
+	slog.Debug("Updated Site24x7 monitor tags", "monitor_id", monitorID, "tags", site24x7TagFields(tags))
+	return nil
+}
+
+// site24x7TagFields returns the ownership tags put on Site24x7 monitors. The domain ID
+// is left out: a tag per domain would pile up on the account and outlive the domain's
+// monitor, and the structured monitor name already carries it.
+func site24x7TagFields(tags model.MonitorTags) map[string]string {
+	fields := tags.Fields()
+	delete(fields, "ddg_domain_id")
+	return fields
+}
+
+// isInvalidTagError reports whether Site24x7 refused a request because of a tag ID
+// that no longer exists
+func isInvalidTagError(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "invalid tag")
+}
+
+// resetTagIDs drops the cached tag IDs, so the next use lists the tags again
+func (c *Site24x7Client) resetTagIDs() {
+	c.tagMutex.Lock()
+	defer c.tagMutex.Unlock()
+	c.tagIDs = nil
+}
+
+// monitorTagIDs returns the Site24x7 tag IDs of the ownership tags, creating the tags
+// that do not exist yet. Monitors only accept tags by ID, so the IDs are cached. The
+// lock is released around requests, so a slow Site24x7 API doesn't stall other monitors.
+func (c *Site24x7Client) monitorTagIDs(tags model.MonitorTags) ([]string, error) {
+	c.tagMutex.Lock()
+	loaded := c.tagIDs != nil
+	c.tagMutex.Unlock()
+
+	if !loaded {
+		existing, err := c.listTags()
+		if err != nil {
+			return nil, err
+		}
+		ids := make(map[string]string, len(existing))
+		for _, tag := range existing {
+			ids[tag.TagName+"="+tag.TagValue] = tag.TagID
+		}
+		c.tagMutex.Lock()
+		if c.tagIDs == nil {
+			c.tagIDs = ids
+		}
+		c.tagMutex.Unlock()
+	}
+
+	fields := site24x7TagFields(tags)
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tagIDs := make([]string, 0, len(names))
+	for _, name := range names {
+		key := name + "=" + fields[name]
+		c.tagMutex.Lock()
+		id, ok := c.tagIDs[key]
+		c.tagMutex.Unlock()
+		if !ok {
+			created, err := c.createTag(site24x7Tag{TagName: name, TagValue: fields[name], TagColor: site24x7TagColor})
+			if err != nil {
+				return nil, err
+			}
+			id = created.TagID
+			c.tagMutex.Lock()
+			// Another request may have created the same tag meanwhile; either ID works
+			if c.tagIDs != nil {
+				c.tagIDs[key] = id
+			}
+			c.tagMutex.Unlock()
+		}
+		tagIDs = append(tagIDs, id)
+	}
+	return tagIDs, nil
+}
+
+// listTags returns every tag on the Site24x7 account
+func (c *Site24x7Client) listTags() ([]site24x7Tag, error) {
+	var tags []site24x7Tag
+	if err := c.tagRequest("GET", nil, &tags); err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	return tags, nil
+}
+
+// createTag adds a tag to the Site24x7 account and returns it with its ID
+func (c *Site24x7Client) createTag(tag site24x7Tag) (site24x7Tag, error) {
+	var created site24x7Tag
+	if err := c.tagRequest("POST", tag, &created); err != nil {
+		return created, fmt.Errorf("failed to create tag %s: %w", tag.TagName, err)
+	}
+	return created, nil
+}
+
+// tagRequest sends a request to the tags endpoint and decodes the response data into out
+func (c *Site24x7Client) tagRequest(method string, payload interface{}, out interface{}) error {
+	token, err := c.getAccessToken()
+	if err != nil {
+		return fmt.Errorf("failed to get access token: %w", err)
+	}
+
+	var reqBody *bytes.Buffer
+	if payload != nil {
+		jsonData, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("error marshaling request: %w", err)
+		}
+		reqBody = bytes.NewBuffer(jsonData)
+	} else {
+		reqBody = &bytes.Buffer{}
+	}
+
+	req, err := http.NewRequest(method, "https://www.site24x7.com/api/tags", reqBody)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json;charset=UTF-8")
+	req.Header.Set("Accept", "application/json; version=2.1")
+	req.Header.Set("Authorization", fmt.Sprintf("Zoho-oauthtoken %s", token))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("API returned non-success status: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Code    int             `json:"code"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("error parsing response: %w", err)
+	}
+	if result.Code != 0 {
+		return fmt.Errorf("Site24x7 API error: %s", result.Message)
+	}
+	if err := json.Unmarshal(result.Data, out); err != nil {
+		return fmt.Errorf("error parsing response data: %w", err)
+	}
+	return nil
+}
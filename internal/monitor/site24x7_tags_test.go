@@ -0,0 +1,33 @@
+package monitor
+
+import (
+	"errors"
+	"testing"
+
+	"domain-detection-go/pkg/model"
+)
+
+func TestSite24x7TagFieldsLeaveOutDomain(t *testing.T) {
+	tags := model.MonitorTags{UserID: 12, DomainID: 345, Environment: "production"}
+	fields := site24x7TagFields(tags)
+	if _, ok := fields["ddg_domain_id"]; ok {
+		t.Errorf("tag fields %v include the domain ID", fields)
+	}
+	if fields["ddg_user_id"] != "12" || fields["ddg_environment"] != "production" {
+		t.Errorf("tag fields = %v, want the user and environment", fields)
+	}
+	// The domain ID stays recoverable from the monitor name
+	parsed, ok := model.ParseMonitorName(tags.MonitorName("example.com"))
+	if !ok || parsed.DomainID != 345 {
+		t.Errorf("ParseMonitorName = %+v, %v, want domain 345", parsed, ok)
+	}
+}
+
+func TestIsInvalidTagError(t *testing.T) {
+	if !isInvalidTagError(errors.New("Site24x7 API error: Invalid tag id")) {
+		t.Error("invalid tag error not recognised")
+	}
+	if isInvalidTagError(errors.New("Site24x7 API error: Invalid location profile")) {
+		t.Error("other API error taken for an invalid tag")
+	}
+}
@@ -0,0 +1,80 @@
+package monitor
+
+import (
+	"sort"
+	"time"
+
+	"domain-detection-go/pkg/model"
+)
+
+// startupStagger holds the slots given to the domains that were overdue when the
+// service started, so they are not all checked in the first run
+type startupStagger struct {
+	planned bool
+	slots   map[int]time.Time // Domain ID to the time its first check may run
+}
+
+// staggerStartup returns the due domains that may be checked now. On the first run
+// after a start, the overdue domains are spread evenly over the shortest of their
+// intervals, most overdue first; a domain is held back until its slot comes. Later runs
+// are unaffected once every slot has passed.
+func (s *MonitorService) staggerStartup(due []model.Domain, now time.Time) []model.Domain {
+	if !s.stagger.planned {
+		s.stagger.planned = true
+		s.planStartupSlots(due, now)
+	}
+	if len(s.stagger.slots) == 0 {
+		return due
+	}
+
+	ready := make([]model.Domain, 0, len(due))
+	held := 0
+	for _, d := range due {
+		if slot, ok := s.stagger.slots[d.ID]; ok && now.Before(slot) {
+			held++
+			continue
+		}
+		ready = append(ready, d)
+	}
+	for id, slot := range s.stagger.slots {
+		if !now.Before(slot) {
+			delete(s.stagger.slots, id)
+		}
+	}
+
+	if held > 0 {
+		s.logger.Debug("Holding back overdue domains after start", "ready", len(ready), "held", held)
+	}
+	return ready
+}
+
+// planStartupSlots gives every overdue domain its slot within the first interval window
+func (s *MonitorService) planStartupSlots(due []model.Domain, now time.Time) {
+	if len(due) <= 1 {
+		return
+	}
+
+	ordered := make([]model.Domain, len(due))
+	copy(ordered, due)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return nextCheckTime(ordered[i]).Before(nextCheckTime(ordered[j]))
+	})
+
+	// The shortest interval bounds the window, so no domain waits longer than one of
+	// its own intervals
+	window := time.Duration(ordered[0].Interval) * time.Minute
+	for _, d := range ordered {
+		if interval := time.Duration(d.Interval) * time.Minute; interval > 0 && interval < window {
+			window = interval
+		}
+	}
+	if window <= 0 {
+		return
+	}
+
+	s.stagger.slots = make(map[int]time.Time, len(ordered))
+	for i, d := range ordered {
+		s.stagger.slots[d.ID] = now.Add(window * time.Duration(i) / time.Duration(len(ordered)))
+	}
+	s.logger.Info("Staggering overdue domains after start", "domains", len(ordered), "window", window.String())
+}
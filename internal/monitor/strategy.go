@@ -0,0 +1,46 @@
+package monitor
+
+import "domain-detection-go/pkg/model"
+
+// providerResult is the check result of one monitoring provider
+type providerResult struct {
+	provider string
+	result   *model.DomainCheckResult
+}
+
+// decideAvailability combines provider results with an availability strategy. It returns
+// the decision, a copy of the most preferred result agreeing with it for the status
+// details, and the providers that disagreed. results must not be empty.
+func decideAvailability(strategy string, results []providerResult) (bool, *model.DomainCheckResult, []string) {
+	up := 0
+	for _, r := range results {
+		if r.result.Available {
+			up++
+		}
+	}
+
+	var available bool
+	switch strategy {
+	case model.AvailabilityAny:
+		available = up > 0
+	case model.AvailabilityMajority:
+		available = up*2 > len(results)
+	default:
+		available = up == len(results)
+	}
+
+	var chosen *model.DomainCheckResult
+	var dissenting []string
+	for _, r := range results {
+		if r.result.Available != available {
+			dissenting = append(dissenting, r.provider)
+			continue
+		}
+		if chosen == nil {
+			chosen = r.result
+		}
+	}
+
+	final := *chosen
+	return available, &final, dissenting
+}
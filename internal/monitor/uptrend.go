@@ -2,32 +2,35 @@ package monitor
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"time"
 
+	"domain-detection-go/internal/outbound"
 	"domain-detection-go/pkg/model"
 )
 
 // UptrendsConfig holds configuration for Uptrends API
 type UptrendsConfig struct {
-	APIKey      string
-	APIUsername string
-	BaseURL     string
-	MaxRetries  int
-	RetryDelay  time.Duration
+	APIKey        string
+	APIUsername   string
+	BaseURL       string
+	MaxRetries    int           // Retries of throttled requests
+	RetryDelay    time.Duration // Wait before retrying a throttled request without Retry-After
+	MaxConcurrent int           // Requests in flight at once
+	CheckpointTTL time.Duration // How long region checkpoint IDs are cached
 }
 
 // UptrendsClient is a client for the Uptrends API
 type UptrendsClient struct {
 	config      UptrendsConfig
-	httpClient  *http.Client
-	rateLimiter *time.Ticker
-	// mu          sync.Mutex
+	httpClient  *outbound.Client
+	checkpoints *checkpointCache
 }
 
 // NewUptrendsClient creates a new client for the Uptrends API
@@ -42,14 +45,21 @@ func NewUptrendsClient(config UptrendsConfig) *UptrendsClient {
 	if config.RetryDelay == 0 {
 		config.RetryDelay = 2 * time.Second
 	}
-
-	// Rate limit to avoid hitting API limits (1 request per second)
-	rateLimiter := time.NewTicker(1 * time.Second)
+	if config.CheckpointTTL == 0 {
+		config.CheckpointTTL = time.Hour
+	}
 
 	client := &UptrendsClient{
-		config:      config,
-		httpClient:  &http.Client{Timeout: 10 * time.Second},
-		rateLimiter: rateLimiter,
+		config: config,
+		httpClient: outbound.NewClient(outbound.Config{
+			Name:          "Uptrends",
+			Timeout:       10 * time.Second,
+			MaxConcurrent: config.MaxConcurrent,
+			MinInterval:   1 * time.Second, // Stay clear of the API rate limit
+			MaxRetries:    config.MaxRetries,
+			RetryDelay:    config.RetryDelay,
+		}),
+		checkpoints: newCheckpointCache(config.CheckpointTTL),
 	}
 
 	// Fetch checkpoint IDs at startup
@@ -69,8 +79,6 @@ func NewUptrendsClient(config UptrendsConfig) *UptrendsClient {
 
 // Updated GetCheckpoints function to parse the correct response format
 func (c *UptrendsClient) GetCheckpoints() (map[string]string, error) {
-	// Wait for rate limiter
-	<-c.rateLimiter.C
 
 	// Fetch checkpoints from API
 	url := fmt.Sprintf("%s/Checkpoint", c.config.BaseURL)
@@ -95,7 +103,7 @@ func (c *UptrendsClient) GetCheckpoints() (map[string]string, error) {
 	if len(bodyPreview) > 1000 {
 		bodyPreview = bodyPreview[:1000] + "... (truncated)"
 	}
-	log.Printf("Checkpoints API response (preview): %s", bodyPreview)
+	slog.Debug("Uptrends checkpoints response", "body", bodyPreview)
 
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("API returned non-200 status when getting checkpoints: %d", resp.StatusCode)
@@ -132,7 +140,7 @@ func (c *UptrendsClient) GetCheckpoints() (map[string]string, error) {
 		name := cp.Attributes.CheckpointName
 		code := cp.Attributes.Code
 
-		log.Printf("Found checkpoint: %s (ID: %s, Code: %s)", name, idStr, code)
+		slog.Debug("Found Uptrends checkpoint", "name", name, "id", idStr, "code", code)
 
 		// Store by name and code
 		checkpointMap[name] = idStr
@@ -143,9 +151,7 @@ func (c *UptrendsClient) GetCheckpoints() (map[string]string, error) {
 }
 
 // CreateMonitor creates a new monitor in Uptrends
-func (c *UptrendsClient) CreateMonitor(fullURL string, name string, regions []string) (string, error) {
-	// Wait for rate limiter
-	<-c.rateLimiter.C
+func (c *UptrendsClient) CreateMonitor(fullURL string, name string, tags model.MonitorTags, regions []string, interval int, settings model.HTTPCheckSettings) (string, error) {
 
 	// Parse the URL to determine protocol
 	parsedURL, err := url.Parse(fullURL)
@@ -173,6 +179,12 @@ func (c *UptrendsClient) CreateMonitor(fullURL string, name string, regions []st
 		uptrendsRegions = append(uptrendsRegions, regionID)
 	}
 
+	// Tag the monitor with its owner so it can be traced back to our records
+	customFields := []map[string]string{}
+	for fieldName, value := range tags.Fields() {
+		customFields = append(customFields, map[string]string{"Name": fieldName, "Value": value})
+	}
+
 	// Create request body
 	requestBody := map[string]interface{}{
 		"MonitorType": monitorType,
@@ -184,6 +196,8 @@ func (c *UptrendsClient) CreateMonitor(fullURL string, name string, regions []st
 		},
 		"UsePrimaryCheckpointsOnly": false,
 		"Name":                      name,
+		"CheckInterval":             uptrendsCheckInterval(interval),
+		"CustomFields":              customFields,
 	}
 
 	jsonData, err := json.Marshal(requestBody)
@@ -191,8 +205,16 @@ func (c *UptrendsClient) CreateMonitor(fullURL string, name string, regions []st
 		return "", fmt.Errorf("error marshalling request: %w", err)
 	}
 
-	// Log the request for debugging
-	log.Printf("Creating monitor with request: %s", string(jsonData))
+	// Log the request for debugging. The HTTP settings are added afterwards since headers
+	// and credentials may hold secrets.
+	slog.Debug("Creating Uptrends monitor", "request", string(jsonData), "method", settings.HTTPMethod,
+		"custom_headers", len(settings.HTTPHeaders), "basic_auth", settings.BasicAuthUsername != "")
+
+	applyUptrendsHTTPSettings(requestBody, settings)
+	jsonData, err = json.Marshal(requestBody)
+	if err != nil {
+		return "", fmt.Errorf("error marshalling request: %w", err)
+	}
 
 	// Build request
 	url := fmt.Sprintf("%s/Monitor", c.config.BaseURL)
@@ -220,7 +242,7 @@ func (c *UptrendsClient) CreateMonitor(fullURL string, name string, regions []st
 	}
 
 	// Log full response for debugging
-	log.Printf("Uptrends API response: %s", string(body))
+	slog.Debug("Uptrends create monitor response", "status", resp.StatusCode, "body", string(body))
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 		return "", fmt.Errorf("API returned non-success status: %d, body: %s", resp.StatusCode, string(body))
@@ -238,19 +260,62 @@ func (c *UptrendsClient) CreateMonitor(fullURL string, name string, regions []st
 	return response.MonitorGuid, nil
 }
 
+// applyUptrendsHTTPSettings adds the HTTP settings of a domain to a monitor request.
+// Uptrends always follows redirects and accepts a single expected status code, so the
+// first one is used.
+func applyUptrendsHTTPSettings(requestBody map[string]interface{}, settings model.HTTPCheckSettings) {
+	requestBody["HttpMethod"] = "Get"
+	if settings.HTTPMethod == model.HTTPMethodPost {
+		requestBody["HttpMethod"] = "Post"
+	}
+
+	if len(settings.HTTPHeaders) > 0 {
+		headers := []map[string]string{}
+		for name, value := range settings.HTTPHeaders {
+			headers = append(headers, map[string]string{"Name": name, "Value": value})
+		}
+		requestBody["RequestHeaders"] = headers
+	}
+
+	if settings.BasicAuthUsername != "" {
+		requestBody["AuthenticationType"] = "Basic"
+		requestBody["Username"] = settings.BasicAuthUsername
+		requestBody["Password"] = settings.BasicAuthPassword
+	}
+
+	if len(settings.ExpectedStatusCodes) > 0 {
+		requestBody["ExpectedHttpStatusCode"] = settings.ExpectedStatusCodes[0]
+		requestBody["ExpectedHttpStatusCodeSpecified"] = true
+	}
+}
+
 // UpdateMonitorStatus updates the IsActive status of a monitor in Uptrends
 func (c *UptrendsClient) UpdateMonitorStatus(monitorGuid string, isActive bool) error {
-	// Wait for rate limiter
-	<-c.rateLimiter.C
+	if err := c.patchMonitor(monitorGuid, map[string]interface{}{"IsActive": isActive}); err != nil {
+		return err
+	}
 
-	// Build request URL
-	requestUrl := fmt.Sprintf("%s/Monitor/%s", c.config.BaseURL, monitorGuid)
+	slog.Info("Updated Uptrends monitor status", "monitor_id", monitorGuid, "active", isActive)
+	return nil
+}
 
-	// Create update request
-	updateRequest := map[string]interface{}{
-		"IsActive": isActive,
+// UpdateMonitorInterval sets how often Uptrends checks a monitor to the domain interval
+func (c *UptrendsClient) UpdateMonitorInterval(monitorGuid string, interval int) error {
+	checkInterval := uptrendsCheckInterval(interval)
+	if err := c.patchMonitor(monitorGuid, map[string]interface{}{"CheckInterval": checkInterval}); err != nil {
+		return err
 	}
 
+	slog.Info("Updated Uptrends monitor interval", "monitor_id", monitorGuid, "check_interval", checkInterval)
+	return nil
+}
+
+// patchMonitor changes the given fields of a monitor
+func (c *UptrendsClient) patchMonitor(monitorGuid string, updateRequest map[string]interface{}) error {
+
+	// Build request URL
+	requestUrl := fmt.Sprintf("%s/Monitor/%s", c.config.BaseURL, monitorGuid)
+
 	jsonData, err := json.Marshal(updateRequest)
 	if err != nil {
 		return fmt.Errorf("error marshaling request: %w", err)
@@ -275,14 +340,10 @@ func (c *UptrendsClient) UpdateMonitorStatus(monitorGuid string, isActive bool)
 		body, _ := ioutil.ReadAll(resp.Body)
 		return fmt.Errorf("API returned non-success status: %d, body: %s", resp.StatusCode, string(body))
 	}
-
-	log.Printf("Successfully updated Uptrends monitor %s status to active=%v", monitorGuid, isActive)
 	return nil
 }
 
 func (c *UptrendsClient) DeleteMonitor(monitorGuid string) error {
-	// Wait for rate limiter
-	<-c.rateLimiter.C
 
 	// Build request URL
 	requestUrl := fmt.Sprintf("%s/Monitor/%s", c.config.BaseURL, monitorGuid)
@@ -306,14 +367,52 @@ func (c *UptrendsClient) DeleteMonitor(monitorGuid string) error {
 		return fmt.Errorf("API returned non-success status: %d, body: %s", resp.StatusCode, string(body))
 	}
 
-	log.Printf("Successfully deleted Uptrends monitor %s", monitorGuid)
+	slog.Info("Deleted Uptrends monitor", "monitor_id", monitorGuid)
 	return nil
 }
 
-// getCheckpointIdsForRegion gets all checkpoint IDs for a specific region
-func (c *UptrendsClient) getCheckpointIdsForRegion(regionCode string) ([]int, error) {
-	// Wait for rate limiter
-	<-c.rateLimiter.C
+// ListMonitors returns every monitor on the Uptrends account
+func (c *UptrendsClient) ListMonitors() ([]model.ProviderMonitor, error) {
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/Monitor", c.config.BaseURL), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.SetBasicAuth(c.config.APIUsername, c.config.APIKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned non-success status: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var result []struct {
+		MonitorGuid string `json:"MonitorGuid"`
+		Name        string `json:"Name"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("error parsing response: %w", err)
+	}
+
+	monitors := make([]model.ProviderMonitor, 0, len(result))
+	for _, m := range result {
+		monitors = append(monitors, model.ProviderMonitor{ID: m.MonitorGuid, Name: m.Name})
+	}
+	return monitors, nil
+}
+
+// fetchCheckpointIdsForRegion gets all checkpoint IDs for a specific region from the API
+func (c *UptrendsClient) fetchCheckpointIdsForRegion(regionCode string) ([]int, error) {
 
 	// Get the Uptrends region ID
 	regionID := getUptrendsRegionID(regionCode)
@@ -322,7 +421,7 @@ func (c *UptrendsClient) getCheckpointIdsForRegion(regionCode string) ([]int, er
 	requestUrl := fmt.Sprintf("%s/CheckpointRegion/%d/Checkpoint", c.config.BaseURL, regionID)
 
 	// Log the request for debugging
-	log.Printf("Getting checkpoints for region %s (ID: %d): %s", regionCode, regionID, requestUrl)
+	slog.Debug("Getting Uptrends checkpoints", "region", regionCode, "region_id", regionID, "url", requestUrl)
 
 	// Create request
 	req, err := http.NewRequest("GET", requestUrl, nil)
@@ -371,21 +470,19 @@ func (c *UptrendsClient) getCheckpointIdsForRegion(regionCode string) ([]int, er
 	var checkpointIds []int
 	for _, cp := range checkpoints {
 		checkpointIds = append(checkpointIds, cp.CheckpointId)
-		log.Printf("Found checkpoint %s (ID: %d) for region %s", cp.CheckpointName, cp.CheckpointId, regionCode)
+		slog.Debug("Found Uptrends checkpoint", "name", cp.CheckpointName, "id", cp.CheckpointId, "region", regionCode)
 	}
 
 	return checkpointIds, nil
 }
 
 // GetLatestMonitorCheck gets the latest check result for a monitor
-func (c *UptrendsClient) GetLatestMonitorCheck(monitorGuid, regionCode string) (*model.DomainCheckResult, error) {
-	// Wait for rate limiter
-	<-c.rateLimiter.C
+func (c *UptrendsClient) GetLatestMonitorCheck(ctx context.Context, monitorGuid, regionCode string) (*model.DomainCheckResult, error) {
 
 	// Get checkpoint IDs for the specified region
 	checkpointIds, err := c.getCheckpointIdsForRegion(regionCode)
 	if err != nil {
-		log.Printf("Error getting checkpoint IDs for region %s: %v", regionCode, err)
+		slog.Error("Failed to get Uptrends checkpoint IDs", "region", regionCode, "error", err)
 		// Continue with the check, but we won't be able to filter by region
 	}
 
@@ -399,9 +496,9 @@ func (c *UptrendsClient) GetLatestMonitorCheck(monitorGuid, regionCode string) (
 	requestUrl := fmt.Sprintf("%s?%s", baseUrl, query.Encode())
 
 	// Log the request for debugging
-	log.Printf("Getting latest 10 checks for monitor %s in region %s: %s", monitorGuid, regionCode, requestUrl)
+	slog.Debug("Getting latest Uptrends checks", "monitor_id", monitorGuid, "region", regionCode, "url", requestUrl)
 
-	req, err := http.NewRequest("GET", requestUrl, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", requestUrl, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
@@ -433,7 +530,7 @@ func (c *UptrendsClient) GetLatestMonitorCheck(monitorGuid, regionCode string) (
 	}
 
 	if err := json.Unmarshal(body, &checkResponse); err != nil {
-		log.Printf("Error parsing check response: %v", err)
+		slog.Error("Failed to parse Uptrends check response", "error", err)
 		return nil, fmt.Errorf("error parsing response: %w", err)
 	}
 
@@ -461,20 +558,17 @@ func (c *UptrendsClient) GetLatestMonitorCheck(monitorGuid, regionCode string) (
 
 			// Check if this is from our target region
 			if checkpointIdMap[int(checkpointId)] {
-				log.Printf("Including check from ServerId %d (CheckpointId %d) for monitor %s",
-					serverId, checkpointId, monitorGuid)
+				slog.Debug("Including Uptrends check", "server_id", serverId, "checkpoint_id", checkpointId, "monitor_id", monitorGuid)
 				filteredChecks = append(filteredChecks, check)
 				break // We only need the first match
 			} else {
-				log.Printf("Filtering out check from ServerId %d (CheckpointId %d) - not in region %s",
-					serverId, checkpointId, regionCode)
+				slog.Debug("Filtering out Uptrends check outside region", "server_id", serverId, "checkpoint_id", checkpointId, "region", regionCode)
 			}
 		}
 	} else {
 		// If we couldn't get checkpoint IDs, use all checks
 		filteredChecks = checkResponse.Data
-		log.Printf("No checkpoint IDs found for region %s, using all %d checks",
-			regionCode, len(filteredChecks))
+		slog.Warn("No Uptrends checkpoint IDs found for region, using every check", "region", regionCode, "checks", len(filteredChecks))
 	}
 
 	// If we have no valid checks after filtering, return error
@@ -508,7 +602,7 @@ func (c *UptrendsClient) GetLatestMonitorCheck(monitorGuid, regionCode string) (
 
 	if err != nil {
 		// If we couldn't parse the time, use current time as fallback
-		log.Printf("Could not parse timestamp '%s': %v. Using current time.", check.Timestamp, err)
+		slog.Warn("Could not parse Uptrends timestamp, using current time", "timestamp", check.Timestamp, "error", err)
 		checkedAt = time.Now()
 	}
 
@@ -551,5 +645,45 @@ func getUptrendsRegionID(region string) int {
 
 // Close cleans up resources used by the client
 func (c *UptrendsClient) Close() {
-	c.rateLimiter.Stop()
+	// No persistent connections to close for Uptrends
+}
+
+// SetUsageRecorder reports every request sent to the Uptrends API to the recorder
+func (c *UptrendsClient) SetUsageRecorder(recorder outbound.Recorder) {
+	c.httpClient.SetRecorder(recorder)
+}
+
+// Stats returns the request counters of the Uptrends API client
+func (c *UptrendsClient) Stats() model.ProviderClientStats {
+	return c.httpClient.Stats()
+}
+
+// Configured reports whether Uptrends API credentials are set
+func (c *UptrendsClient) Configured() bool {
+	return c.config.APIUsername != "" && c.config.APIKey != ""
+}
+
+// Ping verifies the API credentials by fetching the account details
+func (c *UptrendsClient) Ping() error {
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/Account", c.config.BaseURL), nil)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.SetBasicAuth(c.config.APIUsername, c.config.APIKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error connecting to Uptrends: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("invalid Uptrends credentials")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API returned status %d", resp.StatusCode)
+	}
+	return nil
 }
@@ -0,0 +1,80 @@
+// Package netguard keeps requests to user-supplied hosts off the application's own
+// network. Domain names are user input and may resolve to the app servers, their private
+// network or the cloud metadata endpoint.
+package netguard
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrInternalAddress is returned when a connection to an address of the application's
+// own network is refused
+var ErrInternalAddress = errors.New("address is not publicly routable")
+
+// internalNetworks are the ranges connections to user-supplied hosts must never reach
+var internalNetworks = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // unspecified / "this network"
+	netip.MustParsePrefix("10.0.0.0/8"),     // private
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT
+	netip.MustParsePrefix("127.0.0.0/8"),    // loopback
+	netip.MustParsePrefix("169.254.0.0/16"), // link-local, incl. 169.254.169.254
+	netip.MustParsePrefix("172.16.0.0/12"),  // private
+	netip.MustParsePrefix("192.168.0.0/16"), // private
+	netip.MustParsePrefix("::/128"),         // unspecified
+	netip.MustParsePrefix("::1/128"),        // loopback
+	netip.MustParsePrefix("fc00::/7"),       // unique local
+	netip.MustParsePrefix("fe80::/10"),      // link-local
+}
+
+// CheckPublicAddress returns ErrInternalAddress if ip is in one of the internal
+// networks. IPv4-mapped IPv6 addresses are checked as IPv4.
+func CheckPublicAddress(ip netip.Addr) error {
+	ip = ip.Unmap()
+	if ip.IsMulticast() {
+		return fmt.Errorf("%s: %w", ip, ErrInternalAddress)
+	}
+	for _, network := range internalNetworks {
+		if network.Contains(ip) {
+			return fmt.Errorf("%s: %w", ip, ErrInternalAddress)
+		}
+	}
+	return nil
+}
+
+// refuseInternalAddresses is a net.Dialer Control hook. It runs after name resolution
+// for every address tried, so names resolving to internal addresses and redirects to
+// them are refused alike.
+func refuseInternalAddresses(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("unexpected dial address %q: %w", address, err)
+	}
+	return CheckPublicAddress(addrPort.Addr())
+}
+
+// Dialer returns a dialer that only connects to publicly routable addresses
+func Dialer(timeout time.Duration) *net.Dialer {
+	return &net.Dialer{
+		Timeout: timeout,
+		Control: refuseInternalAddresses,
+	}
+}
+
+// Transport returns an HTTP transport that only connects to publicly routable
+// addresses. It uses no proxy: one would connect on the caller's behalf, past the
+// dialer's address check. Timeouts are left to the http.Client.
+func Transport() *http.Transport {
+	return &http.Transport{
+		DialContext:         Dialer(0).DialContext,
+		ForceAttemptHTTP2:   true,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+}
@@ -0,0 +1,55 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"testing"
+)
+
+func TestCheckPublicAddress(t *testing.T) {
+	tests := []struct {
+		ip       string
+		internal bool
+	}{
+		{"127.0.0.1", true},
+		{"10.1.2.3", true},
+		{"172.20.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"100.64.0.1", true},
+		{"0.0.0.0", true},
+		{"::1", true},
+		{"::", true},
+		{"fd00::1", true},
+		{"fe80::1", true},
+		{"::ffff:127.0.0.1", true},
+		{"93.184.216.34", false},
+		{"2606:2800:220:1::", false},
+	}
+
+	for _, tt := range tests {
+		err := CheckPublicAddress(netip.MustParseAddr(tt.ip))
+		if (err != nil) != tt.internal {
+			t.Errorf("CheckPublicAddress(%s) = %v, want internal %v", tt.ip, err, tt.internal)
+		}
+	}
+}
+
+func TestDialerRefusesInternalAddresses(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	conn, err := Dialer(0).DialContext(context.Background(), "tcp", listener.Addr().String())
+	if err == nil {
+		conn.Close()
+		t.Fatal("dial to loopback succeeded")
+	}
+	if !errors.Is(err, ErrInternalAddress) {
+		t.Errorf("dial error = %v, want %v", err, ErrInternalAddress)
+	}
+}
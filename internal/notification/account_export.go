@@ -0,0 +1,34 @@
+package notification
+
+import (
+	"html"
+	"time"
+)
+
+// SendAccountExportLink mails a user the link to download the archive of their data.
+// It implements export.AccountExportMailer.
+func (s *EmailService) SendAccountExportLink(to, link string, expiresAt time.Time) error {
+	subject := "📦 Your Domain Monitor data export is ready"
+	body := `
+	<!DOCTYPE html>
+	<html>
+	<head>
+		<meta charset="UTF-8">
+		<title>Your data export is ready</title>
+	</head>
+	<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+		<div style="max-width: 600px; margin: 0 auto; padding: 20px;">
+			<h2 style="color: #3498db;">📦 Your data export is ready</h2>
+			<p>The archive of your account data you requested from your Domain Monitoring Service has been created.
+			It contains your profile, domains, check history, notification configurations and notification history.</p>
+			<div style="background-color: #f8f9fa; padding: 15px; border-radius: 5px; margin: 20px 0; text-align: center;">
+				<p><a href="` + html.EscapeString(link) + `" style="color: #3498db; font-weight: bold;">Download your data</a></p>
+			</div>
+			<p>The link works until ` + html.EscapeString(expiresAt.UTC().Format("2006-01-02 15:04 UTC")) + `. Anyone with the link can download the archive, so do not forward this email.
+			If you did not request an export, change your password.</p>
+		</div>
+	</body>
+	</html>`
+
+	return s.sendEmail(to, subject, body)
+}
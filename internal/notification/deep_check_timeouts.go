@@ -0,0 +1,59 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"time"
+
+	"domain-detection-go/internal/service"
+	"domain-detection-go/pkg/model"
+)
+
+// RunScheduledDeepCheckTimeouts periodically fails deep check orders whose callback
+// did not arrive within timeoutMinutes and tells their user the check did not complete
+func RunScheduledDeepCheckTimeouts(ctx context.Context, deepChecks *service.DeepCheckService, notifiers *Fanout,
+	telegram *TelegramService, timeoutMinutes int) {
+	slog.Info("RunScheduledDeepCheckTimeouts")
+	ticker := time.NewTicker(5 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("RunScheduledDeepCheckTimeouts stopped")
+			return
+		case <-ticker.C:
+			orders, err := deepChecks.ExpirePendingOrders(timeoutMinutes)
+			if err != nil {
+				slog.Error("Deep check timeout run failed", "error", err)
+				continue
+			}
+			for _, order := range orders {
+				notifyDeepCheckTimeout(order, notifiers, telegram)
+			}
+		}
+	}
+}
+
+// notifyDeepCheckTimeout reports an expired order where its results would have gone
+func notifyDeepCheckTimeout(order model.DeepCheckOrder, notifiers *Fanout, telegram *TelegramService) {
+	text := fmt.Sprintf("⚠️ The deep check of %s (order %s) did not complete. No results were received from the checking nodes; please request a new deep check.",
+		order.DomainName, order.OrderID)
+	render := StaticMessage(CustomMessage{
+		Subject: fmt.Sprintf("Deep check of %s did not complete", order.DomainName),
+		HTML:    fmt.Sprintf("<html><body><p>%s</p></body></html>", html.EscapeString(text)),
+		Text:    []string{text},
+	})
+
+	var err error
+	if order.ReplyChatID != nil {
+		err = telegram.SendCustomToChat(order.UserID, *order.ReplyChatID, render)
+	} else {
+		err = notifiers.SendCustom(order.UserID, render)
+	}
+	if err != nil {
+		slog.Error("Failed to notify user of expired deep check order", "user_id", order.UserID, "order_id", order.OrderID, "error", err)
+	}
+}
@@ -0,0 +1,473 @@
+package notification
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"domain-detection-go/pkg/model"
+
+	"github.com/lib/pq"
+)
+
+// Defaults for digest settings not given in a request
+const (
+	defaultDigestIntervalMinutes = 60
+	defaultDigestTrigger         = model.DigestTriggerInterval
+)
+
+// digestScheduleMinutes maps digest schedules to their delivery interval
+var digestScheduleMinutes = map[string]int{
+	model.DigestScheduleHourly: 60,
+	model.DigestScheduleDaily:  24 * 60,
+}
+
+// digestSlowestDomains is how many of the slowest domains a digest lists
+const digestSlowestDomains = 5
+
+// digestState is the stored digest settings and delivery state of one config
+type digestState struct {
+	model.DigestSettings
+	UserID          int            `db:"user_id"`
+	LastDownDomains pq.StringArray `db:"last_down_domains"`
+}
+
+// digestConfigIDs returns the configs of a channel that have digest-only delivery enabled
+func (d *Dispatcher) digestConfigIDs(channel string, userID int) (map[int]bool, error) {
+	var ids []int
+	err := d.db.Select(&ids, `
+        SELECT config_id FROM notification_digests
+        WHERE channel = $1 AND user_id = $2 AND enabled = true
+    `, channel, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		enabled[id] = true
+	}
+	return enabled, nil
+}
+
+// queueDigestEvent holds back a notification for a digest config
+func (d *Dispatcher) queueDigestEvent(channel string, configID int, domain model.Domain, notificationType string) error {
+	_, err := d.db.Exec(`
+        INSERT INTO notification_digest_events
+        (channel, config_id, domain_id, domain_name, region, notification_type, status_code, error_description, occurred_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+    `, channel, configID, domain.ID, domain.DisplayName(), domain.Region, notificationType, domain.LastStatus, domain.ErrorDescription)
+	if err != nil {
+		return fmt.Errorf("failed to queue digest event: %w", err)
+	}
+	return nil
+}
+
+// GetDigestSettings returns the digest settings of a config, with defaults if none are stored
+func (d *Dispatcher) GetDigestSettings(n Notifier, configID, userID int) (*model.DigestSettings, error) {
+	if err := checkConfigOwner(n, configID, userID); err != nil {
+		return nil, err
+	}
+
+	settings := model.DigestSettings{
+		Channel:         n.Channel(),
+		ConfigID:        configID,
+		IntervalMinutes: defaultDigestIntervalMinutes,
+		Trigger:         defaultDigestTrigger,
+	}
+	err := d.db.Get(&settings, `
+        SELECT channel, config_id, enabled, interval_minutes, delivery_trigger, last_sent_at
+        FROM notification_digests
+        WHERE channel = $1 AND config_id = $2
+    `, n.Channel(), configID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get digest settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// UpdateDigestSettings enables or changes digest-only delivery for a config
+func (d *Dispatcher) UpdateDigestSettings(n Notifier, configID, userID int, req model.DigestSettingsRequest) error {
+	if err := checkConfigOwner(n, configID, userID); err != nil {
+		return err
+	}
+
+	if req.IntervalMinutes == 0 {
+		req.IntervalMinutes = digestScheduleMinutes[req.Schedule]
+	}
+	if req.IntervalMinutes == 0 {
+		req.IntervalMinutes = defaultDigestIntervalMinutes
+	}
+	if req.Trigger == "" {
+		req.Trigger = defaultDigestTrigger
+	}
+
+	_, err := d.db.Exec(`
+        INSERT INTO notification_digests (channel, config_id, user_id, enabled, interval_minutes, delivery_trigger, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, NOW())
+        ON CONFLICT (channel, config_id)
+        DO UPDATE SET enabled = $4, interval_minutes = $5, delivery_trigger = $6, updated_at = NOW()
+    `, n.Channel(), configID, userID, req.Enabled, req.IntervalMinutes, req.Trigger)
+	if err != nil {
+		return fmt.Errorf("failed to save digest settings: %w", err)
+	}
+
+	// Events queued while digests were on would otherwise never be delivered
+	if !req.Enabled {
+		if _, err := d.db.Exec("DELETE FROM notification_digest_events WHERE channel = $1 AND config_id = $2", n.Channel(), configID); err != nil {
+			slog.Error("Failed to clear digest events", "channel", n.Channel(), "config_id", configID, "error", err)
+		}
+	}
+	return nil
+}
+
+// checkConfigOwner verifies the config belongs to the user
+func checkConfigOwner(n Notifier, configID, userID int) error {
+	recipients, err := n.GetRecipients(userID)
+	if err != nil {
+		return err
+	}
+	for _, r := range recipients {
+		if r.ConfigID == configID {
+			return nil
+		}
+	}
+	return ErrConfigNotFound
+}
+
+// FlushDigests delivers every digest of a channel that is due
+func (d *Dispatcher) FlushDigests(n Notifier) error {
+	channel := n.Channel()
+
+	var states []digestState
+	err := d.db.Select(&states, `
+        SELECT channel, config_id, user_id, enabled, interval_minutes, delivery_trigger, last_sent_at, last_down_domains
+        FROM notification_digests
+        WHERE channel = $1 AND enabled = true
+    `, channel)
+	if err != nil {
+		return fmt.Errorf("failed to get %s digest configs: %w", channel, err)
+	}
+
+	quiet, err := d.channelQuietHours(channel)
+	if err != nil {
+		slog.Error("Failed to get quiet hours", "channel", channel, "error", err)
+	}
+	now := time.Now()
+
+	for _, state := range states {
+		// Digests wait for the end of the config's quiet hours
+		if q, ok := quiet[state.ConfigID]; ok && q.Active(now) {
+			continue
+		}
+		if err := d.flushDigest(n, state); err != nil {
+			slog.Error("Failed to flush digest", "channel", channel, "config_id", state.ConfigID, "error", err)
+		}
+	}
+
+	// Configs without a digest deliver what quiet hours or the rate limit held back as one
+	// summary, once quiet hours are over or the rate limit overflow has been collected
+	pending, err := d.pendingSummaries(n)
+	if err != nil {
+		return err
+	}
+	for _, row := range pending {
+		if q, ok := quiet[row.ConfigID]; ok && q.Active(now) {
+			continue
+		}
+		if !row.QuietHours && now.Sub(row.OldestEvent) < overflowSummaryDelay {
+			continue
+		}
+		state := digestState{
+			DigestSettings: model.DigestSettings{
+				Channel:  channel,
+				ConfigID: row.ConfigID,
+				Enabled:  true,
+				Trigger:  model.DigestTriggerInterval,
+			},
+			UserID: row.UserID,
+		}
+		if err := d.flushDigest(n, state); err != nil {
+			slog.Error("Failed to flush summary", "channel", channel, "config_id", row.ConfigID, "error", err)
+		}
+	}
+	return nil
+}
+
+// flushDigest builds and sends the digest of one config if it is due
+func (d *Dispatcher) flushDigest(n Notifier, state digestState) error {
+	channel := n.Channel()
+	now := time.Now()
+
+	var events []model.DigestEvent
+	err := d.db.Select(&events, `
+        SELECT id, domain_id, domain_name, region, notification_type, status_code, error_description, occurred_at
+        FROM notification_digest_events
+        WHERE channel = $1 AND config_id = $2
+        ORDER BY occurred_at, id
+    `, channel, state.ConfigID)
+	if err != nil {
+		return fmt.Errorf("failed to get digest events: %w", err)
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	digest := buildDigest(events, state.LastDownDomains)
+	downSetChanged := len(digest.WentDown) > 0 || len(digest.Recovered) > 0
+
+	switch state.Trigger {
+	case model.DigestTriggerDownSetChange:
+		if !downSetChanged {
+			// Nothing worth reporting; fold the events into the state without sending
+			return d.completeDigest(channel, state, events, digest.DownDomains, false)
+		}
+	default:
+		if state.LastSentAt != nil && now.Sub(*state.LastSentAt) < time.Duration(state.IntervalMinutes)*time.Minute {
+			return nil
+		}
+	}
+
+	recipient, err := findRecipient(n, state.UserID, state.ConfigID)
+	if err != nil {
+		return err
+	}
+	if recipient == nil {
+		// Config was deleted; drop its digest state
+		_, err := d.db.Exec("DELETE FROM notification_digests WHERE channel = $1 AND config_id = $2", channel, state.ConfigID)
+		if err == nil {
+			_, err = d.db.Exec("DELETE FROM notification_digest_events WHERE channel = $1 AND config_id = $2", channel, state.ConfigID)
+		}
+		return err
+	}
+	if !recipient.IsActive {
+		return d.completeDigest(channel, state, events, digest.DownDomains, false)
+	}
+	if recipient.Language == "" {
+		recipient.Language = "en"
+	}
+
+	digest.PeriodStart = events[0].OccurredAt
+	if state.LastSentAt != nil {
+		digest.PeriodStart = *state.LastSentAt
+	}
+	digest.PeriodEnd = now
+
+	if err := d.addDigestStats(&digest, state.UserID, *recipient); err != nil {
+		slog.Error("Failed to add stats to digest", "channel", channel, "config_id", state.ConfigID, "error", err)
+	}
+
+	if err := n.SendDigest(*recipient, digest); err != nil {
+		return fmt.Errorf("failed to send digest to %s: %w", recipient.Label, err)
+	}
+
+	// Record one history row per domain covered so per-domain counts stay accurate
+	recorded := make(map[int]bool)
+	for _, e := range events {
+		if recorded[e.DomainID] {
+			continue
+		}
+		recorded[e.DomainID] = true
+		_, err := d.db.Exec(fmt.Sprintf(`
+            INSERT INTO notification_history
+            (domain_id, %s, status_code, error_code, error_description, notified_at, notification_type)
+            VALUES ($1, $2, COALESCE($3, 0), 0, $4, NOW(), 'digest')
+        `, n.HistoryColumn()), e.DomainID, state.ConfigID, e.StatusCode, e.ErrorDescription)
+		if err != nil {
+			slog.Error("Failed to record digest history", "channel", channel, "error", err)
+		}
+	}
+
+	return d.completeDigest(channel, state, events, digest.DownDomains, true)
+}
+
+// completeDigest removes the processed events and stores the new down set
+func (d *Dispatcher) completeDigest(channel string, state digestState, events []model.DigestEvent, downDomains []string, sent bool) error {
+	lastID := events[len(events)-1].ID
+	_, err := d.db.Exec(`
+        DELETE FROM notification_digest_events
+        WHERE channel = $1 AND config_id = $2 AND id <= $3
+    `, channel, state.ConfigID, lastID)
+	if err != nil {
+		return fmt.Errorf("failed to clear digest events: %w", err)
+	}
+
+	query := "UPDATE notification_digests SET last_down_domains = $1 WHERE channel = $2 AND config_id = $3"
+	if sent {
+		query = "UPDATE notification_digests SET last_down_domains = $1, last_sent_at = NOW() WHERE channel = $2 AND config_id = $3"
+	}
+	if _, err := d.db.Exec(query, pq.Array(downDomains), channel, state.ConfigID); err != nil {
+		return fmt.Errorf("failed to update digest state: %w", err)
+	}
+	return nil
+}
+
+// addDigestStats adds incident counts and the slowest domains of the period. Only
+// domains the recipient would be alerted about, by region and tags, are counted.
+func (d *Dispatcher) addDigestStats(digest *model.Digest, userID int, recipient Recipient) error {
+	const domainFilter = `
+            d.user_id = $1
+            AND (cardinality($4::text[]) = 0 OR d.region = ANY($4))
+            AND (cardinality($5::text[]) = 0 OR EXISTS (
+                SELECT 1 FROM domain_tags t WHERE t.domain_id = d.id AND t.tag = ANY($5)))`
+	args := []interface{}{userID, digest.PeriodStart, digest.PeriodEnd, pq.Array(recipient.MonitorRegions), pq.Array(recipient.Tags)}
+
+	var counts struct {
+		Opened   int `db:"opened"`
+		Resolved int `db:"resolved"`
+	}
+	err := d.db.Get(&counts, `
+        SELECT COUNT(*) FILTER (WHERE i.opened_at >= $2 AND i.opened_at < $3) AS opened,
+               COUNT(*) FILTER (WHERE i.resolved_at >= $2 AND i.resolved_at < $3) AS resolved
+        FROM incidents i
+        JOIN domains d ON d.id = i.domain_id
+        WHERE `+domainFilter, args...)
+	if err != nil {
+		return fmt.Errorf("failed to count incidents: %w", err)
+	}
+	digest.IncidentsOpened = counts.Opened
+	digest.IncidentsResolved = counts.Resolved
+
+	digest.SlowestDomains = []model.DigestDomainTiming{}
+	err = d.db.Select(&digest.SlowestDomains, fmt.Sprintf(`
+        SELECT d.name, AVG(h.total_time) AS avg_response_time
+        FROM domain_check_history h
+        JOIN domains d ON d.id = h.domain_id
+        WHERE h.checked_at >= $2 AND h.checked_at < $3 AND h.available AND h.total_time > 0
+            AND d.archived_at IS NULL AND d.deleted_at IS NULL AND %s
+        GROUP BY d.id, d.name
+        ORDER BY avg_response_time DESC
+        LIMIT %d
+    `, domainFilter, digestSlowestDomains), args...)
+	if err != nil {
+		return fmt.Errorf("failed to get slowest domains: %w", err)
+	}
+	return nil
+}
+
+// findRecipient returns the recipient for a config, or nil if it no longer exists
+func findRecipient(n Notifier, userID, configID int) (*Recipient, error) {
+	recipients, err := n.GetRecipients(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range recipients {
+		if r.ConfigID == configID {
+			return &r, nil
+		}
+	}
+	return nil, nil
+}
+
+// buildDigest applies events in order to the previous down set
+func buildDigest(events []model.DigestEvent, previousDown []string) model.Digest {
+	wasDown := make(map[string]bool, len(previousDown))
+	down := make(map[string]bool, len(previousDown))
+	for _, name := range previousDown {
+		wasDown[name] = true
+		down[name] = true
+	}
+
+	for _, e := range events {
+		key := digestDomainKey(e)
+		switch e.NotificationType {
+		case "down", NotificationTypeBlocked:
+			down[key] = true
+		case "up":
+			delete(down, key)
+		}
+	}
+
+	digest := model.Digest{
+		Events:      events,
+		DownDomains: []string{},
+		WentDown:    []string{},
+		Recovered:   []string{},
+	}
+	for name := range down {
+		digest.DownDomains = append(digest.DownDomains, name)
+		if !wasDown[name] {
+			digest.WentDown = append(digest.WentDown, name)
+		}
+	}
+	for name := range wasDown {
+		if !down[name] {
+			digest.Recovered = append(digest.Recovered, name)
+		}
+	}
+	sort.Strings(digest.DownDomains)
+	sort.Strings(digest.WentDown)
+	sort.Strings(digest.Recovered)
+	return digest
+}
+
+// digestDomainKey identifies a domain in a digest, including its region
+func digestDomainKey(e model.DigestEvent) string {
+	if e.Region != nil && *e.Region != "" {
+		return fmt.Sprintf("%s (%s)", e.DomainName, *e.Region)
+	}
+	return e.DomainName
+}
+
+// formatDigestText renders a digest as plain text shared by all channels
+func formatDigestText(digest model.Digest, timezone string) string {
+	loc := loadLocation(timezone)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📋 Domain monitoring digest\n%s - %s\n",
+		digest.PeriodStart.In(loc).Format("2006-01-02 15:04"), formatLocalTime(digest.PeriodEnd, timezone, "2006-01-02 15:04"))
+
+	writeList := func(title string, names []string) {
+		if len(names) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n%s (%d):\n", title, len(names))
+		for _, name := range names {
+			fmt.Fprintf(&b, "• %s\n", name)
+		}
+	}
+	writeList("🔴 Went down", digest.WentDown)
+	writeList("🟢 Recovered", digest.Recovered)
+	writeList("⚠️ Currently down", digest.DownDomains)
+
+	if len(digest.DownDomains) == 0 {
+		b.WriteString("\n✅ All domains are up\n")
+	}
+
+	if digest.IncidentsOpened > 0 || digest.IncidentsResolved > 0 {
+		fmt.Fprintf(&b, "\n📈 Incidents: %d opened, %d resolved\n", digest.IncidentsOpened, digest.IncidentsResolved)
+	}
+	if len(digest.SlowestDomains) > 0 {
+		b.WriteString("\n🐢 Slowest domains:\n")
+		for _, t := range digest.SlowestDomains {
+			fmt.Fprintf(&b, "• %s: %.0fms\n", t.Name, t.AvgResponseTime)
+		}
+	}
+	fmt.Fprintf(&b, "\n%d status events in this period", len(digest.Events))
+	return b.String()
+}
+
+// RunScheduledDigests delivers due digests for the given channels once a minute
+func RunScheduledDigests(ctx context.Context, flushers ...interface{ FlushDigests() error }) {
+	slog.Info("RunScheduledDigests")
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("RunScheduledDigests stopped")
+			return
+		case <-ticker.C:
+			for _, f := range flushers {
+				if err := f.FlushDigests(); err != nil {
+					slog.Error("Digest flush failed", "error", err)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package notification
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/lib/pq"
+)
+
+// channelEnabled reports whether the domain alerts through a channel. Domains without a
+// channel choice alert through every channel.
+func (d *Dispatcher) channelEnabled(domainID int, channel string) (bool, error) {
+	var channels pq.StringArray
+	err := d.db.Get(&channels, "SELECT notification_channels FROM domains WHERE id = $1", domainID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get domain notification channels: %w", err)
+	}
+	return channels == nil || slices.Contains(channels, channel), nil
+}
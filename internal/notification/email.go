@@ -2,15 +2,14 @@ package notification
 
 import (
 	"bytes"
-	"encoding/json"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"html/template"
-	"log"
-	"net/http"
-	"net/smtp"
-	"net/url"
+	"log/slog"
 	"strings"
-	"sync"
 	"time"
 
 	"domain-detection-go/internal/service"
@@ -21,33 +20,62 @@ import (
 
 // EmailConfig holds the configuration for email service
 type EmailConfig struct {
-	SMTPHost     string
-	SMTPPort     string
-	SMTPUsername string
-	SMTPPassword string
-	FromEmail    string
-	FromName     string
+	Provider      string // EmailProviderSMTP, EmailProviderSendGrid or EmailProviderSES; empty uses SMTP
+	SMTPHost      string
+	SMTPPort      string
+	SMTPUsername  string
+	SMTPPassword  string
+	SMTPPoolSize  int  // Idle SMTP sessions kept for reuse
+	SMTPPlaintext bool // Send without STARTTLS when the server does not offer it
+	FromEmail     string
+	FromName      string
+
+	SendGridAPIKey string
+
+	SESRegion          string
+	SESAccessKeyID     string
+	SESSecretAccessKey string
 }
 
 // EmailService manages email notifications
 type EmailService struct {
 	config        EmailConfig
+	sender        EmailSender
 	db            *sqlx.DB
 	promptService *service.TelegramPromptService
-	notifyLock    sync.Mutex
-	notifyCache   map[string]time.Time
+	dispatcher    *Dispatcher
+	logger        *slog.Logger
 }
 
 // NewEmailService creates a new email service
-func NewEmailService(config EmailConfig, db *sqlx.DB, promptService *service.TelegramPromptService) *EmailService {
+func NewEmailService(config EmailConfig, db *sqlx.DB, promptService *service.TelegramPromptService, logger *slog.Logger) *EmailService {
 	return &EmailService{
 		config:        config,
+		sender:        newEmailSender(config),
 		db:            db,
 		promptService: promptService,
-		notifyCache:   make(map[string]time.Time),
+		dispatcher:    NewDispatcher(db, logger),
+		logger:        logger.With("component", "email"),
 	}
 }
 
+// Close releases the mail provider connections kept between messages
+func (s *EmailService) Close() {
+	if err := s.sender.Close(); err != nil {
+		s.logger.Error("Failed to close email sender", "error", err)
+	}
+}
+
+// SetSuppressionStore replaces where the channel keeps its last notification times
+func (s *EmailService) SetSuppressionStore(store SuppressionStore) {
+	s.dispatcher.SetSuppressionStore(store)
+}
+
+// SetRateLimit caps the notifications of the channel per user and hour
+func (s *EmailService) SetRateLimit(perHour int) {
+	s.dispatcher.SetRateLimit(perHour)
+}
+
 // AddEmailConfig adds a new email notification configuration
 func (s *EmailService) AddEmailConfig(
 	userID int,
@@ -119,6 +147,12 @@ func (s *EmailService) AddEmailConfig(
 		return 0, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	// The config stays pending until the address confirms it. A failed mail can be
+	// sent again through ResendVerification.
+	if sendErr := s.sendVerification(configID); sendErr != nil {
+		s.logger.Error("Failed to send email verification", "config_id", configID, "error", sendErr)
+	}
+
 	return configID, nil
 }
 
@@ -127,7 +161,8 @@ func (s *EmailService) GetEmailConfigsForUser(userID int) ([]model.EmailConfig,
 	var configs []model.EmailConfig
 
 	err := s.db.Select(&configs, `
-        SELECT id, user_id, email_address, email_name, language, is_active, notify_on_down, notify_on_up, created_at, updated_at
+        SELECT id, user_id, email_address, email_name, language, is_active, notify_on_down, notify_on_up, timezone, external_id,
+               verified_at, CASE WHEN verified_at IS NULL THEN 'pending' ELSE 'verified' END AS status, created_at, updated_at
         FROM email_configs
         WHERE user_id = $1
         ORDER BY created_at DESC
@@ -151,6 +186,12 @@ func (s *EmailService) GetEmailConfigsForUser(userID int) ([]model.EmailConfig,
 		}
 
 		configs[i].MonitorRegions = regions
+
+		tags, err := s.dispatcher.configTags(s.Channel(), configs[i].ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tags for config %d: %w", configs[i].ID, err)
+		}
+		configs[i].Tags = tags
 	}
 
 	return configs, nil
@@ -168,22 +209,55 @@ func (s *EmailService) UpdateEmailConfig(
 	isActive bool,
 	monitorRegions []string,
 ) error {
-	if language == "" {
-		language = "en"
-	}
-
 	tx, err := s.db.Beginx()
 	if err != nil {
 		return fmt.Errorf("failed to start transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
+	readdressed, err := updateEmailConfigTx(tx, configID, userID, emailAddress, emailName, language, notifyOnDown, notifyOnUp, isActive, monitorRegions)
+	if err != nil {
+		return err
+	}
 
-	_, err = tx.Exec(`
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if readdressed {
+		s.sendReaddressVerification(configID)
+	}
+	return nil
+}
+
+// sendReaddressVerification asks for confirmation of a config's new address
+func (s *EmailService) sendReaddressVerification(configID int) {
+	if err := s.sendVerification(configID); err != nil {
+		s.logger.Error("Failed to send email verification", "config_id", configID, "error", err)
+	}
+}
+
+// updateEmailConfigTx writes an email configuration and its regions within tx. It
+// reports whether the address changed and has to be confirmed again.
+func updateEmailConfigTx(
+	tx *sqlx.Tx,
+	configID,
+	userID int,
+	emailAddress,
+	emailName string,
+	language string,
+	notifyOnDown,
+	notifyOnUp bool,
+	isActive bool,
+	monitorRegions []string,
+) (bool, error) {
+	if language == "" {
+		language = "en"
+	}
+
+	// A new address has to be confirmed again
+	var readdressed bool
+	err := tx.Get(&readdressed, `
         UPDATE email_configs
         SET email_address = $1,
             email_name = $2,
@@ -191,18 +265,24 @@ func (s *EmailService) UpdateEmailConfig(
             notify_on_down = $4,
             notify_on_up = $5,
             is_active = $6,
+            verified_at = CASE WHEN LOWER(email_address) = LOWER($1) THEN verified_at END,
+            verification_code_hash = CASE WHEN LOWER(email_address) = LOWER($1) THEN verification_code_hash END,
             updated_at = NOW()
         WHERE id = $7 AND user_id = $8
+        RETURNING verified_at IS NULL AND verification_code_hash IS NULL
     `, emailAddress, emailName, language, notifyOnDown, notifyOnUp, isActive, configID, userID)
 
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
 	if err != nil {
-		return fmt.Errorf("failed to update email configuration: %w", err)
+		return false, fmt.Errorf("failed to update email configuration: %w", err)
 	}
 
 	// Delete existing regions
 	_, err = tx.Exec(`DELETE FROM email_config_regions WHERE email_config_id = $1`, configID)
 	if err != nil {
-		return fmt.Errorf("failed to clear existing regions: %w", err)
+		return false, fmt.Errorf("failed to clear existing regions: %w", err)
 	}
 
 	// Add new regions if specified
@@ -212,7 +292,7 @@ func (s *EmailService) UpdateEmailConfig(
             VALUES ($1, $2)
         `)
 		if err != nil {
-			return fmt.Errorf("failed to prepare statement: %w", err)
+			return false, fmt.Errorf("failed to prepare statement: %w", err)
 		}
 		defer stmt.Close()
 
@@ -220,24 +300,20 @@ func (s *EmailService) UpdateEmailConfig(
 			var exists bool
 			err = tx.Get(&exists, "SELECT EXISTS(SELECT 1 FROM regions WHERE code = $1)", region)
 			if err != nil {
-				return fmt.Errorf("failed to verify region %s: %w", region, err)
+				return false, fmt.Errorf("failed to verify region %s: %w", region, err)
 			}
 			if !exists {
-				return fmt.Errorf("region code not found: %s", region)
+				return false, fmt.Errorf("region code not found: %s", region)
 			}
 
 			_, err = stmt.Exec(configID, region)
 			if err != nil {
-				return fmt.Errorf("failed to add region %s: %w", region, err)
+				return false, fmt.Errorf("failed to add region %s: %w", region, err)
 			}
 		}
 	}
 
-	if err = tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
-	return nil
+	return readdressed, nil
 }
 
 // DeleteEmailConfig deletes an email configuration
@@ -254,467 +330,348 @@ func (s *EmailService) DeleteEmailConfig(configID, userID int) error {
 	return nil
 }
 
-// SendDomainStatusNotification sends email notification about domain status change
-func (s *EmailService) SendDomainStatusNotification(domain model.Domain, statusChanged bool) error {
-	var configs []struct {
-		ID             int      `db:"id"`
-		EmailAddress   string   `db:"email_address"`
-		EmailName      string   `db:"email_name"`
-		Language       string   `db:"language"`
-		IsActive       bool     `db:"is_active"`
-		NotifyOnUp     bool     `db:"notify_on_up"`
-		NotifyOnDown   bool     `db:"notify_on_down"`
-		MonitorRegions []string `db:"monitor_regions"`
-	}
+// SendDomainStatus sends email notification about domain status change
+func (s *EmailService) SendDomainStatus(domain model.Domain, statusChanged bool) error {
+	return s.dispatcher.Dispatch(s, domain, statusChanged)
+}
 
-	err := s.db.Select(&configs, `
-        SELECT ec.id, ec.email_address, ec.email_name, ec.language, ec.is_active, ec.notify_on_up, ec.notify_on_down
-        FROM email_configs ec
-        WHERE ec.user_id = $1
-    `, domain.UserID)
+// FlushDigests delivers due digests to digest-only email configs
+func (s *EmailService) FlushDigests() error {
+	return s.dispatcher.FlushDigests(s)
+}
 
-	if err != nil {
-		log.Printf("Failed to get email configurations for user %d: %v", domain.UserID, err)
-		return fmt.Errorf("failed to get email configurations for user: %w", err)
-	}
+// GetDigestSettings returns the digest settings of an email config
+func (s *EmailService) GetDigestSettings(configID, userID int) (*model.DigestSettings, error) {
+	return s.dispatcher.GetDigestSettings(s, configID, userID)
+}
 
-	// Get regions for each config
-	for i := range configs {
-		var regions []string
-		err := s.db.Select(&regions, `
-            SELECT region_code
-            FROM email_config_regions
-            WHERE email_config_id = $1
-        `, configs[i].ID)
+// UpdateDigestSettings updates the digest settings of an email config
+func (s *EmailService) UpdateDigestSettings(configID, userID int, req model.DigestSettingsRequest) error {
+	return s.dispatcher.UpdateDigestSettings(s, configID, userID, req)
+}
 
-		if err != nil {
-			log.Printf("Failed to get regions for config %d: %v", configs[i].ID, err)
-			continue
-		}
+// GetLanguageFallbacks returns the language fallback chain of an email config
+func (s *EmailService) GetLanguageFallbacks(configID, userID int) (*model.LanguageFallbacks, error) {
+	return s.dispatcher.GetLanguageFallbacks(s, configID, userID)
+}
 
-		configs[i].MonitorRegions = regions
-	}
+// UpdateLanguageFallbacks sets the language fallback chain of an email config
+func (s *EmailService) UpdateLanguageFallbacks(configID, userID int, req model.LanguageFallbacksRequest) error {
+	return s.dispatcher.UpdateLanguageFallbacks(s, s.promptService, configID, userID, req)
+}
 
-	if len(configs) == 0 {
-		log.Printf("No email configurations for user %d", domain.UserID)
-		return nil
-	}
+// GetConfigTags returns the domain tags an email config subscribes to
+func (s *EmailService) GetConfigTags(configID, userID int) ([]string, error) {
+	return s.dispatcher.GetConfigTags(s, configID, userID)
+}
 
-	// Determine notification type
-	notificationType := "status"
-	if !domain.Available() {
-		notificationType = "down"
-	} else if statusChanged {
-		notificationType = "up"
-	}
+// UpdateConfigTags replaces the domain tags an email config subscribes to
+func (s *EmailService) UpdateConfigTags(configID, userID int, tags []string) ([]string, error) {
+	return s.dispatcher.UpdateConfigTags(s, configID, userID, tags)
+}
+
+// GetQuietHours returns the quiet hours of an email config
+func (s *EmailService) GetQuietHours(configID, userID int) (*model.QuietHours, error) {
+	return s.dispatcher.GetQuietHours(s, configID, userID)
+}
 
-	// Check suppression
-	s.notifyLock.Lock()
-	defer s.notifyLock.Unlock()
+// UpdateQuietHours replaces the quiet hours of an email config
+func (s *EmailService) UpdateQuietHours(configID, userID int, req model.QuietHoursRequest) (*model.QuietHours, error) {
+	return s.dispatcher.UpdateQuietHours(s, configID, userID, req)
+}
 
-	suppressionDuration := time.Duration(domain.Interval) * time.Minute
-	if !domain.Available() || statusChanged {
-		suppressionDuration = suppressionDuration / 2
-	}
+// GetConfigTimezone returns the timezone preference of an email config
+func (s *EmailService) GetConfigTimezone(configID, userID int) (*model.ConfigTimezone, error) {
+	return s.dispatcher.GetConfigTimezone(s, configID, userID)
+}
 
-	minSuppression := 2 * time.Minute
-	if suppressionDuration < minSuppression {
-		suppressionDuration = minSuppression
-	}
+// UpdateConfigTimezone sets the timezone preference of an email config
+func (s *EmailService) UpdateConfigTimezone(configID, userID int, timezone string) (*model.ConfigTimezone, error) {
+	return s.dispatcher.UpdateConfigTimezone(s, configID, userID, timezone)
+}
 
-	cacheKey := fmt.Sprintf("%d:%s", domain.ID, notificationType)
-	now := time.Now()
-	if lastSent, exists := s.notifyCache[cacheKey]; exists {
-		timeSinceLast := now.Sub(lastSent)
-		if timeSinceLast < suppressionDuration {
-			log.Printf("Skipping email notification for domain %s (%s): last sent %s ago, suppression duration: %s",
-				domain.Name, notificationType, timeSinceLast, suppressionDuration)
-			return nil
-		}
+// Channel implements Notifier
+func (s *EmailService) Channel() string {
+	return "email"
+}
+
+// HistoryColumn implements Notifier
+func (s *EmailService) HistoryColumn() string {
+	return "email_config_id"
+}
+
+// GetRecipients implements Notifier
+func (s *EmailService) GetRecipients(userID int) ([]Recipient, error) {
+	configs, err := s.GetEmailConfigsForUser(userID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Format time
-	loc, err := time.LoadLocation(TIMEZONE_LOCATION)
+	userTimezone, err := s.dispatcher.userTimezone(userID)
 	if err != nil {
-		loc = time.FixedZone("UTC+8", 8*60*60)
+		s.logger.Error("Failed to get user timezone", "user_id", userID, "error", err)
 	}
-	formattedTime := domain.LastCheck.In(loc).Format("2006-01-02 15:04:05")
 
-	// Send to all configured emails
+	recipients := make([]Recipient, 0, len(configs))
 	for _, config := range configs {
-		if !config.IsActive {
-			log.Printf("Skipping email notification for domain %s to %s: Email config is inactive",
-				domain.Name, config.EmailAddress)
-			continue
-		}
-
-		// Check region filtering
-		if len(config.MonitorRegions) > 0 {
-			regionMatches := false
-			for _, region := range config.MonitorRegions {
-				if region == domain.Region {
-					regionMatches = true
-					break
-				}
-			}
-
-			if !regionMatches {
-				log.Printf("Skipping email notification for domain %s to %s: Domain region %s not in monitor regions %v",
-					domain.Name, config.EmailAddress, domain.Region, config.MonitorRegions)
-				continue
-			}
-		}
-
-		// Check notification preferences
-		if notificationType == "up" && !config.NotifyOnUp {
-			log.Printf("Skipping 'back up' email notification for domain %s to %s: notify_on_up is disabled",
-				domain.Name, config.EmailAddress)
-			continue
-		}
-
-		if notificationType == "down" && !config.NotifyOnDown {
-			log.Printf("Skipping 'down' email notification for domain %s to %s: notify_on_down is disabled",
-				domain.Name, config.EmailAddress)
-			continue
-		}
-
-		// Check notification history
-		var lastNotification time.Time
-		err := s.db.Get(&lastNotification, `
-            SELECT MAX(notified_at) 
-            FROM notification_history
-            WHERE domain_id = $1 AND email_config_id = $2 AND notification_type = $3
-        `, domain.ID, config.ID, notificationType)
-
-		if err == nil && !lastNotification.IsZero() {
-			if now.Sub(lastNotification) < suppressionDuration {
-				log.Printf("Skipping email notification to %s for domain %s: last sent at %s (suppression: %s)",
-					config.EmailAddress, domain.Name, lastNotification, suppressionDuration)
-				continue
-			}
-		}
-
-		// Send email with language support
-		subject, body := s.formatEmailMessage(notificationType, domain, formattedTime, config.Language)
-
-		if err := s.sendEmail(config.EmailAddress, subject, body); err != nil {
-			log.Printf("Failed to send email notification to %s: %v", config.EmailAddress, err)
-			continue
-		}
-
-		// Record notification in database
-		_, err = s.db.Exec(`
-            INSERT INTO notification_history
-            (domain_id, email_config_id, status_code, error_code, error_description, notified_at, notification_type)
-            VALUES ($1, $2, $3, $4, $5, NOW(), $6)
-        `, domain.ID, config.ID, domain.LastStatus, domain.ErrorCode, domain.ErrorDescription, notificationType)
+		recipients = append(recipients, Recipient{
+			ConfigID:       config.ID,
+			Address:        config.EmailAddress,
+			Label:          config.EmailAddress,
+			Language:       config.Language,
+			IsActive:       config.IsActive && config.VerifiedAt != nil, // Pending configs are never notified
+			NotifyOnUp:     config.NotifyOnUp,
+			NotifyOnDown:   config.NotifyOnDown,
+			MonitorRegions: config.MonitorRegions,
+			Tags:           config.Tags,
+			Timezone:       effectiveTimezone(config.Timezone, userTimezone),
+		})
+	}
+	return recipients, nil
+}
 
+// Send implements Notifier
+func (s *EmailService) Send(recipient Recipient, notificationType string, domain model.Domain, formattedTime string) (string, error) {
+	// Alerts and recoveries show how the domain fared over the last day
+	var attachments []EmailAttachment
+	if notificationType != "status" {
+		now := time.Now()
+		sparkline, err := s.domainSparkline(domain.ID, "sparkline", now.Add(-sparklineWindow), now)
 		if err != nil {
-			log.Printf("Failed to record email notification history: %v", err)
+			s.logger.Error("Failed to render sparkline", "domain_id", domain.ID, "error", err)
+		} else if sparkline != nil {
+			attachments = append(attachments, *sparkline)
 		}
-
-		s.notifyCache[cacheKey] = now
 	}
 
-	return nil
+	subject, body := s.formatEmailMessage(notificationType, domain, formattedTime, service.LanguageChain(recipient.Language, recipient.Fallbacks), attachments)
+	return s.deliverEmail(recipient.Address, subject, body, attachments...)
 }
 
-// translateText translates text using Google Translate API (free tier) - add this helper function
-func translateText(text, sourceLang, targetLang string) (string, error) {
-	// Use Google Translate's free web API endpoint
-	baseURL := "https://translate.googleapis.com/translate_a/single"
-
-	params := url.Values{}
-	params.Set("client", "gtx")
-	params.Set("sl", sourceLang) // source language
-	params.Set("tl", targetLang) // target language
-	params.Set("dt", "t")        // return translation
-	params.Set("q", text)        // text to translate
+// SendDigest implements Notifier
+func (s *EmailService) SendDigest(recipient Recipient, digest model.Digest) error {
+	subject := fmt.Sprintf("Domain monitoring digest: %d down", len(digest.DownDomains))
+	body := "<html><body><pre style=\"font-family: monospace\">" +
+		template.HTMLEscapeString(formatDigestText(digest, recipient.Timezone)) +
+		"</pre></body></html>"
+	return s.sendEmail(recipient.Address, subject, body)
+}
 
-	fullURL := baseURL + "?" + params.Encode()
+// emailDefaultTexts are the English email texts, used when telegram_prompts has no
+// message for a key in any language of the chain. {domain} is replaced by the domain name.
+var emailDefaultTexts = map[string]string{
+	"email.subject.down":        "🔴 Domain name {domain} is currently unreachable",
+	"email.subject.up":          "🟢 Domain name {domain} is back to normal",
+	"email.subject.status":      "📊 Domain name {domain} status update",
+	"email.subject.blocked":     "🚫 Domain name {domain} is likely blocked by an ISP",
+	"email.title.down":          "🔴 Domain name alert",
+	"email.title.up":            "🟢 Domain name back to normal",
+	"email.title.status":        "📊 Domain name status update",
+	"email.title.blocked":       "🚫 Domain name blocking alert",
+	"email.headline.down":       "Domain name {domain} is currently unreachable",
+	"email.headline.up":         "Domain name {domain} is back to normal!",
+	"email.headline.status":     "Domain name {domain} status update",
+	"email.headline.blocked":    "Domain name {domain} is serving an ISP block page or being reset",
+	"email.label.status_code":   "Status Code:",
+	"email.label.error":         "Error:",
+	"email.label.response_time": "Response Time:",
+	"email.label.last_check":    "Last Check:",
+	"email.label.chart":         "Last 24 hours:",
+	"email.footer":              "This is an automated message from your Domain Monitoring Service.",
+}
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Get(fullURL)
+// emailTexts returns the email texts in the first language of the chain that has each
+// one, with {domain} substituted
+func (s *EmailService) emailTexts(languages []string, domainName string) map[string]string {
+	translations := make(map[string]string)
+	prompts, err := s.promptService.GetAllPromptsByLanguageChain(languages)
 	if err != nil {
-		return "", fmt.Errorf("translation request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("translation API returned status %d", resp.StatusCode)
-	}
-
-	// The response is a complex nested array, we need to parse it carefully
-	var result []interface{}
-	decoder := json.NewDecoder(resp.Body)
-	if err := decoder.Decode(&result); err != nil {
-		return "", fmt.Errorf("failed to decode translation response: %w", err)
+		s.logger.Error("Failed to get prompts", "languages", languages, "error", err)
 	}
-
-	// Extract translated text from the response structure
-	if len(result) > 0 {
-		if translations, ok := result[0].([]interface{}); ok {
-			var translatedParts []string
-			for _, translation := range translations {
-				if part, ok := translation.([]interface{}); ok && len(part) > 0 {
-					if translatedText, ok := part[0].(string); ok {
-						translatedParts = append(translatedParts, translatedText)
-					}
-				}
-			}
-			if len(translatedParts) > 0 {
-				return strings.Join(translatedParts, ""), nil
-			}
+	for _, prompt := range prompts {
+		if msg := prompt.Messages[languages[0]]; msg != "" {
+			translations[prompt.PromptKey] = msg
 		}
 	}
 
-	return "", fmt.Errorf("unexpected response structure from translation API")
-}
-
-// formatEmailMessage formats the email subject and body with translation support
-func (s *EmailService) formatEmailMessage(notificationType string, domain model.Domain, formattedTime string, language string) (string, string) {
-	// Default language to English if not provided
-	if language == "" {
-		language = "en"
-	}
-
-	// Define translatable text in English first
-	var subjectPrefix, alertTitle, recoveryTitle, statusTitle string
-	var domainLabel, statusCodeLabel, errorLabel, responseTimeLabel, lastCheckLabel string
-	var footerText string
-
-	switch notificationType {
-	case "down":
-		subjectPrefix = "🔴 Domain name %s is currently unreachable"
-		alertTitle = "🔴 Domain name alert"
-		domainLabel = "Domain name %s is currently unreachable"
-		statusCodeLabel = "Status Code:"
-		errorLabel = "Error:"
-		responseTimeLabel = "Response Time:"
-		lastCheckLabel = "Last Check:"
-		footerText = "This is an automated message from your Domain Monitoring Service."
-
-	case "up":
-		subjectPrefix = "🟢 Domain name %s is back to normal"
-		recoveryTitle = "🟢 Domain name back to normal"
-		domainLabel = "Domain name %s is back to normal!"
-		statusCodeLabel = "Status Code:"
-		responseTimeLabel = "Response Time:"
-		lastCheckLabel = "Last Check:"
-		footerText = "This is an automated message from your Domain Monitoring Service."
-
-	default:
-		subjectPrefix = "📊 Domain name %s status update"
-		statusTitle = "📊 Domain name status update"
-		domainLabel = "Domain name %s status update"
-		statusCodeLabel = "Status Code:"
-		responseTimeLabel = "Response Time:"
-		lastCheckLabel = "Last Check:"
-		footerText = "This is an automated message from your Domain Monitoring Service."
-	}
-
-	// Translate text if language is not English
-	if language != "en" {
-		log.Printf("[EMAIL] Translating email content from English to %s", language)
-
-		// Translate all text elements with error handling
-		if translated, err := translateText("Domain name", "en", language); err == nil {
-			// Replace "Domain" in patterns
-			switch notificationType {
-			case "down":
-				if translatedDown, err := translateText("is currently unreachable", "en", language); err == nil {
-					subjectPrefix = fmt.Sprintf("🔴 %s %%s %s", translated, translatedDown)
-					domainLabel = fmt.Sprintf("%s %%s %s", translated, func() string {
-						if t, err := translateText("is currently unreachable", "en", language); err == nil {
-							return t
-						}
-						return "is currently unreachable"
-					}())
-				}
-			case "up":
-				if translatedUp, err := translateText("is back to normal", "en", language); err == nil {
-					subjectPrefix = fmt.Sprintf("🟢 %s %%s %s", translated, translatedUp)
-					domainLabel = fmt.Sprintf("%s %%s %s", translated, func() string {
-						if t, err := translateText("is back to normal!", "en", language); err == nil {
-							return t
-						}
-						return "is back to normal!"
-					}())
-				}
-			default:
-				if translatedStatus, err := translateText("status update", "en", language); err == nil {
-					subjectPrefix = fmt.Sprintf("📊 %s %%s %s", translated, translatedStatus)
-					domainLabel = fmt.Sprintf("%s %%s %s", translated, translatedStatus)
-				}
-			}
-		}
-
-		// Translate titles
-		switch notificationType {
-		case "down":
-			if translated, err := translateText("Domain name alert", "en", language); err == nil {
-				alertTitle = "🔴 " + translated
-			}
-		case "up":
-			if translated, err := translateText("Domain name back to normal", "en", language); err == nil {
-				recoveryTitle = "🟢 " + translated
-			}
-		default:
-			if translated, err := translateText("Domain name status update", "en", language); err == nil {
-				statusTitle = "📊 " + translated
-			}
-		}
-
-		// Translate labels
-		if translated, err := translateText("Status Code:", "en", language); err == nil {
-			statusCodeLabel = translated
-		}
-		if translated, err := translateText("Error:", "en", language); err == nil {
-			errorLabel = translated
-		}
-		if translated, err := translateText("Response Time:", "en", language); err == nil {
-			responseTimeLabel = translated
-		}
-		if translated, err := translateText("Last Check:", "en", language); err == nil {
-			lastCheckLabel = translated
+	texts := make(map[string]string, len(emailDefaultTexts))
+	for key, text := range emailDefaultTexts {
+		if msg, ok := translations[key]; ok {
+			text = msg
 		}
-		if translated, err := translateText("This is an automated message from your Domain Monitoring Service.", "en", language); err == nil {
-			footerText = translated
-		}
-
-		// Add delay to avoid API rate limits
-		time.Sleep(500 * time.Millisecond)
+		texts[key] = strings.ReplaceAll(text, "{domain}", domainName)
 	}
+	return texts
+}
 
-	// Create subject and body with translated content
-	var subject, bodyTemplate string
-
-	switch notificationType {
-	case "down":
-		subject = fmt.Sprintf(subjectPrefix, domain.Name)
-		bodyTemplate = `
+// emailBodyTemplate renders every domain status email; the texts come pre-translated
+var emailBodyTemplate = template.Must(template.New("email").Parse(`
             <!DOCTYPE html>
             <html>
             <head>
                 <meta charset="UTF-8">
-                <title>Domain Down Alert</title>
+                <title>{{.Title}}</title>
             </head>
             <body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
                 <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
-                    <h2 style="color: #e74c3c;">` + alertTitle + `</h2>
-                    <p><strong>` + fmt.Sprintf(domainLabel, "{{.Domain}}") + `</strong></p>
+                    <h2 style="color: {{.Color}};">{{.Title}}</h2>
+                    {{if .Escalated}}<p style="color: #e74c3c;"><strong>⏫ Escalation: down for {{.IncidentDuration}}</strong></p>{{end}}
+                    <p><strong>{{.Headline}}</strong></p>
                     <div style="background-color: #f8f9fa; padding: 15px; border-radius: 5px; margin: 20px 0;">
-                        <p><strong>` + statusCodeLabel + `</strong> {{.Status}}</p>
-                        <p><strong>` + errorLabel + `</strong> {{.Error}}</p>
-                        <p><strong>` + responseTimeLabel + `</strong> {{.ResponseTime}}ms</p>
-                        <p><strong>` + lastCheckLabel + `</strong> {{.LastCheck}} (UTC+8)</p>
+                        <p><strong>{{.StatusCodeLabel}}</strong> {{.Status}}</p>
+                        {{if .ErrorLabel}}<p><strong>{{.ErrorLabel}}</strong> {{.Error}}</p>{{end}}
+                        <p><strong>{{.ResponseTimeLabel}}</strong> {{.ResponseTime}}ms</p>
+                        <p><strong>{{.LastCheckLabel}}</strong> {{.LastCheck}}</p>
+                        {{if .IncidentID}}{{if .Resolved}}<p><strong>Incident:</strong> #{{.IncidentID}} resolved after {{.IncidentDuration}}</p>{{else}}<p><strong>Incident:</strong> #{{.IncidentID}}</p>{{end}}{{end}}
                     </div>
-                    <p style="color: #666; font-size: 12px;">` + footerText + `</p>
-                </div>
-            </body>
-            </html>`
-	case "up":
-		subject = fmt.Sprintf(subjectPrefix, domain.Name)
-		bodyTemplate = `
-            <!DOCTYPE html>
-            <html>
-            <head>
-                <meta charset="UTF-8">
-                <title>Domain Recovery Alert</title>
-            </head>
-            <body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
-                <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
-                    <h2 style="color: #27ae60;">` + recoveryTitle + `</h2>
-                    <p><strong>` + fmt.Sprintf(domainLabel, "{{.Domain}}") + `</strong></p>
-                    <div style="background-color: #f8f9fa; padding: 15px; border-radius: 5px; margin: 20px 0;">
-                        <p><strong>` + statusCodeLabel + `</strong> {{.Status}}</p>
-                        <p><strong>` + responseTimeLabel + `</strong> {{.ResponseTime}}ms</p>
-                        <p><strong>` + lastCheckLabel + `</strong> {{.LastCheck}} (UTC+8)</p>
+                    {{if .ChartSrc}}<p><strong>{{.ChartLabel}}</strong><br><img src="{{.ChartSrc}}" width="320" height="64" alt="{{.ChartLabel}}"></p>{{end}}
+                    {{if or .RunbookURL .RunbookNotes}}
+                    <div style="border-left: 4px solid #e67e22; padding: 10px 15px; margin: 20px 0;">
+                        <h3 style="margin-top: 0;">Runbook</h3>
+                        {{if .RunbookNotes}}<p style="white-space: pre-wrap;">{{.RunbookNotes}}</p>{{end}}
+                        {{if .RunbookURL}}<p><a href="{{.RunbookURL}}">{{.RunbookURL}}</a></p>{{end}}
                     </div>
-                    <p style="color: #666; font-size: 12px;">` + footerText + `</p>
+                    {{end}}
+                    <p style="color: #666; font-size: 12px;">{{.Footer}}</p>
                 </div>
             </body>
-            </html>`
-	default:
-		subject = fmt.Sprintf(subjectPrefix, domain.Name)
-		bodyTemplate = `
-            <!DOCTYPE html>
-            <html>
-            <head>
-                <meta charset="UTF-8">
-                <title>Domain Status Update</title>
-            </head>
-            <body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
-                <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
-                    <h2 style="color: #3498db;">` + statusTitle + `</h2>
-                    <p><strong>` + fmt.Sprintf(domainLabel, "{{.Domain}}") + `</strong></p>
-                    <div style="background-color: #f8f9fa; padding: 15px; border-radius: 5px; margin: 20px 0;">
-                        <p><strong>` + statusCodeLabel + `</strong> {{.Status}}</p>
-                        <p><strong>` + responseTimeLabel + `</strong> {{.ResponseTime}}ms</p>
-                        <p><strong>` + lastCheckLabel + `</strong> {{.LastCheck}} (UTC+8)</p>
-                    </div>
-                    <p style="color: #666; font-size: 12px;">` + footerText + `</p>
-                </div>
-            </body>
-            </html>`
+            </html>`))
+
+// formatEmailMessage formats the email subject and body from the stored email texts.
+// Each text is taken from the first language of the chain that has it.
+func (s *EmailService) formatEmailMessage(notificationType string, domain model.Domain, formattedTime string, languages []string, charts []EmailAttachment) (string, string) {
+	// Escalations are down alerts flagged in the subject and headed by the outage length
+	escalated := notificationType == NotificationTypeEscalation
+	if escalated {
+		notificationType = "down"
 	}
 
-	// Execute template
-	tmpl, err := template.New("email").Parse(bodyTemplate)
-	if err != nil {
-		log.Printf("Error parsing email template: %v", err)
-		return subject, "Error generating email content"
+	kind, color := "status", "#3498db"
+	switch notificationType {
+	case "down":
+		kind, color = "down", "#e74c3c"
+	case NotificationTypeBlocked:
+		kind, color = "blocked", "#8e44ad"
+	case "up":
+		kind, color = "up", "#27ae60"
+	}
+
+	texts := s.emailTexts(languages, domain.DisplayName())
+	subject := texts["email.subject."+kind]
+	if escalated {
+		subject = "[Escalation] " + subject
 	}
 
 	data := struct {
-		Domain       string
+		Title             string
+		Color             string
+		Headline          string
+		StatusCodeLabel   string
+		ErrorLabel        string
+		ResponseTimeLabel string
+		LastCheckLabel    string
+		Footer            string
+
 		Status       int
 		Error        string
 		ResponseTime int
 		LastCheck    string
+		RunbookURL   string
+		RunbookNotes string
+
+		IncidentID       int
+		IncidentDuration string
+		Resolved         bool
+		Escalated        bool
+
+		ChartLabel string
+		ChartSrc   template.URL
 	}{
-		Domain:       domain.Name,
-		Status:       domain.LastStatus,
-		Error:        domain.ErrorDescription,
-		ResponseTime: domain.TotalTime,
-		LastCheck:    formattedTime,
+		Title:             texts["email.title."+kind],
+		Color:             color,
+		Headline:          texts["email.headline."+kind],
+		StatusCodeLabel:   texts["email.label.status_code"],
+		ResponseTimeLabel: texts["email.label.response_time"],
+		LastCheckLabel:    texts["email.label.last_check"],
+		Footer:            texts["email.footer"],
+		Status:            domain.LastStatus,
+		Error:             domain.ErrorDescription,
+		ResponseTime:      domain.TotalTime,
+		LastCheck:         formattedTime,
+		Resolved:          kind == "up",
+		Escalated:         escalated && domain.Incident != nil,
+	}
+	if isDownAlert(kind) {
+		data.ErrorLabel = texts["email.label.error"]
+	}
+	// Runbooks help with outages only
+	if domain.Runbook != nil && isDownAlert(kind) {
+		data.RunbookURL = domain.Runbook.URL
+		data.RunbookNotes = domain.Runbook.Notes
+	}
+	if len(charts) > 0 {
+		data.ChartLabel = texts["email.label.chart"]
+		data.ChartSrc = inlineImageSrc(charts[0])
+	}
+	if domain.Incident != nil && kind != "status" {
+		data.IncidentID = domain.Incident.ID
+		data.IncidentDuration = formatIncidentDuration(domain.Incident.Duration())
 	}
 
 	var body bytes.Buffer
-	if err := tmpl.Execute(&body, data); err != nil {
-		log.Printf("Error executing email template: %v", err)
+	if err := emailBodyTemplate.Execute(&body, data); err != nil {
+		s.logger.Error("Failed to execute email template", "error", err)
 		return subject, "Error generating email content"
 	}
 
 	return subject, body.String()
 }
 
-// sendEmail sends an email using SMTP
-func (s *EmailService) sendEmail(toEmail, subject, body string) error {
+// sendEmail sends an email through the configured provider
+func (s *EmailService) sendEmail(toEmail, subject, body string, attachments ...EmailAttachment) error {
+	_, err := s.deliverEmail(toEmail, subject, body, attachments...)
+	return err
+}
+
+// deliverEmail sends an HTML email and returns its Message-ID
+func (s *EmailService) deliverEmail(toEmail, subject, body string, attachments ...EmailAttachment) (string, error) {
 	from := s.config.FromEmail
-	to := []string{toEmail}
-
-	// Create message with proper headers
-	msg := []byte("From: " + from + "\r\n" +
-		"To: " + toEmail + "\r\n" +
-		"Subject: " + subject + "\r\n" +
-		"MIME-Version: 1.0\r\n" +
-		"Content-Type: text/html; charset=UTF-8\r\n" +
-		"\r\n" +
-		body + "\r\n")
-
-	// SMTP authentication
-	auth := smtp.PlainAuth("", s.config.SMTPUsername, s.config.SMTPPassword, s.config.SMTPHost)
-
-	// Send email using smtp.SendMail (handles STARTTLS automatically)
-	serverAddr := s.config.SMTPHost + ":" + s.config.SMTPPort
-	err := smtp.SendMail(serverAddr, auth, from, to, msg)
+	messageID, err := newMessageID(from)
 	if err != nil {
-		return fmt.Errorf("failed to send email: %w", err)
+		return "", err
 	}
 
-	log.Printf("Email sent successfully to %s", toEmail)
-	return nil
+	s.logger.Debug("Sending email", "to", toEmail, "subject", subject, "body", body)
+
+	messageID, err = s.sender.Send(EmailMessage{
+		From:      from,
+		FromName:  s.config.FromName,
+		To:        toEmail,
+		Subject:   subject,
+		HTML:      body,
+		MessageID: messageID,
+
+		Attachments: attachments,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	s.logger.Info("Email sent", "to", toEmail, "message_id", messageID)
+	return messageID, nil
+}
+
+// newMessageID returns a unique Message-ID header value in the sender's domain
+func newMessageID(from string) (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate message ID: %w", err)
+	}
+	host := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 && at < len(from)-1 {
+		host = strings.TrimSuffix(from[at+1:], ">")
+	}
+	return "<" + hex.EncodeToString(raw) + "@" + host + ">", nil
 }
 
 // SendTestEmail sends a test email
@@ -722,14 +679,16 @@ func (s *EmailService) SendTestEmail(config model.EmailConfig) error {
 	if !config.IsActive {
 		return fmt.Errorf("email configuration is not active")
 	}
+	if config.VerifiedAt == nil {
+		return fmt.Errorf("email configuration is not verified")
+	}
 
 	subject := "🧪 Test Email from Domain Monitor"
-	// Format time in UTC+8
-	loc, err := time.LoadLocation(TIMEZONE_LOCATION)
+	userTimezone, err := s.dispatcher.userTimezone(config.UserID)
 	if err != nil {
-		loc = time.FixedZone("UTC+8", 8*60*60)
+		s.logger.Error("Failed to get user timezone", "user_id", config.UserID, "error", err)
 	}
-	formattedTime := time.Now().In(loc).Format("2006-01-02 15:04:05")
+	formattedTime := formatLocalTime(time.Now(), effectiveTimezone(config.Timezone, userTimezone), "2006-01-02 15:04:05")
 
 	body := `
 	<!DOCTYPE html>
@@ -748,7 +707,7 @@ func (s *EmailService) SendTestEmail(config model.EmailConfig) error {
 				<p><strong>Email:</strong> ` + config.EmailAddress + `</p>
 				<p><strong>Language:</strong> ` + config.Language + `</p>
 			</div>
-			<p style="color: #666; font-size: 12px;">Sent at: ` + formattedTime + ` (UTC+8)</p>
+			<p style="color: #666; font-size: 12px;">Sent at: ` + formattedTime + `</p>
 		</div>
 	</body>
 	</html>`
@@ -756,15 +715,31 @@ func (s *EmailService) SendTestEmail(config model.EmailConfig) error {
 	return s.sendEmail(config.EmailAddress, subject, body)
 }
 
-// SendCustomHTMLMessage sends a custom HTML email to user's email configs
-func (s *EmailService) SendCustomHTMLMessage(userID int, subject, htmlBody string) error {
+// SendTest sends a test email to an email config owned by the user
+func (s *EmailService) SendTest(configID, userID int) error {
+	configs, err := s.GetEmailConfigsForUser(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user email configs: %w", err)
+	}
+
+	for _, config := range configs {
+		if config.ID == configID {
+			return s.SendTestEmail(config)
+		}
+	}
+	return ErrConfigNotFound
+}
+
+// SendCustom sends a custom HTML email to the user's active email configs, rendered
+// in the language of each config
+func (s *EmailService) SendCustom(userID int, render MessageRenderer) error {
 	configs, err := s.GetEmailConfigsForUser(userID)
 	if err != nil {
 		return fmt.Errorf("failed to get user email configs: %w", err)
 	}
 
 	if len(configs) == 0 {
-		log.Printf("No email configs found for user %d", userID)
+		s.logger.Info("No email configs found", "user_id", userID)
 		return fmt.Errorf("no email configs found for user %d", userID)
 	}
 
@@ -773,21 +748,29 @@ func (s *EmailService) SendCustomHTMLMessage(userID int, subject, htmlBody strin
 
 	// Send to all active email configs
 	for _, config := range configs {
-		if !config.IsActive {
-			log.Printf("Skipping inactive email config %d for user %d", config.ID, userID)
+		if !config.IsActive || config.VerifiedAt == nil {
+			s.logger.Debug("Skipping inactive or pending email config", "config_id", config.ID, "user_id", userID)
+			continue
+		}
+
+		language := config.Language
+		if language == "" {
+			language = service.DefaultLanguage
+		}
+		msg := render(language)
+		if msg.HTML == "" {
 			continue
 		}
 
-		log.Printf("Sending custom HTML email to %s for user %d", config.EmailAddress, userID)
+		s.logger.Debug("Sending custom HTML email", "config_id", config.ID, "user_id", userID)
 
-		if err := s.sendEmail(config.EmailAddress, subject, htmlBody); err != nil {
-			log.Printf("Failed to send custom HTML email to %s: %v", config.EmailAddress, err)
+		if err := s.SendEmailToSpecificConfig(config, msg.Subject, msg.HTML, msg.Attachments...); err != nil {
+			s.logger.Error("Failed to send custom HTML email", "config_id", config.ID, "error", err)
 			lastError = err
 			continue
 		}
 
 		sentCount++
-		log.Printf("Successfully sent custom HTML email to %s", config.EmailAddress)
 	}
 
 	if sentCount == 0 {
@@ -797,8 +780,7 @@ func (s *EmailService) SendCustomHTMLMessage(userID int, subject, htmlBody strin
 		return fmt.Errorf("no active email configs found for user %d", userID)
 	}
 
-	log.Printf("Successfully sent custom HTML email to %d/%d email configs for user %d",
-		sentCount, len(configs), userID)
+	s.logger.Info("Sent custom HTML email", "sent", sentCount, "configs", len(configs), "user_id", userID)
 	return nil
 }
 
@@ -811,17 +793,19 @@ func (s *EmailService) SendCustomHTMLMessage(userID int, subject, htmlBody strin
 // }
 
 // SendEmailToSpecificConfig sends an email to a specific email configuration
-func (s *EmailService) SendEmailToSpecificConfig(config model.EmailConfig, subject, htmlBody string) error {
+func (s *EmailService) SendEmailToSpecificConfig(config model.EmailConfig, subject, htmlBody string, attachments ...EmailAttachment) error {
 	if !config.IsActive {
 		return fmt.Errorf("email configuration is not active")
 	}
+	if config.VerifiedAt == nil {
+		return fmt.Errorf("email configuration is not verified")
+	}
 
-	log.Printf("Sending email to %s with subject: %s", config.EmailAddress, subject)
+	s.logger.Debug("Sending email to config", "config_id", config.ID, "subject", subject)
 
-	if err := s.sendEmail(config.EmailAddress, subject, htmlBody); err != nil {
+	if err := s.sendEmail(config.EmailAddress, subject, htmlBody, attachments...); err != nil {
 		return fmt.Errorf("failed to send email to %s: %w", config.EmailAddress, err)
 	}
 
-	log.Printf("Successfully sent email to %s", config.EmailAddress)
 	return nil
 }
@@ -0,0 +1,114 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+)
+
+// EmailAttachment is a file attached to an email
+type EmailAttachment struct {
+	Filename    string
+	ContentType string // e.g. "text/csv; charset=utf-8"
+	Data        []byte
+	ContentID   string // Set for images shown in the body through src="cid:<ContentID>"
+}
+
+// buildMIME renders a message with its headers. Inline images are sent with the HTML
+// body as multipart/related, other attachments around them as multipart/mixed.
+func buildMIME(msg EmailMessage) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("From: " + msg.From + "\r\n" +
+		"To: " + msg.To + "\r\n" +
+		"Subject: " + msg.Subject + "\r\n" +
+		"Message-ID: " + msg.MessageID + "\r\n" +
+		"MIME-Version: 1.0\r\n")
+
+	if len(msg.Attachments) == 0 {
+		buf.WriteString("Content-Type: text/html; charset=UTF-8\r\n" +
+			"\r\n" +
+			msg.HTML + "\r\n")
+		return buf.Bytes()
+	}
+
+	var inline, attached []EmailAttachment
+	for _, attachment := range msg.Attachments {
+		if attachment.ContentID != "" {
+			inline = append(inline, attachment)
+		} else {
+			attached = append(attached, attachment)
+		}
+	}
+
+	if len(attached) == 0 {
+		w := multipart.NewWriter(&buf)
+		buf.WriteString("Content-Type: multipart/related; boundary=\"" + w.Boundary() + "\"\r\n\r\n")
+		writeRelated(w, msg.HTML, inline)
+		return buf.Bytes()
+	}
+
+	w := multipart.NewWriter(&buf)
+	buf.WriteString("Content-Type: multipart/mixed; boundary=\"" + w.Boundary() + "\"\r\n\r\n")
+	if len(inline) > 0 {
+		related := multipart.NewWriter(io.Discard) // Only used for a fresh boundary
+		part, _ := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type": {"multipart/related; boundary=\"" + related.Boundary() + "\""},
+		})
+		nested := multipart.NewWriter(part)
+		nested.SetBoundary(related.Boundary())
+		writeRelated(nested, msg.HTML, inline)
+	} else {
+		writeHTMLPart(w, msg.HTML)
+	}
+	for _, attachment := range attached {
+		writeAttachmentPart(w, attachment)
+	}
+	w.Close()
+	return buf.Bytes()
+}
+
+// writeRelated writes the HTML body followed by the images it references, and closes w
+func writeRelated(w *multipart.Writer, html string, inline []EmailAttachment) {
+	writeHTMLPart(w, html)
+	for _, attachment := range inline {
+		writeAttachmentPart(w, attachment)
+	}
+	w.Close()
+}
+
+func writeHTMLPart(w *multipart.Writer, html string) {
+	part, _ := w.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/html; charset=UTF-8"}})
+	io.WriteString(part, html+"\r\n")
+}
+
+func writeAttachmentPart(w *multipart.Writer, attachment EmailAttachment) {
+	contentType := attachment.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header := textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"base64"},
+	}
+	if attachment.ContentID != "" {
+		header.Set("Content-ID", "<"+attachment.ContentID+">")
+		header.Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": attachment.Filename}))
+	} else {
+		header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	}
+	part, _ := w.CreatePart(header)
+	writeBase64Lines(part, attachment.Data)
+}
+
+// writeBase64Lines writes data base64 encoded in lines of 76 characters
+func writeBase64Lines(w io.Writer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		io.WriteString(w, encoded[:76]+"\r\n")
+		encoded = encoded[76:]
+	}
+	io.WriteString(w, encoded+"\r\n")
+}
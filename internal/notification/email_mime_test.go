@@ -0,0 +1,119 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+// readPart returns the media type and decoded body of a MIME part
+func readPart(t *testing.T, part *multipart.Part) (string, []byte) {
+	t.Helper()
+	mediaType, _, err := mime.ParseMediaType(part.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("invalid Content-Type %q: %v", part.Header.Get("Content-Type"), err)
+	}
+	var body io.Reader = part
+	if part.Header.Get("Content-Transfer-Encoding") == "base64" {
+		body = base64.NewDecoder(base64.StdEncoding, part)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mediaType, data
+}
+
+func TestBuildMIMEWithoutAttachments(t *testing.T) {
+	msg, err := mail.ReadMessage(bytes.NewReader(buildMIME(EmailMessage{
+		From:      "alerts@example.com",
+		To:        "user@example.com",
+		Subject:   "Domain down",
+		HTML:      "<p>down</p>",
+		MessageID: "<1@example.com>",
+	})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := msg.Header.Get("Content-Type"); got != "text/html; charset=UTF-8" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if got := msg.Header.Get("Message-ID"); got != "<1@example.com>" {
+		t.Errorf("Message-ID = %q", got)
+	}
+	body, _ := io.ReadAll(msg.Body)
+	if !strings.Contains(string(body), "<p>down</p>") {
+		t.Errorf("body = %q, want the HTML", body)
+	}
+}
+
+func TestBuildMIMEWithInlineImageAndAttachment(t *testing.T) {
+	chart := []byte("\x89PNG fake image")
+	csv := []byte(strings.Repeat("domain,status\r\nexample.com,down\r\n", 10))
+	msg, err := mail.ReadMessage(bytes.NewReader(buildMIME(EmailMessage{
+		From:    "alerts@example.com",
+		To:      "user@example.com",
+		Subject: "Deep check",
+		HTML:    `<img src="cid:chart">`,
+		Attachments: []EmailAttachment{
+			{Filename: "chart.png", ContentType: "image/png", Data: chart, ContentID: "chart"},
+			{Filename: "results.csv", ContentType: "text/csv; charset=utf-8", Data: csv},
+		},
+	})))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("Content-Type = %q, want multipart/mixed", msg.Header.Get("Content-Type"))
+	}
+	mixed := multipart.NewReader(msg.Body, params["boundary"])
+
+	// First the HTML with its inline image, then the attachment
+	related, err := mixed.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaType, params, _ = mime.ParseMediaType(related.Header.Get("Content-Type"))
+	if mediaType != "multipart/related" {
+		t.Fatalf("first part is %s, want multipart/related", mediaType)
+	}
+	inner := multipart.NewReader(related, params["boundary"])
+	html, err := inner.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mediaType, body := readPart(t, html); mediaType != "text/html" || !strings.Contains(string(body), "cid:chart") {
+		t.Errorf("related body is %s %q, want the HTML", mediaType, body)
+	}
+	image, err := inner.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := image.Header.Get("Content-ID"); got != "<chart>" {
+		t.Errorf("Content-ID = %q, want <chart>", got)
+	}
+	if _, body := readPart(t, image); !bytes.Equal(body, chart) {
+		t.Errorf("inline image = %q, want %q", body, chart)
+	}
+
+	attachment, err := mixed.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attachment.FileName() != "results.csv" {
+		t.Errorf("attachment filename = %q, want results.csv", attachment.FileName())
+	}
+	if _, body := readPart(t, attachment); !bytes.Equal(body, csv) {
+		t.Errorf("attachment = %q, want %q", body, csv)
+	}
+	if _, err := mixed.NextPart(); err != io.EOF {
+		t.Errorf("unexpected part after the attachment: %v", err)
+	}
+}
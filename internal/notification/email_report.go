@@ -0,0 +1,388 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"time"
+
+	"domain-detection-go/pkg/model"
+
+	"github.com/lib/pq"
+)
+
+// emailReportLongestIncidents and emailReportSlowestRegions limit the lists of a report
+const (
+	emailReportLongestIncidents = 10
+	emailReportSlowestRegions   = 5
+)
+
+// emailReportSchedule is the stored report settings of one email config
+type emailReportSchedule struct {
+	model.EmailReportSettings
+	UserID int `db:"user_id"`
+}
+
+// GetReportSettings returns the report settings of an email config, with defaults if
+// none are stored
+func (s *EmailService) GetReportSettings(configID, userID int) (*model.EmailReportSettings, error) {
+	if err := checkConfigOwner(s, configID, userID); err != nil {
+		return nil, err
+	}
+
+	settings := model.EmailReportSettings{
+		ConfigID:  configID,
+		Frequency: model.EmailReportWeekly,
+		Regions:   pq.StringArray{},
+	}
+	err := s.db.Get(&settings, `
+        SELECT config_id, enabled, frequency, regions, last_sent_at, last_period_end
+        FROM email_report_schedules
+        WHERE config_id = $1
+    `, configID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get report settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// UpdateReportSettings enables or changes the recurring report of an email config
+func (s *EmailService) UpdateReportSettings(configID, userID int, req model.EmailReportSettingsRequest) (*model.EmailReportSettings, error) {
+	if err := checkConfigOwner(s, configID, userID); err != nil {
+		return nil, err
+	}
+
+	if req.Frequency == "" {
+		req.Frequency = model.EmailReportWeekly
+	}
+	if req.Regions == nil {
+		req.Regions = []string{}
+	}
+	for _, region := range req.Regions {
+		var isValidRegion bool
+		err := s.db.Get(&isValidRegion, "SELECT EXISTS(SELECT 1 FROM regions WHERE code = $1)", region)
+		if err != nil {
+			return nil, fmt.Errorf("error verifying region: %w", err)
+		}
+		if !isValidRegion {
+			return nil, errInvalidRegion
+		}
+	}
+
+	_, err := s.db.Exec(`
+        INSERT INTO email_report_schedules (config_id, user_id, enabled, frequency, regions, updated_at)
+        VALUES ($1, $2, $3, $4, $5, NOW())
+        ON CONFLICT (config_id)
+        DO UPDATE SET enabled = $3, frequency = $4, regions = $5, updated_at = NOW()
+    `, configID, userID, req.Enabled, req.Frequency, pq.Array(req.Regions))
+	if err != nil {
+		return nil, fmt.Errorf("failed to save report settings: %w", err)
+	}
+	return s.GetReportSettings(configID, userID)
+}
+
+// SendReportNow emails the report of the last complete period to a config without
+// changing its schedule
+func (s *EmailService) SendReportNow(configID, userID int) error {
+	settings, err := s.GetReportSettings(configID, userID)
+	if err != nil {
+		return err
+	}
+	recipient, err := findRecipient(s, userID, configID)
+	if err != nil {
+		return err
+	}
+	if recipient == nil {
+		return ErrConfigNotFound
+	}
+	if verified, err := s.configVerified(configID, userID); err != nil {
+		return err
+	} else if !verified {
+		return errors.New("email configuration is not verified")
+	}
+
+	start, end := emailReportPeriod(settings.Frequency, time.Now(), loadLocation(recipient.Timezone))
+	report, err := s.buildEmailReport(userID, settings.Frequency, settings.Regions, start, end)
+	if err != nil {
+		return err
+	}
+	return s.sendEmailReport(*recipient, *report)
+}
+
+// FlushReports emails every report whose last period has not been delivered yet
+func (s *EmailService) FlushReports() error {
+	var schedules []emailReportSchedule
+	err := s.db.Select(&schedules, `
+        SELECT config_id, user_id, enabled, frequency, regions, last_sent_at, last_period_end
+        FROM email_report_schedules
+        WHERE enabled = true
+    `)
+	if err != nil {
+		return fmt.Errorf("failed to get email report schedules: %w", err)
+	}
+
+	now := time.Now()
+	for _, schedule := range schedules {
+		if err := s.flushReport(schedule, now); err != nil {
+			slog.Error("Failed to send email report", "config_id", schedule.ConfigID, "error", err)
+		}
+	}
+	return nil
+}
+
+// flushReport builds and sends the report of one config if its period is due
+func (s *EmailService) flushReport(schedule emailReportSchedule, now time.Time) error {
+	recipient, err := findRecipient(s, schedule.UserID, schedule.ConfigID)
+	if err != nil {
+		return err
+	}
+	if recipient == nil {
+		// Config no longer belongs to the user
+		_, err := s.db.Exec("DELETE FROM email_report_schedules WHERE config_id = $1", schedule.ConfigID)
+		return err
+	}
+
+	start, end := emailReportPeriod(schedule.Frequency, now, loadLocation(recipient.Timezone))
+	if schedule.LastPeriodEnd != nil && !schedule.LastPeriodEnd.Before(end) {
+		return nil
+	}
+
+	// Inactive configs skip the period instead of receiving it once reactivated
+	if recipient.IsActive {
+		report, err := s.buildEmailReport(schedule.UserID, schedule.Frequency, schedule.Regions, start, end)
+		if err != nil {
+			return err
+		}
+		if err := s.sendEmailReport(*recipient, *report); err != nil {
+			return err
+		}
+	}
+
+	_, err = s.db.Exec(`
+        UPDATE email_report_schedules SET last_period_end = $1, last_sent_at = CASE WHEN $2 THEN NOW() ELSE last_sent_at END
+        WHERE config_id = $3
+    `, end, recipient.IsActive, schedule.ConfigID)
+	if err != nil {
+		return fmt.Errorf("failed to update email report state: %w", err)
+	}
+	return nil
+}
+
+// emailReportPeriod returns the last complete week or month before now, in loc
+func emailReportPeriod(frequency string, now time.Time, loc *time.Location) (time.Time, time.Time) {
+	local := now.In(loc)
+	if frequency == model.EmailReportMonthly {
+		end := time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, loc)
+		return end.AddDate(0, -1, 0), end
+	}
+
+	daysSinceMonday := (int(local.Weekday()) + 6) % 7
+	end := time.Date(local.Year(), local.Month(), local.Day()-daysSinceMonday, 0, 0, 0, 0, loc)
+	return end.AddDate(0, 0, -7), end
+}
+
+// buildEmailReport aggregates uptime, incidents and response times of the user's
+// domains in the regions over the period
+func (s *EmailService) buildEmailReport(userID int, frequency string, regions []string, start, end time.Time) (*model.EmailReport, error) {
+	const domainFilter = `
+            d.user_id = $1 AND d.archived_at IS NULL AND d.deleted_at IS NULL
+            AND (cardinality($4::text[]) = 0 OR d.region = ANY($4))`
+	args := []interface{}{userID, start, end, pq.Array(regions)}
+
+	report := &model.EmailReport{
+		Frequency:        frequency,
+		PeriodStart:      start,
+		PeriodEnd:        end,
+		Domains:          []model.EmailReportDomain{},
+		LongestIncidents: []model.EmailReportIncident{},
+		SlowestRegions:   []model.EmailReportRegionTime{},
+	}
+
+	err := s.db.Select(&report.Domains, `
+        SELECT d.id AS domain_id, d.name, d.region,
+               COUNT(h.id) AS checks,
+               (100.0 * COUNT(h.id) FILTER (WHERE h.available) / NULLIF(COUNT(h.id), 0))::float8 AS uptime_percent,
+               (SELECT COUNT(*) FROM incidents i
+                WHERE i.domain_id = d.id AND i.opened_at >= $2 AND i.opened_at < $3) AS incidents
+        FROM domains d
+        LEFT JOIN domain_check_history h ON h.domain_id = d.id AND h.checked_at >= $2 AND h.checked_at < $3
+        WHERE `+domainFilter+`
+        GROUP BY d.id, d.name, d.region
+        ORDER BY uptime_percent ASC NULLS LAST, d.name
+    `, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate domain uptime: %w", err)
+	}
+
+	var counts struct {
+		Opened   int `db:"opened"`
+		Resolved int `db:"resolved"`
+	}
+	err = s.db.Get(&counts, `
+        SELECT COUNT(*) FILTER (WHERE i.opened_at >= $2 AND i.opened_at < $3) AS opened,
+               COUNT(*) FILTER (WHERE i.resolved_at >= $2 AND i.resolved_at < $3) AS resolved
+        FROM incidents i
+        JOIN domains d ON d.id = i.domain_id
+        WHERE `+domainFilter, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count incidents: %w", err)
+	}
+	report.IncidentsOpened = counts.Opened
+	report.IncidentsResolved = counts.Resolved
+
+	err = s.db.Select(&report.LongestIncidents, fmt.Sprintf(`
+        SELECT i.domain_name, i.opened_at, i.resolved_at,
+               EXTRACT(EPOCH FROM (LEAST(COALESCE(i.resolved_at, $3), $3) - i.opened_at))::int AS duration_seconds
+        FROM incidents i
+        JOIN domains d ON d.id = i.domain_id
+        WHERE i.opened_at >= $2 AND i.opened_at < $3 AND %s
+        ORDER BY duration_seconds DESC
+        LIMIT %d
+    `, domainFilter, emailReportLongestIncidents), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get incidents: %w", err)
+	}
+
+	err = s.db.Select(&report.SlowestRegions, fmt.Sprintf(`
+        SELECT d.region, AVG(h.total_time)::float8 AS avg_response_time, COUNT(*) AS checks
+        FROM domain_check_history h
+        JOIN domains d ON d.id = h.domain_id
+        WHERE h.checked_at >= $2 AND h.checked_at < $3 AND h.available AND h.total_time > 0
+            AND %s
+        GROUP BY d.region
+        ORDER BY avg_response_time DESC
+        LIMIT %d
+    `, domainFilter, emailReportSlowestRegions), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get slowest regions: %w", err)
+	}
+	return report, nil
+}
+
+// emailReportTemplate renders a report as an HTML email
+var emailReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"percent": func(p *float64) string {
+		if p == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%.2f%%", *p)
+	},
+	"duration": func(seconds int) string {
+		return (time.Duration(seconds) * time.Second).String()
+	},
+}).Parse(`<html><body style="font-family: Arial, sans-serif; color: #333">
+<h2>{{.Title}}</h2>
+<p>{{.Period}}</p>
+<p>Incidents: {{.Report.IncidentsOpened}} opened, {{.Report.IncidentsResolved}} resolved</p>
+<h3>Uptime per domain</h3>
+{{if .Report.Domains}}<table cellpadding="6" style="border-collapse: collapse" border="1">
+<tr><th>Domain</th><th>Region</th><th>Uptime</th><th>Checks</th><th>Incidents</th>{{if .Charts}}<th>Trend</th>{{end}}</tr>
+{{range .Report.Domains}}<tr><td>{{.Name}}</td><td>{{.Region}}</td><td>{{percent .UptimePercent}}</td><td>{{.Checks}}</td><td>{{.Incidents}}</td>{{if $.Charts}}<td>{{with index $.Charts .DomainID}}<img src="{{.}}" width="160" height="32" alt="Uptime and response time">{{end}}</td>{{end}}</tr>
+{{end}}</table>{{else}}<p>No domains in this report.</p>{{end}}
+{{if .Report.LongestIncidents}}<h3>Longest incidents</h3>
+<table cellpadding="6" style="border-collapse: collapse" border="1">
+<tr><th>Domain</th><th>Opened</th><th>Duration</th></tr>
+{{range .Incidents}}<tr><td>{{.DomainName}}</td><td>{{.OpenedAt}}</td><td>{{duration .DurationSeconds}}{{if not .Resolved}} (ongoing){{end}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Report.SlowestRegions}}<h3>Slowest regions</h3>
+<table cellpadding="6" style="border-collapse: collapse" border="1">
+<tr><th>Region</th><th>Average response time</th><th>Checks</th></tr>
+{{range .Report.SlowestRegions}}<tr><td>{{.Region}}</td><td>{{printf "%.0f" .AvgResponseTime}}ms</td><td>{{.Checks}}</td></tr>
+{{end}}</table>{{end}}
+<p style="color: #888; font-size: 12px">This is an automated report from your Domain Monitoring Service.</p>
+</body></html>`))
+
+// emailReportIncidentRow is an incident with its opening time formatted for the recipient
+type emailReportIncidentRow struct {
+	DomainName      string
+	OpenedAt        string
+	DurationSeconds int
+	Resolved        bool
+}
+
+// sendEmailReport renders a report in the recipient's timezone and emails it
+func (s *EmailService) sendEmailReport(recipient Recipient, report model.EmailReport) error {
+	loc := loadLocation(recipient.Timezone)
+	title := "Weekly domain monitoring report"
+	if report.Frequency == model.EmailReportMonthly {
+		title = "Monthly domain monitoring report"
+	}
+
+	incidents := make([]emailReportIncidentRow, 0, len(report.LongestIncidents))
+	for _, i := range report.LongestIncidents {
+		incidents = append(incidents, emailReportIncidentRow{
+			DomainName:      i.DomainName,
+			OpenedAt:        formatLocalTime(i.OpenedAt, recipient.Timezone, "2006-01-02 15:04"),
+			DurationSeconds: i.DurationSeconds,
+			Resolved:        i.ResolvedAt != nil && i.ResolvedAt.Before(report.PeriodEnd),
+		})
+	}
+
+	// The domains with the worst uptime get a chart of the period
+	var attachments []EmailAttachment
+	charts := make(map[int]template.URL)
+	for _, d := range report.Domains {
+		if len(charts) == emailReportCharts {
+			break
+		}
+		if d.Checks == 0 {
+			continue
+		}
+		sparkline, err := s.domainSparkline(d.DomainID, fmt.Sprintf("sparkline-%d", d.DomainID), report.PeriodStart, report.PeriodEnd)
+		if err != nil {
+			slog.Warn("Failed to render sparkline for the email report", "domain_id", d.DomainID, "error", err)
+			continue
+		}
+		if sparkline != nil {
+			attachments = append(attachments, *sparkline)
+			charts[d.DomainID] = inlineImageSrc(*sparkline)
+		}
+	}
+
+	var body bytes.Buffer
+	err := emailReportTemplate.Execute(&body, struct {
+		Title     string
+		Period    string
+		Report    model.EmailReport
+		Incidents []emailReportIncidentRow
+		Charts    map[int]template.URL
+	}{
+		Title:     title,
+		Period:    fmt.Sprintf("%s - %s", report.PeriodStart.In(loc).Format("2006-01-02"), report.PeriodEnd.In(loc).AddDate(0, 0, -1).Format("2006-01-02")),
+		Report:    report,
+		Incidents: incidents,
+		Charts:    charts,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render email report: %w", err)
+	}
+
+	subject := fmt.Sprintf("%s: %s", title, report.PeriodStart.In(loc).Format("2006-01-02"))
+	if err := s.sendEmail(recipient.Address, subject, body.String(), attachments...); err != nil {
+		return fmt.Errorf("failed to send email report to %s: %w", recipient.Label, err)
+	}
+	return nil
+}
+
+// RunScheduledEmailReports sends due weekly and monthly email reports once an hour
+func RunScheduledEmailReports(ctx context.Context, email *EmailService) {
+	slog.Info("RunScheduledEmailReports")
+	ticker := time.NewTicker(1 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("RunScheduledEmailReports stopped")
+			return
+		case <-ticker.C:
+			if err := email.FlushReports(); err != nil {
+				slog.Error("Email report flush failed", "error", err)
+			}
+		}
+	}
+}
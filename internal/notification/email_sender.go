@@ -0,0 +1,44 @@
+package notification
+
+import (
+	"strings"
+)
+
+// Email providers, selected through EmailConfig.Provider
+const (
+	EmailProviderSMTP     = "smtp"
+	EmailProviderSendGrid = "sendgrid"
+	EmailProviderSES      = "ses"
+)
+
+// EmailMessage is an HTML email ready to be delivered
+type EmailMessage struct {
+	From      string // Sender address
+	FromName  string // Display name of the sender, used by the API providers
+	To        string
+	Subject   string
+	HTML      string
+	MessageID string // Message-ID header value, providers that assign their own ignore it
+
+	Attachments []EmailAttachment
+}
+
+// EmailSender delivers emails through a mail provider
+type EmailSender interface {
+	// Send delivers a message and returns the ID the provider knows it by
+	Send(msg EmailMessage) (string, error)
+	// Close releases connections kept open between messages
+	Close() error
+}
+
+// newEmailSender returns the sender of the configured provider, SMTP by default
+func newEmailSender(config EmailConfig) EmailSender {
+	switch strings.ToLower(config.Provider) {
+	case EmailProviderSendGrid:
+		return newSendGridSender(config.SendGridAPIKey)
+	case EmailProviderSES:
+		return newSESSender(config.SESRegion, config.SESAccessKeyID, config.SESSecretAccessKey)
+	default:
+		return newSMTPSender(config)
+	}
+}
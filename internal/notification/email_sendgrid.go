@@ -0,0 +1,118 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"domain-detection-go/internal/outbound"
+)
+
+// sendGridURL is the SendGrid v3 mail send endpoint
+const sendGridURL = "https://api.sendgrid.com/v3/mail/send"
+
+// sendGridSender delivers emails through the SendGrid API
+type sendGridSender struct {
+	apiKey string
+	client *outbound.Client
+}
+
+func newSendGridSender(apiKey string) *sendGridSender {
+	return &sendGridSender{
+		apiKey: apiKey,
+		client: outbound.NewClient(outbound.Config{
+			Name:       "SendGrid",
+			Timeout:    15 * time.Second,
+			MaxRetries: 2,
+		}),
+	}
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+type sendGridAttachment struct {
+	Content     string `json:"content"` // Base64 encoded
+	Filename    string `json:"filename"`
+	Type        string `json:"type,omitempty"`
+	Disposition string `json:"disposition"`
+	ContentID   string `json:"content_id,omitempty"`
+}
+
+type sendGridMail struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+	Attachments      []sendGridAttachment      `json:"attachments,omitempty"`
+}
+
+// Send implements EmailSender. SendGrid assigns the message ID.
+func (s *sendGridSender) Send(msg EmailMessage) (string, error) {
+	mail := sendGridMail{
+		Personalizations: []sendGridPersonalization{{To: []sendGridAddress{{Email: msg.To}}}},
+		From:             sendGridAddress{Email: msg.From, Name: msg.FromName},
+		Subject:          msg.Subject,
+		Content:          []sendGridContent{{Type: "text/html", Value: msg.HTML}},
+	}
+	for _, attachment := range msg.Attachments {
+		disposition := "attachment"
+		if attachment.ContentID != "" {
+			disposition = "inline"
+		}
+		mail.Attachments = append(mail.Attachments, sendGridAttachment{
+			Content:     base64.StdEncoding.EncodeToString(attachment.Data),
+			Filename:    attachment.Filename,
+			Type:        attachment.ContentType,
+			Disposition: disposition,
+			ContentID:   attachment.ContentID,
+		})
+	}
+
+	body, err := json.Marshal(mail)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode email: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, sendGridURL, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send email: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("failed to send email: SendGrid returned %s: %s", resp.Status, detail)
+	}
+
+	if id := resp.Header.Get("X-Message-Id"); id != "" {
+		return id, nil
+	}
+	return msg.MessageID, nil
+}
+
+// Close implements EmailSender
+func (s *sendGridSender) Close() error {
+	return nil
+}
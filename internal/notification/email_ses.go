@@ -0,0 +1,196 @@
+package notification
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+
+	"domain-detection-go/internal/outbound"
+)
+
+// sesSender delivers emails through the Amazon SES v2 API, signing requests with AWS
+// Signature Version 4
+type sesSender struct {
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+	client          *outbound.Client
+}
+
+func newSESSender(region, accessKeyID, secretAccessKey string) *sesSender {
+	return &sesSender{
+		region:          region,
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+		client: outbound.NewClient(outbound.Config{
+			Name:       "SES",
+			Timeout:    15 * time.Second,
+			MaxRetries: 2,
+		}),
+	}
+}
+
+type sesContent struct {
+	Data    string `json:"Data"`
+	Charset string `json:"Charset"`
+}
+
+type sesSimpleContent struct {
+	Subject sesContent `json:"Subject"`
+	Body    struct {
+		HTML sesContent `json:"Html"`
+	} `json:"Body"`
+}
+
+type sesRawContent struct {
+	Data []byte `json:"Data"` // Full MIME message, base64 encoded by encoding/json
+}
+
+type sesEmail struct {
+	FromEmailAddress string `json:"FromEmailAddress"`
+	Destination      struct {
+		ToAddresses []string `json:"ToAddresses"`
+	} `json:"Destination"`
+	Content struct {
+		Simple *sesSimpleContent `json:"Simple,omitempty"`
+		Raw    *sesRawContent    `json:"Raw,omitempty"`
+	} `json:"Content"`
+}
+
+// Send implements EmailSender. SES assigns the message ID.
+func (s *sesSender) Send(msg EmailMessage) (string, error) {
+	var email sesEmail
+	email.FromEmailAddress = msg.From
+	if msg.FromName != "" {
+		email.FromEmailAddress = (&mail.Address{Name: msg.FromName, Address: msg.From}).String()
+	}
+	email.Destination.ToAddresses = []string{msg.To}
+	if len(msg.Attachments) > 0 {
+		// Simple content has no attachments, so the MIME message is sent as is
+		raw := msg
+		raw.From = email.FromEmailAddress
+		email.Content.Raw = &sesRawContent{Data: buildMIME(raw)}
+	} else {
+		simple := &sesSimpleContent{Subject: sesContent{Data: msg.Subject, Charset: "UTF-8"}}
+		simple.Body.HTML = sesContent{Data: msg.HTML, Charset: "UTF-8"}
+		email.Content.Simple = simple
+	}
+
+	body, err := json.Marshal(email)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode email: %w", err)
+	}
+	host := "email." + s.region + ".amazonaws.com"
+	req, err := http.NewRequest(http.MethodPost, "https://"+host+"/v2/email/outbound-emails", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	s.sign(req, host, body, time.Now().UTC())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send email: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to send email: SES returned %s: %s", resp.Status, respBody)
+	}
+
+	var result struct {
+		MessageID string `json:"MessageId"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil || result.MessageID == "" {
+		return msg.MessageID, nil
+	}
+	return result.MessageID, nil
+}
+
+// sign adds the Signature Version 4 headers of the SES service to a request
+func (s *sesSender) sign(req *http.Request, host string, body []byte, now time.Time) {
+	req.Host = host
+	req.Header.Set("X-Amz-Content-Sha256", sha256Hex(body))
+	signV4(req, body, "ses", s.region, s.accessKeyID, s.secretAccessKey, now)
+}
+
+// signV4 signs a request with AWS Signature Version 4. Every header already set on the
+// request is signed along with Host and the X-Amz-Date header it adds.
+func signV4(req *http.Request, body []byte, service, region, accessKeyID, secretAccessKey string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		sha256Hex(body),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+	signature := hex.EncodeToString(hmacSHA256(signingKeyV4(secretAccessKey, date, region, service), stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, scope, signedHeaders, signature))
+}
+
+// signingKeyV4 derives the Signature Version 4 key of a day, region and service
+func signingKeyV4(secretAccessKey, date, region, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+// Close implements EmailSender
+func (s *sesSender) Close() error {
+	return nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,62 @@
+package notification
+
+import (
+	"encoding/hex"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// Credentials and request of the AWS Signature Version 4 test suite
+const (
+	sigV4TestAccessKeyID     = "AKIDEXAMPLE"
+	sigV4TestSecretAccessKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+)
+
+func TestSigningKeyV4(t *testing.T) {
+	// Example of the AWS documentation on deriving a signing key
+	key := signingKeyV4(sigV4TestSecretAccessKey, "20120215", "us-east-1", "iam")
+	want := "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d"
+	if got := hex.EncodeToString(key); got != want {
+		t.Errorf("signing key = %s, want %s", got, want)
+	}
+}
+
+func TestSignV4GetVanilla(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	signV4(req, nil, "service", "us-east-1", sigV4TestAccessKeyID, sigV4TestSecretAccessKey, now)
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, " +
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %s\nwant %s", got, want)
+	}
+	if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+		t.Errorf("X-Amz-Date = %s, want 20150830T123600Z", got)
+	}
+}
+
+func TestSESSignSignsPayload(t *testing.T) {
+	sender := newSESSender("eu-west-1", sigV4TestAccessKeyID, sigV4TestSecretAccessKey)
+	host := "email.eu-west-1.amazonaws.com"
+	req, err := http.NewRequest(http.MethodPost, "https://"+host+"/v2/email/outbound-emails", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	sender.sign(req, host, []byte(`{}`), time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	if got, want := req.Header.Get("X-Amz-Content-Sha256"), sha256Hex([]byte(`{}`)); got != want {
+		t.Errorf("X-Amz-Content-Sha256 = %s, want %s", got, want)
+	}
+	wantPrefix := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20240102/eu-west-1/ses/aws4_request, " +
+		"SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date, Signature="
+	if got := req.Header.Get("Authorization"); len(got) != len(wantPrefix)+64 || got[:len(wantPrefix)] != wantPrefix {
+		t.Errorf("Authorization = %s, want prefix %s and a 64 character signature", got, wantPrefix)
+	}
+}
@@ -0,0 +1,193 @@
+package notification
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+// smtpIdleTimeout is how long an idle SMTP session is kept for the next message. Servers
+// commonly drop sessions idle for longer.
+const smtpIdleTimeout = 30 * time.Second
+
+// defaultSMTPPoolSize is how many idle SMTP sessions are kept when the config sets none
+const defaultSMTPPoolSize = 2
+
+// smtpSession is an open SMTP connection and when it was last used
+type smtpSession struct {
+	client   *smtp.Client
+	lastUsed time.Time
+}
+
+// smtpSender delivers emails over SMTP with PLAIN auth, reusing sessions between
+// messages instead of connecting for each one. Port 465 uses implicit TLS, other ports
+// require STARTTLS unless plaintext is allowed. A server that strips STARTTLS or AUTH
+// is refused rather than sent to in plaintext or without authentication.
+type smtpSender struct {
+	host      string
+	port      string
+	username  string
+	password  string
+	plaintext bool
+	idle      chan *smtpSession
+}
+
+func newSMTPSender(config EmailConfig) *smtpSender {
+	poolSize := config.SMTPPoolSize
+	if poolSize <= 0 {
+		poolSize = defaultSMTPPoolSize
+	}
+	return &smtpSender{
+		host:      config.SMTPHost,
+		port:      config.SMTPPort,
+		username:  config.SMTPUsername,
+		password:  config.SMTPPassword,
+		plaintext: config.SMTPPlaintext,
+		idle:      make(chan *smtpSession, poolSize),
+	}
+}
+
+// Send implements EmailSender. A message failing on a reused session is retried once on
+// a new one, as the server may have closed it in the meantime.
+func (s *smtpSender) Send(msg EmailMessage) (string, error) {
+	data := buildMIME(msg)
+
+	session, reused := s.get()
+	err := s.deliver(session, msg.From, msg.To, data)
+	if err != nil && reused {
+		session, err = s.dial()
+		if err == nil {
+			err = s.deliver(session, msg.From, msg.To, data)
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	return msg.MessageID, nil
+}
+
+// deliver sends one message on a session and returns the session to the pool. Failed
+// sessions are closed.
+func (s *smtpSender) deliver(session *smtpSession, from, to string, data []byte) error {
+	if session == nil {
+		var err error
+		if session, err = s.dial(); err != nil {
+			return err
+		}
+	}
+
+	err := sendOnSession(session.client, from, to, data)
+	if err != nil {
+		session.client.Close()
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	s.put(session)
+	return nil
+}
+
+// sendOnSession runs one mail transaction
+func sendOnSession(client *smtp.Client, from, to string, data []byte) error {
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		client.Reset()
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// get returns an idle session that is still fresh, or nil when a new one is needed
+func (s *smtpSender) get() (*smtpSession, bool) {
+	for {
+		select {
+		case session := <-s.idle:
+			if time.Since(session.lastUsed) < smtpIdleTimeout {
+				return session, true
+			}
+			session.client.Close()
+		default:
+			return nil, false
+		}
+	}
+}
+
+// put keeps a session for the next message, or closes it when the pool is full
+func (s *smtpSender) put(session *smtpSession) {
+	session.lastUsed = time.Now()
+	select {
+	case s.idle <- session:
+	default:
+		session.client.Quit()
+	}
+}
+
+// dial opens and authenticates a new session
+func (s *smtpSender) dial() (*smtpSession, error) {
+	addr := net.JoinHostPort(s.host, s.port)
+	tlsConfig := &tls.Config{ServerName: s.host}
+
+	var client *smtp.Client
+	if s.port == "465" {
+		conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 10 * time.Second}, "tcp", addr, tlsConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+		}
+		if client, err = smtp.NewClient(conn, s.host); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to start SMTP session: %w", err)
+		}
+	} else {
+		conn, err := net.DialTimeout("tcp", addr, 10*time.Second)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+		}
+		if client, err = smtp.NewClient(conn, s.host); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to start SMTP session: %w", err)
+		}
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				client.Close()
+				return nil, fmt.Errorf("failed to start TLS: %w", err)
+			}
+		} else if !s.plaintext {
+			client.Close()
+			return nil, fmt.Errorf("SMTP server %s does not support STARTTLS", addr)
+		}
+	}
+
+	if s.username != "" {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			client.Close()
+			return nil, fmt.Errorf("SMTP server %s does not support AUTH", addr)
+		}
+		if err := client.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+	return &smtpSession{client: client}, nil
+}
+
+// Close implements EmailSender
+func (s *smtpSender) Close() error {
+	for {
+		select {
+		case session := <-s.idle:
+			session.client.Quit()
+		default:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package notification
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeSMTPServer accepts SMTP sessions offering neither STARTTLS nor AUTH and records
+// the messages it receives
+type fakeSMTPServer struct {
+	listener  net.Listener
+	mu        sync.Mutex
+	conns     []net.Conn
+	accepted  int
+	delivered []string
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeSMTPServer{listener: listener}
+	go s.serve()
+	t.Cleanup(func() {
+		listener.Close()
+		s.closeSessions()
+	})
+	return s
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns = append(s.conns, conn)
+		s.accepted++
+		s.mu.Unlock()
+		go s.session(conn)
+	}
+}
+
+func (s *fakeSMTPServer) session(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 fake ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		verb := strings.ToUpper(strings.Fields(line + " x")[0])
+		switch verb {
+		case "EHLO":
+			reply("250 fake")
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			s.mu.Lock()
+			s.delivered = append(s.delivered, data.String())
+			s.mu.Unlock()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+// closeSessions drops every open session, as a server does with idle ones
+func (s *fakeSMTPServer) closeSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+func (s *fakeSMTPServer) config() EmailConfig {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return EmailConfig{SMTPHost: host, SMTPPort: port, SMTPPlaintext: true}
+}
+
+func (s *fakeSMTPServer) counts() (accepted, delivered int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.accepted, len(s.delivered)
+}
+
+func TestSMTPSenderRetriesOnClosedSession(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	sender := newSMTPSender(server.config())
+	defer sender.Close()
+
+	msg := EmailMessage{From: "alerts@example.com", To: "user@example.com", Subject: "Down", HTML: "<p>down</p>"}
+	if _, err := sender.Send(msg); err != nil {
+		t.Fatalf("first send: %v", err)
+	}
+	if accepted, delivered := server.counts(); accepted != 1 || delivered != 1 {
+		t.Fatalf("after first send: %d sessions, %d messages, want 1 and 1", accepted, delivered)
+	}
+
+	// The pooled session is gone; the next message goes out on a new one
+	server.closeSessions()
+	if _, err := sender.Send(msg); err != nil {
+		t.Fatalf("send after the session was closed: %v", err)
+	}
+	if accepted, delivered := server.counts(); accepted != 2 || delivered != 2 {
+		t.Errorf("after second send: %d sessions, %d messages, want 2 and 2", accepted, delivered)
+	}
+
+	// The new session is reused
+	if _, err := sender.Send(msg); err != nil {
+		t.Fatalf("third send: %v", err)
+	}
+	if accepted, delivered := server.counts(); accepted != 2 || delivered != 3 {
+		t.Errorf("after third send: %d sessions, %d messages, want 2 and 3", accepted, delivered)
+	}
+}
+
+func TestSMTPSenderRefusesDowngrades(t *testing.T) {
+	tests := []struct {
+		name   string
+		config func(EmailConfig) EmailConfig
+		want   string
+	}{
+		{
+			name: "no STARTTLS",
+			config: func(c EmailConfig) EmailConfig {
+				c.SMTPPlaintext = false
+				return c
+			},
+			want: "does not support STARTTLS",
+		},
+		{
+			name: "credentials without AUTH",
+			config: func(c EmailConfig) EmailConfig {
+				c.SMTPUsername, c.SMTPPassword = "user", "secret"
+				return c
+			},
+			want: "does not support AUTH",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeSMTPServer(t)
+			sender := newSMTPSender(tt.config(server.config()))
+			defer sender.Close()
+
+			_, err := sender.Send(EmailMessage{From: "alerts@example.com", To: "user@example.com"})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Send error = %v, want %q", err, tt.want)
+			}
+			if _, delivered := server.counts(); delivered != 0 {
+				t.Errorf("%d messages delivered, want 0", delivered)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package notification
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"time"
+)
+
+// emailVerificationTTL is how long a code mailed to a pending email config can be confirmed
+const emailVerificationTTL = 24 * time.Hour
+
+// sendVerification issues a new code for a pending email config and mails it to the
+// config's address. Codes sent before are replaced.
+func (s *EmailService) sendVerification(configID int) error {
+	raw := make([]byte, 5)
+	if _, err := rand.Read(raw); err != nil {
+		return fmt.Errorf("failed to generate verification code: %w", err)
+	}
+	code := linkCodeEncoding.EncodeToString(raw)
+	code = code[:4] + "-" + code[4:]
+
+	var address string
+	err := s.db.Get(&address, `
+        UPDATE email_configs
+        SET verification_code_hash = $1, verification_expires_at = NOW() + make_interval(secs => $2)
+        WHERE id = $3 AND verified_at IS NULL
+        RETURNING email_address
+    `, hashLinkCode(code), emailVerificationTTL.Seconds(), configID)
+	if err != nil {
+		return fmt.Errorf("failed to store verification code: %w", err)
+	}
+
+	subject := "✉️ Confirm your email address for Domain Monitor"
+	body := `
+	<!DOCTYPE html>
+	<html>
+	<head>
+		<meta charset="UTF-8">
+		<title>Confirm your email address</title>
+	</head>
+	<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+		<div style="max-width: 600px; margin: 0 auto; padding: 20px;">
+			<h2 style="color: #3498db;">✉️ Confirm your email address</h2>
+			<p>This address was added to receive domain alerts from your Domain Monitoring Service.</p>
+			<p>Enter this code in the notification settings to start receiving them:</p>
+			<div style="background-color: #f8f9fa; padding: 15px; border-radius: 5px; margin: 20px 0; text-align: center;">
+				<p style="font-size: 24px; letter-spacing: 4px;"><strong>` + html.EscapeString(code) + `</strong></p>
+			</div>
+			<p>The code expires in 24 hours. If you did not expect this email, you can ignore it and no alerts will be sent to you.</p>
+		</div>
+	</body>
+	</html>`
+
+	return s.sendEmail(address, subject, body)
+}
+
+// ResendVerification mails a new code to a pending email config of the user
+func (s *EmailService) ResendVerification(configID, userID int) error {
+	verified, err := s.configVerified(configID, userID)
+	if err != nil {
+		return err
+	}
+	if verified {
+		return ErrAlreadyVerified
+	}
+	return s.sendVerification(configID)
+}
+
+// VerifyEmailConfig confirms a pending email config of the user with the code mailed to
+// it. From then on the config is notified.
+func (s *EmailService) VerifyEmailConfig(configID, userID int, code string) error {
+	verified, err := s.configVerified(configID, userID)
+	if err != nil {
+		return err
+	}
+	if verified {
+		return ErrAlreadyVerified
+	}
+
+	result, err := s.db.Exec(`
+        UPDATE email_configs
+        SET verified_at = NOW(), verification_code_hash = NULL, verification_expires_at = NULL, updated_at = NOW()
+        WHERE id = $1 AND user_id = $2 AND verified_at IS NULL
+          AND verification_code_hash = $3 AND verification_expires_at > NOW()
+    `, configID, userID, hashLinkCode(code))
+	if err != nil {
+		return fmt.Errorf("failed to verify email configuration: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrInvalidVerificationCode
+	}
+	return nil
+}
+
+// configVerified reports whether an email config of the user has been confirmed
+func (s *EmailService) configVerified(configID, userID int) (bool, error) {
+	var verifiedAt *time.Time
+	err := s.db.Get(&verifiedAt, "SELECT verified_at FROM email_configs WHERE id = $1 AND user_id = $2", configID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, ErrConfigNotFound
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get email configuration: %w", err)
+	}
+	return verifiedAt != nil, nil
+}
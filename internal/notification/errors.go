@@ -0,0 +1,37 @@
+package notification
+
+import (
+	"errors"
+
+	"domain-detection-go/internal/domain"
+)
+
+// Errors returned by the notification services for requests that cannot be applied.
+// Handlers match them with errors.Is to choose the response.
+var (
+	ErrConfigNotFound     = errors.New("configuration not found")
+	ErrAPIKeyRequired     = errors.New("api key is required")
+	ErrNotificationAbsent = errors.New("notification not found")
+	ErrAlreadyDelivered   = errors.New("notification was delivered")
+	ErrNotResendable      = errors.New("notification cannot be resent")
+
+	ErrUnsupportedChannel      = errors.New("unsupported channel")
+	ErrRoutingRuleNotFound     = errors.New("routing rule not found")
+	ErrEscalationRuleNotFound  = errors.New("escalation rule not found")
+	ErrAlreadyVerified         = errors.New("email configuration already verified")
+	ErrInvalidVerificationCode = errors.New("invalid or expired verification code")
+	ErrInvalidLinkCode         = errors.New("invalid or expired link code")
+	ErrChatLinkedElsewhere     = errors.New("chat is linked to another account")
+
+	// ErrStatusPageConfigNotFound is returned for status page configs of other users too
+	ErrStatusPageConfigNotFound = errors.New("configuration not found or not owned by user")
+)
+
+// The domain package is referenced through these because notifiers name their domain
+// values "domain"
+var (
+	// errDomainNotFound is returned for domains the user does not own
+	errDomainNotFound = domain.ErrDomainNotFound
+	// errInvalidRegion is returned for regions that are not monitored
+	errInvalidRegion = domain.ErrInvalidRegion
+)
@@ -0,0 +1,226 @@
+package notification
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"domain-detection-go/pkg/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// NotificationTypeEscalation is a down alert sent to an escalation config because the
+// outage outlasted the rule's delay
+const NotificationTypeEscalation = "escalation"
+
+// MaintenanceChecker reports whether a domain is in a planned maintenance window
+type MaintenanceChecker interface {
+	InMaintenance(domain model.Domain, t time.Time) (bool, error)
+}
+
+// dueEscalation is an escalation rule whose delay has passed for an open incident
+type dueEscalation struct {
+	IncidentID int    `db:"incident_id"`
+	RuleID     int    `db:"rule_id"`
+	Level      int    `db:"level"`
+	Channel    string `db:"channel"`
+	ConfigID   int    `db:"config_id"`
+	DomainID   int    `db:"domain_id"`
+}
+
+// EscalationService manages escalation rules and alerts extra configs about
+// prolonged outages
+type EscalationService struct {
+	db          *sqlx.DB
+	notifiers   *Fanout
+	maintenance MaintenanceChecker
+}
+
+// NewEscalationService creates a new escalation service
+func NewEscalationService(db *sqlx.DB, notifiers *Fanout, maintenance MaintenanceChecker) *EscalationService {
+	return &EscalationService{
+		db:          db,
+		notifiers:   notifiers,
+		maintenance: maintenance,
+	}
+}
+
+// GetRules returns every escalation rule of a user
+func (s *EscalationService) GetRules(userID int) ([]model.EscalationRule, error) {
+	rules := []model.EscalationRule{}
+	err := s.db.Select(&rules, `
+        SELECT id, user_id, level, after_minutes, channel, config_id, created_at
+        FROM escalation_rules
+        WHERE user_id = $1
+        ORDER BY level, channel, config_id
+    `, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get escalation rules: %w", err)
+	}
+	return rules, nil
+}
+
+// AddRule adds an escalation level target for a config owned by the user
+func (s *EscalationService) AddRule(userID int, req model.EscalationRuleRequest) (int, error) {
+	n, ok := s.notifiers.Get(req.Channel)
+	if !ok {
+		return 0, ErrUnsupportedChannel
+	}
+	if err := checkConfigOwner(n, req.ConfigID, userID); err != nil {
+		return 0, err
+	}
+
+	var ruleID int
+	err := s.db.Get(&ruleID, `
+        INSERT INTO escalation_rules (user_id, level, after_minutes, channel, config_id, created_at)
+        VALUES ($1, $2, $3, $4, $5, NOW())
+        ON CONFLICT (user_id, level, channel, config_id) DO UPDATE SET after_minutes = EXCLUDED.after_minutes
+        RETURNING id
+    `, userID, req.Level, req.AfterMinutes, req.Channel, req.ConfigID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add escalation rule: %w", err)
+	}
+	return ruleID, nil
+}
+
+// DeleteRule removes an escalation rule owned by the user
+func (s *EscalationService) DeleteRule(userID, ruleID int) error {
+	var id int
+	err := s.db.Get(&id, "DELETE FROM escalation_rules WHERE id = $1 AND user_id = $2 RETURNING id", ruleID, userID)
+	if err == sql.ErrNoRows {
+		return ErrEscalationRuleNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete escalation rule: %w", err)
+	}
+	return nil
+}
+
+// RunScheduledEscalations escalates prolonged outages once a minute
+func (s *EscalationService) RunScheduledEscalations(ctx context.Context) {
+	slog.Info("RunScheduledEscalations")
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("RunScheduledEscalations stopped")
+			return
+		case <-ticker.C:
+			if err := s.EscalateOpenIncidents(); err != nil {
+				slog.Error("Escalation run failed", "error", err)
+			}
+		}
+	}
+}
+
+// EscalateOpenIncidents sends every escalation that is due. Each rule fires at most
+// once per incident; a recovery resolves the incident and stops further levels, and
+// an acknowledgement holds them back until it expires.
+func (s *EscalationService) EscalateOpenIncidents() error {
+	var due []dueEscalation
+	err := s.db.Select(&due, `
+        SELECT i.id AS incident_id, r.id AS rule_id, r.level, r.channel, r.config_id, i.domain_id
+        FROM incidents i
+        JOIN domains d ON d.id = i.domain_id
+        JOIN escalation_rules r ON r.user_id = i.user_id
+        WHERE i.resolved_at IS NULL
+          AND d.active = true AND d.archived_at IS NULL
+          AND (i.acknowledged_until IS NULL OR i.acknowledged_until <= NOW())
+          AND (d.snoozed_until IS NULL OR d.snoozed_until <= NOW())
+          AND i.opened_at <= NOW() - make_interval(mins => r.after_minutes)
+          AND NOT EXISTS (
+              SELECT 1 FROM incident_escalations e WHERE e.incident_id = i.id AND e.rule_id = r.id)
+        ORDER BY i.id, r.level
+    `)
+	if err != nil {
+		return fmt.Errorf("failed to get due escalations: %w", err)
+	}
+
+	for _, e := range due {
+		if err := s.escalate(e); err != nil {
+			slog.Error("Failed to escalate incident", "incident_id", e.IncidentID, "channel", e.Channel, "config_id", e.ConfigID, "error", err)
+		}
+	}
+	return nil
+}
+
+// escalate sends one due escalation
+func (s *EscalationService) escalate(e dueEscalation) error {
+	var domain model.Domain
+	err := s.db.Get(&domain, `
+        SELECT id, user_id, name, region, last_status, error_code, error_description, total_time, last_check
+        FROM domains
+        WHERE id = $1
+    `, e.DomainID)
+	if err != nil {
+		return fmt.Errorf("failed to get domain: %w", err)
+	}
+
+	inMaintenance, err := s.maintenance.InMaintenance(domain, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to check maintenance windows: %w", err)
+	}
+	if inMaintenance {
+		// Checked again next run; the incident may outlast the window
+		return nil
+	}
+
+	var incident model.Incident
+	err = s.db.Get(&incident, `
+        SELECT id, domain_id, user_id, domain_name, opened_at, resolved_at, duration_seconds,
+               failure_count, first_status, last_status, last_error, last_failure_at,
+               acknowledged_at, acknowledged_by, acknowledged_until
+        FROM incidents
+        WHERE id = $1
+    `, e.IncidentID)
+	if err != nil {
+		return fmt.Errorf("failed to get incident: %w", err)
+	}
+	domain.Incident = &incident
+
+	// Claim the escalation first so concurrent runs never send it twice
+	result, err := s.db.Exec(`
+        INSERT INTO incident_escalations (incident_id, rule_id) VALUES ($1, $2)
+        ON CONFLICT DO NOTHING
+    `, e.IncidentID, e.RuleID)
+	if err != nil {
+		return fmt.Errorf("failed to record escalation: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err != nil || rows == 0 {
+		return err
+	}
+
+	n, ok := s.notifiers.Get(e.Channel)
+	if !ok {
+		return ErrUnsupportedChannel
+	}
+	recipient, err := findRecipient(n, domain.UserID, e.ConfigID)
+	if err != nil {
+		return err
+	}
+	if recipient == nil || !recipient.IsActive {
+		slog.Warn("Skipping escalation, config is missing or inactive", "incident_id", e.IncidentID, "channel", e.Channel, "config_id", e.ConfigID)
+		return nil
+	}
+	if recipient.Language == "" {
+		recipient.Language = "en"
+	}
+
+	formattedTime := formatLocalTime(domain.LastCheck, recipient.Timezone, "2006-01-02 15:04:05")
+
+	messageID, err := n.Send(*recipient, NotificationTypeEscalation, domain, formattedTime)
+	if err != nil {
+		return fmt.Errorf("failed to send escalation to %s: %w", recipient.Label, err)
+	}
+	slog.Info("Escalated incident", "incident_id", e.IncidentID, "domain", domain.Name, "level", e.Level, "recipient", recipient.Label)
+
+	if err := recordHistory(s.db, n.HistoryColumn(), domain, e.ConfigID, NotificationTypeEscalation, &incident.ID, messageID, nil); err != nil {
+		slog.Error("Failed to record escalation history", "channel", e.Channel, "error", err)
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package notification
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"domain-detection-go/internal/etag"
+	"domain-detection-go/pkg/model"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// configState is the client-managed part of a notification config that its ETag covers
+type configState struct {
+	Target         string   `json:"target"` // Chat ID or email address
+	Name           string   `json:"name"`
+	Language       string   `json:"language"`
+	NotifyOnDown   bool     `json:"notify_on_down"`
+	NotifyOnUp     bool     `json:"notify_on_up"`
+	IsActive       bool     `json:"is_active"`
+	MonitorRegions []string `json:"monitor_regions"`
+}
+
+func (c configState) etag() string {
+	if c.Language == "" {
+		c.Language = "en"
+	}
+	regions := append([]string{}, c.MonitorRegions...)
+	sort.Strings(regions)
+	c.MonitorRegions = regions
+	return etag.Compute(c)
+}
+
+// TelegramConfigETag returns the ETag of a Telegram config
+func TelegramConfigETag(cfg model.TelegramConfig) string {
+	return configState{cfg.ChatID, cfg.ChatName, cfg.Language, cfg.NotifyOnDown, cfg.NotifyOnUp, cfg.IsActive, cfg.MonitorRegions}.etag()
+}
+
+// EmailConfigETag returns the ETag of an email config
+func EmailConfigETag(cfg model.EmailConfig) string {
+	return configState{cfg.EmailAddress, cfg.EmailName, cfg.Language, cfg.NotifyOnDown, cfg.NotifyOnUp, cfg.IsActive, cfg.MonitorRegions}.etag()
+}
+
+// setExternalID tags a newly created config of a channel with its external ID. The config
+// is removed again if another request claimed the external ID in the meantime.
+func (d *Dispatcher) setExternalID(channel string, configID, userID int, externalID string) error {
+	_, err := d.db.Exec(fmt.Sprintf("UPDATE %s_configs SET external_id = $1 WHERE id = $2", channel), externalID, configID)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		d.db.Exec(fmt.Sprintf("DELETE FROM %s_configs WHERE id = $1 AND user_id = $2", channel), configID, userID)
+		return etag.ErrPreconditionFailed
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set external ID: %w", err)
+	}
+	return nil
+}
+
+// GetTelegramConfigByExternalID returns the user's Telegram config with the given external ID,
+// or nil if none exists
+func (s *TelegramService) GetTelegramConfigByExternalID(userID int, externalID string) (*model.TelegramConfig, error) {
+	return telegramConfigByExternalID(s.db, userID, externalID, "")
+}
+
+// telegramConfigByExternalID loads a Telegram config through q. lock is appended to the
+// config query, e.g. "FOR UPDATE" to hold the row until the transaction ends.
+func telegramConfigByExternalID(q sqlx.Queryer, userID int, externalID, lock string) (*model.TelegramConfig, error) {
+	var cfg model.TelegramConfig
+	err := sqlx.Get(q, &cfg, `
+        SELECT id, user_id, chat_id, chat_name, language, is_active, notify_on_down, notify_on_up, timezone, external_id, created_at, updated_at
+        FROM telegram_configs
+        WHERE user_id = $1 AND external_id = $2
+    `+lock, userID, externalID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Telegram configuration: %w", err)
+	}
+
+	cfg.MonitorRegions = []string{}
+	err = sqlx.Select(q, &cfg.MonitorRegions, "SELECT region_code FROM telegram_config_regions WHERE telegram_config_id = $1", cfg.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get regions for config %d: %w", cfg.ID, err)
+	}
+	return &cfg, nil
+}
+
+// UpsertTelegramConfigByExternalID creates or replaces the Telegram config with the given
+// external ID. Unchanged configs are not written. ifMatch and ifNoneMatch are the conditional
+// request headers; etag.ErrPreconditionFailed is returned when they do not hold. An existing
+// config stays locked from the check until it is written, so a concurrent change cannot slip
+// in between. The boolean result reports whether the config was created.
+func (s *TelegramService) UpsertTelegramConfigByExternalID(userID int, externalID string, req model.TelegramConfigRequest, ifMatch, ifNoneMatch string) (*model.TelegramConfig, bool, error) {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	existing, err := telegramConfigByExternalID(tx, userID, externalID, " FOR UPDATE")
+	if err != nil {
+		return nil, false, err
+	}
+
+	current := ""
+	if existing != nil {
+		current = TelegramConfigETag(*existing)
+	}
+	if err := etag.Check(ifMatch, ifNoneMatch, current); err != nil {
+		return nil, false, err
+	}
+
+	desired := configState{req.ChatID, req.ChatName, req.Language, req.NotifyOnDown, req.NotifyOnUp, req.IsActive, req.MonitorRegions}
+	if existing != nil && desired.etag() == current {
+		return existing, false, nil
+	}
+
+	created := existing == nil
+	if created {
+		// There is no row to lock; setExternalID fails the request if another one
+		// claimed the external ID first
+		tx.Rollback()
+		configID, err := s.AddTelegramConfig(userID, req.ChatID, req.ChatName, req.Language,
+			req.NotifyOnDown, req.NotifyOnUp, req.IsActive, req.MonitorRegions)
+		if err != nil {
+			return nil, false, err
+		}
+		if err := s.dispatcher.setExternalID(s.Channel(), configID, userID, externalID); err != nil {
+			return nil, false, err
+		}
+	} else {
+		err := updateTelegramConfigTx(tx, existing.ID, userID, req.ChatID, req.ChatName, req.Language,
+			req.NotifyOnDown, req.NotifyOnUp, req.IsActive, req.MonitorRegions)
+		if err != nil {
+			return nil, false, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, false, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+	}
+
+	cfg, err := s.GetTelegramConfigByExternalID(userID, externalID)
+	return cfg, created, err
+}
+
+// GetEmailConfigByExternalID returns the user's email config with the given external ID,
+// or nil if none exists
+func (s *EmailService) GetEmailConfigByExternalID(userID int, externalID string) (*model.EmailConfig, error) {
+	return emailConfigByExternalID(s.db, userID, externalID, "")
+}
+
+// emailConfigByExternalID loads an email config through q. See telegramConfigByExternalID.
+func emailConfigByExternalID(q sqlx.Queryer, userID int, externalID, lock string) (*model.EmailConfig, error) {
+	var cfg model.EmailConfig
+	err := sqlx.Get(q, &cfg, `
+        SELECT id, user_id, email_address, email_name, language, is_active, notify_on_down, notify_on_up, timezone, external_id,
+               verified_at, CASE WHEN verified_at IS NULL THEN 'pending' ELSE 'verified' END AS status, created_at, updated_at
+        FROM email_configs
+        WHERE user_id = $1 AND external_id = $2
+    `+lock, userID, externalID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get email configuration: %w", err)
+	}
+
+	cfg.MonitorRegions = []string{}
+	err = sqlx.Select(q, &cfg.MonitorRegions, "SELECT region_code FROM email_config_regions WHERE email_config_id = $1", cfg.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get regions for config %d: %w", cfg.ID, err)
+	}
+	return &cfg, nil
+}
+
+// UpsertEmailConfigByExternalID creates or replaces the email config with the given
+// external ID. See UpsertTelegramConfigByExternalID.
+func (s *EmailService) UpsertEmailConfigByExternalID(userID int, externalID string, req model.EmailConfigRequest, ifMatch, ifNoneMatch string) (*model.EmailConfig, bool, error) {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	existing, err := emailConfigByExternalID(tx, userID, externalID, " FOR UPDATE")
+	if err != nil {
+		return nil, false, err
+	}
+
+	current := ""
+	if existing != nil {
+		current = EmailConfigETag(*existing)
+	}
+	if err := etag.Check(ifMatch, ifNoneMatch, current); err != nil {
+		return nil, false, err
+	}
+
+	desired := configState{req.EmailAddress, req.EmailName, req.Language, req.NotifyOnDown, req.NotifyOnUp, req.IsActive, req.MonitorRegions}
+	if existing != nil && desired.etag() == current {
+		return existing, false, nil
+	}
+
+	created := existing == nil
+	if created {
+		tx.Rollback()
+		configID, err := s.AddEmailConfig(userID, req.EmailAddress, req.EmailName, req.Language,
+			req.NotifyOnDown, req.NotifyOnUp, req.IsActive, req.MonitorRegions)
+		if err != nil {
+			return nil, false, err
+		}
+		if err := s.dispatcher.setExternalID(s.Channel(), configID, userID, externalID); err != nil {
+			return nil, false, err
+		}
+	} else {
+		readdressed, err := updateEmailConfigTx(tx, existing.ID, userID, req.EmailAddress, req.EmailName, req.Language,
+			req.NotifyOnDown, req.NotifyOnUp, req.IsActive, req.MonitorRegions)
+		if err != nil {
+			return nil, false, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, false, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		if readdressed {
+			s.sendReaddressVerification(existing.ID)
+		}
+	}
+
+	cfg, err := s.GetEmailConfigByExternalID(userID, externalID)
+	return cfg, created, err
+}
@@ -0,0 +1,97 @@
+package notification
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"domain-detection-go/internal/events"
+	"domain-detection-go/pkg/model"
+)
+
+// alertQueueSize is how many alerts may wait for delivery before the monitor blocks
+const alertQueueSize = 256
+
+// Fanout sends notifications through every registered channel, so callers do not
+// need to know which channels exist
+type Fanout struct {
+	notifiers []Notifier
+}
+
+// NewFanout creates a fanout over the given channels
+func NewFanout(notifiers ...Notifier) *Fanout {
+	f := &Fanout{}
+	for _, n := range notifiers {
+		f.Register(n)
+	}
+	return f
+}
+
+// Register adds a channel
+func (f *Fanout) Register(n Notifier) {
+	f.notifiers = append(f.notifiers, n)
+}
+
+// Get returns the registered notifier of a channel
+func (f *Fanout) Get(channel string) (Notifier, bool) {
+	for _, n := range f.notifiers {
+		if n.Channel() == channel {
+			return n, true
+		}
+	}
+	return nil, false
+}
+
+// Channels returns the names of the registered channels
+func (f *Fanout) Channels() []string {
+	channels := make([]string, len(f.notifiers))
+	for i, n := range f.notifiers {
+		channels[i] = n.Channel()
+	}
+	return channels
+}
+
+// SendDomainStatus notifies every channel about a domain check result. Every channel
+// is tried; the returned error joins the failures of each channel.
+func (f *Fanout) SendDomainStatus(domain model.Domain, statusChanged bool) error {
+	return f.each(func(n Notifier) error { return n.SendDomainStatus(domain, statusChanged) })
+}
+
+// SubscribeAlerts delivers the alerts published on the bus in the background, so slow
+// channels do not hold up checks
+func (f *Fanout) SubscribeAlerts(bus *events.Bus) {
+	bus.SubscribeAsync(func(e events.Event) {
+		domain, ok := e.Payload["domain"].(model.Domain)
+		if !ok {
+			return
+		}
+		statusChanged, _ := e.Payload["status_changed"].(bool)
+		if err := f.SendDomainStatus(domain, statusChanged); err != nil {
+			slog.Error("Failed to send notifications", "domain", domain.Name, "error", err)
+		}
+	}, alertQueueSize, events.DomainAlert)
+}
+
+// SendTest sends a test message to a config of the given channel
+func (f *Fanout) SendTest(channel string, configID, userID int) error {
+	n, ok := f.Get(channel)
+	if !ok {
+		return ErrUnsupportedChannel
+	}
+	return n.SendTest(configID, userID)
+}
+
+// SendCustom sends a free-form message through every channel of the user
+func (f *Fanout) SendCustom(userID int, render MessageRenderer) error {
+	return f.each(func(n Notifier) error { return n.SendCustom(userID, render) })
+}
+
+func (f *Fanout) each(send func(n Notifier) error) error {
+	var errs []error
+	for _, n := range f.notifiers {
+		if err := send(n); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", n.Channel(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,194 @@
+package notification
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"domain-detection-go/pkg/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// recordHistory stores the outcome of a domain status notification sent to a config
+func recordHistory(db *sqlx.DB, historyColumn string, domain model.Domain, configID int, notificationType string,
+	incidentID *int, messageID string, sendErr error) error {
+	status, deliveryError := model.DeliverySent, (*string)(nil)
+	if sendErr != nil {
+		status = model.DeliveryFailed
+		message := sendErr.Error()
+		deliveryError = &message
+	}
+	var storedID *string
+	if messageID != "" {
+		storedID = &messageID
+	}
+
+	_, err := db.Exec(fmt.Sprintf(`
+        INSERT INTO notification_history
+        (domain_id, %s, status_code, error_code, error_description, notified_at, notification_type, incident_id,
+         delivery_status, delivery_error, message_id)
+        VALUES ($1, $2, $3, $4, $5, NOW(), $6, $7, $8, $9, $10)
+    `, historyColumn), domain.ID, configID, domain.LastStatus, domain.ErrorCode, domain.ErrorDescription,
+		notificationType, incidentID, status, deliveryError, storedID)
+	return err
+}
+
+// DomainGetter loads a domain of a user
+type DomainGetter interface {
+	GetDomain(domainID, userID int) (*model.Domain, error)
+}
+
+// HistoryService lists sent notifications and resends the ones that failed
+type HistoryService struct {
+	db        *sqlx.DB
+	notifiers *Fanout
+	domains   DomainGetter
+}
+
+// NewHistoryService creates a new notification history service
+func NewHistoryService(db *sqlx.DB, notifiers *Fanout, domains DomainGetter) *HistoryService {
+	return &HistoryService{
+		db:        db,
+		notifiers: notifiers,
+		domains:   domains,
+	}
+}
+
+// historySelect reads history entries of a user's domains; nh is notification_history
+const historySelect = `
+    SELECT nh.id, nh.domain_id, d.name AS domain_name,
+           CASE WHEN nh.email_config_id IS NOT NULL THEN 'email' ELSE 'telegram' END AS channel,
+           COALESCE(nh.email_config_id, nh.telegram_config_id) AS config_id,
+           nh.notification_type, nh.status_code, nh.error_code, nh.error_description, nh.incident_id,
+           nh.delivery_status, nh.delivery_error, nh.message_id, nh.attempts, nh.notified_at, nh.last_attempt_at
+    FROM notification_history nh
+    JOIN domains d ON d.id = nh.domain_id
+    WHERE d.user_id = $1`
+
+// GetHistory returns the user's most recent notifications matching the filter
+func (s *HistoryService) GetHistory(userID int, filter model.NotificationHistoryFilter) ([]model.NotificationHistoryEntry, error) {
+	if filter.Limit <= 0 || filter.Limit > 500 {
+		filter.Limit = 100
+	}
+
+	query := historySelect
+	args := []interface{}{userID}
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		query += fmt.Sprintf(" AND "+condition, len(args))
+	}
+	if filter.DomainID != 0 {
+		addCondition("nh.domain_id = $%d", filter.DomainID)
+	}
+	switch filter.Channel {
+	case "":
+	case "email":
+		query += " AND nh.email_config_id IS NOT NULL"
+	case "telegram":
+		query += " AND nh.telegram_config_id IS NOT NULL"
+	default:
+		return nil, ErrUnsupportedChannel
+	}
+	if filter.Type != "" {
+		addCondition("nh.notification_type = $%d", filter.Type)
+	}
+	if filter.DeliveryStatus != "" {
+		addCondition("nh.delivery_status = $%d", filter.DeliveryStatus)
+	}
+	if filter.From != nil {
+		addCondition("nh.notified_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("nh.notified_at < $%d", *filter.To)
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY nh.notified_at DESC, nh.id DESC LIMIT $%d", len(args))
+
+	entries := []model.NotificationHistoryEntry{}
+	if err := s.db.Select(&entries, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get notification history: %w", err)
+	}
+	return entries, nil
+}
+
+// getEntry returns one history entry of the user
+func (s *HistoryService) getEntry(userID, entryID int) (*model.NotificationHistoryEntry, error) {
+	var entry model.NotificationHistoryEntry
+	err := s.db.Get(&entry, historySelect+" AND nh.id = $2", userID, entryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotificationAbsent
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification: %w", err)
+	}
+	return &entry, nil
+}
+
+// Resend delivers a failed domain status notification again to its config. The
+// message is rebuilt from the check result stored with the notification.
+func (s *HistoryService) Resend(userID, entryID int) (*model.NotificationHistoryEntry, error) {
+	entry, err := s.getEntry(userID, entryID)
+	if err != nil {
+		return nil, err
+	}
+	if entry.DeliveryStatus != model.DeliveryFailed {
+		return nil, ErrAlreadyDelivered
+	}
+	switch entry.Type {
+	case "down", NotificationTypeBlocked, "up", "status", NotificationTypeEscalation:
+	default:
+		return nil, ErrNotResendable
+	}
+
+	n, ok := s.notifiers.Get(entry.Channel)
+	if !ok {
+		return nil, ErrUnsupportedChannel
+	}
+	recipient, err := findRecipient(n, userID, entry.ConfigID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configuration: %w", err)
+	}
+	if recipient == nil || !recipient.IsActive {
+		return nil, ErrConfigNotFound
+	}
+	if recipient.Language == "" {
+		recipient.Language = "en"
+	}
+
+	domain, err := s.domains.GetDomain(entry.DomainID, userID)
+	if err != nil {
+		return nil, err
+	}
+	domain.LastStatus = entry.StatusCode
+	domain.LastCheck = entry.NotifiedAt
+	domain.ErrorCode = 0
+	if entry.ErrorCode != nil {
+		domain.ErrorCode = *entry.ErrorCode
+	}
+	domain.ErrorDescription = ""
+	if entry.ErrorDescription != nil {
+		domain.ErrorDescription = *entry.ErrorDescription
+	}
+
+	formattedTime := formatLocalTime(domain.LastCheck, recipient.Timezone, "2006-01-02 15:04:05")
+	messageID, sendErr := n.Send(*recipient, entry.Type, *domain, formattedTime)
+
+	status, deliveryError := model.DeliverySent, (*string)(nil)
+	if sendErr != nil {
+		status = model.DeliveryFailed
+		message := sendErr.Error()
+		deliveryError = &message
+	}
+	_, err = s.db.Exec(`
+        UPDATE notification_history
+        SET delivery_status = $1, delivery_error = $2, message_id = COALESCE(NULLIF($3, ''), message_id),
+            attempts = attempts + 1, last_attempt_at = NOW()
+        WHERE id = $4
+    `, status, deliveryError, messageID, entryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record resend: %w", err)
+	}
+
+	return s.getEntry(userID, entryID)
+}
@@ -0,0 +1,98 @@
+package notification
+
+import (
+	"fmt"
+
+	"domain-detection-go/internal/service"
+	"domain-detection-go/pkg/model"
+
+	"github.com/lib/pq"
+)
+
+// languageFallbacks returns the fallback languages of every config of a channel that has any
+func (d *Dispatcher) languageFallbacks(channel string, userID int) (map[int][]string, error) {
+	var rows []struct {
+		ConfigID  int            `db:"config_id"`
+		Languages pq.StringArray `db:"languages"`
+	}
+	err := d.db.Select(&rows, `
+        SELECT config_id, languages FROM notification_language_fallbacks
+        WHERE channel = $1 AND user_id = $2
+    `, channel, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get language fallbacks: %w", err)
+	}
+
+	fallbacks := make(map[int][]string, len(rows))
+	for _, row := range rows {
+		fallbacks[row.ConfigID] = row.Languages
+	}
+	return fallbacks, nil
+}
+
+// GetLanguageFallbacks returns the language fallback chain of a config
+func (d *Dispatcher) GetLanguageFallbacks(n Notifier, configID, userID int) (*model.LanguageFallbacks, error) {
+	recipient, err := findRecipient(n, userID, configID)
+	if err != nil {
+		return nil, err
+	}
+	if recipient == nil {
+		return nil, ErrConfigNotFound
+	}
+
+	fallbacks, err := d.languageFallbacks(n.Channel(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.LanguageFallbacks{
+		Channel:   n.Channel(),
+		ConfigID:  configID,
+		Language:  recipient.Language,
+		Fallbacks: fallbacks[configID],
+		Chain:     service.LanguageChain(recipient.Language, fallbacks[configID]),
+	}
+	if result.Fallbacks == nil {
+		result.Fallbacks = []string{}
+	}
+	return result, nil
+}
+
+// UpdateLanguageFallbacks sets the language fallback chain of a config. The config's
+// language followed by the fallbacks must fully cover the alert-critical prompts.
+func (d *Dispatcher) UpdateLanguageFallbacks(n Notifier, prompts *service.TelegramPromptService, configID, userID int, req model.LanguageFallbacksRequest) error {
+	recipient, err := findRecipient(n, userID, configID)
+	if err != nil {
+		return err
+	}
+	if recipient == nil {
+		return ErrConfigNotFound
+	}
+
+	if len(req.Fallbacks) == 0 {
+		_, err := d.db.Exec("DELETE FROM notification_language_fallbacks WHERE channel = $1 AND config_id = $2", n.Channel(), configID)
+		if err != nil {
+			return fmt.Errorf("failed to clear language fallbacks: %w", err)
+		}
+		return nil
+	}
+
+	language := recipient.Language
+	if language == "" {
+		language = service.DefaultLanguage
+	}
+	if err := prompts.ValidateLanguageChain(append([]string{language}, req.Fallbacks...)); err != nil {
+		return err
+	}
+
+	_, err = d.db.Exec(`
+        INSERT INTO notification_language_fallbacks (channel, config_id, user_id, languages, updated_at)
+        VALUES ($1, $2, $3, $4, NOW())
+        ON CONFLICT (channel, config_id)
+        DO UPDATE SET languages = $4, updated_at = NOW()
+    `, n.Channel(), configID, userID, pq.Array(req.Fallbacks))
+	if err != nil {
+		return fmt.Errorf("failed to save language fallbacks: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,349 @@
+package notification
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"domain-detection-go/pkg/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Recipient is a single configured destination on a notification channel
+type Recipient struct {
+	ConfigID       int
+	Address        string // Chat ID, email address, webhook URL...
+	Label          string // Human readable name used in logs
+	Language       string
+	Fallbacks      []string // Languages tried after Language when a message is missing
+	IsActive       bool
+	NotifyOnUp     bool
+	NotifyOnDown   bool
+	MonitorRegions []string
+	Tags           []string // Domain tags subscribed to; empty means all domains
+	Timezone       string   // Timezone timestamps are formatted in
+}
+
+// CustomMessage is free-form content sent outside the domain status flow. Each
+// channel uses the parts it supports.
+type CustomMessage struct {
+	Subject     string            // Email subject
+	HTML        string            // Email body
+	Attachments []EmailAttachment // Email attachments
+	Text        []string          // Chat messages, sent in order
+}
+
+// MessageRenderer renders a custom message in a recipient's language
+type MessageRenderer func(language string) CustomMessage
+
+// StaticMessage returns a renderer that sends the same message in every language
+func StaticMessage(msg CustomMessage) MessageRenderer {
+	return func(string) CustomMessage { return msg }
+}
+
+// Notifier is implemented by each notification channel. Channels only load their
+// recipients and format/transport messages; the Dispatcher does everything else.
+// Callers outside this package send through a Fanout instead of a channel directly.
+type Notifier interface {
+	// SendDomainStatus notifies every matching recipient about a domain check result
+	SendDomainStatus(domain model.Domain, statusChanged bool) error
+	// SendTest sends a test message to one config owned by the user
+	SendTest(configID, userID int) error
+	// SendCustom sends a free-form message to every active config of the user
+	SendCustom(userID int, render MessageRenderer) error
+
+	// Channel returns the channel name used in logs, e.g. "telegram"
+	Channel() string
+	// HistoryColumn returns the notification_history column referencing the channel's config
+	HistoryColumn() string
+	// GetRecipients returns every configured recipient for a user
+	GetRecipients(userID int) ([]Recipient, error)
+	// Send formats and delivers a notification to one recipient, returning the ID the
+	// transport assigned to the message when it has one
+	Send(recipient Recipient, notificationType string, domain model.Domain, formattedTime string) (string, error)
+	// SendDigest formats and delivers a summary of accumulated events to one recipient
+	SendDigest(recipient Recipient, digest model.Digest) error
+}
+
+// Dispatcher applies preference checks, region filtering, suppression and history
+// recording once for every channel
+type Dispatcher struct {
+	db          *sqlx.DB
+	logger      *slog.Logger
+	suppression SuppressionStore // Last notification per channel, domain and type
+	rateLimit   int              // Notifications per user and hour, 0 for no limit
+}
+
+// NewDispatcher creates a new notification dispatcher, suppressing repeats through
+// the database
+func NewDispatcher(db *sqlx.DB, logger *slog.Logger) *Dispatcher {
+	return &Dispatcher{
+		db:          db,
+		logger:      logger.With("component", "notification"),
+		suppression: NewDatabaseSuppressionStore(db),
+	}
+}
+
+// SetSuppressionStore replaces where the last notification times are kept
+func (d *Dispatcher) SetSuppressionStore(store SuppressionStore) {
+	d.suppression = store
+}
+
+// Dispatch sends a domain status notification to every matching recipient of a channel
+func (d *Dispatcher) Dispatch(n Notifier, domain model.Domain, statusChanged bool) error {
+	channel := n.Channel()
+	logger := d.logger.With("channel", channel, "domain_id", domain.ID, "user_id", domain.UserID)
+
+	recipients, err := n.GetRecipients(domain.UserID)
+	if err != nil {
+		logger.Error("Failed to get configurations", "error", err)
+		return fmt.Errorf("failed to get %s configurations for user: %w", channel, err)
+	}
+
+	if len(recipients) == 0 {
+		logger.Debug("No configurations")
+		return nil
+	}
+
+	notificationType := NotificationType(domain, statusChanged)
+
+	// Snoozed domains keep being checked but send nothing
+	if snoozed, err := d.snoozed(domain.ID, time.Now()); err != nil {
+		logger.Error("Failed to check snooze", "error", err)
+	} else if snoozed {
+		logger.Info("Skipping notification, domain snoozed", "type", notificationType)
+		return nil
+	}
+
+	// Domains can be limited to some channels, or none
+	if enabled, err := d.channelEnabled(domain.ID, channel); err != nil {
+		logger.Error("Failed to check domain notification channels", "error", err)
+	} else if !enabled {
+		logger.Info("Skipping notification, channel disabled for domain", "type", notificationType)
+		return nil
+	}
+
+	suppressionDuration := SuppressionDuration(domain, statusChanged)
+
+	// Check if we've recently sent the same notification. The claim is released again
+	// below when no recipient ends up being notified.
+	key := suppressionKey(channel, domain.ID, notificationType)
+	now := time.Now()
+	claimed, err := d.suppression.Claim(key, now, suppressionDuration)
+	if err != nil {
+		// The per-config history check below still prevents most repeats
+		logger.Error("Failed to check notification suppression", "error", err)
+	} else if !claimed {
+		logger.Info("Skipping notification, recently sent", "type", notificationType,
+			"suppression", suppressionDuration.String())
+		return nil
+	}
+	sent := false
+	defer func() {
+		if claimed && !sent {
+			if err := d.suppression.Release(key, now); err != nil {
+				logger.Error("Failed to release notification suppression", "error", err)
+			}
+		}
+	}()
+
+	digestConfigs, err := d.digestConfigIDs(channel, domain.UserID)
+	if err != nil {
+		logger.Error("Failed to get digest configs", "error", err)
+	}
+
+	routedConfigs, routed, err := d.routedConfigIDs(channel, domain.UserID, domain.AlertRegion())
+	if err != nil {
+		logger.Error("Failed to get routing rules", "error", err)
+	}
+
+	languageFallbacks, err := d.languageFallbacks(channel, domain.UserID)
+	if err != nil {
+		logger.Error("Failed to get language fallbacks", "error", err)
+	}
+
+	domainTags, err := d.domainTags(domain.ID)
+	if err != nil {
+		logger.Error("Failed to get domain tags", "error", err)
+	}
+
+	quietHours, err := d.quietHours(channel, domain.UserID)
+	if err != nil {
+		logger.Error("Failed to get quiet hours", "error", err)
+	}
+
+	// During a flood of alerts, such as a regional ISP outage, notifications over the
+	// user's hourly cap are collected into one summary
+	sentRecently := 0
+	if d.rateLimit > 0 {
+		if sentRecently, err = d.sentLastHour(n, domain.UserID); err != nil {
+			logger.Error("Failed to check notification rate limit", "error", err)
+		}
+	}
+
+	for _, recipient := range recipients {
+		// Routing rules for the region replace the per-config region filter
+		if routed {
+			if !routedConfigs[recipient.ConfigID] {
+				logger.Debug("Skipping notification, region routed to other configs",
+					"config_id", recipient.ConfigID, "region", domain.AlertRegion())
+				continue
+			}
+			recipient.MonitorRegions = nil
+		}
+
+		if reason := skipReason(recipient, domain, domainTags, notificationType); reason != "" {
+			logger.Debug("Skipping notification", "config_id", recipient.ConfigID, "reason", reason)
+			continue
+		}
+
+		// Digest-only configs get the event in their next summary instead, as do configs
+		// in quiet hours once the quiet period is over
+		quiet, hasQuiet := quietHours[recipient.ConfigID]
+		if digestConfigs[recipient.ConfigID] || (hasQuiet && holdForQuietHours(quiet, notificationType, now)) {
+			if err := d.queueDigestEvent(channel, recipient.ConfigID, domain, notificationType); err != nil {
+				logger.Error("Failed to queue digest event", "config_id", recipient.ConfigID, "error", err)
+			}
+			continue
+		}
+
+		if d.rateLimit > 0 && sentRecently >= d.rateLimit {
+			logger.Info("Notification rate limit reached, holding for summary", "config_id", recipient.ConfigID, "limit", d.rateLimit)
+			if err := d.queueDigestEvent(channel, recipient.ConfigID, domain, notificationType); err != nil {
+				logger.Error("Failed to queue digest event", "config_id", recipient.ConfigID, "error", err)
+			}
+			continue
+		}
+
+		// Check notification history in database
+		var lastNotification time.Time
+		err := d.db.Get(&lastNotification, fmt.Sprintf(`
+            SELECT MAX(notified_at)
+            FROM notification_history
+            WHERE domain_id = $1 AND %s = $2 AND notification_type = $3 AND delivery_status = 'sent'
+        `, n.HistoryColumn()), domain.ID, recipient.ConfigID, notificationType)
+
+		if err == nil && !lastNotification.IsZero() {
+			if now.Sub(lastNotification) < suppressionDuration {
+				logger.Info("Skipping notification, recently sent", "config_id", recipient.ConfigID,
+					"last_sent", lastNotification, "suppression", suppressionDuration.String())
+				continue
+			}
+		}
+
+		if recipient.Language == "" {
+			recipient.Language = "en"
+		}
+		recipient.Fallbacks = languageFallbacks[recipient.ConfigID]
+		formattedTime := formatLocalTime(domain.LastCheck, recipient.Timezone, "2006-01-02 15:04:05")
+
+		var incidentID *int
+		if domain.Incident != nil {
+			incidentID = &domain.Incident.ID
+		}
+
+		// Failed sends are recorded too so they can be listed and resent
+		messageID, sendErr := n.Send(recipient, notificationType, domain, formattedTime)
+		if sendErr != nil {
+			logger.Error("Failed to send notification", "config_id", recipient.ConfigID, "error", sendErr)
+		}
+		if err := recordHistory(d.db, n.HistoryColumn(), domain, recipient.ConfigID, notificationType, incidentID, messageID, sendErr); err != nil {
+			logger.Error("Failed to record notification history", "config_id", recipient.ConfigID, "error", err)
+		}
+		if sendErr != nil {
+			continue
+		}
+		sent = true
+		sentRecently++
+	}
+
+	return nil
+}
+
+// formatIncidentDuration renders an incident duration for messages, e.g. "1h 5m"
+func formatIncidentDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Minute {
+		return "less than a minute"
+	}
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	if hours == 0 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
+
+// NotificationTypeBlocked is a down alert for a domain whose check got an ISP block page
+// or an injected connection reset rather than a generic error
+const NotificationTypeBlocked = "blocked"
+
+// NotificationType returns "down", "blocked", "up" or "status" for a domain check result
+func NotificationType(domain model.Domain, statusChanged bool) string {
+	if !domain.Available() {
+		if domain.ErrorCode == model.ErrorCodeBlocked {
+			return NotificationTypeBlocked
+		}
+		return "down"
+	} else if statusChanged {
+		return "up"
+	}
+	return "status"
+}
+
+// isDownAlert reports whether a notification type announces an outage
+func isDownAlert(notificationType string) bool {
+	return notificationType == "down" || notificationType == NotificationTypeBlocked
+}
+
+// SuppressionDuration returns how long repeated notifications for a domain are suppressed
+func SuppressionDuration(domain model.Domain, statusChanged bool) time.Duration {
+	suppressionDuration := time.Duration(domain.Interval) * time.Minute
+
+	// For UP/DOWN status changes, use a shorter suppression period (half of regular interval)
+	if !domain.Available() || statusChanged {
+		suppressionDuration = suppressionDuration / 2
+	}
+
+	// Set a minimum suppression time to avoid flooding
+	minSuppression := 2 * time.Minute
+	if suppressionDuration < minSuppression {
+		suppressionDuration = minSuppression
+	}
+	return suppressionDuration
+}
+
+// skipReason returns why a recipient should not be notified, or "" if it should
+func skipReason(recipient Recipient, domain model.Domain, domainTags []string, notificationType string) string {
+	if !recipient.IsActive {
+		return "config is inactive"
+	}
+
+	// Skip if region doesn't match (if regions are specified)
+	if len(recipient.MonitorRegions) > 0 {
+		regionMatches := false
+		for _, region := range recipient.MonitorRegions {
+			if region == domain.AlertRegion() {
+				regionMatches = true
+				break
+			}
+		}
+		if !regionMatches {
+			return fmt.Sprintf("domain region %s not in monitor regions %v", domain.AlertRegion(), recipient.MonitorRegions)
+		}
+	}
+
+	// Tag subscriptions apply on top of region filtering and routing rules
+	if !tagsMatch(recipient.Tags, domainTags) {
+		return fmt.Sprintf("domain tags %v not in subscribed tags %v", domainTags, recipient.Tags)
+	}
+
+	if notificationType == "up" && !recipient.NotifyOnUp {
+		return "notify_on_up is disabled"
+	}
+	if isDownAlert(notificationType) && !recipient.NotifyOnDown {
+		return "notify_on_down is disabled"
+	}
+	return ""
+}
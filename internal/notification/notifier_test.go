@@ -0,0 +1,96 @@
+package notification
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"domain-detection-go/internal/testdb"
+	"domain-detection-go/pkg/model"
+)
+
+// fakeNotifier records who the dispatcher sends domain alerts to
+type fakeNotifier struct {
+	recipients []Recipient
+	sentTo     []int
+}
+
+func (f *fakeNotifier) SendDomainStatus(model.Domain, bool) error { return nil }
+func (f *fakeNotifier) SendTest(int, int) error                   { return nil }
+func (f *fakeNotifier) SendCustom(int, MessageRenderer) error     { return nil }
+func (f *fakeNotifier) Channel() string                           { return "telegram" }
+func (f *fakeNotifier) HistoryColumn() string                     { return "telegram_config_id" }
+func (f *fakeNotifier) GetRecipients(int) ([]Recipient, error)    { return f.recipients, nil }
+func (f *fakeNotifier) SendDigest(Recipient, model.Digest) error  { return nil }
+
+func (f *fakeNotifier) Send(recipient Recipient, _ string, _ model.Domain, _ string) (string, error) {
+	f.sentTo = append(f.sentTo, recipient.ConfigID)
+	return "", nil
+}
+
+func TestSkipReasonFiltersByFailingRegion(t *testing.T) {
+	recipient := Recipient{IsActive: true, NotifyOnDown: true, MonitorRegions: []string{"TH"}}
+	domain := model.Domain{Region: "VN", ExtraRegions: []string{"TH"}}
+
+	if reason := skipReason(recipient, domain, nil, "down"); reason == "" {
+		t.Error("TH recipient notified of a domain failing in its primary region VN")
+	}
+	domain.CheckedRegion = "TH"
+	if reason := skipReason(recipient, domain, nil, "down"); reason != "" {
+		t.Errorf("TH recipient skipped for a domain failing in TH: %s", reason)
+	}
+}
+
+func TestDispatchRoutesByFailingExtraRegion(t *testing.T) {
+	db := testdb.Open(t)
+	d := NewDispatcher(db, slog.Default())
+
+	var ids struct {
+		UserID   int `db:"user_id"`
+		DomainID int `db:"domain_id"`
+		Primary  int `db:"primary_config"`
+		Extra    int `db:"extra_config"`
+	}
+	err := db.Get(&ids, `
+        WITH u AS (
+            INSERT INTO users (username, password_hash, email) VALUES ('routing', 'hash', 'routing@example.com')
+            RETURNING id
+        ), d AS (
+            INSERT INTO domains (user_id, name, region, extra_regions) SELECT id, 'example.com', 'VN', ARRAY['TH'] FROM u
+            RETURNING id
+        ), p AS (
+            INSERT INTO telegram_configs (user_id, chat_id) SELECT id, '1' FROM u
+            RETURNING id
+        ), e AS (
+            INSERT INTO telegram_configs (user_id, chat_id) SELECT id, '2' FROM u
+            RETURNING id
+        )
+        SELECT u.id AS user_id, d.id AS domain_id, p.id AS primary_config, e.id AS extra_config FROM u, d, p, e
+    `)
+	if err != nil {
+		t.Fatalf("insert fixtures: %v", err)
+	}
+	_, err = db.Exec(`
+        INSERT INTO notification_routing_rules (user_id, region_code, channel, config_id)
+        VALUES ($1, 'VN', 'telegram', $2), ($1, 'TH', 'telegram', $3)
+    `, ids.UserID, ids.Primary, ids.Extra)
+	if err != nil {
+		t.Fatalf("insert routing rules: %v", err)
+	}
+
+	n := &fakeNotifier{recipients: []Recipient{
+		{ConfigID: ids.Primary, IsActive: true, NotifyOnDown: true},
+		{ConfigID: ids.Extra, IsActive: true, NotifyOnDown: true},
+	}}
+	domain := model.Domain{
+		ID: ids.DomainID, UserID: ids.UserID, Name: "example.com", Region: "VN", ExtraRegions: []string{"TH"},
+		LastCheck: time.Now(), CheckedRegion: "TH",
+	}
+	if err := d.Dispatch(n, domain, true); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+
+	if len(n.sentTo) != 1 || n.sentTo[0] != ids.Extra {
+		t.Errorf("alert sent to configs %v, want only the TH config %d", n.sentTo, ids.Extra)
+	}
+}
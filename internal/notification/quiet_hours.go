@@ -0,0 +1,177 @@
+package notification
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"domain-detection-go/pkg/model"
+
+	"github.com/lib/pq"
+)
+
+// quietHoursRow is the stored form of model.QuietHours
+type quietHoursRow struct {
+	Channel       string        `db:"channel"`
+	ConfigID      int           `db:"config_id"`
+	UserID        int           `db:"user_id"`
+	Enabled       bool          `db:"enabled"`
+	Timezone      string        `db:"timezone"`
+	StartTime     string        `db:"start_time"`
+	EndTime       string        `db:"end_time"`
+	Days          pq.Int64Array `db:"days"`
+	AllowCritical bool          `db:"allow_critical"`
+}
+
+func (r quietHoursRow) model() model.QuietHours {
+	q := model.QuietHours{
+		Channel:       r.Channel,
+		ConfigID:      r.ConfigID,
+		Enabled:       r.Enabled,
+		Timezone:      r.Timezone,
+		StartTime:     r.StartTime,
+		EndTime:       r.EndTime,
+		Days:          make([]int, len(r.Days)),
+		AllowCritical: r.AllowCritical,
+	}
+	for i, d := range r.Days {
+		q.Days[i] = int(d)
+	}
+	return q
+}
+
+// quietHours returns the enabled quiet hours of every config of a channel that has any
+func (d *Dispatcher) quietHours(channel string, userID int) (map[int]model.QuietHours, error) {
+	var rows []quietHoursRow
+	err := d.db.Select(&rows, `
+        SELECT channel, config_id, user_id, enabled, timezone, start_time, end_time, days, allow_critical
+        FROM notification_quiet_hours
+        WHERE channel = $1 AND user_id = $2 AND enabled = true
+    `, channel, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quiet hours: %w", err)
+	}
+
+	quiet := make(map[int]model.QuietHours, len(rows))
+	for _, row := range rows {
+		quiet[row.ConfigID] = row.model()
+	}
+	return quiet, nil
+}
+
+// channelQuietHours returns the enabled quiet hours of every config of a channel
+func (d *Dispatcher) channelQuietHours(channel string) (map[int]model.QuietHours, error) {
+	var rows []quietHoursRow
+	err := d.db.Select(&rows, `
+        SELECT channel, config_id, user_id, enabled, timezone, start_time, end_time, days, allow_critical
+        FROM notification_quiet_hours
+        WHERE channel = $1 AND enabled = true
+    `, channel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quiet hours: %w", err)
+	}
+
+	quiet := make(map[int]model.QuietHours, len(rows))
+	for _, row := range rows {
+		quiet[row.ConfigID] = row.model()
+	}
+	return quiet, nil
+}
+
+// configQuietHours returns the quiet hours of one config, or nil if it has none
+func (d *Dispatcher) configQuietHours(channel string, configID int) (*model.QuietHours, error) {
+	var row quietHoursRow
+	err := d.db.Get(&row, `
+        SELECT channel, config_id, user_id, enabled, timezone, start_time, end_time, days, allow_critical
+        FROM notification_quiet_hours
+        WHERE channel = $1 AND config_id = $2
+    `, channel, configID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quiet hours: %w", err)
+	}
+	q := row.model()
+	return &q, nil
+}
+
+// holdForQuietHours reports whether a notification is held back by quiet hours. Down
+// alerts are critical and go out when the config allows it.
+func holdForQuietHours(quiet model.QuietHours, notificationType string, now time.Time) bool {
+	if !quiet.Active(now) {
+		return false
+	}
+	return !(quiet.AllowCritical && isDownAlert(notificationType))
+}
+
+// GetQuietHours returns the quiet hours of a config, disabled if none are stored
+func (d *Dispatcher) GetQuietHours(n Notifier, configID, userID int) (*model.QuietHours, error) {
+	if err := checkConfigOwner(n, configID, userID); err != nil {
+		return nil, err
+	}
+
+	quiet, err := d.configQuietHours(n.Channel(), configID)
+	if err != nil {
+		return nil, err
+	}
+	if quiet == nil {
+		timezone, err := d.userTimezone(userID)
+		if err != nil {
+			return nil, err
+		}
+		quiet = &model.QuietHours{
+			Channel:       n.Channel(),
+			ConfigID:      configID,
+			Timezone:      timezone,
+			Days:          []int{},
+			AllowCritical: true,
+		}
+	}
+	return quiet, nil
+}
+
+// UpdateQuietHours replaces the quiet hours of a config
+func (d *Dispatcher) UpdateQuietHours(n Notifier, configID, userID int, req model.QuietHoursRequest) (*model.QuietHours, error) {
+	if err := checkConfigOwner(n, configID, userID); err != nil {
+		return nil, err
+	}
+
+	if req.Timezone == "" {
+		timezone, err := d.userTimezone(userID)
+		if err != nil {
+			return nil, err
+		}
+		req.Timezone = timezone
+	}
+	if _, err := time.LoadLocation(req.Timezone); err != nil {
+		return nil, fmt.Errorf("invalid timezone %q", req.Timezone)
+	}
+	if _, err := model.ParseClock(req.StartTime); err != nil {
+		return nil, err
+	}
+	if _, err := model.ParseClock(req.EndTime); err != nil {
+		return nil, err
+	}
+	allowCritical := true
+	if req.AllowCritical != nil {
+		allowCritical = *req.AllowCritical
+	}
+	days := make([]int64, len(req.Days))
+	for i, day := range req.Days {
+		days[i] = int64(day)
+	}
+
+	_, err := d.db.Exec(`
+        INSERT INTO notification_quiet_hours
+        (channel, config_id, user_id, enabled, timezone, start_time, end_time, days, allow_critical, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+        ON CONFLICT (channel, config_id)
+        DO UPDATE SET enabled = $4, timezone = $5, start_time = $6, end_time = $7, days = $8,
+                      allow_critical = $9, updated_at = NOW()
+    `, n.Channel(), configID, userID, req.Enabled, req.Timezone, req.StartTime, req.EndTime, pq.Array(days), allowCritical)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save quiet hours: %w", err)
+	}
+	return d.configQuietHours(n.Channel(), configID)
+}
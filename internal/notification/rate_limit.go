@@ -0,0 +1,74 @@
+package notification
+
+import (
+	"fmt"
+	"time"
+)
+
+// overflowSummaryDelay is how long notifications held back by the rate limit are
+// collected before they are delivered as one summary
+const overflowSummaryDelay = 15 * time.Minute
+
+// pendingSummary is a config without a digest of its own that has held notifications
+type pendingSummary struct {
+	ConfigID    int       `db:"config_id"`
+	UserID      int       `db:"user_id"`
+	OldestEvent time.Time `db:"oldest_event"`
+	QuietHours  bool      `db:"quiet_hours"`
+}
+
+// SetRateLimit caps the notifications of a channel per user and hour. Notifications over
+// the cap are delivered as one summary instead. 0 disables the cap.
+func (d *Dispatcher) SetRateLimit(perHour int) {
+	d.rateLimit = perHour
+}
+
+// sentLastHour counts the notifications a channel delivered to a user in the last hour
+func (d *Dispatcher) sentLastHour(n Notifier, userID int) (int, error) {
+	table, ok := configTables[n.Channel()]
+	if !ok {
+		return 0, nil
+	}
+
+	var count int
+	err := d.db.Get(&count, fmt.Sprintf(`
+        SELECT COUNT(*)
+        FROM notification_history h
+        JOIN %s c ON c.id = h.%s
+        WHERE c.user_id = $1 AND h.notified_at > NOW() - INTERVAL '1 hour' AND h.delivery_status = 'sent'
+    `, table, n.HistoryColumn()), userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count recent notifications: %w", err)
+	}
+	return count, nil
+}
+
+// pendingSummaries returns the configs of a channel with held notifications but no
+// enabled digest to deliver them, held by quiet hours or by the rate limit
+func (d *Dispatcher) pendingSummaries(n Notifier) ([]pendingSummary, error) {
+	table, ok := configTables[n.Channel()]
+	if !ok {
+		return nil, nil
+	}
+
+	var pending []pendingSummary
+	err := d.db.Select(&pending, fmt.Sprintf(`
+        SELECT e.config_id, c.user_id, MIN(e.occurred_at) AS oldest_event,
+               EXISTS (
+                   SELECT 1 FROM notification_quiet_hours q
+                   WHERE q.channel = e.channel AND q.config_id = e.config_id AND q.enabled = true
+               ) AS quiet_hours
+        FROM notification_digest_events e
+        JOIN %s c ON c.id = e.config_id
+        WHERE e.channel = $1
+          AND NOT EXISTS (
+              SELECT 1 FROM notification_digests g
+              WHERE g.channel = e.channel AND g.config_id = e.config_id AND g.enabled = true
+          )
+        GROUP BY e.channel, e.config_id, c.user_id
+    `, table), n.Channel())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending summaries: %w", err)
+	}
+	return pending, nil
+}
@@ -0,0 +1,115 @@
+package notification
+
+import (
+	"database/sql"
+	"fmt"
+
+	"domain-detection-go/pkg/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// routedConfigIDs returns the configs of a channel that alerts for a region are routed to.
+// The boolean result is false when the user has no usable rules for the region, in which
+// case every recipient is eligible as before. Rules pointing at deleted or inactive configs
+// are ignored so a stale rule never silences a region.
+func (d *Dispatcher) routedConfigIDs(channel string, userID int, region string) (map[int]bool, bool, error) {
+	var rules []model.RoutingRule
+	err := d.db.Select(&rules, `
+        SELECT r.channel, r.config_id
+        FROM notification_routing_rules r
+        WHERE r.user_id = $1 AND r.region_code = $2
+          AND (
+              (r.channel = 'telegram' AND EXISTS (
+                  SELECT 1 FROM telegram_configs t WHERE t.id = r.config_id AND t.user_id = r.user_id AND t.is_active = true))
+              OR (r.channel = 'email' AND EXISTS (
+                  SELECT 1 FROM email_configs e WHERE e.id = r.config_id AND e.user_id = r.user_id AND e.is_active = true))
+          )
+    `, userID, region)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get routing rules: %w", err)
+	}
+
+	ids := make(map[int]bool)
+	for _, rule := range rules {
+		if rule.Channel == channel {
+			ids[rule.ConfigID] = true
+		}
+	}
+	return ids, len(rules) > 0, nil
+}
+
+// RoutingService manages per-region routing rules across notification channels
+type RoutingService struct {
+	db        *sqlx.DB
+	notifiers *Fanout
+}
+
+// NewRoutingService creates a new routing rule service
+func NewRoutingService(db *sqlx.DB, notifiers *Fanout) *RoutingService {
+	return &RoutingService{
+		db:        db,
+		notifiers: notifiers,
+	}
+}
+
+// GetRules returns every routing rule of a user
+func (s *RoutingService) GetRules(userID int) ([]model.RoutingRule, error) {
+	rules := []model.RoutingRule{}
+	err := s.db.Select(&rules, `
+        SELECT id, user_id, region_code, channel, config_id, created_at
+        FROM notification_routing_rules
+        WHERE user_id = $1
+        ORDER BY region_code, channel, config_id
+    `, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get routing rules: %w", err)
+	}
+	return rules, nil
+}
+
+// AddRule routes alerts for a region to a config owned by the user
+func (s *RoutingService) AddRule(userID int, req model.RoutingRuleRequest) (int, error) {
+	n, ok := s.notifiers.Get(req.Channel)
+	if !ok {
+		return 0, ErrUnsupportedChannel
+	}
+
+	var isValidRegion bool
+	err := s.db.Get(&isValidRegion, "SELECT EXISTS(SELECT 1 FROM regions WHERE code = $1)", req.RegionCode)
+	if err != nil {
+		return 0, fmt.Errorf("error verifying region: %w", err)
+	}
+	if !isValidRegion {
+		return 0, errInvalidRegion
+	}
+
+	if err := checkConfigOwner(n, req.ConfigID, userID); err != nil {
+		return 0, err
+	}
+
+	var ruleID int
+	err = s.db.Get(&ruleID, `
+        INSERT INTO notification_routing_rules (user_id, region_code, channel, config_id, created_at)
+        VALUES ($1, $2, $3, $4, NOW())
+        ON CONFLICT (user_id, region_code, channel, config_id) DO UPDATE SET region_code = EXCLUDED.region_code
+        RETURNING id
+    `, userID, req.RegionCode, req.Channel, req.ConfigID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add routing rule: %w", err)
+	}
+	return ruleID, nil
+}
+
+// DeleteRule removes a routing rule owned by the user
+func (s *RoutingService) DeleteRule(userID, ruleID int) error {
+	var id int
+	err := s.db.Get(&id, "DELETE FROM notification_routing_rules WHERE id = $1 AND user_id = $2 RETURNING id", ruleID, userID)
+	if err == sql.ErrNoRows {
+		return ErrRoutingRuleNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete routing rule: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package notification
+
+import (
+	"fmt"
+	"time"
+)
+
+// SnoozeCallbackPrefix prefixes the callback data of the snooze button on down alerts,
+// followed by the domain ID
+const SnoozeCallbackPrefix = "domain_snooze_"
+
+// AlertSnoozeDuration is how long the snooze button on down alerts silences a domain
+const AlertSnoozeDuration = time.Hour
+
+// snoozed reports whether the domain's alerts are snoozed at now
+func (d *Dispatcher) snoozed(domainID int, now time.Time) (bool, error) {
+	var snoozedUntil *time.Time
+	err := d.db.Get(&snoozedUntil, "SELECT snoozed_until FROM domains WHERE id = $1", domainID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get domain snooze: %w", err)
+	}
+	return snoozedUntil != nil && now.Before(*snoozedUntil), nil
+}
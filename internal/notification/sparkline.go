@@ -0,0 +1,49 @@
+package notification
+
+import (
+	"fmt"
+	"html/template"
+	"time"
+
+	"domain-detection-go/internal/chart"
+)
+
+// sparklineWindow is the period charted in domain status emails
+const sparklineWindow = 24 * time.Hour
+
+// emailReportCharts caps how many domains of a report get a chart, worst uptime first
+const emailReportCharts = 10
+
+// domainSparkline renders the checks of a domain between from and to as an inline image
+// referenced by contentID. It returns nil when the domain has no checks in the period.
+func (s *EmailService) domainSparkline(domainID int, contentID string, from, to time.Time) (*EmailAttachment, error) {
+	var points []chart.Point
+	err := s.db.Select(&points, `
+        SELECT checked_at, available, COALESCE(total_time, 0) AS total_time
+        FROM domain_check_history
+        WHERE domain_id = $1 AND checked_at >= $2 AND checked_at < $3
+        ORDER BY checked_at
+    `, domainID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get check history: %w", err)
+	}
+	if len(points) == 0 {
+		return nil, nil
+	}
+
+	image, err := chart.Sparkline(points, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &EmailAttachment{
+		Filename:    contentID + ".png",
+		ContentType: "image/png",
+		Data:        image,
+		ContentID:   contentID,
+	}, nil
+}
+
+// inlineImageSrc returns the src of an <img> showing an inline attachment
+func inlineImageSrc(attachment EmailAttachment) template.URL {
+	return template.URL("cid:" + attachment.ContentID)
+}
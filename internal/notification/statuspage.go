@@ -0,0 +1,467 @@
+package notification
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"domain-detection-go/internal/credentials"
+	"domain-detection-go/internal/events"
+	"domain-detection-go/internal/outbound"
+	"domain-detection-go/pkg/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// statusChangeQueueSize is how many status changes may wait for publishing before the
+// monitor blocks
+const statusChangeQueueSize = 256
+
+// StatusPageService publishes domain incidents to external status page providers
+type StatusPageService struct {
+	db            *sqlx.DB
+	httpClient    *outbound.Client
+	encryptionKey string // Seals the provider API keys
+}
+
+// NewStatusPageService creates a new status page service and subscribes it to domain
+// status changes. Changes are published in the background in the order they happened,
+// so a quick down and up never resolves an incident before it is opened.
+func NewStatusPageService(db *sqlx.DB, eventBus *events.Bus, encryptionKey string) *StatusPageService {
+	s := &StatusPageService{
+		db: db,
+		httpClient: outbound.NewClient(outbound.Config{
+			Name:       "StatusPage",
+			Timeout:    15 * time.Second,
+			MaxRetries: 2,
+		}),
+		encryptionKey: encryptionKey,
+	}
+
+	if eventBus != nil {
+		eventBus.SubscribeAsync(func(e events.Event) {
+			available, ok := e.Payload["available"].(bool)
+			if !ok {
+				return
+			}
+			s.PublishStatusChange(e.DomainID, available)
+		}, statusChangeQueueSize, events.DomainStatusChanged)
+	}
+
+	return s
+}
+
+// AddConfig adds a new status page integration
+func (s *StatusPageService) AddConfig(userID int, req model.StatusPageConfigRequest) (int, error) {
+	if req.APIKey == "" {
+		return 0, ErrAPIKeyRequired
+	}
+	apiKey, err := credentials.Seal(req.APIKey, s.encryptionKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encrypt api key: %w", err)
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var configID int
+	err = tx.QueryRow(`
+        INSERT INTO status_page_configs (user_id, provider, name, page_id, api_key, api_key_encrypted, is_active, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, true, $6, NOW(), NOW())
+        RETURNING id
+    `, userID, req.Provider, req.Name, req.PageID, apiKey, req.IsActive).Scan(&configID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add status page configuration: %w", err)
+	}
+
+	if err := s.replaceComponents(tx, configID, userID, req.Components); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return configID, nil
+}
+
+// GetConfigsForUser retrieves all status page integrations for a user
+func (s *StatusPageService) GetConfigsForUser(userID int) ([]model.StatusPageConfig, error) {
+	configs := []model.StatusPageConfig{}
+	err := s.db.Select(&configs, `
+        SELECT id, user_id, provider, COALESCE(name, '') AS name, page_id, is_active, created_at, updated_at
+        FROM status_page_configs
+        WHERE user_id = $1
+        ORDER BY created_at DESC
+    `, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status page configurations: %w", err)
+	}
+
+	for i := range configs {
+		configs[i].Components = []model.StatusPageComponent{}
+		err := s.db.Select(&configs[i].Components, `
+            SELECT domain_id, component_id FROM status_page_components
+            WHERE status_page_config_id = $1
+            ORDER BY domain_id
+        `, configs[i].ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get status page components: %w", err)
+		}
+	}
+
+	return configs, nil
+}
+
+// UpdateConfig updates a status page integration. An empty API key keeps the stored one.
+func (s *StatusPageService) UpdateConfig(configID, userID int, req model.StatusPageConfigRequest) error {
+	apiKey := ""
+	if req.APIKey != "" {
+		sealed, err := credentials.Seal(req.APIKey, s.encryptionKey)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt api key: %w", err)
+		}
+		apiKey = sealed
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+        UPDATE status_page_configs
+        SET provider = $1, name = $2, page_id = $3, is_active = $4,
+            api_key = COALESCE(NULLIF($5, ''), api_key),
+            api_key_encrypted = api_key_encrypted OR $5 <> '', updated_at = NOW()
+        WHERE id = $6 AND user_id = $7
+    `, req.Provider, req.Name, req.PageID, req.IsActive, apiKey, configID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update status page configuration: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrStatusPageConfigNotFound
+	}
+
+	if err := s.replaceComponents(tx, configID, userID, req.Components); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// EncryptStoredAPIKeys seals the API keys stored in plaintext before keys were encrypted
+func (s *StatusPageService) EncryptStoredAPIKeys() (int, error) {
+	var plain []struct {
+		ID     int    `db:"id"`
+		APIKey string `db:"api_key"`
+	}
+	err := s.db.Select(&plain, "SELECT id, api_key FROM status_page_configs WHERE NOT api_key_encrypted")
+	if err != nil {
+		return 0, fmt.Errorf("failed to get plaintext api keys: %w", err)
+	}
+
+	for _, row := range plain {
+		sealed, err := credentials.Seal(row.APIKey, s.encryptionKey)
+		if err != nil {
+			return 0, fmt.Errorf("failed to encrypt api key: %w", err)
+		}
+		_, err = s.db.Exec(`
+            UPDATE status_page_configs SET api_key = $1, api_key_encrypted = true
+            WHERE id = $2 AND NOT api_key_encrypted
+        `, sealed, row.ID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to store encrypted api key: %w", err)
+		}
+	}
+	return len(plain), nil
+}
+
+// DeleteConfig deletes a status page integration
+func (s *StatusPageService) DeleteConfig(configID, userID int) error {
+	result, err := s.db.Exec("DELETE FROM status_page_configs WHERE id = $1 AND user_id = $2", configID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete status page configuration: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrStatusPageConfigNotFound
+	}
+	return nil
+}
+
+// replaceComponents replaces the component mappings of a config, checking domain ownership
+func (s *StatusPageService) replaceComponents(tx *sqlx.Tx, configID, userID int, components []model.StatusPageComponent) error {
+	if _, err := tx.Exec("DELETE FROM status_page_components WHERE status_page_config_id = $1", configID); err != nil {
+		return fmt.Errorf("failed to clear component mappings: %w", err)
+	}
+
+	for _, component := range components {
+		var owned bool
+		err := tx.Get(&owned, "SELECT EXISTS(SELECT 1 FROM domains WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL)", component.DomainID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to verify domain %d: %w", component.DomainID, err)
+		}
+		if !owned {
+			return fmt.Errorf("%w: %d", errDomainNotFound, component.DomainID)
+		}
+
+		_, err = tx.Exec(`
+            INSERT INTO status_page_components (status_page_config_id, domain_id, component_id)
+            VALUES ($1, $2, $3)
+        `, configID, component.DomainID, component.ComponentID)
+		if err != nil {
+			return fmt.Errorf("failed to add component mapping for domain %d: %w", component.DomainID, err)
+		}
+	}
+
+	return nil
+}
+
+// PublishStatusChange opens or resolves incidents on every status page the domain is mapped to
+func (s *StatusPageService) PublishStatusChange(domainID int, available bool) {
+	var targets []struct {
+		model.StatusPageConfig
+		ComponentID string `db:"component_id"`
+		DomainName  string `db:"domain_name"`
+	}
+	err := s.db.Select(&targets, `
+        SELECT c.id, c.user_id, c.provider, c.page_id, c.api_key, c.api_key_encrypted, c.is_active,
+               m.component_id, d.name AS domain_name
+        FROM status_page_components m
+        JOIN status_page_configs c ON c.id = m.status_page_config_id
+        JOIN domains d ON d.id = m.domain_id
+        WHERE m.domain_id = $1 AND c.is_active = true
+    `, domainID)
+	if err != nil {
+		slog.Error("Failed to load status pages", "domain_id", domainID, "error", err)
+		return
+	}
+
+	status := "down"
+	if available {
+		status = "up"
+	}
+
+	for _, target := range targets {
+		if target.APIKeyEncrypted {
+			if target.APIKey, err = credentials.Open(target.APIKey, s.encryptionKey); err != nil {
+				slog.Error("Failed to decrypt status page api key", "config_id", target.ID, "error", err)
+				continue
+			}
+		}
+		if available {
+			err = s.resolveIncident(target.StatusPageConfig, domainID, target.ComponentID, target.DomainName)
+		} else {
+			err = s.openIncident(target.StatusPageConfig, domainID, target.ComponentID, target.DomainName)
+		}
+		if err != nil {
+			slog.Error("Failed to publish status", "status", status, "domain_id", domainID,
+				"provider", target.Provider, "page_id", target.PageID, "error", err)
+		}
+	}
+}
+
+// openIncident creates an incident on the provider unless one is already open. The
+// incident is claimed in the database first, so it is opened once even when several
+// instances publish the same change.
+func (s *StatusPageService) openIncident(config model.StatusPageConfig, domainID int, componentID, domainName string) error {
+	var incidentID int
+	err := s.db.Get(&incidentID, `
+        INSERT INTO status_page_incidents (status_page_config_id, domain_id, external_id, status, opened_at)
+        VALUES ($1, $2, '', 'open', NOW())
+        ON CONFLICT (status_page_config_id, domain_id) WHERE status = 'open' DO NOTHING
+        RETURNING id
+    `, config.ID, domainID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record incident: %w", err)
+	}
+
+	externalID, err := s.createIncident(config, componentID, domainName)
+	if err != nil {
+		// Release the claim so the next failed check tries again
+		if _, dbErr := s.db.Exec("DELETE FROM status_page_incidents WHERE id = $1", incidentID); dbErr != nil {
+			slog.Error("Failed to release status page incident", "incident_id", incidentID, "error", dbErr)
+		}
+		return err
+	}
+
+	_, err = s.db.Exec("UPDATE status_page_incidents SET external_id = $1 WHERE id = $2", externalID, incidentID)
+	if err != nil {
+		return fmt.Errorf("failed to record incident: %w", err)
+	}
+
+	slog.Info("Opened status page incident", "provider", config.Provider, "incident_id", externalID, "domain_id", domainID)
+	return nil
+}
+
+// createIncident creates an incident on the provider and returns its ID there
+func (s *StatusPageService) createIncident(config model.StatusPageConfig, componentID, domainName string) (string, error) {
+	var err error
+
+	title := fmt.Sprintf("%s is unavailable", domainName)
+	message := fmt.Sprintf("We are investigating availability issues with %s.", domainName)
+
+	var externalID string
+	switch config.Provider {
+	case model.StatusPageProviderStatuspage:
+		var resp struct {
+			ID string `json:"id"`
+		}
+		err = s.doRequest("POST", fmt.Sprintf("https://api.statuspage.io/v1/pages/%s/incidents", url.PathEscape(config.PageID)),
+			"OAuth "+config.APIKey, map[string]interface{}{
+				"incident": map[string]interface{}{
+					"name":          title,
+					"status":        "investigating",
+					"body":          message,
+					"component_ids": []string{componentID},
+					"components":    map[string]string{componentID: "major_outage"},
+				},
+			}, &resp)
+		externalID = resp.ID
+	case model.StatusPageProviderInstatus:
+		var resp struct {
+			ID string `json:"id"`
+		}
+		err = s.doRequest("POST", fmt.Sprintf("https://api.instatus.com/v1/%s/incidents", url.PathEscape(config.PageID)),
+			"Bearer "+config.APIKey, map[string]interface{}{
+				"name":       title,
+				"message":    message,
+				"components": []string{componentID},
+				"started":    time.Now().UTC().Format(time.RFC3339),
+				"status":     "INVESTIGATING",
+				"notify":     true,
+				"statuses":   []map[string]string{{"id": componentID, "status": "MAJOROUTAGE"}},
+			}, &resp)
+		externalID = resp.ID
+	default:
+		return "", fmt.Errorf("unsupported provider: %s", config.Provider)
+	}
+	if err != nil {
+		return "", err
+	}
+	if externalID == "" {
+		return "", errors.New("provider did not return an incident id")
+	}
+	return externalID, nil
+}
+
+// resolveIncident resolves the open incident for the domain, if any
+func (s *StatusPageService) resolveIncident(config model.StatusPageConfig, domainID int, componentID, domainName string) error {
+	var incident struct {
+		ID         int    `db:"id"`
+		ExternalID string `db:"external_id"`
+	}
+	err := s.db.Get(&incident, `
+        SELECT id, external_id FROM status_page_incidents
+        WHERE status_page_config_id = $1 AND domain_id = $2 AND status = 'open'
+        ORDER BY opened_at DESC
+        LIMIT 1
+    `, config.ID, domainID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find open incident: %w", err)
+	}
+
+	message := fmt.Sprintf("%s is available again.", domainName)
+	pageID, incidentID := url.PathEscape(config.PageID), url.PathEscape(incident.ExternalID)
+
+	switch {
+	case incident.ExternalID == "":
+		// Left behind by an instance that stopped while opening it; nothing to resolve
+		// on the provider
+	case config.Provider == model.StatusPageProviderStatuspage:
+		err = s.doRequest("PATCH", fmt.Sprintf("https://api.statuspage.io/v1/pages/%s/incidents/%s", pageID, incidentID),
+			"OAuth "+config.APIKey, map[string]interface{}{
+				"incident": map[string]interface{}{
+					"status":     "resolved",
+					"body":       message,
+					"components": map[string]string{componentID: "operational"},
+				},
+			}, nil)
+	case config.Provider == model.StatusPageProviderInstatus:
+		err = s.doRequest("POST", fmt.Sprintf("https://api.instatus.com/v1/%s/incidents/%s/incident-updates", pageID, incidentID),
+			"Bearer "+config.APIKey, map[string]interface{}{
+				"message":    message,
+				"components": []string{componentID},
+				"started":    time.Now().UTC().Format(time.RFC3339),
+				"status":     "RESOLVED",
+				"notify":     true,
+				"statuses":   []map[string]string{{"id": componentID, "status": "OPERATIONAL"}},
+			}, nil)
+	default:
+		return fmt.Errorf("unsupported provider: %s", config.Provider)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`
+        UPDATE status_page_incidents SET status = 'resolved', resolved_at = NOW() WHERE id = $1
+    `, incident.ID)
+	if err != nil {
+		return fmt.Errorf("failed to record incident resolution: %w", err)
+	}
+
+	slog.Info("Resolved status page incident", "provider", config.Provider, "incident_id", incident.ExternalID, "domain_id", domainID)
+	return nil
+}
+
+// doRequest sends a JSON request to a status page provider and decodes the response into out
+func (s *StatusPageService) doRequest(method, endpoint, authorization string, body interface{}, out interface{}) error {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("error marshalling request: %w", err)
+	}
+
+	req, err := http.NewRequest(method, endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("API returned non-success status: %d, body: %s", resp.StatusCode, string(respBody))
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("error parsing response: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package notification
+
+import (
+	"testing"
+
+	"domain-detection-go/internal/credentials"
+	"domain-detection-go/internal/testdb"
+	"domain-detection-go/pkg/model"
+)
+
+func TestStatusPageAPIKeysAreStoredEncrypted(t *testing.T) {
+	db := testdb.Open(t)
+	s := NewStatusPageService(db, nil, "test-key")
+
+	var userID int
+	err := db.Get(&userID, `
+        INSERT INTO users (username, password_hash, email) VALUES ('status', 'hash', 'status@example.com')
+        RETURNING id
+    `)
+	if err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+
+	configID, err := s.AddConfig(userID, model.StatusPageConfigRequest{
+		Provider: model.StatusPageProviderInstatus, PageID: "page", APIKey: "plain-key", IsActive: true,
+	})
+	if err != nil {
+		t.Fatalf("AddConfig: %v", err)
+	}
+
+	var legacyID int
+	err = db.Get(&legacyID, `
+        INSERT INTO status_page_configs (user_id, provider, page_id, api_key) VALUES ($1, 'instatus', 'legacy', 'legacy-key')
+        RETURNING id
+    `, userID)
+	if err != nil {
+		t.Fatalf("insert legacy config: %v", err)
+	}
+	if sealed, err := s.EncryptStoredAPIKeys(); err != nil || sealed != 1 {
+		t.Fatalf("EncryptStoredAPIKeys = %d, %v, want 1, nil", sealed, err)
+	}
+
+	for id, want := range map[int]string{configID: "plain-key", legacyID: "legacy-key"} {
+		var stored model.StatusPageConfig
+		if err := db.Get(&stored, "SELECT api_key, api_key_encrypted FROM status_page_configs WHERE id = $1", id); err != nil {
+			t.Fatalf("get config %d: %v", id, err)
+		}
+		if !stored.APIKeyEncrypted || stored.APIKey == want {
+			t.Errorf("config %d stores its api key in plaintext", id)
+			continue
+		}
+		if got, err := credentials.Open(stored.APIKey, "test-key"); err != nil || got != want {
+			t.Errorf("config %d api key opens to %q, %v, want %q", id, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package notification
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// SuppressionStore remembers when a notification was last sent so repeats within the
+// suppression window are skipped. Stores shared between processes, such as the
+// database store, keep suppression across restarts and replicas.
+type SuppressionStore interface {
+	// Claim records a notification for key at now unless one was recorded less than
+	// window ago, and reports whether the caller may send it
+	Claim(key string, now time.Time, window time.Duration) (bool, error)
+	// Release drops a claim made at claimedAt whose notification was not sent after all
+	Release(key string, claimedAt time.Time) error
+}
+
+// NewSuppressionStore returns the store of the given kind: "memory" keeps suppression
+// in this process only, anything else uses the database
+func NewSuppressionStore(kind string, db *sqlx.DB) SuppressionStore {
+	if kind == "memory" {
+		return NewMemorySuppressionStore()
+	}
+	return NewDatabaseSuppressionStore(db)
+}
+
+// suppressionKey identifies a notification type of a domain on a channel
+func suppressionKey(channel string, domainID int, notificationType string) string {
+	return fmt.Sprintf("%s:%d:%s", channel, domainID, notificationType)
+}
+
+// memorySweepInterval is how often expired entries are evicted from a memory store
+const memorySweepInterval = 10 * time.Minute
+
+type memoryEntry struct {
+	sentAt    time.Time
+	expiresAt time.Time
+}
+
+// MemorySuppressionStore keeps suppression in process memory, evicting entries once
+// their window has passed
+type MemorySuppressionStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+}
+
+// NewMemorySuppressionStore creates an empty in-memory suppression store
+func NewMemorySuppressionStore() *MemorySuppressionStore {
+	return &MemorySuppressionStore{entries: make(map[string]memoryEntry)}
+}
+
+// Claim implements SuppressionStore
+func (s *MemorySuppressionStore) Claim(key string, now time.Time, window time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= memorySweepInterval {
+		for k, e := range s.entries {
+			if !now.Before(e.expiresAt) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	if e, ok := s.entries[key]; ok && now.Sub(e.sentAt) < window {
+		return false, nil
+	}
+	s.entries[key] = memoryEntry{sentAt: now, expiresAt: now.Add(window)}
+	return true, nil
+}
+
+// Release implements SuppressionStore
+func (s *MemorySuppressionStore) Release(key string, claimedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[key]; ok && e.sentAt.Equal(claimedAt) {
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+// databaseSweepInterval is how often expired rows are deleted from the database store
+const databaseSweepInterval = time.Hour
+
+// DatabaseSuppressionStore keeps suppression in the notification_suppression table
+type DatabaseSuppressionStore struct {
+	db        *sqlx.DB
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+// NewDatabaseSuppressionStore creates a suppression store backed by the database
+func NewDatabaseSuppressionStore(db *sqlx.DB) *DatabaseSuppressionStore {
+	return &DatabaseSuppressionStore{db: db}
+}
+
+// Claim implements SuppressionStore. The insert only overwrites rows older than the
+// window, so of two processes claiming at once only one may send.
+func (s *DatabaseSuppressionStore) Claim(key string, now time.Time, window time.Duration) (bool, error) {
+	now = now.Truncate(time.Microsecond) // Postgres precision, so Release matches the stored time
+	s.sweep(now)
+
+	result, err := s.db.Exec(`
+        INSERT INTO notification_suppression (key, last_sent_at, expires_at)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (key) DO UPDATE SET last_sent_at = EXCLUDED.last_sent_at, expires_at = EXCLUDED.expires_at
+        WHERE notification_suppression.last_sent_at <= $4
+    `, key, now, now.Add(window), now.Add(-window))
+	if err != nil {
+		return false, fmt.Errorf("failed to claim notification: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim notification: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// Release implements SuppressionStore
+func (s *DatabaseSuppressionStore) Release(key string, claimedAt time.Time) error {
+	claimedAt = claimedAt.Truncate(time.Microsecond)
+	_, err := s.db.Exec("DELETE FROM notification_suppression WHERE key = $1 AND last_sent_at = $2", key, claimedAt)
+	if err != nil {
+		return fmt.Errorf("failed to release notification claim: %w", err)
+	}
+	return nil
+}
+
+// sweep deletes expired rows at most once per databaseSweepInterval
+func (s *DatabaseSuppressionStore) sweep(now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.lastSweep) < databaseSweepInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastSweep = now
+	s.mu.Unlock()
+
+	// Expired rows are overwritten by later claims anyway; the sweep only bounds growth
+	s.db.Exec("DELETE FROM notification_suppression WHERE expires_at < $1", now)
+}
@@ -0,0 +1,114 @@
+package notification
+
+import (
+	"fmt"
+
+	"domain-detection-go/pkg/model"
+)
+
+// configTagTables maps each channel to the join table of its tag subscriptions
+var configTagTables = map[string]struct{ table, column string }{
+	model.ChannelTelegram: {"telegram_config_tags", "telegram_config_id"},
+	model.ChannelEmail:    {"email_config_tags", "email_config_id"},
+}
+
+// domainTags returns the tags of a domain
+func (d *Dispatcher) domainTags(domainID int) ([]string, error) {
+	var tags []string
+	err := d.db.Select(&tags, "SELECT tag FROM domain_tags WHERE domain_id = $1", domainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get domain tags: %w", err)
+	}
+	return tags, nil
+}
+
+// configTags returns the tags a config of a channel subscribes to
+func (d *Dispatcher) configTags(channel string, configID int) ([]string, error) {
+	t, ok := configTagTables[channel]
+	if !ok {
+		return nil, nil
+	}
+
+	tags := []string{}
+	err := d.db.Select(&tags, fmt.Sprintf("SELECT tag FROM %s WHERE %s = $1 ORDER BY tag", t.table, t.column), configID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config tags: %w", err)
+	}
+	return tags, nil
+}
+
+// GetConfigTags returns the tags a config subscribes to
+func (d *Dispatcher) GetConfigTags(n Notifier, configID, userID int) ([]string, error) {
+	recipient, err := findRecipient(n, userID, configID)
+	if err != nil {
+		return nil, err
+	}
+	if recipient == nil {
+		return nil, ErrConfigNotFound
+	}
+	return d.configTags(n.Channel(), configID)
+}
+
+// UpdateConfigTags replaces the tags a config subscribes to. A config with tags only
+// gets alerts for domains carrying at least one of them.
+func (d *Dispatcher) UpdateConfigTags(n Notifier, configID, userID int, tags []string) ([]string, error) {
+	t, ok := configTagTables[n.Channel()]
+	if !ok {
+		return nil, ErrUnsupportedChannel
+	}
+
+	recipient, err := findRecipient(n, userID, configID)
+	if err != nil {
+		return nil, err
+	}
+	if recipient == nil {
+		return nil, ErrConfigNotFound
+	}
+
+	tags, err = model.NormalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s = $1", t.table, t.column), configID); err != nil {
+		return nil, fmt.Errorf("failed to clear config tags: %w", err)
+	}
+	for _, tag := range tags {
+		if _, err := tx.Exec(fmt.Sprintf("INSERT INTO %s (%s, tag) VALUES ($1, $2)", t.table, t.column), configID, tag); err != nil {
+			return nil, fmt.Errorf("failed to add tag %s: %w", tag, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return tags, nil
+}
+
+// tagsMatch reports whether a domain carries at least one of the subscribed tags.
+// Recipients without subscriptions match every domain.
+func tagsMatch(subscribed, domainTags []string) bool {
+	if len(subscribed) == 0 {
+		return true
+	}
+	for _, tag := range subscribed {
+		if containsTag(domainTags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
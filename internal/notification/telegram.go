@@ -7,10 +7,9 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
-	"sync"
 	"time"
 
 	"domain-detection-go/internal/service"
@@ -19,9 +18,6 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
-// Add this constant at the top of your file
-const TIMEZONE_LOCATION = "Asia/Hong_Kong" // UTC+8
-
 // TelegramConfig holds the configuration for Telegram API
 type TelegramConfig struct {
 	APIToken string
@@ -35,13 +31,12 @@ type TelegramService struct {
 	promptService *service.TelegramPromptService
 	httpClient    *http.Client
 	rateLimiter   <-chan time.Time
-	notifyLock    sync.Mutex
-	notifyCache   map[string]time.Time // Cache to track recent notifications
-	// cacheTTL      time.Duration        // How long to suppress duplicate notifications
+	dispatcher    *Dispatcher
+	logger        *slog.Logger
 }
 
 // NewTelegramService creates a new telegram service
-func NewTelegramService(config TelegramConfig, db *sqlx.DB, promptService *service.TelegramPromptService) *TelegramService {
+func NewTelegramService(config TelegramConfig, db *sqlx.DB, promptService *service.TelegramPromptService, logger *slog.Logger) *TelegramService {
 	// Set defaults if not provided
 	if config.BaseURL == "" {
 		config.BaseURL = "https://api.telegram.org/bot"
@@ -53,11 +48,21 @@ func NewTelegramService(config TelegramConfig, db *sqlx.DB, promptService *servi
 		promptService: promptService,
 		httpClient:    &http.Client{Timeout: 10 * time.Second},
 		rateLimiter:   time.Tick(500 * time.Millisecond), // Max 2 API calls per second
-		notifyCache:   make(map[string]time.Time),
-		// cacheTTL:    1 * time.Hour, // Default: suppress same notifications for 1 hour
+		dispatcher:    NewDispatcher(db, logger),
+		logger:        logger.With("component", "telegram"),
 	}
 }
 
+// SetSuppressionStore replaces where the channel keeps its last notification times
+func (s *TelegramService) SetSuppressionStore(store SuppressionStore) {
+	s.dispatcher.SetSuppressionStore(store)
+}
+
+// SetRateLimit caps the notifications of the channel per user and hour
+func (s *TelegramService) SetRateLimit(perHour int) {
+	s.dispatcher.SetRateLimit(perHour)
+}
+
 // SetupBot initializes the bot and returns its details
 func (s *TelegramService) SetupBot() (model.TelegramBot, error) {
 	<-s.rateLimiter // Rate limiting
@@ -99,6 +104,17 @@ func (s *TelegramService) SetupBot() (model.TelegramBot, error) {
 	}, nil
 }
 
+// Configured reports whether a bot token is set
+func (s *TelegramService) Configured() bool {
+	return s.config.APIToken != ""
+}
+
+// Ping verifies that the Telegram API is reachable and accepts the bot token
+func (s *TelegramService) Ping() error {
+	_, err := s.SetupBot()
+	return err
+}
+
 // JoinGroup attempts to join a Telegram group via invite link
 // Note: This cannot be done purely via API; the user must click the invitation link
 func (s *TelegramService) JoinGroup(inviteLink string) (string, error) {
@@ -107,8 +123,7 @@ func (s *TelegramService) JoinGroup(inviteLink string) (string, error) {
 	parts := strings.Split(inviteLink, "/")
 	inviteCode := parts[len(parts)-1]
 
-	log.Printf("Bot must be manually added to group with invite code: %s", inviteCode)
-	log.Printf("Please provide instructions to the user on how to add the bot manually")
+	s.logger.Info("Bot must be manually added to the group", "invite_code", inviteCode)
 
 	return inviteCode, nil
 }
@@ -199,7 +214,7 @@ func (s *TelegramService) GetTelegramConfigsForUser(userID int) ([]model.Telegra
 
 	// Query base configurations
 	err := s.db.Select(&configs, `
-        SELECT id, user_id, chat_id, chat_name, language, is_active, notify_on_down, notify_on_up, created_at, updated_at
+        SELECT id, user_id, chat_id, chat_name, language, is_active, notify_on_down, notify_on_up, timezone, external_id, created_at, updated_at
         FROM telegram_configs
         WHERE user_id = $1
         ORDER BY created_at DESC
@@ -223,6 +238,12 @@ func (s *TelegramService) GetTelegramConfigsForUser(userID int) ([]model.Telegra
 		}
 
 		configs[i].MonitorRegions = regions
+
+		tags, err := s.dispatcher.configTags(s.Channel(), configs[i].ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tags for config %d: %w", configs[i].ID, err)
+		}
+		configs[i].Tags = tags
 	}
 
 	return configs, nil
@@ -240,26 +261,43 @@ func (s *TelegramService) UpdateTelegramConfig(
 	isActive bool,
 	monitorRegions []string,
 ) error {
-	// Set default language if not provided
-	if language == "" {
-		language = "en"
-	}
-
-	// Start a transaction
 	tx, err := s.db.Beginx()
 	if err != nil {
 		return fmt.Errorf("failed to start transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	// Rollback transaction if any error occurs
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
+	err = updateTelegramConfigTx(tx, configID, userID, chatID, chatName, language, notifyOnDown, notifyOnUp, isActive, monitorRegions)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// updateTelegramConfigTx writes a Telegram configuration and its regions within tx
+func updateTelegramConfigTx(
+	tx *sqlx.Tx,
+	configID,
+	userID int,
+	chatID,
+	chatName string,
+	language string,
+	notifyOnDown,
+	notifyOnUp bool,
+	isActive bool,
+	monitorRegions []string,
+) error {
+	// Set default language if not provided
+	if language == "" {
+		language = "en"
+	}
 
 	// Update the config
-	_, err = tx.Exec(`
+	_, err := tx.Exec(`
         UPDATE telegram_configs
         SET chat_id = $1,
             chat_name = $2,
@@ -310,11 +348,6 @@ func (s *TelegramService) UpdateTelegramConfig(
 		}
 	}
 
-	// Commit the transaction
-	if err = tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	return nil
 }
 
@@ -332,211 +365,186 @@ func (s *TelegramService) DeleteTelegramConfig(configID, userID int) error {
 	return nil
 }
 
-// SendDomainStatusNotification sends a notification about domain status change
-func (s *TelegramService) SendDomainStatusNotification(domain model.Domain, statusChanged bool) error {
-	// Get all active telegram configs for this domain's user
-	var configs []struct {
-		ID             int      `db:"id"`
-		ChatID         string   `db:"chat_id"`
-		ChatName       string   `db:"chat_name"`
-		Language       string   `db:"language"`
-		IsActive       bool     `db:"is_active"`
-		NotifyOnUp     bool     `db:"notify_on_up"`
-		NotifyOnDown   bool     `db:"notify_on_down"`
-		MonitorRegions []string `db:"monitor_regions"`
-	}
+// SendDomainStatus sends a notification about domain status change
+func (s *TelegramService) SendDomainStatus(domain model.Domain, statusChanged bool) error {
+	return s.dispatcher.Dispatch(s, domain, statusChanged)
+}
 
-	// First get basic config info
-	err := s.db.Select(&configs, `
-        SELECT tc.id, tc.chat_id, tc.chat_name, tc.language, tc.is_active, tc.notify_on_up, tc.notify_on_down
-        FROM telegram_configs tc
-        WHERE tc.user_id = $1
-    `, domain.UserID)
+// FlushDigests delivers due digests to digest-only Telegram configs
+func (s *TelegramService) FlushDigests() error {
+	return s.dispatcher.FlushDigests(s)
+}
 
-	if err != nil {
-		log.Printf("Failed to get Telegram configurations for user %d: %v", domain.UserID, err)
-		return fmt.Errorf("failed to get Telegram configurations for user: %w", err)
-	}
+// GetDigestSettings returns the digest settings of a Telegram config
+func (s *TelegramService) GetDigestSettings(configID, userID int) (*model.DigestSettings, error) {
+	return s.dispatcher.GetDigestSettings(s, configID, userID)
+}
 
-	// Then get the regions for each config
-	for i := range configs {
-		var regions []string
-		err := s.db.Select(&regions, `
-            SELECT region_code
-            FROM telegram_config_regions
-            WHERE telegram_config_id = $1
-        `, configs[i].ID)
+// UpdateDigestSettings updates the digest settings of a Telegram config
+func (s *TelegramService) UpdateDigestSettings(configID, userID int, req model.DigestSettingsRequest) error {
+	return s.dispatcher.UpdateDigestSettings(s, configID, userID, req)
+}
 
-		if err != nil {
-			log.Printf("Failed to get regions for config %d: %v", configs[i].ID, err)
-			continue
-		}
+// GetLanguageFallbacks returns the language fallback chain of a Telegram config
+func (s *TelegramService) GetLanguageFallbacks(configID, userID int) (*model.LanguageFallbacks, error) {
+	return s.dispatcher.GetLanguageFallbacks(s, configID, userID)
+}
 
-		configs[i].MonitorRegions = regions
-	}
+// UpdateLanguageFallbacks sets the language fallback chain of a Telegram config
+func (s *TelegramService) UpdateLanguageFallbacks(configID, userID int, req model.LanguageFallbacksRequest) error {
+	return s.dispatcher.UpdateLanguageFallbacks(s, s.promptService, configID, userID, req)
+}
 
-	if len(configs) == 0 {
-		log.Printf("No Telegram configurations for user %d", domain.UserID)
-		return nil
-	}
+// GetConfigTags returns the domain tags a Telegram config subscribes to
+func (s *TelegramService) GetConfigTags(configID, userID int) ([]string, error) {
+	return s.dispatcher.GetConfigTags(s, configID, userID)
+}
 
-	// Determine notification type
-	notificationType := "status"
-	if !domain.Available() {
-		notificationType = "down"
-	} else if statusChanged {
-		notificationType = "up"
-	}
+// UpdateConfigTags replaces the domain tags a Telegram config subscribes to
+func (s *TelegramService) UpdateConfigTags(configID, userID int, tags []string) ([]string, error) {
+	return s.dispatcher.UpdateConfigTags(s, configID, userID, tags)
+}
 
-	// Check if we should send notification based on history and rate limiting
-	s.notifyLock.Lock()
-	defer s.notifyLock.Unlock()
+// GetQuietHours returns the quiet hours of a Telegram config
+func (s *TelegramService) GetQuietHours(configID, userID int) (*model.QuietHours, error) {
+	return s.dispatcher.GetQuietHours(s, configID, userID)
+}
 
-	// Calculate suppression duration based on domain's interval
-	suppressionDuration := time.Duration(domain.Interval) * time.Minute
+// UpdateQuietHours replaces the quiet hours of a Telegram config
+func (s *TelegramService) UpdateQuietHours(configID, userID int, req model.QuietHoursRequest) (*model.QuietHours, error) {
+	return s.dispatcher.UpdateQuietHours(s, configID, userID, req)
+}
 
-	// For UP/DOWN status changes, use a shorter suppression period (half of regular interval)
-	if !domain.Available() || statusChanged {
-		suppressionDuration = suppressionDuration / 2
-	}
+// GetConfigTimezone returns the timezone preference of a Telegram config
+func (s *TelegramService) GetConfigTimezone(configID, userID int) (*model.ConfigTimezone, error) {
+	return s.dispatcher.GetConfigTimezone(s, configID, userID)
+}
 
-	// Set a minimum suppression time to avoid flooding
-	minSuppression := 2 * time.Minute
-	if suppressionDuration < minSuppression {
-		suppressionDuration = minSuppression
-	}
+// UpdateConfigTimezone sets the timezone preference of a Telegram config
+func (s *TelegramService) UpdateConfigTimezone(configID, userID int, timezone string) (*model.ConfigTimezone, error) {
+	return s.dispatcher.UpdateConfigTimezone(s, configID, userID, timezone)
+}
 
-	// Check if we've recently sent the same notification
-	cacheKey := fmt.Sprintf("%d:%s", domain.ID, notificationType)
-	now := time.Now()
-	if lastSent, exists := s.notifyCache[cacheKey]; exists {
-		timeSinceLast := now.Sub(lastSent)
-		if timeSinceLast < suppressionDuration {
-			log.Printf("Skipping notification for domain %s (%s): last sent %s ago, suppression duration: %s",
-				domain.Name, notificationType, timeSinceLast, suppressionDuration)
-			return nil
-		}
-	}
+// Channel implements Notifier
+func (s *TelegramService) Channel() string {
+	return "telegram"
+}
 
-	// Create base message templates with prompt keys
-	var baseMessage string
-	if !domain.Available() {
-		baseMessage = "{emoji} telegram.label.domain {domain} telegram.message.domain_down\n\ntelegram.label.status: {status}\ntelegram.label.error: {error}\ntelegram.label.response_time: {response_time}ms\ntelegram.label.last_check: {last_check} (UTC+8)"
-	} else if statusChanged {
-		baseMessage = "{emoji} telegram.label.domain {domain} telegram.message.domain_up\n\ntelegram.label.status: {status}\ntelegram.label.response_time: {response_time}ms\ntelegram.label.last_check: {last_check} (UTC+8)"
-	} else {
-		baseMessage = "{emoji} telegram.label.domain {domain} telegram.message.domain_status\n\ntelegram.label.status: {status}\ntelegram.label.response_time: {response_time}ms\ntelegram.label.last_check: {last_check} (UTC+8)"
+// HistoryColumn implements Notifier
+func (s *TelegramService) HistoryColumn() string {
+	return "telegram_config_id"
+}
+
+// GetRecipients implements Notifier
+func (s *TelegramService) GetRecipients(userID int) ([]Recipient, error) {
+	configs, err := s.GetTelegramConfigsForUser(userID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Create time formatting
-	loc, err := time.LoadLocation(TIMEZONE_LOCATION)
+	userTimezone, err := s.dispatcher.userTimezone(userID)
 	if err != nil {
-		loc = time.FixedZone("UTC+8", 8*60*60)
+		s.logger.Error("Failed to get user timezone", "user_id", userID, "error", err)
 	}
-	formattedTime := domain.LastCheck.In(loc).Format("2006-01-02 15:04:05")
 
-	// Send to all configured chats that match notification preferences
+	recipients := make([]Recipient, 0, len(configs))
 	for _, config := range configs {
-		// Skip if telegram config is not active
-		if !config.IsActive {
-			log.Printf("Skipping notification for domain %s to chat %s: Telegram config is inactive",
-				domain.Name, config.ChatName)
-			continue
-		}
+		recipients = append(recipients, Recipient{
+			ConfigID:       config.ID,
+			Address:        config.ChatID,
+			Label:          config.ChatName,
+			Language:       config.Language,
+			IsActive:       config.IsActive,
+			NotifyOnUp:     config.NotifyOnUp,
+			NotifyOnDown:   config.NotifyOnDown,
+			MonitorRegions: config.MonitorRegions,
+			Tags:           config.Tags,
+			Timezone:       effectiveTimezone(config.Timezone, userTimezone),
+		})
+	}
+	return recipients, nil
+}
 
-		// Skip if region doesn't match (if regions are specified)
-		if len(config.MonitorRegions) > 0 {
-			regionMatches := false
-			for _, region := range config.MonitorRegions {
-				if region == domain.Region {
-					regionMatches = true
-					break
-				}
-			}
+// Send implements Notifier
+func (s *TelegramService) Send(recipient Recipient, notificationType string, domain model.Domain, formattedTime string) (string, error) {
+	// Escalations are down alerts headed by how long the outage has lasted
+	escalated := notificationType == NotificationTypeEscalation
+	if escalated {
+		notificationType = "down"
+	}
 
-			if !regionMatches {
-				log.Printf("Skipping notification for domain %s to chat %s: Domain region %s not in monitor regions %v",
-					domain.Name, config.ChatName, domain.Region, config.MonitorRegions)
-				continue
-			}
-		}
+	// Create base message templates with prompt keys
+	var baseMessage string
+	switch notificationType {
+	case "down":
+		baseMessage = "{emoji} telegram.label.domain {domain} telegram.message.domain_down\n\ntelegram.label.status: {status}\ntelegram.label.error: {error}\ntelegram.label.response_time: {response_time}ms\ntelegram.label.last_check: {last_check}"
+	case NotificationTypeBlocked:
+		baseMessage = "🚫 telegram.label.domain {domain} telegram.message.domain_blocked\n\ntelegram.label.error: {error}\ntelegram.label.last_check: {last_check}"
+	case "up":
+		baseMessage = "{emoji} telegram.label.domain {domain} telegram.message.domain_up\n\ntelegram.label.status: {status}\ntelegram.label.response_time: {response_time}ms\ntelegram.label.last_check: {last_check}"
+	default:
+		baseMessage = "{emoji} telegram.label.domain {domain} telegram.message.domain_status\n\ntelegram.label.status: {status}\ntelegram.label.response_time: {response_time}ms\ntelegram.label.last_check: {last_check}"
+	}
 
-		// Skip "up" notifications if notify_on_up is disabled
-		if notificationType == "up" && !config.NotifyOnUp {
-			log.Printf("Skipping 'back up' notification for domain %s to chat %s: notify_on_up is disabled",
-				domain.Name, config.ChatName)
-			continue
-		}
+	// Format message using prompt replacement for this specific language
+	message := s.formatMessage(baseMessage, service.LanguageChain(recipient.Language, recipient.Fallbacks), domain, formattedTime)
 
-		// Skip "down" notifications if notify_on_down is disabled
-		if notificationType == "down" && !config.NotifyOnDown {
-			log.Printf("Skipping 'down' notification for domain %s to chat %s: notify_on_down is disabled",
-				domain.Name, config.ChatName)
-			continue
+	// Reference the incident so follow-up messages can be matched to the outage
+	if domain.Incident != nil {
+		if notificationType == "up" && !domain.Incident.IsOpen() {
+			message += fmt.Sprintf("\n\n🆔 Incident #%d resolved after %s", domain.Incident.ID, formatIncidentDuration(domain.Incident.Duration()))
+		} else {
+			message += fmt.Sprintf("\n\n🆔 Incident #%d", domain.Incident.ID)
 		}
-
-		// Check notification history in database
-		var lastNotification time.Time
-		err := s.db.Get(&lastNotification, `
-            SELECT MAX(notified_at) 
-            FROM notification_history
-            WHERE domain_id = $1 AND telegram_config_id = $2 AND notification_type = $3
-        `, domain.ID, config.ID, notificationType)
-
-		if err == nil && !lastNotification.IsZero() {
-			// Skip if we've notified this chat about this domain recently
-			if now.Sub(lastNotification) < suppressionDuration {
-				log.Printf("Skipping notification to chat %s for domain %s: last sent at %s (suppression: %s)",
-					config.ChatName, domain.Name, lastNotification, suppressionDuration)
-				continue
-			}
+		if escalated {
+			message = fmt.Sprintf("⏫ Escalation: down for %s\n\n", formatIncidentDuration(domain.Incident.Duration())) + message
 		}
+	}
 
-		// Get language for this config, default to English
-		language := config.Language
-		if language == "" {
-			language = "en"
+	// Attach the remediation runbook: notes in the text, the link as a button
+	var keyboard [][]TelegramInlineKeyboardButton
+	if isDownAlert(notificationType) && domain.Runbook != nil {
+		if domain.Runbook.Notes != "" {
+			message += "\n\n📋 Runbook:\n" + domain.Runbook.Notes
 		}
-
-		// Format message using prompt replacement for this specific language
-		message := s.formatMessage(baseMessage, language, domain, formattedTime)
-
-		// Send message to this chat
-		if err := s.sendTelegramMessage(config.ChatID, message); err != nil {
-			log.Printf("Failed to send Telegram notification to chat %s: %v", config.ChatName, err)
-			continue
+		if domain.Runbook.URL != "" {
+			keyboard = [][]TelegramInlineKeyboardButton{{{Text: "📖 Open runbook", URL: domain.Runbook.URL}}}
 		}
+	}
 
-		// Record notification in database
-		_, err = s.db.Exec(`
-            INSERT INTO notification_history
-            (domain_id, telegram_config_id, status_code, error_code, error_description, notified_at, notification_type)
-            VALUES ($1, $2, $3, $4, $5, NOW(), $6)
-        `, domain.ID, config.ID, domain.LastStatus, domain.ErrorCode, domain.ErrorDescription, notificationType)
-
-		if err != nil {
-			log.Printf("Failed to record notification history: %v", err)
+	// Let whoever takes care of the outage pause its further alerts, or silence the
+	// domain for a while
+	if isDownAlert(notificationType) {
+		var row []TelegramInlineKeyboardButton
+		if domain.Incident != nil && domain.Incident.IsOpen() {
+			row = append(row, TelegramInlineKeyboardButton{Text: "✅ Acknowledge", CallbackData: fmt.Sprintf("%s%d", IncidentAckCallbackPrefix, domain.Incident.ID)})
 		}
-
-		// Update cache with current timestamp
-		s.notifyCache[cacheKey] = now
+		row = append(row, TelegramInlineKeyboardButton{Text: "🔕 Snooze 1h", CallbackData: fmt.Sprintf("%s%d", SnoozeCallbackPrefix, domain.ID)})
+		keyboard = append(keyboard, row)
 	}
 
-	return nil
+	return s.postTelegramMessage(recipient.Address, message, keyboard)
 }
 
-// formatMessage replaces all prompt keys in the message with translations
-func (s *TelegramService) formatMessage(message, language string, domain model.Domain, formattedTime string) string {
+// IncidentAckCallbackPrefix prefixes the callback data of the acknowledge button on down
+// alerts, followed by the incident ID
+const IncidentAckCallbackPrefix = "incident_ack_"
+
+// SendDigest implements Notifier
+func (s *TelegramService) SendDigest(recipient Recipient, digest model.Digest) error {
+	return s.sendTelegramMessage(recipient.Address, formatDigestText(digest, recipient.Timezone))
+}
+
+// formatMessage replaces all prompt keys in the message with translations,
+// using the first language of the chain that has each message
+func (s *TelegramService) formatMessage(message string, languages []string, domain model.Domain, formattedTime string) string {
+	language := languages[0]
+
 	// Get all prompts
-	prompts, err := s.promptService.GetAllPromptsByLanguage(language)
+	prompts, err := s.promptService.GetAllPromptsByLanguageChain(languages)
 	if err != nil {
-		log.Printf("Failed to get prompts for language %s: %v", language, err)
-		// Fallback to English if language prompts not found
-		prompts, err = s.promptService.GetAllPromptsByLanguage("en")
-		if err != nil {
-			log.Printf("Failed to get English prompts as fallback: %v", err)
-			return message // Return original message if no prompts found
-		}
+		s.logger.Error("Failed to get prompts", "languages", languages, "error", err)
+		return message // Return original message if no prompts found
 	}
 
 	promptsEn, _ := s.promptService.GetAllPromptsByLanguage("en")
@@ -556,52 +564,33 @@ func (s *TelegramService) formatMessage(message, language string, domain model.D
 	}
 
 	// Replace domain-specific placeholders (no escaping needed for plain text)
-	message = strings.ReplaceAll(message, "{domain}", domain.Name)
+	message = strings.ReplaceAll(message, "{domain}", domain.DisplayName())
 	message = strings.ReplaceAll(message, "{status}", fmt.Sprintf("%d", domain.LastStatus))
 	message = strings.ReplaceAll(message, "{error}", domain.ErrorDescription)
 	message = strings.ReplaceAll(message, "{response_time}", fmt.Sprintf("%d", domain.TotalTime))
 	message = strings.ReplaceAll(message, "{last_check}", formattedTime)
 
 	// Additional pass: Replace any English text that appears directly in the message
-	// Debug: Print all prompts
-	log.Printf("DEBUG: Total prompts found: %d", len(prompts))
-	for i, prompt := range prompts {
-		log.Printf("DEBUG: Prompt %d - Key: %s", i, prompt.PromptKey)
-		for lang, msg := range prompt.Messages {
-			log.Printf("DEBUG: Prompt %d - Language: %s, Message: %s", i, lang, msg)
-		}
-	}
-
-	// Debug: Print current message before replacement
-	log.Printf("DEBUG: Current message before English replacement: %s", message)
+	s.logger.Debug("Translating message", "language", language, "prompts", len(prompts))
 
 	for _, promptEn := range promptsEn {
 		if enMsg, exists := promptEn.Messages["en"]; exists && enMsg != "" {
-			log.Printf("DEBUG: Checking English message: '%s'", enMsg)
 			if strings.Contains(message, enMsg) {
-				log.Printf("DEBUG: Found English text '%s' in message for language '%s'", enMsg, language)
-
 				// Find corresponding prompt in target language prompts
 				for _, prompt := range prompts {
 					if prompt.PromptKey == promptEn.PromptKey {
 						if msg, exists := prompt.Messages[language]; exists && msg != "" {
-							log.Printf("DEBUG: Replacing '%s' with '%s'", enMsg, msg)
 							message = strings.ReplaceAll(message, enMsg, msg)
 						} else {
-							log.Printf("DEBUG: No translation found for language '%s', keeping English text '%s'", language, enMsg)
+							s.logger.Debug("No translation found, keeping English text", "language", language, "prompt_key", prompt.PromptKey)
 						}
 						break
 					}
 				}
-			} else {
-				log.Printf("DEBUG: English text '%s' not found in message", enMsg)
 			}
 		}
 	}
 
-	// Debug: Print final message after replacement
-	log.Printf("DEBUG: Final message after English replacement: %s", message)
-
 	// Handle emoji for status updates
 	if strings.Contains(message, "{emoji}") {
 		emoji := "🟢"
@@ -616,11 +605,21 @@ func (s *TelegramService) formatMessage(message, language string, domain model.D
 
 // sendTelegramMessage sends a text message to a specific Telegram chat
 func (s *TelegramService) sendTelegramMessage(chatID, message string) error {
+	return s.sendTelegramMessageWithKeyboard(chatID, message, nil)
+}
+
+// sendTelegramMessageWithKeyboard sends a plain text message with an optional inline keyboard
+func (s *TelegramService) sendTelegramMessageWithKeyboard(chatID, message string, keyboard [][]TelegramInlineKeyboardButton) error {
+	_, err := s.postTelegramMessage(chatID, message, keyboard)
+	return err
+}
+
+// postTelegramMessage sends a plain text message with an optional inline keyboard and
+// returns the ID Telegram assigned to it
+func (s *TelegramService) postTelegramMessage(chatID, message string, keyboard [][]TelegramInlineKeyboardButton) (string, error) {
 	<-s.rateLimiter // Rate limiting
 
-	// Debug: Print the message before sending
-	log.Printf("DEBUG: Sending message to chat %s:\n%s", chatID, message)
-	log.Printf("DEBUG: Message length: %d bytes", len(message))
+	s.logger.Debug("Sending message", "chat_id", chatID, "length", len(message), "text", message)
 
 	url := fmt.Sprintf("%s%s/sendMessage", s.config.BaseURL, s.config.APIToken)
 
@@ -630,20 +629,25 @@ func (s *TelegramService) sendTelegramMessage(chatID, message string) error {
 		"text":    message,
 		// Remove parse_mode to send as plain text
 	}
+	if len(keyboard) > 0 {
+		requestBody["reply_markup"] = map[string]interface{}{
+			"inline_keyboard": keyboard,
+		}
+	}
 
 	jsonData, err := json.Marshal(requestBody)
 	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
+		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	resp, err := s.httpClient.Post(url, "application/json", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return fmt.Errorf("failed to send message: %w", err)
+		return "", fmt.Errorf("failed to send message: %w", err)
 	}
 	defer resp.Body.Close()
 
+	body, _ := ioutil.ReadAll(resp.Body)
 	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
 
 		// Check for group migration error
 		if resp.StatusCode == 400 {
@@ -662,24 +666,32 @@ func (s *TelegramService) sendTelegramMessage(chatID, message string) error {
 
 					// Extract new chat ID
 					newChatID := fmt.Sprintf("%d", errorResponse.Parameters.MigrateToChatID)
-					log.Printf("Group migrated to supergroup. Old ID: %s, New ID: %s", chatID, newChatID)
+					s.logger.Info("Group migrated to supergroup", "chat_id", chatID, "new_chat_id", newChatID)
 
 					// Update the chat ID in database
 					err := s.updateChatID(chatID, newChatID)
 					if err != nil {
-						log.Printf("Failed to update chat ID in database: %v", err)
+						s.logger.Error("Failed to update chat ID", "chat_id", chatID, "error", err)
 					}
 
 					// Try again with the new chat ID
-					return s.sendTelegramMessage(newChatID, message)
+					return s.postTelegramMessage(newChatID, message, keyboard)
 				}
 			}
 		}
 
-		return fmt.Errorf("telegram API error (status %d): %s", resp.StatusCode, string(body))
+		return "", fmt.Errorf("telegram API error (status %d): %s", resp.StatusCode, string(body))
 	}
 
-	return nil
+	var sent struct {
+		Result struct {
+			MessageID int64 `json:"message_id"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &sent); err != nil || sent.Result.MessageID == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("%d", sent.Result.MessageID), nil
 }
 
 // Add this new method to update the chat ID in database
@@ -694,7 +706,7 @@ func (s *TelegramService) updateChatID(oldChatID, newChatID string) error {
 		return fmt.Errorf("failed to update chat ID: %w", err)
 	}
 
-	log.Printf("Successfully updated chat ID from %s to %s in database", oldChatID, newChatID)
+	s.logger.Info("Updated chat ID", "chat_id", oldChatID, "new_chat_id", newChatID)
 	return nil
 }
 
@@ -707,13 +719,12 @@ func (s *TelegramService) SendTelegramMessageToConfig(config model.TelegramConfi
 
 	// If you want to include a timestamp in test messages:
 	if strings.Contains(message, "Test Message") {
-		loc, err := time.LoadLocation(TIMEZONE_LOCATION)
+		userTimezone, err := s.dispatcher.userTimezone(config.UserID)
 		if err != nil {
-			loc = time.FixedZone("UTC+8", 8*60*60)
+			s.logger.Error("Failed to get user timezone", "user_id", config.UserID, "error", err)
 		}
-
-		now := time.Now().In(loc)
-		message += fmt.Sprintf("\n\nSent at: %s (UTC+8)", now.Format("2006-01-02 15:04:05"))
+		timezone := effectiveTimezone(config.Timezone, userTimezone)
+		message += fmt.Sprintf("\n\nSent at: %s", formatLocalTime(time.Now(), timezone, "2006-01-02 15:04:05"))
 	}
 
 	// Send the message
@@ -731,7 +742,7 @@ func (s *TelegramService) SendTelegramMessageToConfig(config model.TelegramConfi
     `, 0, config.ID, "test", now)
 
 	if err != nil {
-		log.Printf("Failed to record test notification in history: %v", err)
+		s.logger.Error("Failed to record test notification", "config_id", config.ID, "error", err)
 		// Continue despite error in recording history
 	}
 
@@ -741,10 +752,12 @@ func (s *TelegramService) SendTelegramMessageToConfig(config model.TelegramConfi
 // GetUserIDByChatID finds user ID by chat ID
 func (s *TelegramService) GetUserIDByChatID(chatID string) (int, error) {
 	var userID int
+	// Chats of accounts being deleted act as unlinked
 	err := s.db.Get(&userID, `
-        SELECT user_id 
-        FROM telegram_configs 
-        WHERE chat_id = $1 AND is_active = true
+        SELECT tc.user_id
+        FROM telegram_configs tc
+        JOIN users u ON u.id = tc.user_id
+        WHERE tc.chat_id = $1 AND tc.is_active = true AND u.deletion_requested_at IS NULL
         LIMIT 1
     `, chatID)
 
@@ -763,6 +776,7 @@ func (s *TelegramService) SendMessage(chatID, message string) error {
 type TelegramInlineKeyboardButton struct {
 	Text         string `json:"text"`
 	CallbackData string `json:"callback_data,omitempty"`
+	URL          string `json:"url,omitempty"`
 }
 
 // SendMessageWithKeyboard sends a message with inline keyboard
@@ -825,12 +839,25 @@ func (s *TelegramService) AnswerCallbackQuery(callbackQueryID, text string) erro
 	return nil
 }
 
-// SendMultipleCustomMessages sends multiple custom messages to user's Telegram configs
-func (s *TelegramService) SendMultipleCustomMessages(userID int, messages []string) error {
-	if len(messages) == 0 {
-		return fmt.Errorf("no messages to send")
+// SendTest sends a test message to a Telegram config owned by the user
+func (s *TelegramService) SendTest(configID, userID int) error {
+	configs, err := s.GetTelegramConfigsForUser(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user Telegram configs: %w", err)
 	}
 
+	for _, config := range configs {
+		if config.ID == configID {
+			message := "🧪 Test Message\n\nThis is a test message from your Domain Detection service. If you're receiving this, your Telegram notifications are configured correctly."
+			return s.SendTelegramMessageToConfig(config, message)
+		}
+	}
+	return ErrConfigNotFound
+}
+
+// SendCustom sends a custom message to the user's active Telegram configs, rendered
+// in the language of each config
+func (s *TelegramService) SendCustom(userID int, render MessageRenderer) error {
 	// Get user's Telegram configurations
 	configs, err := s.GetTelegramConfigsForUser(userID)
 	if err != nil {
@@ -838,7 +865,7 @@ func (s *TelegramService) SendMultipleCustomMessages(userID int, messages []stri
 	}
 
 	if len(configs) == 0 {
-		log.Printf("No Telegram configs found for user %d", userID)
+		s.logger.Info("No Telegram configs found", "user_id", userID)
 		return fmt.Errorf("no Telegram configs found for user %d", userID)
 	}
 
@@ -848,90 +875,60 @@ func (s *TelegramService) SendMultipleCustomMessages(userID int, messages []stri
 	// Send messages to all active configs
 	for _, config := range configs {
 		if !config.IsActive {
-			log.Printf("Skipping inactive Telegram config %d for user %d", config.ID, userID)
+			s.logger.Debug("Skipping inactive Telegram config", "config_id", config.ID, "user_id", userID)
 			continue
 		}
 
-		log.Printf("Sending %d custom messages to Telegram chat %s for user %d", len(messages), config.ChatID, userID)
-
-		// Send each message with a small delay to avoid rate limits
-		for i, message := range messages {
-			if err := s.sendMessage(config.ChatID, message); err != nil {
-				log.Printf("Failed to send message %d/%d to chat %s: %v", i+1, len(messages), config.ChatID, err)
-				lastError = err
-				break // Stop sending remaining messages to this config on error
-			}
+		language := config.Language
+		if language == "" {
+			language = service.DefaultLanguage
+		}
+		messages := render(language).Text
+		if len(messages) == 0 {
+			continue
+		}
 
-			log.Printf("Successfully sent message %d/%d to chat %s", i+1, len(messages), config.ChatID)
+		s.logger.Debug("Sending custom messages", "count", len(messages), "config_id", config.ID, "chat_id", config.ChatID, "user_id", userID)
 
-			// Add small delay between messages to avoid rate limits
-			if i < len(messages)-1 {
-				time.Sleep(100 * time.Millisecond)
-			}
+		if err := s.SendMultipleMessagesToConfig(config, messages); err != nil {
+			s.logger.Error("Failed to send custom messages", "config_id", config.ID, "error", err)
+			lastError = err
+			continue
 		}
 
-		if lastError == nil {
-			sentCount++
-		}
+		sentCount++
 	}
 
 	if sentCount == 0 {
 		if lastError != nil {
-			return fmt.Errorf("failed to send messages to any Telegram config: %w", lastError)
+			return fmt.Errorf("failed to send message to any Telegram config: %w", lastError)
 		}
 		return fmt.Errorf("no active Telegram configs found for user %d", userID)
 	}
 
-	log.Printf("Successfully sent custom messages to %d/%d Telegram configs for user %d",
-		sentCount, len(configs), userID)
+	s.logger.Info("Sent custom message", "sent", sentCount, "configs", len(configs), "user_id", userID)
 	return nil
 }
 
-// SendCustomMessage sends a custom message to user's Telegram configs
-func (s *TelegramService) SendCustomMessage(userID int, message string) error {
-	// Get user's Telegram configurations
+// SendCustomToChat sends a custom message to one of the user's active Telegram chats,
+// rendered in the language of its config
+func (s *TelegramService) SendCustomToChat(userID int, chatID string, render MessageRenderer) error {
 	configs, err := s.GetTelegramConfigsForUser(userID)
 	if err != nil {
 		return fmt.Errorf("failed to get user Telegram configs: %w", err)
 	}
 
-	if len(configs) == 0 {
-		log.Printf("No Telegram configs found for user %d", userID)
-		return fmt.Errorf("no Telegram configs found for user %d", userID)
-	}
-
-	var sentCount int
-	var lastError error
-
-	// Send message to all active configs
 	for _, config := range configs {
-		if !config.IsActive {
-			log.Printf("Skipping inactive Telegram config %d for user %d", config.ID, userID)
-			continue
-		}
-
-		log.Printf("Sending custom message to Telegram chat %s for user %d", config.ChatID, userID)
-
-		if err := s.sendMessage(config.ChatID, message); err != nil {
-			log.Printf("Failed to send custom message to chat %s: %v", config.ChatID, err)
-			lastError = err
+		if config.ChatID != chatID || !config.IsActive {
 			continue
 		}
-
-		sentCount++
-		log.Printf("Successfully sent custom message to chat %s", config.ChatID)
-	}
-
-	if sentCount == 0 {
-		if lastError != nil {
-			return fmt.Errorf("failed to send message to any Telegram config: %w", lastError)
+		language := config.Language
+		if language == "" {
+			language = service.DefaultLanguage
 		}
-		return fmt.Errorf("no active Telegram configs found for user %d", userID)
+		return s.SendMultipleMessagesToConfig(config, render(language).Text)
 	}
-
-	log.Printf("Successfully sent custom message to %d/%d Telegram configs for user %d",
-		sentCount, len(configs), userID)
-	return nil
+	return fmt.Errorf("no active Telegram config found for chat %s", chatID)
 }
 
 // sendMessage sends a message to a specific chat ID (helper method)
@@ -975,11 +972,11 @@ func (s *TelegramService) sendMessage(chatID, message string) error {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		log.Printf("Telegram API error: Status %d, Body: %s", resp.StatusCode, string(body))
+		s.logger.Error("Telegram API error", "status", resp.StatusCode, "body", string(body))
 		return fmt.Errorf("telegram API returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	log.Printf("Message sent successfully to chat %s", chatID)
+	s.logger.Debug("Message sent", "chat_id", chatID)
 	return nil
 }
 
@@ -993,10 +990,10 @@ func (s *TelegramService) SendMultipleMessagesToConfig(config model.TelegramConf
 	var lastError error
 
 	for i, message := range messages {
-		log.Printf("Sending message %d/%d to chat %s", i+1, len(messages), config.ChatID)
+		s.logger.Debug("Sending message", "index", i+1, "count", len(messages), "chat_id", config.ChatID)
 
 		if err := s.sendMessage(config.ChatID, message); err != nil {
-			log.Printf("Failed to send message %d to chat %s: %v", i+1, config.ChatID, err)
+			s.logger.Error("Failed to send message", "index", i+1, "config_id", config.ID, "error", err)
 			lastError = err
 			continue
 		}
@@ -1014,7 +1011,7 @@ func (s *TelegramService) SendMultipleMessagesToConfig(config model.TelegramConf
 	}
 
 	if sentCount < len(messages) {
-		log.Printf("WARNING: Only sent %d/%d messages to chat %s", sentCount, len(messages), config.ChatID)
+		s.logger.Warn("Only some messages were sent", "sent", sentCount, "count", len(messages), "config_id", config.ID)
 	}
 
 	return nil
@@ -0,0 +1,114 @@
+package notification
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"domain-detection-go/pkg/model"
+)
+
+// linkCodeTTL is how long a link code can be sent to the bot
+const linkCodeTTL = 10 * time.Minute
+
+// linkCodeEncoding renders codes without padding or easily confused characters
+var linkCodeEncoding = base32.NewEncoding("ABCDEFGHJKMNPQRSTUVWXYZ023456789").WithPadding(base32.NoPadding)
+
+// hashLinkCode returns the stored form of a link code, ignoring case and spacing
+func hashLinkCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateLinkCode issues a code the user sends to the bot to link a chat to the account.
+// Codes still waiting to be used are replaced.
+func (s *TelegramService) CreateLinkCode(userID int) (*model.TelegramLinkCode, error) {
+	raw := make([]byte, 5)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate link code: %w", err)
+	}
+	code := linkCodeEncoding.EncodeToString(raw)
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM telegram_link_codes WHERE user_id = $1 AND used_at IS NULL", userID); err != nil {
+		return nil, fmt.Errorf("failed to clear link codes: %w", err)
+	}
+
+	link := &model.TelegramLinkCode{Code: code[:4] + "-" + code[4:]}
+	err = tx.QueryRow(`
+        INSERT INTO telegram_link_codes (user_id, code_hash, expires_at)
+        VALUES ($1, $2, NOW() + make_interval(secs => $3))
+        RETURNING expires_at
+    `, userID, hashLinkCode(code), linkCodeTTL.Seconds()).Scan(&link.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store link code: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return link, nil
+}
+
+// LinkChat consumes a link code and registers the chat as an active Telegram
+// configuration of the code's user. It returns the user and configuration IDs.
+func (s *TelegramService) LinkChat(code, chatID, chatName string) (int, int, error) {
+	var owners []int
+	err := s.db.Select(&owners, "SELECT DISTINCT user_id FROM telegram_configs WHERE chat_id = $1", chatID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to check chat: %w", err)
+	}
+
+	var userID int
+	err = s.db.Get(&userID, `
+        UPDATE telegram_link_codes SET used_at = NOW()
+        WHERE code_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+        RETURNING user_id
+    `, hashLinkCode(code))
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, 0, ErrInvalidLinkCode
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to use link code: %w", err)
+	}
+
+	for _, owner := range owners {
+		if owner != userID {
+			return 0, 0, ErrChatLinkedElsewhere
+		}
+	}
+
+	// A chat linked before is switched back on rather than added twice
+	var configID int
+	err = s.db.Get(&configID, `
+        UPDATE telegram_configs SET is_active = true, updated_at = NOW()
+        WHERE id = (SELECT id FROM telegram_configs WHERE user_id = $1 AND chat_id = $2 ORDER BY id LIMIT 1)
+        RETURNING id
+    `, userID, chatID)
+	if err == nil {
+		s.logger.Info("Telegram chat relinked", "user_id", userID, "config_id", configID)
+		return userID, configID, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, 0, fmt.Errorf("failed to reactivate Telegram configuration: %w", err)
+	}
+
+	configID, err = s.AddTelegramConfig(userID, chatID, chatName, "", true, true, true, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	s.logger.Info("Telegram chat linked", "user_id", userID, "config_id", configID)
+	return userID, configID, nil
+}
@@ -0,0 +1,128 @@
+package notification
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"domain-detection-go/pkg/model"
+)
+
+// configTables maps each channel to the table of its configs
+var configTables = map[string]string{
+	model.ChannelTelegram: "telegram_configs",
+	model.ChannelEmail:    "email_configs",
+}
+
+// userTimezone returns the timezone a user chose, or the default
+func (d *Dispatcher) userTimezone(userID int) (string, error) {
+	var timezone sql.NullString
+	err := d.db.Get(&timezone, "SELECT timezone FROM users WHERE id = $1", userID)
+	if err != nil && err != sql.ErrNoRows {
+		return model.DefaultTimezone, fmt.Errorf("failed to get user timezone: %w", err)
+	}
+	if !timezone.Valid || timezone.String == "" {
+		return model.DefaultTimezone, nil
+	}
+	return timezone.String, nil
+}
+
+// effectiveTimezone returns the timezone of a config, falling back to the user's
+func effectiveTimezone(configTimezone *string, userTimezone string) string {
+	if configTimezone != nil && *configTimezone != "" {
+		return *configTimezone
+	}
+	return userTimezone
+}
+
+// loadLocation loads a timezone, falling back to the default
+func loadLocation(timezone string) *time.Location {
+	if loc, err := time.LoadLocation(timezone); err == nil {
+		return loc
+	}
+	if loc, err := time.LoadLocation(model.DefaultTimezone); err == nil {
+		return loc
+	}
+	return time.FixedZone("UTC+8", 8*60*60)
+}
+
+// formatLocalTime formats t in the timezone followed by its UTC offset, e.g.
+// "2024-01-02 15:04:05 (UTC+8)"
+func formatLocalTime(t time.Time, timezone, layout string) string {
+	local := t.In(loadLocation(timezone))
+	return fmt.Sprintf("%s (%s)", local.Format(layout), utcOffsetLabel(local))
+}
+
+// utcOffsetLabel returns the UTC offset of a time as "UTC", "UTC+8" or "UTC-3:30"
+func utcOffsetLabel(t time.Time) string {
+	_, offset := t.Zone()
+	if offset == 0 {
+		return "UTC"
+	}
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	hours, minutes := offset/3600, offset%3600/60
+	if minutes == 0 {
+		return fmt.Sprintf("UTC%s%d", sign, hours)
+	}
+	return fmt.Sprintf("UTC%s%d:%02d", sign, hours, minutes)
+}
+
+// GetConfigTimezone returns the timezone preference of a config
+func (d *Dispatcher) GetConfigTimezone(n Notifier, configID, userID int) (*model.ConfigTimezone, error) {
+	table, ok := configTables[n.Channel()]
+	if !ok {
+		return nil, ErrConfigNotFound
+	}
+
+	var timezone sql.NullString
+	err := d.db.Get(&timezone, fmt.Sprintf("SELECT timezone FROM %s WHERE id = $1 AND user_id = $2", table), configID, userID)
+	if err == sql.ErrNoRows {
+		return nil, ErrConfigNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config timezone: %w", err)
+	}
+
+	userTimezone, err := d.userTimezone(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.ConfigTimezone{Channel: n.Channel(), ConfigID: configID}
+	if timezone.Valid && timezone.String != "" {
+		result.Timezone = &timezone.String
+	}
+	result.Effective = effectiveTimezone(result.Timezone, userTimezone)
+	return result, nil
+}
+
+// UpdateConfigTimezone sets the timezone preference of a config. An empty timezone
+// makes the config use the user's timezone again.
+func (d *Dispatcher) UpdateConfigTimezone(n Notifier, configID, userID int, timezone string) (*model.ConfigTimezone, error) {
+	table, ok := configTables[n.Channel()]
+	if !ok {
+		return nil, ErrConfigNotFound
+	}
+
+	var value *string
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone %q", timezone)
+		}
+		value = &timezone
+	}
+
+	result, err := d.db.Exec(fmt.Sprintf("UPDATE %s SET timezone = $1, updated_at = NOW() WHERE id = $2 AND user_id = $3", table),
+		value, configID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update config timezone: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, ErrConfigNotFound
+	}
+	return d.GetConfigTimezone(n, configID, userID)
+}
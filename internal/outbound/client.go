@@ -0,0 +1,305 @@
+package outbound
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"domain-detection-go/pkg/model"
+)
+
+// ErrCircuitOpen is returned while a provider has failed too often in a row and requests
+// to it are rejected without being sent
+var ErrCircuitOpen = errors.New("circuit open")
+
+// Config limits the requests made to a provider API
+type Config struct {
+	Name             string        // Provider name used in logs and stats
+	Timeout          time.Duration // Timeout of a single request
+	MaxConcurrent    int           // Requests in flight at once
+	MinInterval      time.Duration // Minimum gap between the start of two requests, 0 for none
+	MaxRetries       int           // Retries of a throttled (429) request
+	RetryDelay       time.Duration // Wait before retrying a 429 without Retry-After, doubled per attempt
+	MaxRetryAfter    time.Duration // Longest throttle wait honoured; longer ones return the 429
+	FailureThreshold int           // Consecutive failures that open the circuit
+	OpenDuration     time.Duration // How long an open circuit rejects requests
+}
+
+// Recorder receives every request sent to a provider API, for usage tracking
+type Recorder interface {
+	RecordCall(call model.ProviderCall)
+}
+
+// Client sends requests to a provider API within its rate limits. Throttling pauses
+// every request to the provider until the Retry-After has passed, and repeated
+// connection errors or 5xx responses open a circuit that fails requests fast.
+type Client struct {
+	config   Config
+	http     *http.Client
+	slots    chan struct{}
+	recorder Recorder
+
+	mu             sync.Mutex
+	nextStart      time.Time // Earliest start of the next request
+	throttledUntil time.Time
+	failures       int // Consecutive failures
+	openUntil      time.Time
+	stats          model.ProviderClientStats
+	totalLatency   time.Duration
+}
+
+// NewClient creates a client for a provider API, filling in defaults for unset limits
+func NewClient(config Config) *Client {
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+	if config.MaxConcurrent <= 0 {
+		config.MaxConcurrent = 4
+	}
+	if config.RetryDelay == 0 {
+		config.RetryDelay = 2 * time.Second
+	}
+	if config.MaxRetryAfter == 0 {
+		config.MaxRetryAfter = time.Minute
+	}
+	if config.FailureThreshold == 0 {
+		config.FailureThreshold = 5
+	}
+	if config.OpenDuration == 0 {
+		config.OpenDuration = time.Minute
+	}
+
+	return &Client{
+		config: config,
+		http:   &http.Client{Timeout: config.Timeout},
+		slots:  make(chan struct{}, config.MaxConcurrent),
+		stats:  model.ProviderClientStats{Provider: config.Name},
+	}
+}
+
+// Do sends a request, waiting for a free slot and the provider's pacing, and retries it
+// while the provider answers 429. The response of the last attempt is returned.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	if until, open := c.circuitOpen(); open {
+		return nil, fmt.Errorf("%s API unavailable until %s: %w", c.config.Name, until.Format(time.RFC3339), ErrCircuitOpen)
+	}
+
+	ctx := req.Context()
+	select {
+	case c.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-c.slots }()
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			if req.GetBody == nil && req.Body != nil {
+				return nil, fmt.Errorf("%s API throttled and request cannot be retried", c.config.Name)
+			}
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, fmt.Errorf("error rewinding request body: %w", err)
+				}
+				req.Body = body
+			}
+		}
+
+		if err := c.wait(req); err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		resp, err := c.http.Do(req)
+		c.record(time.Since(start), resp, err)
+		c.recordCall(req, start, resp)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+			return resp, err
+		}
+
+		delay := retryAfter(resp.Header.Get("Retry-After"), c.config.RetryDelay<<attempt)
+		c.throttle(delay)
+		if attempt >= c.config.MaxRetries || delay > c.config.MaxRetryAfter {
+			slog.Warn("Throttled by provider API, giving up", "provider", c.config.Name, "attempts", attempt+1, "retry_after", delay)
+			return resp, nil
+		}
+
+		slog.Info("Throttled by provider API, retrying", "provider", c.config.Name, "retry_after", delay)
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		c.mu.Lock()
+		c.stats.Retries++
+		c.mu.Unlock()
+	}
+}
+
+// wait blocks until the provider's pacing and throttling allow the next request
+func (c *Client) wait(req *http.Request) error {
+	c.mu.Lock()
+	now := time.Now()
+	start := c.nextStart
+	if c.throttledUntil.After(start) {
+		start = c.throttledUntil
+	}
+	if start.Before(now) {
+		start = now
+	}
+	c.nextStart = start.Add(c.config.MinInterval)
+	c.mu.Unlock()
+
+	delay := time.Until(start)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-req.Context().Done():
+		return req.Context().Err()
+	}
+}
+
+// throttle pauses every request to the provider for the given delay
+func (c *Client) throttle(delay time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if until := time.Now().Add(delay); until.After(c.throttledUntil) {
+		c.throttledUntil = until
+	}
+	c.stats.Throttled++
+}
+
+// record updates the stats and the circuit with the outcome of a request. Connection
+// errors and 5xx responses count as failures; throttling does not.
+func (c *Client) record(latency time.Duration, resp *http.Response, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stats.Requests++
+	c.totalLatency += latency
+	c.stats.AvgLatencyMs = int(c.totalLatency.Milliseconds() / int64(c.stats.Requests))
+
+	if err != nil || resp.StatusCode >= http.StatusInternalServerError {
+		c.stats.Failures++
+		c.failures++
+		if c.failures >= c.config.FailureThreshold {
+			c.openUntil = time.Now().Add(c.config.OpenDuration)
+			slog.Warn("Provider API circuit opened", "provider", c.config.Name, "failures", c.failures, "until", c.openUntil)
+		}
+		return
+	}
+	c.failures = 0
+}
+
+// SetRecorder sets where every request sent is reported. It must be called before the
+// client is used.
+func (c *Client) SetRecorder(recorder Recorder) {
+	c.recorder = recorder
+}
+
+// recordCall reports a request to the recorder. Connection errors have status code 0.
+func (c *Client) recordCall(req *http.Request, start time.Time, resp *http.Response) {
+	if c.recorder == nil {
+		return
+	}
+	call := model.ProviderCall{
+		Provider: c.config.Name,
+		Method:   req.Method,
+		Endpoint: endpointPattern(req.URL.Path),
+		Latency:  time.Since(start),
+		CalledAt: start,
+	}
+	if resp != nil {
+		call.StatusCode = resp.StatusCode
+	}
+	c.recorder.RecordCall(call)
+}
+
+// endpointPattern replaces the IDs in a request path with ":id", so calls for different
+// monitors count against the same endpoint
+func endpointPattern(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if isIDSegment(segment) {
+			segments[i] = ":id"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// isIDSegment reports whether a path segment looks like a numeric ID or a GUID
+func isIDSegment(segment string) bool {
+	if segment == "" {
+		return false
+	}
+	digits := 0
+	for _, r := range segment {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '-' || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F'):
+		default:
+			return false
+		}
+	}
+	return digits > 0
+}
+
+// circuitOpen reports whether requests are currently rejected
+func (c *Client) circuitOpen() (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Now().Before(c.openUntil) {
+		c.stats.Rejected++
+		return c.openUntil, true
+	}
+	return time.Time{}, false
+}
+
+// Stats returns the request counters and current state of the client
+func (c *Client) Stats() model.ProviderClientStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.InFlight = len(c.slots)
+	stats.MaxConcurrent = c.config.MaxConcurrent
+	now := time.Now()
+	if c.throttledUntil.After(now) {
+		until := c.throttledUntil
+		stats.ThrottledUntil = &until
+	}
+	if c.openUntil.After(now) {
+		until := c.openUntil
+		stats.CircuitOpenUntil = &until
+	}
+	return stats
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date, falling
+// back to the given delay
+func retryAfter(header string, fallback time.Duration) time.Duration {
+	if header == "" {
+		return fallback
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		if delay := time.Until(at); delay > 0 {
+			return delay
+		}
+		return 0
+	}
+	return fallback
+}
@@ -0,0 +1,7 @@
+package probe
+
+import "errors"
+
+// ErrRegistrationNotFound is returned for domains without a registration lookup or owned
+// by another user
+var ErrRegistrationNotFound = errors.New("registration not found")
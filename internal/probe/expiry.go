@@ -0,0 +1,268 @@
+package probe
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"domain-detection-go/internal/events"
+	"domain-detection-go/internal/notification"
+	"domain-detection-go/pkg/model"
+)
+
+// registrationRecheckInterval is how often the registration of a domain is looked up.
+// Warnings are evaluated on every run from the stored expiry date.
+const registrationRecheckInterval = 7 * 24 * time.Hour
+
+// expiringRegistration is a stored registration that is due for a warning
+type expiringRegistration struct {
+	DomainID          int       `db:"domain_id"`
+	UserID            int       `db:"user_id"`
+	RegistrableDomain string    `db:"registrable_domain"`
+	ExpiresAt         time.Time `db:"expires_at"`
+}
+
+// GetRegistration returns the latest registration lookup for a domain owned by the user
+func (s *ProbeService) GetRegistration(domainID, userID int) (*model.DomainRegistration, error) {
+	var registration model.DomainRegistration
+	err := s.db.Get(&registration, `
+        SELECT r.domain_id, r.registrable_domain, r.registrar, r.expires_at, r.source, r.error, r.checked_at
+        FROM domain_registrations r
+        JOIN domains d ON d.id = r.domain_id
+        WHERE r.domain_id = $1 AND d.user_id = $2
+    `, domainID, userID)
+	if err == sql.ErrNoRows {
+		return nil, ErrRegistrationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get registration: %w", err)
+	}
+
+	if registration.ExpiresAt != nil {
+		days := int(math.Floor(time.Until(*registration.ExpiresAt).Hours() / 24))
+		registration.DaysRemaining = &days
+	}
+	return &registration, nil
+}
+
+// RunRegistrationChecks looks up registrations that are due and warns about those
+// expiring soon
+func (s *ProbeService) RunRegistrationChecks() error {
+	if err := s.refreshRegistrations(); err != nil {
+		return err
+	}
+	return s.sendExpiryWarnings()
+}
+
+// RunScheduledRegistrationChecks runs the registration checks once a day. Each domain
+// is only looked up weekly, but warnings are sent on the day a threshold is reached.
+// The checks also run at startup, so that restarts more often than daily still check.
+func (s *ProbeService) RunScheduledRegistrationChecks(ctx context.Context) {
+	slog.Info("RunScheduledRegistrationChecks")
+	run := func() {
+		if err := s.RunRegistrationChecks(); err != nil {
+			slog.Error("Registration check run failed", "error", err)
+		}
+	}
+	run()
+
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("RunScheduledRegistrationChecks stopped")
+			return
+		case <-ticker.C:
+			run()
+		}
+	}
+}
+
+// refreshRegistrations looks up the registration of every active domain not checked
+// within the recheck interval. Domains sharing a registrable domain share one lookup.
+func (s *ProbeService) refreshRegistrations() error {
+	var domains []model.Domain
+	err := s.db.Select(&domains, `
+        SELECT d.id, d.user_id, d.name
+        FROM domains d
+        JOIN users u ON u.id = d.user_id
+        LEFT JOIN domain_registrations r ON r.domain_id = d.id
+        WHERE d.active = true AND u.suspended_at IS NULL
+          AND (r.checked_at IS NULL OR r.checked_at < NOW() - make_interval(secs => $1))
+    `, registrationRecheckInterval.Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to get domains for registration checks: %w", err)
+	}
+
+	type result struct {
+		lookup *registrationLookup
+		err    error
+	}
+	results := make(map[string]result)
+
+	for _, domain := range domains {
+		name, err := registrableDomain(domain.Name)
+		if err != nil {
+			slog.Warn("No registrable domain", "domain", domain.Name, "error", err)
+			continue
+		}
+
+		res, ok := results[name]
+		if !ok {
+			// Lookups run one at a time; WHOIS servers rate limit aggressively
+			lookup, err := s.registrations.Lookup(name)
+			res = result{lookup: lookup, err: err}
+			results[name] = res
+		}
+
+		var registrar *string
+		var expiresAt *time.Time
+		var source string
+		var lookupError *string
+		if res.err != nil {
+			slog.Warn("Registration lookup failed", "domain", name, "error", res.err)
+			message := res.err.Error()
+			lookupError = &message
+		} else {
+			registrar, expiresAt, source = res.lookup.Registrar, res.lookup.ExpiresAt, res.lookup.Source
+		}
+
+		// A failed lookup keeps the last known expiry so warnings are not lost
+		_, err = s.db.Exec(`
+            INSERT INTO domain_registrations
+            (domain_id, registrable_domain, registrar, expires_at, source, error, checked_at)
+            VALUES ($1, $2, $3, $4, $5, $6, NOW())
+            ON CONFLICT (domain_id) DO UPDATE SET
+                registrable_domain = EXCLUDED.registrable_domain,
+                registrar = COALESCE(EXCLUDED.registrar, domain_registrations.registrar),
+                expires_at = COALESCE(EXCLUDED.expires_at, domain_registrations.expires_at),
+                source = CASE WHEN EXCLUDED.error IS NULL THEN EXCLUDED.source ELSE domain_registrations.source END,
+                error = EXCLUDED.error,
+                checked_at = NOW()
+        `, domain.ID, name, registrar, expiresAt, source, lookupError)
+		if err != nil {
+			slog.Error("Failed to save registration", "domain_id", domain.ID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// sendExpiryWarnings notifies users whose registrations expire within one of the
+// warning thresholds. Like trial warnings, only the nearest threshold is sent and
+// larger ones are marked as sent with it.
+func (s *ProbeService) sendExpiryWarnings() error {
+	var registrations []expiringRegistration
+	err := s.db.Select(&registrations, `
+        SELECT DISTINCT ON (d.user_id, r.registrable_domain)
+               r.domain_id, d.user_id, r.registrable_domain, r.expires_at
+        FROM domain_registrations r
+        JOIN domains d ON d.id = r.domain_id
+        JOIN users u ON u.id = d.user_id
+        WHERE d.active = true AND u.suspended_at IS NULL
+          AND r.expires_at > NOW() AND r.expires_at <= NOW() + make_interval(days => $1)
+        ORDER BY d.user_id, r.registrable_domain, r.domain_id
+    `, maxRegistrationWarningDays())
+	if err != nil {
+		return fmt.Errorf("failed to get expiring registrations: %w", err)
+	}
+
+	for _, reg := range registrations {
+		remaining := time.Until(reg.ExpiresAt)
+
+		var sent []int
+		err := s.db.Select(&sent, `
+            SELECT days_before FROM registration_warnings
+            WHERE user_id = $1 AND registrable_domain = $2 AND expires_at = $3
+        `, reg.UserID, reg.RegistrableDomain, reg.ExpiresAt)
+		if err != nil {
+			slog.Error("Failed to get sent registration warnings", "domain", reg.RegistrableDomain, "error", err)
+			continue
+		}
+
+		due := 0
+		for _, days := range model.RegistrationWarningDays {
+			if remaining <= time.Duration(days)*24*time.Hour && !containsInt(sent, days) {
+				if due == 0 || days < due {
+					due = days
+				}
+			}
+		}
+		if due == 0 {
+			continue
+		}
+
+		slog.Info("Registration expiring, warning user", "domain", reg.RegistrableDomain,
+			"expires_at", reg.ExpiresAt.UTC().Format("2006-01-02"), "user_id", reg.UserID)
+		s.events.Publish(events.Event{
+			Type:     events.RegistrationExpiring,
+			UserID:   reg.UserID,
+			DomainID: reg.DomainID,
+			Payload: map[string]interface{}{
+				"domain":     reg.RegistrableDomain,
+				"expires_at": reg.ExpiresAt,
+				"days":       due,
+			},
+		})
+		s.notifyExpiry(reg, due)
+
+		for _, days := range model.RegistrationWarningDays {
+			if days >= due && !containsInt(sent, days) {
+				_, err := s.db.Exec(`
+                    INSERT INTO registration_warnings (user_id, registrable_domain, expires_at, days_before, sent_at)
+                    VALUES ($1, $2, $3, $4, NOW())
+                    ON CONFLICT DO NOTHING
+                `, reg.UserID, reg.RegistrableDomain, reg.ExpiresAt, days)
+				if err != nil {
+					slog.Error("Failed to record registration warning", "domain", reg.RegistrableDomain, "error", err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// notifyExpiry alerts the user through every configured channel
+func (s *ProbeService) notifyExpiry(reg expiringRegistration, days int) {
+	expires := reg.ExpiresAt.UTC().Format("2006-01-02")
+	subject := fmt.Sprintf("Domain registration of %s expires in %d days", reg.RegistrableDomain, days)
+	message := fmt.Sprintf("⏳ The registration of %s expires on %s (within %d days). Renew it with your registrar to avoid losing the domain.",
+		reg.RegistrableDomain, expires, days)
+	body := fmt.Sprintf("<html><body><h2>%s</h2><p>The registration of <b>%s</b> expires on %s. Renew it with your registrar to avoid losing the domain.</p></body></html>",
+		subject, reg.RegistrableDomain, expires)
+
+	err := s.notifiers.SendCustom(reg.UserID, notification.StaticMessage(notification.CustomMessage{
+		Subject: subject,
+		HTML:    body,
+		Text:    []string{message},
+	}))
+	if err != nil {
+		slog.Error("Registration alert not sent", "domain", reg.RegistrableDomain, "error", err)
+	}
+}
+
+// maxRegistrationWarningDays returns the earliest warning threshold in days
+func maxRegistrationWarningDays() int {
+	max := 0
+	for _, days := range model.RegistrationWarningDays {
+		if days > max {
+			max = days
+		}
+	}
+	return max
+}
+
+func containsInt(values []int, v int) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
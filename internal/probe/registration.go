@@ -0,0 +1,327 @@
+package probe
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"domain-detection-go/pkg/model"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+const (
+	// rdapBootstrapURL lists the RDAP servers of every TLD that has one
+	rdapBootstrapURL = "https://data.iana.org/rdap/dns.json"
+	// rdapBootstrapTTL is how long the bootstrap file is cached
+	rdapBootstrapTTL = 24 * time.Hour
+	// ianaWhoisServer tells which WHOIS server is authoritative for a TLD
+	ianaWhoisServer = "whois.iana.org"
+)
+
+// whoisExpiryKeys are the WHOIS fields holding the expiry date, as used by the
+// common registry and registrar formats
+var whoisExpiryKeys = []string{
+	"registry expiry date",
+	"registrar registration expiration date",
+	"expiration date",
+	"expiry date",
+	"expire date",
+	"expires on",
+	"expires",
+	"paid-till",
+}
+
+// whoisDateLayouts are the date formats found in WHOIS responses
+var whoisDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"2006.01.02",
+	"2006/01/02",
+	"02-Jan-2006",
+	"02.01.2006",
+}
+
+// registrationLookup is the outcome of a registration lookup
+type registrationLookup struct {
+	Registrar *string
+	ExpiresAt *time.Time
+	Source    string
+}
+
+// registrationClient looks up domain registrations over RDAP, falling back to WHOIS
+// for TLDs without an RDAP server
+type registrationClient struct {
+	httpClient *http.Client
+
+	mu           sync.Mutex
+	rdapServers  map[string]string // TLD -> base URL
+	bootstrapped time.Time
+}
+
+func newRegistrationClient() *registrationClient {
+	return &registrationClient{
+		httpClient: &http.Client{Timeout: probeTimeout},
+	}
+}
+
+// registrableDomain returns the domain that is registered for a domain entry,
+// e.g. example.co.uk for https://www.example.co.uk/path
+func registrableDomain(domainName string) (string, error) {
+	host, _ := probeTarget(domainName)
+	return publicsuffix.EffectiveTLDPlusOne(strings.TrimSuffix(strings.ToLower(host), "."))
+}
+
+// Lookup returns the registration of a registrable domain
+func (c *registrationClient) Lookup(name string) (*registrationLookup, error) {
+	tld := name[strings.LastIndex(name, ".")+1:]
+
+	server, err := c.rdapServer(tld)
+	if err != nil {
+		return nil, err
+	}
+	if server != "" {
+		lookup, err := c.lookupRDAP(server, name)
+		if err == nil {
+			return lookup, nil
+		}
+		// Some registries run RDAP without expiry data; WHOIS may still have it
+		if whois, whoisErr := lookupWHOIS(name, tld); whoisErr == nil {
+			return whois, nil
+		}
+		return nil, err
+	}
+	return lookupWHOIS(name, tld)
+}
+
+// rdapServer returns the RDAP base URL for a TLD, or "" when it has none
+func (c *registrationClient) rdapServer(tld string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.rdapServers == nil || time.Since(c.bootstrapped) > rdapBootstrapTTL {
+		servers, err := c.fetchBootstrap()
+		if err != nil {
+			if c.rdapServers == nil {
+				return "", err
+			}
+			// Keep using the stale list rather than failing every lookup
+		} else {
+			c.rdapServers = servers
+			c.bootstrapped = time.Now()
+		}
+	}
+	return c.rdapServers[tld], nil
+}
+
+// fetchBootstrap downloads the IANA RDAP bootstrap file
+func (c *registrationClient) fetchBootstrap() (map[string]string, error) {
+	resp, err := c.httpClient.Get(rdapBootstrapURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch RDAP bootstrap: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("RDAP bootstrap returned status %d", resp.StatusCode)
+	}
+
+	var bootstrap struct {
+		Services [][][]string `json:"services"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&bootstrap); err != nil {
+		return nil, fmt.Errorf("failed to decode RDAP bootstrap: %w", err)
+	}
+
+	servers := make(map[string]string)
+	for _, service := range bootstrap.Services {
+		if len(service) != 2 || len(service[1]) == 0 {
+			continue
+		}
+		base := service[1][0]
+		for _, u := range service[1] {
+			if strings.HasPrefix(u, "https://") {
+				base = u
+				break
+			}
+		}
+		if !strings.HasSuffix(base, "/") {
+			base += "/"
+		}
+		for _, tld := range service[0] {
+			servers[strings.ToLower(tld)] = base
+		}
+	}
+	return servers, nil
+}
+
+// lookupRDAP queries an RDAP server for a domain
+func (c *registrationClient) lookupRDAP(server, name string) (*registrationLookup, error) {
+	req, err := http.NewRequest(http.MethodGet, server+"domain/"+name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create RDAP request: %w", err)
+	}
+	req.Header.Set("Accept", "application/rdap+json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("RDAP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errors.New("domain is not registered")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("RDAP server returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Events []struct {
+			Action string `json:"eventAction"`
+			Date   string `json:"eventDate"`
+		} `json:"events"`
+		Entities []struct {
+			Roles      []string          `json:"roles"`
+			VCardArray []json.RawMessage `json:"vcardArray"`
+		} `json:"entities"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode RDAP response: %w", err)
+	}
+
+	lookup := &registrationLookup{Source: model.RegistrationSourceRDAP}
+	for _, event := range body.Events {
+		if event.Action != "expiration" {
+			continue
+		}
+		if expiresAt, err := time.Parse(time.RFC3339, event.Date); err == nil {
+			lookup.ExpiresAt = &expiresAt
+		}
+	}
+	for _, entity := range body.Entities {
+		if containsString(entity.Roles, "registrar") && len(entity.VCardArray) == 2 {
+			lookup.Registrar = vcardName(entity.VCardArray[1])
+		}
+	}
+
+	if lookup.ExpiresAt == nil {
+		return nil, errors.New("RDAP response has no expiration date")
+	}
+	return lookup, nil
+}
+
+// vcardName returns the "fn" property of a jCard property list
+func vcardName(properties json.RawMessage) *string {
+	var props [][]interface{}
+	if json.Unmarshal(properties, &props) != nil {
+		return nil
+	}
+	for _, prop := range props {
+		if len(prop) == 4 && prop[0] == "fn" {
+			if name, ok := prop[3].(string); ok && name != "" {
+				return &name
+			}
+		}
+	}
+	return nil
+}
+
+// lookupWHOIS finds the WHOIS server of the TLD through IANA and queries it
+func lookupWHOIS(name, tld string) (*registrationLookup, error) {
+	referral, err := whoisQuery(ianaWhoisServer, tld)
+	if err != nil {
+		return nil, err
+	}
+	server := whoisField(referral, "refer", "whois")
+	if server == "" {
+		return nil, fmt.Errorf("no WHOIS server known for .%s", tld)
+	}
+
+	response, err := whoisQuery(server, name)
+	if err != nil {
+		return nil, err
+	}
+
+	lookup := &registrationLookup{Source: model.RegistrationSourceWHOIS}
+	if registrar := whoisField(response, "registrar"); registrar != "" {
+		lookup.Registrar = &registrar
+	}
+	if value := whoisField(response, whoisExpiryKeys...); value != "" {
+		for _, layout := range whoisDateLayouts {
+			if expiresAt, err := time.Parse(layout, value); err == nil {
+				lookup.ExpiresAt = &expiresAt
+				break
+			}
+		}
+		if lookup.ExpiresAt == nil {
+			return nil, fmt.Errorf("unrecognised WHOIS expiry date %q", value)
+		}
+	}
+
+	if lookup.ExpiresAt == nil {
+		return nil, errors.New("WHOIS response has no expiration date")
+	}
+	return lookup, nil
+}
+
+// whoisQuery sends a query to a WHOIS server on port 43 and returns the response
+func whoisQuery(server, query string) (string, error) {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(server, "43"), probeTimeout)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to %s: %w", server, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(probeTimeout))
+
+	if _, err := fmt.Fprintf(conn, "%s\r\n", query); err != nil {
+		return "", fmt.Errorf("failed to query %s: %w", server, err)
+	}
+	data, err := io.ReadAll(io.LimitReader(conn, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read from %s: %w", server, err)
+	}
+	return string(data), nil
+}
+
+// whoisField returns the value of the first of keys found in a WHOIS response.
+// Keys are matched case-insensitively and in the order given.
+func whoisField(response string, keys ...string) string {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(response))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		idx := strings.Index(line, ":")
+		if idx <= 0 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(line[:idx]))
+		value := strings.TrimSpace(line[idx+1:])
+		if _, seen := values[key]; !seen && value != "" {
+			values[key] = value
+		}
+	}
+	for _, key := range keys {
+		if value, ok := values[key]; ok {
+			return value
+		}
+	}
+	return ""
+}
+
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,205 @@
+package probe
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"domain-detection-go/internal/events"
+	"domain-detection-go/internal/notification"
+	"domain-detection-go/pkg/model"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// probeConcurrency limits how many domains are probed at the same time
+const probeConcurrency = 5
+
+// tlsProbeRow scans a probe together with its array columns
+type tlsProbeRow struct {
+	model.TLSProbe
+	TLSVersions   pq.StringArray `db:"tls_versions"`
+	ALPNProtocols pq.StringArray `db:"alpn_protocols"`
+}
+
+func (r tlsProbeRow) toModel() model.TLSProbe {
+	probe := r.TLSProbe
+	probe.TLSVersions = []string(r.TLSVersions)
+	probe.ALPNProtocols = []string(r.ALPNProtocols)
+	return probe
+}
+
+// ProbeService periodically records TLS/HTTP capabilities and registration expiry of
+// domains and alerts on regressions and upcoming expiry
+type ProbeService struct {
+	db            *sqlx.DB
+	events        *events.Bus
+	notifiers     *notification.Fanout
+	registrations *registrationClient
+}
+
+// NewProbeService creates a new capability probe service
+func NewProbeService(db *sqlx.DB, eventBus *events.Bus,
+	notifiers *notification.Fanout) *ProbeService {
+	return &ProbeService{
+		db:            db,
+		events:        eventBus,
+		notifiers:     notifiers,
+		registrations: newRegistrationClient(),
+	}
+}
+
+// GetHistory returns the most recent probes for a domain owned by the user
+func (s *ProbeService) GetHistory(domainID, userID, limit int) ([]model.TLSProbe, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+
+	var rows []tlsProbeRow
+	err := s.db.Select(&rows, `
+        SELECT p.id, p.domain_id, p.tls_versions, p.alpn_protocols, p.http3,
+               p.cipher_suite, p.weak_cipher, p.error, p.probed_at
+        FROM domain_tls_probes p
+        JOIN domains d ON d.id = p.domain_id
+        WHERE p.domain_id = $1 AND d.user_id = $2
+        ORDER BY p.probed_at DESC
+        LIMIT $3
+    `, domainID, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get TLS probe history: %w", err)
+	}
+
+	probes := make([]model.TLSProbe, len(rows))
+	for i, row := range rows {
+		probes[i] = row.toModel()
+	}
+	return probes, nil
+}
+
+// RunProbes probes every active domain of non-suspended users once
+func (s *ProbeService) RunProbes() error {
+	var domains []model.Domain
+	err := s.db.Select(&domains, `
+        SELECT d.id, d.user_id, d.name, COALESCE(d.region, '') AS region
+        FROM domains d
+        JOIN users u ON u.id = d.user_id
+        WHERE d.active = true AND u.suspended_at IS NULL
+    `)
+	if err != nil {
+		return fmt.Errorf("failed to get domains to probe: %w", err)
+	}
+
+	sem := make(chan struct{}, probeConcurrency)
+	var wg sync.WaitGroup
+	for _, domain := range domains {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(d model.Domain) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := s.ProbeDomain(d); err != nil {
+				slog.Error("Failed to probe domain", "domain", d.Name, "error", err)
+			}
+		}(domain)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// RunScheduledProbes probes all domains every six hours
+func (s *ProbeService) RunScheduledProbes(ctx context.Context) {
+	slog.Info("RunScheduledProbes")
+	ticker := time.NewTicker(6 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("RunScheduledProbes stopped")
+			return
+		case <-ticker.C:
+			if err := s.RunProbes(); err != nil {
+				slog.Error("Probe run failed", "error", err)
+			}
+		}
+	}
+}
+
+// ProbeDomain probes a single domain, stores the result and alerts on regressions
+// compared to the last successful probe
+func (s *ProbeService) ProbeDomain(domain model.Domain) error {
+	current := probeTLS(domain.Name)
+	current.DomainID = domain.ID
+
+	var previousRow tlsProbeRow
+	err := s.db.Get(&previousRow, `
+        SELECT id, domain_id, tls_versions, alpn_protocols, http3, cipher_suite, weak_cipher, error, probed_at
+        FROM domain_tls_probes
+        WHERE domain_id = $1 AND error IS NULL
+        ORDER BY probed_at DESC
+        LIMIT 1
+    `, domain.ID)
+	hasPrevious := err == nil
+	if err != nil && err != sql.ErrNoRows {
+		slog.Error("Failed to get previous probe", "domain_id", domain.ID, "error", err)
+	}
+
+	_, err = s.db.Exec(`
+        INSERT INTO domain_tls_probes
+        (domain_id, tls_versions, alpn_protocols, http3, cipher_suite, weak_cipher, error, probed_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+    `, domain.ID, pq.Array(current.TLSVersions), pq.Array(current.ALPNProtocols), current.HTTP3,
+		current.CipherSuite, current.WeakCipher, current.Error)
+	if err != nil {
+		return fmt.Errorf("failed to save TLS probe: %w", err)
+	}
+
+	// A failed handshake is an availability problem, which the regular checks already report
+	if current.Error != nil || !hasPrevious {
+		return nil
+	}
+
+	regressions := describeRegressions(previousRow.toModel(), current)
+	if len(regressions) == 0 {
+		return nil
+	}
+
+	slog.Warn("Capability regression", "domain", domain.Name, "regressions", regressions)
+	s.events.Publish(events.Event{
+		Type:     events.TLSRegression,
+		UserID:   domain.UserID,
+		DomainID: domain.ID,
+		Payload: map[string]interface{}{
+			"domain":      domain.Name,
+			"regressions": regressions,
+		},
+	})
+	s.notifyRegression(domain, regressions)
+
+	return nil
+}
+
+// notifyRegression alerts the user through every configured channel
+func (s *ProbeService) notifyRegression(domain model.Domain, regressions []string) {
+	message := fmt.Sprintf("⚠️ TLS/HTTP capability change detected for %s:\n- %s\n\nThis often follows a CDN or load balancer change.",
+		domain.Name, strings.Join(regressions, "\n- "))
+
+	subject := fmt.Sprintf("Capability change detected for %s", domain.Name)
+	body := fmt.Sprintf("<html><body><h2>%s</h2><ul><li>%s</li></ul><p>This often follows a CDN or load balancer change.</p></body></html>",
+		subject, strings.Join(regressions, "</li><li>"))
+
+	err := s.notifiers.SendCustom(domain.UserID, notification.StaticMessage(notification.CustomMessage{
+		Subject: subject,
+		HTML:    body,
+		Text:    []string{message},
+	}))
+	if err != nil {
+		slog.Error("Capability alert not sent", "domain", domain.Name, "error", err)
+	}
+}
@@ -0,0 +1,173 @@
+package probe
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"domain-detection-go/internal/netguard"
+	"domain-detection-go/pkg/model"
+)
+
+// probeTimeout bounds each handshake and the Alt-Svc request
+const probeTimeout = 10 * time.Second
+
+// tlsVersions lists the versions probed, oldest first
+var tlsVersions = []struct {
+	id   uint16
+	name string
+}{
+	{tls.VersionTLS10, "TLS 1.0"},
+	{tls.VersionTLS11, "TLS 1.1"},
+	{tls.VersionTLS12, "TLS 1.2"},
+	{tls.VersionTLS13, "TLS 1.3"},
+}
+
+// allCipherSuites offers every suite Go implements, including insecure ones, so that
+// legacy-only servers still complete the handshake and can be reported
+var allCipherSuites = func() []uint16 {
+	var ids []uint16
+	for _, suite := range tls.CipherSuites() {
+		ids = append(ids, suite.ID)
+	}
+	for _, suite := range tls.InsecureCipherSuites() {
+		ids = append(ids, suite.ID)
+	}
+	return ids
+}()
+
+// probeTLS records the TLS versions, ALPN protocols, HTTP/3 advertisement and
+// negotiated cipher strength of a domain
+func probeTLS(domainName string) model.TLSProbe {
+	result := model.TLSProbe{TLSVersions: []string{}, ALPNProtocols: []string{}}
+	host, address := probeTarget(domainName)
+
+	for _, version := range tlsVersions {
+		if _, err := handshake(host, address, version.id, version.id, nil); err == nil {
+			result.TLSVersions = append(result.TLSVersions, version.name)
+		}
+	}
+
+	// Negotiate with the best settings to record ALPN and the cipher a modern client gets
+	state, err := handshake(host, address, tls.VersionTLS10, tls.VersionTLS13, []string{"h2", "http/1.1"})
+	if err != nil {
+		errText := err.Error()
+		result.Error = &errText
+		return result
+	}
+
+	protocol := state.NegotiatedProtocol
+	if protocol == "" {
+		protocol = "http/1.1"
+	}
+	result.ALPNProtocols = append(result.ALPNProtocols, protocol)
+
+	cipher := tls.CipherSuiteName(state.CipherSuite)
+	result.CipherSuite = &cipher
+	result.WeakCipher = isInsecureCipher(state.CipherSuite)
+
+	result.HTTP3 = advertisesHTTP3(host)
+
+	return result
+}
+
+// handshake performs a TLS handshake limited to the given version range. Internal
+// addresses are refused: the domain is user input.
+func handshake(host, address string, minVersion, maxVersion uint16, nextProtos []string) (tls.ConnectionState, error) {
+	conn, err := tls.DialWithDialer(netguard.Dialer(probeTimeout), "tcp", address, &tls.Config{
+		ServerName:         host,
+		MinVersion:         minVersion,
+		MaxVersion:         maxVersion,
+		CipherSuites:       allCipherSuites,
+		NextProtos:         nextProtos,
+		InsecureSkipVerify: true, // Only capabilities are probed; certificates are checked elsewhere
+	})
+	if err != nil {
+		return tls.ConnectionState{}, err
+	}
+	defer conn.Close()
+	return conn.ConnectionState(), nil
+}
+
+// advertisesHTTP3 reports whether the domain advertises h3 in its Alt-Svc header
+func advertisesHTTP3(host string) bool {
+	transport := netguard.Transport()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	defer transport.CloseIdleConnections()
+	client := &http.Client{
+		Timeout:   probeTimeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	resp, err := client.Head("https://" + host + "/")
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	for _, altSvc := range resp.Header.Values("Alt-Svc") {
+		for _, entry := range strings.Split(altSvc, ",") {
+			entry = strings.TrimSpace(entry)
+			if strings.HasPrefix(entry, "h3=") || strings.HasPrefix(entry, "h3-") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// probeTarget returns the TLS server name and host:port to dial for a domain entry
+func probeTarget(domainName string) (string, string) {
+	raw := domainName
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	host := domainName
+	port := "443"
+	if parsed, err := url.Parse(raw); err == nil && parsed.Hostname() != "" {
+		host = parsed.Hostname()
+		if parsed.Scheme == "https" && parsed.Port() != "" {
+			port = parsed.Port()
+		}
+	}
+	return host, net.JoinHostPort(host, port)
+}
+
+// isInsecureCipher reports whether a cipher suite is one Go classifies as insecure
+func isInsecureCipher(id uint16) bool {
+	for _, suite := range tls.InsecureCipherSuites() {
+		if suite.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// describeRegressions compares two probes and describes capabilities that were lost
+func describeRegressions(previous, current model.TLSProbe) []string {
+	var regressions []string
+	if previous.HasALPN("h2") && !current.HasALPN("h2") {
+		regressions = append(regressions, "HTTP/2 is no longer negotiated")
+	}
+	if previous.HTTP3 && !current.HTTP3 {
+		regressions = append(regressions, "HTTP/3 is no longer advertised")
+	}
+	if !previous.HasTLSVersion("TLS 1.0") && current.HasTLSVersion("TLS 1.0") {
+		regressions = append(regressions, "TLS 1.0 is now accepted")
+	}
+	if previous.HasTLSVersion("TLS 1.3") && !current.HasTLSVersion("TLS 1.3") {
+		regressions = append(regressions, "TLS 1.3 is no longer supported")
+	}
+	if !previous.WeakCipher && current.WeakCipher {
+		regressions = append(regressions, fmt.Sprintf("a weak cipher suite is now negotiated (%s)", *current.CipherSuite))
+	}
+	return regressions
+}
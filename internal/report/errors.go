@@ -0,0 +1,20 @@
+package report
+
+import (
+	"errors"
+
+	"domain-detection-go/internal/domain"
+)
+
+// Errors returned by the report service for requests that cannot be applied. Handlers
+// match them with errors.Is to choose the response.
+var (
+	ErrInvalidWindow = errors.New("invalid window")
+	ErrInvalidSlug   = errors.New("invalid slug")
+	ErrSlugTaken     = errors.New("slug already taken")
+	ErrPageNotFound  = errors.New("status page not found")
+)
+
+// errDomainNotFound is returned for domains the user does not own. The domain package
+// is referenced through it because reports name their domain values "domain".
+var errDomainNotFound = domain.ErrDomainNotFound
@@ -0,0 +1,207 @@
+package report
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"time"
+
+	"domain-detection-go/pkg/model"
+
+	"github.com/lib/pq"
+)
+
+// slugPattern matches a public status page slug, e.g. "acme-status"
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{2,63}$`)
+
+// GetPublicPageSettings returns the hosted status page configuration of the user. Users
+// without a page get a disabled one with no domains.
+func (s *ReportService) GetPublicPageSettings(userID int) (*model.PublicPageSettings, error) {
+	settings := model.PublicPageSettings{DomainIDs: []int{}}
+	err := s.db.Get(&settings, `
+        SELECT is_enabled, slug, title, updated_at
+        FROM public_status_pages
+        WHERE user_id = $1
+    `, userID)
+	if err == sql.ErrNoRows {
+		return &settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status page: %w", err)
+	}
+
+	err = s.db.Select(&settings.DomainIDs, `
+        SELECT domain_id FROM public_status_page_domains WHERE user_id = $1 ORDER BY position
+    `, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status page domains: %w", err)
+	}
+	return &settings, nil
+}
+
+// UpdatePublicPageSettings replaces the hosted status page configuration of the user
+func (s *ReportService) UpdatePublicPageSettings(userID int, req model.PublicPageRequest) (*model.PublicPageSettings, error) {
+	current, err := s.GetPublicPageSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	slug := req.Slug
+	if slug == "" {
+		slug = current.Slug
+	}
+	if slug == "" {
+		if slug, err = generateSlug(); err != nil {
+			return nil, err
+		}
+	}
+	if !slugPattern.MatchString(slug) {
+		return nil, ErrInvalidSlug
+	}
+
+	domainIDs := []int{}
+	seen := make(map[int]bool, len(req.DomainIDs))
+	for _, id := range req.DomainIDs {
+		if !seen[id] {
+			seen[id] = true
+			domainIDs = append(domainIDs, id)
+		}
+	}
+
+	var owned []int
+	err = s.db.Select(&owned, "SELECT id FROM domains WHERE user_id = $1 AND id = ANY($2) AND deleted_at IS NULL", userID, pq.Array(domainIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify domains: %w", err)
+	}
+	if len(owned) != len(domainIDs) {
+		ownedSet := make(map[int]bool, len(owned))
+		for _, id := range owned {
+			ownedSet[id] = true
+		}
+		for _, id := range domainIDs {
+			if !ownedSet[id] {
+				return nil, fmt.Errorf("%w: %d", errDomainNotFound, id)
+			}
+		}
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+        INSERT INTO public_status_pages (user_id, slug, title, is_enabled)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (user_id) DO UPDATE SET
+            slug = EXCLUDED.slug,
+            title = EXCLUDED.title,
+            is_enabled = EXCLUDED.is_enabled,
+            updated_at = NOW()
+    `, userID, slug, req.Title, req.Enabled)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return nil, ErrSlugTaken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save status page: %w", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM public_status_page_domains WHERE user_id = $1", userID); err != nil {
+		return nil, fmt.Errorf("failed to clear status page domains: %w", err)
+	}
+	for i, id := range domainIDs {
+		_, err := tx.Exec(`
+            INSERT INTO public_status_page_domains (user_id, domain_id, position)
+            VALUES ($1, $2, $3)
+        `, userID, id, i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add domain %d to status page: %w", id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return s.GetPublicPageSettings(userID)
+}
+
+// GetPublicPage builds the public status page served at the slug. Archived domains
+// are left out.
+func (s *ReportService) GetPublicPage(slug string) (*model.PublicStatusPage, error) {
+	var page struct {
+		UserID int    `db:"user_id"`
+		Title  string `db:"title"`
+	}
+	err := s.db.Get(&page, `
+        SELECT user_id, title
+        FROM public_status_pages
+        WHERE slug = $1 AND is_enabled
+    `, slug)
+	if err == sql.ErrNoRows {
+		return nil, ErrPageNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status page: %w", err)
+	}
+
+	var domains []model.Domain
+	err = s.db.Select(&domains, `
+        SELECT d.id, d.name, d.last_status, d.error_code, d.last_check, d.recovery_pending, d.failure_pending
+        FROM public_status_page_domains p
+        JOIN domains d ON d.id = p.domain_id
+        WHERE p.user_id = $1 AND d.archived_at IS NULL AND d.deleted_at IS NULL
+        ORDER BY p.position
+    `, page.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status page domains: %w", err)
+	}
+
+	stats, err := s.windowStats(page.UserID, 0, 30*24)
+	if err != nil {
+		return nil, err
+	}
+	uptime := make(map[int]float64, len(stats))
+	for _, st := range stats {
+		if st.TotalChecks > 0 {
+			uptime[st.DomainID] = float64(st.TotalChecks-st.FailedChecks) * 100 / float64(st.TotalChecks)
+		}
+	}
+
+	result := &model.PublicStatusPage{
+		Title:       page.Title,
+		AllUp:       true,
+		Domains:     make([]model.PublicDomainStatus, 0, len(domains)),
+		GeneratedAt: time.Now(),
+	}
+	for _, d := range domains {
+		status := model.PublicDomainStatus{Name: d.Name, Status: model.PublicStatusUnknown}
+		if d.LastStatus != 0 || d.ErrorCode != 0 {
+			lastCheck := d.LastCheck
+			status.LastCheck = &lastCheck
+			status.Status = model.PublicStatusDown
+			if d.ConfirmedAvailable() {
+				status.Status = model.PublicStatusUp
+			}
+		}
+		if pct, ok := uptime[d.ID]; ok {
+			status.Uptime30d = &pct
+		}
+		if status.Status == model.PublicStatusDown {
+			result.AllUp = false
+		}
+		result.Domains = append(result.Domains, status)
+	}
+	return result, nil
+}
+
+// generateSlug returns a random slug for a new status page
+func generateSlug() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate slug: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,79 @@
+package report
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"domain-detection-go/pkg/model"
+)
+
+// GetDomainResponseStats returns the response time percentiles of a domain owned by the
+// user over the window, compared with the window just before it
+func (s *ReportService) GetDomainResponseStats(userID, domainID int, window string) (*model.DomainResponseStats, error) {
+	hours := 0
+	for _, w := range uptimeWindows {
+		if w.name == window {
+			hours = w.hours
+		}
+	}
+	if hours == 0 {
+		return nil, ErrInvalidWindow
+	}
+
+	var domain model.Domain
+	err := s.db.Get(&domain, `
+        SELECT id, name, region FROM domains
+        WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+    `, domainID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errDomainNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get domain: %w", err)
+	}
+
+	stats := &model.DomainResponseStats{DomainID: domain.ID, Name: domain.Name, Region: domain.Region, Window: window}
+	if stats.Current, err = s.responseTimes(domainID, hours, 0); err != nil {
+		return nil, err
+	}
+	if stats.Previous, err = s.responseTimes(domainID, hours, hours); err != nil {
+		return nil, err
+	}
+	stats.Trend = model.ResponseTimeTrend{
+		P50: percentChange(stats.Previous.P50, stats.Current.P50),
+		P95: percentChange(stats.Previous.P95, stats.Current.P95),
+		P99: percentChange(stats.Previous.P99, stats.Current.P99),
+	}
+	return stats, nil
+}
+
+// responseTimes aggregates the successful checks between offset+hours and offset hours ago
+func (s *ReportService) responseTimes(domainID, hours, offsetHours int) (model.ResponseTimeStats, error) {
+	var stats model.ResponseTimeStats
+	err := s.db.Get(&stats, `
+        SELECT COUNT(*) AS checks,
+               AVG(total_time)::float8 AS avg,
+               percentile_cont(0.5) WITHIN GROUP (ORDER BY total_time) AS p50,
+               percentile_cont(0.95) WITHIN GROUP (ORDER BY total_time) AS p95,
+               percentile_cont(0.99) WITHIN GROUP (ORDER BY total_time) AS p99,
+               MAX(total_time)::float8 AS max
+        FROM domain_check_history
+        WHERE domain_id = $1 AND available AND total_time > 0
+          AND checked_at >= NOW() - make_interval(hours => $2 + $3)
+          AND checked_at < NOW() - make_interval(hours => $3)
+    `, domainID, hours, offsetHours)
+	if err != nil {
+		return stats, fmt.Errorf("failed to aggregate response times: %w", err)
+	}
+	return stats, nil
+}
+
+// percentChange returns how much current differs from previous, in percent
+func percentChange(previous, current *float64) *float64 {
+	if previous == nil || current == nil || *previous == 0 {
+		return nil
+	}
+	change := (*current - *previous) * 100 / *previous
+	return &change
+}
@@ -0,0 +1,135 @@
+package report
+
+import (
+	"fmt"
+
+	"domain-detection-go/pkg/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// uptimeWindows are the report windows in hours, in response order
+var uptimeWindows = []struct {
+	name  string
+	hours int
+}{
+	{model.UptimeWindow24h, 24},
+	{model.UptimeWindow7d, 7 * 24},
+	{model.UptimeWindow30d, 30 * 24},
+}
+
+// windowStats is a row of the per-window aggregation
+type windowStats struct {
+	DomainID int `db:"domain_id"`
+	model.UptimeStats
+}
+
+// ReportService aggregates check history into uptime and SLA figures
+type ReportService struct {
+	db *sqlx.DB
+}
+
+// NewReportService creates a new report service
+func NewReportService(db *sqlx.DB) *ReportService {
+	return &ReportService{db: db}
+}
+
+// GetDomainUptime returns the uptime report of a single domain owned by the user
+func (s *ReportService) GetDomainUptime(userID, domainID int) (*model.DomainUptimeReport, error) {
+	reports, err := s.uptimeReports(userID, domainID)
+	if err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 {
+		return nil, errDomainNotFound
+	}
+	return &reports[0], nil
+}
+
+// GetUptimeReport returns the uptime report of every non-archived domain of the user
+func (s *ReportService) GetUptimeReport(userID int) ([]model.DomainUptimeReport, error) {
+	return s.uptimeReports(userID, 0)
+}
+
+// uptimeReports builds reports for the user's domains, or only for domainID when set
+func (s *ReportService) uptimeReports(userID, domainID int) ([]model.DomainUptimeReport, error) {
+	var domains []model.Domain
+	err := s.db.Select(&domains, `
+        SELECT id, name, region
+        FROM domains
+        WHERE user_id = $1 AND ($2 = 0 OR id = $2) AND ($2 <> 0 OR archived_at IS NULL) AND deleted_at IS NULL
+        ORDER BY name
+    `, userID, domainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get domains: %w", err)
+	}
+
+	reports := make([]model.DomainUptimeReport, len(domains))
+	index := make(map[int]int, len(domains))
+	for i, d := range domains {
+		reports[i] = model.DomainUptimeReport{DomainID: d.ID, Name: d.Name, Region: d.Region, Windows: []model.UptimeStats{}}
+		index[d.ID] = i
+	}
+	if len(domains) == 0 {
+		return reports, nil
+	}
+
+	for _, window := range uptimeWindows {
+		stats, err := s.windowStats(userID, domainID, window.hours)
+		if err != nil {
+			return nil, err
+		}
+
+		byDomain := make(map[int]model.UptimeStats, len(stats))
+		for _, st := range stats {
+			byDomain[st.DomainID] = st.UptimeStats
+		}
+		for i := range reports {
+			st := byDomain[reports[i].DomainID]
+			st.Window = window.name
+			if st.TotalChecks > 0 {
+				uptime := float64(st.TotalChecks-st.FailedChecks) * 100 / float64(st.TotalChecks)
+				st.UptimePercentage = &uptime
+			}
+			reports[i].Windows = append(reports[i].Windows, st)
+		}
+	}
+
+	return reports, nil
+}
+
+// windowStats aggregates the checks of the last hours per domain. An incident is a run
+// of failed checks; a run already ongoing when the window starts is counted once.
+func (s *ReportService) windowStats(userID, domainID, hours int) ([]windowStats, error) {
+	var stats []windowStats
+	err := s.db.Select(&stats, `
+        WITH checks AS (
+            SELECT h.domain_id, h.available, h.total_time,
+                   LAG(h.available) OVER (PARTITION BY h.domain_id ORDER BY h.checked_at) AS prev_available
+            FROM domain_check_history h
+            JOIN domains d ON d.id = h.domain_id
+            WHERE d.user_id = $1 AND ($2 = 0 OR d.id = $2)
+              AND h.checked_at >= NOW() - make_interval(hours => $3)
+        ), skipped AS (
+            SELECT k.domain_id, COUNT(*) AS skipped_checks
+            FROM domain_skipped_checks k
+            JOIN domains d ON d.id = k.domain_id
+            WHERE d.user_id = $1 AND ($2 = 0 OR d.id = $2)
+              AND k.skipped_at >= NOW() - make_interval(hours => $3)
+            GROUP BY k.domain_id
+        )
+        SELECT c.domain_id,
+               COUNT(*) AS total_checks,
+               COUNT(*) FILTER (WHERE NOT c.available) AS failed_checks,
+               COALESCE(MAX(k.skipped_checks), 0) AS skipped_checks,
+               COALESCE(AVG(c.total_time) FILTER (WHERE c.available AND c.total_time > 0), 0) AS avg_response_time,
+               COUNT(*) FILTER (WHERE NOT c.available AND (c.prev_available IS NULL OR c.prev_available)) AS incidents
+        FROM checks c
+        LEFT JOIN skipped k ON k.domain_id = c.domain_id
+        GROUP BY c.domain_id
+    `, userID, domainID, hours)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate check history: %w", err)
+	}
+	return stats, nil
+}
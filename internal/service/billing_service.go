@@ -0,0 +1,277 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"domain-detection-go/internal/events"
+	"domain-detection-go/internal/export"
+	"domain-detection-go/pkg/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// statementMonthLayout is the format of the month a statement covers
+const statementMonthLayout = "2006-01"
+
+// BillingService records check usage and builds monthly usage statements
+type BillingService struct {
+	db *sqlx.DB
+}
+
+// NewBillingService creates a new billing service and starts counting checks from the event bus
+func NewBillingService(db *sqlx.DB, eventBus *events.Bus) *BillingService {
+	s := &BillingService{db: db}
+	if eventBus != nil {
+		eventBus.Subscribe(s.handleDomainChecked, events.DomainChecked)
+	}
+	return s
+}
+
+// handleDomainChecked counts a completed check against the domain's current month
+func (s *BillingService) handleDomainChecked(e events.Event) {
+	if err := s.RecordCheck(e.DomainID, e.OccurredAt); err != nil {
+		slog.Error("Failed to record billable check", "domain_id", e.DomainID, "error", err)
+	}
+}
+
+// RecordCheck increments the check counter for a domain in the month of checkedAt
+func (s *BillingService) RecordCheck(domainID int, checkedAt time.Time) error {
+	_, err := s.db.Exec(`
+        INSERT INTO domain_check_usage (domain_id, period_start, checks, updated_at)
+        VALUES ($1, $2, 1, NOW())
+        ON CONFLICT (domain_id, period_start)
+        DO UPDATE SET checks = domain_check_usage.checks + 1, updated_at = NOW()
+    `, domainID, monthStart(checkedAt))
+	if err != nil {
+		return fmt.Errorf("failed to record check usage: %w", err)
+	}
+	return nil
+}
+
+// GetPrices returns the unit prices for every plan
+func (s *BillingService) GetPrices() ([]model.BillingUnitPrices, error) {
+	var prices []model.BillingUnitPrices
+	err := s.db.Select(&prices, `
+        SELECT plan, currency, domain_price, check_price, deep_check_price, notification_price, updated_at
+        FROM billing_unit_prices
+        ORDER BY plan
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unit prices: %w", err)
+	}
+	return prices, nil
+}
+
+// UpsertPrices creates or updates the unit prices for a plan
+func (s *BillingService) UpsertPrices(plan string, req model.BillingUnitPricesRequest) error {
+	if plan == "" {
+		return errors.New("plan is required")
+	}
+
+	_, err := s.db.Exec(`
+        INSERT INTO billing_unit_prices (plan, currency, domain_price, check_price, deep_check_price, notification_price, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, NOW())
+        ON CONFLICT (plan)
+        DO UPDATE SET currency = $2, domain_price = $3, check_price = $4,
+                      deep_check_price = $5, notification_price = $6, updated_at = NOW()
+    `, plan, req.Currency, req.DomainPrice, req.CheckPrice, req.DeepCheckPrice, req.NotificationPrice)
+	if err != nil {
+		return fmt.Errorf("failed to save unit prices: %w", err)
+	}
+	return nil
+}
+
+// getPricesForUser returns the unit prices of the user's plan, falling back to the default plan
+func (s *BillingService) getPricesForUser(userID int) (model.BillingUnitPrices, error) {
+	var prices model.BillingUnitPrices
+	err := s.db.Get(&prices, `
+        SELECT plan, currency, domain_price, check_price, deep_check_price, notification_price, updated_at
+        FROM billing_unit_prices
+        WHERE plan IN (COALESCE((SELECT plan FROM user_settings WHERE user_id = $1), $2), $2)
+        ORDER BY plan = $2
+        LIMIT 1
+    `, userID, model.DEFAULT_PLAN)
+	if err == sql.ErrNoRows {
+		return model.BillingUnitPrices{Plan: model.DEFAULT_PLAN, Currency: "USD"}, nil
+	}
+	if err != nil {
+		return prices, fmt.Errorf("failed to get unit prices: %w", err)
+	}
+	return prices, nil
+}
+
+// GetStatement builds the statement for a user and month (YYYY-MM, empty for the current month)
+func (s *BillingService) GetStatement(userID int, month string) (*model.Statement, error) {
+	start, err := parseStatementMonth(month)
+	if err != nil {
+		return nil, err
+	}
+	end := start.AddDate(0, 1, 0)
+
+	statement := &model.Statement{
+		UserID:      userID,
+		Month:       start.Format(statementMonthLayout),
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Lines:       []model.StatementLine{},
+	}
+
+	err = s.db.Get(&statement.Username, "SELECT username FROM users WHERE id = $1", userID)
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	statement.Prices, err = s.getPricesForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Select(&statement.Lines, `
+        SELECT d.id AS domain_id, d.name AS domain_name, COALESCE(d.region, '') AS region, d.interval,
+               COALESCE((SELECT u.checks FROM domain_check_usage u
+                         WHERE u.domain_id = d.id AND u.period_start = $2::date), 0) AS checks,
+               (SELECT COUNT(*) FROM deep_check_orders o
+                WHERE o.domain_id = d.id AND o.created_at >= $2 AND o.created_at < $3) AS deep_checks,
+               (SELECT COUNT(*) FROM notification_history nh
+                WHERE nh.domain_id = d.id AND nh.notified_at >= $2 AND nh.notified_at < $3
+                  AND nh.delivery_status = 'sent') AS notifications
+        FROM domains d
+        WHERE d.user_id = $1 AND d.created_at < $3
+        ORDER BY d.name, d.region
+    `, userID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statement usage: %w", err)
+	}
+
+	p := statement.Prices
+	for i := range statement.Lines {
+		line := &statement.Lines[i]
+		line.Amount = p.DomainPrice +
+			float64(line.Checks)*p.CheckPrice +
+			float64(line.DeepChecks)*p.DeepCheckPrice +
+			float64(line.Notifications)*p.NotificationPrice
+
+		statement.TotalDomains++
+		statement.TotalChecks += line.Checks
+		statement.TotalDeepChecks += line.DeepChecks
+		statement.TotalNotifications += line.Notifications
+		statement.TotalAmount += line.Amount
+	}
+
+	return statement, nil
+}
+
+// GetStatements builds the statements of every user with domains for a month
+func (s *BillingService) GetStatements(month string) ([]model.Statement, error) {
+	start, err := parseStatementMonth(month)
+	if err != nil {
+		return nil, err
+	}
+
+	var userIDs []int
+	err = s.db.Select(&userIDs, `
+        SELECT DISTINCT user_id FROM domains
+        WHERE created_at < $1
+        ORDER BY user_id
+    `, start.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get billable users: %w", err)
+	}
+
+	statements := []model.Statement{}
+	for _, userID := range userIDs {
+		statement, err := s.GetStatement(userID, month)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, *statement)
+	}
+	return statements, nil
+}
+
+// WriteStatementCSV writes a statement as CSV, one row per domain plus a total row
+func WriteStatementCSV(w io.Writer, statements ...model.Statement) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"month", "user_id", "username", "domain_id", "domain", "region", "interval",
+		"checks", "deep_checks", "notifications", "amount", "currency"})
+
+	for _, st := range statements {
+		for _, line := range st.Lines {
+			cw.Write([]string{
+				st.Month, strconv.Itoa(st.UserID), st.Username,
+				strconv.Itoa(line.DomainID), line.DomainName, line.Region, strconv.Itoa(line.Interval),
+				strconv.Itoa(line.Checks), strconv.Itoa(line.DeepChecks), strconv.Itoa(line.Notifications),
+				formatAmount(line.Amount), st.Prices.Currency,
+			})
+		}
+		cw.Write([]string{
+			st.Month, strconv.Itoa(st.UserID), st.Username,
+			"", "TOTAL", "", "",
+			strconv.Itoa(st.TotalChecks), strconv.Itoa(st.TotalDeepChecks), strconv.Itoa(st.TotalNotifications),
+			formatAmount(st.TotalAmount), st.Prices.Currency,
+		})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// StatementPDF renders a statement as a PDF document
+func StatementPDF(st model.Statement) []byte {
+	doc := export.NewTextPDF()
+	doc.AddLine("Usage statement %s", st.Month)
+	doc.AddLine("Account: %s (ID %d)", st.Username, st.UserID)
+	doc.AddLine("Period: %s - %s", st.PeriodStart.Format("2006-01-02"), st.PeriodEnd.AddDate(0, 0, -1).Format("2006-01-02"))
+	doc.AddLine("Plan: %s", st.Prices.Plan)
+	doc.AddBlank()
+	doc.AddLine("Unit prices (%s): domain %s/month, check %s, deep check %s, notification %s",
+		st.Prices.Currency, formatAmount(st.Prices.DomainPrice), formatAmount(st.Prices.CheckPrice),
+		formatAmount(st.Prices.DeepCheckPrice), formatAmount(st.Prices.NotificationPrice))
+	doc.AddBlank()
+
+	row := "%-40.40s %-6.6s %8s %6s %6s %12s"
+	doc.AddLine(row, "Domain", "Region", "Checks", "Deep", "Notif", "Amount")
+	for _, line := range st.Lines {
+		doc.AddLine(row, line.DomainName, line.Region, strconv.Itoa(line.Checks),
+			strconv.Itoa(line.DeepChecks), strconv.Itoa(line.Notifications), formatAmount(line.Amount))
+	}
+	doc.AddBlank()
+	doc.AddLine(row, fmt.Sprintf("TOTAL (%d domains)", st.TotalDomains), "", strconv.Itoa(st.TotalChecks),
+		strconv.Itoa(st.TotalDeepChecks), strconv.Itoa(st.TotalNotifications), formatAmount(st.TotalAmount))
+	doc.AddLine("Amounts in %s", st.Prices.Currency)
+
+	return doc.Bytes()
+}
+
+// parseStatementMonth parses YYYY-MM into the first instant of that month (UTC)
+func parseStatementMonth(month string) (time.Time, error) {
+	if month == "" {
+		return monthStart(time.Now()), nil
+	}
+	t, err := time.Parse(statementMonthLayout, month)
+	if err != nil {
+		return time.Time{}, errors.New("month must be in YYYY-MM format")
+	}
+	return t, nil
+}
+
+// monthStart returns the first instant of t's month in UTC
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// formatAmount renders a monetary amount with two decimals
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
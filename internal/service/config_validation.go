@@ -0,0 +1,127 @@
+package service
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"domain-detection-go/pkg/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// SupportedLanguages lists the notification languages we have prompts for
+var SupportedLanguages = []string{"en", "zh", "hi", "id", "vi", "ko", "ja", "th"}
+
+// telegramChatIDPattern matches numeric chat IDs (groups are negative) and @channel usernames
+var telegramChatIDPattern = regexp.MustCompile(`^(-?\d+|@[A-Za-z][A-Za-z0-9_]{4,31})$`)
+
+// ConfigValidationService validates notification channel configs before they are stored
+type ConfigValidationService struct {
+	db *sqlx.DB
+}
+
+// NewConfigValidationService creates a new config validation service
+func NewConfigValidationService(db *sqlx.DB) *ConfigValidationService {
+	return &ConfigValidationService{db: db}
+}
+
+// ValidateTelegramConfig returns every problem found with a Telegram config
+func (s *ConfigValidationService) ValidateTelegramConfig(userID int, chatID, language string, regions []string) []string {
+	return s.ValidateTelegramConfigReplacing(userID, 0, chatID, language, regions)
+}
+
+// ValidateTelegramConfigReplacing validates a Telegram config that will overwrite configID,
+// so that config does not count as a duplicate. configID is 0 for new configs.
+func (s *ConfigValidationService) ValidateTelegramConfigReplacing(userID, configID int, chatID, language string, regions []string) []string {
+	var problems []string
+
+	if chatID == "" {
+		problems = append(problems, "chat_id is required")
+	} else if !telegramChatIDPattern.MatchString(chatID) {
+		problems = append(problems, "chat_id must be a numeric ID or @channel username")
+	} else {
+		var exists bool
+		err := s.db.Get(&exists, "SELECT EXISTS(SELECT 1 FROM telegram_configs WHERE user_id = $1 AND chat_id = $2 AND id <> $3)", userID, chatID, configID)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("failed to check existing configs: %v", err))
+		} else if exists {
+			problems = append(problems, "a Telegram config for this chat already exists")
+		}
+	}
+
+	return append(problems, s.validateCommon(language, regions)...)
+}
+
+// ValidateEmailConfig returns every problem found with an email config
+func (s *ConfigValidationService) ValidateEmailConfig(userID int, emailAddress, language string, regions []string) []string {
+	return s.ValidateEmailConfigReplacing(userID, 0, emailAddress, language, regions)
+}
+
+// ValidateEmailConfigReplacing validates an email config that will overwrite configID,
+// so that config does not count as a duplicate. configID is 0 for new configs.
+func (s *ConfigValidationService) ValidateEmailConfigReplacing(userID, configID int, emailAddress, language string, regions []string) []string {
+	var problems []string
+
+	if emailAddress == "" {
+		problems = append(problems, "email_address is required")
+	} else if addr, err := mail.ParseAddress(emailAddress); err != nil || addr.Address != emailAddress {
+		problems = append(problems, "email_address is not a valid email address")
+	} else {
+		var exists bool
+		err := s.db.Get(&exists, "SELECT EXISTS(SELECT 1 FROM email_configs WHERE user_id = $1 AND LOWER(email_address) = LOWER($2) AND id <> $3)", userID, emailAddress, configID)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("failed to check existing configs: %v", err))
+		} else if exists {
+			problems = append(problems, "an email config for this address already exists")
+		}
+	}
+
+	return append(problems, s.validateCommon(language, regions)...)
+}
+
+// ValidateBatchItem validates a batch item according to its channel
+func (s *ConfigValidationService) ValidateBatchItem(userID int, item model.NotificationConfigBatchItem) []string {
+	switch item.Channel {
+	case model.ChannelTelegram:
+		return s.ValidateTelegramConfig(userID, item.ChatID, item.Language, item.MonitorRegions)
+	case model.ChannelEmail:
+		return s.ValidateEmailConfig(userID, item.EmailAddress, item.Language, item.MonitorRegions)
+	case "":
+		return []string{"channel is required"}
+	default:
+		return []string{fmt.Sprintf("unsupported channel: %s", item.Channel)}
+	}
+}
+
+// validateCommon checks fields shared by every channel
+func (s *ConfigValidationService) validateCommon(language string, regions []string) []string {
+	var problems []string
+
+	if language != "" && !isSupportedLanguage(language) {
+		problems = append(problems, fmt.Sprintf("language must be one of %s", strings.Join(SupportedLanguages, ", ")))
+	}
+
+	for _, region := range regions {
+		var exists bool
+		err := s.db.Get(&exists, "SELECT EXISTS(SELECT 1 FROM regions WHERE code = $1)", region)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("failed to verify region %s: %v", region, err))
+		} else if !exists {
+			problems = append(problems, fmt.Sprintf("region code not found: %s", region))
+		}
+	}
+
+	return problems
+}
+
+// isSupportedLanguage checks if a language code has notification prompts
+func isSupportedLanguage(language string) bool {
+	for _, l := range SupportedLanguages {
+		if l == language {
+			return true
+		}
+	}
+	return false
+}
@@ -1,9 +1,10 @@
 package service
 
 import (
+	"database/sql"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"domain-detection-go/internal/deepcheck"
@@ -14,41 +15,53 @@ import (
 
 // DeepCheckService handles deep check order management
 type DeepCheckService struct {
-	db *sqlx.DB
+	db                 *sqlx.DB
+	escalationFailures int // Default failed checks before escalating to a deep check, 0 disables
 }
 
 // NewDeepCheckService creates a new deep check service
-func NewDeepCheckService(db *sqlx.DB) *DeepCheckService {
+func NewDeepCheckService(db *sqlx.DB, escalationFailures int) *DeepCheckService {
 	return &DeepCheckService{
-		db: db,
+		db:                 db,
+		escalationFailures: escalationFailures,
 	}
 }
 
-// CreateDeepCheckOrder creates a new deep check order record
-func (s *DeepCheckService) CreateDeepCheckOrder(orderID string, userID, domainID int, domainName string) error {
+// CreateDeepCheckOrder creates a new deep check order record. consecutiveFailures is
+// only set for escalations.
+func (s *DeepCheckService) CreateDeepCheckOrder(orderID string, userID, domainID int, domainName, source string, consecutiveFailures *int) error {
 	_, err := s.db.Exec(`
-        INSERT INTO deep_check_orders (order_id, user_id, domain_id, domain_name, status, created_at)
-        VALUES ($1, $2, $3, $4, 'pending', NOW())
-    `, orderID, userID, domainID, domainName)
+        INSERT INTO deep_check_orders (order_id, user_id, domain_id, domain_name, status, source, consecutive_failures, created_at)
+        VALUES ($1, $2, $3, $4, 'pending', $5, $6, NOW())
+    `, orderID, userID, domainID, domainName, source, consecutiveFailures)
 
 	if err != nil {
-		log.Printf("Failed to create deep check order record: %v", err)
 		return fmt.Errorf("failed to create deep check order: %w", err)
 	}
 
-	log.Printf("Created deep check order record: OrderID=%s, UserID=%d, DomainID=%d, Domain=%s",
-		orderID, userID, domainID, domainName)
+	slog.Info("Created deep check order", "order_id", orderID, "user_id", userID, "domain_id", domainID, "domain", domainName)
 
 	return nil
 }
 
+// SetOrderReplyChat makes the results of an order go to a single Telegram chat instead
+// of every notification channel of the user
+func (s *DeepCheckService) SetOrderReplyChat(orderID, chatID string) error {
+	_, err := s.db.Exec("UPDATE deep_check_orders SET reply_chat_id = $1 WHERE order_id = $2", chatID, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to set deep check reply chat: %w", err)
+	}
+	return nil
+}
+
 // GetDeepCheckOrderByOrderID retrieves a deep check order by order ID
 func (s *DeepCheckService) GetDeepCheckOrderByOrderID(orderID string) (*model.DeepCheckOrder, error) {
 	var order model.DeepCheckOrder
 
 	err := s.db.Get(&order, `
         SELECT id, order_id, user_id, domain_id, domain_name, status, 
-               created_at, completed_at, callback_received, callback_data
+               created_at, completed_at, callback_received, callback_data, source, consecutive_failures,
+               reply_chat_id
         FROM deep_check_orders 
         WHERE order_id = $1
     `, orderID)
@@ -60,35 +73,90 @@ func (s *DeepCheckService) GetDeepCheckOrderByOrderID(orderID string) (*model.De
 	return &order, nil
 }
 
-// UpdateDeepCheckOrderCallback updates the order with callback data
-func (s *DeepCheckService) UpdateDeepCheckOrderCallback(orderID string, callback *deepcheck.DeepCheckCallbackRequest) error {
+// GetUserDeepCheckOrder retrieves a deep check order by order ID if it belongs to the user
+func (s *DeepCheckService) GetUserDeepCheckOrder(orderID string, userID int) (*model.DeepCheckOrder, error) {
+	var order model.DeepCheckOrder
+
+	err := s.db.Get(&order, `
+        SELECT id, order_id, user_id, domain_id, domain_name, status, 
+               created_at, completed_at, callback_received, callback_data, source, consecutive_failures,
+               reply_chat_id
+        FROM deep_check_orders 
+        WHERE order_id = $1 AND user_id = $2
+    `, orderID, userID)
+	if err == sql.ErrNoRows {
+		return nil, ErrDeepCheckOrderNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deep check order: %w", err)
+	}
+
+	return &order, nil
+}
+
+// GetRecentPendingOrder returns the domain's pending order created within the last
+// withinMinutes, or nil if there is none
+func (s *DeepCheckService) GetRecentPendingOrder(domainID, withinMinutes int) (*model.DeepCheckOrder, error) {
+	var order model.DeepCheckOrder
+
+	err := s.db.Get(&order, `
+        SELECT id, order_id, user_id, domain_id, domain_name, status, 
+               created_at, completed_at, callback_received, callback_data, source, consecutive_failures,
+               reply_chat_id
+        FROM deep_check_orders 
+        WHERE domain_id = $1 AND status = 'pending'
+          AND created_at > NOW() - make_interval(mins => $2)
+        ORDER BY created_at DESC
+        LIMIT 1
+    `, domainID, withinMinutes)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending deep check order: %w", err)
+	}
+
+	return &order, nil
+}
+
+// UpdateDeepCheckOrderCallback completes a pending order with its callback data. It
+// reports false when the order was no longer pending, so a re-delivered callback is
+// only processed once.
+func (s *DeepCheckService) UpdateDeepCheckOrderCallback(orderID string, callback *deepcheck.DeepCheckCallbackRequest) (bool, error) {
 	// Convert callback to JSON for storage
 	callbackJSON, err := json.Marshal(callback)
 	if err != nil {
-		return fmt.Errorf("failed to marshal callback data: %w", err)
+		return false, fmt.Errorf("failed to marshal callback data: %w", err)
 	}
 
 	var callbackData model.CallbackData
 	if err := json.Unmarshal(callbackJSON, &callbackData); err != nil {
-		return fmt.Errorf("failed to convert callback data: %w", err)
+		return false, fmt.Errorf("failed to convert callback data: %w", err)
 	}
 
-	_, err = s.db.Exec(`
+	result, err := s.db.Exec(`
         UPDATE deep_check_orders 
         SET status = 'completed', 
             completed_at = NOW(), 
             callback_received = true, 
             callback_data = $1
-        WHERE order_id = $2
+        WHERE order_id = $2 AND status = 'pending'
     `, callbackData, orderID)
 
 	if err != nil {
-		log.Printf("Failed to update deep check order callback: %v", err)
-		return fmt.Errorf("failed to update deep check order: %w", err)
+		return false, fmt.Errorf("failed to update deep check order: %w", err)
 	}
 
-	log.Printf("Updated deep check order with callback: OrderID=%s", orderID)
-	return nil
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check updated deep check order: %w", err)
+	}
+	if rows == 0 {
+		return false, nil
+	}
+
+	slog.Info("Completed deep check order", "order_id", orderID)
+	return true, nil
 }
 
 // GetPendingDeepCheckOrders gets all pending orders (for cleanup/monitoring)
@@ -99,7 +167,8 @@ func (s *DeepCheckService) GetPendingDeepCheckOrders(olderThanMinutes int) ([]mo
 
 	err := s.db.Select(&orders, `
         SELECT id, order_id, user_id, domain_id, domain_name, status, 
-               created_at, completed_at, callback_received, callback_data
+               created_at, completed_at, callback_received, callback_data, source, consecutive_failures,
+               reply_chat_id
         FROM deep_check_orders 
         WHERE status = 'pending' AND created_at < $1
         ORDER BY created_at ASC
@@ -108,15 +177,100 @@ func (s *DeepCheckService) GetPendingDeepCheckOrders(olderThanMinutes int) ([]mo
 	return orders, err
 }
 
-// MarkDeepCheckOrderFailed marks an order as failed
-func (s *DeepCheckService) MarkDeepCheckOrderFailed(orderID string, reason string) error {
-	_, err := s.db.Exec(`
+// MarkDeepCheckOrderFailed marks a pending order as failed. It reports false when the
+// order was no longer pending, e.g. because its callback arrived in the meantime.
+func (s *DeepCheckService) MarkDeepCheckOrderFailed(orderID string, reason string) (bool, error) {
+	result, err := s.db.Exec(`
         UPDATE deep_check_orders 
         SET status = 'failed', 
             completed_at = NOW(),
-            callback_data = jsonb_build_object('error', $1)
-        WHERE order_id = $2
+            callback_data = jsonb_build_object('error', $1::text)
+        WHERE order_id = $2 AND status = 'pending'
     `, reason, orderID)
+	if err != nil {
+		return false, err
+	}
 
-	return err
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check failed deep check order: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// ExpirePendingOrders fails the orders still waiting for their callback after
+// olderThanMinutes and returns the ones this call failed. Orders completed or expired
+// concurrently are left out, so they are only reported once.
+func (s *DeepCheckService) ExpirePendingOrders(olderThanMinutes int) ([]model.DeepCheckOrder, error) {
+	orders, err := s.GetPendingDeepCheckOrders(olderThanMinutes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending deep check orders: %w", err)
+	}
+
+	var expired []model.DeepCheckOrder
+	for _, order := range orders {
+		failed, err := s.MarkDeepCheckOrderFailed(order.OrderID, "callback timed out")
+		if err != nil {
+			slog.Error("Failed to expire deep check order", "order_id", order.OrderID, "error", err)
+			continue
+		}
+		if !failed {
+			continue
+		}
+		slog.Warn("Deep check order timed out waiting for its callback", "order_id", order.OrderID, "domain", order.DomainName)
+		expired = append(expired, order)
+	}
+	return expired, nil
+}
+
+// GetUserDeepCheckOrders lists a user's most recent deep check orders, optionally for one domain
+func (s *DeepCheckService) GetUserDeepCheckOrders(userID, domainID, limit int) ([]model.DeepCheckOrder, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+
+	orders := []model.DeepCheckOrder{}
+	err := s.db.Select(&orders, `
+        SELECT id, order_id, user_id, domain_id, domain_name, status, 
+               created_at, completed_at, callback_received, callback_data, source, consecutive_failures,
+               reply_chat_id
+        FROM deep_check_orders 
+        WHERE user_id = $1 AND ($2 = 0 OR domain_id = $2)
+        ORDER BY created_at DESC
+        LIMIT $3
+    `, userID, domainID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deep check orders: %w", err)
+	}
+
+	return orders, nil
+}
+
+// GetEscalationSettings returns after how many consecutive failed checks a deep check
+// is ordered for the user's domains
+func (s *DeepCheckService) GetEscalationSettings(userID int) (*model.DeepCheckEscalationSettings, error) {
+	var failures sql.NullInt64
+	err := s.db.Get(&failures, "SELECT deep_check_escalation_failures FROM user_settings WHERE user_id = $1", userID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get deep check escalation settings: %w", err)
+	}
+
+	if !failures.Valid {
+		return &model.DeepCheckEscalationSettings{Failures: s.escalationFailures, IsDefault: true}, nil
+	}
+	return &model.DeepCheckEscalationSettings{Failures: int(failures.Int64)}, nil
+}
+
+// UpdateEscalationSettings sets the user's escalation threshold; nil restores the default
+func (s *DeepCheckService) UpdateEscalationSettings(userID int, failures *int) error {
+	_, err := s.db.Exec(`
+        INSERT INTO user_settings (user_id, deep_check_escalation_failures, updated_at)
+        VALUES ($1, $2, NOW())
+        ON CONFLICT (user_id)
+        DO UPDATE SET deep_check_escalation_failures = $2, updated_at = NOW()
+    `, userID, failures)
+	if err != nil {
+		return fmt.Errorf("failed to update deep check escalation settings: %w", err)
+	}
+	return nil
 }
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"testing"
+
+	"domain-detection-go/internal/testdb"
+)
+
+func TestDeepCheckOrderFailsOnlyOnce(t *testing.T) {
+	db := testdb.Open(t)
+	s := NewDeepCheckService(db, 0)
+
+	_, err := db.Exec(`
+        WITH u AS (
+            INSERT INTO users (username, password_hash, email) VALUES ('deepcheck', 'hash', 'deepcheck@example.com')
+            RETURNING id
+        ), d AS (
+            INSERT INTO domains (user_id, name, region) SELECT id, 'example.com', 'VN' FROM u
+            RETURNING id, user_id
+        )
+        INSERT INTO deep_check_orders (order_id, user_id, domain_id, domain_name, created_at)
+        SELECT o.order_id, d.user_id, d.id, 'example.com', NOW() - INTERVAL '1 hour'
+        FROM d, (VALUES ('expire'), ('fail')) AS o(order_id)
+    `)
+	if err != nil {
+		t.Fatalf("insert orders: %v", err)
+	}
+
+	if failed, err := s.MarkDeepCheckOrderFailed("fail", "dispatch failed"); err != nil || !failed {
+		t.Fatalf("first MarkDeepCheckOrderFailed = %v, %v, want true, nil", failed, err)
+	}
+	if failed, err := s.MarkDeepCheckOrderFailed("fail", "dispatch failed"); err != nil || failed {
+		t.Fatalf("second MarkDeepCheckOrderFailed = %v, %v, want false, nil", failed, err)
+	}
+
+	expired, err := s.ExpirePendingOrders(30)
+	if err != nil || len(expired) != 1 || expired[0].OrderID != "expire" {
+		t.Fatalf("ExpirePendingOrders = %v, %v, want only order expire", expired, err)
+	}
+	if expired, err := s.ExpirePendingOrders(30); err != nil || len(expired) != 0 {
+		t.Fatalf("second ExpirePendingOrders = %v, %v, want none", expired, err)
+	}
+}
@@ -0,0 +1,11 @@
+package service
+
+import "errors"
+
+// Errors returned by the services for requests that cannot be applied. Handlers match
+// them with errors.Is to choose the response.
+var (
+	ErrUserNotFound            = errors.New("user not found")
+	ErrDeepCheckOrderNotFound  = errors.New("deep check order not found")
+	ErrIncompleteLanguageChain = errors.New("no language in the chain covers all alert messages")
+)
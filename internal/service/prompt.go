@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type TelegramPromptService struct {
@@ -116,8 +117,52 @@ func (s *TelegramPromptService) GetPrompts(page, perPage int, search, sortBy, so
 	}, nil
 }
 
+// DefaultLanguage is the last resort of every language chain
+const DefaultLanguage = "en"
+
+// AlertCriticalPromptKeys are the prompts every down and recovery alert is built from.
+// A fallback chain must contain at least one language that translates all of them.
+var AlertCriticalPromptKeys = []string{
+	"telegram.label.domain",
+	"telegram.message.domain_down",
+	"telegram.message.domain_up",
+	"telegram.label.status",
+	"telegram.label.error",
+	"telegram.label.response_time",
+	"telegram.label.last_check",
+}
+
+// LanguageChain returns the languages tried in order for a config: its own language,
+// then its fallbacks, then English. Duplicates and empty entries are dropped.
+func LanguageChain(language string, fallbacks []string) []string {
+	chain := make([]string, 0, len(fallbacks)+2)
+	seen := make(map[string]bool)
+	for _, lang := range append(append([]string{language}, fallbacks...), DefaultLanguage) {
+		if lang != "" && !seen[lang] {
+			seen[lang] = true
+			chain = append(chain, lang)
+		}
+	}
+	return chain
+}
+
+// resolveMessage returns the first non-empty message along the chain
+func resolveMessage(messages map[string]string, chain []string) (string, bool) {
+	for _, lang := range chain {
+		if msg, exists := messages[lang]; exists && msg != "" {
+			return msg, true
+		}
+	}
+	return "", false
+}
+
 // GetTranslation gets a specific message for a language
 func (s *TelegramPromptService) GetTranslation(key, language string) (string, error) {
+	return s.GetTranslationForChain(key, LanguageChain(language, nil))
+}
+
+// GetTranslationForChain gets a message in the first language of the chain that has it
+func (s *TelegramPromptService) GetTranslationForChain(key string, chain []string) (string, error) {
 	var messages MessagesMap
 	err := s.db.Get(&messages, `
         SELECT messages FROM telegram_prompts 
@@ -127,13 +172,7 @@ func (s *TelegramPromptService) GetTranslation(key, language string) (string, er
 		return "", err
 	}
 
-	// Try to get the specific language
-	if msg, exists := messages[language]; exists && msg != "" {
-		return msg, nil
-	}
-
-	// Fallback to English
-	if msg, exists := messages["en"]; exists && msg != "" {
+	if msg, ok := resolveMessage(messages, chain); ok {
 		return msg, nil
 	}
 
@@ -143,6 +182,16 @@ func (s *TelegramPromptService) GetTranslation(key, language string) (string, er
 
 // GetAllPromptsByLanguage gets all prompts with messages for a specific language
 func (s *TelegramPromptService) GetAllPromptsByLanguage(language string) ([]model.TelegramPrompt, error) {
+	return s.GetAllPromptsByLanguageChain(LanguageChain(language, nil))
+}
+
+// GetAllPromptsByLanguageChain gets all prompts with the message of the first language in
+// the chain that has one, stored under the first language of the chain
+func (s *TelegramPromptService) GetAllPromptsByLanguageChain(chain []string) ([]model.TelegramPrompt, error) {
+	if len(chain) == 0 {
+		chain = []string{DefaultLanguage}
+	}
+
 	rows, err := s.db.Query(`
         SELECT id, prompt_key, description, messages, created_at, updated_at
         FROM telegram_prompts
@@ -163,13 +212,10 @@ func (s *TelegramPromptService) GetAllPromptsByLanguage(language string) ([]mode
 			return nil, err
 		}
 
-		// Create a simplified prompt with only the requested language message
+		// Create a simplified prompt with only the resolved message
 		prompt.Messages = make(map[string]string)
-		if msg, exists := messages[language]; exists && msg != "" {
-			prompt.Messages[language] = msg
-		} else if msg, exists := messages["en"]; exists && msg != "" {
-			// Fallback to English
-			prompt.Messages[language] = msg
+		if msg, ok := resolveMessage(messages, chain); ok {
+			prompt.Messages[chain[0]] = msg
 		}
 
 		prompts = append(prompts, prompt)
@@ -178,6 +224,30 @@ func (s *TelegramPromptService) GetAllPromptsByLanguage(language string) ([]mode
 	return prompts, nil
 }
 
+// ValidateLanguageChain checks that at least one language of the chain translates every
+// alert-critical prompt
+func (s *TelegramPromptService) ValidateLanguageChain(chain []string) error {
+	var rows []MessagesMap
+	err := s.db.Select(&rows, "SELECT messages FROM telegram_prompts WHERE prompt_key = ANY($1)", pq.Array(AlertCriticalPromptKeys))
+	if err != nil {
+		return fmt.Errorf("failed to get alert prompts: %w", err)
+	}
+
+	for _, lang := range chain {
+		complete := len(rows) == len(AlertCriticalPromptKeys)
+		for _, messages := range rows {
+			if messages[lang] == "" {
+				complete = false
+				break
+			}
+		}
+		if complete {
+			return nil
+		}
+	}
+	return ErrIncompleteLanguageChain
+}
+
 // CreatePrompt creates a new prompt
 func (s *TelegramPromptService) CreatePrompt(req model.TelegramPromptRequest) (*model.TelegramPrompt, error) {
 	messages := req.ToMessages()
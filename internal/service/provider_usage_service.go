@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"domain-detection-go/pkg/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// providerUsageFlushInterval is how often counted provider calls are written out
+const providerUsageFlushInterval = time.Minute
+
+// providerUsageKey identifies a row of provider_api_usage
+type providerUsageKey struct {
+	provider   string
+	day        string
+	method     string
+	endpoint   string
+	statusCode int
+}
+
+// providerUsageCount is the calls counted for a key since the last flush
+type providerUsageCount struct {
+	calls     int
+	latencyMs int64
+}
+
+// ProviderUsageService counts the requests sent to provider APIs and reports daily usage
+// with a projection of the month against each provider's quota. Calls are counted in
+// memory and written out once a minute, so checks never wait for the database.
+type ProviderUsageService struct {
+	db     *sqlx.DB
+	quotas map[string]int // Monthly request quota per provider, missing or 0 for none
+
+	mu      sync.Mutex
+	pending map[providerUsageKey]*providerUsageCount
+}
+
+// NewProviderUsageService creates a new provider usage service
+func NewProviderUsageService(db *sqlx.DB, quotas map[string]int) *ProviderUsageService {
+	return &ProviderUsageService{
+		db:      db,
+		quotas:  quotas,
+		pending: make(map[providerUsageKey]*providerUsageCount),
+	}
+}
+
+// RecordCall implements outbound.Recorder
+func (s *ProviderUsageService) RecordCall(call model.ProviderCall) {
+	key := providerUsageKey{
+		provider:   call.Provider,
+		day:        call.CalledAt.UTC().Format("2006-01-02"),
+		method:     call.Method,
+		endpoint:   call.Endpoint,
+		statusCode: call.StatusCode,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count, ok := s.pending[key]
+	if !ok {
+		count = &providerUsageCount{}
+		s.pending[key] = count
+	}
+	count.calls++
+	count.latencyMs += call.Latency.Milliseconds()
+}
+
+// Flush writes the calls counted since the last flush. Counts that fail to be written
+// are kept for the next flush.
+func (s *ProviderUsageService) Flush() error {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[providerUsageKey]*providerUsageCount)
+	s.mu.Unlock()
+
+	var firstErr error
+	for key, count := range pending {
+		_, err := s.db.Exec(`
+            INSERT INTO provider_api_usage (provider, day, method, endpoint, status_code, calls, total_latency_ms)
+            VALUES ($1, $2, $3, $4, $5, $6, $7)
+            ON CONFLICT (provider, day, method, endpoint, status_code)
+            DO UPDATE SET calls = provider_api_usage.calls + $6,
+                          total_latency_ms = provider_api_usage.total_latency_ms + $7
+        `, key.provider, key.day, key.method, key.endpoint, key.statusCode, count.calls, count.latencyMs)
+		if err != nil {
+			s.mu.Lock()
+			if current, ok := s.pending[key]; ok {
+				current.calls += count.calls
+				current.latencyMs += count.latencyMs
+			} else {
+				s.pending[key] = count
+			}
+			s.mu.Unlock()
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to record provider usage: %w", err)
+			}
+		}
+	}
+	return firstErr
+}
+
+// RunScheduledUsageFlush writes out the counted provider calls once a minute, and a last
+// time when ctx is cancelled
+func (s *ProviderUsageService) RunScheduledUsageFlush(ctx context.Context) {
+	slog.Info("RunScheduledUsageFlush")
+	ticker := time.NewTicker(providerUsageFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := s.Flush(); err != nil {
+				slog.Error("Final provider usage flush failed", "error", err)
+			}
+			slog.Info("RunScheduledUsageFlush stopped")
+			return
+		case <-ticker.C:
+			if err := s.Flush(); err != nil {
+				slog.Error("Provider usage flush failed", "error", err)
+			}
+		}
+	}
+}
+
+// GetUsage returns the usage of every provider over the last days, including today, and
+// the projected usage of the current month
+func (s *ProviderUsageService) GetUsage(days int) ([]model.ProviderUsage, error) {
+	// Include the calls not written out yet
+	if err := s.Flush(); err != nil {
+		slog.Error("Provider usage flush before report failed", "error", err)
+	}
+
+	now := time.Now().UTC()
+	since := now.AddDate(0, 0, -(days - 1)).Format("2006-01-02")
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	var providers []string
+	err := s.db.Select(&providers, `
+        SELECT DISTINCT provider FROM provider_api_usage
+        WHERE day >= LEAST($1::date, $2::date)
+        ORDER BY provider
+    `, since, monthStart.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get providers: %w", err)
+	}
+	for provider := range s.quotas {
+		if !containsString(providers, provider) {
+			providers = append(providers, provider)
+		}
+	}
+
+	usage := make([]model.ProviderUsage, 0, len(providers))
+	for _, provider := range providers {
+		u := model.ProviderUsage{
+			Provider:     provider,
+			MonthlyQuota: s.quotas[provider],
+			Days:         []model.ProviderUsageDay{},
+			Endpoints:    []model.ProviderEndpointUsage{},
+		}
+
+		err := s.db.Select(&u.Days, `
+            SELECT day::timestamptz AS day, SUM(calls) AS calls,
+                   COALESCE(SUM(calls) FILTER (WHERE status_code = 0 OR status_code >= $3), 0) AS failures,
+                   COALESCE(SUM(calls) FILTER (WHERE status_code = $4), 0) AS throttled,
+                   (SUM(total_latency_ms) / GREATEST(SUM(calls), 1))::int AS avg_latency_ms
+            FROM provider_api_usage
+            WHERE provider = $1 AND day >= $2
+            GROUP BY day
+            ORDER BY day
+        `, provider, since, http.StatusInternalServerError, http.StatusTooManyRequests)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get daily provider usage: %w", err)
+		}
+
+		err = s.db.Select(&u.Endpoints, `
+            SELECT method, endpoint, SUM(calls) AS calls,
+                   COALESCE(SUM(calls) FILTER (WHERE status_code = 0 OR status_code >= $3), 0) AS failures,
+                   (SUM(total_latency_ms) / GREATEST(SUM(calls), 1))::int AS avg_latency_ms
+            FROM provider_api_usage
+            WHERE provider = $1 AND day >= $2
+            GROUP BY method, endpoint
+            ORDER BY calls DESC
+        `, provider, since, http.StatusInternalServerError)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get provider usage per endpoint: %w", err)
+		}
+
+		err = s.db.Get(&u.MonthToDateCalls, `
+            SELECT COALESCE(SUM(calls), 0) FROM provider_api_usage
+            WHERE provider = $1 AND day >= $2
+        `, provider, monthStart.Format("2006-01-02"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get monthly provider usage: %w", err)
+		}
+
+		// Extrapolate the month so far, by the fraction of the month that has passed
+		elapsed := now.Sub(monthStart)
+		month := monthStart.AddDate(0, 1, 0).Sub(monthStart)
+		if elapsed > 0 {
+			u.ProjectedMonthCalls = int(float64(u.MonthToDateCalls) * float64(month) / float64(elapsed))
+		}
+		if u.MonthlyQuota > 0 {
+			used := float64(u.ProjectedMonthCalls) * 100 / float64(u.MonthlyQuota)
+			u.ProjectedQuotaUsed = &used
+		}
+
+		usage = append(usage, u)
+	}
+	return usage, nil
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"domain-detection-go/pkg/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Patterns used to strip personal data from retained records
+const (
+	ipv4Pattern  = `\m\d{1,3}(\.\d{1,3}){3}\M`
+	emailPattern = `[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`
+)
+
+// retentionTarget describes a table that is pruned according to a retention policy
+type retentionTarget struct {
+	name       string
+	table      string
+	timeColumn string
+	viaDomain  bool // true if rows are owned through domain_id, false if they have user_id directly
+	days       func(p model.RetentionPolicy) int
+}
+
+// retentionTargets lists every table the pruner manages. Tables that do not
+// exist yet are skipped, so targets can be registered ahead of their migrations.
+var retentionTargets = []retentionTarget{
+	{
+		name:       "notification_history",
+		table:      "notification_history",
+		timeColumn: "notified_at",
+		viaDomain:  true,
+		days:       func(p model.RetentionPolicy) int { return p.NotificationHistoryDays },
+	},
+	{
+		name:       "check_history",
+		table:      "domain_check_history",
+		timeColumn: "checked_at",
+		viaDomain:  true,
+		days:       func(p model.RetentionPolicy) int { return p.CheckHistoryDays },
+	},
+	{
+		name:       "skipped_checks",
+		table:      "domain_skipped_checks",
+		timeColumn: "skipped_at",
+		viaDomain:  true,
+		days:       func(p model.RetentionPolicy) int { return p.CheckHistoryDays },
+	},
+	{
+		name:       "tls_probes",
+		table:      "domain_tls_probes",
+		timeColumn: "probed_at",
+		viaDomain:  true,
+		days:       func(p model.RetentionPolicy) int { return p.CheckHistoryDays },
+	},
+	{
+		name:       "archive_checks",
+		table:      "domain_archive_checks",
+		timeColumn: "checked_at",
+		viaDomain:  true,
+		days:       func(p model.RetentionPolicy) int { return p.CheckHistoryDays },
+	},
+	{
+		name:       "audit_logs",
+		table:      "audit_logs",
+		timeColumn: "created_at",
+		viaDomain:  false,
+		days:       func(p model.RetentionPolicy) int { return p.AuditLogDays },
+	},
+}
+
+// RetentionService prunes and anonymizes historical data per plan
+type RetentionService struct {
+	db *sqlx.DB
+}
+
+// NewRetentionService creates a new retention service
+func NewRetentionService(db *sqlx.DB) *RetentionService {
+	return &RetentionService{db: db}
+}
+
+// GetPolicies returns all retention policies
+func (s *RetentionService) GetPolicies() ([]model.RetentionPolicy, error) {
+	var policies []model.RetentionPolicy
+	err := s.db.Select(&policies, `
+        SELECT plan, check_history_days, notification_history_days, audit_log_days, personal_data_days, updated_at
+        FROM retention_policies
+        ORDER BY plan
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get retention policies: %w", err)
+	}
+	return policies, nil
+}
+
+// UpsertPolicy creates or updates the retention policy for a plan
+func (s *RetentionService) UpsertPolicy(plan string, req model.RetentionPolicyRequest) error {
+	if plan == "" {
+		return errors.New("plan is required")
+	}
+
+	_, err := s.db.Exec(`
+        INSERT INTO retention_policies (plan, check_history_days, notification_history_days, audit_log_days, personal_data_days, updated_at)
+        VALUES ($1, $2, $3, $4, $5, NOW())
+        ON CONFLICT (plan)
+        DO UPDATE SET check_history_days = $2, notification_history_days = $3,
+                      audit_log_days = $4, personal_data_days = $5, updated_at = NOW()
+    `, plan, req.CheckHistoryDays, req.NotificationHistoryDays, req.AuditLogDays, req.PersonalDataDays)
+	if err != nil {
+		return fmt.Errorf("failed to save retention policy: %w", err)
+	}
+	return nil
+}
+
+// GetReport returns the most recent pruner runs with totals
+func (s *RetentionService) GetReport(limit int) (*model.RetentionReport, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+
+	report := &model.RetentionReport{Runs: []model.RetentionRun{}}
+	err := s.db.Select(&report.Runs, `
+        SELECT id, plan, target, action, rows_affected, cutoff, error, ran_at
+        FROM retention_runs
+        ORDER BY ran_at DESC
+        LIMIT $1
+    `, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get retention runs: %w", err)
+	}
+
+	for _, run := range report.Runs {
+		switch run.Action {
+		case "purge":
+			report.TotalPurged += run.RowsAffected
+		case "anonymize":
+			report.TotalAnonymized += run.RowsAffected
+		}
+	}
+
+	return report, nil
+}
+
+// RunRetention applies every retention policy once
+func (s *RetentionService) RunRetention() error {
+	policies, err := s.GetPolicies()
+	if err != nil {
+		return err
+	}
+
+	for _, policy := range policies {
+		for _, target := range retentionTargets {
+			exists, err := s.tableExists(target.table)
+			if err != nil {
+				slog.Error("Failed to check retention table", "table", target.table, "error", err)
+				continue
+			}
+			if !exists {
+				continue
+			}
+
+			cutoff := time.Now().AddDate(0, 0, -target.days(policy))
+			rows, err := s.purgeTarget(target, policy.Plan, cutoff)
+			s.recordRun(policy.Plan, target.name, "purge", rows, cutoff, err)
+		}
+
+		cutoff := time.Now().AddDate(0, 0, -policy.PersonalDataDays)
+		rows, err := s.anonymizeNotificationHistory(policy.Plan, cutoff)
+		s.recordRun(policy.Plan, "notification_history", "anonymize", rows, cutoff, err)
+
+		rows, err = s.anonymizeDeepCheckOrders(policy.Plan, cutoff)
+		s.recordRun(policy.Plan, "deep_check_orders", "anonymize", rows, cutoff, err)
+	}
+
+	return nil
+}
+
+// RunScheduledRetention runs the pruner at startup and then once a day, so that
+// restarts more often than daily still prune
+func (s *RetentionService) RunScheduledRetention(ctx context.Context) {
+	slog.Info("RunScheduledRetention")
+	run := func() {
+		if err := s.RunRetention(); err != nil {
+			slog.Error("Retention run failed", "error", err)
+		}
+	}
+	run()
+
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("RunScheduledRetention stopped")
+			return
+		case <-ticker.C:
+			run()
+		}
+	}
+}
+
+// tableExists checks whether a table is present in the current schema
+func (s *RetentionService) tableExists(table string) (bool, error) {
+	var exists bool
+	err := s.db.Get(&exists, "SELECT to_regclass($1) IS NOT NULL", table)
+	return exists, err
+}
+
+// planFilter returns a SQL fragment matching users on the given plan ($2)
+func planFilter(userColumn string) string {
+	return fmt.Sprintf("COALESCE((SELECT us.plan FROM user_settings us WHERE us.user_id = %s), '%s') = $2",
+		userColumn, model.DEFAULT_PLAN)
+}
+
+// purgeTarget deletes rows older than cutoff for users on the plan
+func (s *RetentionService) purgeTarget(target retentionTarget, plan string, cutoff time.Time) (int, error) {
+	var query string
+	if target.viaDomain {
+		query = fmt.Sprintf(`
+            DELETE FROM %s t
+            USING domains d
+            WHERE t.domain_id = d.id AND t.%s < $1 AND %s
+        `, target.table, target.timeColumn, planFilter("d.user_id"))
+	} else {
+		query = fmt.Sprintf(`
+            DELETE FROM %s t
+            WHERE t.%s < $1 AND %s
+        `, target.table, target.timeColumn, planFilter("t.user_id"))
+	}
+
+	result, err := s.db.Exec(query, cutoff, plan)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge %s: %w", target.name, err)
+	}
+	rows, _ := result.RowsAffected()
+	return int(rows), nil
+}
+
+// anonymizeNotificationHistory strips IPs and emails from old error descriptions
+func (s *RetentionService) anonymizeNotificationHistory(plan string, cutoff time.Time) (int, error) {
+	result, err := s.db.Exec(fmt.Sprintf(`
+        UPDATE notification_history nh
+        SET error_description = regexp_replace(
+                regexp_replace(nh.error_description, '%s', '[redacted-ip]', 'g'),
+                '%s', '[redacted]', 'g')
+        FROM domains d
+        WHERE nh.domain_id = d.id
+          AND nh.notified_at < $1
+          AND (nh.error_description ~ '%s' OR nh.error_description ~ '%s')
+          AND %s
+    `, ipv4Pattern, emailPattern, ipv4Pattern, emailPattern, planFilter("d.user_id")), cutoff, plan)
+	if err != nil {
+		return 0, fmt.Errorf("failed to anonymize notification history: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	return int(rows), nil
+}
+
+// anonymizeDeepCheckOrders strips resolved IPs and emails from old callback payloads
+func (s *RetentionService) anonymizeDeepCheckOrders(plan string, cutoff time.Time) (int, error) {
+	result, err := s.db.Exec(fmt.Sprintf(`
+        UPDATE deep_check_orders o
+        SET callback_data = regexp_replace(
+                regexp_replace(o.callback_data::text, '%s', '[redacted-ip]', 'g'),
+                '%s', '[redacted]', 'g')::jsonb,
+            anonymized_at = NOW()
+        WHERE o.anonymized_at IS NULL
+          AND o.callback_data IS NOT NULL
+          AND o.created_at < $1
+          AND %s
+    `, ipv4Pattern, emailPattern, planFilter("o.user_id")), cutoff, plan)
+	if err != nil {
+		return 0, fmt.Errorf("failed to anonymize deep check orders: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	return int(rows), nil
+}
+
+// recordRun stores the outcome of a purge or anonymization pass
+func (s *RetentionService) recordRun(plan, target, action string, rows int, cutoff time.Time, runErr error) {
+	var errText sql.NullString
+	if runErr != nil {
+		slog.Error("Retention step failed", "action", action, "target", target, "plan", plan, "error", runErr)
+		errText = sql.NullString{String: runErr.Error(), Valid: true}
+	} else if rows > 0 {
+		slog.Info("Retention step applied", "action", action, "target", target, "plan", plan, "rows", rows, "cutoff", cutoff.Format(time.RFC3339))
+	}
+
+	_, err := s.db.Exec(`
+        INSERT INTO retention_runs (plan, target, action, rows_affected, cutoff, error, ran_at)
+        VALUES ($1, $2, $3, $4, $5, $6, NOW())
+    `, plan, target, action, rows, cutoff, errText)
+	if err != nil {
+		slog.Error("Failed to record retention run", "error", err)
+	}
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"domain-detection-go/internal/testdb"
+	"domain-detection-go/pkg/model"
+)
+
+func TestAnonymizeNotificationHistoryIsIdempotent(t *testing.T) {
+	db := testdb.Open(t)
+	s := NewRetentionService(db)
+
+	var historyID int
+	err := db.Get(&historyID, `
+        WITH u AS (
+            INSERT INTO users (username, password_hash, email) VALUES ('retention', 'hash', 'retention@example.com')
+            RETURNING id
+        ), d AS (
+            INSERT INTO domains (user_id, name, region) SELECT id, 'example.com', 'VN' FROM u
+            RETURNING id, user_id
+        ), tc AS (
+            INSERT INTO telegram_configs (user_id, chat_id) SELECT id, '1' FROM u
+            RETURNING id
+        )
+        INSERT INTO notification_history (domain_id, telegram_config_id, status_code, error_description, notified_at, notification_type)
+        SELECT d.id, tc.id, 0, 'timeout from 10.1.2.3 reported by ops@example.com', NOW() - INTERVAL '1 year', 'down'
+        FROM d, tc
+        RETURNING id
+    `)
+	if err != nil {
+		t.Fatalf("insert history: %v", err)
+	}
+
+	if rows, err := s.anonymizeNotificationHistory(model.DEFAULT_PLAN, time.Now()); err != nil || rows != 1 {
+		t.Fatalf("first run = %d, %v, want 1, nil", rows, err)
+	}
+	if rows, err := s.anonymizeNotificationHistory(model.DEFAULT_PLAN, time.Now()); err != nil || rows != 0 {
+		t.Fatalf("second run = %d, %v, want 0, nil", rows, err)
+	}
+
+	var description string
+	if err := db.Get(&description, "SELECT error_description FROM notification_history WHERE id = $1", historyID); err != nil {
+		t.Fatalf("get history: %v", err)
+	}
+	if want := "timeout from [redacted-ip] reported by [redacted]"; description != want {
+		t.Errorf("error_description = %q, want %q", description, want)
+	}
+}
@@ -0,0 +1,11 @@
+package trial
+
+import "errors"
+
+// Errors returned by the trial service for requests that cannot be applied. Handlers
+// match them with errors.Is to choose the response.
+var (
+	ErrUserNotFound       = errors.New("user not found")
+	ErrPlanNotFound       = errors.New("plan not found")
+	ErrConvertToTrialPlan = errors.New("cannot convert a trial to the trial plan")
+)
@@ -0,0 +1,285 @@
+package trial
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"domain-detection-go/internal/domain"
+	"domain-detection-go/internal/notification"
+	"domain-detection-go/pkg/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// TrialService expires trial accounts, suspending their monitoring, and warns users beforehand
+type TrialService struct {
+	db            *sqlx.DB
+	domainService *domain.DomainService
+	notifiers     *notification.Fanout
+}
+
+// NewTrialService creates a new trial service
+func NewTrialService(db *sqlx.DB, domainService *domain.DomainService,
+	notifiers *notification.Fanout) *TrialService {
+	return &TrialService{
+		db:            db,
+		domainService: domainService,
+		notifiers:     notifiers,
+	}
+}
+
+// GetStatus returns the trial status of a user
+func (s *TrialService) GetStatus(userID int) (*model.TrialStatus, error) {
+	var status model.TrialStatus
+	err := s.db.Get(&status, `
+        SELECT u.id AS user_id, u.username, u.trial_expires_at, u.suspended_at,
+               COALESCE(us.plan, $2) AS plan
+        FROM users u
+        LEFT JOIN user_settings us ON us.user_id = u.id
+        WHERE u.id = $1
+    `, userID, model.DEFAULT_PLAN)
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trial status: %w", err)
+	}
+
+	if status.TrialExpiresAt != nil {
+		remaining := time.Until(*status.TrialExpiresAt)
+		status.Expired = remaining <= 0
+		if !status.Expired {
+			status.DaysRemaining = int(math.Ceil(remaining.Hours() / 24))
+		}
+	}
+	return &status, nil
+}
+
+// IsTrialExpired reports whether the user has a trial that has already expired
+func (s *TrialService) IsTrialExpired(userID int) (bool, error) {
+	var expired bool
+	err := s.db.Get(&expired, `
+        SELECT trial_expires_at IS NOT NULL AND trial_expires_at <= NOW()
+        FROM users WHERE id = $1
+    `, userID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return expired, err
+}
+
+// SetTrialExpiry starts or extends a user's trial. Monitoring is resumed if the
+// new expiry is in the future and the account was suspended by an earlier expiry.
+func (s *TrialService) SetTrialExpiry(userID int, expiresAt time.Time) error {
+	result, err := s.db.Exec("UPDATE users SET trial_expires_at = $1, updated_at = NOW() WHERE id = $2", expiresAt, userID)
+	if err != nil {
+		return fmt.Errorf("failed to set trial expiry: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrUserNotFound
+	}
+
+	if err := s.setPlan(userID, model.TRIAL_PLAN); err != nil {
+		return err
+	}
+
+	// Warnings are re-sent for the new expiry date
+	if _, err := s.db.Exec("DELETE FROM trial_warnings WHERE user_id = $1", userID); err != nil {
+		return fmt.Errorf("failed to reset trial warnings: %w", err)
+	}
+
+	if expiresAt.After(time.Now()) {
+		return s.domainService.SetUserMonitoringSuspended(userID, false)
+	}
+	return nil
+}
+
+// ConvertTrial ends a user's trial by moving them to a paid plan and resuming monitoring
+func (s *TrialService) ConvertTrial(userID int, plan string) error {
+	if plan == model.TRIAL_PLAN {
+		return ErrConvertToTrialPlan
+	}
+
+	var exists bool
+	if err := s.db.Get(&exists, "SELECT EXISTS(SELECT 1 FROM plans WHERE name = $1)", plan); err != nil {
+		return fmt.Errorf("failed to verify plan: %w", err)
+	}
+	if !exists {
+		return ErrPlanNotFound
+	}
+
+	result, err := s.db.Exec("UPDATE users SET trial_expires_at = NULL, updated_at = NOW() WHERE id = $1", userID)
+	if err != nil {
+		return fmt.Errorf("failed to end trial: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrUserNotFound
+	}
+
+	if err := s.setPlan(userID, plan); err != nil {
+		return err
+	}
+	if _, err := s.db.Exec("DELETE FROM trial_warnings WHERE user_id = $1", userID); err != nil {
+		slog.Error("Failed to clear trial warnings", "user_id", userID, "error", err)
+	}
+
+	return s.domainService.SetUserMonitoringSuspended(userID, false)
+}
+
+// setPlan assigns a plan to a user, creating their settings row if needed
+func (s *TrialService) setPlan(userID int, plan string) error {
+	_, err := s.db.Exec(`
+        INSERT INTO user_settings (user_id, plan, updated_at)
+        VALUES ($1, $2, NOW())
+        ON CONFLICT (user_id)
+        DO UPDATE SET plan = $2, updated_at = NOW()
+    `, userID, plan)
+	if err != nil {
+		return fmt.Errorf("failed to set plan: %w", err)
+	}
+	return nil
+}
+
+// RunTrialChecks sends due expiry warnings and suspends expired trials
+func (s *TrialService) RunTrialChecks() error {
+	if err := s.sendWarnings(); err != nil {
+		return err
+	}
+	return s.suspendExpired()
+}
+
+// RunScheduledTrialChecks checks trials once an hour
+func (s *TrialService) RunScheduledTrialChecks(ctx context.Context) {
+	slog.Info("RunScheduledTrialChecks")
+	ticker := time.NewTicker(1 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("RunScheduledTrialChecks stopped")
+			return
+		case <-ticker.C:
+			if err := s.RunTrialChecks(); err != nil {
+				slog.Error("Trial check run failed", "error", err)
+			}
+		}
+	}
+}
+
+// sendWarnings notifies users whose trial expires within one of the warning thresholds.
+// Only the nearest threshold is sent; larger ones are marked as sent with it.
+func (s *TrialService) sendWarnings() error {
+	var users []model.TrialStatus
+	err := s.db.Select(&users, `
+        SELECT id AS user_id, username, trial_expires_at
+        FROM users
+        WHERE trial_expires_at IS NOT NULL AND trial_expires_at > NOW()
+          AND trial_expires_at <= NOW() + make_interval(days => $1)
+    `, maxWarningDays())
+	if err != nil {
+		return fmt.Errorf("failed to get expiring trials: %w", err)
+	}
+
+	for _, user := range users {
+		remaining := time.Until(*user.TrialExpiresAt)
+
+		var sent []int
+		if err := s.db.Select(&sent, "SELECT days_before FROM trial_warnings WHERE user_id = $1", user.UserID); err != nil {
+			slog.Error("Failed to get sent trial warnings", "user_id", user.UserID, "error", err)
+			continue
+		}
+
+		due := 0
+		for _, days := range model.TrialWarningDays {
+			if remaining <= time.Duration(days)*24*time.Hour && !containsInt(sent, days) {
+				if due == 0 || days < due {
+					due = days
+				}
+			}
+		}
+		if due == 0 {
+			continue
+		}
+
+		message := fmt.Sprintf("Your trial ends in %d day(s), on %s. Domain monitoring will be paused when it expires. Upgrade your plan to keep monitoring your domains.",
+			due, user.TrialExpiresAt.UTC().Format("2006-01-02 15:04 UTC"))
+		s.notifyUser(user.UserID, "Your trial is ending soon", message)
+
+		for _, days := range model.TrialWarningDays {
+			if days >= due && !containsInt(sent, days) {
+				_, err := s.db.Exec(`
+                    INSERT INTO trial_warnings (user_id, days_before, sent_at)
+                    VALUES ($1, $2, NOW())
+                    ON CONFLICT (user_id, days_before) DO NOTHING
+                `, user.UserID, days)
+				if err != nil {
+					slog.Error("Failed to record trial warning", "user_id", user.UserID, "error", err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// suspendExpired pauses monitoring for users whose trial has expired
+func (s *TrialService) suspendExpired() error {
+	var userIDs []int
+	err := s.db.Select(&userIDs, `
+        SELECT id FROM users
+        WHERE trial_expires_at IS NOT NULL AND trial_expires_at <= NOW() AND suspended_at IS NULL
+    `)
+	if err != nil {
+		return fmt.Errorf("failed to get expired trials: %w", err)
+	}
+
+	for _, userID := range userIDs {
+		slog.Info("Trial expired, suspending monitoring", "user_id", userID)
+		if err := s.domainService.SetUserMonitoringSuspended(userID, true); err != nil {
+			slog.Error("Failed to suspend user", "user_id", userID, "error", err)
+			continue
+		}
+
+		s.notifyUser(userID, "Your trial has expired",
+			"Your trial has expired and domain monitoring has been paused. Upgrade your plan to resume monitoring.")
+	}
+
+	return nil
+}
+
+// notifyUser sends a message through every channel the user has configured
+func (s *TrialService) notifyUser(userID int, subject, message string) {
+	err := s.notifiers.SendCustom(userID, notification.StaticMessage(notification.CustomMessage{
+		Subject: subject,
+		HTML:    fmt.Sprintf("<html><body><h2>%s</h2><p>%s</p></body></html>", subject, message),
+		Text:    []string{message},
+	}))
+	if err != nil {
+		slog.Error("Trial notification not sent", "user_id", userID, "error", err)
+	}
+}
+
+// maxWarningDays returns the earliest warning threshold in days
+func maxWarningDays() int {
+	max := 0
+	for _, days := range model.TrialWarningDays {
+		if days > max {
+			max = days
+		}
+	}
+	return max
+}
+
+func containsInt(values []int, v int) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,5 @@
+ALTER TABLE deep_check_orders DROP COLUMN IF EXISTS anonymized_at;
+DROP INDEX IF EXISTS idx_retention_runs_ran_at;
+DROP TABLE IF EXISTS retention_runs;
+DROP TABLE IF EXISTS retention_policies;
+ALTER TABLE user_settings DROP COLUMN IF EXISTS plan;
@@ -0,0 +1,31 @@
+-- Plan assignment for users (used by retention and other per-plan settings)
+ALTER TABLE user_settings ADD COLUMN plan VARCHAR(50) NOT NULL DEFAULT 'default';
+
+-- Retention windows per plan
+CREATE TABLE retention_policies (
+    plan VARCHAR(50) PRIMARY KEY,
+    check_history_days INTEGER NOT NULL DEFAULT 90,
+    notification_history_days INTEGER NOT NULL DEFAULT 90,
+    audit_log_days INTEGER NOT NULL DEFAULT 365,
+    personal_data_days INTEGER NOT NULL DEFAULT 30, -- records older than this are anonymized
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+INSERT INTO retention_policies (plan) VALUES ('default');
+
+-- Report of every pruner run so admins can see what was purged
+CREATE TABLE retention_runs (
+    id SERIAL PRIMARY KEY,
+    plan VARCHAR(50) NOT NULL,
+    target VARCHAR(100) NOT NULL,
+    action VARCHAR(20) NOT NULL, -- 'purge' or 'anonymize'
+    rows_affected INTEGER NOT NULL DEFAULT 0,
+    cutoff TIMESTAMP WITH TIME ZONE NOT NULL,
+    error TEXT,
+    ran_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_retention_runs_ran_at ON retention_runs(ran_at);
+
+-- Track which deep check orders have already had personal data stripped
+ALTER TABLE deep_check_orders ADD COLUMN anonymized_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,11 @@
+-- Plan definitions with the check intervals (in minutes) each plan may use
+CREATE TABLE plans (
+    name VARCHAR(50) PRIMARY KEY,
+    allowed_intervals INTEGER[] NOT NULL DEFAULT '{10,20,30,60,120}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+INSERT INTO plans (name, allowed_intervals) VALUES
+('default', '{10,20,30,60,120}'),
+('premium', '{5,10,20,30,60,120}');
@@ -0,0 +1,33 @@
+CREATE TABLE status_page_configs (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(20) NOT NULL CHECK (provider IN ('statuspage', 'instatus')),
+    name VARCHAR(255),
+    page_id VARCHAR(255) NOT NULL,
+    api_key TEXT NOT NULL,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE status_page_components (
+    status_page_config_id INTEGER NOT NULL REFERENCES status_page_configs(id) ON DELETE CASCADE,
+    domain_id INTEGER NOT NULL REFERENCES domains(id) ON DELETE CASCADE,
+    component_id VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY(status_page_config_id, domain_id)
+);
+
+CREATE TABLE status_page_incidents (
+    id SERIAL PRIMARY KEY,
+    status_page_config_id INTEGER NOT NULL REFERENCES status_page_configs(id) ON DELETE CASCADE,
+    domain_id INTEGER NOT NULL REFERENCES domains(id) ON DELETE CASCADE,
+    external_id VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    opened_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    resolved_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_status_page_configs_user_id ON status_page_configs(user_id);
+CREATE INDEX idx_status_page_components_domain_id ON status_page_components(domain_id);
+CREATE INDEX idx_status_page_incidents_open ON status_page_incidents(status_page_config_id, domain_id) WHERE status = 'open';
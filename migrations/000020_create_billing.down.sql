@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS domain_check_usage;
+DROP TABLE IF EXISTS billing_unit_prices;
@@ -0,0 +1,22 @@
+CREATE TABLE billing_unit_prices (
+    plan VARCHAR(50) PRIMARY KEY,
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+    domain_price NUMERIC(12, 6) NOT NULL DEFAULT 0,       -- per monitored domain per month
+    check_price NUMERIC(12, 6) NOT NULL DEFAULT 0,        -- per check performed
+    deep_check_price NUMERIC(12, 6) NOT NULL DEFAULT 0,   -- per deep check order
+    notification_price NUMERIC(12, 6) NOT NULL DEFAULT 0, -- per notification sent
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+INSERT INTO billing_unit_prices (plan) VALUES ('default');
+
+-- Monthly check counters per domain, incremented on every scheduled check
+CREATE TABLE domain_check_usage (
+    domain_id INTEGER NOT NULL REFERENCES domains(id) ON DELETE CASCADE,
+    period_start DATE NOT NULL, -- first day of the month
+    checks INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY(domain_id, period_start)
+);
+
+CREATE INDEX idx_domain_check_usage_period ON domain_check_usage(period_start);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS trial_warnings;
+DELETE FROM retention_policies WHERE plan = 'trial';
+DELETE FROM plans WHERE name = 'trial';
+DROP INDEX IF EXISTS idx_users_trial_expires_at;
+ALTER TABLE users DROP COLUMN IF EXISTS suspended_at;
+ALTER TABLE users DROP COLUMN IF EXISTS trial_expires_at;
@@ -0,0 +1,17 @@
+-- Trial accounts: monitoring is suspended once the trial expires
+ALTER TABLE users ADD COLUMN trial_expires_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE users ADD COLUMN suspended_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_users_trial_expires_at ON users(trial_expires_at) WHERE trial_expires_at IS NOT NULL;
+
+-- Trial users get their own plan so limits, retention and prices can differ
+INSERT INTO plans (name, allowed_intervals) VALUES ('trial', '{10,20,30,60,120}') ON CONFLICT (name) DO NOTHING;
+INSERT INTO retention_policies (plan) VALUES ('trial') ON CONFLICT (plan) DO NOTHING;
+
+-- Expiry warnings already sent, so each threshold is only notified once
+CREATE TABLE trial_warnings (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    days_before INTEGER NOT NULL,
+    sent_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY(user_id, days_before)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS domain_dns_comparisons;
+DROP TABLE IF EXISTS dns_resolvers;
@@ -0,0 +1,27 @@
+-- Public resolvers representative of each monitored region
+CREATE TABLE dns_resolvers (
+    id SERIAL PRIMARY KEY,
+    region_code VARCHAR(10) NOT NULL, -- 'GLOBAL' is the baseline every region is compared against
+    name VARCHAR(100) NOT NULL,
+    address VARCHAR(255) NOT NULL, -- host:port
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(region_code, address)
+);
+
+INSERT INTO dns_resolvers (region_code, name, address) VALUES
+('GLOBAL', 'Google Public DNS', '8.8.8.8:53'),
+('CN', '114DNS', '114.114.114.114:53'),
+('CN', 'AliDNS', '223.5.5.5:53'),
+('KR', 'KT', '168.126.63.1:53');
+
+-- Latest cross-region DNS comparison for each domain
+CREATE TABLE domain_dns_comparisons (
+    domain_id INTEGER PRIMARY KEY REFERENCES domains(id) ON DELETE CASCADE,
+    divergent BOOLEAN NOT NULL DEFAULT false,
+    reason TEXT,
+    results JSONB NOT NULL DEFAULT '[]',
+    compared_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_domain_dns_comparisons_divergent ON domain_dns_comparisons(divergent) WHERE divergent = true;
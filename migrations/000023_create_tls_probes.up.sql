@@ -0,0 +1,14 @@
+-- History of TLS/ALPN capability probes per domain
+CREATE TABLE domain_tls_probes (
+    id SERIAL PRIMARY KEY,
+    domain_id INTEGER NOT NULL REFERENCES domains(id) ON DELETE CASCADE,
+    tls_versions TEXT[] NOT NULL DEFAULT '{}', -- e.g. {TLS 1.2,TLS 1.3}
+    alpn_protocols TEXT[] NOT NULL DEFAULT '{}', -- e.g. {h2,http/1.1}
+    http3 BOOLEAN NOT NULL DEFAULT false, -- advertised through Alt-Svc
+    cipher_suite VARCHAR(100),
+    weak_cipher BOOLEAN NOT NULL DEFAULT false,
+    error TEXT,
+    probed_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_domain_tls_probes_domain_probed ON domain_tls_probes(domain_id, probed_at DESC);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS notification_digest_events;
+DROP TABLE IF EXISTS notification_digests;
@@ -0,0 +1,29 @@
+-- Digest-only delivery settings and state per notification config (any channel)
+CREATE TABLE notification_digests (
+    channel VARCHAR(20) NOT NULL, -- 'telegram', 'email'
+    config_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    interval_minutes INTEGER NOT NULL DEFAULT 60,
+    delivery_trigger VARCHAR(20) NOT NULL DEFAULT 'interval' CHECK (delivery_trigger IN ('interval', 'down_set_change')),
+    last_sent_at TIMESTAMP WITH TIME ZONE,
+    last_down_domains TEXT[] NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY(channel, config_id)
+);
+
+-- Events accumulated for digest configs until the next summary is delivered
+CREATE TABLE notification_digest_events (
+    id SERIAL PRIMARY KEY,
+    channel VARCHAR(20) NOT NULL,
+    config_id INTEGER NOT NULL,
+    domain_id INTEGER NOT NULL REFERENCES domains(id) ON DELETE CASCADE,
+    domain_name VARCHAR(255) NOT NULL,
+    region VARCHAR(10),
+    notification_type VARCHAR(50) NOT NULL,
+    status_code INTEGER,
+    error_description TEXT,
+    occurred_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_notification_digest_events_config ON notification_digest_events(channel, config_id, occurred_at);
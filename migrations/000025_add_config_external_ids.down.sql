@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_email_configs_external_id;
+DROP INDEX IF EXISTS idx_telegram_configs_external_id;
+
+ALTER TABLE email_configs DROP COLUMN IF EXISTS external_id;
+ALTER TABLE telegram_configs DROP COLUMN IF EXISTS external_id;
@@ -0,0 +1,6 @@
+-- Client-chosen identifiers so declarative tools (e.g. Terraform) can upsert configs
+ALTER TABLE telegram_configs ADD COLUMN external_id VARCHAR(255);
+ALTER TABLE email_configs ADD COLUMN external_id VARCHAR(255);
+
+CREATE UNIQUE INDEX idx_telegram_configs_external_id ON telegram_configs(user_id, external_id) WHERE external_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_email_configs_external_id ON email_configs(user_id, external_id) WHERE external_id IS NOT NULL;
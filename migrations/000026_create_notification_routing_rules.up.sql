@@ -0,0 +1,13 @@
+-- Route alerts for domains in a region to specific notification configs (any channel).
+-- When a region has rules, only the routed configs are notified for it.
+CREATE TABLE notification_routing_rules (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    region_code VARCHAR(10) NOT NULL,
+    channel VARCHAR(20) NOT NULL, -- 'telegram', 'email'
+    config_id INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(user_id, region_code, channel, config_id)
+);
+
+CREATE INDEX idx_notification_routing_rules_region ON notification_routing_rules(user_id, region_code);
@@ -0,0 +1,2 @@
+ALTER TABLE domains DROP COLUMN IF EXISTS recovery_successes;
+ALTER TABLE domains DROP COLUMN IF EXISTS recovery_pending;
@@ -0,0 +1,3 @@
+-- A down domain is only reported as recovered after several consecutive successful checks
+ALTER TABLE domains ADD COLUMN recovery_pending BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE domains ADD COLUMN recovery_successes INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS domain_archive_checks;
+
+DROP INDEX IF EXISTS idx_domains_archived;
+ALTER TABLE domains DROP COLUMN IF EXISTS archived_at;
@@ -0,0 +1,16 @@
+-- Archived domains have no provider monitors and are only probed daily by the service itself
+ALTER TABLE domains ADD COLUMN archived_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_domains_archived ON domains(archived_at) WHERE archived_at IS NOT NULL;
+
+-- Minimal history of the daily internal probes of archived domains
+CREATE TABLE domain_archive_checks (
+    id SERIAL PRIMARY KEY,
+    domain_id INTEGER NOT NULL REFERENCES domains(id) ON DELETE CASCADE,
+    status_code INTEGER NOT NULL,
+    total_time INTEGER NOT NULL,
+    error_description TEXT,
+    checked_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_domain_archive_checks_domain ON domain_archive_checks(domain_id, checked_at);
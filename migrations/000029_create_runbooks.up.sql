@@ -0,0 +1,15 @@
+-- Remediation runbooks attached to a single domain or to every domain in a region.
+-- A domain runbook takes precedence over the runbook of its region.
+CREATE TABLE runbooks (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    domain_id INTEGER REFERENCES domains(id) ON DELETE CASCADE,
+    region_code VARCHAR(10),
+    runbook_url TEXT NOT NULL DEFAULT '',
+    notes TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK ((domain_id IS NULL) <> (region_code IS NULL))
+);
+
+CREATE UNIQUE INDEX idx_runbooks_domain ON runbooks(domain_id) WHERE domain_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_runbooks_region ON runbooks(user_id, region_code) WHERE region_code IS NOT NULL;
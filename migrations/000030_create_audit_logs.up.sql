@@ -0,0 +1,12 @@
+-- Record of user and support actions with side effects outside the database.
+-- domain_id has no foreign key so entries outlive the domain they refer to.
+CREATE TABLE audit_logs (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    action VARCHAR(50) NOT NULL,
+    domain_id INTEGER,
+    details JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_logs_user ON audit_logs(user_id, created_at);
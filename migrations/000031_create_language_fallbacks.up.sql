@@ -0,0 +1,10 @@
+-- Ordered languages tried after a notification config's own language when a
+-- message is missing. Configs without a row fall back straight to English.
+CREATE TABLE notification_language_fallbacks (
+    channel VARCHAR(20) NOT NULL, -- 'telegram', 'email'
+    config_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    languages TEXT[] NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY(channel, config_id)
+);
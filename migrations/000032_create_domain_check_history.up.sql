@@ -0,0 +1,13 @@
+-- One row per completed check of a domain, used for the status timeline
+CREATE TABLE domain_check_history (
+    id BIGSERIAL PRIMARY KEY,
+    domain_id INTEGER NOT NULL REFERENCES domains(id) ON DELETE CASCADE,
+    available BOOLEAN NOT NULL,
+    status_code INTEGER,
+    error_code INTEGER,
+    total_time INTEGER,
+    error_description TEXT,
+    checked_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_domain_check_history_domain ON domain_check_history(domain_id, checked_at);
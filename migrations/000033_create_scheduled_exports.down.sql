@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS export_deliveries;
+DROP TABLE IF EXISTS export_targets;
@@ -0,0 +1,41 @@
+-- Customer SFTP destinations for the daily data export. Credentials are encrypted
+-- with the application encryption key.
+CREATE TABLE export_targets (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    host VARCHAR(255) NOT NULL,
+    port INTEGER NOT NULL DEFAULT 22,
+    username VARCHAR(255) NOT NULL,
+    password_encrypted TEXT,
+    private_key_encrypted TEXT,
+    host_key_fingerprint VARCHAR(100) NOT NULL DEFAULT '', -- Pinned on first successful delivery if empty
+    remote_dir VARCHAR(500) NOT NULL DEFAULT '.',
+    datasets TEXT[] NOT NULL, -- 'incidents', 'checks', 'notifications'
+    delivery_hour INTEGER NOT NULL DEFAULT 1 CHECK (delivery_hour BETWEEN 0 AND 23), -- UTC
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_export_targets_user ON export_targets(user_id);
+
+-- One file per target, dataset and day, retried with backoff until delivered
+CREATE TABLE export_deliveries (
+    id SERIAL PRIMARY KEY,
+    target_id INTEGER NOT NULL REFERENCES export_targets(id) ON DELETE CASCADE,
+    dataset VARCHAR(20) NOT NULL,
+    period_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    period_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    rows_exported INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(target_id, dataset, period_start)
+);
+
+CREATE INDEX idx_export_deliveries_due ON export_deliveries(status, next_attempt_at);
@@ -0,0 +1,3 @@
+ALTER TABLE domains DROP COLUMN IF EXISTS last_skipped_at;
+
+DROP TABLE IF EXISTS domain_skipped_checks;
@@ -0,0 +1,12 @@
+-- Checks skipped by the scheduler while shedding load, so SLA math can exclude them
+CREATE TABLE domain_skipped_checks (
+    id BIGSERIAL PRIMARY KEY,
+    domain_id INTEGER NOT NULL REFERENCES domains(id) ON DELETE CASCADE,
+    reason VARCHAR(50) NOT NULL,
+    backlog INTEGER NOT NULL DEFAULT 0,
+    skipped_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_domain_skipped_checks_domain ON domain_skipped_checks(domain_id, skipped_at);
+
+ALTER TABLE domains ADD COLUMN last_skipped_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,3 @@
+ALTER TABLE domains DROP COLUMN IF EXISTS direct_monitor_id;
+
+DROP TABLE IF EXISTS direct_monitors;
@@ -0,0 +1,15 @@
+-- Built-in HTTP checks run from the application servers. The monitor ID stored on the
+-- domain is the domain ID; NULL settings fall back to the server defaults.
+CREATE TABLE direct_monitors (
+    domain_id INTEGER PRIMARY KEY REFERENCES domains(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    timeout_seconds INTEGER,
+    user_agent VARCHAR(255),
+    follow_redirects BOOLEAN NOT NULL DEFAULT true,
+    max_redirects INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+ALTER TABLE domains ADD COLUMN direct_monitor_id VARCHAR(255);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS registration_warnings;
+DROP TABLE IF EXISTS domain_registrations;
@@ -0,0 +1,22 @@
+-- Registration (WHOIS/RDAP) data of the registrable domain behind each monitored domain
+CREATE TABLE domain_registrations (
+    domain_id INTEGER PRIMARY KEY REFERENCES domains(id) ON DELETE CASCADE,
+    registrable_domain VARCHAR(255) NOT NULL,
+    registrar VARCHAR(255),
+    expires_at TIMESTAMP WITH TIME ZONE,
+    source VARCHAR(10) NOT NULL DEFAULT '',
+    error TEXT,
+    checked_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_domain_registrations_expires_at ON domain_registrations(expires_at);
+
+-- Expiry warnings already sent. Keyed by expiry date so a renewal starts over.
+CREATE TABLE registration_warnings (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    registrable_domain VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    days_before INTEGER NOT NULL,
+    sent_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY(user_id, registrable_domain, expires_at, days_before)
+);
@@ -0,0 +1,2 @@
+DELETE FROM dns_resolvers WHERE region_code = 'VN' AND name IN ('Viettel', 'VNPT');
+DROP TABLE IF EXISTS domain_dns_snapshots;
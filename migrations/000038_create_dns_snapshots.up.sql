@@ -0,0 +1,20 @@
+-- Distinct DNS record sets seen for each domain. A new row is only added when the
+-- records change; otherwise last_seen_at of the latest row is moved forward.
+CREATE TABLE domain_dns_snapshots (
+    id SERIAL PRIMARY KEY,
+    domain_id INTEGER NOT NULL REFERENCES domains(id) ON DELETE CASCADE,
+    resolver VARCHAR(100) NOT NULL,
+    a_records TEXT[] NOT NULL DEFAULT '{}',
+    aaaa_records TEXT[] NOT NULL DEFAULT '{}',
+    cname VARCHAR(255),
+    ns_records TEXT[] NOT NULL DEFAULT '{}',
+    first_seen_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    last_seen_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_domain_dns_snapshots_domain ON domain_dns_snapshots(domain_id, first_seen_at DESC);
+
+-- Record snapshots of VN domains are taken from in-country resolvers
+INSERT INTO dns_resolvers (region_code, name, address) VALUES
+('VN', 'Viettel', '203.113.131.1:53'),
+('VN', 'VNPT', '203.162.4.191:53');
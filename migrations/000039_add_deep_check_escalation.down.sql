@@ -0,0 +1,4 @@
+ALTER TABLE deep_check_orders DROP COLUMN IF EXISTS consecutive_failures;
+ALTER TABLE deep_check_orders DROP COLUMN IF EXISTS source;
+ALTER TABLE user_settings DROP COLUMN IF EXISTS deep_check_escalation_failures;
+ALTER TABLE domains DROP COLUMN IF EXISTS consecutive_failures;
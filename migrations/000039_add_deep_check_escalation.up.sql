@@ -0,0 +1,10 @@
+-- Consecutive failed checks of the current outage, reset by a successful check
+ALTER TABLE domains ADD COLUMN consecutive_failures INTEGER NOT NULL DEFAULT 0;
+
+-- Failed checks after which a deep check is ordered automatically. NULL uses the
+-- server default, 0 disables escalation.
+ALTER TABLE user_settings ADD COLUMN deep_check_escalation_failures INTEGER;
+
+-- Why an order was placed: "automatic" (CN down rule), "manual" or "escalation"
+ALTER TABLE deep_check_orders ADD COLUMN source VARCHAR(20) NOT NULL DEFAULT 'automatic';
+ALTER TABLE deep_check_orders ADD COLUMN consecutive_failures INTEGER;
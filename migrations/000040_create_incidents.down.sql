@@ -0,0 +1,2 @@
+ALTER TABLE notification_history DROP COLUMN IF EXISTS incident_id;
+DROP TABLE IF EXISTS incidents;
@@ -0,0 +1,22 @@
+-- An incident spans an outage of a domain: it opens with the first failed check,
+-- collects the following failures and is resolved by the recovery
+CREATE TABLE incidents (
+    id SERIAL PRIMARY KEY,
+    domain_id INTEGER NOT NULL REFERENCES domains(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    domain_name VARCHAR(255) NOT NULL,
+    opened_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    duration_seconds INTEGER,
+    failure_count INTEGER NOT NULL DEFAULT 1,
+    first_status INTEGER NOT NULL DEFAULT 0,
+    last_status INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    last_failure_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- At most one open incident per domain
+CREATE UNIQUE INDEX idx_incidents_open_domain ON incidents(domain_id) WHERE resolved_at IS NULL;
+CREATE INDEX idx_incidents_user_opened ON incidents(user_id, opened_at DESC);
+
+ALTER TABLE notification_history ADD COLUMN incident_id INTEGER REFERENCES incidents(id) ON DELETE SET NULL;
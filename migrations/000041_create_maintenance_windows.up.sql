@@ -0,0 +1,17 @@
+-- Planned maintenance during which status alerts are not sent. A window without a
+-- domain covers every domain of the user. Recurring windows repeat from starts_at
+-- with the same length until deleted.
+CREATE TABLE maintenance_windows (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    domain_id INTEGER REFERENCES domains(id) ON DELETE CASCADE,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    recurrence VARCHAR(10) NOT NULL DEFAULT 'none',
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX idx_maintenance_windows_user ON maintenance_windows(user_id);
+CREATE INDEX idx_maintenance_windows_domain ON maintenance_windows(domain_id);
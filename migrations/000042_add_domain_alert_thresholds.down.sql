@@ -0,0 +1,3 @@
+ALTER TABLE domains DROP COLUMN IF EXISTS failure_pending;
+ALTER TABLE domains DROP COLUMN IF EXISTS recovery_threshold;
+ALTER TABLE domains DROP COLUMN IF EXISTS failure_threshold;
@@ -0,0 +1,9 @@
+-- Consecutive failed checks before a domain counts as down and is alerted on
+ALTER TABLE domains ADD COLUMN failure_threshold INTEGER NOT NULL DEFAULT 1;
+
+-- Consecutive successful checks before a down domain counts as recovered. NULL uses
+-- the server default (RECOVERY_CONFIRMATIONS).
+ALTER TABLE domains ADD COLUMN recovery_threshold INTEGER;
+
+-- Down but not yet confirmed by failure_threshold failed checks
+ALTER TABLE domains ADD COLUMN failure_pending BOOLEAN NOT NULL DEFAULT false;
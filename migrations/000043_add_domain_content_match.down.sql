@@ -0,0 +1,2 @@
+ALTER TABLE domains DROP COLUMN IF EXISTS content_match_pattern;
+ALTER TABLE domains DROP COLUMN IF EXISTS content_match_type;
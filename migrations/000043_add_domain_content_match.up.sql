@@ -0,0 +1,4 @@
+-- Expected homepage content: "present" or "absent" keyword, or "regex". Empty disables
+-- content matching.
+ALTER TABLE domains ADD COLUMN content_match_type VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE domains ADD COLUMN content_match_pattern TEXT NOT NULL DEFAULT '';
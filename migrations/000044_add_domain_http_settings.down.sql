@@ -0,0 +1,6 @@
+ALTER TABLE domains DROP COLUMN IF EXISTS follow_redirects;
+ALTER TABLE domains DROP COLUMN IF EXISTS expected_status_codes;
+ALTER TABLE domains DROP COLUMN IF EXISTS basic_auth_password;
+ALTER TABLE domains DROP COLUMN IF EXISTS basic_auth_username;
+ALTER TABLE domains DROP COLUMN IF EXISTS http_headers;
+ALTER TABLE domains DROP COLUMN IF EXISTS http_method;
@@ -0,0 +1,7 @@
+-- HTTP settings passed to the provider monitors of a domain
+ALTER TABLE domains ADD COLUMN http_method VARCHAR(10) NOT NULL DEFAULT 'GET';
+ALTER TABLE domains ADD COLUMN http_headers JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE domains ADD COLUMN basic_auth_username VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE domains ADD COLUMN basic_auth_password TEXT NOT NULL DEFAULT ''; -- Encrypted with ENCRYPTION_KEY
+ALTER TABLE domains ADD COLUMN expected_status_codes JSONB NOT NULL DEFAULT '[]'; -- Empty accepts any 2xx/3xx status
+ALTER TABLE domains ADD COLUMN follow_redirects BOOLEAN NOT NULL DEFAULT true;
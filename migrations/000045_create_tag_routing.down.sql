@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS email_config_tags;
+DROP TABLE IF EXISTS telegram_config_tags;
+DROP TABLE IF EXISTS domain_tags;
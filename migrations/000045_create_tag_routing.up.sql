@@ -0,0 +1,25 @@
+-- Free-form labels on domains, e.g. a brand or team
+CREATE TABLE domain_tags (
+    domain_id INTEGER NOT NULL REFERENCES domains(id) ON DELETE CASCADE,
+    tag VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY(domain_id, tag)
+);
+
+CREATE INDEX idx_domain_tags_tag ON domain_tags(tag);
+
+-- Tags a notification config subscribes to. A config without tags gets alerts for
+-- every domain.
+CREATE TABLE telegram_config_tags (
+    telegram_config_id INTEGER NOT NULL REFERENCES telegram_configs(id) ON DELETE CASCADE,
+    tag VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY(telegram_config_id, tag)
+);
+
+CREATE TABLE email_config_tags (
+    email_config_id INTEGER NOT NULL REFERENCES email_configs(id) ON DELETE CASCADE,
+    tag VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY(email_config_id, tag)
+);
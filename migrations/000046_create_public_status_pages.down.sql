@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public_status_page_domains;
+DROP TABLE IF EXISTS public_status_pages;
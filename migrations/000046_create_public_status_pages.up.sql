@@ -0,0 +1,17 @@
+-- Hosted, unauthenticated status page, one per user
+CREATE TABLE public_status_pages (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    slug VARCHAR(64) NOT NULL UNIQUE,
+    title VARCHAR(100) NOT NULL DEFAULT '',
+    is_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Domains listed on the page, in display order
+CREATE TABLE public_status_page_domains (
+    user_id INTEGER NOT NULL REFERENCES public_status_pages(user_id) ON DELETE CASCADE,
+    domain_id INTEGER NOT NULL REFERENCES domains(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY(user_id, domain_id)
+);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS two_factor_recovery_codes;
@@ -0,0 +1,21 @@
+-- Escalation levels: when an incident stays open for after_minutes, alert an extra config
+CREATE TABLE escalation_rules (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    level INTEGER NOT NULL CHECK (level BETWEEN 1 AND 5),
+    after_minutes INTEGER NOT NULL CHECK (after_minutes > 0),
+    channel VARCHAR(20) NOT NULL, -- 'telegram' or 'email'
+    config_id INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(user_id, level, channel, config_id)
+);
+
+CREATE INDEX idx_escalation_rules_user_id ON escalation_rules(user_id);
+
+-- Escalations already sent, so each rule fires once per incident
+CREATE TABLE incident_escalations (
+    incident_id INTEGER NOT NULL REFERENCES incidents(id) ON DELETE CASCADE,
+    rule_id INTEGER NOT NULL REFERENCES escalation_rules(id) ON DELETE CASCADE,
+    notified_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY(incident_id, rule_id)
+);
@@ -0,0 +1,15 @@
+-- Per-config quiet hours. Alerts held during quiet hours are queued with the digest
+-- events and delivered as a summary once the quiet period ends.
+CREATE TABLE notification_quiet_hours (
+    channel VARCHAR(20) NOT NULL, -- 'telegram' or 'email'
+    config_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'Asia/Hong_Kong',
+    start_time VARCHAR(5) NOT NULL, -- 'HH:MM' in timezone
+    end_time VARCHAR(5) NOT NULL,
+    days INTEGER[] NOT NULL DEFAULT '{}', -- Weekdays the quiet period starts on, 0 = Sunday; empty means every day
+    allow_critical BOOLEAN NOT NULL DEFAULT true, -- Down alerts still go out
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY(channel, config_id)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE email_configs DROP COLUMN IF EXISTS timezone;
+ALTER TABLE telegram_configs DROP COLUMN IF EXISTS timezone;
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
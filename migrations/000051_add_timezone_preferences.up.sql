@@ -0,0 +1,5 @@
+-- Timezone used for timestamps in notifications. NULL falls back to the user's timezone,
+-- then to Asia/Hong_Kong.
+ALTER TABLE users ADD COLUMN timezone VARCHAR(64);
+ALTER TABLE telegram_configs ADD COLUMN timezone VARCHAR(64);
+ALTER TABLE email_configs ADD COLUMN timezone VARCHAR(64);
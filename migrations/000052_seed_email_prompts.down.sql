@@ -0,0 +1 @@
+DELETE FROM telegram_prompts WHERE prompt_key LIKE 'email.%';
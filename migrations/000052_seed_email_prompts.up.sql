@@ -0,0 +1,18 @@
+-- Email texts are rendered from telegram_prompts like Telegram messages. English is
+-- seeded here; other languages are added through the prompts API.
+INSERT INTO telegram_prompts (prompt_key, description, messages) VALUES
+    ('email.subject.down', 'Email subject of a down alert', '{"en": "🔴 Domain name {domain} is currently unreachable"}'),
+    ('email.subject.up', 'Email subject of a recovery', '{"en": "🟢 Domain name {domain} is back to normal"}'),
+    ('email.subject.status', 'Email subject of a status update', '{"en": "📊 Domain name {domain} status update"}'),
+    ('email.title.down', 'Email heading of a down alert', '{"en": "🔴 Domain name alert"}'),
+    ('email.title.up', 'Email heading of a recovery', '{"en": "🟢 Domain name back to normal"}'),
+    ('email.title.status', 'Email heading of a status update', '{"en": "📊 Domain name status update"}'),
+    ('email.headline.down', 'Email summary line of a down alert', '{"en": "Domain name {domain} is currently unreachable"}'),
+    ('email.headline.up', 'Email summary line of a recovery', '{"en": "Domain name {domain} is back to normal!"}'),
+    ('email.headline.status', 'Email summary line of a status update', '{"en": "Domain name {domain} status update"}'),
+    ('email.label.status_code', 'Email label for the HTTP status code', '{"en": "Status Code:"}'),
+    ('email.label.error', 'Email label for the error description', '{"en": "Error:"}'),
+    ('email.label.response_time', 'Email label for the response time', '{"en": "Response Time:"}'),
+    ('email.label.last_check', 'Email label for the time of the last check', '{"en": "Last Check:"}'),
+    ('email.footer', 'Email footer', '{"en": "This is an automated message from your Domain Monitoring Service."}')
+ON CONFLICT (prompt_key) DO NOTHING;
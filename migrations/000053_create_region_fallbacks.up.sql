@@ -0,0 +1,16 @@
+-- Extra probe regions added to the monitors of domains in a region, in order
+CREATE TABLE region_fallbacks (
+    region_code VARCHAR(10) NOT NULL REFERENCES regions(code) ON DELETE CASCADE,
+    fallback_code VARCHAR(10) NOT NULL REFERENCES regions(code) ON DELETE CASCADE,
+    position INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY(region_code, fallback_code),
+    CHECK (region_code <> fallback_code)
+);
+
+-- The fallbacks that used to be hard-coded
+INSERT INTO region_fallbacks (region_code, fallback_code, position) VALUES
+    ('TH', 'VN', 0),
+    ('ID', 'VN', 0),
+    ('KR', 'VN', 0),
+    ('VN', 'TH', 0)
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS domain_monitor_providers;
+DROP TABLE IF EXISTS user_monitor_providers;
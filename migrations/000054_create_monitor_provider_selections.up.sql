@@ -0,0 +1,14 @@
+-- Monitoring providers a user's domains are checked by. Without a row every configured
+-- provider is used.
+CREATE TABLE user_monitor_providers (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    providers TEXT[] NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Per-domain override of the user's provider selection
+CREATE TABLE domain_monitor_providers (
+    domain_id INTEGER PRIMARY KEY REFERENCES domains(id) ON DELETE CASCADE,
+    providers TEXT[] NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
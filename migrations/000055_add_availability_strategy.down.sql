@@ -0,0 +1,2 @@
+ALTER TABLE domain_check_history DROP COLUMN IF EXISTS dissenting_providers;
+ALTER TABLE domains DROP COLUMN IF EXISTS availability_strategy;
@@ -0,0 +1,6 @@
+-- How the results of several monitoring providers combine into the availability of a domain
+ALTER TABLE domains ADD COLUMN availability_strategy VARCHAR(10) NOT NULL DEFAULT 'all'
+    CHECK (availability_strategy IN ('any', 'all', 'majority'));
+
+-- Providers whose result disagreed with the combined decision of a check
+ALTER TABLE domain_check_history ADD COLUMN dissenting_providers TEXT[] NOT NULL DEFAULT '{}';
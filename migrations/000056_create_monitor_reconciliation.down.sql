@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS monitor_drift;
+DROP TABLE IF EXISTS monitor_reconciliation_runs;
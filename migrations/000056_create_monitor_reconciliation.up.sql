@@ -0,0 +1,33 @@
+-- Every pass of the monitor reconciliation job
+CREATE TABLE monitor_reconciliation_runs (
+    id SERIAL PRIMARY KEY,
+    dry_run BOOLEAN NOT NULL DEFAULT false,
+    monitors_listed INTEGER NOT NULL DEFAULT 0,
+    orphaned INTEGER NOT NULL DEFAULT 0,
+    missing INTEGER NOT NULL DEFAULT 0,
+    stale INTEGER NOT NULL DEFAULT 0,
+    fixed INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_monitor_reconciliation_runs_started_at ON monitor_reconciliation_runs(started_at);
+
+-- Drift found by a run: 'orphaned' monitors no domain references, domains 'missing' a
+-- monitor on a selected provider, and 'stale' monitor IDs the provider no longer has
+CREATE TABLE monitor_drift (
+    id SERIAL PRIMARY KEY,
+    run_id INTEGER NOT NULL REFERENCES monitor_reconciliation_runs(id) ON DELETE CASCADE,
+    provider VARCHAR(20) NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    monitor_id VARCHAR(255),
+    monitor_name TEXT,
+    domain_id INTEGER,
+    action VARCHAR(20) NOT NULL, -- 'deleted', 'recreated', 'none' (dry run) or 'failed'
+    error TEXT,
+    detected_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_monitor_drift_run_id ON monitor_drift(run_id);
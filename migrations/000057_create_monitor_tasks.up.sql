@@ -0,0 +1,18 @@
+-- Provider monitor creations and deletions that failed and are retried with backoff
+CREATE TABLE monitor_tasks (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    domain_id INTEGER NOT NULL, -- Kept after the domain is deleted so its monitors are still removed
+    provider VARCHAR(20) NOT NULL,
+    action VARCHAR(10) NOT NULL CHECK (action IN ('create', 'delete')),
+    monitor_id VARCHAR(255), -- Monitor to delete
+    status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_monitor_tasks_due ON monitor_tasks(status, next_attempt_at);
+CREATE INDEX idx_monitor_tasks_domain_id ON monitor_tasks(domain_id);
@@ -0,0 +1,14 @@
+-- Latest result of each provider checking a domain from each of its regions, so a domain
+-- with fallback regions can be up in one region and down in another
+CREATE TABLE domain_region_status (
+    domain_id INTEGER NOT NULL REFERENCES domains(id) ON DELETE CASCADE,
+    provider VARCHAR(20) NOT NULL,
+    region VARCHAR(10) NOT NULL,
+    available BOOLEAN NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    error_code INTEGER NOT NULL DEFAULT 0,
+    error_description TEXT NOT NULL DEFAULT '',
+    total_time INTEGER NOT NULL DEFAULT 0,
+    checked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(domain_id, provider, region)
+);
@@ -0,0 +1,3 @@
+-- Regions a domain is monitored in besides its primary region. The domain counts once
+-- against the user's limit however many regions it has.
+ALTER TABLE domains ADD COLUMN extra_regions TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,5 @@
+-- Ownership verification of domains. A domain with a token but no verified_at is not
+-- monitored until the token is found in its DNS or on its well-known file; domains added
+-- while verification was not required have no token.
+ALTER TABLE domains ADD COLUMN verification_token TEXT;
+ALTER TABLE domains ADD COLUMN verified_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_domains_deleted;
+ALTER TABLE domains DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,5 @@
+-- Deleted domains stay in the trash for 30 days before they are purged, so they can be
+-- restored. Trashed domains are hidden everywhere except the trash listing.
+ALTER TABLE domains ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_domains_deleted ON domains(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE deep_check_orders DROP COLUMN IF EXISTS reply_chat_id;
@@ -0,0 +1,2 @@
+-- Deep checks ordered from the Telegram bot report back to the chat they were ordered from
+ALTER TABLE deep_check_orders ADD COLUMN reply_chat_id VARCHAR(255);
@@ -0,0 +1,11 @@
+-- Short-lived codes that let a Telegram chat register itself for an account with /link
+CREATE TABLE telegram_link_codes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_telegram_link_codes_user_id ON telegram_link_codes(user_id);
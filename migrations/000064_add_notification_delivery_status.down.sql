@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_notification_history_notified_at;
+ALTER TABLE notification_history DROP COLUMN IF EXISTS last_attempt_at;
+ALTER TABLE notification_history DROP COLUMN IF EXISTS attempts;
+ALTER TABLE notification_history DROP COLUMN IF EXISTS message_id;
+ALTER TABLE notification_history DROP COLUMN IF EXISTS delivery_error;
+ALTER TABLE notification_history DROP COLUMN IF EXISTS delivery_status;
@@ -0,0 +1,8 @@
+-- Record whether each notification was delivered, so failed sends can be resent
+ALTER TABLE notification_history ADD COLUMN delivery_status VARCHAR(20) NOT NULL DEFAULT 'sent';
+ALTER TABLE notification_history ADD COLUMN delivery_error TEXT;
+ALTER TABLE notification_history ADD COLUMN message_id VARCHAR(255);
+ALTER TABLE notification_history ADD COLUMN attempts INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE notification_history ADD COLUMN last_attempt_at TIMESTAMP;
+
+CREATE INDEX idx_notification_history_notified_at ON notification_history(notified_at);
@@ -0,0 +1,9 @@
+-- Last notification per channel, domain and type, shared by every API instance so
+-- restarts and replicas do not repeat notifications
+CREATE TABLE notification_suppression (
+    key VARCHAR(255) PRIMARY KEY,
+    last_sent_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_notification_suppression_expires_at ON notification_suppression(expires_at);
@@ -0,0 +1,12 @@
+-- Recurring summary reports per email config. period_end is the end of the last period
+-- reported, so each weekly or monthly period is delivered once.
+CREATE TABLE email_report_schedules (
+    config_id INTEGER PRIMARY KEY REFERENCES email_configs(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    frequency VARCHAR(10) NOT NULL DEFAULT 'weekly' CHECK (frequency IN ('weekly', 'monthly')),
+    regions TEXT[] NOT NULL DEFAULT '{}', -- Empty means all regions
+    last_sent_at TIMESTAMP WITH TIME ZONE,
+    last_period_end TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
@@ -0,0 +1,14 @@
+-- Requests sent to provider APIs, counted per day, endpoint and response status.
+-- status_code 0 is a connection error.
+CREATE TABLE provider_api_usage (
+    provider VARCHAR(20) NOT NULL,
+    day DATE NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    endpoint VARCHAR(255) NOT NULL,
+    status_code INTEGER NOT NULL,
+    calls INTEGER NOT NULL DEFAULT 0,
+    total_latency_ms BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (provider, day, method, endpoint, status_code)
+);
+
+CREATE INDEX idx_provider_api_usage_day ON provider_api_usage(day);
@@ -0,0 +1,3 @@
+ALTER TABLE incidents DROP COLUMN IF EXISTS acknowledged_until;
+ALTER TABLE incidents DROP COLUMN IF EXISTS acknowledged_by;
+ALTER TABLE incidents DROP COLUMN IF EXISTS acknowledged_at;
@@ -0,0 +1,5 @@
+-- An acknowledged incident sends no further down alerts or escalations until it
+-- recovers or the acknowledgement expires
+ALTER TABLE incidents ADD COLUMN acknowledged_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE incidents ADD COLUMN acknowledged_by VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE incidents ADD COLUMN acknowledged_until TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,2 @@
+-- A snoozed domain keeps being checked and recorded but sends no alerts until then
+ALTER TABLE domains ADD COLUMN snoozed_until TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,8 @@
+ALTER TABLE plans ADD COLUMN allowed_intervals INTEGER[] NOT NULL DEFAULT '{10,20,30,60,120}';
+
+UPDATE plans SET allowed_intervals = '{5,10,20,30,60,120}' WHERE min_interval <= 5;
+
+ALTER TABLE plans DROP COLUMN min_interval;
+
+-- Intervals outside the old lists fall back to the default
+UPDATE domains SET interval = 20 WHERE interval NOT IN (5, 10, 20, 30, 60, 120);
@@ -0,0 +1,7 @@
+-- Plans bound the check interval from below instead of listing the allowed values;
+-- any interval from the plan's minimum up to 1440 minutes is accepted
+ALTER TABLE plans ADD COLUMN min_interval INTEGER NOT NULL DEFAULT 10;
+
+UPDATE plans SET min_interval = GREATEST(5, COALESCE((SELECT MIN(v) FROM unnest(allowed_intervals) AS v), 10));
+
+ALTER TABLE plans DROP COLUMN allowed_intervals;
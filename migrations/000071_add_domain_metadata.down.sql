@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_domains_labels;
+ALTER TABLE domains DROP COLUMN IF EXISTS labels;
+ALTER TABLE domains DROP COLUMN IF EXISTS owner_contact;
+ALTER TABLE domains DROP COLUMN IF EXISTS notes;
@@ -0,0 +1,7 @@
+-- Free-form metadata ops keep on a domain, e.g. which merchant it belongs to and whom
+-- to escalate to. Labels are key/value pairs the domain list can be filtered by.
+ALTER TABLE domains ADD COLUMN notes TEXT NOT NULL DEFAULT '';
+ALTER TABLE domains ADD COLUMN owner_contact VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE domains ADD COLUMN labels JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_domains_labels ON domains USING GIN (labels);
@@ -0,0 +1,3 @@
+-- Internationalized domains are stored and monitored in their ASCII (punycode) form;
+-- unicode_name keeps the form users entered for display, empty for ASCII domains
+ALTER TABLE domains ADD COLUMN unicode_name VARCHAR(255) NOT NULL DEFAULT '';
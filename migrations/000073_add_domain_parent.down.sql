@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_domains_parent_id;
+ALTER TABLE domains DROP COLUMN IF EXISTS parent_id;
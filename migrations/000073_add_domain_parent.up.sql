@@ -0,0 +1,5 @@
+-- Subdomains discovered for a base domain are monitored as their own domains and
+-- grouped under it
+ALTER TABLE domains ADD COLUMN parent_id INTEGER REFERENCES domains(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_domains_parent_id ON domains(parent_id) WHERE parent_id IS NOT NULL;
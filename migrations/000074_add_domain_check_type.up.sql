@@ -0,0 +1,5 @@
+-- Domains that are not websites, such as SMTP relays or game servers, are checked with a
+-- TCP connect to their port or a ping, by the built-in checker only
+ALTER TABLE domains ADD COLUMN check_type VARCHAR(10) NOT NULL DEFAULT 'https'
+    CHECK (check_type IN ('http', 'https', 'tcp', 'icmp'));
+ALTER TABLE domains ADD COLUMN port INTEGER CHECK (port BETWEEN 1 AND 65535);
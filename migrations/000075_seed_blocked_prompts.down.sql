@@ -0,0 +1,6 @@
+DELETE FROM telegram_prompts WHERE prompt_key IN (
+    'telegram.message.domain_blocked',
+    'email.subject.blocked',
+    'email.title.blocked',
+    'email.headline.blocked'
+);
@@ -0,0 +1,7 @@
+-- Texts of the alert sent when a domain is likely blocked by an ISP
+INSERT INTO telegram_prompts (prompt_key, description, messages) VALUES
+    ('telegram.message.domain_blocked', 'Telegram text of a likely ISP-blocked alert', '{"en": "is likely blocked by an ISP"}'),
+    ('email.subject.blocked', 'Email subject of a likely ISP-blocked alert', '{"en": "🚫 Domain name {domain} is likely blocked by an ISP"}'),
+    ('email.title.blocked', 'Email heading of a likely ISP-blocked alert', '{"en": "🚫 Domain name blocking alert"}'),
+    ('email.headline.blocked', 'Email summary line of a likely ISP-blocked alert', '{"en": "Domain name {domain} is serving an ISP block page or being reset"}')
+ON CONFLICT (prompt_key) DO NOTHING;
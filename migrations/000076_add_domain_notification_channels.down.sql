@@ -0,0 +1 @@
+ALTER TABLE domains DROP COLUMN IF EXISTS notification_channels;
@@ -0,0 +1,2 @@
+-- Channels a domain alerts through. NULL alerts through every channel.
+ALTER TABLE domains ADD COLUMN notification_channels TEXT[];
@@ -0,0 +1,3 @@
+ALTER TABLE email_configs DROP COLUMN IF EXISTS verification_expires_at;
+ALTER TABLE email_configs DROP COLUMN IF EXISTS verification_code_hash;
+ALTER TABLE email_configs DROP COLUMN IF EXISTS verified_at;
@@ -0,0 +1,8 @@
+-- Email configs stay pending, and are never notified, until the address confirms the
+-- code mailed to it
+ALTER TABLE email_configs ADD COLUMN verified_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE email_configs ADD COLUMN verification_code_hash VARCHAR(64);
+ALTER TABLE email_configs ADD COLUMN verification_expires_at TIMESTAMP WITH TIME ZONE;
+
+-- Configs added before verification keep receiving alerts
+UPDATE email_configs SET verified_at = NOW();
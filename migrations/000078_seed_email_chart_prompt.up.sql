@@ -0,0 +1,3 @@
+INSERT INTO telegram_prompts (prompt_key, description, messages) VALUES
+    ('email.label.chart', 'Email label above the chart of the last day', '{"en": "Last 24 hours:"}')
+ON CONFLICT (prompt_key) DO NOTHING;
@@ -0,0 +1,18 @@
+-- Accounts deleted by their owner, removed step by step in the background. user_id has
+-- no foreign key so the record of the deletion outlives the account.
+CREATE TABLE account_deletions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    username VARCHAR(255) NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+    step VARCHAR(20) NOT NULL DEFAULT 'monitors',
+    domains_total INTEGER NOT NULL DEFAULT 0,
+    domains_done INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    requested_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_account_deletions_user_id ON account_deletions(user_id);
+CREATE UNIQUE INDEX idx_account_deletions_active ON account_deletions(user_id) WHERE status IN ('pending', 'running');
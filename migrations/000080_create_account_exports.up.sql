@@ -0,0 +1,16 @@
+-- Archives of all data of a user, built in the background and downloaded through a
+-- link mailed to the user. The archive is dropped once the link expires.
+CREATE TABLE account_exports (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'ready', 'failed', 'expired')),
+    token_hash VARCHAR(64) UNIQUE,
+    archive BYTEA,
+    size_bytes INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    requested_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_account_exports_user_id ON account_exports(user_id);
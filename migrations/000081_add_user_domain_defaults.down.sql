@@ -0,0 +1,5 @@
+ALTER TABLE user_settings DROP COLUMN IF EXISTS default_recovery_threshold;
+ALTER TABLE user_settings DROP COLUMN IF EXISTS default_failure_threshold;
+ALTER TABLE user_settings DROP COLUMN IF EXISTS default_notification_channels;
+ALTER TABLE user_settings DROP COLUMN IF EXISTS default_region;
+ALTER TABLE user_settings DROP COLUMN IF EXISTS default_interval;
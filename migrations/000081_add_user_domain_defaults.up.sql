@@ -0,0 +1,7 @@
+-- Defaults applied to domains a user adds without explicit values. NULL falls back to
+-- the server default; for notification_channels that is every channel.
+ALTER TABLE user_settings ADD COLUMN default_interval INTEGER;
+ALTER TABLE user_settings ADD COLUMN default_region VARCHAR(50);
+ALTER TABLE user_settings ADD COLUMN default_notification_channels TEXT[];
+ALTER TABLE user_settings ADD COLUMN default_failure_threshold INTEGER;
+ALTER TABLE user_settings ADD COLUMN default_recovery_threshold INTEGER;
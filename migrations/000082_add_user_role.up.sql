@@ -0,0 +1,4 @@
+-- Admins reach the /api/admin routes. Grant the role by hand:
+-- UPDATE users SET role = 'admin' WHERE username = '...';
+ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user'
+    CHECK (role IN ('user', 'admin'));
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_domains_user_name_region_unique;
+CREATE UNIQUE INDEX idx_domains_user_name_region_unique ON domains (user_id, LOWER(name), region);
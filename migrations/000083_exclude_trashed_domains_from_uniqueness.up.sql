@@ -0,0 +1,5 @@
+-- Trashed domains no longer block adding the same domain again. Restoring one checks
+-- for an active duplicate first.
+DROP INDEX IF EXISTS idx_domains_user_name_region_unique;
+CREATE UNIQUE INDEX idx_domains_user_name_region_unique ON domains (user_id, LOWER(name), region)
+    WHERE deleted_at IS NULL;
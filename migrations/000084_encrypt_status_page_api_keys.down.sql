@@ -0,0 +1 @@
+ALTER TABLE status_page_configs DROP COLUMN IF EXISTS api_key_encrypted;
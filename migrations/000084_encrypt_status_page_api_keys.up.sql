@@ -0,0 +1,3 @@
+-- Status page API keys are sealed with ENCRYPTION_KEY. Keys stored before are sealed by
+-- the API on startup; until then they are used as stored.
+ALTER TABLE status_page_configs ADD COLUMN api_key_encrypted BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,8 @@
+DELETE FROM dns_resolvers WHERE (region_code, address) IN (
+    ('SG', '165.21.83.88:53'),
+    ('SG', '165.21.100.88:53'),
+    ('TH', '203.144.207.29:53'),
+    ('TH', '203.144.207.49:53'),
+    ('ID', '203.130.193.74:53'),
+    ('ID', '180.131.144.144:53')
+);
@@ -0,0 +1,10 @@
+-- In-country resolvers for the remaining monitored regions, so their answers are
+-- compared against what local users actually see
+INSERT INTO dns_resolvers (region_code, name, address) VALUES
+('SG', 'SingNet', '165.21.83.88:53'),
+('SG', 'SingNet Secondary', '165.21.100.88:53'),
+('TH', 'True Internet', '203.144.207.29:53'),
+('TH', 'True Internet Secondary', '203.144.207.49:53'),
+('ID', 'Telkom Indonesia', '203.130.193.74:53'),
+('ID', 'Nawala', '180.131.144.144:53')
+ON CONFLICT (region_code, address) DO NOTHING;
@@ -0,0 +1,2 @@
+-- Users whose account is being deleted can no longer log in or make changes
+ALTER TABLE users ADD COLUMN deletion_requested_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_status_page_incidents_open;
+CREATE INDEX idx_status_page_incidents_open ON status_page_incidents(status_page_config_id, domain_id) WHERE status = 'open';
@@ -0,0 +1,12 @@
+-- A domain has at most one open incident per status page, so concurrent status
+-- changes can't open it twice. Older duplicates are resolved first.
+UPDATE status_page_incidents i SET status = 'resolved', resolved_at = NOW()
+WHERE i.status = 'open' AND i.id < (
+    SELECT MAX(o.id) FROM status_page_incidents o
+    WHERE o.status = 'open'
+      AND o.status_page_config_id = i.status_page_config_id
+      AND o.domain_id = i.domain_id
+);
+
+DROP INDEX IF EXISTS idx_status_page_incidents_open;
+CREATE UNIQUE INDEX idx_status_page_incidents_open ON status_page_incidents(status_page_config_id, domain_id) WHERE status = 'open';
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_account_exports_pending;
@@ -0,0 +1,9 @@
+-- A user has at most one account export being built, so concurrent requests can't
+-- start two. Older duplicates are failed first.
+UPDATE account_exports e SET status = 'failed', last_error = 'superseded', completed_at = NOW()
+WHERE e.status = 'pending' AND e.id < (
+    SELECT MAX(p.id) FROM account_exports p
+    WHERE p.status = 'pending' AND p.user_id = e.user_id
+);
+
+CREATE UNIQUE INDEX idx_account_exports_pending ON account_exports(user_id) WHERE status = 'pending';
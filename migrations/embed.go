@@ -0,0 +1,9 @@
+// Package migrations embeds the SQL schema migrations so the binary can apply them
+package migrations
+
+import "embed"
+
+// FS holds the numbered up and down migrations
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,140 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+
+	"domain-detection-go/pkg/model"
+)
+
+// LoginUser is the user summary returned on login
+type LoginUser struct {
+	ID         int    `json:"id"`
+	Username   string `json:"username"`
+	Email      string `json:"email"`
+	Require2FA bool   `json:"require_2fa"`
+}
+
+// LoginResponse is returned by a successful login
+type LoginResponse struct {
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresIn    int       `json:"expires_in"` // Access token lifetime in seconds
+	User         LoginUser `json:"user"`
+}
+
+// Profile is the authenticated user's profile
+type Profile struct {
+	Username         string `json:"username"`
+	Email            string `json:"email"`
+	TwoFactorEnabled bool   `json:"twoFactorEnabled"`
+	Timezone         string `json:"timezone"`
+}
+
+// Login authenticates and stores the returned token on the client. totpCode may be
+// empty for accounts without two-factor authentication.
+func (c *Client) Login(username, password, totpCode string) (*LoginResponse, error) {
+	var resp LoginResponse
+	err := c.do(http.MethodPost, "/login", model.UserCredentials{
+		Username: username,
+		Password: password,
+		TOTPCode: totpCode,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	c.SetToken(resp.Token)
+	return &resp, nil
+}
+
+// RefreshToken exchanges a refresh token for a new token pair and stores the new
+// access token on the client. The old refresh token stops working.
+func (c *Client) RefreshToken(refreshToken string) (*model.TokenPair, error) {
+	var resp model.TokenPair
+	if err := c.do(http.MethodPost, "/token/refresh", model.TokenRefreshRequest{RefreshToken: refreshToken}, &resp); err != nil {
+		return nil, err
+	}
+
+	c.SetToken(resp.Token)
+	return &resp, nil
+}
+
+// Logout revokes the session of the client's token
+func (c *Client) Logout() error {
+	return c.do(http.MethodPost, "/logout", nil, nil)
+}
+
+// GetSessions lists the user's active login sessions
+func (c *Client) GetSessions() ([]model.Session, error) {
+	var resp struct {
+		Sessions []model.Session `json:"sessions"`
+	}
+	if err := c.do(http.MethodGet, "/sessions", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Sessions, nil
+}
+
+// RevokeSession ends one of the user's login sessions
+func (c *Client) RevokeSession(id int) error {
+	return c.do(http.MethodDelete, fmt.Sprintf("/sessions/%d", id), nil, nil)
+}
+
+// Register creates a new account
+func (c *Client) Register(req model.RegistrationRequest) (*model.RegistrationResponse, error) {
+	var resp model.RegistrationResponse
+	if err := c.do(http.MethodPost, "/register", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetProfile returns the authenticated user's profile
+func (c *Client) GetProfile() (*Profile, error) {
+	var resp Profile
+	if err := c.do(http.MethodGet, "/user/profile", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UpdateTimezone sets the timezone notification timestamps are shown in. An empty
+// timezone restores the default.
+func (c *Client) UpdateTimezone(timezone string) error {
+	return c.do(http.MethodPut, "/user/timezone", model.TimezoneRequest{Timezone: timezone}, nil)
+}
+
+// GetTrialStatus returns the user's trial status
+func (c *Client) GetTrialStatus() (*model.TrialStatus, error) {
+	var resp model.TrialStatus
+	if err := c.do(http.MethodGet, "/user/trial", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CreateAPIKey creates an API key. The returned Key is shown only once.
+func (c *Client) CreateAPIKey(name string) (*model.APIKeyCreateResponse, error) {
+	var resp model.APIKeyCreateResponse
+	if err := c.do(http.MethodPost, "/apikeys", model.APIKeyCreateRequest{Name: name}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetAPIKeys lists the user's API keys
+func (c *Client) GetAPIKeys() ([]model.APIKey, error) {
+	var resp struct {
+		APIKeys []model.APIKey `json:"api_keys"`
+	}
+	if err := c.do(http.MethodGet, "/apikeys", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.APIKeys, nil
+}
+
+// DeleteAPIKey revokes an API key
+func (c *Client) DeleteAPIKey(id int) error {
+	return c.do(http.MethodDelete, fmt.Sprintf("/apikeys/%d", id), nil, nil)
+}
@@ -0,0 +1,166 @@
+// Package client is a Go SDK for the domain detection API. Request and response
+// bodies use the same pkg/model types as the server.
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultTimeout is used when no HTTP client is supplied
+const defaultTimeout = 30 * time.Second
+
+// Client calls the API on behalf of one user
+type Client struct {
+	baseURL    string
+	token      string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithToken sets the JWT sent with every request
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithAPIKey sets an API key sent instead of a JWT. Keys are only accepted by the
+// domain endpoints.
+func WithAPIKey(key string) Option {
+	return func(c *Client) { c.apiKey = key }
+}
+
+// WithHTTPClient replaces the default HTTP client
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// New creates a client for the API at baseURL, e.g. "https://example.com"
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: defaultTimeout},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// SetToken replaces the JWT, e.g. after Login
+func (c *Client) SetToken(token string) {
+	c.token = token
+}
+
+// Token returns the JWT currently in use
+func (c *Client) Token() string {
+	return c.token
+}
+
+// APIError is returned when the API answers with a non-2xx status
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api error (%d): %s", e.StatusCode, e.Message)
+}
+
+// IsPreconditionFailed reports whether err is a 412 answer to a conditional request,
+// i.e. the resource changed since its ETag was read
+func IsPreconditionFailed(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.StatusCode == http.StatusPreconditionFailed
+}
+
+// conditional returns the If-Match header for ifMatch, which may be empty
+func conditional(ifMatch string) http.Header {
+	if ifMatch == "" {
+		return nil
+	}
+	return http.Header{"If-Match": []string{ifMatch}}
+}
+
+// messageResponse is the body returned by most mutating endpoints
+type messageResponse struct {
+	Message string `json:"message"`
+	ID      int    `json:"id"`
+}
+
+// do sends a JSON request to path (relative to /api) and decodes the response into out
+func (c *Client) do(method, path string, body, out interface{}) error {
+	_, err := c.doWithHeaders(method, path, nil, body, out)
+	return err
+}
+
+// doWithHeaders is do with extra request headers, returning the response headers
+func (c *Client) doWithHeaders(method, path string, header http.Header, body, out interface{}) (http.Header, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+"/api"+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	} else if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.Header, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+		var errBody struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &errBody) == nil && errBody.Error != "" {
+			apiErr.Message = errBody.Error
+		} else if out != nil {
+			// Batch endpoints report per-item failures in the regular response body
+			json.Unmarshal(data, out)
+		}
+		return resp.Header, apiErr
+	}
+
+	if out == nil || len(data) == 0 {
+		return resp.Header, nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return resp.Header, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return resp.Header, nil
+}
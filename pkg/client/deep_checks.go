@@ -0,0 +1,67 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"domain-detection-go/pkg/model"
+)
+
+// ListDeepChecks returns the user's most recent deep check orders. A domainID of 0
+// lists orders for every domain.
+func (c *Client) ListDeepChecks(domainID, limit int) ([]model.DeepCheckOrder, error) {
+	var resp struct {
+		Orders []model.DeepCheckOrder `json:"orders"`
+	}
+	path := fmt.Sprintf("/deep-checks?limit=%d", limit)
+	if domainID > 0 {
+		path += fmt.Sprintf("&domain_id=%d", domainID)
+	}
+	if err := c.do(http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Orders, nil
+}
+
+// RequestDeepCheck orders a deep check of a domain and returns the order ID to poll
+// with GetDeepCheck
+func (c *Client) RequestDeepCheck(domainID int) (string, error) {
+	var resp struct {
+		OrderID string `json:"order_id"`
+	}
+	if err := c.do(http.MethodPost, fmt.Sprintf("/domains/%d/deepcheck", domainID), nil, &resp); err != nil {
+		return "", err
+	}
+	return resp.OrderID, nil
+}
+
+// GetDeepCheck returns a deep check order with its results once they have arrived
+func (c *Client) GetDeepCheck(orderID string) (*model.DeepCheckOrder, error) {
+	var order model.DeepCheckOrder
+	if err := c.do(http.MethodGet, "/deepchecks/"+url.PathEscape(orderID), nil, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetDeepCheckEscalation returns after how many consecutive failed checks a deep check
+// is ordered automatically
+func (c *Client) GetDeepCheckEscalation() (*model.DeepCheckEscalationSettings, error) {
+	var settings model.DeepCheckEscalationSettings
+	if err := c.do(http.MethodGet, "/user/deep-check-escalation", nil, &settings); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// UpdateDeepCheckEscalation sets the escalation threshold. 0 disables escalation and
+// nil restores the server default.
+func (c *Client) UpdateDeepCheckEscalation(failures *int) (*model.DeepCheckEscalationSettings, error) {
+	var settings model.DeepCheckEscalationSettings
+	req := model.DeepCheckEscalationRequest{Failures: failures}
+	if err := c.do(http.MethodPut, "/user/deep-check-escalation", req, &settings); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
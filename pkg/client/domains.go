@@ -0,0 +1,274 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"domain-detection-go/pkg/model"
+)
+
+// ListDomains returns the user's domains together with the plan limit
+func (c *Client) ListDomains() (*model.DomainListResponse, error) {
+	var resp model.DomainListResponse
+	if err := c.do(http.MethodGet, "/domains", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetDomain returns a single domain
+func (c *Client) GetDomain(id int) (*model.Domain, error) {
+	var resp model.Domain
+	if err := c.do(http.MethodGet, fmt.Sprintf("/domains/%d", id), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetSummary returns the dashboard summary of the user's domains
+func (c *Client) GetSummary() (*model.DomainSummary, error) {
+	var resp model.DomainSummary
+	if err := c.do(http.MethodGet, "/summary", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AddDomain adds a domain and returns its ID
+func (c *Client) AddDomain(req model.DomainAddRequest) (int, error) {
+	var resp messageResponse
+	if err := c.do(http.MethodPost, "/domains", req, &resp); err != nil {
+		return 0, err
+	}
+	return resp.ID, nil
+}
+
+// AddDomains adds several domains at once. When some or all domains fail, the
+// per-domain results are still returned alongside the error.
+func (c *Client) AddDomains(req model.DomainBatchAddRequest) (*model.DomainBatchAddResponse, error) {
+	var resp model.DomainBatchAddResponse
+	err := c.do(http.MethodPost, "/domains/batch", req, &resp)
+	return &resp, err
+}
+
+// UpsertDomain creates or updates the domain identified by req.Name and req.Region and
+// returns it with its ETag. A non-empty ifMatch makes the call fail with a 412 APIError
+// when the domain changed since that ETag was read.
+func (c *Client) UpsertDomain(req model.DomainUpsertRequest, ifMatch string) (*model.Domain, string, error) {
+	var resp model.Domain
+	header, err := c.doWithHeaders(http.MethodPut, "/domains", conditional(ifMatch), req, &resp)
+	if err != nil {
+		return nil, "", err
+	}
+	return &resp, header.Get("ETag"), nil
+}
+
+// UpdateDomain updates the settings of a domain
+func (c *Client) UpdateDomain(id int, req model.DomainUpdateRequest) error {
+	return c.do(http.MethodPut, fmt.Sprintf("/domains/%d", id), req, nil)
+}
+
+// UpdateAllDomains applies the same settings to every domain of the user
+func (c *Client) UpdateAllDomains(req model.DomainUpdateRequest) error {
+	return c.do(http.MethodPut, "/domains/batch", req, nil)
+}
+
+// DeleteDomain deletes a domain
+func (c *Client) DeleteDomain(id int) error {
+	return c.do(http.MethodDelete, fmt.Sprintf("/domains/%d", id), nil, nil)
+}
+
+// ArchiveDomain removes a domain's provider monitors and keeps it listed with a daily check
+func (c *Client) ArchiveDomain(id int) error {
+	return c.do(http.MethodPost, fmt.Sprintf("/domains/%d/archive", id), nil, nil)
+}
+
+// UnarchiveDomain reactivates an archived domain
+func (c *Client) UnarchiveDomain(id int) error {
+	return c.do(http.MethodPost, fmt.Sprintf("/domains/%d/unarchive", id), nil, nil)
+}
+
+// GetDomainHistory returns the status timeline of a domain. Zero times leave the
+// range open on that side and a zero limit uses the server default.
+func (c *Client) GetDomainHistory(id int, from, to time.Time, limit int) (*model.DomainHistoryResponse, error) {
+	query := url.Values{}
+	if !from.IsZero() {
+		query.Set("from", from.Format(time.RFC3339))
+	}
+	if !to.IsZero() {
+		query.Set("to", to.Format(time.RFC3339))
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	var resp model.DomainHistoryResponse
+	path := fmt.Sprintf("/domains/%d/history", id)
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	if err := c.do(http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RecreateMonitors deletes and recreates a domain's monitors on the given providers,
+// or on every provider when none are given
+func (c *Client) RecreateMonitors(id int, providers ...string) (*model.MonitorRecreateResponse, error) {
+	var resp model.MonitorRecreateResponse
+	req := model.MonitorRecreateRequest{Providers: providers}
+	if err := c.do(http.MethodPost, fmt.Sprintf("/domains/%d/monitors/recreate", id), req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RetryMonitorTasks retries the failed monitor creations and deletions of a domain now,
+// returning the number of tasks retried
+func (c *Client) RetryMonitorTasks(id int) (int, error) {
+	var resp struct {
+		Retried int `json:"retried"`
+	}
+	if err := c.do(http.MethodPost, fmt.Sprintf("/domains/%d/monitor-tasks/retry", id), nil, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Retried, nil
+}
+
+// GetDirectCheck returns the built-in HTTP check settings of a domain
+func (c *Client) GetDirectCheck(id int) (*model.DirectCheckSettings, error) {
+	var resp model.DirectCheckSettings
+	if err := c.do(http.MethodGet, fmt.Sprintf("/domains/%d/direct-check", id), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UpdateDirectCheck enables, disables or reconfigures the built-in HTTP check of a domain
+func (c *Client) UpdateDirectCheck(id int, req model.DirectCheckRequest) (*model.DirectCheckSettings, error) {
+	var resp model.DirectCheckSettings
+	if err := c.do(http.MethodPut, fmt.Sprintf("/domains/%d/direct-check", id), req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetDomainTags returns the tags of a domain
+func (c *Client) GetDomainTags(id int) ([]string, error) {
+	var resp model.TagsResponse
+	if err := c.do(http.MethodGet, fmt.Sprintf("/domains/%d/tags", id), nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Tags, nil
+}
+
+// SetDomainTags replaces the tags of a domain and returns them normalized
+func (c *Client) SetDomainTags(id int, tags ...string) ([]string, error) {
+	var resp model.TagsResponse
+	if err := c.do(http.MethodPut, fmt.Sprintf("/domains/%d/tags", id), model.TagsRequest{Tags: tags}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Tags, nil
+}
+
+// GetDomainProviders returns the monitoring providers a domain is checked by
+func (c *Client) GetDomainProviders(id int) (*model.ProviderSelection, error) {
+	var resp model.ProviderSelection
+	if err := c.do(http.MethodGet, fmt.Sprintf("/domains/%d/providers", id), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SetDomainProviders selects the monitoring providers a domain is checked by. With no
+// providers the domain follows the user's selection again.
+func (c *Client) SetDomainProviders(id int, providers ...string) (*model.ProviderSelection, error) {
+	var resp model.ProviderSelection
+	req := model.ProviderSelectionRequest{Providers: providers}
+	if err := c.do(http.MethodPut, fmt.Sprintf("/domains/%d/providers", id), req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetMonitorProviders returns the default monitoring providers of the user's domains
+func (c *Client) GetMonitorProviders() (*model.ProviderSelection, error) {
+	var resp model.ProviderSelection
+	if err := c.do(http.MethodGet, "/user/monitor-providers", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SetMonitorProviders selects the default monitoring providers of the user's domains. With
+// no providers every configured provider is used.
+func (c *Client) SetMonitorProviders(providers ...string) (*model.ProviderSelection, error) {
+	var resp model.ProviderSelection
+	req := model.ProviderSelectionRequest{Providers: providers}
+	if err := c.do(http.MethodPut, "/user/monitor-providers", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetDomainRunbook returns the runbook that applies to a domain
+func (c *Client) GetDomainRunbook(id int) (*model.Runbook, error) {
+	var runbook model.Runbook
+	if err := c.do(http.MethodGet, fmt.Sprintf("/domains/%d/runbook", id), nil, &runbook); err != nil {
+		return nil, err
+	}
+	return &runbook, nil
+}
+
+// SetDomainRunbook attaches a runbook to a domain
+func (c *Client) SetDomainRunbook(id int, req model.RunbookRequest) error {
+	return c.do(http.MethodPut, fmt.Sprintf("/domains/%d/runbook", id), req, nil)
+}
+
+// SetRegionRunbook sets the runbook used by domains in a region without their own
+func (c *Client) SetRegionRunbook(region string, req model.RunbookRequest) error {
+	return c.do(http.MethodPut, "/runbooks/regions/"+url.PathEscape(region), req, nil)
+}
+
+// DeleteDomains deletes several domains at once. When some domains fail, the
+// per-domain results are still returned alongside the error.
+func (c *Client) DeleteDomains(ids []int) (*model.DomainBatchDeleteResponse, error) {
+	var resp model.DomainBatchDeleteResponse
+	err := c.do(http.MethodDelete, "/domains/batch", model.DomainBatchDeleteRequest{DomainIDs: ids}, &resp)
+	return &resp, err
+}
+
+// GetTLSHistory returns the most recent TLS capability probes of a domain
+func (c *Client) GetTLSHistory(id, limit int) ([]model.TLSProbe, error) {
+	var resp struct {
+		Probes []model.TLSProbe `json:"probes"`
+	}
+	if err := c.do(http.MethodGet, fmt.Sprintf("/domains/%d/tls-history?limit=%d", id, limit), nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Probes, nil
+}
+
+// GetDNSHistory returns the distinct DNS record sets seen for a domain, newest first
+func (c *Client) GetDNSHistory(id, limit int) ([]model.DNSSnapshot, error) {
+	var resp struct {
+		Snapshots []model.DNSSnapshot `json:"snapshots"`
+	}
+	if err := c.do(http.MethodGet, fmt.Sprintf("/domains/%d/dns-history?limit=%d", id, limit), nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Snapshots, nil
+}
+
+// GetRegistration returns the latest registration (WHOIS/RDAP) lookup for a domain
+func (c *Client) GetRegistration(id int) (*model.DomainRegistration, error) {
+	var resp model.DomainRegistration
+	if err := c.do(http.MethodGet, fmt.Sprintf("/domains/%d/registration", id), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
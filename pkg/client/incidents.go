@@ -0,0 +1,37 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"domain-detection-go/pkg/model"
+)
+
+// ListIncidents returns the user's incidents, newest first. status may be
+// model.IncidentStatusOpen, model.IncidentStatusResolved or empty for both.
+func (c *Client) ListIncidents(status string, limit int) ([]model.Incident, error) {
+	return c.listIncidents("/incidents", status, limit)
+}
+
+// ListDomainIncidents returns the incidents of one domain, newest first
+func (c *Client) ListDomainIncidents(domainID int, status string, limit int) ([]model.Incident, error) {
+	return c.listIncidents(fmt.Sprintf("/domains/%d/incidents", domainID), status, limit)
+}
+
+func (c *Client) listIncidents(path, status string, limit int) ([]model.Incident, error) {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(limit))
+	if status != "" {
+		query.Set("status", status)
+	}
+
+	var resp struct {
+		Incidents []model.Incident `json:"incidents"`
+	}
+	if err := c.do(http.MethodGet, path+"?"+query.Encode(), nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Incidents, nil
+}
@@ -0,0 +1,52 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+
+	"domain-detection-go/pkg/model"
+)
+
+// ListMaintenanceWindows returns the user's current and upcoming maintenance windows
+func (c *Client) ListMaintenanceWindows() ([]model.MaintenanceWindow, error) {
+	return c.listMaintenanceWindows("/maintenance")
+}
+
+// ListDomainMaintenanceWindows returns the maintenance windows covering a domain,
+// including user-wide ones
+func (c *Client) ListDomainMaintenanceWindows(domainID int) ([]model.MaintenanceWindow, error) {
+	return c.listMaintenanceWindows(fmt.Sprintf("/domains/%d/maintenance", domainID))
+}
+
+// CreateMaintenanceWindow schedules maintenance for every domain of the user
+func (c *Client) CreateMaintenanceWindow(req model.MaintenanceWindowRequest) (*model.MaintenanceWindow, error) {
+	var window model.MaintenanceWindow
+	if err := c.do(http.MethodPost, "/maintenance", req, &window); err != nil {
+		return nil, err
+	}
+	return &window, nil
+}
+
+// CreateDomainMaintenanceWindow schedules maintenance for one domain
+func (c *Client) CreateDomainMaintenanceWindow(domainID int, req model.MaintenanceWindowRequest) (*model.MaintenanceWindow, error) {
+	var window model.MaintenanceWindow
+	if err := c.do(http.MethodPost, fmt.Sprintf("/domains/%d/maintenance", domainID), req, &window); err != nil {
+		return nil, err
+	}
+	return &window, nil
+}
+
+// DeleteMaintenanceWindow removes a maintenance window
+func (c *Client) DeleteMaintenanceWindow(id int) error {
+	return c.do(http.MethodDelete, fmt.Sprintf("/maintenance/%d", id), nil, nil)
+}
+
+func (c *Client) listMaintenanceWindows(path string) ([]model.MaintenanceWindow, error) {
+	var resp struct {
+		Windows []model.MaintenanceWindow `json:"maintenance_windows"`
+	}
+	if err := c.do(http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Windows, nil
+}
@@ -0,0 +1,214 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"domain-detection-go/pkg/model"
+)
+
+// Notification channels accepted by the digest endpoints
+const (
+	ChannelTelegram = "telegram"
+	ChannelEmail    = "email"
+)
+
+// ListTelegramConfigs returns the user's Telegram notification configs
+func (c *Client) ListTelegramConfigs() ([]model.TelegramConfig, error) {
+	var resp struct {
+		Configs []model.TelegramConfig `json:"configs"`
+	}
+	if err := c.do(http.MethodGet, "/telegram/configs", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Configs, nil
+}
+
+// AddTelegramConfig adds a Telegram notification config and returns its ID
+func (c *Client) AddTelegramConfig(req model.TelegramConfigRequest) (int, error) {
+	var resp messageResponse
+	if err := c.do(http.MethodPost, "/telegram/configs", req, &resp); err != nil {
+		return 0, err
+	}
+	return resp.ID, nil
+}
+
+// UpdateTelegramConfig updates a Telegram notification config
+func (c *Client) UpdateTelegramConfig(id int, req model.TelegramConfigRequest) error {
+	return c.do(http.MethodPut, fmt.Sprintf("/telegram/configs/%d", id), req, nil)
+}
+
+// DeleteTelegramConfig deletes a Telegram notification config
+func (c *Client) DeleteTelegramConfig(id int) error {
+	return c.do(http.MethodDelete, fmt.Sprintf("/telegram/configs/%d", id), nil, nil)
+}
+
+// TestTelegramConfig sends a test message to a Telegram chat
+func (c *Client) TestTelegramConfig(id int) error {
+	return c.do(http.MethodPost, fmt.Sprintf("/telegram/configs/%d/test", id), nil, nil)
+}
+
+// GetTelegramConfigByExternalID returns the Telegram config with the given external ID and its ETag
+func (c *Client) GetTelegramConfigByExternalID(externalID string) (*model.TelegramConfig, string, error) {
+	var resp model.TelegramConfig
+	header, err := c.doWithHeaders(http.MethodGet, "/telegram/configs/external/"+url.PathEscape(externalID), nil, nil, &resp)
+	if err != nil {
+		return nil, "", err
+	}
+	return &resp, header.Get("ETag"), nil
+}
+
+// UpsertTelegramConfig creates or replaces the Telegram config with the given external ID
+// and returns it with its ETag. See UpsertDomain for ifMatch.
+func (c *Client) UpsertTelegramConfig(externalID string, req model.TelegramConfigRequest, ifMatch string) (*model.TelegramConfig, string, error) {
+	var resp model.TelegramConfig
+	header, err := c.doWithHeaders(http.MethodPut, "/telegram/configs/external/"+url.PathEscape(externalID), conditional(ifMatch), req, &resp)
+	if err != nil {
+		return nil, "", err
+	}
+	return &resp, header.Get("ETag"), nil
+}
+
+// ListEmailConfigs returns the user's email notification configs
+func (c *Client) ListEmailConfigs() ([]model.EmailConfig, error) {
+	var resp struct {
+		Configs []model.EmailConfig `json:"configs"`
+	}
+	if err := c.do(http.MethodGet, "/email/configs", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Configs, nil
+}
+
+// AddEmailConfig adds an email notification config and returns its ID
+func (c *Client) AddEmailConfig(req model.EmailConfigRequest) (int, error) {
+	var resp messageResponse
+	if err := c.do(http.MethodPost, "/email/configs", req, &resp); err != nil {
+		return 0, err
+	}
+	return resp.ID, nil
+}
+
+// UpdateEmailConfig updates an email notification config
+func (c *Client) UpdateEmailConfig(id int, req model.EmailConfigRequest) error {
+	return c.do(http.MethodPut, fmt.Sprintf("/email/configs/%d", id), req, nil)
+}
+
+// DeleteEmailConfig deletes an email notification config
+func (c *Client) DeleteEmailConfig(id int) error {
+	return c.do(http.MethodDelete, fmt.Sprintf("/email/configs/%d", id), nil, nil)
+}
+
+// TestEmailConfig sends a test email to an address
+func (c *Client) TestEmailConfig(id int) error {
+	return c.do(http.MethodPost, fmt.Sprintf("/email/configs/%d/test", id), nil, nil)
+}
+
+// GetEmailConfigByExternalID returns the email config with the given external ID and its ETag
+func (c *Client) GetEmailConfigByExternalID(externalID string) (*model.EmailConfig, string, error) {
+	var resp model.EmailConfig
+	header, err := c.doWithHeaders(http.MethodGet, "/email/configs/external/"+url.PathEscape(externalID), nil, nil, &resp)
+	if err != nil {
+		return nil, "", err
+	}
+	return &resp, header.Get("ETag"), nil
+}
+
+// UpsertEmailConfig creates or replaces the email config with the given external ID
+// and returns it with its ETag. See UpsertDomain for ifMatch.
+func (c *Client) UpsertEmailConfig(externalID string, req model.EmailConfigRequest, ifMatch string) (*model.EmailConfig, string, error) {
+	var resp model.EmailConfig
+	header, err := c.doWithHeaders(http.MethodPut, "/email/configs/external/"+url.PathEscape(externalID), conditional(ifMatch), req, &resp)
+	if err != nil {
+		return nil, "", err
+	}
+	return &resp, header.Get("ETag"), nil
+}
+
+// GetDigestSettings returns the digest settings of a notification config
+func (c *Client) GetDigestSettings(channel string, configID int) (*model.DigestSettings, error) {
+	var resp model.DigestSettings
+	if err := c.do(http.MethodGet, fmt.Sprintf("/notifications/configs/%s/%d/digest", channel, configID), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UpdateDigestSettings updates the digest settings of a notification config
+func (c *Client) UpdateDigestSettings(channel string, configID int, req model.DigestSettingsRequest) error {
+	return c.do(http.MethodPut, fmt.Sprintf("/notifications/configs/%s/%d/digest", channel, configID), req, nil)
+}
+
+// GetLanguageFallbacks returns the language fallback chain of a notification config
+func (c *Client) GetLanguageFallbacks(channel string, configID int) (*model.LanguageFallbacks, error) {
+	var resp model.LanguageFallbacks
+	if err := c.do(http.MethodGet, fmt.Sprintf("/notifications/configs/%s/%d/languages", channel, configID), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UpdateLanguageFallbacks sets the language fallback chain of a notification config
+func (c *Client) UpdateLanguageFallbacks(channel string, configID int, fallbacks ...string) error {
+	req := model.LanguageFallbacksRequest{Fallbacks: fallbacks}
+	return c.do(http.MethodPut, fmt.Sprintf("/notifications/configs/%s/%d/languages", channel, configID), req, nil)
+}
+
+// GetConfigTags returns the domain tags a notification config subscribes to
+func (c *Client) GetConfigTags(channel string, configID int) ([]string, error) {
+	var resp model.TagsResponse
+	if err := c.do(http.MethodGet, fmt.Sprintf("/notifications/configs/%s/%d/tags", channel, configID), nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Tags, nil
+}
+
+// UpdateConfigTags replaces the domain tags a notification config subscribes to. With
+// no tags the config gets alerts for every domain again.
+func (c *Client) UpdateConfigTags(channel string, configID int, tags ...string) ([]string, error) {
+	var resp model.TagsResponse
+	req := model.TagsRequest{Tags: tags}
+	if err := c.do(http.MethodPut, fmt.Sprintf("/notifications/configs/%s/%d/tags", channel, configID), req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Tags, nil
+}
+
+// GetQuietHours returns the quiet hours of a notification config
+func (c *Client) GetQuietHours(channel string, configID int) (*model.QuietHours, error) {
+	var quiet model.QuietHours
+	if err := c.do(http.MethodGet, fmt.Sprintf("/notifications/configs/%s/%d/quiet-hours", channel, configID), nil, &quiet); err != nil {
+		return nil, err
+	}
+	return &quiet, nil
+}
+
+// UpdateQuietHours replaces the quiet hours of a notification config
+func (c *Client) UpdateQuietHours(channel string, configID int, req model.QuietHoursRequest) (*model.QuietHours, error) {
+	var quiet model.QuietHours
+	if err := c.do(http.MethodPut, fmt.Sprintf("/notifications/configs/%s/%d/quiet-hours", channel, configID), req, &quiet); err != nil {
+		return nil, err
+	}
+	return &quiet, nil
+}
+
+// GetConfigTimezone returns the timezone preference of a notification config
+func (c *Client) GetConfigTimezone(channel string, configID int) (*model.ConfigTimezone, error) {
+	var resp model.ConfigTimezone
+	if err := c.do(http.MethodGet, fmt.Sprintf("/notifications/configs/%s/%d/timezone", channel, configID), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UpdateConfigTimezone sets the timezone preference of a notification config. An empty
+// timezone makes the config use the user's timezone again.
+func (c *Client) UpdateConfigTimezone(channel string, configID int, timezone string) (*model.ConfigTimezone, error) {
+	var resp model.ConfigTimezone
+	req := model.TimezoneRequest{Timezone: timezone}
+	if err := c.do(http.MethodPut, fmt.Sprintf("/notifications/configs/%s/%d/timezone", channel, configID), req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+
+	"domain-detection-go/pkg/model"
+)
+
+// GetDomainUptime returns the 24h, 7d and 30d uptime of a domain
+func (c *Client) GetDomainUptime(id int) (*model.DomainUptimeReport, error) {
+	var resp model.DomainUptimeReport
+	if err := c.do(http.MethodGet, fmt.Sprintf("/domains/%d/uptime", id), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetUptimeReport returns the uptime of every non-archived domain of the user
+func (c *Client) GetUptimeReport() ([]model.DomainUptimeReport, error) {
+	var resp struct {
+		Domains []model.DomainUptimeReport `json:"domains"`
+	}
+	if err := c.do(http.MethodGet, "/reports/uptime", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Domains, nil
+}
+
+// GetPublicPageSettings returns the hosted status page configuration
+func (c *Client) GetPublicPageSettings() (*model.PublicPageSettings, error) {
+	var resp model.PublicPageSettings
+	if err := c.do(http.MethodGet, "/status-pages/public", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UpdatePublicPageSettings enables or disables the hosted status page and chooses the
+// domains it lists
+func (c *Client) UpdatePublicPageSettings(req model.PublicPageRequest) (*model.PublicPageSettings, error) {
+	var resp model.PublicPageSettings
+	if err := c.do(http.MethodPut, "/status-pages/public", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
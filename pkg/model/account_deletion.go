@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// Account deletion statuses
+const (
+	AccountDeletionPending   = "pending"
+	AccountDeletionRunning   = "running"
+	AccountDeletionCompleted = "completed"
+	AccountDeletionFailed    = "failed" // Stopped at a step, resumed there by the scheduler
+)
+
+// Account deletion steps, in the order they run
+const (
+	AccountDeletionStepMonitors      = "monitors"      // Provider monitors of every domain
+	AccountDeletionStepHistory       = "history"       // Check and notification history, deep check orders
+	AccountDeletionStepNotifications = "notifications" // Telegram and email configs
+	AccountDeletionStepDomains       = "domains"
+	AccountDeletionStepAccount       = "account" // The user and everything left that belongs to it
+)
+
+// AccountDeletionRequest confirms the deletion of the requesting user's account. Users
+// with 2FA enabled also give a TOTP code or a recovery code.
+type AccountDeletionRequest struct {
+	Password     string `json:"password" binding:"required"`
+	TOTPCode     string `json:"totp_code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// AccountDeletion is the progress of an account being deleted in the background
+type AccountDeletion struct {
+	ID           int        `json:"id" db:"id"`
+	UserID       int        `json:"user_id" db:"user_id"`
+	Username     string     `json:"-" db:"username"`
+	Status       string     `json:"status" db:"status"`
+	Step         string     `json:"step" db:"step"`
+	DomainsTotal int        `json:"domains_total" db:"domains_total"`
+	DomainsDone  int        `json:"domains_done" db:"domains_done"` // Domains whose provider monitors were deleted
+	LastError    *string    `json:"last_error,omitempty" db:"last_error"`
+	RequestedAt  time.Time  `json:"requested_at" db:"requested_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// APIKey is a user's key for programmatic access. The secret itself is never stored.
+type APIKey struct {
+	ID         int        `json:"id" db:"id"`
+	UserID     int        `json:"user_id" db:"user_id"`
+	Name       string     `json:"name" db:"name"`
+	KeyPrefix  string     `json:"key_prefix" db:"key_prefix"`
+	LastUsedAt *time.Time `json:"last_used_at" db:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// APIKeyCreateRequest represents a request to create an API key
+type APIKeyCreateRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// APIKeyCreateResponse is returned once when a key is created; Key is the only time
+// the full secret is shown
+type APIKeyCreateResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
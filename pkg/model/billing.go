@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// BillingUnitPrices holds the configurable unit prices used on statements for a plan
+type BillingUnitPrices struct {
+	Plan              string    `json:"plan" db:"plan"`
+	Currency          string    `json:"currency" db:"currency"`
+	DomainPrice       float64   `json:"domain_price" db:"domain_price"`
+	CheckPrice        float64   `json:"check_price" db:"check_price"`
+	DeepCheckPrice    float64   `json:"deep_check_price" db:"deep_check_price"`
+	NotificationPrice float64   `json:"notification_price" db:"notification_price"`
+	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// BillingUnitPricesRequest represents a request to update unit prices for a plan
+type BillingUnitPricesRequest struct {
+	Currency          string  `json:"currency" binding:"required,len=3"`
+	DomainPrice       float64 `json:"domain_price" binding:"min=0"`
+	CheckPrice        float64 `json:"check_price" binding:"min=0"`
+	DeepCheckPrice    float64 `json:"deep_check_price" binding:"min=0"`
+	NotificationPrice float64 `json:"notification_price" binding:"min=0"`
+}
+
+// StatementLine is the usage and cost of a single domain for the statement period
+type StatementLine struct {
+	DomainID      int     `json:"domain_id" db:"domain_id"`
+	DomainName    string  `json:"domain_name" db:"domain_name"`
+	Region        string  `json:"region" db:"region"`
+	Interval      int     `json:"interval" db:"interval"`
+	Checks        int     `json:"checks" db:"checks"`
+	DeepChecks    int     `json:"deep_checks" db:"deep_checks"`
+	Notifications int     `json:"notifications" db:"notifications"`
+	Amount        float64 `json:"amount"`
+}
+
+// Statement is a monthly usage statement for a user
+type Statement struct {
+	UserID             int               `json:"user_id"`
+	Username           string            `json:"username"`
+	Month              string            `json:"month"` // YYYY-MM
+	PeriodStart        time.Time         `json:"period_start"`
+	PeriodEnd          time.Time         `json:"period_end"`
+	Prices             BillingUnitPrices `json:"prices"`
+	Lines              []StatementLine   `json:"lines"`
+	TotalDomains       int               `json:"total_domains"`
+	TotalChecks        int               `json:"total_checks"`
+	TotalDeepChecks    int               `json:"total_deep_checks"`
+	TotalNotifications int               `json:"total_notifications"`
+	TotalAmount        float64           `json:"total_amount"`
+}
@@ -4,6 +4,14 @@ import (
 	"time"
 )
 
+// ErrorCodeContentMismatch marks a check whose page was served but failed the domain's
+// content match
+const ErrorCodeContentMismatch = -2
+
+// ErrorCodeBlocked marks a check that got an ISP block page or an injected connection
+// reset instead of the site
+const ErrorCodeBlocked = -3
+
 // DomainCheckResult represents the result of a domain check
 type DomainCheckResult struct {
 	Domain           string    `json:"domain"`
@@ -15,6 +23,9 @@ type DomainCheckResult struct {
 	ErrorCode        int       `db:"error_code" json:"error_code"`
 	TotalTime        int       `db:"total_time" json:"total_time"`
 	ErrorDescription string    `db:"error_description" json:"error_description"`
+
+	// Providers whose result disagreed with the combined decision
+	DissentingProviders []string `json:"dissenting_providers,omitempty"`
 }
 
 type UpTrendCheckResult []struct {
@@ -18,6 +18,38 @@ type DeepCheckOrder struct {
 	CompletedAt      *time.Time    `json:"completed_at" db:"completed_at"`
 	CallbackReceived bool          `json:"callback_received" db:"callback_received"`
 	CallbackData     *CallbackData `json:"callback_data" db:"callback_data"`
+
+	Source              string  `json:"source" db:"source"`                             // "automatic", "manual", "escalation" or "telegram"
+	ConsecutiveFailures *int    `json:"consecutive_failures" db:"consecutive_failures"` // Failed checks when an escalation was ordered
+	ReplyChatID         *string `json:"reply_chat_id,omitempty" db:"reply_chat_id"`     // Telegram chat that receives the results instead of every channel
+}
+
+// Deep check order states. Orders start pending and move once, either to completed
+// when the callback arrives or to failed when it never does.
+const (
+	DeepCheckStatusPending   = "pending"
+	DeepCheckStatusCompleted = "completed"
+	DeepCheckStatusFailed    = "failed"
+)
+
+// Deep check order sources
+const (
+	DeepCheckSourceAutomatic  = "automatic"
+	DeepCheckSourceManual     = "manual"
+	DeepCheckSourceEscalation = "escalation"
+	DeepCheckSourceTelegram   = "telegram" // Ordered with the bot's /deepcheck command
+)
+
+// DeepCheckEscalationSettings is how many consecutive failed checks trigger a deep check
+type DeepCheckEscalationSettings struct {
+	Failures  int  `json:"failures"`   // 0 means escalation is disabled
+	IsDefault bool `json:"is_default"` // True when the server default applies
+}
+
+// DeepCheckEscalationRequest updates the escalation threshold; a null value restores
+// the server default
+type DeepCheckEscalationRequest struct {
+	Failures *int `json:"failures" binding:"omitempty,min=0,max=100"`
 }
 
 // CallbackData represents the JSONB callback data
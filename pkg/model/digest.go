@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+// Digest triggers
+const (
+	DigestTriggerInterval      = "interval"        // Deliver every interval_minutes when there are events
+	DigestTriggerDownSetChange = "down_set_change" // Deliver only when the set of down domains changes
+)
+
+// Digest schedules, shorthands for the delivery interval
+const (
+	DigestScheduleHourly = "hourly"
+	DigestScheduleDaily  = "daily"
+)
+
+// DigestSettings configures digest-only delivery for a notification config
+type DigestSettings struct {
+	Channel         string     `json:"channel" db:"channel"`
+	ConfigID        int        `json:"config_id" db:"config_id"`
+	Enabled         bool       `json:"enabled" db:"enabled"`
+	IntervalMinutes int        `json:"interval_minutes" db:"interval_minutes"`
+	Trigger         string     `json:"trigger" db:"delivery_trigger"`
+	LastSentAt      *time.Time `json:"last_sent_at" db:"last_sent_at"`
+}
+
+// DigestSettingsRequest represents a request to update digest settings for a config
+type DigestSettingsRequest struct {
+	Enabled         bool   `json:"enabled"`
+	IntervalMinutes int    `json:"interval_minutes" binding:"omitempty,min=5,max=1440"`
+	Trigger         string `json:"trigger" binding:"omitempty,oneof=interval down_set_change"`
+	Schedule        string `json:"schedule" binding:"omitempty,oneof=hourly daily"` // Sets the interval when interval_minutes is not given
+}
+
+// DigestEvent is a single status notification held back for a digest
+type DigestEvent struct {
+	ID               int       `json:"id" db:"id"`
+	DomainID         int       `json:"domain_id" db:"domain_id"`
+	DomainName       string    `json:"domain_name" db:"domain_name"`
+	Region           *string   `json:"region" db:"region"`
+	NotificationType string    `json:"notification_type" db:"notification_type"`
+	StatusCode       *int      `json:"status_code" db:"status_code"`
+	ErrorDescription *string   `json:"error_description" db:"error_description"`
+	OccurredAt       time.Time `json:"occurred_at" db:"occurred_at"`
+}
+
+// Digest is a compact summary delivered instead of individual alerts
+type Digest struct {
+	Events      []DigestEvent `json:"events"`
+	DownDomains []string      `json:"down_domains"` // Domains down at the time of the digest
+	WentDown    []string      `json:"went_down"`    // Domains newly down since the last digest
+	Recovered   []string      `json:"recovered"`    // Domains back up since the last digest
+	PeriodStart time.Time     `json:"period_start"`
+	PeriodEnd   time.Time     `json:"period_end"`
+
+	IncidentsOpened   int                  `json:"incidents_opened"`
+	IncidentsResolved int                  `json:"incidents_resolved"`
+	SlowestDomains    []DigestDomainTiming `json:"slowest_domains"`
+}
+
+// DigestDomainTiming is the average response time of a domain over a digest period
+type DigestDomainTiming struct {
+	Name            string  `json:"name" db:"name"`
+	AvgResponseTime float64 `json:"avg_response_time" db:"avg_response_time"` // Milliseconds, successful checks only
+}
@@ -0,0 +1,21 @@
+package model
+
+// DirectCheckSettings configures the built-in HTTP check of a domain. Nil values use
+// the server defaults.
+type DirectCheckSettings struct {
+	DomainID        int     `json:"domain_id" db:"domain_id"`
+	Enabled         bool    `json:"enabled" db:"is_active"`
+	TimeoutSeconds  *int    `json:"timeout_seconds" db:"timeout_seconds"`
+	UserAgent       *string `json:"user_agent" db:"user_agent"`
+	FollowRedirects bool    `json:"follow_redirects" db:"follow_redirects"`
+	MaxRedirects    *int    `json:"max_redirects" db:"max_redirects"`
+}
+
+// DirectCheckRequest enables, disables or reconfigures the built-in HTTP check of a domain
+type DirectCheckRequest struct {
+	Enabled         bool    `json:"enabled"`
+	TimeoutSeconds  *int    `json:"timeout_seconds" binding:"omitempty,min=1,max=60"`
+	UserAgent       *string `json:"user_agent" binding:"omitempty,max=255"`
+	FollowRedirects *bool   `json:"follow_redirects"`
+	MaxRedirects    *int    `json:"max_redirects" binding:"omitempty,min=0,max=20"`
+}
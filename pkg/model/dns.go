@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// DNSResolver is a public resolver used to see a domain the way a region sees it
+type DNSResolver struct {
+	ID         int    `json:"id" db:"id"`
+	RegionCode string `json:"region_code" db:"region_code"`
+	Name       string `json:"name" db:"name"`
+	Address    string `json:"address" db:"address"`
+	IsActive   bool   `json:"is_active" db:"is_active"`
+}
+
+// DNSResolution is the answer a single resolver gave for a domain
+type DNSResolution struct {
+	RegionCode string   `json:"region_code"`
+	Resolver   string   `json:"resolver"`
+	Address    string   `json:"address"`
+	IPs        []string `json:"ips"`
+	CNAME      string   `json:"cname,omitempty"` // Canonical name the answer was served from, if aliased
+	Error      string   `json:"error,omitempty"`
+}
+
+// DNSComparison is the latest cross-region resolution comparison for a domain
+type DNSComparison struct {
+	DomainID   int             `json:"domain_id" db:"domain_id"`
+	Divergent  bool            `json:"divergent" db:"divergent"`
+	Reason     *string         `json:"reason,omitempty" db:"reason"`
+	Results    []DNSResolution `json:"results" db:"-"`
+	ComparedAt time.Time       `json:"compared_at" db:"compared_at"`
+}
+
+// DNSSnapshot is a distinct set of DNS records seen for a domain, from first_seen_at
+// until last_seen_at
+type DNSSnapshot struct {
+	ID          int       `json:"id" db:"id"`
+	DomainID    int       `json:"domain_id" db:"domain_id"`
+	Resolver    string    `json:"resolver" db:"resolver"`
+	A           []string  `json:"a" db:"-"`
+	AAAA        []string  `json:"aaaa" db:"-"`
+	CNAME       *string   `json:"cname,omitempty" db:"cname"`
+	NS          []string  `json:"ns" db:"-"`
+	FirstSeenAt time.Time `json:"first_seen_at" db:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at" db:"last_seen_at"`
+}
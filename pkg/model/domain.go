@@ -2,26 +2,105 @@ package model
 
 import (
 	"time"
+
+	"github.com/lib/pq"
 )
 
 // Domain represents a domain to be monitored
 type Domain struct {
-	ID                int       `json:"id" db:"id"`
-	UserID            int       `json:"user_id" db:"user_id"`
-	Name              string    `json:"name" db:"name"`
-	Active            bool      `json:"active" db:"active"`
-	Interval          int       `json:"interval" db:"interval"` // Interval in minutes
-	Region            string    `json:"region" db:"region"`     // Region for this domain
-	MonitorGuid       *string   `json:"monitor_guid" db:"monitor_guid"`
-	Site24x7MonitorID *string   `json:"site24x7_monitor_id" db:"site24x7_monitor_id"` // Add this field
-	IsDeepCheck       bool      `json:"is_deep_check" db:"is_deep_check"`
-	CreatedAt         time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
-	LastStatus        int       `json:"last_status" db:"last_status"`
-	LastCheck         time.Time `json:"last_check,omitempty" db:"last_check"`
-	ErrorCode         int       `json:"error_code" db:"error_code"`
-	TotalTime         int       `json:"total_time" db:"total_time"`
-	ErrorDescription  string    `json:"error_description" db:"error_description"`
+	ID                   int            `json:"id" db:"id"`
+	UserID               int            `json:"user_id" db:"user_id"`
+	Name                 string         `json:"name" db:"name"`
+	Active               bool           `json:"active" db:"active"`
+	Interval             int            `json:"interval" db:"interval"`           // Interval in minutes
+	Region               string         `json:"region" db:"region"`               // Region for this domain
+	ExtraRegions         pq.StringArray `json:"extra_regions" db:"extra_regions"` // Further regions the domain is monitored in
+	MonitorGuid          *string        `json:"monitor_guid" db:"monitor_guid"`
+	Site24x7MonitorID    *string        `json:"site24x7_monitor_id" db:"site24x7_monitor_id"` // Add this field
+	DirectMonitorID      *string        `json:"direct_monitor_id" db:"direct_monitor_id"`     // Set while the built-in HTTP check is enabled
+	IsDeepCheck          bool           `json:"is_deep_check" db:"is_deep_check"`
+	CreatedAt            time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time      `json:"updated_at" db:"updated_at"`
+	LastStatus           int            `json:"last_status" db:"last_status"`
+	LastCheck            time.Time      `json:"last_check,omitempty" db:"last_check"`
+	ErrorCode            int            `json:"error_code" db:"error_code"`
+	TotalTime            int            `json:"total_time" db:"total_time"`
+	ErrorDescription     string         `json:"error_description" db:"error_description"`
+	RecoveryPending      bool           `json:"recovery_pending" db:"recovery_pending"`               // Up again but not yet confirmed
+	RecoverySuccesses    int            `json:"recovery_successes" db:"recovery_successes"`           // Consecutive successful checks while pending
+	ConsecutiveFailures  int            `json:"consecutive_failures" db:"consecutive_failures"`       // Failed checks in a row, 0 while up
+	FailurePending       bool           `json:"failure_pending" db:"failure_pending"`                 // Down but not yet confirmed
+	FailureThreshold     int            `json:"failure_threshold" db:"failure_threshold"`             // Failed checks before the domain counts as down
+	RecoveryThreshold    *int           `json:"recovery_threshold" db:"recovery_threshold"`           // Successful checks before recovery, nil uses the server default
+	ContentMatchType     string         `json:"content_match_type" db:"content_match_type"`           // Empty when content matching is off
+	ContentMatchPattern  string         `json:"content_match_pattern" db:"content_match_pattern"`     // Keyword or regular expression
+	AvailabilityStrategy string         `json:"availability_strategy" db:"availability_strategy"`     // How provider results combine, see AvailabilityAll
+	ArchivedAt           *time.Time     `json:"archived_at" db:"archived_at"`                         // Set while the domain is archived
+	DeletedAt            *time.Time     `json:"deleted_at,omitempty" db:"deleted_at"`                 // Set while the domain is in the trash
+	LastSkippedAt        *time.Time     `json:"last_skipped_at" db:"last_skipped_at"`                 // Last check skipped by load shedding
+	VerificationToken    *string        `json:"verification_token,omitempty" db:"verification_token"` // Set while ownership verification is required
+	VerifiedAt           *time.Time     `json:"verified_at,omitempty" db:"verified_at"`
+	SnoozedUntil         *time.Time     `json:"snoozed_until" db:"snoozed_until"` // Alerts are silenced until then, checks go on
+	Notes                string         `json:"notes" db:"notes"`
+	OwnerContact         string         `json:"owner_contact" db:"owner_contact"` // Whom to escalate to, e.g. an email or a name
+	Labels               DomainLabels   `json:"labels" db:"labels"`
+	UnicodeName          string         `json:"unicode_name,omitempty" db:"unicode_name"`         // Internationalized form of Name, empty for ASCII domains
+	ParentID             *int           `json:"parent_id,omitempty" db:"parent_id"`               // Base domain of a discovered subdomain
+	CheckType            string         `json:"check_type" db:"check_type"`                       // See CheckTypeHTTPS
+	Port                 *int           `json:"port,omitempty" db:"port"`                         // Port of TCP checks
+	NotificationChannels pq.StringArray `json:"notification_channels" db:"notification_channels"` // Channels the domain alerts through, nil for every channel
+
+	HTTPCheckSettings // Only populated on the domain detail and where monitors are created
+
+	DNSComparison *DNSComparison `json:"dns_comparison,omitempty" db:"-"` // Only populated on the domain detail
+	Runbook       *Runbook       `json:"runbook,omitempty" db:"-"`        // Populated on the domain detail and in down alerts
+	Incident      *Incident      `json:"incident,omitempty" db:"-"`       // Populated in down and recovery alerts
+	MonitorTasks  []MonitorTask  `json:"monitor_tasks,omitempty" db:"-"`  // Pending and failed monitor tasks, on the domain list and detail
+	Provisioning  bool           `json:"provisioning" db:"-"`             // A monitor creation is waiting to be retried
+
+	RegionStatuses []DomainRegionStatus `json:"region_statuses,omitempty" db:"-"` // Per-provider, per-region results, on the domain detail
+	CheckedRegion  string               `json:"checked_region,omitempty" db:"-"`  // Region whose result decided the last check, in alerts
+}
+
+// DomainSnoozeRequest silences the alerts of a domain for a duration such as "2h"
+type DomainSnoozeRequest struct {
+	Duration string `json:"duration" binding:"required"`
+}
+
+// DomainSnooze is the snooze set on a domain
+type DomainSnooze struct {
+	DomainID     int       `json:"domain_id" db:"domain_id"`
+	Name         string    `json:"name" db:"name"`
+	SnoozedUntil time.Time `json:"snoozed_until" db:"snoozed_until"`
+}
+
+// DisplayName returns the name to show users: the Unicode form of internationalized
+// domains, Name otherwise
+func (d Domain) DisplayName() string {
+	if d.UnicodeName != "" {
+		return d.UnicodeName
+	}
+	return d.Name
+}
+
+// Regions returns the primary region of the domain followed by its extra regions
+func (d Domain) Regions() []string {
+	return append([]string{d.Region}, d.ExtraRegions...)
+}
+
+// AlertRegion returns the region alerts about the domain are routed by: the region whose
+// result decided the last check, such as a failing extra region, or the primary region
+func (d Domain) AlertRegion() string {
+	if d.CheckedRegion != "" {
+		return d.CheckedRegion
+	}
+	return d.Region
+}
+
+// VerificationPending reports whether the domain waits for ownership verification
+// before it is monitored
+func (d Domain) VerificationPending() bool {
+	return d.VerificationToken != nil && d.VerifiedAt == nil
 }
 
 // GetMonitorGuid returns the monitor GUID as a string (empty if nil)
@@ -40,12 +119,30 @@ func (d Domain) GetSite24x7MonitorID() string {
 	return ""
 }
 
+// GetDirectMonitorID returns the built-in HTTP check monitor ID as a string (empty if nil)
+func (d Domain) GetDirectMonitorID() string {
+	if d.DirectMonitorID != nil {
+		return *d.DirectMonitorID
+	}
+	return ""
+}
+
 // DomainAddRequest represents the request to add a new domain
 type DomainAddRequest struct {
 	Name        string `json:"name" binding:"required"`
-	Interval    int    `json:"interval"`                  // If not provided, default will be used
-	Region      string `json:"region" binding:"required"` // NEW: Required region field
+	Interval    int    `json:"interval"` // If not provided, the user's default will be used
+	Region      string `json:"region"`   // If not provided, the user's default region will be used
 	IsDeepCheck bool   `json:"is_deep_check"`
+
+	ExtraRegions []string `json:"extra_regions"` // Further regions to monitor the domain in
+
+	// Discover the subdomains in certificate transparency logs and monitor them too
+	DiscoverSubdomains bool `json:"discover_subdomains"`
+
+	CheckType string `json:"check_type" binding:"omitempty,oneof=http https tcp icmp"` // Defaults to https
+	Port      *int   `json:"port" binding:"omitempty,min=1,max=65535"`                 // Required for tcp checks
+
+	HTTPCheckRequest
 }
 
 // DomainListResponse represents the response for domain listing
@@ -55,6 +152,12 @@ type DomainListResponse struct {
 	DomainLimit  int      `json:"domain_limit"`
 }
 
+// DomainTrashResponse lists the deleted domains of a user that can still be restored
+type DomainTrashResponse struct {
+	Domains       []Domain `json:"domains"`
+	RetentionDays int      `json:"retention_days"` // Days a domain stays in the trash before it is purged
+}
+
 // DomainStatusResponse represents the response for domain status
 type DomainStatusResponse struct {
 	ID           int       `json:"id"`
@@ -68,8 +171,11 @@ type DomainStatusResponse struct {
 // DomainBatchItem represents a single domain in a batch request
 type DomainBatchItem struct {
 	Name        string `json:"name" binding:"required"`
-	Region      string `json:"region" binding:"required"`
+	Region      string `json:"region"` // Optional, the user's default region is used without it
 	IsDeepCheck bool   `json:"is_deep_check"`
+	Interval    int    `json:"interval,omitempty"` // Optional, overrides the batch interval
+
+	ExtraRegions []string `json:"extra_regions,omitempty"` // Further regions to monitor the domain in
 }
 
 // DomainBatchAddRequest represents a batch request to add multiple domains
@@ -89,6 +195,7 @@ type DomainBatchAddResponse struct {
 // DomainAddResult represents the result for a single domain in batch operation
 type DomainAddResult struct {
 	Name   string `json:"name"`
+	Region string `json:"region,omitempty"`
 	ID     int    `json:"id,omitempty"`     // Only set for successful additions
 	Reason string `json:"reason,omitempty"` // Only set for failed additions
 }
@@ -99,6 +206,67 @@ type DomainUpdateRequest struct {
 	Interval    *int    `json:"interval"` // Interval in minutes
 	Region      *string `json:"region"`   // NEW: Optional region field for updates
 	IsDeepCheck *bool   `json:"is_deep_check"`
+
+	ExtraRegions *[]string `json:"extra_regions"` // Replaces the further regions; an empty list leaves the primary region only
+
+	FailureThreshold  *int `json:"failure_threshold" binding:"omitempty,min=1,max=10"`  // Failed checks before alerting
+	RecoveryThreshold *int `json:"recovery_threshold" binding:"omitempty,min=1,max=10"` // Successful checks before a recovery alert
+
+	// Expected homepage content. An empty type turns content matching off.
+	ContentMatchType    *string `json:"content_match_type" binding:"omitempty,oneof=present absent regex"`
+	ContentMatchPattern *string `json:"content_match_pattern" binding:"omitempty,max=500"`
+
+	AvailabilityStrategy *string `json:"availability_strategy" binding:"omitempty,oneof=any all majority"`
+
+	Port *int `json:"port" binding:"omitempty,min=1,max=65535"` // Only for tcp checks
+
+	Notes        *string       `json:"notes" binding:"omitempty,max=2000"`
+	OwnerContact *string       `json:"owner_contact" binding:"omitempty,max=255"`
+	Labels       *DomainLabels `json:"labels"` // Replaces every label; an empty object removes them
+
+	// Channels the domain alerts through; an empty list silences it everywhere
+	NotificationChannels *[]string `json:"notification_channels" binding:"omitempty,dive,oneof=telegram email"`
+
+	HTTPCheckRequest
+}
+
+// Check types. HTTP(S) domains are checked by every provider; TCP connect and ping checks
+// only by the built-in checker.
+const (
+	CheckTypeHTTP  = "http"
+	CheckTypeHTTPS = "https"
+	CheckTypeTCP   = "tcp"
+	CheckTypeICMP  = "icmp"
+)
+
+// IsHTTPCheckType reports whether a check type is served by the website monitors of the
+// external providers. The empty type counts as https.
+func IsHTTPCheckType(checkType string) bool {
+	return checkType == "" || checkType == CheckTypeHTTP || checkType == CheckTypeHTTPS
+}
+
+// Content match types
+const (
+	ContentMatchPresent = "present" // Keyword must appear on the page
+	ContentMatchAbsent  = "absent"  // Keyword must not appear, e.g. the text of a block page
+	ContentMatchRegex   = "regex"   // Regular expression must match
+)
+
+// Availability strategies combining the results of several monitoring providers
+const (
+	AvailabilityAll      = "all"      // Up only if every provider reports it up
+	AvailabilityAny      = "any"      // Up if any provider reports it up
+	AvailabilityMajority = "majority" // Up if more than half of the providers report it up; a tie counts as down
+)
+
+// DomainUpsertRequest declares the desired state of a domain, identified by its
+// canonical key (name and region). Used by PUT /api/domains for declarative clients.
+type DomainUpsertRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Region      string `json:"region" binding:"required"`
+	Interval    int    `json:"interval"` // If not provided, default will be used
+	Active      *bool  `json:"active"`   // Defaults to true
+	IsDeepCheck bool   `json:"is_deep_check"`
 }
 
 // DomainWithRegion extends Domain with user region info
@@ -117,6 +285,42 @@ func (d Domain) Available() bool {
 	return d.LastStatus >= 200 && d.LastStatus < 400
 }
 
+// ConfirmedAvailable reports whether the domain is up and any recovery has been confirmed.
+// A domain whose failures have not reached its failure threshold is still up.
+func (d Domain) ConfirmedAvailable() bool {
+	if d.FailurePending {
+		return true
+	}
+	return d.Available() && !d.RecoveryPending
+}
+
+// GetFailureThreshold returns the number of consecutive failed checks before the domain
+// counts as down (at least 1)
+func (d Domain) GetFailureThreshold() int {
+	if d.FailureThreshold < 1 {
+		return 1
+	}
+	return d.FailureThreshold
+}
+
+// GetAvailabilityStrategy returns how provider results combine, AvailabilityAll by default
+func (d Domain) GetAvailabilityStrategy() string {
+	switch d.AvailabilityStrategy {
+	case AvailabilityAny, AvailabilityMajority:
+		return d.AvailabilityStrategy
+	}
+	return AvailabilityAll
+}
+
+// GetRecoveryThreshold returns the number of consecutive successful checks before the
+// domain counts as recovered, falling back to the given default
+func (d Domain) GetRecoveryThreshold(defaultThreshold int) int {
+	if d.RecoveryThreshold != nil {
+		return *d.RecoveryThreshold
+	}
+	return defaultThreshold
+}
+
 // DomainBatchDeleteRequest represents a batch request to delete multiple domains
 type DomainBatchDeleteRequest struct {
 	DomainIDs []int `json:"domain_ids" binding:"required,min=1"`
@@ -136,3 +340,80 @@ type DomainDeleteResult struct {
 	Name   string `json:"name"`
 	Reason string `json:"reason,omitempty"` // Only present for failed deletions
 }
+
+// DomainBatchUpdateRequest changes the same settings on each of the selected domains
+type DomainBatchUpdateRequest struct {
+	DomainIDs []int   `json:"domain_ids" binding:"required,min=1"`
+	Active    *bool   `json:"active"`
+	Interval  *int    `json:"interval"` // Interval in minutes
+	Region    *string `json:"region"`   // Moves the domains to this primary region
+}
+
+// DomainBatchUpdateResponse represents the response for a batch domain update operation
+type DomainBatchUpdateResponse struct {
+	Success      []DomainUpdateResult `json:"success"`
+	Failed       []DomainUpdateResult `json:"failed"`
+	UpdatedCount int                  `json:"updated_count"`
+	TotalCount   int                  `json:"total_count"`
+}
+
+// DomainUpdateResult represents the result for a single domain in batch update operation
+type DomainUpdateResult struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Reason string `json:"reason,omitempty"` // Only present for failed updates
+}
+
+// DomainTransferRequest moves domains of one user to another
+type DomainTransferRequest struct {
+	FromUserID int   `json:"from_user_id" binding:"required"`
+	ToUserID   int   `json:"to_user_id" binding:"required"`
+	DomainIDs  []int `json:"domain_ids" binding:"required,min=1"`
+}
+
+// DomainTransferResponse lists the domains moved by a transfer
+type DomainTransferResponse struct {
+	FromUserID int                    `json:"from_user_id"`
+	ToUserID   int                    `json:"to_user_id"`
+	Domains    []DomainTransferResult `json:"domains"`
+}
+
+// DomainTransferResult is a domain moved by a transfer
+type DomainTransferResult struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// DomainSummary is a lightweight per-user overview for the dashboard header
+type DomainSummary struct {
+	TotalDomains    int `json:"total_domains" db:"total_domains"`
+	ActiveDomains   int `json:"active_domains" db:"active_domains"`
+	DownDomains     int `json:"down_domains" db:"down_domains"`
+	ArchivedDomains int `json:"archived_domains" db:"archived_domains"` // Not counted in total_domains
+	DomainLimit     int `json:"domain_limit" db:"domain_limit"`
+}
+
+// Domain import row statuses
+const (
+	ImportRowAdded  = "added"
+	ImportRowFailed = "failed"
+)
+
+// DomainImportRowResult is the outcome of a single data row of an imported CSV file
+type DomainImportRowResult struct {
+	Row      int    `json:"row"` // 1-based line number in the file, the header being line 1
+	Name     string `json:"name"`
+	Region   string `json:"region"`
+	Interval int    `json:"interval,omitempty"`
+	Status   string `json:"status"`
+	ID       int    `json:"id,omitempty"`     // Only set for added domains
+	Reason   string `json:"reason,omitempty"` // Only set for failed rows
+}
+
+// DomainImportResponse reports the outcome of every row of a CSV import
+type DomainImportResponse struct {
+	Total  int                     `json:"total"`
+	Added  int                     `json:"added"`
+	Failed int                     `json:"failed"`
+	Rows   []DomainImportRowResult `json:"rows"`
+}
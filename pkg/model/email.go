@@ -4,17 +4,33 @@ import "time"
 
 // EmailConfig represents a user's email notification configuration
 type EmailConfig struct {
-	ID             int       `json:"id" db:"id"`
-	UserID         int       `json:"user_id" db:"user_id"`
-	EmailAddress   string    `json:"email_address" db:"email_address"`
-	EmailName      string    `json:"email_name" db:"email_name"`
-	Language       string    `json:"language" db:"language"`
-	IsActive       bool      `json:"is_active" db:"is_active"`
-	NotifyOnDown   bool      `json:"notify_on_down" db:"notify_on_down"`
-	NotifyOnUp     bool      `json:"notify_on_up" db:"notify_on_up"`
-	MonitorRegions []string  `json:"monitor_regions"`
-	CreatedAt      time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+	ID             int        `json:"id" db:"id"`
+	UserID         int        `json:"user_id" db:"user_id"`
+	EmailAddress   string     `json:"email_address" db:"email_address"`
+	EmailName      string     `json:"email_name" db:"email_name"`
+	Language       string     `json:"language" db:"language"`
+	IsActive       bool       `json:"is_active" db:"is_active"`
+	NotifyOnDown   bool       `json:"notify_on_down" db:"notify_on_down"`
+	NotifyOnUp     bool       `json:"notify_on_up" db:"notify_on_up"`
+	MonitorRegions []string   `json:"monitor_regions"`
+	Tags           []string   `json:"tags"`                   // Domain tags subscribed to; empty means all domains
+	Timezone       *string    `json:"timezone" db:"timezone"` // nil uses the user's timezone
+	ExternalID     *string    `json:"external_id,omitempty" db:"external_id"`
+	Status         string     `json:"status" db:"status"` // EmailConfigPending until the address is confirmed
+	VerifiedAt     *time.Time `json:"verified_at" db:"verified_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Email config states. Pending configs are never notified.
+const (
+	EmailConfigPending  = "pending"
+	EmailConfigVerified = "verified"
+)
+
+// EmailVerificationRequest confirms an email config with the code mailed to it
+type EmailVerificationRequest struct {
+	Code string `json:"code" binding:"required"`
 }
 
 // EmailConfigRequest represents a request to add/update email configuration
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Email report frequencies
+const (
+	EmailReportWeekly  = "weekly"  // Monday to Monday in the config's timezone
+	EmailReportMonthly = "monthly" // First to first of the month in the config's timezone
+)
+
+// EmailReportSettings configures the recurring summary report of an email config
+type EmailReportSettings struct {
+	ConfigID      int            `json:"config_id" db:"config_id"`
+	Enabled       bool           `json:"enabled" db:"enabled"`
+	Frequency     string         `json:"frequency" db:"frequency"`
+	Regions       pq.StringArray `json:"regions" db:"regions"` // Empty means all regions
+	LastSentAt    *time.Time     `json:"last_sent_at" db:"last_sent_at"`
+	LastPeriodEnd *time.Time     `json:"last_period_end" db:"last_period_end"`
+}
+
+// EmailReportSettingsRequest represents a request to update the report of an email config
+type EmailReportSettingsRequest struct {
+	Enabled   bool     `json:"enabled"`
+	Frequency string   `json:"frequency" binding:"omitempty,oneof=weekly monthly"`
+	Regions   []string `json:"regions"`
+}
+
+// EmailReport is the summary of one report period
+type EmailReport struct {
+	Frequency         string                  `json:"frequency"`
+	PeriodStart       time.Time               `json:"period_start"`
+	PeriodEnd         time.Time               `json:"period_end"`
+	Domains           []EmailReportDomain     `json:"domains"`
+	IncidentsOpened   int                     `json:"incidents_opened"`
+	IncidentsResolved int                     `json:"incidents_resolved"`
+	LongestIncidents  []EmailReportIncident   `json:"longest_incidents"`
+	SlowestRegions    []EmailReportRegionTime `json:"slowest_regions"`
+}
+
+// EmailReportDomain is the uptime of a domain over a report period
+type EmailReportDomain struct {
+	DomainID      int      `json:"domain_id" db:"domain_id"`
+	Name          string   `json:"name" db:"name"`
+	Region        string   `json:"region" db:"region"`
+	Checks        int      `json:"checks" db:"checks"`
+	UptimePercent *float64 `json:"uptime_percent" db:"uptime_percent"` // nil without checks
+	Incidents     int      `json:"incidents" db:"incidents"`
+}
+
+// EmailReportIncident is an incident opened during a report period
+type EmailReportIncident struct {
+	DomainName      string     `json:"domain_name" db:"domain_name"`
+	OpenedAt        time.Time  `json:"opened_at" db:"opened_at"`
+	ResolvedAt      *time.Time `json:"resolved_at" db:"resolved_at"`
+	DurationSeconds int        `json:"duration_seconds" db:"duration_seconds"` // Up to the period end while unresolved
+}
+
+// EmailReportRegionTime is the average response time of a region over a report period
+type EmailReportRegionTime struct {
+	Region          string  `json:"region" db:"region"`
+	AvgResponseTime float64 `json:"avg_response_time" db:"avg_response_time"` // Milliseconds, successful checks only
+	Checks          int     `json:"checks" db:"checks"`
+}
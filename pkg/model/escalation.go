@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// EscalationRule alerts an extra notification config when an incident stays open for
+// AfterMinutes. Rules with the same level fire together.
+type EscalationRule struct {
+	ID           int       `json:"id" db:"id"`
+	UserID       int       `json:"user_id" db:"user_id"`
+	Level        int       `json:"level" db:"level"`
+	AfterMinutes int       `json:"after_minutes" db:"after_minutes"`
+	Channel      string    `json:"channel" db:"channel"`
+	ConfigID     int       `json:"config_id" db:"config_id"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// EscalationRuleRequest represents a request to add an escalation rule
+type EscalationRuleRequest struct {
+	Level        int    `json:"level" binding:"required,min=1,max=5"`
+	AfterMinutes int    `json:"after_minutes" binding:"required,min=1,max=10080"`
+	Channel      string `json:"channel" binding:"required,oneof=telegram email"`
+	ConfigID     int    `json:"config_id" binding:"required"`
+}
@@ -0,0 +1,90 @@
+package model
+
+import "time"
+
+// Datasets that can be exported to a customer SFTP server
+const (
+	ExportDatasetIncidents     = "incidents"
+	ExportDatasetChecks        = "checks"
+	ExportDatasetNotifications = "notifications"
+)
+
+// Export delivery statuses
+const (
+	ExportStatusPending   = "pending"
+	ExportStatusDelivered = "delivered"
+	ExportStatusFailed    = "failed" // Gave up after the maximum number of attempts
+)
+
+// ExportTarget is a customer SFTP server receiving a daily dump of the selected datasets.
+// Credentials are never returned.
+type ExportTarget struct {
+	ID                 int       `json:"id" db:"id"`
+	UserID             int       `json:"user_id" db:"user_id"`
+	Name               string    `json:"name" db:"name"`
+	Host               string    `json:"host" db:"host"`
+	Port               int       `json:"port" db:"port"`
+	Username           string    `json:"username" db:"username"`
+	HasPassword        bool      `json:"has_password" db:"has_password"`
+	HasPrivateKey      bool      `json:"has_private_key" db:"has_private_key"`
+	HostKeyFingerprint string    `json:"host_key_fingerprint" db:"host_key_fingerprint"`
+	RemoteDir          string    `json:"remote_dir" db:"remote_dir"`
+	Datasets           []string  `json:"datasets" db:"-"`
+	DeliveryHour       int       `json:"delivery_hour" db:"delivery_hour"` // UTC
+	IsActive           bool      `json:"is_active" db:"is_active"`
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ExportTargetRequest represents a request to add or update an export target. On update,
+// empty credentials keep the stored ones.
+type ExportTargetRequest struct {
+	Name               string   `json:"name" binding:"required"`
+	Host               string   `json:"host" binding:"required"`
+	Port               int      `json:"port" binding:"omitempty,min=1,max=65535"`
+	Username           string   `json:"username" binding:"required"`
+	Password           string   `json:"password"`
+	PrivateKey         string   `json:"private_key"`
+	HostKeyFingerprint string   `json:"host_key_fingerprint" binding:"required"` // SHA256 fingerprint as printed by ssh-keygen -l
+	RemoteDir          string   `json:"remote_dir"`
+	Datasets           []string `json:"datasets" binding:"required,min=1,dive,oneof=incidents checks notifications"`
+	DeliveryHour       int      `json:"delivery_hour" binding:"min=0,max=23"`
+	IsActive           *bool    `json:"is_active"`
+}
+
+// ExportDelivery is one exported file and its delivery state
+type ExportDelivery struct {
+	ID            int        `json:"id" db:"id"`
+	TargetID      int        `json:"target_id" db:"target_id"`
+	Dataset       string     `json:"dataset" db:"dataset"`
+	PeriodStart   time.Time  `json:"period_start" db:"period_start"`
+	PeriodEnd     time.Time  `json:"period_end" db:"period_end"`
+	FileName      string     `json:"file_name" db:"file_name"`
+	Status        string     `json:"status" db:"status"`
+	Attempts      int        `json:"attempts" db:"attempts"`
+	RowsExported  int        `json:"rows_exported" db:"rows_exported"`
+	LastError     *string    `json:"last_error,omitempty" db:"last_error"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty" db:"next_attempt_at"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty" db:"delivered_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Account export statuses
+const (
+	AccountExportPending = "pending"
+	AccountExportReady   = "ready" // The download link was mailed to the user
+	AccountExportFailed  = "failed"
+	AccountExportExpired = "expired" // The link expired and the archive was dropped
+)
+
+// AccountExport is an archive of all data of a user, built in the background. The
+// archive itself is only available through the link mailed to the user.
+type AccountExport struct {
+	ID          int        `json:"id" db:"id"`
+	Status      string     `json:"status" db:"status"`
+	SizeBytes   int        `json:"size_bytes" db:"size_bytes"`
+	LastError   *string    `json:"last_error,omitempty" db:"last_error"`
+	RequestedAt time.Time  `json:"requested_at" db:"requested_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" db:"expires_at"` // When the download link stops working
+}
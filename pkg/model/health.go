@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// Health statuses of a component and of the whole service
+const (
+	HealthOK       = "ok"
+	HealthDown     = "down"
+	HealthDisabled = "disabled" // Not configured, e.g. no Site24x7 credentials
+	HealthDegraded = "degraded" // Only non-critical components are down
+)
+
+// HealthComponent is the state of one dependency of the service
+type HealthComponent struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Critical  bool      `json:"critical"` // A critical component that is down makes the service not ready
+	LatencyMs int64     `json:"latency_ms"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// HealthReport is the response of the health and readiness probes
+type HealthReport struct {
+	Status     string            `json:"status"`
+	Components []HealthComponent `json:"components"`
+	CheckedAt  time.Time         `json:"checked_at"`
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// DomainCheckHistory is a single recorded check of a domain
+type DomainCheckHistory struct {
+	ID               int64     `json:"id" db:"id"`
+	DomainID         int       `json:"domain_id" db:"domain_id"`
+	Available        bool      `json:"available" db:"available"`
+	StatusCode       int       `json:"status_code" db:"status_code"`
+	ErrorCode        int       `json:"error_code" db:"error_code"`
+	TotalTime        int       `json:"total_time" db:"total_time"`
+	ErrorDescription string    `json:"error_description" db:"error_description"`
+	CheckedAt        time.Time `json:"checked_at" db:"checked_at"`
+
+	DissentingProviders []string `json:"dissenting_providers" db:"-"` // Providers that disagreed with the result
+}
+
+// DomainHistoryResponse is the status timeline of a domain. The counts and uptime
+// cover the whole requested range, not only the returned checks. Checks skipped by
+// load shedding are reported separately and do not count towards uptime.
+type DomainHistoryResponse struct {
+	DomainID         int                  `json:"domain_id"`
+	From             *time.Time           `json:"from,omitempty"`
+	To               *time.Time           `json:"to,omitempty"`
+	TotalChecks      int                  `json:"total_checks"`
+	FailedChecks     int                  `json:"failed_checks"`
+	SkippedChecks    int                  `json:"skipped_checks"`
+	UptimePercentage float64              `json:"uptime_percentage"`
+	History          []DomainCheckHistory `json:"history"`
+}
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// HTTP methods supported by every monitoring provider
+const (
+	HTTPMethodGet  = "GET"
+	HTTPMethodPost = "POST"
+)
+
+// HTTPCheckSettings configures how the monitoring providers request a domain. The basic
+// auth password is stored encrypted and never returned by the API.
+type HTTPCheckSettings struct {
+	HTTPMethod          string      `json:"http_method" db:"http_method"`
+	HTTPHeaders         HTTPHeaders `json:"http_headers" db:"http_headers"`
+	BasicAuthUsername   string      `json:"basic_auth_username" db:"basic_auth_username"`
+	BasicAuthPassword   string      `json:"-" db:"basic_auth_password"`
+	ExpectedStatusCodes StatusCodes `json:"expected_status_codes" db:"expected_status_codes"` // Empty accepts any 2xx/3xx status
+	FollowRedirects     bool        `json:"follow_redirects" db:"follow_redirects"`
+}
+
+// DefaultHTTPCheckSettings returns the settings of a domain that has not customized its
+// checks
+func DefaultHTTPCheckSettings() HTTPCheckSettings {
+	return HTTPCheckSettings{HTTPMethod: HTTPMethodGet, FollowRedirects: true}
+}
+
+// HTTPCheckRequest changes the HTTP check settings of a domain. Nil fields are left
+// unchanged; an empty header map, status code list or password clears the value.
+type HTTPCheckRequest struct {
+	HTTPMethod          *string           `json:"http_method" binding:"omitempty,oneof=GET POST"`
+	HTTPHeaders         map[string]string `json:"http_headers" binding:"omitempty,max=20"`
+	BasicAuthUsername   *string           `json:"basic_auth_username" binding:"omitempty,max=255"`
+	BasicAuthPassword   *string           `json:"basic_auth_password" binding:"omitempty,max=255"`
+	ExpectedStatusCodes []int             `json:"expected_status_codes" binding:"omitempty,max=10,dive,min=100,max=599"`
+	FollowRedirects     *bool             `json:"follow_redirects"`
+}
+
+// IsSet reports whether the request changes any setting
+func (r HTTPCheckRequest) IsSet() bool {
+	return r.HTTPMethod != nil || r.HTTPHeaders != nil || r.BasicAuthUsername != nil ||
+		r.BasicAuthPassword != nil || r.ExpectedStatusCodes != nil || r.FollowRedirects != nil
+}
+
+// HTTPHeaders represents the JSONB custom request headers of a domain
+type HTTPHeaders map[string]string
+
+// Value implements the driver.Valuer interface for database storage
+func (h HTTPHeaders) Value() (driver.Value, error) {
+	if h == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(h)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (h *HTTPHeaders) Scan(value interface{}) error {
+	return scanJSON(value, h)
+}
+
+// StatusCodes represents the JSONB expected status codes of a domain
+type StatusCodes []int
+
+// Value implements the driver.Valuer interface for database storage
+func (c StatusCodes) Value() (driver.Value, error) {
+	if c == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(c)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (c *StatusCodes) Scan(value interface{}) error {
+	return scanJSON(value, c)
+}
+
+// scanJSON decodes a JSON or JSONB column into dest
+// scanJSON decodes a JSON or JSONB column into dest
+func scanJSON(value interface{}, dest interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, dest)
+	case string:
+		return json.Unmarshal([]byte(v), dest)
+	}
+	return fmt.Errorf("unsupported JSON value type %T", value)
+}
@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+// Incident is one outage of a domain, from the first failed check until recovery
+type Incident struct {
+	ID              int        `json:"id" db:"id"`
+	DomainID        int        `json:"domain_id" db:"domain_id"`
+	UserID          int        `json:"user_id" db:"user_id"`
+	DomainName      string     `json:"domain_name" db:"domain_name"`
+	OpenedAt        time.Time  `json:"opened_at" db:"opened_at"`
+	ResolvedAt      *time.Time `json:"resolved_at" db:"resolved_at"`
+	DurationSeconds *int       `json:"duration_seconds" db:"duration_seconds"` // Set once resolved
+	FailureCount    int        `json:"failure_count" db:"failure_count"`       // Failed checks attached to the incident
+	FirstStatus     int        `json:"first_status" db:"first_status"`
+	LastStatus      int        `json:"last_status" db:"last_status"`
+	LastError       string     `json:"last_error" db:"last_error"`
+	LastFailureAt   time.Time  `json:"last_failure_at" db:"last_failure_at"`
+
+	// Acknowledging an open incident pauses its down alerts and escalations
+	AcknowledgedAt    *time.Time `json:"acknowledged_at" db:"acknowledged_at"`
+	AcknowledgedBy    string     `json:"acknowledged_by" db:"acknowledged_by"`
+	AcknowledgedUntil *time.Time `json:"acknowledged_until" db:"acknowledged_until"`
+}
+
+// IsOpen reports whether the domain is still down
+func (i Incident) IsOpen() bool {
+	return i.ResolvedAt == nil
+}
+
+// Acknowledged reports whether the incident's alerts are paused at now
+func (i Incident) Acknowledged(now time.Time) bool {
+	return i.IsOpen() && i.AcknowledgedUntil != nil && now.Before(*i.AcknowledgedUntil)
+}
+
+// Duration returns how long the incident lasted, or has lasted so far
+func (i Incident) Duration() time.Duration {
+	if i.DurationSeconds != nil {
+		return time.Duration(*i.DurationSeconds) * time.Second
+	}
+	return time.Since(i.OpenedAt)
+}
+
+// Incident status filters
+const (
+	IncidentStatusOpen     = "open"
+	IncidentStatusResolved = "resolved"
+)
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// labelKeyPattern matches a normalized label key, e.g. "merchant"
+var labelKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,49}$`)
+
+// maxLabels and maxLabelValueLength bound the labels of a domain
+const (
+	maxLabels           = 20
+	maxLabelValueLength = 200
+)
+
+// LabelError is returned for labels or label filters that are not valid
+type LabelError struct {
+	Subject string // What is invalid, e.g. `label "merchant"`
+	Reason  string
+}
+
+func (e *LabelError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Subject, e.Reason)
+}
+
+// DomainLabels represents the JSONB key/value labels of a domain, e.g. merchant=acme
+type DomainLabels map[string]string
+
+// Value implements the driver.Valuer interface for database storage
+func (l DomainLabels) Value() (driver.Value, error) {
+	if l == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(l)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (l *DomainLabels) Scan(value interface{}) error {
+	return scanJSON(value, l)
+}
+
+// NormalizeLabels lowercases and trims label keys and trims values. Labels with an
+// empty value are dropped.
+func NormalizeLabels(labels DomainLabels) (DomainLabels, error) {
+	normalized := DomainLabels{}
+	for key, value := range labels {
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if !labelKeyPattern.MatchString(key) {
+			return nil, &LabelError{Subject: fmt.Sprintf("label %q", key), Reason: "use up to 50 letters, digits, '.', '_' or '-' as key"}
+		}
+		if len(value) > maxLabelValueLength {
+			return nil, &LabelError{Subject: fmt.Sprintf("label %q", key), Reason: fmt.Sprintf("value is longer than %d characters", maxLabelValueLength)}
+		}
+		if value != "" {
+			normalized[key] = value
+		}
+	}
+	if len(normalized) > maxLabels {
+		return nil, &LabelError{Subject: "label set", Reason: fmt.Sprintf("at most %d labels are allowed", maxLabels)}
+	}
+	return normalized, nil
+}
+
+// ParseLabelFilters parses "key:value" filters, as given in the label query parameter
+func ParseLabelFilters(filters []string) (DomainLabels, error) {
+	labels := DomainLabels{}
+	for _, filter := range filters {
+		key, value, ok := strings.Cut(filter, ":")
+		if !ok {
+			return nil, &LabelError{Subject: fmt.Sprintf("label filter %q", filter), Reason: "use key:value"}
+		}
+		labels[key] = value
+	}
+	return NormalizeLabels(labels)
+}
@@ -0,0 +1,16 @@
+package model
+
+// LanguageFallbacks is the language fallback chain of a notification config
+type LanguageFallbacks struct {
+	Channel   string   `json:"channel"`
+	ConfigID  int      `json:"config_id"`
+	Language  string   `json:"language"`  // The config's own language, always tried first
+	Fallbacks []string `json:"fallbacks"` // Tried in order after Language
+	Chain     []string `json:"chain"`     // The full chain applied when rendering messages
+}
+
+// LanguageFallbacksRequest represents a request to set the fallback chain of a config.
+// An empty list restores the default of falling back straight to English.
+type LanguageFallbacksRequest struct {
+	Fallbacks []string `json:"fallbacks" binding:"max=5,dive,min=2,max=10"`
+}
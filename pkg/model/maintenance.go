@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+// Maintenance window recurrences
+const (
+	RecurrenceNone   = "none"
+	RecurrenceDaily  = "daily"
+	RecurrenceWeekly = "weekly"
+)
+
+// MaintenanceWindow is planned maintenance during which status alerts are suppressed.
+// A nil DomainID covers every domain of the user.
+type MaintenanceWindow struct {
+	ID         int       `json:"id" db:"id"`
+	UserID     int       `json:"user_id" db:"user_id"`
+	DomainID   *int      `json:"domain_id" db:"domain_id"`
+	StartsAt   time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt     time.Time `json:"ends_at" db:"ends_at"`
+	Recurrence string    `json:"recurrence" db:"recurrence"`
+	Reason     string    `json:"reason" db:"reason"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// RecurrencePeriod returns how often the window repeats, or 0 if it does not
+func (w MaintenanceWindow) RecurrencePeriod() time.Duration {
+	switch w.Recurrence {
+	case RecurrenceDaily:
+		return 24 * time.Hour
+	case RecurrenceWeekly:
+		return 7 * 24 * time.Hour
+	}
+	return 0
+}
+
+// ActiveAt reports whether t falls inside the window or one of its repetitions.
+// Repetitions are a fixed period apart, so they do not follow DST changes.
+func (w MaintenanceWindow) ActiveAt(t time.Time) bool {
+	if t.Before(w.StartsAt) {
+		return false
+	}
+	start := w.StartsAt
+	if period := w.RecurrencePeriod(); period > 0 {
+		start = start.Add(t.Sub(w.StartsAt) / period * period)
+	}
+	return t.Before(start.Add(w.EndsAt.Sub(w.StartsAt)))
+}
+
+// MaintenanceWindowRequest represents a request to schedule maintenance
+type MaintenanceWindowRequest struct {
+	StartsAt   time.Time `json:"starts_at" binding:"required"`
+	EndsAt     time.Time `json:"ends_at" binding:"required"`
+	Recurrence string    `json:"recurrence" binding:"omitempty,oneof=none daily weekly"`
+	Reason     string    `json:"reason" binding:"max=500"`
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// monitorNamePattern matches names produced by MonitorTags.MonitorName
+var monitorNamePattern = regexp.MustCompile(`^DDG\[([^\]]+)\] u(\d+) d(\d+) - `)
+
+// MonitorTags identifies who owns a provider-side monitor
+type MonitorTags struct {
+	UserID      int    `json:"user_id"`
+	DomainID    int    `json:"domain_id"`
+	Environment string `json:"environment"`
+}
+
+// MonitorName builds the structured provider-side name, e.g. "DDG[production] u12 d345 - example.com"
+func (t MonitorTags) MonitorName(host string) string {
+	return fmt.Sprintf("DDG[%s] u%d d%d - %s", t.Environment, t.UserID, t.DomainID, host)
+}
+
+// Fields returns the tags as provider custom field key/value pairs
+func (t MonitorTags) Fields() map[string]string {
+	return map[string]string{
+		"ddg_user_id":     strconv.Itoa(t.UserID),
+		"ddg_domain_id":   strconv.Itoa(t.DomainID),
+		"ddg_environment": t.Environment,
+	}
+}
+
+// ParseMonitorName extracts ownership from a structured monitor name.
+// Returns false for monitors not created by this service (e.g. legacy "Domain Check - host" names).
+func ParseMonitorName(name string) (MonitorTags, bool) {
+	match := monitorNamePattern.FindStringSubmatch(name)
+	if match == nil {
+		return MonitorTags{}, false
+	}
+
+	userID, _ := strconv.Atoi(match[2])
+	domainID, _ := strconv.Atoi(match[3])
+	return MonitorTags{UserID: userID, DomainID: domainID, Environment: match[1]}, true
+}
+
+// Monitoring providers a domain can have monitors on
+const (
+	ProviderUptrends = "uptrends"
+	ProviderSite24x7 = "site24x7"
+	ProviderDirect   = "direct" // Built-in HTTP check from the application servers
+)
+
+// MonitorRecreateRequest selects the providers whose monitors are recreated.
+// An empty list recreates the monitors on every configured provider.
+type MonitorRecreateRequest struct {
+	Providers []string `json:"providers" binding:"omitempty,dive,oneof=uptrends site24x7"`
+}
+
+// MonitorRecreateResult is the outcome of recreating the monitor on one provider
+type MonitorRecreateResult struct {
+	Provider  string `json:"provider"`
+	OldID     string `json:"old_id,omitempty"`
+	NewID     string `json:"new_id,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Recreated bool   `json:"recreated"`
+}
+
+// MonitorRecreateResponse is returned by POST /api/domains/:id/monitors/recreate
+type MonitorRecreateResponse struct {
+	DomainID int                     `json:"domain_id"`
+	Results  []MonitorRecreateResult `json:"results"`
+}
+
+// MonitorSyncResult is returned by POST /api/admin/monitors/sync
+type MonitorSyncResult struct {
+	Domains int      `json:"domains"` // Domains with at least one provider monitor
+	Synced  int      `json:"synced"`  // Monitors whose active flag was set
+	Failed  int      `json:"failed"`
+	Errors  []string `json:"errors,omitempty"`
+}
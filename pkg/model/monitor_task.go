@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// Monitor task actions
+const (
+	MonitorTaskCreate = "create"
+	MonitorTaskDelete = "delete"
+)
+
+// Monitor task statuses. Tasks are removed once they succeed.
+const (
+	MonitorTaskPending = "pending" // Waiting for its next attempt
+	MonitorTaskFailed  = "failed"  // Gave up after the maximum number of attempts
+)
+
+// MonitorTask is a provider monitor creation or deletion that failed and is retried
+// in the background
+type MonitorTask struct {
+	ID            int       `json:"id" db:"id"`
+	UserID        int       `json:"-" db:"user_id"`
+	DomainID      int       `json:"domain_id" db:"domain_id"`
+	Provider      string    `json:"provider" db:"provider"`
+	Action        string    `json:"action" db:"action"`
+	MonitorID     *string   `json:"monitor_id,omitempty" db:"monitor_id"`
+	Status        string    `json:"status" db:"status"`
+	Attempts      int       `json:"attempts" db:"attempts"`
+	LastError     *string   `json:"last_error,omitempty" db:"last_error"`
+	NextAttemptAt time.Time `json:"next_attempt_at" db:"next_attempt_at"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"slices"
+
+	"github.com/lib/pq"
+)
+
+// Notification channels supported by batch provisioning
+const (
+	ChannelTelegram = "telegram"
+	ChannelEmail    = "email"
+)
+
+// AllChannels lists every notification channel
+var AllChannels = []string{ChannelTelegram, ChannelEmail}
+
+// DomainChannels returns the distinct channels a domain alerts through. Nil is returned
+// when every channel is chosen, so that channels added later alert as well.
+func DomainChannels(channels []string) pq.StringArray {
+	chosen := pq.StringArray{}
+	for _, channel := range AllChannels {
+		if slices.Contains(channels, channel) {
+			chosen = append(chosen, channel)
+		}
+	}
+	if len(chosen) == len(AllChannels) {
+		return nil
+	}
+	return chosen
+}
+
+// NotificationConfigBatchItem is a single channel config in a batch request.
+// Only the fields relevant to the item's channel are used.
+type NotificationConfigBatchItem struct {
+	Channel        string   `json:"channel"`
+	ChatID         string   `json:"chat_id"`       // Telegram
+	EmailAddress   string   `json:"email_address"` // Email
+	Name           string   `json:"name"`          // Chat name or recipient name
+	Language       string   `json:"language"`
+	NotifyOnDown   bool     `json:"notify_on_down"`
+	NotifyOnUp     bool     `json:"notify_on_up"`
+	IsActive       bool     `json:"active"`
+	MonitorRegions []string `json:"monitor_regions"`
+}
+
+// NotificationConfigBatchRequest represents a batch request to provision notification configs
+type NotificationConfigBatchRequest struct {
+	Configs []NotificationConfigBatchItem `json:"configs" binding:"required,min=1,max=200"`
+}
+
+// NotificationConfigBatchResult represents the result for a single config in a batch
+type NotificationConfigBatchResult struct {
+	Index   int      `json:"index"`
+	Channel string   `json:"channel"`
+	Target  string   `json:"target"`           // Chat ID or email address
+	ID      int      `json:"id,omitempty"`     // Only set for created configs
+	Errors  []string `json:"errors,omitempty"` // Only set for failed configs
+}
+
+// NotificationConfigBatchResponse represents the response for a batch provisioning request
+type NotificationConfigBatchResponse struct {
+	Success []NotificationConfigBatchResult `json:"success"`
+	Failed  []NotificationConfigBatchResult `json:"failed"`
+	Added   int                             `json:"added"`
+	Total   int                             `json:"total"`
+}
@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// Delivery outcomes of a notification
+const (
+	DeliverySent   = "sent"
+	DeliveryFailed = "failed"
+)
+
+// NotificationHistoryEntry is one notification sent, or attempted, to a config
+type NotificationHistoryEntry struct {
+	ID               int        `json:"id" db:"id"`
+	DomainID         int        `json:"domain_id" db:"domain_id"`
+	DomainName       string     `json:"domain_name" db:"domain_name"`
+	Channel          string     `json:"channel" db:"channel"`
+	ConfigID         int        `json:"config_id" db:"config_id"`
+	Type             string     `json:"type" db:"notification_type"`
+	StatusCode       int        `json:"status_code" db:"status_code"`
+	ErrorCode        *int       `json:"error_code,omitempty" db:"error_code"`
+	ErrorDescription *string    `json:"error_description,omitempty" db:"error_description"`
+	IncidentID       *int       `json:"incident_id,omitempty" db:"incident_id"`
+	DeliveryStatus   string     `json:"delivery_status" db:"delivery_status"`
+	DeliveryError    *string    `json:"delivery_error,omitempty" db:"delivery_error"`
+	MessageID        *string    `json:"message_id,omitempty" db:"message_id"` // Telegram message ID or email Message-ID
+	Attempts         int        `json:"attempts" db:"attempts"`
+	NotifiedAt       time.Time  `json:"notified_at" db:"notified_at"`
+	LastAttemptAt    *time.Time `json:"last_attempt_at,omitempty" db:"last_attempt_at"`
+}
+
+// NotificationHistoryFilter narrows the notification history; zero values match everything
+type NotificationHistoryFilter struct {
+	DomainID       int
+	Channel        string
+	Type           string
+	DeliveryStatus string
+	From           *time.Time
+	To             *time.Time
+	Limit          int
+}
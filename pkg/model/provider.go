@@ -0,0 +1,72 @@
+package model
+
+import "time"
+
+// ProviderSelection is the set of monitoring providers a domain or user is checked by.
+// How their results combine is set by the domain's availability strategy.
+type ProviderSelection struct {
+	Providers []string `json:"providers"`
+	Available []string `json:"available"` // Providers configured on this deployment
+	Inherited bool     `json:"inherited"` // The selection comes from a default rather than being set here
+}
+
+// ProviderSelectionRequest replaces a provider selection. An empty list restores the
+// default: the user's selection for a domain, every configured provider for a user.
+type ProviderSelectionRequest struct {
+	Providers []string `json:"providers" binding:"max=10"`
+}
+
+// ProviderCall is one request sent to a provider API
+type ProviderCall struct {
+	Provider   string
+	Method     string
+	Endpoint   string // Path with IDs replaced by ":id"
+	StatusCode int    // 0 for connection errors
+	Latency    time.Duration
+	CalledAt   time.Time
+}
+
+// ProviderUsageDay is the number of requests made to a provider API on one day (UTC)
+type ProviderUsageDay struct {
+	Day          time.Time `json:"day" db:"day"`
+	Calls        int       `json:"calls" db:"calls"`
+	Failures     int       `json:"failures" db:"failures"`   // Connection errors and 5xx responses
+	Throttled    int       `json:"throttled" db:"throttled"` // 429 responses
+	AvgLatencyMs int       `json:"avg_latency_ms" db:"avg_latency_ms"`
+}
+
+// ProviderEndpointUsage is the number of requests made to one endpoint of a provider API
+type ProviderEndpointUsage struct {
+	Method       string `json:"method" db:"method"`
+	Endpoint     string `json:"endpoint" db:"endpoint"`
+	Calls        int    `json:"calls" db:"calls"`
+	Failures     int    `json:"failures" db:"failures"`
+	AvgLatencyMs int    `json:"avg_latency_ms" db:"avg_latency_ms"`
+}
+
+// ProviderUsage reports the API usage of one provider and the projected usage of the
+// current month against its quota
+type ProviderUsage struct {
+	Provider            string                  `json:"provider"`
+	MonthToDateCalls    int                     `json:"month_to_date_calls"`
+	ProjectedMonthCalls int                     `json:"projected_month_calls"` // Month to date extrapolated to the whole month
+	MonthlyQuota        int                     `json:"monthly_quota"`         // 0 when no quota is configured
+	ProjectedQuotaUsed  *float64                `json:"projected_quota_used"`  // Percent of the quota, nil without a quota
+	Days                []ProviderUsageDay      `json:"days"`
+	Endpoints           []ProviderEndpointUsage `json:"endpoints"` // Over the same days, busiest first
+}
+
+// ProviderClientStats reports the requests made to a provider API since startup
+type ProviderClientStats struct {
+	Provider         string     `json:"provider"`
+	Requests         int        `json:"requests"`
+	Failures         int        `json:"failures"`  // Connection errors and 5xx responses
+	Throttled        int        `json:"throttled"` // 429 responses
+	Retries          int        `json:"retries"`
+	Rejected         int        `json:"rejected"` // Requests failed fast while the circuit was open
+	InFlight         int        `json:"in_flight"`
+	MaxConcurrent    int        `json:"max_concurrent"`
+	AvgLatencyMs     int        `json:"avg_latency_ms"`
+	ThrottledUntil   *time.Time `json:"throttled_until,omitempty"`
+	CircuitOpenUntil *time.Time `json:"circuit_open_until,omitempty"`
+}
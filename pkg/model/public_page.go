@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// Public status page domain states
+const (
+	PublicStatusUp      = "up"
+	PublicStatusDown    = "down"
+	PublicStatusUnknown = "unknown" // Not checked yet
+)
+
+// PublicPageSettings is the hosted status page configuration of a user
+type PublicPageSettings struct {
+	Enabled   bool       `json:"enabled" db:"is_enabled"`
+	Slug      string     `json:"slug" db:"slug"` // Page is served at /status/:slug
+	Title     string     `json:"title" db:"title"`
+	DomainIDs []int      `json:"domain_ids" db:"-"` // Listed domains, in display order
+	UpdatedAt *time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// PublicPageRequest replaces the hosted status page configuration. An empty slug
+// keeps the current one, or generates one for a new page.
+type PublicPageRequest struct {
+	Enabled   bool   `json:"enabled"`
+	Slug      string `json:"slug" binding:"omitempty,min=3,max=64"`
+	Title     string `json:"title" binding:"max=100"`
+	DomainIDs []int  `json:"domain_ids" binding:"max=100"`
+}
+
+// PublicStatusPage is what unauthenticated visitors see. It only exposes domain
+// names and states, never check details.
+type PublicStatusPage struct {
+	Title       string               `json:"title"`
+	AllUp       bool                 `json:"all_up"`
+	Domains     []PublicDomainStatus `json:"domains"`
+	GeneratedAt time.Time            `json:"generated_at"`
+}
+
+// PublicDomainStatus is the state of one domain on a public status page
+type PublicDomainStatus struct {
+	Name      string     `json:"name"`
+	Status    string     `json:"status"`
+	Uptime30d *float64   `json:"uptime_30d"` // Nil without checks in the window
+	LastCheck *time.Time `json:"last_check"`
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// QuietHours holds back non-critical notifications of a config during a daily period
+type QuietHours struct {
+	Channel       string `json:"channel"`
+	ConfigID      int    `json:"config_id"`
+	Enabled       bool   `json:"enabled"`
+	Timezone      string `json:"timezone"`   // IANA name, e.g. "Europe/London"
+	StartTime     string `json:"start_time"` // "HH:MM"
+	EndTime       string `json:"end_time"`   // "HH:MM", before StartTime when the period spans midnight
+	Days          []int  `json:"days"`       // Weekdays the period starts on, 0 = Sunday; empty means every day
+	AllowCritical bool   `json:"allow_critical"`
+}
+
+// QuietHoursRequest replaces the quiet hours of a config
+type QuietHoursRequest struct {
+	Enabled       bool   `json:"enabled"`
+	Timezone      string `json:"timezone"` // Defaults to the user's timezone
+	StartTime     string `json:"start_time" binding:"required"`
+	EndTime       string `json:"end_time" binding:"required"`
+	Days          []int  `json:"days" binding:"max=7,dive,min=0,max=6"`
+	AllowCritical *bool  `json:"allow_critical"` // Defaults to true
+}
+
+// Active reports whether t falls in the quiet period
+func (q QuietHours) Active(t time.Time) bool {
+	if !q.Enabled {
+		return false
+	}
+	loc, err := time.LoadLocation(q.Timezone)
+	if err != nil {
+		return false
+	}
+	start, err1 := ParseClock(q.StartTime)
+	end, err2 := ParseClock(q.EndTime)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+
+	local := t.In(loc)
+	now := local.Hour()*60 + local.Minute()
+	today := int(local.Weekday())
+	yesterday := (today + 6) % 7
+
+	switch {
+	case start == end:
+		// Whole day
+		return q.onDay(today)
+	case start < end:
+		return now >= start && now < end && q.onDay(today)
+	default:
+		// Spans midnight: the evening part belongs to today, the morning part to yesterday
+		return (now >= start && q.onDay(today)) || (now < end && q.onDay(yesterday))
+	}
+}
+
+// onDay reports whether the quiet period starts on the weekday
+func (q QuietHours) onDay(weekday int) bool {
+	if len(q.Days) == 0 {
+		return true
+	}
+	for _, d := range q.Days {
+		if d == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseClock parses "HH:MM" into minutes since midnight
+func ParseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
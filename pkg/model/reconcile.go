@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+// ProviderMonitor is a monitor as listed by a monitoring provider
+type ProviderMonitor struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Kinds of drift between provider monitors and domains
+const (
+	DriftOrphaned = "orphaned" // Monitor of this deployment that no domain references
+	DriftMissing  = "missing"  // Domain without a monitor on a selected provider
+	DriftStale    = "stale"    // Domain references a monitor the provider no longer has
+)
+
+// MonitorDrift is a single mismatch found by a reconciliation run
+type MonitorDrift struct {
+	ID          int       `json:"id" db:"id"`
+	RunID       int       `json:"run_id" db:"run_id"`
+	Provider    string    `json:"provider" db:"provider"`
+	Kind        string    `json:"kind" db:"kind"`
+	MonitorID   *string   `json:"monitor_id,omitempty" db:"monitor_id"`
+	MonitorName *string   `json:"monitor_name,omitempty" db:"monitor_name"`
+	DomainID    *int      `json:"domain_id,omitempty" db:"domain_id"`
+	Action      string    `json:"action" db:"action"` // "deleted", "recreated", "none" or "failed"
+	Error       *string   `json:"error,omitempty" db:"error"`
+	DetectedAt  time.Time `json:"detected_at" db:"detected_at"`
+}
+
+// MonitorReconciliation records one pass of the monitor reconciliation job
+type MonitorReconciliation struct {
+	ID             int            `json:"id" db:"id"`
+	DryRun         bool           `json:"dry_run" db:"dry_run"`
+	MonitorsListed int            `json:"monitors_listed" db:"monitors_listed"`
+	Orphaned       int            `json:"orphaned" db:"orphaned"`
+	Missing        int            `json:"missing" db:"missing"`
+	Stale          int            `json:"stale" db:"stale"`
+	Fixed          int            `json:"fixed" db:"fixed"`
+	Failed         int            `json:"failed" db:"failed"`
+	Error          *string        `json:"error,omitempty" db:"error"`
+	StartedAt      time.Time      `json:"started_at" db:"started_at"`
+	FinishedAt     *time.Time     `json:"finished_at" db:"finished_at"`
+	Drift          []MonitorDrift `json:"drift,omitempty" db:"-"` // Only populated on the latest run
+}
+
+// MonitorDriftReport shows the drift found by the latest reconciliation run and the
+// history of recent runs
+type MonitorDriftReport struct {
+	Latest *MonitorReconciliation  `json:"latest"`
+	Runs   []MonitorReconciliation `json:"runs"`
+}
@@ -10,3 +10,15 @@ type Region struct {
 	CreatedAt time.Time `db:"created_at" json:"-"`
 	UpdatedAt time.Time `db:"updated_at" json:"-"`
 }
+
+// RegionFallbacks lists the regions added to the monitors of domains in a region
+type RegionFallbacks struct {
+	Region    string   `json:"region"`
+	Fallbacks []string `json:"fallbacks"`
+}
+
+// RegionFallbacksRequest replaces the fallback regions of a region. An empty list
+// leaves its domains monitored from their own region only.
+type RegionFallbacksRequest struct {
+	Fallbacks []string `json:"fallbacks" binding:"max=5"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// DomainRegionStatus is the latest result of one provider checking a domain from one of
+// its regions
+type DomainRegionStatus struct {
+	DomainID         int       `json:"-" db:"domain_id"`
+	Provider         string    `json:"provider" db:"provider"`
+	Region           string    `json:"region" db:"region"`
+	Available        bool      `json:"available" db:"available"`
+	StatusCode       int       `json:"status_code" db:"status_code"`
+	ErrorCode        int       `json:"error_code" db:"error_code"`
+	ErrorDescription string    `json:"error_description" db:"error_description"`
+	TotalTime        int       `json:"total_time" db:"total_time"`
+	CheckedAt        time.Time `json:"checked_at" db:"checked_at"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// RegistrationWarningDays lists how many days before a domain registration expires a
+// warning is sent
+var RegistrationWarningDays = []int{30, 14, 7}
+
+// Registration data sources
+const (
+	RegistrationSourceRDAP  = "rdap"
+	RegistrationSourceWHOIS = "whois"
+)
+
+// DomainRegistration is the latest registration lookup for a monitored domain. The
+// lookup is made for the registrable domain, e.g. example.com for www.example.com.
+type DomainRegistration struct {
+	DomainID          int        `json:"domain_id" db:"domain_id"`
+	RegistrableDomain string     `json:"registrable_domain" db:"registrable_domain"`
+	Registrar         *string    `json:"registrar" db:"registrar"`
+	ExpiresAt         *time.Time `json:"expires_at" db:"expires_at"`
+	Source            string     `json:"source" db:"source"` // "rdap" or "whois"
+	Error             *string    `json:"error,omitempty" db:"error"`
+	CheckedAt         time.Time  `json:"checked_at" db:"checked_at"`
+	DaysRemaining     *int       `json:"days_remaining,omitempty" db:"-"`
+}
@@ -0,0 +1,60 @@
+package model
+
+// Uptime report windows
+const (
+	UptimeWindow24h = "24h"
+	UptimeWindow7d  = "7d"
+	UptimeWindow30d = "30d"
+)
+
+// UptimeStats aggregates the check history of a domain over one window. Checks
+// skipped by load shedding are excluded from the uptime percentage, which is nil
+// when the window has no checks.
+type UptimeStats struct {
+	Window           string   `json:"window" db:"-"`
+	TotalChecks      int      `json:"total_checks" db:"total_checks"`
+	FailedChecks     int      `json:"failed_checks" db:"failed_checks"`
+	SkippedChecks    int      `json:"skipped_checks" db:"skipped_checks"`
+	UptimePercentage *float64 `json:"uptime_percentage" db:"-"`
+	AvgResponseTime  float64  `json:"avg_response_time" db:"avg_response_time"` // Milliseconds, successful checks only
+	Incidents        int      `json:"incidents" db:"incidents"`                 // Outages that started or were ongoing in the window
+}
+
+// DomainUptimeReport is the uptime of a domain over every report window
+type DomainUptimeReport struct {
+	DomainID int           `json:"domain_id"`
+	Name     string        `json:"name"`
+	Region   string        `json:"region"`
+	Windows  []UptimeStats `json:"windows"`
+}
+
+// ResponseTimeStats summarizes the response times of the successful checks of a
+// domain over a period, in milliseconds. The figures are nil when there were no checks.
+type ResponseTimeStats struct {
+	Checks int      `json:"checks" db:"checks"`
+	Avg    *float64 `json:"avg" db:"avg"`
+	P50    *float64 `json:"p50" db:"p50"`
+	P95    *float64 `json:"p95" db:"p95"`
+	P99    *float64 `json:"p99" db:"p99"`
+	Max    *float64 `json:"max" db:"max"`
+}
+
+// ResponseTimeTrend is the change of each percentile against the previous period, in
+// percent; nil when either period has no checks
+type ResponseTimeTrend struct {
+	P50 *float64 `json:"p50"`
+	P95 *float64 `json:"p95"`
+	P99 *float64 `json:"p99"`
+}
+
+// DomainResponseStats compares the response times of a domain over the last window
+// with the window before it
+type DomainResponseStats struct {
+	DomainID int               `json:"domain_id"`
+	Name     string            `json:"name"`
+	Region   string            `json:"region"`
+	Window   string            `json:"window"`
+	Current  ResponseTimeStats `json:"current"`
+	Previous ResponseTimeStats `json:"previous"`
+	Trend    ResponseTimeTrend `json:"trend"`
+}
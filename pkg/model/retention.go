@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// DEFAULT_PLAN is the plan assigned to users without an explicit plan
+const DEFAULT_PLAN = "default"
+
+// RetentionPolicy defines how long data is kept for users on a plan
+type RetentionPolicy struct {
+	Plan                    string    `json:"plan" db:"plan"`
+	CheckHistoryDays        int       `json:"check_history_days" db:"check_history_days"`
+	NotificationHistoryDays int       `json:"notification_history_days" db:"notification_history_days"`
+	AuditLogDays            int       `json:"audit_log_days" db:"audit_log_days"`
+	PersonalDataDays        int       `json:"personal_data_days" db:"personal_data_days"` // Anonymize records older than this
+	UpdatedAt               time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// RetentionPolicyRequest represents a request to create/update a retention policy
+type RetentionPolicyRequest struct {
+	CheckHistoryDays        int `json:"check_history_days" binding:"required,min=1"`
+	NotificationHistoryDays int `json:"notification_history_days" binding:"required,min=1"`
+	AuditLogDays            int `json:"audit_log_days" binding:"required,min=1"`
+	PersonalDataDays        int `json:"personal_data_days" binding:"required,min=1"`
+}
+
+// RetentionRun records a single purge or anonymization pass
+type RetentionRun struct {
+	ID           int       `json:"id" db:"id"`
+	Plan         string    `json:"plan" db:"plan"`
+	Target       string    `json:"target" db:"target"`
+	Action       string    `json:"action" db:"action"` // "purge" or "anonymize"
+	RowsAffected int       `json:"rows_affected" db:"rows_affected"`
+	Cutoff       time.Time `json:"cutoff" db:"cutoff"`
+	Error        *string   `json:"error,omitempty" db:"error"`
+	RanAt        time.Time `json:"ran_at" db:"ran_at"`
+}
+
+// RetentionReport summarizes recent pruner activity for admins
+type RetentionReport struct {
+	Runs            []RetentionRun `json:"runs"`
+	TotalPurged     int            `json:"total_purged"`
+	TotalAnonymized int            `json:"total_anonymized"`
+}
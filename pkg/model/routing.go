@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// RoutingRule sends alerts for a user's domains in a region to a specific notification config
+type RoutingRule struct {
+	ID         int       `json:"id" db:"id"`
+	UserID     int       `json:"user_id" db:"user_id"`
+	RegionCode string    `json:"region_code" db:"region_code"`
+	Channel    string    `json:"channel" db:"channel"`
+	ConfigID   int       `json:"config_id" db:"config_id"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// RoutingRuleRequest represents a request to add a routing rule
+type RoutingRuleRequest struct {
+	RegionCode string `json:"region_code" binding:"required"`
+	Channel    string `json:"channel" binding:"required,oneof=telegram email"`
+	ConfigID   int    `json:"config_id" binding:"required"`
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// Runbook holds remediation instructions for a domain or for every domain in a region
+type Runbook struct {
+	ID         int       `json:"id" db:"id"`
+	UserID     int       `json:"user_id" db:"user_id"`
+	DomainID   *int      `json:"domain_id,omitempty" db:"domain_id"`
+	RegionCode *string   `json:"region_code,omitempty" db:"region_code"`
+	URL        string    `json:"runbook_url" db:"runbook_url"`
+	Notes      string    `json:"notes" db:"notes"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// RunbookRequest represents a request to set a runbook
+type RunbookRequest struct {
+	URL   string `json:"runbook_url" binding:"omitempty,url"`
+	Notes string `json:"notes" binding:"max=4000"`
+}
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// Session is a login of a user on one device. Its refresh token is never stored.
+type Session struct {
+	ID         int       `json:"id" db:"id"`
+	UserAgent  string    `json:"user_agent" db:"user_agent"`
+	IPAddress  string    `json:"ip_address" db:"ip_address"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at" db:"last_used_at"` // Last login or token refresh
+	ExpiresAt  time.Time `json:"expires_at" db:"expires_at"`
+	Current    bool      `json:"current" db:"-"` // Session of the requesting token
+}
+
+// TokenPair is a short-lived access token and the refresh token used to renew it
+type TokenPair struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"` // Access token lifetime in seconds
+}
+
+// TokenRefreshRequest exchanges a refresh token for a new token pair
+type TokenRefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// ImpersonationRequest is the reason support staff give for acting as a user
+type ImpersonationRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// ImpersonationToken is a short-lived, read-only access token for acting as a user.
+// It is bound to no session and cannot be refreshed.
+type ImpersonationToken struct {
+	Token     string    `json:"token"`
+	UserID    int       `json:"user_id"`
+	Username  string    `json:"username"`
+	ExpiresIn int       `json:"expires_in"` // Token lifetime in seconds
+	ExpiresAt time.Time `json:"expires_at"`
+}
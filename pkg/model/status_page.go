@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// Supported status page providers
+const (
+	StatusPageProviderStatuspage = "statuspage"
+	StatusPageProviderInstatus   = "instatus"
+)
+
+// StatusPageConfig represents a user's external status page integration
+type StatusPageConfig struct {
+	ID              int                   `json:"id" db:"id"`
+	UserID          int                   `json:"user_id" db:"user_id"`
+	Provider        string                `json:"provider" db:"provider"`
+	Name            string                `json:"name" db:"name"`
+	PageID          string                `json:"page_id" db:"page_id"`
+	APIKey          string                `json:"-" db:"api_key"` // Never returned to clients, sealed unless APIKeyEncrypted is false
+	APIKeyEncrypted bool                  `json:"-" db:"api_key_encrypted"`
+	IsActive        bool                  `json:"is_active" db:"is_active"`
+	Components      []StatusPageComponent `json:"components"`
+	CreatedAt       time.Time             `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time             `json:"updated_at" db:"updated_at"`
+}
+
+// StatusPageComponent maps one of our domains to a component on the status page
+type StatusPageComponent struct {
+	DomainID    int    `json:"domain_id" db:"domain_id" binding:"required"`
+	ComponentID string `json:"component_id" db:"component_id" binding:"required"`
+}
+
+// StatusPageConfigRequest represents a request to add/update a status page integration
+type StatusPageConfigRequest struct {
+	Provider   string                `json:"provider" binding:"required,oneof=statuspage instatus"`
+	Name       string                `json:"name"`
+	PageID     string                `json:"page_id" binding:"required"`
+	APIKey     string                `json:"api_key"` // Required on create, optional on update
+	IsActive   bool                  `json:"active"`
+	Components []StatusPageComponent `json:"components" binding:"dive"`
+}
@@ -0,0 +1,37 @@
+package model
+
+// Sources subdomains are discovered from
+const (
+	SubdomainSourceCT   = "ct"   // Certificate transparency logs
+	SubdomainSourceList = "list" // Names given by the user
+)
+
+// Overall status of a domain and its subdomains
+const (
+	DomainGroupUp       = "up"
+	DomainGroupDegraded = "degraded"
+	DomainGroupDown     = "down"
+)
+
+// SubdomainDiscoveryRequest discovers subdomains of a base domain and monitors them.
+// Subdomains may be full names or the labels in front of the base domain, e.g. "www".
+type SubdomainDiscoveryRequest struct {
+	Source     string   `json:"source" binding:"required,oneof=ct list"`
+	Subdomains []string `json:"subdomains" binding:"max=100"` // Only used with the list source
+}
+
+// SubdomainDiscoveryResponse lists the subdomains found and the outcome of adding them
+type SubdomainDiscoveryResponse struct {
+	Discovered []string `json:"discovered"`
+	DomainBatchAddResponse
+}
+
+// DomainGroupStatus is the combined status of a base domain and its subdomains
+type DomainGroupStatus struct {
+	Parent     Domain   `json:"parent"`
+	Subdomains []Domain `json:"subdomains"`
+	Total      int      `json:"total"` // Active domains of the group, the parent included
+	Up         int      `json:"up"`
+	Down       int      `json:"down"`
+	Status     string   `json:"status"` // See DomainGroupUp
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// tagPattern matches a normalized tag, e.g. "brand-a"
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,49}$`)
+
+// TagsRequest replaces the tags of a domain or the tag subscriptions of a notification
+// config. An empty list removes them all.
+type TagsRequest struct {
+	Tags []string `json:"tags" binding:"max=20"`
+}
+
+// TagsResponse lists the tags of a domain or notification config
+type TagsResponse struct {
+	Tags []string `json:"tags"`
+}
+
+// NormalizeTags lowercases, trims, deduplicates and sorts tags
+func NormalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !tagPattern.MatchString(tag) {
+			return nil, fmt.Errorf("invalid tag %q: use up to 50 letters, digits, '.', '_' or '-'", tag)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
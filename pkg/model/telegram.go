@@ -20,6 +20,9 @@ type TelegramConfig struct {
 	NotifyOnDown   bool      `json:"notify_on_down" db:"notify_on_down"`
 	NotifyOnUp     bool      `json:"notify_on_up" db:"notify_on_up"`
 	MonitorRegions []string  `json:"monitor_regions"`
+	Tags           []string  `json:"tags"`                   // Domain tags subscribed to; empty means all domains
+	Timezone       *string   `json:"timezone" db:"timezone"` // nil uses the user's timezone
+	ExternalID     *string   `json:"external_id,omitempty" db:"external_id"`
 	CreatedAt      time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -97,3 +100,9 @@ type TelegramPromptResponse struct {
 	PerPage    int              `json:"per_page"`
 	TotalPages int              `json:"total_pages"`
 }
+
+// TelegramLinkCode is a one-time code sent to the bot as /link <code> to register a chat
+type TelegramLinkCode struct {
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,18 @@
+package model
+
+// DefaultTimezone is used for notification timestamps when neither the user nor the
+// notification config chose a timezone
+const DefaultTimezone = "Asia/Hong_Kong"
+
+// TimezoneRequest sets a timezone preference. An empty timezone clears it.
+type TimezoneRequest struct {
+	Timezone string `json:"timezone"` // IANA name, e.g. "Europe/London"
+}
+
+// ConfigTimezone is the timezone preference of a notification config
+type ConfigTimezone struct {
+	Channel   string  `json:"channel"`
+	ConfigID  int     `json:"config_id"`
+	Timezone  *string `json:"timezone"`  // nil uses the user's timezone
+	Effective string  `json:"effective"` // Timezone notifications are formatted in
+}
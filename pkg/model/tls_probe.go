@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// TLSProbe records the TLS and HTTP protocol capabilities of a domain at a point in time
+type TLSProbe struct {
+	ID            int       `json:"id" db:"id"`
+	DomainID      int       `json:"domain_id" db:"domain_id"`
+	TLSVersions   []string  `json:"tls_versions" db:"-"`   // e.g. "TLS 1.2", "TLS 1.3"
+	ALPNProtocols []string  `json:"alpn_protocols" db:"-"` // e.g. "h2", "http/1.1"
+	HTTP3         bool      `json:"http3" db:"http3"`
+	CipherSuite   *string   `json:"cipher_suite" db:"cipher_suite"`
+	WeakCipher    bool      `json:"weak_cipher" db:"weak_cipher"`
+	Error         *string   `json:"error,omitempty" db:"error"`
+	ProbedAt      time.Time `json:"probed_at" db:"probed_at"`
+}
+
+// HasTLSVersion reports whether the probe found the given TLS version, e.g. "TLS 1.0"
+func (p TLSProbe) HasTLSVersion(version string) bool {
+	for _, v := range p.TLSVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// HasALPN reports whether the probe negotiated the given ALPN protocol, e.g. "h2"
+func (p TLSProbe) HasALPN(protocol string) bool {
+	for _, v := range p.ALPNProtocols {
+		if v == protocol {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// TRIAL_PLAN is the plan assigned to users while their trial is running
+const TRIAL_PLAN = "trial"
+
+// TrialWarningDays lists how many days before expiry a warning is sent
+var TrialWarningDays = []int{7, 3, 1}
+
+// TrialStatus describes the trial state of a user
+type TrialStatus struct {
+	UserID         int        `json:"user_id" db:"user_id"`
+	Username       string     `json:"username" db:"username"`
+	Plan           string     `json:"plan" db:"plan"`
+	TrialExpiresAt *time.Time `json:"trial_expires_at" db:"trial_expires_at"`
+	SuspendedAt    *time.Time `json:"suspended_at" db:"suspended_at"`
+	DaysRemaining  int        `json:"days_remaining"`
+	Expired        bool       `json:"expired"`
+}
+
+// TrialUpdateRequest represents a request to start or extend a user's trial
+type TrialUpdateRequest struct {
+	ExpiresAt time.Time `json:"expires_at" binding:"required"`
+}
+
+// TrialConvertRequest represents a request to end a trial by moving the user to a paid plan
+type TrialConvertRequest struct {
+	Plan string `json:"plan" binding:"required"`
+}
@@ -16,14 +16,28 @@ type User struct {
 	TwoFactorSecret  sql.NullString `json:"-" db:"two_factor_secret"`
 	CreatedAt        time.Time      `json:"created_at" db:"created_at"`
 	UpdatedAt        time.Time      `json:"updated_at" db:"updated_at"`
-	Region           sql.NullString `json:"region" db:"region"` // Changed to sql.NullString
+	Region           sql.NullString `json:"region" db:"region"`     // Changed to sql.NullString
+	Timezone         sql.NullString `json:"timezone" db:"timezone"` // Timestamps in notifications; NULL uses DefaultTimezone
+	TrialExpiresAt   *time.Time     `json:"trial_expires_at,omitempty" db:"trial_expires_at"`
+	SuspendedAt      *time.Time     `json:"suspended_at,omitempty" db:"suspended_at"`
+	Role             string         `json:"role" db:"role"` // RoleUser or RoleAdmin
+	// DeletionRequestedAt is set once the user asked for their account to be deleted
+	DeletionRequestedAt *time.Time `json:"deletion_requested_at,omitempty" db:"deletion_requested_at"`
 }
 
+// Roles of users; admins reach the admin API
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 // UserCredentials is used for login requests
 type UserCredentials struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
 	TOTPCode string `json:"totp_code"`
+	// RecoveryCode replaces TOTPCode when the authenticator is unavailable
+	RecoveryCode string `json:"recovery_code"`
 }
 
 // TwoFactorSetupResponse contains info for QR code setup
@@ -32,6 +46,17 @@ type TwoFactorSetupResponse struct {
 	QRCodeURL string `json:"qrcode_url"`
 }
 
+// RecoveryCodesResponse is returned when recovery codes are regenerated; the codes are
+// shown only once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// RecoveryCodesStatus reports how many unused recovery codes a user has left
+type RecoveryCodesStatus struct {
+	Remaining int `json:"remaining"`
+}
+
 // TwoFactorVerifyRequest is used to verify and enable 2FA
 type TwoFactorVerifyRequest struct {
 	TOTPCode string `json:"totp_code" binding:"required"`
@@ -0,0 +1,25 @@
+package model
+
+import "github.com/lib/pq"
+
+// UserSettings are a user's domain limit and the defaults applied to domains they add
+// without explicit values. Nil defaults fall back to the server defaults.
+type UserSettings struct {
+	DomainLimit int `json:"domain_limit" db:"domain_limit"` // Set by admins only
+
+	DefaultInterval          *int           `json:"default_interval" db:"default_interval"`
+	DefaultRegion            *string        `json:"default_region" db:"default_region"`
+	DefaultFailureThreshold  *int           `json:"default_failure_threshold" db:"default_failure_threshold"`
+	DefaultRecoveryThreshold *int           `json:"default_recovery_threshold" db:"default_recovery_threshold"`
+	DefaultChannels          pq.StringArray `json:"default_notification_channels" db:"default_notification_channels"` // Nil alerts through every channel
+}
+
+// UserSettingsRequest replaces the domain defaults of a user; a null value restores the
+// server default
+type UserSettingsRequest struct {
+	DefaultInterval          *int      `json:"default_interval"`
+	DefaultRegion            *string   `json:"default_region"`
+	DefaultFailureThreshold  *int      `json:"default_failure_threshold" binding:"omitempty,min=1,max=10"`
+	DefaultRecoveryThreshold *int      `json:"default_recovery_threshold" binding:"omitempty,min=1,max=10"`
+	DefaultChannels          *[]string `json:"default_notification_channels" binding:"omitempty,dive,oneof=telegram email"` // An empty list adds domains silenced everywhere
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// Ways of proving ownership of a domain
+const (
+	VerificationDNS  = "dns"  // TXT record on the verification host name
+	VerificationHTTP = "http" // Token served from the well-known path of the domain
+)
+
+// DomainVerification tells how to prove ownership of a domain and whether it is proven
+type DomainVerification struct {
+	DomainID   int        `json:"domain_id"`
+	Verified   bool       `json:"verified"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+	Method     string     `json:"method,omitempty"` // How the token was found, on the response of a successful check
+	Token      string     `json:"token"`
+	DNSName    string     `json:"dns_name"` // TXT record to create, holding the token
+	HTTPURL    string     `json:"http_url"` // File to serve, holding the token
+}
@@ -16,9 +16,15 @@ if [ -z "$TELEGRAM_WEBHOOK_URL" ]; then
     exit 1
 fi
 
+# The API refuses webhook updates that don't carry this secret
+if [ -z "$TELEGRAM_WEBHOOK_SECRET" ]; then
+    echo "Error: TELEGRAM_WEBHOOK_SECRET is not set"
+    exit 1
+fi
+
 # Set webhook
 curl -X POST "https://api.telegram.org/bot$TELEGRAM_BOT_TOKEN/setWebhook" \
   -H "Content-Type: application/json" \
-  -d "{\"url\":\"$TELEGRAM_WEBHOOK_URL\"}"
+  -d "{\"url\":\"$TELEGRAM_WEBHOOK_URL\",\"secret_token\":\"$TELEGRAM_WEBHOOK_SECRET\"}"
 
 echo "Webhook set to: $TELEGRAM_WEBHOOK_URL"
\ No newline at end of file